@@ -0,0 +1,73 @@
+package benchmarks_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/tinywasm/pdf/benchmarks"
+)
+
+func loadImage(tb testing.TB) []byte {
+	img, err := os.ReadFile("../fpdf/image/logo.png")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return img
+}
+
+func BenchmarkTextHeavy(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := benchmarks.TextHeavy(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTableHeavy(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := benchmarks.TableHeavy(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkImageHeavy(b *testing.B) {
+	img := loadImage(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := benchmarks.ImageHeavy(io.Discard, img); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVectorHeavy(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := benchmarks.VectorHeavy(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestBudgets guards the workloads against allocation regressions. The
+// budgets leave about 50% headroom over the baseline documented in README.md.
+func TestBudgets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping performance budgets in short mode")
+	}
+	img := loadImage(t)
+
+	benchmarks.Check(t, "TextHeavy", benchmarks.Budget{AllocsPerOp: 3900, BytesPerOp: 28 << 20},
+		func() error { return benchmarks.TextHeavy(io.Discard) })
+	benchmarks.Check(t, "TableHeavy", benchmarks.Budget{AllocsPerOp: 33500, BytesPerOp: 54 << 20},
+		func() error { return benchmarks.TableHeavy(io.Discard) })
+	benchmarks.Check(t, "ImageHeavy", benchmarks.Budget{AllocsPerOp: 1300, BytesPerOp: 14 << 20},
+		func() error { return benchmarks.ImageHeavy(io.Discard, img) })
+	benchmarks.Check(t, "VectorHeavy", benchmarks.Budget{AllocsPerOp: 46000, BytesPerOp: 18 << 20},
+		func() error { return benchmarks.VectorHeavy(io.Discard) })
+}
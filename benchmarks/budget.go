@@ -0,0 +1,47 @@
+package benchmarks
+
+import (
+	"testing"
+)
+
+// Budget is the performance budget of a workload. Zero values are not
+// checked. Allocation counts are stable across machines, whereas timings are
+// not, so budgets are usually expressed in allocations and bytes.
+type Budget struct {
+	AllocsPerOp int64 // maximum allocations per run
+	BytesPerOp  int64 // maximum bytes allocated per run
+	NsPerOp     int64 // maximum duration per run, in nanoseconds
+}
+
+// Measure benchmarks fn, which typically calls one of the workloads of this
+// package, and returns the result. The first error returned by fn is
+// reported instead.
+func Measure(fn func() error) (res testing.BenchmarkResult, err error) {
+	res = testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N && err == nil; i++ {
+			err = fn()
+		}
+	})
+	return
+}
+
+// Check measures fn and fails tb if the result exceeds budget. The measured
+// numbers are logged so they can be compared with the documented baseline.
+func Check(tb testing.TB, name string, budget Budget, fn func() error) {
+	tb.Helper()
+	res, err := Measure(fn)
+	if err != nil {
+		tb.Fatalf("%s: %v", name, err)
+	}
+	tb.Logf("%s: %d ns/op, %d B/op, %d allocs/op", name, res.NsPerOp(), res.AllocedBytesPerOp(), res.AllocsPerOp())
+	if budget.AllocsPerOp > 0 && res.AllocsPerOp() > budget.AllocsPerOp {
+		tb.Errorf("%s: %d allocs/op exceeds budget of %d", name, res.AllocsPerOp(), budget.AllocsPerOp)
+	}
+	if budget.BytesPerOp > 0 && res.AllocedBytesPerOp() > budget.BytesPerOp {
+		tb.Errorf("%s: %d B/op exceeds budget of %d", name, res.AllocedBytesPerOp(), budget.BytesPerOp)
+	}
+	if budget.NsPerOp > 0 && res.NsPerOp() > budget.NsPerOp {
+		tb.Errorf("%s: %d ns/op exceeds budget of %d", name, res.NsPerOp(), budget.NsPerOp)
+	}
+}
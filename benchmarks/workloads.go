@@ -0,0 +1,82 @@
+// Package benchmarks provides representative document workloads and helpers
+// to check them against a performance budget. The workloads are exercised by
+// the benchmarks in this package; see README.md for the baseline numbers.
+package benchmarks
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/pdf"
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+const loremIpsum = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod " +
+	"tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis " +
+	"nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. "
+
+// TextHeavy writes a 20 page document made of justified paragraphs.
+func TextHeavy(w io.Writer) error {
+	f := fpdf.New("P", "mm", "A4", "")
+	f.SetFont("Helvetica", "", 11)
+	f.AddPage()
+	for f.PageNo() < 20 {
+		f.MultiCell(0, 5, loremIpsum+loremIpsum+loremIpsum, "", "J", false)
+		f.Ln(2)
+	}
+	return f.Output(w)
+}
+
+// TableHeavy writes a table of 1000 rows and 4 columns using the Document
+// API, spanning several pages.
+func TableHeavy(w io.Writer) error {
+	doc := pdf.NewDocument()
+	doc.AddPage()
+	t := doc.AddTable().
+		AddColumn("ID").Width(20).AlignRight().
+		AddColumn("Product").Width(80).
+		AddColumn("Quantity").Width(30).AlignRight().
+		AddColumn("Total").Width(40).AlignRight()
+	for i := 0; i < 1000; i++ {
+		t.AddRow(Convert(i).String(), "Product "+Convert(i%37).String(),
+			Convert(i%12+1).String(), Sprintf("%.2f", float64(i)*1.25))
+	}
+	t.Draw()
+	return doc.OutputTo(w)
+}
+
+// ImageHeavy writes 10 pages each holding 12 placements of img, which must
+// be PNG data. The image is registered once and reused, as a report with a
+// repeated logo or icon would do.
+func ImageHeavy(w io.Writer, img []byte) error {
+	f := fpdf.New("P", "mm", "A4", "")
+	f.RegisterImageOptionsReader("img", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(img))
+	for p := 0; p < 10; p++ {
+		f.AddPage()
+		for i := 0; i < 12; i++ {
+			x := 10 + float64(i%3)*65
+			y := 10 + float64(i/3)*70
+			f.ImageOptions("img", x, y, 60, 0, false, fpdf.ImageOptions{}, 0, "")
+		}
+	}
+	return f.Output(w)
+}
+
+// VectorHeavy writes 10 pages each holding 500 lines, rectangles and
+// curves.
+func VectorHeavy(w io.Writer) error {
+	f := fpdf.New("P", "mm", "A4", "")
+	for p := 0; p < 10; p++ {
+		f.AddPage()
+		for i := 0; i < 500; i++ {
+			x := 10 + float64(i%19)*10
+			y := 10 + float64(i/19)*10
+			f.SetDrawColor(i%255, (i*7)%255, (i*13)%255)
+			f.Line(x, y, x+8, y+8)
+			f.Rect(x, y, 8, 8, "D")
+			f.Curve(x, y+8, x+4, y, x+8, y+8, "D")
+		}
+	}
+	return f.Output(w)
+}
@@ -0,0 +1,129 @@
+package pdf
+
+// CalloutKind selects the colors, icon and default title of a callout box
+// drawn by Callout.
+type CalloutKind int
+
+const (
+	CalloutNote CalloutKind = iota
+	CalloutTip
+	CalloutWarning
+	CalloutDanger
+	// CalloutQuote draws a blockquote: a gray bar beside italic text, without
+	// background, icon or default title.
+	CalloutQuote
+)
+
+// calloutStyle holds the appearance of a kind of callout.
+type calloutStyle struct {
+	title      string // default title
+	icon       string // letter drawn in the icon, empty for none
+	bar        Color
+	background *Color // nil for none
+}
+
+var calloutStyles = map[CalloutKind]calloutStyle{
+	CalloutNote:    {"Note", "i", Color{33, 110, 200}, &Color{232, 241, 252}},
+	CalloutTip:     {"Tip", "i", Color{40, 150, 80}, &Color{233, 247, 238}},
+	CalloutWarning: {"Warning", "!", Color{220, 140, 0}, &Color{255, 246, 224}},
+	CalloutDanger:  {"Danger", "!", Color{200, 40, 40}, &Color{252, 234, 234}},
+	CalloutQuote:   {"", "", Color{150, 150, 150}, nil},
+}
+
+// calloutPad is the space between the edges of a callout and its text, and
+// calloutBarW the width of the colored bar on its left.
+const (
+	calloutPad  = 3
+	calloutBarW = 1.2
+)
+
+// Callout draws a box with a colored bar on its left, such as the NOTE and
+// WARNING blocks of a manual, across the text area. The title is printed in
+// bold beside an icon in the color of kind; an empty title uses the name of
+// the kind, such as "Note". body is wrapped in the Body style of the theme
+// and may contain line breaks.
+//
+// A callout that does not fit on the page continues on the next one: the
+// background and the bar are drawn on each page it spans, and it is never
+// split before its first line.
+func (d *Document) Callout(kind CalloutKind, title, body string) *Document {
+	f := d.internal
+	cs, ok := calloutStyles[kind]
+	if !ok {
+		f.SetErrorf("undefined callout kind: %d", kind)
+		return d
+	}
+	if title == "" {
+		title = cs.title
+	}
+	fr, fg, fb := f.GetFillColor()
+
+	bodyStyle := d.theme.Body
+	if kind == CalloutQuote {
+		bodyStyle.Font = FontItalic
+	}
+	lineHt := d.applyTextStyle(bodyStyle)
+	pageW, _ := f.GetPageSize()
+	lMargin, _, rMargin, _ := f.GetMargins()
+	x := lMargin
+	w := pageW - lMargin - rMargin
+	textX := x + calloutBarW + calloutPad
+	textW := x + w - calloutPad - textX
+
+	// row draws the background and the bar of a row of height h, on the next
+	// page if it does not fit, then calls draw with the top of the row.
+	row := func(h float64, draw func(y float64)) {
+		d.keepTogether(h)
+		y := f.GetY()
+		if cs.background != nil {
+			f.SetFillColor(cs.background.R, cs.background.G, cs.background.B)
+			f.Rect(x, y, w, h, "F")
+		}
+		f.SetFillColor(cs.bar.R, cs.bar.G, cs.bar.B)
+		f.Rect(x, y, calloutBarW, h, "F")
+		if draw != nil {
+			draw(y)
+		}
+		f.SetY(y + h)
+	}
+
+	// Keep the top padding with the first line.
+	d.keepTogether(calloutPad + lineHt)
+	row(calloutPad, nil)
+	if title != "" {
+		titleStyle := bodyStyle
+		titleStyle.Font = FontBold
+		titleStyle.Color = cs.bar
+		d.applyTextStyle(titleStyle)
+		row(lineHt, func(y float64) {
+			tx := textX
+			if cs.icon != "" {
+				r := lineHt * 0.35
+				f.Circle(tx+r, y+lineHt/2, r, "F")
+				f.SetTextColor(255, 255, 255)
+				f.SetXY(tx, y)
+				f.CellFormat(2*r, lineHt, cs.icon, "", 0, "C", false, 0, "")
+				f.SetTextColor(cs.bar.R, cs.bar.G, cs.bar.B)
+				tx += 2*r + 1.5
+			}
+			f.SetXY(tx, y)
+			f.CellFormat(x+w-calloutPad-tx, lineHt, title, "", 0, "L", false, 0, "")
+		})
+		d.applyTextStyle(bodyStyle)
+	}
+	if body != "" {
+		for _, line := range f.SplitText(body, textW) {
+			row(lineHt, func(y float64) {
+				f.SetXY(textX, y)
+				f.CellFormat(textW, lineHt, line, "", 0, "L", false, 0, "")
+			})
+		}
+	}
+	row(calloutPad, nil)
+
+	f.SetX(lMargin)
+	f.Ln(bodyStyle.SpaceAfter)
+	f.SetFillColor(fr, fg, fb)
+	f.SetTextColor(0, 0, 0)
+	return d
+}
@@ -0,0 +1,43 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCallout(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	doc.Callout(CalloutWarning, "", "Disconnect the power before opening the case.")
+	doc.internal.SetY(250)
+	doc.Callout(CalloutNote, "Details", strings.Repeat("A long note that does not fit on the page. ", 30))
+	doc.Callout(CalloutQuote, "", "Quoted text.")
+	if doc.internal.Err() {
+		t.Fatal(doc.internal.Error())
+	}
+	if n := doc.internal.PageCount(); n != 2 {
+		t.Fatalf("expected the note to continue on a second page, got %d pages", n)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+	pages := strings.Split(buf.String(), "/Type /Page\n")
+	for _, want := range []string{"(Warning)", "(Details)", "(Quoted text.)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %s", want)
+		}
+	}
+	// The bar and background of the note are drawn on both pages.
+	if len(pages) < 3 || !strings.Contains(pages[1], "0.129 0.431 0.784 rg") || !strings.Contains(pages[2], "0.129 0.431 0.784 rg") {
+		t.Errorf("note bar not drawn on both pages")
+	}
+
+	doc.Callout(CalloutKind(99), "", "")
+	if !doc.internal.Err() {
+		t.Errorf("expected error for an undefined kind")
+	}
+}
@@ -0,0 +1,145 @@
+package pdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// CodeBlockOptions configures the blocks drawn by CodeBlock. The zero value
+// draws the code without background or line numbers, cutting the lines that
+// are too long.
+type CodeBlockOptions struct {
+	// Wrap continues the lines that are too long on the following lines,
+	// ending each broken part with a backslash. Otherwise they are cut at the
+	// right edge of the block.
+	Wrap bool
+	// LineNumbers shows the number of each source line in a gutter on the
+	// left.
+	LineNumbers bool
+	// Background is the color of the box drawn behind the code, nil for none.
+	Background *Color
+	// TabWidth is the number of columns of a tab; 0 means 4.
+	TabWidth int
+}
+
+// codeBlockPad is the space between the edges of a code block and its text.
+const codeBlockPad = 2
+
+// CodeBlock draws the source code src across the text area in the Code style
+// of the theme, which must use a monospaced font such as Courier. Spaces and
+// tabs are preserved, and the text is printed as it is, without any syntax
+// highlighting. A block that does not fit on the page continues on the next
+// one, with its background drawn on each page.
+func (d *Document) CodeBlock(src string, opts CodeBlockOptions) *Document {
+	style, ok := d.style(StyleCode)
+	if !ok {
+		return d
+	}
+	f := d.internal
+	tabWidth := opts.TabWidth
+	if tabWidth == 0 {
+		tabWidth = 4
+	}
+	lineHt := d.applyTextStyle(style)
+	pageW, _ := f.GetPageSize()
+	lMargin, _, rMargin, _ := f.GetMargins()
+	cellMargin := f.GetCellMargin()
+	x := lMargin
+	w := pageW - lMargin - rMargin
+	charW := f.GetStringWidth("0")
+
+	lines := Convert(Convert(src).Replace("\r", "").String()).Split("\n")
+	for len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	gutterW := 0.0
+	if opts.LineNumbers {
+		gutterW = float64(len(Sprintf("%d", len(lines)))+1)*charW + 2*cellMargin
+	}
+	textX := x + codeBlockPad + gutterW
+	cols := int((x + w - codeBlockPad - textX - 2*cellMargin) / charW)
+	if cols < 2 {
+		cols = 2
+	}
+
+	fr, fg, fb := f.GetFillColor()
+	// row draws the background of a row of height h, on the next page if it
+	// does not fit, then calls draw with the top of the row.
+	row := func(h float64, draw func(y float64)) {
+		d.keepTogether(h)
+		y := f.GetY()
+		if opts.Background != nil {
+			f.SetFillColor(opts.Background.R, opts.Background.G, opts.Background.B)
+			f.Rect(x, y, w, h, "F")
+		}
+		if draw != nil {
+			draw(y)
+		}
+		f.SetY(y + h)
+	}
+
+	d.keepTogether(codeBlockPad + lineHt)
+	row(codeBlockPad, nil)
+	for n, line := range lines {
+		parts := codeBlockParts(expandTabs(line, tabWidth), cols, opts.Wrap)
+		for j, part := range parts {
+			row(lineHt, func(y float64) {
+				if opts.LineNumbers && j == 0 {
+					f.SetTextColor(150, 150, 150)
+					f.SetXY(x+codeBlockPad, y)
+					f.CellFormat(gutterW, lineHt, Sprintf("%d", n+1), "", 0, "R", false, 0, "")
+					f.SetTextColor(style.Color.R, style.Color.G, style.Color.B)
+				}
+				f.SetXY(textX, y)
+				f.CellFormat(x+w-codeBlockPad-textX, lineHt, part, "", 0, "L", false, 0, "")
+			})
+		}
+	}
+	row(codeBlockPad, nil)
+
+	f.SetX(lMargin)
+	f.Ln(style.SpaceAfter)
+	f.SetFillColor(fr, fg, fb)
+	f.SetTextColor(0, 0, 0)
+	return d
+}
+
+// expandTabs replaces the tabs of line with spaces up to the next multiple of
+// tabWidth columns.
+func expandTabs(line string, tabWidth int) string {
+	if !Contains(line, "\t") {
+		return line
+	}
+	var out []rune
+	for _, r := range line {
+		if r != '\t' {
+			out = append(out, r)
+			continue
+		}
+		for {
+			out = append(out, ' ')
+			if len(out)%tabWidth == 0 {
+				break
+			}
+		}
+	}
+	return string(out)
+}
+
+// codeBlockParts returns line in parts of at most cols columns: the first
+// one only unless wrap is set, in which case every part but the last ends
+// with a backslash marking its continuation.
+func codeBlockParts(line string, cols int, wrap bool) []string {
+	r := []rune(line)
+	if len(r) <= cols {
+		return []string{line}
+	}
+	if !wrap {
+		return []string{string(r[:cols])}
+	}
+	var parts []string
+	for len(r) > cols {
+		parts = append(parts, string(r[:cols-1])+"\\")
+		r = r[cols-1:]
+	}
+	return append(parts, string(r))
+}
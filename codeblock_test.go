@@ -0,0 +1,45 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCodeBlock(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	src := "func main() {\n\tfmt.Println(\"(hi)\")\n\t// " + strings.Repeat("x", 200) + "\n}\n"
+	doc.CodeBlock(src, CodeBlockOptions{Wrap: true, LineNumbers: true, Background: &Color{246, 248, 250}})
+	doc.CodeBlock(src, CodeBlockOptions{})
+	if doc.internal.Err() {
+		t.Fatal(doc.internal.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"(    fmt.Println\\(\"\\(hi\\)\"\\))", "(1)", "(4)", "\\\\)Tj", "0.965 0.973 0.980 rg"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %s", want)
+		}
+	}
+	if n := strings.Count(out, "(5)"); n != 0 {
+		t.Errorf("trailing newline numbered as a line")
+	}
+}
+
+func TestCodeBlockParts(t *testing.T) {
+	if got := codeBlockParts("abcdefgh", 4, true); strings.Join(got, "|") != "abc\\|def\\|gh" {
+		t.Errorf("unexpected wrapped parts: %q", got)
+	}
+	if got := codeBlockParts("abcdefgh", 4, false); strings.Join(got, "|") != "abcd" {
+		t.Errorf("unexpected cut parts: %q", got)
+	}
+	if got := expandTabs("a\tb\t\tc", 4); got != "a   b       c" {
+		t.Errorf("unexpected tab expansion: %q", got)
+	}
+}
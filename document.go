@@ -3,20 +3,29 @@ package pdf
 import (
 	"bytes"
 	"io"
+	"io/fs"
 	"strings"
 
 	. "github.com/tinywasm/fmt"
 	"github.com/tinywasm/pdf/fpdf"
+	"github.com/tinywasm/time"
 )
 
 // Document wraps the internal fpdf.Fpdf to provide a fluent API.
 type Document struct {
 	internal *fpdf.Fpdf
-	logger   func(message ...any)
+	logger   Logger
 
 	// Resource registries
 	fonts  map[string]string // family -> path
 	images map[string]string // name -> path
+
+	theme  Theme         // styles used by headings, paragraphs, tables and UseStyle
+	inline *inlineLayout // fragments collected between Inline and EndInline
+	// number following the last top-level item of the previous NumberedList
+	listNext int
+	// files read by the documents of a mail merge, by path; nil outside one
+	shared map[string][]byte
 }
 
 // DefaultFontPath is the default path to the Arial UTF-8 font.
@@ -24,14 +33,22 @@ const DefaultFontPath = "fonts/Arial.ttf"
 
 // NewDocument creates a new Document instance with UTF-8 support.
 func NewDocument() *Document {
+	return newDocument(nil)
+}
+
+// newDocument creates a Document reading its files through shared, if not
+// nil, so that each file is read once for all the documents sharing it.
+func newDocument(shared map[string][]byte) *Document {
 	d := &Document{
 		fonts:  make(map[string]string),
 		images: make(map[string]string),
+		theme:  DefaultTheme(),
+		shared: shared,
 	}
 	d.initIO() // initializes logger + IO depending on build tag
 	d.internal = fpdf.New(
 		fpdf.WriteFileFunc(d.writeFile),
-		fpdf.ReadFileFunc(d.readFile),
+		fpdf.ReadFileFunc(d.readShared),
 		fpdf.FileSizeFunc(d.fileSize),
 	)
 	d.loadDefaultFont()
@@ -40,24 +57,44 @@ func NewDocument() *Document {
 
 // loadDefaultFont loads Arial as UTF-8 font so the default "Arial" supports unicode.
 func (d *Document) loadDefaultFont() {
-	data, err := d.readFile(DefaultFontPath)
+	data, err := d.internal.ReadResource(DefaultFontPath)
 	if err != nil {
-		return // fallback to built-in Arial (Latin-1 only)
+		// fallback to built-in Arial (Latin-1 only)
+		d.logger.Debug("default font not found, using the built-in Arial", "path", DefaultFontPath, "error", err)
+		return
 	}
 	d.internal.AddUTF8FontFromBytes("Arial", "", data)
+	d.logger.Debug("font loaded", "family", "Arial", "path", DefaultFontPath)
 }
 
-// SetLog sets the logger function.
-func (d *Document) SetLog(fn func(...any)) *Document {
-	d.logger = fn
-	return d
-}
-
-// Log writes a message to the logger.
-func (d *Document) Log(message ...any) {
-	if d.logger != nil {
-		d.logger(message...)
+// readShared reads filePath, once for all the documents of a mail merge.
+func (d *Document) readShared(filePath string) ([]byte, error) {
+	if d.shared == nil {
+		return d.readFile(filePath)
+	}
+	if data, ok := d.shared[filePath]; ok {
+		return data, nil
 	}
+	data, err := d.readFile(filePath)
+	if err == nil {
+		d.shared[filePath] = data
+	}
+	return data, err
+}
+
+// SetResourceFS makes the document read its fonts, images and other files
+// from fsys, such as an embed.FS, instead of the file system of the server or
+// the network in the browser. The default font is loaded from it if it was
+// not found before.
+//
+//	//go:embed fonts images
+//	var assets embed.FS
+//
+//	doc := pdf.NewDocument().SetResourceFS(assets)
+func (d *Document) SetResourceFS(fsys fs.FS) *Document {
+	d.internal.SetResourceFS(fsys)
+	d.loadDefaultFont()
+	return d
 }
 
 // RegisterFont registers a font to be loaded.
@@ -76,18 +113,21 @@ func (d *Document) RegisterImage(name, path string) *Document {
 // Load loads all registered resources.
 func (d *Document) Load(cb func(error)) {
 	for family, path := range d.fonts {
-		data, err := d.readFile(path)
+		data, err := d.internal.ReadResource(path)
 		if err != nil {
+			d.logger.Error("font loading failed", "family", family, "path", path, "error", err)
 			cb(err)
 			return
 		}
 		// We assume regular style ("") and UTF8 font for now
 		d.internal.AddUTF8FontFromBytes(family, "", data)
+		d.logger.Debug("font loaded", "family", family, "path", path)
 	}
 
 	for name, path := range d.images {
-		data, err := d.readFile(path)
+		data, err := d.internal.ReadResource(path)
 		if err != nil {
+			d.logger.Error("image loading failed", "name", name, "path", path, "error", err)
 			cb(err)
 			return
 		}
@@ -99,6 +139,7 @@ func (d *Document) Load(cb func(error)) {
 
 		opt := fpdf.ImageOptions{ImageType: ext, ReadDpi: true}
 		d.internal.RegisterImageOptionsReader(name, opt, bytes.NewReader(data))
+		d.logger.Debug("image registered", "name", name, "path", path)
 	}
 
 	cb(nil)
@@ -111,12 +152,103 @@ func (d *Document) Draw() *Document {
 
 // WritePdf generates the PDF and writes it to the specified path.
 func (d *Document) WritePdf(path string) error {
-	return d.internal.OutputFileAndClose(path)
+	err := d.internal.OutputFileAndClose(path)
+	d.logOutput(err, "path", path)
+	return err
 }
 
 // OutputTo writes the generated PDF into the provided writer.
 func (d *Document) OutputTo(w io.Writer) error {
-	return d.internal.Output(w)
+	err := d.internal.Output(w)
+	d.logOutput(err)
+	return err
+}
+
+// logOutput logs the warnings of the document and the result err of writing
+// it, with the details in args.
+func (d *Document) logOutput(err error, args ...any) {
+	for _, w := range d.internal.GetErrors() {
+		if w != err {
+			d.logger.Warn(w.Error())
+		}
+	}
+	if err != nil {
+		d.logger.Error("PDF output failed", append(args, "error", err)...)
+		return
+	}
+	d.logger.Debug("PDF written", args...)
+}
+
+// SetStats makes the document count the pages, bytes, fonts and images it
+// writes and the time spent compressing streams and subsetting fonts in s, to
+// tune server workloads.
+func (d *Document) SetStats(s *fpdf.Stats) *Document {
+	d.internal.SetStats(s)
+	return d
+}
+
+// Engine returns the fpdf.Fpdf that renders the document. It is the only PDF
+// engine of the module, so code written against it, such as fpdf.GridType or
+// the HTML and template helpers, can draw on a Document, mixed with the
+// fluent API, without being rewritten.
+func (d *Document) Engine() *fpdf.Fpdf {
+	return d.internal
+}
+
+// Err returns the error that halted the generation of the document, or nil.
+// Once set, the error turns the calls that follow into no-ops, so a chain of
+// calls such as AddText(...).Bold().Size(14).Draw() can be checked once, at
+// its end or before writing the document.
+func (d *Document) Err() error {
+	return d.internal.Error()
+}
+
+// GetErrors returns the non-fatal warnings accumulated while generating the
+// document, such as missing glyphs or clipped images, followed by the error
+// that halted generation, if any.
+func (d *Document) GetErrors() []error {
+	return d.internal.GetErrors()
+}
+
+// SetLocale selects the language conventions, such as "de-CH", used to format
+// numbers, amounts of money and dates by FormatNumber, FormatCurrency,
+// FormatDate and the Number, Currency and Date table columns. An unknown tag
+// sets the document error.
+func (d *Document) SetLocale(tag string) *Document {
+	d.internal.SetLocale(tag)
+	return d
+}
+
+// FormatNumber returns v with decimals digits and grouped thousands in the
+// document locale, e.g. "1’234.50" in de-CH.
+func (d *Document) FormatNumber(v float64, decimals int) string {
+	return d.internal.FormatNumber(v, decimals)
+}
+
+// FormatCurrency returns the amount v in the currency of the document locale,
+// e.g. "CHF 1’234.50" in de-CH.
+func (d *Document) FormatCurrency(v float64) string {
+	return d.internal.FormatCurrency(v)
+}
+
+// FormatDate returns the date of nano, in nanoseconds since the Unix epoch,
+// in the date layout of the document locale, e.g. "14.11.2023" in de-CH.
+func (d *Document) FormatDate(nano int64) string {
+	return d.internal.FormatDate(nano)
+}
+
+// RTL switches to right-to-left mode: text is reversed and the layout is
+// mirrored, so that lines start at the right margin and the columns of tables
+// follow each other from right to left.
+func (d *Document) RTL() *Document {
+	d.internal.RTL()
+	return d
+}
+
+// LTR switches back to left-to-right mode.
+func (d *Document) LTR() *Document {
+	d.internal.LTR()
+	return d
 }
 
 // --- Base Components ---
@@ -129,27 +261,37 @@ func (d *Document) AddText(text string) *TextComponent {
 	}
 }
 
-// AddHeader1 adds a level 1 header.
+// AddHeader1 adds a level 1 header using the Header1 style of the FontConfig.
 func (d *Document) AddHeader1(text string) *Document {
-	d.internal.SetFont("Arial", "B", 24)
-	d.internal.CellFormat(0, 10, text, "", 1, "L", false, 0, "")
-	d.internal.Ln(5)
-	return d
+	return d.addHeading(d.theme.Header1, text)
 }
 
-// AddHeader2 adds a level 2 header.
+// AddHeader2 adds a level 2 header using the Header2 style of the FontConfig.
 func (d *Document) AddHeader2(text string) *Document {
-	d.internal.SetFont("Arial", "B", 18)
-	d.internal.CellFormat(0, 10, text, "", 1, "L", false, 0, "")
-	d.internal.Ln(4)
-	return d
+	return d.addHeading(d.theme.Header2, text)
 }
 
-// AddHeader3 adds a level 3 header.
+// AddHeader3 adds a level 3 header using the Header3 style of the FontConfig.
 func (d *Document) AddHeader3(text string) *Document {
-	d.internal.SetFont("Arial", "B", 14)
-	d.internal.CellFormat(0, 10, text, "", 1, "L", false, 0, "")
-	d.internal.Ln(3)
+	return d.addHeading(d.theme.Header3, text)
+}
+
+func (d *Document) addHeading(style TextStyle, text string) *Document {
+	lineHt := d.applyTextStyle(style)
+	d.internal.CellFormat(0, lineHt, text, "", 1, "L", false, 0, "")
+	d.internal.Ln(style.SpaceAfter)
+	d.internal.SetTextColor(0, 0, 0)
+	return d
+}
+
+// AddParagraph adds a left aligned paragraph using the Body style of the
+// FontConfig. Use AddText for paragraphs that need their own styling.
+func (d *Document) AddParagraph(text string) *Document {
+	style := d.theme.Body
+	lineHt := d.applyTextStyle(style)
+	d.internal.MultiCell(0, lineHt, text, "", "L", false)
+	d.internal.Ln(style.SpaceAfter)
+	d.internal.SetTextColor(0, 0, 0)
 	return d
 }
 
@@ -159,12 +301,29 @@ func (d *Document) SpaceBefore(u float64) *Document {
 	return d
 }
 
+// AddSpace adds vertical space; it is an alias of SpaceBefore.
+func (d *Document) AddSpace(u float64) *Document {
+	return d.SpaceBefore(u)
+}
+
 // AddPage adds a new page.
 func (d *Document) AddPage() *Document {
 	d.internal.AddPage()
 	return d
 }
 
+// keepTogether starts a new page unless content of height h fits below the
+// current position, so that the blocks of the document keep a heading, a
+// label or a row with what follows it.
+func (d *Document) keepTogether(h float64) {
+	f := d.internal
+	_, pageHt := f.GetPageSize()
+	_, _, _, bMargin := f.GetMargins()
+	if auto, _ := f.GetAutoPageBreak(); auto && !f.AtPageTop() && f.GetY()+h > pageHt-bMargin {
+		f.AddPage()
+	}
+}
+
 // AddSeparator adds a horizontal line.
 func (d *Document) AddSeparator() *Document {
 	x := d.internal.GetX()
@@ -178,6 +337,176 @@ func (d *Document) AddSeparator() *Document {
 	return d
 }
 
+// AddLine draws a default Divider.
+func (d *Document) AddLine() *Document {
+	return d.Divider(DividerStyle{})
+}
+
+// Divider draws a horizontal rule across the text area using style. Unlike
+// AddSeparator, a divider is never the first element of a page and is kept
+// with the content that follows it: it is dropped when the current position
+// is at the top of a page or the rule does not fit before the page break, and
+// removed again when the following content breaks the page before anything
+// of it is drawn, so that the content starts the next page on its own.
+func (d *Document) Divider(style DividerStyle) *Document {
+	f := d.internal
+	before, after := style.SpaceBefore, style.SpaceAfter
+	if before == 0 && after == 0 {
+		before, after = 2, 3
+	}
+	_, pageHt := f.GetPageSize()
+	lMargin, _, rMargin, bMargin := f.GetMargins()
+	if f.AtPageTop() || f.GetY()+before+after > pageHt-bMargin {
+		return d
+	}
+
+	lineWd := style.Width
+	if lineWd == 0 {
+		lineWd = 0.2
+	}
+	f.KeepWithNext(func() {
+		prevWd := f.GetLineWidth()
+		r, g, b := f.GetDrawColor()
+		f.SetLineWidth(lineWd)
+		f.SetDrawColor(style.Color.R, style.Color.G, style.Color.B)
+		if style.Dashed {
+			f.SetDashPattern([]float64{2, 1}, 0)
+		}
+
+		pageWd, _ := f.GetPageSize()
+		y := f.GetY() + before
+		f.Line(lMargin, y, pageWd-rMargin, y)
+
+		if style.Dashed {
+			f.SetDashPattern([]float64{}, 0)
+		}
+		f.SetDrawColor(r, g, b)
+		f.SetLineWidth(prevWd)
+	})
+	f.Ln(before + after)
+	return d
+}
+
+// MarkAnchor remembers the current page and position under name. See
+// PlaceAt.
+func (d *Document) MarkAnchor(name string) *Document {
+	d.internal.MarkAnchor(name)
+	return d
+}
+
+// PlaceAt calls fn with the position set to the anchor recorded by MarkAnchor,
+// offset by dx and dy, even if more pages have been added since. The current
+// page and position are restored afterwards.
+func (d *Document) PlaceAt(name string, dx, dy float64, fn func()) *Document {
+	d.internal.PlaceAt(name, dx, dy, fn)
+	return d
+}
+
+// AddPlaceholder reserves a box of width w and height h at the current
+// position whose content is drawn later with FillPlaceholder.
+func (d *Document) AddPlaceholder(name string, w, h float64) *Document {
+	d.internal.AddPlaceholder(name, w, h)
+	return d
+}
+
+// FillPlaceholder draws the content of a box reserved by AddPlaceholder, on
+// whichever page it was reserved. fn receives the position and size of the
+// box; drawing is clipped to it.
+func (d *Document) FillPlaceholder(name string, fn func(x, y, w, h float64)) *Document {
+	d.internal.FillPlaceholder(name, fn)
+	return d
+}
+
+// AddTextField reserves a box of width w and height h at the current
+// position for the text bound to name by FillFields, aligned in it by
+// alignStr ("L", "C" or "R").
+func (d *Document) AddTextField(name string, w, h float64, alignStr string) *Document {
+	d.internal.AddTextField(name, w, h, alignStr)
+	return d
+}
+
+// AddImageSlot reserves a box of width w and height h at the current
+// position for the image bound to name by FillFields, scaled to fit in it.
+func (d *Document) AddImageSlot(name string, w, h float64) *Document {
+	d.internal.AddImageSlot(name, w, h)
+	return d
+}
+
+// FillFields fills the text fields and image slots with the values of data,
+// a record of a mail merge, and releases them so that the same fields can be
+// added again for the next record.
+func (d *Document) FillFields(data map[string]any) *Document {
+	d.internal.FillFields(data)
+	return d
+}
+
+// AddExclusionZone reserves an area (a letterhead logo, a pre-printed form
+// field) that text, tables and images flow around instead of printing over.
+// With allPages set the zone applies to every page, otherwise only to the
+// current one.
+func (d *Document) AddExclusionZone(x, y, w, h float64, allPages bool) *Document {
+	d.internal.AddExclusionZone(x, y, w, h, allPages)
+	return d
+}
+
+// Redact removes the text that overlaps an area of the current page (a
+// name, an account number) and paints the area black, so the text can no
+// longer be copied or extracted from the document.
+func (d *Document) Redact(x, y, w, h float64) *Document {
+	d.internal.Redact(x, y, w, h)
+	return d
+}
+
+// SetStationery sets the template drawn as the letterhead under the content
+// of the pages selected by scope, each time one of them is added, so it does
+// not have to be repeated after every AddPage. The template is created with
+// CreateTemplate or imported from another PDF file with ImportPage of the
+// engine.
+func (d *Document) SetStationery(scope fpdf.StationeryScope, tpl *fpdf.Template) *Document {
+	d.internal.SetStationery(tpl, scope)
+	return d
+}
+
+// Impose arranges the finished pages on larger sheets when the document is
+// written, several per sheet with fpdf.NUp or in signature order with
+// fpdf.Booklet.
+func (d *Document) Impose(imp fpdf.Imposition) *Document {
+	d.internal.Impose(imp)
+	return d
+}
+
+// DropBlankPages leaves pages to which no content was added, such as those
+// started before a conditional section that came out empty, out of the
+// document when it is written.
+func (d *Document) DropBlankPages() *Document {
+	d.internal.SetDropBlankPages(true)
+	return d
+}
+
+// PadToMultipleOf appends blank pages when the document is written until the
+// page count is a multiple of n, as needed for duplex or booklet printing.
+// stamp, if not empty, is printed in the middle of each added page.
+func (d *Document) PadToMultipleOf(n int, stamp string) *Document {
+	d.internal.PadToMultipleOf(n, stamp)
+	return d
+}
+
+// ClonePage appends a copy of page n of src, as rendered, with the fonts,
+// images and links it uses. Sections generated as separate documents, for
+// example concurrently, can be assembled this way.
+func (d *Document) ClonePage(src *Document, n int) *Document {
+	d.internal.ClonePage(src.internal, n)
+	return d
+}
+
+// AddLabels prints n labels on sheets laid out as s, such as fpdf.AveryL7160,
+// leaving the first skip labels of the first sheet blank. draw is called for
+// each label with its index and rectangle, and draws it with Engine().
+func (d *Document) AddLabels(s fpdf.LabelSheet, skip, n int, draw func(i int, x, y, w, h float64)) *Document {
+	d.internal.Labels(s, skip, n, draw)
+	return d
+}
+
 // AddImage adds an image by name (must be registered/loaded).
 func (d *Document) AddImage(name string) *ImageComponent {
 	return &ImageComponent{
@@ -188,13 +517,38 @@ func (d *Document) AddImage(name string) *ImageComponent {
 
 // --- Components Helpers ---
 
+// Align is the horizontal alignment of a text block.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+	AlignJustify
+)
+
+// flag returns the alignment string of fpdf for a.
+func (a Align) flag() string {
+	switch a {
+	case AlignCenter:
+		return "C"
+	case AlignRight:
+		return "R"
+	case AlignJustify:
+		return "J"
+	}
+	return "L"
+}
+
 type TextComponent struct {
-	doc   *Document
-	text  string
-	align string
-	color [3]int
-	bold  bool
-	size  float64
+	doc     *Document
+	text    string
+	align   string
+	color   [3]int
+	colored bool // color set with SetColor; otherwise the current one is used
+	bold    bool
+	italic  bool
+	size    float64
 }
 
 func (t *TextComponent) Bold() *TextComponent {
@@ -202,6 +556,28 @@ func (t *TextComponent) Bold() *TextComponent {
 	return t
 }
 
+func (t *TextComponent) Italic() *TextComponent {
+	t.italic = true
+	return t
+}
+
+// Size sets the font size of the text in points. A size that is not positive
+// sets the document error.
+func (t *TextComponent) Size(pt float64) *TextComponent {
+	if pt <= 0 {
+		t.doc.internal.SetErrorf("invalid font size: %.2f", pt)
+		return t
+	}
+	t.size = pt
+	return t
+}
+
+// Align sets the horizontal alignment of the text.
+func (t *TextComponent) Align(a Align) *TextComponent {
+	t.align = a.flag()
+	return t
+}
+
 func (t *TextComponent) AlignRight() *TextComponent {
 	t.align = "R"
 	return t
@@ -219,40 +595,67 @@ func (t *TextComponent) Justify() *TextComponent {
 
 func (t *TextComponent) SetColor(r, g, b int) *TextComponent {
 	t.color = [3]int{r, g, b}
+	t.colored = true
 	return t
 }
 
+// Color sets the color of the text, as SetColor does.
+func (t *TextComponent) Color(r, g, b int) *TextComponent {
+	return t.SetColor(r, g, b)
+}
+
+// Draw renders the text. The font style and the text color not set on the
+// component are those in effect, such as the ones selected with UseStyle.
 func (t *TextComponent) Draw() *Document {
+	f := t.doc.internal
+
 	// Apply styles
-	style := ""
-	if t.bold {
-		style = "B"
+	style := f.GetFontStyle()
+	if t.bold || t.italic {
+		style = ""
+		if t.bold {
+			style = FontBold
+		}
+		if t.italic {
+			style += FontItalic
+		}
 	}
 
 	// Default font if not set
-	family := t.doc.internal.GetFontFamily()
+	family := f.GetFontFamily()
 	if family == "" {
 		family = "Arial" // Fallback
 	}
 
 	size := t.size
 	if size == 0 {
-		pt, _ := t.doc.internal.GetFontSize()
+		pt, _ := f.GetFontSize()
 		size = pt
 	}
 
-	t.doc.internal.SetFont(family, style, size)
-	t.doc.internal.SetTextColor(t.color[0], t.color[1], t.color[2])
+	r, g, b := f.GetTextColor()
+	color := Color{r, g, b}
+	if t.colored {
+		color = Color{t.color[0], t.color[1], t.color[2]}
+	}
+
+	if t.doc.inline != nil {
+		t.doc.inline.addText(f, t.text, family, style, size, color)
+		return t.doc
+	}
+
+	f.SetFont(family, style, size)
+	f.SetTextColor(color.R, color.G, color.B)
 
 	align := "L"
 	if t.align != "" {
 		align = t.align
 	}
 
-	t.doc.internal.MultiCell(0, 5, t.text, "", align, false)
+	f.MultiCell(0, 5, t.text, "", align, false)
 
-	// Reset text color to black (optional, but good practice)
-	t.doc.internal.SetTextColor(0, 0, 0)
+	// Restore the text color in effect before the component
+	f.SetTextColor(r, g, b)
 
 	return t.doc
 }
@@ -281,6 +684,11 @@ func (i *ImageComponent) AlignCenter() *ImageComponent {
 }
 
 func (i *ImageComponent) Draw() *Document {
+	if i.doc.inline != nil {
+		i.doc.inline.addImage(i.doc.internal, i.name, i.width, i.height)
+		return i.doc
+	}
+
 	// Logic to center image if needed
 	x := i.doc.internal.GetX()
 	y := i.doc.internal.GetY()
@@ -314,82 +722,210 @@ func (i *ImageComponent) Draw() *Document {
 
 // --- Page Header/Footer ---
 
+// pageBand holds the left, center and right texts of a page header or footer.
+// Texts may contain the {page} and {nb} placeholders, see PageOfTotal.
+type pageBand struct {
+	doc    *Document
+	left   string
+	center string
+	right  string
+}
+
+func (b *pageBand) set(pos *string, text string) {
+	*pos = text
+	if strings.Contains(text, "{nb}") {
+		b.doc.internal.AliasNbPages("")
+	}
+}
+
+func (b *pageBand) draw(h float64) {
+	f := b.doc.internal
+	lMargin, _, _, _ := f.GetMargins()
+	page := Convert(f.PageNo()).String()
+	for _, cell := range [...]struct{ text, align string }{
+		{b.left, "L"}, {b.center, "C"}, {b.right, "R"},
+	} {
+		if cell.text == "" {
+			continue
+		}
+		f.SetX(lMargin)
+		f.CellFormat(0, h, strings.ReplaceAll(cell.text, "{page}", page), "", 0, cell.align, false, 0, "")
+	}
+}
+
 type PageHeader struct {
-	doc       *Document
-	leftText  string
-	rightText string
+	pageBand
 }
 
+// SetPageHeader returns the builder of the header repeated on every page.
+//
+//	doc.SetPageHeader().Left("ACME Corp.").Right(pdf.Date("02/01/2006"))
 func (d *Document) SetPageHeader() *PageHeader {
-	ph := &PageHeader{doc: d}
+	ph := &PageHeader{pageBand{doc: d}}
 	// Register the callback immediately, but it captures the struct so updates will reflect
 	d.internal.SetHeaderFunc(func() {
 		d.internal.SetY(10) // Standard header position
 		d.internal.SetFont("Arial", "I", 8)
-		if ph.leftText != "" {
-			d.internal.Cell(0, 10, ph.leftText)
-		}
-		if ph.rightText != "" {
-			// Align right
-			// Calculate width? Or use CellFormat with align R?
-			// Cell(0) goes to right margin.
-			d.internal.CellFormat(0, 10, ph.rightText, "", 0, "R", false, 0, "")
-		}
+		ph.draw(10)
 		d.internal.Ln(20) // Space after header
 	})
 	return ph
 }
 
-func (ph *PageHeader) SetLeftText(t string) *PageHeader {
-	ph.leftText = t
+// Left sets the text printed at the left margin.
+func (ph *PageHeader) Left(text string) *PageHeader {
+	ph.set(&ph.left, text)
 	return ph
 }
 
-func (ph *PageHeader) SetRightText(t string) *PageHeader {
-	ph.rightText = t
+// Center sets the text printed in the middle of the page.
+func (ph *PageHeader) Center(text string) *PageHeader {
+	ph.set(&ph.center, text)
+	return ph
+}
+
+// Right sets the text printed at the right margin.
+func (ph *PageHeader) Right(text string) *PageHeader {
+	ph.set(&ph.right, text)
 	return ph
 }
 
+func (ph *PageHeader) SetLeftText(t string) *PageHeader {
+	return ph.Left(t)
+}
+
+func (ph *PageHeader) SetRightText(t string) *PageHeader {
+	return ph.Right(t)
+}
+
 type PageFooter struct {
-	doc        *Document
-	centerText string
-	pageTotal  bool
+	pageBand
 }
 
+// SetPageFooter returns the builder of the footer repeated on every page.
+//
+//	doc.SetPageFooter().Center(pdf.PageOfTotal())
 func (d *Document) SetPageFooter() *PageFooter {
-	pf := &PageFooter{doc: d}
+	pf := &PageFooter{pageBand{doc: d}}
 	d.internal.SetFooterFunc(func() {
 		d.internal.SetY(-15) // Standard footer position
 		d.internal.SetFont("Arial", "I", 8)
-		if pf.centerText != "" {
-			d.internal.CellFormat(0, 10, pf.centerText, "", 0, "C", false, 0, "")
-		}
-		if pf.pageTotal {
-			// We can use alias for total pages if enabled
-			// d.internal.AliasNbPages("") should be called somewhere
-			pageStr := Convert(d.internal.PageNo()).String()
-			d.internal.CellFormat(0, 10, pageStr+" / {nb}", "", 0, "R", false, 0, "")
-		}
+		pf.draw(10)
 	})
 	return pf
 }
 
-func (pf *PageFooter) SetCenterText(t string) *PageFooter {
-	pf.centerText = t
+// Left sets the text printed at the left margin.
+func (pf *PageFooter) Left(text string) *PageFooter {
+	pf.set(&pf.left, text)
 	return pf
 }
 
-func (pf *PageFooter) WithPageTotal(align string) *PageFooter {
-	pf.pageTotal = true
-	pf.doc.internal.AliasNbPages("")
+// Center sets the text printed in the middle of the page.
+func (pf *PageFooter) Center(text string) *PageFooter {
+	pf.set(&pf.center, text)
+	return pf
+}
+
+// Right sets the text printed at the right margin.
+func (pf *PageFooter) Right(text string) *PageFooter {
+	pf.set(&pf.right, text)
 	return pf
 }
 
+func (pf *PageFooter) SetCenterText(t string) *PageFooter {
+	return pf.Center(t)
+}
+
+// WithPageTotal prints "page / total" at the given alignment: "L", "C" or
+// "R" (the default).
+func (pf *PageFooter) WithPageTotal(align string) *PageFooter {
+	switch align {
+	case "L":
+		return pf.Left(PageOfTotal())
+	case "C":
+		return pf.Center(PageOfTotal())
+	}
+	return pf.Right(PageOfTotal())
+}
+
+// PageNumber returns a header or footer text that prints the current page
+// number.
+func PageNumber() string {
+	return "{page}"
+}
+
+// PageOfTotal returns a header or footer text that prints the current page
+// number and the total number of pages, e.g. "3 / 12".
+func PageOfTotal() string {
+	return "{page} / {nb}"
+}
+
+// Date returns the current date formatted with a subset of the Go reference
+// layout: 2006 (year), 01 (month), 02 (day), 15 (hour), 04 (minute) and 05
+// (second), e.g. Date("02/01/2006"). The date is that of the local time zone,
+// or of the offset set with SetTimeZoneOffset of github.com/tinywasm/time.
+func Date(layout string) string {
+	return formatDate(time.Now(), layout)
+}
+
+func formatDate(nano int64, layout string) string {
+	local := time.FormatDateTime(nano) // 2006-01-02 15:04:05 in the local time zone
+	if len(local) < 19 {
+		return ""
+	}
+	return strings.NewReplacer(
+		"2006", local[0:4],
+		"01", local[5:7],
+		"02", local[8:10],
+		"15", local[11:13],
+		"04", local[14:16],
+		"05", local[17:19],
+	).Replace(layout)
+}
+
 func (d *Document) SetFont(family string, size float64) *Document {
 	d.internal.SetFont(family, "", size)
 	return d
 }
 
+// Font is a font family in one style, as returned by Document.Font, whose
+// glyph coverage can be inspected before text is rendered with it.
+type Font struct {
+	d      *Document
+	family string
+	style  string
+}
+
+// Font returns the font family in style, one of FontRegular, FontBold or
+// FontItalic. An empty family selects the current font.
+func (d *Document) Font(family, style string) Font {
+	return Font{d: d, family: family, style: style}
+}
+
+// HasGlyph reports whether the font has a glyph for r, so that unsupported
+// characters such as emoji can be replaced or set in a fallback font. It is
+// false if the font has not been loaded.
+func (f Font) HasGlyph(r rune) bool {
+	return f.d.internal.HasGlyph(f.family, f.style, r)
+}
+
+// MissingGlyphs returns the characters of text that the current font has no
+// glyph for, each once, in the order they appear. Control characters such as
+// newlines are not reported.
+func (d *Document) MissingGlyphs(text string) []rune {
+	return d.internal.MissingGlyphs("", "", text)
+}
+
+// SetFallbackFont sets the font family that prints the characters the current
+// font has no glyph for, such as emoji in user-generated content. The family
+// must have been loaded with RegisterFont and Load; a monochrome emoji font
+// such as Noto Emoji suits emoji. An empty family removes the fallback font.
+func (d *Document) SetFallbackFont(family string) *Document {
+	d.internal.SetFallbackFont(family, "")
+	return d
+}
+
 // --- Styles ---
 
 type Style struct {
@@ -399,6 +935,16 @@ type Style struct {
 	FontSize  float64
 }
 
+// DividerStyle configures the rule drawn by Divider. The zero value draws a
+// thin solid black line with a little space above and below it.
+type DividerStyle struct {
+	Color       Color
+	Width       float64 // line width; 0 means 0.2
+	Dashed      bool
+	SpaceBefore float64 // space above the rule
+	SpaceAfter  float64 // space below the rule
+}
+
 type Color struct {
 	R, G, B int
 }
@@ -0,0 +1,89 @@
+package pdf
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetResourceFS(t *testing.T) {
+	data, err := os.ReadFile("fpdf/image/logo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := NewDocument().SetResourceFS(fstest.MapFS{"assets/logo.png": {Data: data}})
+	doc.RegisterImage("logo", "assets/logo.png")
+	doc.Load(func(err error) {
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+	})
+	if doc.internal.GetImageInfo("logo") == nil {
+		t.Errorf("image not registered from the file system")
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+}
+
+func TestMissingGlyphs(t *testing.T) {
+	doc := NewDocument()
+	doc.SetFont("Arial", 12)
+	if !doc.Font("Arial", FontRegular).HasGlyph('A') {
+		t.Errorf("Arial has no glyph for A")
+	}
+	if doc.Font("Arial", FontRegular).HasGlyph('😀') {
+		t.Errorf("Arial has a glyph for an emoji")
+	}
+	if got := doc.MissingGlyphs("smile 😀"); len(got) != 1 || got[0] != '😀' {
+		t.Errorf("MissingGlyphs: got %q", got)
+	}
+}
+
+func TestEngine(t *testing.T) {
+	doc := NewDocument().AddPage()
+	doc.AddText("Before the grid").Draw()
+	engine := doc.Engine()
+	if engine != doc.internal {
+		t.Fatalf("Engine does not return the engine of the document")
+	}
+	grid := fpdf.NewGrid(engine.GetX(), engine.GetY(), 80, 40)
+	grid.Grid(engine)
+	doc.AddText("After the grid").Draw()
+	if engine.PageCount() != 1 {
+		t.Errorf("got %d pages, want 1", engine.PageCount())
+	}
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+}
+
+func TestTextBuilder(t *testing.T) {
+	doc := NewDocument().AddPage()
+	doc.AddText("Styled").Bold().Italic().Size(14).Color(200, 0, 0).Align(AlignCenter).Draw()
+	if err := doc.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	if pt, _ := doc.internal.GetFontSize(); pt != 14 {
+		t.Errorf("got font size %.1f, want 14", pt)
+	}
+	if style := doc.internal.GetFontStyle(); style != "BI" {
+		t.Errorf("got font style %q, want BI", style)
+	}
+
+	doc.AddText("Invalid").Size(-1).Draw()
+	doc.AddText("Skipped").Draw()
+	if err := doc.Err(); err == nil {
+		t.Errorf("no error for a negative font size")
+	}
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err == nil {
+		t.Errorf("document written despite the error")
+	}
+}
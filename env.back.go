@@ -11,9 +11,9 @@ import (
 // initIO inicializa las funciones de IO para entorno backend (no-wasm)
 func (d *Document) initIO() {
 	// Inicializar logger para backend usando fmt.Println
-	d.logger = func(message ...any) {
+	d.logger = funcLogger{print: func(message ...any) {
 		fmt.Println(message...)
-	}
+	}}
 }
 
 // writeFile escribe un archivo en el sistema de archivos usando os
@@ -5,19 +5,29 @@ package pdf
 
 import (
 	"encoding/base64"
-	"github.com/tinywasm/fetch"
-	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/pdf/fpdf"
 	"syscall/js"
 )
 
 // initIO inicializa las funciones de IO para entorno frontend (wasm)
 func (d *Document) initIO() {
 	// Inicializar logger para frontend usando console.log
-	d.logger = func(message ...any) {
-		console := js.Global().Get("console")
-		if !console.IsUndefined() {
-			console.Call("log", Translate(message...))
-		}
+	d.logger = consoleLogger{}
+}
+
+// consoleLogger es un Logger que escribe en la consola del navegador, con el
+// método de la consola de cada nivel
+type consoleLogger struct{}
+
+func (consoleLogger) Debug(msg string, args ...any) { consoleLog("debug", msg, args) }
+func (consoleLogger) Info(msg string, args ...any)  { consoleLog("info", msg, args) }
+func (consoleLogger) Warn(msg string, args ...any)  { consoleLog("warn", msg, args) }
+func (consoleLogger) Error(msg string, args ...any) { consoleLog("error", msg, args) }
+
+func consoleLog(method, msg string, args []any) {
+	console := js.Global().Get("console")
+	if !console.IsUndefined() {
+		console.Call(method, logLine(msg, args))
 	}
 }
 
@@ -47,34 +57,7 @@ func (d *Document) writeFile(filePath string, content []byte) error {
 
 // readFile lee un archivo usando fetch (para cargar recursos estáticos como fuentes e imágenes)
 func (d *Document) readFile(filePath string) ([]byte, error) {
-	ch := make(chan struct {
-		data []byte
-		err  error
-	}, 1)
-
-	fetch.Get(filePath).Send(func(resp *fetch.Response, err error) {
-		if err != nil {
-			ch <- struct {
-				data []byte
-				err  error
-			}{nil, err}
-			return
-		}
-		if resp.Status != 200 {
-			ch <- struct {
-				data []byte
-				err  error
-			}{nil, Errf("error fetching file %s: status %d", filePath, resp.Status)}
-			return
-		}
-		ch <- struct {
-			data []byte
-			err  error
-		}{resp.Body(), nil}
-	})
-
-	res := <-ch
-	return res.data, res.err
+	return fpdf.BrowserFetch(filePath)
 }
 
 // fileSize obtiene el tamaño de un archivo de localStorage
@@ -0,0 +1,154 @@
+package pdf
+
+import "maps"
+
+// TextStyle describes how a block of text is rendered by the Document
+// building blocks.
+type TextStyle struct {
+	Family     string  // font family; empty uses FontConfig.Family
+	Font       string  // FontBold, FontItalic or FontRegular
+	Size       float64 // font size in points
+	Color      Color
+	LineHeight float64 // height of each line; 0 derives it from Size
+	SpaceAfter float64 // vertical space added after the block
+}
+
+// FontConfig holds the text styles used by AddHeader1, AddHeader2,
+// AddHeader3, AddParagraph and the header row and body of tables. The
+// HeaderStyle of a table overrides the font, size and text color of
+// TableHeader.
+type FontConfig struct {
+	Family      string // default family for every style
+	Header1     TextStyle
+	Header2     TextStyle
+	Header3     TextStyle
+	Body        TextStyle
+	TableHeader TextStyle
+}
+
+// DefaultFontConfig returns the configuration a new Document starts with.
+func DefaultFontConfig() FontConfig {
+	return FontConfig{
+		Family:      "Arial",
+		Header1:     TextStyle{Font: FontBold, Size: 24, LineHeight: 10, SpaceAfter: 5},
+		Header2:     TextStyle{Font: FontBold, Size: 18, LineHeight: 10, SpaceAfter: 4},
+		Header3:     TextStyle{Font: FontBold, Size: 14, LineHeight: 10, SpaceAfter: 3},
+		Body:        TextStyle{Font: FontRegular, Size: 12, LineHeight: 5},
+		TableHeader: TextStyle{Font: FontBold, Size: 12},
+	}
+}
+
+// Names of the styles defined by DefaultTheme.
+const (
+	StyleTitle    = "Title"
+	StyleSubtitle = "Subtitle"
+	StyleCaption  = "Caption"
+	StyleCode     = "Code"
+	StyleQuote    = "Quote"
+)
+
+// Theme extends FontConfig with named text styles that can be selected with
+// UseStyle or AddStyledText. Replacing the theme restyles every building block
+// drawn afterwards.
+type Theme struct {
+	FontConfig
+	Styles map[string]TextStyle // named styles, e.g. StyleCaption
+}
+
+// DefaultTheme returns the theme a new Document starts with.
+func DefaultTheme() Theme {
+	return Theme{
+		FontConfig: DefaultFontConfig(),
+		Styles: map[string]TextStyle{
+			StyleTitle:    {Font: FontBold, Size: 28, LineHeight: 12, SpaceAfter: 6},
+			StyleSubtitle: {Font: FontItalic, Size: 16, Color: Color{R: 90, G: 90, B: 90}, LineHeight: 8, SpaceAfter: 4},
+			StyleCaption:  {Font: FontItalic, Size: 9, Color: Color{R: 100, G: 100, B: 100}, LineHeight: 4, SpaceAfter: 2},
+			StyleCode:     {Family: "Courier", Font: FontRegular, Size: 10, LineHeight: 4.5, SpaceAfter: 2},
+			StyleQuote:    {Font: FontItalic, Size: 12, Color: Color{R: 70, G: 70, B: 70}, LineHeight: 5, SpaceAfter: 3},
+		},
+	}
+}
+
+// SetFontConfig replaces the styles used by the Document building blocks.
+// Named styles of the current theme are kept.
+func (d *Document) SetFontConfig(cfg FontConfig) *Document {
+	d.theme.FontConfig = cfg
+	return d
+}
+
+// FontConfig returns the styles used by the Document building blocks.
+func (d *Document) FontConfig() FontConfig {
+	return d.theme.FontConfig
+}
+
+// SetTheme replaces the heading, body and named styles of the document.
+// The document keeps its own copy of the named styles.
+func (d *Document) SetTheme(theme Theme) *Document {
+	theme.Styles = maps.Clone(theme.Styles)
+	d.theme = theme
+	return d
+}
+
+// Theme returns a copy of the current theme; changing it does not affect the
+// document until it is passed to SetTheme.
+func (d *Document) Theme() Theme {
+	theme := d.theme
+	theme.Styles = maps.Clone(theme.Styles)
+	return theme
+}
+
+// UseStyle selects the font and text color of the named theme style for the
+// content that follows. An unknown name sets the document error.
+func (d *Document) UseStyle(name string) *Document {
+	if style, ok := d.style(name); ok {
+		d.applyTextStyle(style)
+	}
+	return d
+}
+
+// AddStyledText adds a left aligned paragraph using the named theme style.
+// An unknown name sets the document error.
+func (d *Document) AddStyledText(name, text string) *Document {
+	style, ok := d.style(name)
+	if !ok {
+		return d
+	}
+	lineHt := d.applyTextStyle(style)
+	d.internal.MultiCell(0, lineHt, text, "", "L", false)
+	d.internal.Ln(style.SpaceAfter)
+	d.internal.SetTextColor(0, 0, 0)
+	return d
+}
+
+// style looks up a named theme style, recording an error if it is missing.
+func (d *Document) style(name string) (TextStyle, bool) {
+	style, ok := d.theme.Styles[name]
+	if !ok {
+		d.internal.SetErrorf("undefined theme style: %s", name)
+	}
+	return style, ok
+}
+
+// applyTextStyle selects the font and color of s and returns the line height
+// to use with it.
+func (d *Document) applyTextStyle(s TextStyle) float64 {
+	family := s.Family
+	if family == "" {
+		family = d.theme.Family
+	}
+	if family == "" {
+		family = "Arial"
+	}
+	size := s.Size
+	if size == 0 {
+		size, _ = d.internal.GetFontSize()
+	}
+	d.internal.SetFont(family, s.Font, size)
+	d.internal.SetTextColor(s.Color.R, s.Color.G, s.Color.B)
+
+	if s.LineHeight > 0 {
+		return s.LineHeight
+	}
+	_, unitSize := d.internal.GetFontSize()
+	return unitSize * 1.2
+}
@@ -0,0 +1,99 @@
+package fpdf
+
+// anchorType records a named position in the document
+type anchorType struct {
+	page int
+	x, y float64
+}
+
+// MarkAnchor records the current page and position under name so that
+// content can later be placed relative to it with PlaceAt(). Marking an
+// existing name again replaces the previous position.
+func (f *Fpdf) MarkAnchor(name string) {
+	if f.err != nil {
+		return
+	}
+	if f.page == 0 {
+		f.errorf("MarkAnchor", "anchor %s requires a page; call AddPage first", name)
+		return
+	}
+	if f.inTemplate {
+		f.errorf("MarkAnchor", "anchor %s cannot be marked in a template", name)
+		return
+	}
+	if f.anchors == nil {
+		f.anchors = make(map[string]anchorType)
+	}
+	f.anchors[name] = anchorType{page: f.page, x: f.x, y: f.y}
+}
+
+// GetAnchor returns the page number and position recorded for name by
+// MarkAnchor(). ok is false if no such anchor exists.
+func (f *Fpdf) GetAnchor(name string) (page int, x, y float64, ok bool) {
+	a, ok := f.anchors[name]
+	return a.page, a.x, a.y, ok
+}
+
+// PlaceAt calls fn with the current page and position set to the anchor
+// recorded by MarkAnchor() under name, offset by dx and dy. This makes it
+// possible to draw content relative to an earlier position, for example a
+// total box aligned with the last row of a table, even after more pages have
+// been added. Automatic page breaks are suspended while fn runs, and the
+// current page and position are restored afterwards.
+//
+// When the anchor lies on another page, the current font, colors and line
+// width are carried over to it before fn is called.
+func (f *Fpdf) PlaceAt(name string, dx, dy float64, fn func()) {
+	if f.err != nil {
+		return
+	}
+	a, ok := f.anchors[name]
+	if !ok {
+		f.errorf("PlaceAt", "undefined anchor: %s", name)
+		return
+	}
+	x, y := f.x, f.y
+	f.onPage(a.page, func() {
+		f.x, f.y = a.x+dx, a.y+dy
+		fn()
+	})
+	f.x, f.y = x, y
+}
+
+// onPage calls fn with page n temporarily made the current page, so that
+// content can be added to a page that has already been left. Automatic page
+// breaks are suspended while fn runs. The dimensions of page n, which may
+// differ from those of the current page, are in effect while fn runs. The
+// graphics state is written to page n before fn is called, and written back
+// to the current page afterwards in case fn changed it.
+func (f *Fpdf) onPage(n int, fn func()) {
+	page := f.page
+	w, h, wPt, hPt := f.w, f.h, f.wPt, f.hPt
+	accept := f.acceptPageBreak
+	f.acceptPageBreak = func() bool { return false }
+	if n != page {
+		f.page = n
+		f.wPt, f.hPt = f.pageSizePt(n)
+		f.w, f.h = f.wPt/f.k, f.hPt/f.k
+		f.outState()
+	}
+	fn()
+	if n != page {
+		f.pageBody[n].used = true
+		f.page = page
+		f.w, f.h, f.wPt, f.hPt = w, h, wPt, hPt
+		f.outState()
+	}
+	f.acceptPageBreak = accept
+}
+
+// outState writes the current font, draw and fill colors and line width to
+// the current page.
+func (f *Fpdf) outState() {
+	if f.fontFamily != "" {
+		f.out("BT /F" + f.currentFont.i + " " + f.fmtF64(f.fontSizePt, f.prec.Text) + " Tf ET")
+	}
+	f.out(f.color.draw.str)
+	f.out(f.color.fill.str)
+	f.out(f.fmtF64(f.lineWidth*f.k, f.prec.Path) + " w")
+}
@@ -0,0 +1,212 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestPlaceAt(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.SetXY(30, 40)
+	pdf.MarkAnchor("total")
+	pdf.AddPage()
+	pdf.SetXY(50, 60)
+
+	var page int
+	var x, y float64
+	pdf.PlaceAt("total", 5, 10, func() {
+		page = pdf.PageNo()
+		x, y = pdf.GetXY()
+		pdf.Cell(40, 10, "Total")
+	})
+	if page != 1 || !floatEqual(x, 35) || !floatEqual(y, 50) {
+		t.Errorf("invalid placement: got page=%d (%v, %v), want page=1 (35, 50)", page, x, y)
+	}
+	x, y = pdf.GetXY()
+	if pdf.PageNo() != 2 || !floatEqual(x, 50) || !floatEqual(y, 60) {
+		t.Errorf("position not restored: got page=%d (%v, %v)", pdf.PageNo(), x, y)
+	}
+	if got, want := pdf.PageCount(), 2; got != want {
+		t.Errorf("unexpected page count: got=%d, want=%d", got, want)
+	}
+
+	pdf.PlaceAt("missing", 0, 0, func() {})
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for undefined anchor")
+	}
+}
+
+func TestPlaceAtRestoresState(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.MarkAnchor("note")
+	pdf.AddPage()
+	pdf.PlaceAt("note", 0, 0, func() {
+		pdf.SetFontSize(20)
+		pdf.SetDrawColor(255, 0, 0)
+	})
+	pdf.Line(10, 10, 50, 10)
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// The font size and color set by fn on page 1 are written to page 2 too,
+	// where drawing goes on with them
+	for _, s := range []string{"20.00 Tf ET", "1.000 0.000 0.000 RG"} {
+		if n := strings.Count(buf.String(), s); n != 2 {
+			t.Errorf("%q written %d times, want 2", s, n)
+		}
+	}
+}
+
+func TestPlaceAtPageSize(t *testing.T) {
+	pdf := NewDocPdfTest() // A4 portrait, millimeters
+	pdf.SetPrecision(fpdf.Precision{Text: 3, Path: 3, Curve: 5, Matrix: 5})
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.SetXY(10, 20)
+	pdf.MarkAnchor("top")
+	pdf.AddPageFormat("L", fpdf.PageSize{Wd: 420, Ht: 595})
+	_, pageH := pdf.GetPageSize()
+	pdf.PlaceAt("top", 0, 0, func() {
+		if _, h := pdf.GetPageSize(); math.Abs(h-297) > 1e-3 {
+			t.Errorf("page height of the anchor: got %v, want 297", h)
+		}
+		pdf.Line(10, 20, 50, 20)
+	})
+	if _, h := pdf.GetPageSize(); h != pageH {
+		t.Errorf("page height not restored: got %v, want %v", h, pageH)
+	}
+	// 20 mm from the top of an A4 page is 785.197 points from its bottom
+	content := pdf.PageContentString(1)
+	if !strings.Contains(content, "28.346 785.197 m 141.732 785.197 l S") {
+		t.Errorf("line not drawn from the top of page 1: %s", content)
+	}
+	if !strings.Contains(content, "12.000 Tf ET") || !strings.Contains(content, "0.567 w") {
+		t.Errorf("state not written with the precision of the document: %s", content)
+	}
+}
+
+func TestFillPlaceholder(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	left, top, _, _ := pdf.GetMargins()
+	pdf.Cell(30, 10, "Pages:")
+	pdf.AddPlaceholder("pages", 20, 10)
+	if x := pdf.GetX(); !floatEqual(x, left+50) {
+		t.Errorf("placeholder did not advance the position: got x=%v, want %v", x, left+50)
+	}
+	pdf.AddPage()
+	pdf.AddPage()
+	pdf.SetXY(40, 50)
+
+	var box [4]float64
+	pdf.FillPlaceholder("pages", func(x, y, w, h float64) {
+		box = [4]float64{x, y, w, h}
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(w, h, "3", "", 0, "R", false, 0, "")
+	})
+	if want := [4]float64{left + 30, top, 20, 10}; box != want {
+		t.Errorf("unexpected box: got %v, want %v", box, want)
+	}
+	if x, y := pdf.GetXY(); pdf.PageNo() != 3 || !floatEqual(x, 40) || !floatEqual(y, 50) {
+		t.Errorf("position not restored: page=%d (%v, %v)", pdf.PageNo(), x, y)
+	}
+
+	pdf.FillPlaceholder("missing", func(x, y, w, h float64) {})
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for undefined placeholder")
+	}
+}
+
+func TestFillFields(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	letter := func() {
+		pdf.SetXY(20, 20)
+		pdf.AddTextField("name", 60, 10, "L")
+		pdf.AddTextField("amount", 30, 10, "R")
+		pdf.SetXY(150, 20)
+		pdf.AddImageSlot("logo", 40, 20)
+		pdf.SetXY(20, 40)
+		pdf.AddTextField("note", 60, 10, "L")
+	}
+	records := []map[string]any{
+		{"name": "Ada Lovelace", "amount": 12.5, "logo": ImageFile("logo.png"), "unused": true},
+		{"name": "Alan Turing", "amount": 7},
+	}
+	for _, rec := range records {
+		pdf.AddPage()
+		letter()
+		pdf.FillFields(rec)
+	}
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"(Ada Lovelace)", "(12.5)", "(Alan Turing)", "(7)"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%s missing from the document", s)
+		}
+	}
+	if n := len(regexp.MustCompile(`cm /I\w+ Do`).FindAllString(out, -1)); n != 1 {
+		t.Errorf("image placed %d times, want once", n)
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.AddTextField("name", 10, 10, "L")
+	pdf.AddImageSlot("name", 10, 10)
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for a field defined twice")
+	}
+}
+
+func TestFillFieldsImageFit(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetXY(10, 10)
+	pdf.AddImageSlot("logo", 100, 20)
+	pdf.FillFields(map[string]any{"logo": ImageFile("logo.png")})
+	info := pdf.GetImageInfo(ImageFile("logo.png"))
+	if info == nil {
+		t.Fatalf("image not registered: %v", pdf.Error())
+	}
+	// The image fills the height of the slot and is centered horizontally
+	w := info.Width() * 20 / info.Height()
+	x, _ := pdf.GetXY()
+	if !floatEqual(x, 10+100) {
+		t.Errorf("position not restored: x=%v", x)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	m := regexp.MustCompile(`([0-9.]+) 0 0 ([0-9.]+) ([0-9.]+) [0-9.]+ cm /I`).FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("image not placed")
+	}
+	k := 72 / 25.4
+	for j, want := range []float64{w * k, 20 * k, (10 + (100-w)/2) * k} {
+		if got, _ := strconv.ParseFloat(m[j+1], 64); math.Abs(got-want) > 1e-4 {
+			t.Errorf("image not fitted in the slot: operand %d is %v, want %v", j+1, got, want)
+		}
+	}
+}
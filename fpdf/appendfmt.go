@@ -0,0 +1,149 @@
+package fpdf
+
+import "math"
+
+// The append functions format the operands of content stream operators into
+// a byte slice as the Sprintf verbs they stand for do, without allocating,
+// for the paths that write text in hot loops such as tables.
+//
+// appendFloat is the one formatter of real numbers: putF64(), fmtF64() and
+// the sprintf verb %.<prec>f give the same digits for the same value, so the
+// same content yields the same stream whichever path writes it. The
+// precisions follow the operands: 2 decimals for positions and lengths in
+// points, 3 for color components, opacities and word spacing, which range
+// over a small interval, and 5 for the coefficients of transformation
+// matrices and the control points of curves, whose errors are magnified.
+// Those of text positions, paths and matrices can be changed with
+// SetPrecision().
+
+// appendFloat appends v formatted as with the verb %.<prec>f, rounded half
+// away from zero.
+func appendFloat(b []byte, v float64, prec int) []byte {
+	switch {
+	case v != v:
+		return append(b, "NaN"...)
+	case v == 0:
+		b = append(b, '0')
+		if prec > 0 {
+			b = append(b, '.')
+			for j := 0; j < prec; j++ {
+				b = append(b, '0')
+			}
+		}
+		return b
+	case v > math.MaxFloat64:
+		return append(b, "+Inf"...)
+	case v < -math.MaxFloat64:
+		return append(b, "-Inf"...)
+	}
+	if v < 0 {
+		b = append(b, '-')
+		v = -v
+	}
+	mul := 1.0
+	for j := 0; j < prec; j++ {
+		mul *= 10
+	}
+	if v*mul >= 1<<63 {
+		// The value holds no fractional digits at this magnitude
+		return appendBigFloat(b, v, prec)
+	}
+	// Rounded half up, as tinywasm/fmt does
+	rounded := int64(v*mul + 0.5)
+	intPart := rounded
+	for j := 0; j < prec; j++ {
+		intPart /= 10
+	}
+	b = appendInt(b, intPart)
+	if prec > 0 {
+		b = append(b, '.')
+		frac := rounded - intPart*int64(mul)
+		var digits [20]byte
+		for j := prec - 1; j >= 0; j-- {
+			digits[j] = byte(frac%10) + '0'
+			frac /= 10
+		}
+		b = append(b, digits[:prec]...)
+	}
+	return b
+}
+
+// appendBigFloat appends v, an integral value too large for an int64, with
+// prec zero decimals.
+func appendBigFloat(b []byte, v float64, prec int) []byte {
+	var digits [320]byte
+	j := len(digits)
+	for v = math.Floor(v); v >= 1 && j > 0; v = math.Floor(v / 10) {
+		j--
+		digits[j] = byte(math.Mod(v, 10)) + '0'
+	}
+	b = append(b, digits[j:]...)
+	if prec > 0 {
+		b = append(b, '.')
+		for k := 0; k < prec; k++ {
+			b = append(b, '0')
+		}
+	}
+	return b
+}
+
+// appendFloats appends each of vals formatted as with the verb %.<prec>f and
+// followed by a space.
+func appendFloats(b []byte, prec int, vals ...float64) []byte {
+	for _, v := range vals {
+		b = append(appendFloat(b, v, prec), ' ')
+	}
+	return b
+}
+
+// appendInt appends v formatted as with the verb %d.
+func appendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		b = append(b, '-')
+		if v == math.MinInt64 {
+			return append(b, "9223372036854775808"...)
+		}
+		v = -v
+	}
+	var digits [20]byte
+	j := len(digits)
+	for {
+		j--
+		digits[j] = byte(v%10) + '0'
+		v /= 10
+		if v == 0 {
+			break
+		}
+	}
+	return append(b, digits[j:]...)
+}
+
+// appendEscaped appends c escaped for a literal string as escape() does.
+func appendEscaped(b []byte, c byte) []byte {
+	switch c {
+	case '\\', '(', ')':
+		return append(b, '\\', c)
+	case '\r':
+		return append(b, '\\', 'r')
+	}
+	return append(b, c)
+}
+
+// appendEncoded appends txtStr encoded for the current UTF-8 font and
+// escaped, as escape(encodeText(txtStr)) returns it when no character is
+// printed with the fallback font.
+func (f *Fpdf) appendEncoded(b []byte, txtStr string) []byte {
+	for _, r := range txtStr {
+		cid := max(f.currentFont.cid(r), 0)
+		b = appendEscaped(appendEscaped(b, byte(cid>>8)), byte(cid))
+	}
+	return b
+}
+
+// appendLine appends the operators that stroke a line from (x1, y1) to
+// (x2, y2), in points with prec decimal places, as "%.2f %.2f m %.2f %.2f l S "
+// does for 2 places.
+func appendLine(b []byte, prec int, x1, y1, x2, y2 float64) []byte {
+	b = append(appendFloats(b, prec, x1, y1), "m "...)
+	return append(appendFloats(b, prec, x2, y2), "l S "...)
+}
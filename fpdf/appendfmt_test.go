@@ -0,0 +1,47 @@
+package fpdf
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAppendFloat(t *testing.T) {
+	vals := []float64{0, math.Copysign(0, -1), 0.005, 0.015, -0.001, 2.675, 123.455, 870.6374998241978,
+		1e12, -1e12, math.NaN(), math.Inf(1), math.Inf(-1)}
+	r := rand.New(rand.NewSource(1))
+	for j := 0; j < 10000; j++ {
+		vals = append(vals, (r.Float64()-0.3)*2000, float64(r.Intn(200000))/1000-50)
+	}
+	f := New()
+	for _, prec := range []int{0, 2, 3, 5} {
+		verb := "%." + string(rune('0'+prec)) + "f"
+		for _, v := range vals {
+			want := sprintf(verb, v)
+			if got := string(appendFloat(nil, v, prec)); got != want {
+				t.Fatalf("%s of %v: got %s, want %s", verb, v, got, want)
+			}
+			if got := f.fmtF64(v, prec); got != want {
+				t.Fatalf("fmtF64(%v, %d): got %s, want %s", v, prec, got, want)
+			}
+		}
+	}
+	for _, c := range []struct {
+		v    float64
+		prec int
+		want string
+	}{
+		{1e15, 5, "1000000000000000.00000"},
+		{-3e16, 3, "-30000000000000000.000"},
+		{1e19, 0, "10000000000000000000"},
+	} {
+		if got := string(appendFloat(nil, c.v, c.prec)); got != c.want {
+			t.Errorf("appendFloat(%v, %d): got %s, want %s", c.v, c.prec, got, c.want)
+		}
+	}
+	for _, v := range []int64{0, 7, -42, 1234567890} {
+		if got, want := string(appendInt(nil, v)), sprintf("%d", v); got != want {
+			t.Errorf("%%d of %d: got %s, want %s", v, got, want)
+		}
+	}
+}
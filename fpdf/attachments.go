@@ -39,7 +39,7 @@ func checksum(data []byte) string {
 func (f *Fpdf) writeCompressedFileObject(content []byte) {
 	lenUncompressed := len(content)
 	sum := checksum(content)
-	mem := xmem.compress(content)
+	mem := f.compressStream(content)
 	defer mem.release()
 	compressed := mem.bytes()
 	lenCompressed := len(compressed)
@@ -82,6 +82,9 @@ func (f *Fpdf) SetAttachments(as []Attachment) {
 // embed current attachments. store object numbers
 // for later use by getEmbeddedFiles()
 func (f *Fpdf) putAttachments() {
+	if f.sanitize {
+		return
+	}
 	for i, a := range f.attachments {
 		f.embed(&a)
 		f.attachments[i] = a
@@ -90,6 +93,9 @@ func (f *Fpdf) putAttachments() {
 
 // return /EmbeddedFiles tree name catalog entry.
 func (f Fpdf) getEmbeddedFiles() string {
+	if f.sanitize {
+		return "<< /Names [] >>"
+	}
 	names := make([]string, len(f.attachments))
 	for i, as := range f.attachments {
 		names[i] = Sprintf("(Attachement%d) %d 0 R ", i+1, as.objectNumber)
@@ -120,9 +126,10 @@ func (f *Fpdf) AddAttachmentAnnotation(a *Attachment, x, y, w, h float64) {
 	if a == nil {
 		return
 	}
+	xPt, yPt := f.pagePoint(f.page, x, y)
 	f.pageAttachments[f.page] = append(f.pageAttachments[f.page], annotationAttach{
 		Attachment: a,
-		x:          x * f.k, y: f.hPt - y*f.k, w: w * f.k, h: h * f.k,
+		x:          xPt, y: yPt, w: w * f.k, h: h * f.k,
 	})
 }
 
@@ -130,6 +137,9 @@ func (f *Fpdf) AddAttachmentAnnotation(a *Attachment, x, y, w, h float64) {
 // for later use by putAttachmentAnnotationLinks(), which is
 // called for each page.
 func (f *Fpdf) putAnnotationsAttachments() {
+	if f.sanitize {
+		return
+	}
 	// avoid duplication
 	m := map[*Attachment]bool{}
 	for _, l := range f.pageAttachments {
@@ -142,9 +152,14 @@ func (f *Fpdf) putAnnotationsAttachments() {
 	}
 }
 
-func (f *Fpdf) putAttachmentAnnotationLinks(out *fmtBuffer, page int) {
+// putAttachmentAnnotationLinks writes the file attachment annotations of
+// page, moved by dx and dy points, to out.
+func (f *Fpdf) putAttachmentAnnotationLinks(out *fmtBuffer, page int, dx, dy float64) {
+	if f.sanitize {
+		return
+	}
 	for _, an := range f.pageAttachments[page] {
-		x1, y1, x2, y2 := an.x, an.y, an.x+an.w, an.y-an.h
+		x1, y1, x2, y2 := an.x+dx, an.y+dy, an.x+dx+an.w, an.y+dy-an.h
 		as := Sprintf("<< /Type /XObject /Subtype /Form /BBox [%.2f %.2f %.2f %.2f] /Length 0 >>",
 			x1, y1, x2, y2)
 		as += "\nstream\nendstream"
@@ -0,0 +1,76 @@
+package fpdf
+
+import (
+	"bytes"
+	"math"
+)
+
+// maxPageHtPt is the largest page height in points that PDF viewers accept.
+const maxPageHtPt = 14400
+
+// SetRollMode controls whether all pages added from now on grow with their
+// content like pages whose PageSize has AutoHt set, so that the document
+// comes out as a continuous roll for label and ticket printers. The width of
+// the roll is the width of the page size passed to New() or AddPageFormat().
+//
+// Automatic page breaks only occur when the content reaches the largest page
+// height supported by PDF, 14400 points (about 5 m); the roll then continues
+// on a new page. AddPage() still starts a new page at any time. The default
+// is false.
+func (f *Fpdf) SetRollMode(on bool) {
+	f.rollMode = on
+}
+
+// growPage gives the current page its final height if it grows with its
+// content. It is called when the page ends, before the footer is written.
+func (f *Fpdf) growPage() {
+	if !f.autoHt {
+		return
+	}
+	if _, boxed := f.pageOrigins[f.page]; boxed {
+		return
+	}
+	h := math.Min(f.y+f.bMargin, maxPageHtPt/f.k)
+	// The content drawn so far is positioned from the top of a page of height
+	// f.h: move it to the top of the final page.
+	dy := (h - f.h) * f.k
+	prefix := "1 0 0 1 0 " + f.fmtF64(dy, f.prec.Matrix) + " cm\n"
+	page := bytes.NewBufferString(prefix)
+	page.Write(f.pages[f.page].Bytes())
+	f.pages[f.page] = page
+	body := &f.pageBody[f.page]
+	body.start += len(prefix)
+	if body.end >= 0 {
+		body.end += len(prefix)
+	}
+	for j := range f.textObjs[f.page] {
+		f.textObjs[f.page][j].start += len(prefix)
+		f.textObjs[f.page][j].end += len(prefix)
+	}
+	for j := range f.pageLinks[f.page] {
+		f.pageLinks[f.page][j].y += dy
+	}
+	for j := range f.pageAttachments[f.page] {
+		f.pageAttachments[f.page][j].y += dy
+	}
+	for j := range f.patternList {
+		if f.patternList[j].page == f.page {
+			f.patternList[j].matrix[5] += dy
+		}
+	}
+	// Undo the move for the footer, which is positioned on the final page.
+	f.put("1 0 0 1 0 ")
+	f.putF64s(f.prec.Matrix, -dy)
+	f.put("cm\n")
+	f.h = h
+	f.hPt = h * f.k
+	f.pageSizes[f.page] = PageSize{Wd: f.wPt, Ht: f.hPt, AutoHt: true}
+}
+
+// autoHtBreakTrigger limits automatic page breaks on pages that grow with
+// their content to content reaching the largest page height.
+func (f *Fpdf) autoHtBreakTrigger() {
+	if f.autoHt {
+		f.pageBreakTrigger = maxPageHtPt/f.k - f.bMargin
+	}
+}
@@ -0,0 +1,86 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestAutoHtPage(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 10)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-20)
+		pdf.Cell(0, 10, "Thank you")
+	})
+	pdf.AddPageFormat(fpdf.Portrait, fpdf.PageSize{Wd: 226, Ht: 100, AutoHt: true})
+	for j := 0; j < 30; j++ {
+		// Would break the page several times on a page 100pt high.
+		pdf.Cell(0, 10, "Item")
+		pdf.Ln(10)
+	}
+	pdf.LinkString(10, 10, 50, 10, "https://example.com")
+
+	if got := pdf.PageCount(); got != 1 {
+		t.Fatalf("invalid page count: got=%d, want=1", got)
+	}
+	pdf.AddPageFormat(fpdf.Portrait, fpdf.PageSize{Wd: 226, Ht: 100})
+	if _, h := pdf.GetPageSize(); h != 100 {
+		t.Errorf("invalid height of the page following the receipt: got=%v, want=100", h)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// 30 lines of 10pt below a 10pt top margin, plus the bottom margin.
+	for _, s := range []string{
+		"/MediaBox [0 0 226.00 320.00]",
+		"1 0 0 1 0 220.00000 cm",
+		"1 0 0 1 0 -220.00000 cm",
+		"/Rect [10.00 310.00 60.00 300.00]",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	if w, h, _ := pdf.PageSize(1); w != 226 || h != 320 {
+		t.Errorf("invalid size of the receipt page: got=%v x %v, want=226 x 320", w, h)
+	}
+}
+
+func TestSetRollMode(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 10)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetRollMode(true)
+	pdf.AddPageFormat(fpdf.Portrait, fpdf.PageSize{Wd: 164, Ht: 400})
+	for j := 0; j < 1500; j++ {
+		pdf.Cell(0, 10, "Label")
+		pdf.Ln(10)
+	}
+	pdf.Close()
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := pdf.PageCount(); got != 2 {
+		t.Fatalf("invalid page count: got=%d, want=2", got)
+	}
+	// The first segment ends where the content reaches the height limit.
+	if w, h, _ := pdf.PageSize(1); w != 164 || h != 14400 {
+		t.Errorf("invalid size of the first segment: got=%v x %v, want=164 x 14400", w, h)
+	}
+	// 1500 lines less the 1438 of the first segment.
+	if _, h, _ := pdf.PageSize(2); h != 640 {
+		t.Errorf("invalid height of the second segment: got=%v, want=640", h)
+	}
+}
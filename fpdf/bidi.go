@@ -0,0 +1,491 @@
+package fpdf
+
+import "unicode"
+
+// Bidirectional character types of the Unicode Bidirectional Algorithm
+// (UAX #9).
+const (
+	bidiL   = iota // left-to-right
+	bidiR          // right-to-left
+	bidiAL         // Arabic letter
+	bidiEN         // European number
+	bidiES         // European separator
+	bidiET         // European terminator
+	bidiAN         // Arabic number
+	bidiCS         // common separator
+	bidiNSM        // nonspacing mark
+	bidiBN         // boundary neutral
+	bidiB          // paragraph separator
+	bidiS          // segment separator
+	bidiWS         // white space
+	bidiON         // other neutral
+	bidiLRE        // left-to-right embedding
+	bidiLRO        // left-to-right override
+	bidiRLE        // right-to-left embedding
+	bidiRLO        // right-to-left override
+	bidiPDF        // pop directional format
+	bidiLRI        // left-to-right isolate
+	bidiRLI        // right-to-left isolate
+	bidiFSI        // first strong isolate
+	bidiPDI        // pop directional isolate
+)
+
+// bidiMaxDepth is the maximum embedding level.
+const bidiMaxDepth = 125
+
+// bidiMirrors lists the characters that are replaced with their mirror image
+// in right-to-left text.
+var bidiMirrors = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{', '<': '>', '>': '<',
+	'«': '»', '»': '«', '‹': '›', '›': '‹', '⁅': '⁆', '⁆': '⁅', '≤': '≥', '≥': '≤',
+}
+
+// bidiClass returns the bidirectional type of r. Characters of scripts written
+// from right to left are recognized by their block.
+func bidiClass(r rune) int {
+	switch r {
+	case 0x200E:
+		return bidiL
+	case 0x200F:
+		return bidiR
+	case 0x061C:
+		return bidiAL
+	case 0x202A:
+		return bidiLRE
+	case 0x202B:
+		return bidiRLE
+	case 0x202C:
+		return bidiPDF
+	case 0x202D:
+		return bidiLRO
+	case 0x202E:
+		return bidiRLO
+	case 0x2066:
+		return bidiLRI
+	case 0x2067:
+		return bidiRLI
+	case 0x2068:
+		return bidiFSI
+	case 0x2069:
+		return bidiPDI
+	case '\t', 0x0B, 0x1F:
+		return bidiS
+	case '\n', '\r', 0x1C, 0x1D, 0x1E, 0x85, 0x2029:
+		return bidiB
+	case '+', '-', 0x2212:
+		return bidiES
+	case '#', '$', '%', 0xA2, 0xA3, 0xA4, 0xA5, 0xB0, 0xB1, 0x066A:
+		return bidiET
+	case ',', '.', '/', ':', 0xA0, 0x060C, 0x202F:
+		return bidiCS
+	case 0xAD, 0x200B, 0x200C, 0x200D, 0x2060, 0xFEFF:
+		return bidiBN
+	}
+	switch {
+	case r >= '0' && r <= '9', r >= 0x06F0 && r <= 0x06F9:
+		return bidiEN
+	case r >= 0x0660 && r <= 0x0669, r == 0x066B, r == 0x066C:
+		return bidiAN
+	case r >= 0x20A0 && r <= 0x20CF, r >= 0x2030 && r <= 0x2034:
+		return bidiET
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return bidiNSM
+	case unicode.IsSpace(r):
+		return bidiWS
+	case r >= 0x0590 && r <= 0x05FF, r >= 0x07C0 && r <= 0x085F, r >= 0xFB1D && r <= 0xFB4F,
+		r >= 0x10800 && r <= 0x10FFF, r >= 0x1E800 && r <= 0x1EFFF:
+		return bidiR
+	case r >= 0x0600 && r <= 0x07BF, r >= 0x0860 && r <= 0x08FF, r >= 0xFB50 && r <= 0xFDFF,
+		r >= 0xFE70 && r <= 0xFEFE:
+		return bidiAL
+	case unicode.IsLetter(r), unicode.IsDigit(r), unicode.In(r, unicode.Mc):
+		return bidiL
+	}
+	return bidiON
+}
+
+// hasRTL returns whether text contains characters written from right to left
+// or explicit directional formatting characters.
+func hasRTL(text string) bool {
+	for _, r := range text {
+		if r < 0x0590 {
+			continue
+		}
+		switch bidiClass(r) {
+		case bidiR, bidiAL, bidiAN, bidiRLE, bidiRLO, bidiRLI, bidiFSI:
+			return true
+		}
+	}
+	return false
+}
+
+// visualOrder returns the line text in the order in which its characters are
+// displayed, following the Unicode Bidirectional Algorithm with a paragraph
+// embedding level of 1 if rtl is set and 0 otherwise: runs of left-to-right
+// text, such as Latin words and numbers, keep their order within right-to-left
+// text and the other way round, embeddings and overrides are applied, and
+// brackets in right-to-left runs are mirrored. The directional formatting
+// characters are removed.
+//
+// Isolates are processed as embeddings, and each level run is resolved on its
+// own rather than as part of an isolating run sequence.
+func visualOrder(text string, rtl bool) string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return text
+	}
+	para := 0
+	if rtl {
+		para = 1
+	}
+	types := make([]int, len(runes))
+	for j, r := range runes {
+		types[j] = bidiClass(r)
+	}
+	orig := make([]int, len(types))
+	copy(orig, types)
+	levels := bidiExplicitLevels(types, para)
+	bidiResolveRuns(types, levels, para)
+
+	// L1: separators and trailing white space go back to the paragraph level.
+	trailing := true
+	for j := len(runes) - 1; j >= 0; j-- {
+		switch orig[j] {
+		case bidiS, bidiB:
+			levels[j] = para
+			trailing = true
+		case bidiWS, bidiBN, bidiLRE, bidiRLE, bidiLRO, bidiRLO, bidiPDF,
+			bidiLRI, bidiRLI, bidiFSI, bidiPDI:
+			if trailing {
+				levels[j] = para
+			}
+		default:
+			trailing = false
+		}
+	}
+
+	// L2: reverse every sequence at or above each odd level, from the highest.
+	highest, lowestOdd := 0, bidiMaxDepth+2
+	for _, l := range levels {
+		highest = max(highest, l)
+		if l%2 == 1 && l < lowestOdd {
+			lowestOdd = l
+		}
+	}
+	idx := make([]int, len(runes))
+	for j := range idx {
+		idx[j] = j
+	}
+	for l := highest; l >= lowestOdd; l-- {
+		for j := 0; j < len(idx); {
+			if levels[idx[j]] < l {
+				j++
+				continue
+			}
+			k := j
+			for k < len(idx) && levels[idx[k]] >= l {
+				k++
+			}
+			for a, b := j, k-1; a < b; a, b = a+1, b-1 {
+				idx[a], idx[b] = idx[b], idx[a]
+			}
+			j = k
+		}
+	}
+
+	out := make([]rune, 0, len(runes))
+	for _, j := range idx {
+		switch orig[j] {
+		case bidiLRE, bidiRLE, bidiLRO, bidiRLO, bidiPDF, bidiLRI, bidiRLI, bidiFSI, bidiPDI:
+			continue
+		}
+		if orig[j] == bidiBN && runes[j] != 0xAD {
+			continue
+		}
+		r := runes[j]
+		// L4: mirrored glyphs
+		if m, ok := bidiMirrors[r]; ok && levels[j]%2 == 1 {
+			r = m
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// bidiExplicitLevels applies the explicit embeddings, overrides and isolates
+// of types (rules X1 to X10) and returns the embedding level of each
+// character. Overridden characters get the type of the override, and the
+// formatting characters are changed to BN, or to ON for isolates.
+func bidiExplicitLevels(types []int, para int) []int {
+	type entry struct {
+		level    int
+		override int // bidiON for none
+		isolate  bool
+	}
+	stack := []entry{{level: para, override: bidiON}}
+	levels := make([]int, len(types))
+	for j, t := range types {
+		top := stack[len(stack)-1]
+		switch t {
+		case bidiRLE, bidiLRE, bidiRLO, bidiLRO, bidiRLI, bidiLRI, bidiFSI:
+			rtl := t == bidiRLE || t == bidiRLO || t == bidiRLI
+			if t == bidiFSI {
+				rtl = bidiFirstStrongRTL(types[j+1:])
+			}
+			level := top.level + 1
+			if rtl {
+				level = (top.level + 1) | 1
+			} else if level%2 == 1 {
+				level++
+			}
+			isolate := t == bidiRLI || t == bidiLRI || t == bidiFSI
+			levels[j] = top.level
+			if isolate {
+				types[j] = bidiON
+				if top.override != bidiON {
+					types[j] = top.override
+				}
+			} else {
+				types[j] = bidiBN
+			}
+			if level <= bidiMaxDepth {
+				override := bidiON
+				switch t {
+				case bidiRLO:
+					override = bidiR
+				case bidiLRO:
+					override = bidiL
+				}
+				stack = append(stack, entry{level: level, override: override, isolate: isolate})
+			}
+		case bidiPDF:
+			levels[j] = top.level
+			types[j] = bidiBN
+			if len(stack) > 1 && !top.isolate {
+				stack = stack[:len(stack)-1]
+			}
+		case bidiPDI:
+			// Close the embeddings up to the last isolate.
+			for n := len(stack) - 1; n > 0; n-- {
+				if stack[n].isolate {
+					stack = stack[:n]
+					break
+				}
+			}
+			top = stack[len(stack)-1]
+			levels[j] = top.level
+			types[j] = bidiON
+			if top.override != bidiON {
+				types[j] = top.override
+			}
+		case bidiB:
+			stack = stack[:1]
+			levels[j] = para
+		default:
+			levels[j] = top.level
+			if top.override != bidiON && t != bidiBN {
+				types[j] = top.override
+			}
+		}
+	}
+	return levels
+}
+
+// bidiFirstStrongRTL returns whether the first strong character of types,
+// before the isolate that begins them is closed, is written from right to
+// left.
+func bidiFirstStrongRTL(types []int) bool {
+	depth := 0
+	for _, t := range types {
+		switch t {
+		case bidiLRI, bidiRLI, bidiFSI:
+			depth++
+		case bidiPDI:
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case bidiL:
+			if depth == 0 {
+				return false
+			}
+		case bidiR, bidiAL:
+			if depth == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bidiResolveRuns resolves the weak and neutral types of each run of
+// characters at the same level and raises the levels accordingly (rules W1 to
+// I2).
+func bidiResolveRuns(types, levels []int, para int) {
+	// Boundary neutrals take the level of the preceding character, so that
+	// they do not split runs.
+	for j := range types {
+		if types[j] == bidiBN && j > 0 {
+			levels[j] = levels[j-1]
+		}
+	}
+	explicit := make([]int, len(levels))
+	copy(explicit, levels)
+	for start := 0; start < len(types); {
+		end := start
+		for end < len(types) && explicit[end] == explicit[start] {
+			end++
+		}
+		prev, next := para, para
+		if start > 0 {
+			prev = explicit[start-1]
+		}
+		if end < len(types) {
+			next = explicit[end]
+		}
+		sos := bidiDirection(max(prev, explicit[start]))
+		eos := bidiDirection(max(next, explicit[start]))
+		bidiResolveRun(types[start:end], levels[start:end], sos, eos)
+		start = end
+	}
+}
+
+// bidiDirection returns the strong type of embedding level level.
+func bidiDirection(level int) int {
+	if level%2 == 1 {
+		return bidiR
+	}
+	return bidiL
+}
+
+// bidiResolveRun resolves the types of a run at a single level, between the
+// types sos and eos, and sets the final level of each character.
+func bidiResolveRun(types, levels []int, sos, eos int) {
+	n := len(types)
+	// W1: nonspacing marks take the type of the previous character.
+	prev := sos
+	for j, t := range types {
+		switch t {
+		case bidiNSM:
+			types[j] = prev
+		case bidiBN:
+		default:
+			prev = t
+		}
+	}
+	// W2: European numbers after an Arabic letter are Arabic numbers.
+	// W3: Arabic letters are right-to-left.
+	strong := sos
+	for j, t := range types {
+		switch t {
+		case bidiL, bidiR, bidiAL:
+			strong = t
+		case bidiEN:
+			if strong == bidiAL {
+				types[j] = bidiAN
+			}
+		}
+	}
+	for j, t := range types {
+		if t == bidiAL {
+			types[j] = bidiR
+		}
+	}
+	// W4: single separators between numbers of the same kind.
+	for j := 1; j < n-1; j++ {
+		a, b := types[j-1], types[j+1]
+		switch {
+		case types[j] == bidiES && a == bidiEN && b == bidiEN:
+			types[j] = bidiEN
+		case types[j] == bidiCS && a == bidiEN && b == bidiEN:
+			types[j] = bidiEN
+		case types[j] == bidiCS && a == bidiAN && b == bidiAN:
+			types[j] = bidiAN
+		}
+	}
+	// W5: terminators next to European numbers.
+	for j := 0; j < n; j++ {
+		if types[j] != bidiET {
+			continue
+		}
+		k := j
+		for k < n && (types[k] == bidiET || types[k] == bidiBN) {
+			k++
+		}
+		if (j > 0 && types[j-1] == bidiEN) || (k < n && types[k] == bidiEN) {
+			for m := j; m < k; m++ {
+				types[m] = bidiEN
+			}
+		}
+		j = k
+	}
+	// W6: remaining separators and terminators are neutral.
+	for j, t := range types {
+		switch t {
+		case bidiES, bidiET, bidiCS:
+			types[j] = bidiON
+		}
+	}
+	// W7: European numbers after left-to-right text are left-to-right.
+	strong = sos
+	for j, t := range types {
+		switch t {
+		case bidiL, bidiR:
+			strong = t
+		case bidiEN:
+			if strong == bidiL {
+				types[j] = bidiL
+			}
+		}
+	}
+	// N1, N2: neutrals take the direction of the text around them if it is
+	// the same on both sides, numbers counting as right-to-left, and the
+	// direction of the embedding otherwise.
+	embedding := bidiDirection(levels[0])
+	strongOf := func(t int) int {
+		switch t {
+		case bidiL:
+			return bidiL
+		case bidiR, bidiEN, bidiAN:
+			return bidiR
+		}
+		return -1
+	}
+	for j := 0; j < n; j++ {
+		if strongOf(types[j]) >= 0 {
+			continue
+		}
+		k := j
+		for k < n && strongOf(types[k]) < 0 {
+			k++
+		}
+		before, after := sos, eos
+		if j > 0 {
+			before = strongOf(types[j-1])
+		}
+		if k < n {
+			after = strongOf(types[k])
+		}
+		dir := embedding
+		if before == after {
+			dir = before
+		}
+		for m := j; m < k; m++ {
+			types[m] = dir
+		}
+		j = k
+	}
+	// I1, I2: implicit levels.
+	for j, t := range types {
+		if levels[j]%2 == 0 {
+			switch t {
+			case bidiR:
+				levels[j]++
+			case bidiAN, bidiEN:
+				levels[j] += 2
+			}
+		} else if t == bidiL || t == bidiEN || t == bidiAN {
+			levels[j]++
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package fpdf
+
+import "testing"
+
+func TestVisualOrder(t *testing.T) {
+	for _, c := range []struct {
+		text string
+		rtl  bool
+		want string
+	}{
+		// Right-to-left text is reversed.
+		{"שלום", true, "םולש"},
+		// Latin words and numbers keep their order in right-to-left text.
+		{"שלום world 123", true, "world 123 םולש"},
+		{"מחיר: 1,250.50 ש״ח", true, "ח״ש 1,250.50 :ריחמ"},
+		// Right-to-left words in left-to-right text.
+		{"the word שלום means peace", false, "the word םולש means peace"},
+		{"version 2 של התוכנה", false, "version 2 הנכותה לש"},
+		// Brackets are mirrored in right-to-left runs.
+		{"שלום (עולם)", true, "(םלוע) םולש"},
+		// An embedding makes a right-to-left phrase of left-to-right text.
+		{"a ‫בג דה‬ b", false, "a הד גב b"},
+		// An override reverses Latin letters.
+		{"‮abc‬", false, "cba"},
+		{"plain text", false, "plain text"},
+		{"plain text", true, "plain text"},
+	} {
+		if got := visualOrder(c.text, c.rtl); got != c.want {
+			t.Errorf("visualOrder(%q, %v): got %q, want %q", c.text, c.rtl, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package fpdf
+
+// pageBodyType delimits the content added to a page between its header and
+// its footer.
+type pageBodyType struct {
+	start, end int  // offsets in the page buffer; end is -1 until the footer is written
+	used       bool // content has been added after the footer, or the page is padding
+	cloned     bool // copied from another document, without header or footer
+}
+
+// keepNextType delimits the content drawn on a page by KeepWithNext().
+type keepNextType struct {
+	page       int        // page of the content, 0 if none
+	start, end int        // offsets of the content in the page buffer
+	extent     extentType // extent of the page before the content
+	extended   bool       // whether the page had an extent before the content
+}
+
+// KeepWithNext calls fn to draw content that belongs with whatever follows
+// it, such as a rule separating two sections. If the page is broken before
+// anything else is written to it, the content drawn by fn is removed from the
+// bottom of the page instead of being left there on its own, and the content
+// that follows begins the next page. fn must restore the font, colors and
+// line width it changes.
+func (f *Fpdf) KeepWithNext(fn func()) {
+	if f.err != nil || f.page == 0 {
+		return
+	}
+	kn := keepNextType{page: f.page, start: f.pages[f.page].Len()}
+	kn.extent, kn.extended = f.pageExtents[f.page]
+	fn()
+	if f.page == kn.page {
+		kn.end = f.pages[f.page].Len()
+		f.keepNext = kn
+	}
+}
+
+// dropKeptWithNext removes the content drawn by KeepWithNext() from the
+// current page if nothing has been written after it. It is called when the
+// page is broken.
+func (f *Fpdf) dropKeptWithNext() {
+	kn := f.keepNext
+	f.keepNext = keepNextType{}
+	if kn.page == 0 || kn.page != f.page || f.pages[f.page].Len() != kn.end {
+		return
+	}
+	f.pages[f.page].Truncate(kn.start)
+	if kn.extended {
+		f.pageExtents[f.page] = kn.extent
+	} else {
+		delete(f.pageExtents, f.page)
+	}
+}
+
+// SetDropBlankPages controls whether pages without content are left out of
+// the document when it is closed. A page is blank if nothing was written to
+// it between its header and its footer, as happens when AddPage() is
+// followed by a conditional section that turned out to be empty. Note that
+// changing the font, colors or line width writes to the page too. Links and
+// bookmarks that point to a dropped page are moved to the following page.
+//
+// At least one page is always kept. The default is false.
+func (f *Fpdf) SetDropBlankPages(on bool) {
+	f.dropBlankPages = on
+}
+
+// PadToMultipleOf appends blank pages when the document is closed until the
+// page count is a multiple of n, so that duplex and booklet printing start
+// every section on the expected side. If stamp is not empty, it is printed
+// centered on each added page, for example "This page intentionally left
+// blank", in the current font or in Helvetica 12 if no font has been set.
+// Added pages receive the usual header and footer. Pages dropped by
+// SetDropBlankPages() are not counted. A value of n less than 2 disables
+// padding.
+func (f *Fpdf) PadToMultipleOf(n int, stamp string) {
+	f.padMultiple = n
+	f.padStamp = stamp
+}
+
+// isBlankPage reports whether page n is to be dropped by
+// SetDropBlankPages().
+func (f *Fpdf) isBlankPage(n int) bool {
+	if !f.dropBlankPages {
+		return false
+	}
+	body := f.pageBody[n]
+	if body.used {
+		return false
+	}
+	end := body.end
+	if end < 0 {
+		end = f.pages[n].Len()
+	}
+	return end == body.start
+}
+
+// endPageBody records the end of the content of the current page. It is
+// called before the footer is written.
+func (f *Fpdf) endPageBody() {
+	f.pageBody[f.page].end = f.pages[f.page].Len()
+}
+
+// padPages adds the pages requested by PadToMultipleOf(). It is called by
+// Close() before the footer of the last page is written.
+func (f *Fpdf) padPages() {
+	if f.padMultiple < 2 {
+		return
+	}
+	count := 0
+	for n := 1; n <= f.page; n++ {
+		if !f.isBlankPage(n) {
+			count++
+		}
+	}
+	for count%f.padMultiple != 0 && f.err == nil {
+		f.AddPage()
+		f.pageBody[f.page].used = true
+		if f.padStamp != "" {
+			if f.fontFamily == "" {
+				f.SetFont("Helvetica", "", 12)
+			}
+			lineHt := f.fontSize * 1.5
+			f.SetXY(f.lMargin, (f.h-lineHt)/2)
+			f.CellFormat(0, lineHt, f.padStamp, "", 0, "C", false, 0, "")
+		}
+		count++
+	}
+}
+
+// removeBlankPages drops the pages selected by SetDropBlankPages() and
+// renumbers the remaining ones. It is called by Close() after the last page
+// has been ended.
+func (f *Fpdf) removeBlankPages() {
+	if !f.dropBlankPages {
+		return
+	}
+	nb := f.page
+	keep := make([]bool, nb+1)
+	renum := make([]int, nb+1)
+	kept := 0
+	for n := 1; n <= nb; n++ {
+		if !f.isBlankPage(n) || (kept == 0 && n == nb) {
+			kept++
+			keep[n] = true
+			renum[n] = kept
+		}
+	}
+	if kept == nb {
+		return
+	}
+	// Dropped pages forward to the next kept page, or the last one.
+	next := kept
+	for n := nb; n >= 1; n-- {
+		if keep[n] {
+			next = renum[n]
+		} else {
+			renum[n] = next
+		}
+	}
+
+	pages := f.pages[:1]
+	pageLinks := f.pageLinks[:1]
+	pageAttachments := f.pageAttachments[:1]
+	pageBody := f.pageBody[:1]
+	pageSizes := make(map[int]PageSize)
+	pageBoxes := make(map[int]map[string]PageBox)
+	pageOrigins := make(map[int]PageBox)
+	pageExtents := make(map[int]extentType)
+	textObjs := make(map[int][]textObjType)
+	textMap := make(map[int][]textMapType)
+	redactions := make(map[int][]extentType)
+	for n := 1; n <= nb; n++ {
+		if !keep[n] {
+			continue
+		}
+		pages = append(pages, f.pages[n])
+		pageLinks = append(pageLinks, f.pageLinks[n])
+		pageAttachments = append(pageAttachments, f.pageAttachments[n])
+		pageBody = append(pageBody, f.pageBody[n])
+		if sz, ok := f.pageSizes[n]; ok {
+			pageSizes[renum[n]] = sz
+		}
+		if pb, ok := f.pageBoxes[n]; ok {
+			pageBoxes[renum[n]] = pb
+		}
+		if o, ok := f.pageOrigins[n]; ok {
+			pageOrigins[renum[n]] = o
+		}
+		if e, ok := f.pageExtents[n]; ok {
+			pageExtents[renum[n]] = e
+		}
+		if objs, ok := f.textObjs[n]; ok {
+			textObjs[renum[n]] = objs
+		}
+		if entries, ok := f.textMap[n]; ok {
+			for j := range entries {
+				entries[j].entry.Page = renum[n]
+			}
+			textMap[renum[n]] = entries
+		}
+		if boxes, ok := f.redactions[n]; ok {
+			redactions[renum[n]] = boxes
+		}
+	}
+	f.pages, f.pageLinks, f.pageAttachments, f.pageBody = pages, pageLinks, pageAttachments, pageBody
+	f.pageSizes, f.pageBoxes, f.pageOrigins, f.pageExtents = pageSizes, pageBoxes, pageOrigins, pageExtents
+	f.textObjs, f.textMap, f.redactions = textObjs, textMap, redactions
+	for j := range f.links {
+		f.links[j].page = renum[f.links[j].page]
+	}
+	for j := range f.outlines {
+		f.outlines[j].p = renum[f.outlines[j].p]
+	}
+	for j := range f.sections {
+		f.sections[j].first = renum[f.sections[j].first]
+	}
+	f.page = kept
+}
@@ -0,0 +1,71 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDropBlankPages(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	pdf.SetHeaderFunc(func() {
+		pdf.Cell(0, 10, "Header")
+	})
+	pdf.SetDropBlankPages(true)
+	pdf.AddPage()
+	link := pdf.AddLink()
+	pdf.CellFormat(40, 10, "Go to section", "", 1, "L", false, link, "")
+	pdf.AddPage() // left empty, dropped
+	pdf.SetLink(link, 0, -1)
+	pdf.AddPage()
+	pdf.Cell(40, 10, "Section")
+	pdf.AddPage() // left empty, dropped
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pdf.PageCount(), 2; got != want {
+		t.Errorf("got %d pages, want %d", got, want)
+	}
+	// The link to the dropped page now points to the page that followed it.
+	if !strings.Contains(buf.String(), "/Dest [5 0 R") {
+		t.Errorf("link not moved to page 2")
+	}
+}
+
+func TestPadToMultipleOf(t *testing.T) {
+	const stamp = "This page intentionally left blank"
+	tests := []struct {
+		pages, blank, want int
+	}{
+		{5, 0, 8},
+		{4, 0, 4},
+		{3, 1, 4}, // one dropped page leaves two, padded to four
+	}
+	for _, tt := range tests {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(false)
+		pdf.SetFont("Arial", "", 12)
+		pdf.SetDropBlankPages(tt.blank > 0)
+		pdf.PadToMultipleOf(4, stamp)
+		for n := 1; n <= tt.pages; n++ {
+			pdf.AddPage()
+			if n > tt.blank {
+				pdf.Cell(40, 10, "Content")
+			}
+		}
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if got := pdf.PageCount(); got != tt.want {
+			t.Errorf("%d pages: got %d pages after padding, want %d", tt.pages, got, tt.want)
+		}
+		padded := tt.want - tt.pages + tt.blank
+		if got := strings.Count(buf.String(), "("+stamp+")"); got != padded {
+			t.Errorf("%d pages: got %d stamps, want %d", tt.pages, got, padded)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package fpdf
+
+import "math"
+
+// dayNano is the length of a day in nanoseconds.
+const dayNano = 86400e9
+
+// daysFromCivil returns the number of days from 1970-01-01 to the date of
+// year, month (1-12) and day in the proleptic Gregorian calendar.
+func daysFromCivil(year, month, day int) int64 {
+	y := int64(year)
+	if month <= 2 {
+		y--
+	}
+	era := y
+	if era < 0 {
+		era -= 399
+	}
+	era /= 400
+	yoe := y - era*400
+	m := int64(month) + 9
+	if month > 2 {
+		m = int64(month) - 3
+	}
+	doy := (153*m+2)/5 + int64(day) - 1
+	doe := yoe*365 + yoe/4 - yoe/100 + doy
+	return era*146097 + doe - 719468
+}
+
+// dayOf returns the day, counted from 1970-01-01, of the time nano in
+// nanoseconds since the Unix epoch.
+func dayOf(nano int64) int64 {
+	day := nano / dayNano
+	if nano%dayNano < 0 {
+		day--
+	}
+	return day
+}
+
+// CalendarOptions configures the month grids drawn by Calendar(). The zero
+// value draws an English calendar with weeks starting on Sunday.
+type CalendarOptions struct {
+	// FirstWeekday is the first day of the weeks, 0 for Sunday, 1 for
+	// Monday.
+	FirstWeekday int
+	// MonthNames and DayNames, from Sunday, replace the English names.
+	MonthNames *[12]string
+	DayNames   *[7]string
+	// Highlight fills the cells of some days, by day of the month, with a
+	// color, e.g. for holidays.
+	Highlight map[int]RGBType
+	// Day, if not nil, is called for each day with the cell of the day, after
+	// its number is printed, to add content such as events.
+	Day func(day int, x, y, w, h float64)
+}
+
+var (
+	calendarMonths = [12]string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+	calendarDays = [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+)
+
+// Calendar draws the month grid of month (1-12) of year in the rectangle of
+// width w and height h with its upper left corner at (x, y): the name of the
+// month and the year, a row with the names of the days of the week, then a
+// row per week with the number of each day at the top left of its cell. The
+// cells are laid out and framed with a GridType. Text is printed in the
+// current font; the current position and drawing state are restored
+// afterwards.
+func (f *Fpdf) Calendar(year, month int, x, y, w, h float64, opt CalendarOptions) {
+	if f.err != nil {
+		return
+	}
+	if month < 1 || month > 12 || opt.FirstWeekday < 0 || opt.FirstWeekday > 6 {
+		f.errorf("Calendar", "invalid month %d or first weekday %d", month, opt.FirstWeekday)
+		return
+	}
+	months, days := &calendarMonths, &calendarDays
+	if opt.MonthNames != nil {
+		months = opt.MonthNames
+	}
+	if opt.DayNames != nil {
+		days = opt.DayNames
+	}
+	first := daysFromCivil(year, month, 1)
+	count := int(daysFromCivil(year+month/12, month%12+1, 1) - first)
+	// 1970-01-01 was a Thursday
+	offset := (int((first%7+7+4)%7) - opt.FirstWeekday + 7) % 7
+	weeks := (offset + count + 6) / 7
+
+	st := StateGet(f)
+	x0, y0 := f.GetXY()
+	auto, margin := f.GetAutoPageBreak()
+	f.SetAutoPageBreak(false, margin)
+	titleHt, headHt := f.fontSize*2, f.fontSize*1.6
+	f.SetXY(x, y)
+	f.CellFormat(w, titleHt, months[month-1]+" "+sprintf("%d", year), "", 0, "C", false, 0, "")
+	cellW := w / 7
+	for c := range 7 {
+		f.SetXY(x+float64(c)*cellW, y+titleHt)
+		f.CellFormat(cellW, headHt, days[(opt.FirstWeekday+c)%7], "", 0, "C", false, 0, "")
+	}
+
+	g := NewGrid(x, y+titleHt+headHt, w, h-titleHt-headHt)
+	g.TickmarksExtentX(0, 1, 7)
+	g.TickmarksExtentY(0, 1, weeks)
+	g.XDiv, g.YDiv = 1, 1
+	g.XTickStr, g.YTickStr = nil, nil
+	g.ClrMain = RGBAType{R: 160, G: 160, B: 160, Alpha: 1}
+	cellH := g.HtAbs(1)
+	for day := 1; day <= count; day++ {
+		j := offset + day - 1
+		cx, cy := g.XY(float64(j%7), float64(weeks-j/7))
+		if clr, ok := opt.Highlight[day]; ok {
+			f.SetFillColor(clr.R, clr.G, clr.B)
+			f.Rect(cx, cy, cellW, cellH, "F")
+		}
+		f.SetXY(cx, cy)
+		f.CellFormat(cellW, f.fontSize*1.4, sprintf("%d", day), "", 0, "L", false, 0, "")
+		if opt.Day != nil {
+			opt.Day(day, cx, cy, cellW, cellH)
+		}
+	}
+	g.Grid(f)
+
+	st.Put(f)
+	f.SetAutoPageBreak(auto, margin)
+	f.SetXY(x0, y0)
+}
+
+// GanttTask is a row of a Gantt chart: a bar from the day of Start to the day
+// of End included, both in nanoseconds since the Unix epoch.
+type GanttTask struct {
+	Name       string
+	Start, End int64
+	// Progress is the part of the task done, from 0 to 1, shown as a darker
+	// part of the bar.
+	Progress float64
+	// Color is the color of the bar; the zero value uses steel blue.
+	Color RGBType
+}
+
+// GanttChart holds the tasks drawn by Gantt().
+type GanttChart struct {
+	Tasks []GanttTask
+	// Today, in nanoseconds since the Unix epoch, is marked by a red line if
+	// it falls within the chart; 0 for none.
+	Today int64
+	// LabelWd is the width of the column of the names of the tasks; 0
+	// measures the names.
+	LabelWd float64
+}
+
+// Gantt draws chart in the rectangle of width w and height h with its upper
+// left corner at (x, y): the names of the tasks in a column on the left, and
+// for each task a bar scaled to its dates on a GridType whose tickmarks are
+// labeled with dates in the layout of the document locale, below the chart.
+// Tickmarks are daily for up to 12 days, weekly or every few weeks beyond.
+// Text is printed in the current font; the current position and drawing state
+// are restored afterwards.
+func (f *Fpdf) Gantt(x, y, w, h float64, chart GanttChart) {
+	if f.err != nil || len(chart.Tasks) == 0 {
+		return
+	}
+	first, last := dayOf(chart.Tasks[0].Start), dayOf(chart.Tasks[0].End)
+	for _, t := range chart.Tasks {
+		if d := dayOf(t.Start); d < first {
+			first = d
+		}
+		if d := dayOf(t.End); d > last {
+			last = d
+		}
+	}
+	last++ // the last day is included
+	div, sub := int64(1), 1
+	if span := last - first; span > 12 {
+		weeks := (span + 7*12 - 1) / (7 * 12)
+		div, sub = 7*weeks, 7
+		// Start the weeks on Monday: 1970-01-05 was one
+		first -= ((first-4)%7 + 7) % 7
+	}
+	count := int((last - first + div - 1) / div)
+
+	st := StateGet(f)
+	x0, y0 := f.GetXY()
+	auto, margin := f.GetAutoPageBreak()
+	f.SetAutoPageBreak(false, margin)
+	labelWd := chart.LabelWd
+	if labelWd == 0 {
+		for _, t := range chart.Tasks {
+			labelWd = math.Max(labelWd, f.GetStringWidth(t.Name)+2*f.cMargin)
+		}
+	}
+	textSz := f.PointToUnitConvert(7)
+	g := NewGrid(x+labelWd, y, w-labelWd, h-3*textSz)
+	g.TickmarksExtentX(float64(first), float64(div), count)
+	g.TickmarksExtentY(0, 1, len(chart.Tasks))
+	g.XDiv, g.YDiv = sub, 1
+	g.TextSize = 7
+	g.XTickStr = func(day float64, _ int) string {
+		return f.FormatDate(int64(day) * dayNano)
+	}
+	g.YTickStr = nil
+	g.ClrMain = RGBAType{R: 160, G: 160, B: 160, Alpha: 1}
+	g.ClrSub = RGBAType{R: 220, G: 220, B: 220, Alpha: 1}
+	g.Grid(f)
+
+	rowHt := g.HtAbs(1)
+	for j, t := range chart.Tasks {
+		top := g.Y(float64(len(chart.Tasks) - j))
+		f.SetXY(x, top)
+		f.CellFormat(labelWd, rowHt, t.Name, "", 0, "L", false, 0, "")
+		clr := t.Color
+		if clr == (RGBType{}) {
+			clr = RGBType{R: 70, G: 130, B: 180}
+		}
+		bx := g.X(float64(dayOf(t.Start)))
+		bw := g.X(float64(dayOf(t.End)+1)) - bx
+		by, bh := top+rowHt*0.2, rowHt*0.6
+		f.SetFillColor(clr.R, clr.G, clr.B)
+		f.Rect(bx, by, bw, bh, "F")
+		if p := math.Min(t.Progress, 1); p > 0 {
+			f.SetFillColor(clr.R*6/10, clr.G*6/10, clr.B*6/10)
+			f.Rect(bx, by+bh/3, bw*p, bh/3, "F")
+		}
+	}
+	if chart.Today != 0 {
+		if today := dayOf(chart.Today); today >= first && today < last {
+			tx := g.X(float64(today) + 0.5)
+			f.SetDrawColor(220, 30, 30)
+			f.SetLineWidth(f.PointToUnitConvert(1))
+			f.Line(tx, g.Y(float64(len(chart.Tasks))), tx, g.Y(0))
+		}
+	}
+
+	st.Put(f)
+	f.SetAutoPageBreak(auto, margin)
+	f.SetXY(x0, y0)
+}
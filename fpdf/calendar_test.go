@@ -0,0 +1,155 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestCalendar(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFont("Arial", "", 10)
+	pdf.SetXY(30, 40)
+	type cell struct{ x, y, w, h float64 }
+	cells := map[int]cell{}
+	// February 2024 has 29 days and starts on a Thursday
+	pdf.Calendar(2024, 2, 10, 20, 140, 100, fpdf.CalendarOptions{
+		FirstWeekday: 1,
+		Highlight:    map[int]fpdf.RGBType{14: {R: 255, G: 220, B: 220}},
+		Day: func(day int, x, y, w, h float64) {
+			cells[day] = cell{x, y, w, h}
+		},
+	})
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 29 {
+		t.Fatalf("got %d days, want 29", len(cells))
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	_, fontHt := pdf.GetFontSize()
+	top := 20 + fontHt*2 + fontHt*1.6
+	cellW, cellH := 140.0/7, (100-fontHt*3.6)/5
+	for day, want := range map[int]cell{
+		1:  {10 + 3*cellW, top, cellW, cellH},
+		5:  {10, top + cellH, cellW, cellH},
+		29: {10 + 3*cellW, top + 4*cellH, cellW, cellH},
+	} {
+		if got := cells[day]; !near(got.x, want.x) || !near(got.y, want.y) || !near(got.w, want.w) || !near(got.h, want.h) {
+			t.Errorf("day %d: got cell %+v, want %+v", day, got, want)
+		}
+	}
+	if x, y := pdf.GetXY(); x != 30 || y != 40 {
+		t.Errorf("got position %.2f, %.2f, want it restored", x, y)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"(February 2024)Tj", "(Mon)Tj", "(Sun)Tj", "(29)Tj"} {
+		if !strings.Contains(buf.String(), text) {
+			t.Errorf("%s missing", text)
+		}
+	}
+
+	pdf.Calendar(2024, 13, 10, 20, 140, 100, fpdf.CalendarOptions{})
+	if pdf.Error() == nil {
+		t.Error("no error for month 13")
+	}
+}
+
+func TestCalendarNoPageBreak(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetFont("Arial", "", 10)
+	// Both end below the page break trigger, in the bottom margin
+	pdf.Calendar(2024, 2, 10, 240, 140, 45, fpdf.CalendarOptions{})
+	pdf.Gantt(10, 250, 190, 40, fpdf.GanttChart{Tasks: []fpdf.GanttTask{
+		{Name: "Plan", Start: 1704067200e9, End: 1704067200e9 + 5*86400e9},
+	}})
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if n := pdf.PageCount(); n != 1 {
+		t.Errorf("got %d pages, want 1", n)
+	}
+	if auto, _ := pdf.GetAutoPageBreak(); !auto {
+		t.Error("automatic page break not restored")
+	}
+}
+
+func TestGantt(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetLocale("de-CH")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "", 10)
+	day := int64(86400e9)
+	start := int64(1704067200e9) // Monday 2024-01-01
+	pdf.Gantt(10, 20, 190, 60, fpdf.GanttChart{
+		Tasks: []fpdf.GanttTask{
+			{Name: "Design", Start: start, End: start + 4*day, Progress: 1},
+			{Name: "Build", Start: start + 5*day, End: start + 9*day, Progress: 0.5},
+		},
+		Today:   start + 6*day,
+		LabelWd: 30,
+	})
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// 10 days, one tickmark per day labeled in the layout of the locale
+	for _, text := range []string{"(Design)Tj", "(Build)Tj", "(01.01.2024)Tj", "(11.01.2024)Tj"} {
+		if !strings.Contains(out, text) {
+			t.Errorf("%s missing", text)
+		}
+	}
+	if strings.Contains(out, "(12.01.2024)Tj") {
+		t.Error("grid extends beyond the last task")
+	}
+	// The bar of Build spans days 5 to 9 of the 10 days of the grid
+	k := 72 / 25.4
+	dayW := 160.0 / 10
+	bar := regexp.MustCompile(strconv.FormatFloat(k*(40+5*dayW), 'f', 2, 64) + ` [\d.]+ ` +
+		strconv.FormatFloat(k*5*dayW, 'f', 2, 64) + ` -[\d.]+ re f`)
+	if !bar.MatchString(out) {
+		t.Errorf("bar of Build not found at %s", bar)
+	}
+	if !strings.Contains(out, "0.863 0.118 0.118 RG") {
+		t.Error("today line missing")
+	}
+
+	// Longer charts have weekly tickmarks starting on a Monday
+	pdf = NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetLocale("de-CH")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "", 10)
+	pdf.Gantt(10, 20, 190, 60, fpdf.GanttChart{Tasks: []fpdf.GanttTask{
+		{Name: "Plan", Start: start + 2*day, End: start + 30*day},
+	}})
+	buf.Reset()
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out = buf.String()
+	for _, text := range []string{"(01.01.2024)Tj", "(08.01.2024)Tj", "(05.02.2024)Tj"} {
+		if !strings.Contains(out, text) {
+			t.Errorf("%s missing", text)
+		}
+	}
+	if strings.Contains(out, "(02.01.2024)Tj") {
+		t.Error("daily tickmarks on a chart of a month")
+	}
+}
@@ -0,0 +1,146 @@
+package fpdf
+
+import "math"
+
+// ScaledCanvas maps a world coordinate system, such as the coordinates of
+// scientific data or of a map, onto a rectangular region of the page. World
+// coordinates have their y axis pointing up, like in mathematics and GIS, and
+// any origin and scale. The drawing methods of ScaledCanvas take world
+// coordinates and draw with the current colors, line width and font of the
+// document, so that data can be drawn without flipping and scaling each
+// coordinate.
+type ScaledCanvas struct {
+	pdf *Fpdf
+	// Region of the page, in the unit of measure specified in New()
+	x, y, w, h float64
+	// Slopes and intercepts that map world coordinates to page coordinates
+	xm, xb, ym, yb float64
+}
+
+// NewScaledCanvas returns a canvas that maps the world rectangle from
+// (minX, minY) at its lower left corner to (maxX, maxY) at its upper right
+// corner onto the rectangle of the page of width w and height h with its
+// upper left corner at (x, y). If uniform is true, both axes are given the
+// same scale, the smaller of the two, and the world rectangle is centered in
+// the page rectangle, so that shapes are not distorted as is needed for maps
+// and drawings.
+func NewScaledCanvas(pdf *Fpdf, x, y, w, h, minX, minY, maxX, maxY float64, uniform bool) *ScaledCanvas {
+	c := &ScaledCanvas{pdf: pdf, x: x, y: y, w: w, h: h}
+	if minX == maxX || minY == maxY {
+		pdf.errorf("NewScaledCanvas", "the world rectangle is empty")
+		return c
+	}
+	sx, sy := w/(maxX-minX), h/(maxY-minY)
+	if uniform {
+		s := math.Min(math.Abs(sx), math.Abs(sy))
+		sx, sy = math.Copysign(s, sx), math.Copysign(s, sy)
+		x += (w - sx*(maxX-minX)) / 2
+		y += (h - sy*(maxY-minY)) / 2
+	}
+	c.xm, c.xb = sx, x-sx*minX
+	c.ym, c.yb = -sy, y+sy*maxY
+	return c
+}
+
+// X returns the page abscissa of the world abscissa wx.
+func (c *ScaledCanvas) X(wx float64) float64 {
+	return c.xm*wx + c.xb
+}
+
+// Y returns the page ordinate of the world ordinate wy.
+func (c *ScaledCanvas) Y(wy float64) float64 {
+	return c.ym*wy + c.yb
+}
+
+// XY returns the page coordinates of the world point (wx, wy).
+func (c *ScaledCanvas) XY(wx, wy float64) (float64, float64) {
+	return c.X(wx), c.Y(wy)
+}
+
+// Wd returns the page length of the horizontal world distance dx.
+func (c *ScaledCanvas) Wd(dx float64) float64 {
+	return math.Abs(c.xm * dx)
+}
+
+// Ht returns the page length of the vertical world distance dy.
+func (c *ScaledCanvas) Ht(dy float64) float64 {
+	return math.Abs(c.ym * dy)
+}
+
+// ClipBegin restricts the following output to the page region of the canvas,
+// so that world shapes extending beyond it are cut at its edges. It must be
+// paired with a call to ClipEnd().
+func (c *ScaledCanvas) ClipBegin() {
+	c.pdf.ClipRect(c.x, c.y, c.w, c.h, false)
+}
+
+// ClipEnd ends the clipping started with ClipBegin().
+func (c *ScaledCanvas) ClipEnd() {
+	c.pdf.ClipEnd()
+}
+
+// Line draws a line between the world points (x1, y1) and (x2, y2).
+func (c *ScaledCanvas) Line(x1, y1, x2, y2 float64) {
+	c.pdf.Line(c.X(x1), c.Y(y1), c.X(x2), c.Y(y2))
+}
+
+// Rect draws the world rectangle of width w and height h with its lower left
+// corner at (x, y). styleStr is used as in Fpdf.Rect().
+func (c *ScaledCanvas) Rect(x, y, w, h float64, styleStr string) {
+	x0, y0 := c.XY(x, y)
+	x1, y1 := c.XY(x+w, y+h)
+	c.pdf.Rect(math.Min(x0, x1), math.Min(y0, y1), math.Abs(x1-x0), math.Abs(y1-y0), styleStr)
+}
+
+// Circle draws the world circle of radius r centered at (x, y), which is an
+// ellipse on the page unless both axes have the same scale. styleStr is used
+// as in Fpdf.Circle().
+func (c *ScaledCanvas) Circle(x, y, r float64, styleStr string) {
+	c.pdf.Ellipse(c.X(x), c.Y(y), c.Wd(r), c.Ht(r), 0, styleStr)
+}
+
+// Polygon draws the closed figure with the world vertices points. styleStr
+// is used as in Fpdf.Polygon().
+func (c *ScaledCanvas) Polygon(points []PointType, styleStr string) {
+	c.pdf.Polygon(c.points(points), styleStr)
+}
+
+// SmoothPolyline draws a smooth curve through the world points. tension and
+// styleStr are used as in Fpdf.SmoothPolyline().
+func (c *ScaledCanvas) SmoothPolyline(points []PointType, tension float64, styleStr string) {
+	c.pdf.SmoothPolyline(c.points(points), tension, styleStr)
+}
+
+// MoveTo starts a path at the world point (x, y), like Fpdf.MoveTo(). The
+// path is drawn with Fpdf.DrawPath().
+func (c *ScaledCanvas) MoveTo(x, y float64) {
+	c.pdf.MoveTo(c.XY(x, y))
+}
+
+// LineTo adds a line to the world point (x, y) to the current path, like
+// Fpdf.LineTo().
+func (c *ScaledCanvas) LineTo(x, y float64) {
+	c.pdf.LineTo(c.XY(x, y))
+}
+
+// CurveBezierCubicTo adds a cubic Bézier curve to the world point (x, y),
+// with world control points (cx0, cy0) and (cx1, cy1), to the current path,
+// like Fpdf.CurveBezierCubicTo().
+func (c *ScaledCanvas) CurveBezierCubicTo(cx0, cy0, cx1, cy1, x, y float64) {
+	c.pdf.CurveBezierCubicTo(c.X(cx0), c.Y(cy0), c.X(cx1), c.Y(cy1), c.X(x), c.Y(y))
+}
+
+// Text prints txtStr with the start of its baseline at the world point
+// (x, y). The text itself is not scaled.
+func (c *ScaledCanvas) Text(x, y float64, txtStr string) {
+	c.pdf.Text(c.X(x), c.Y(y), txtStr)
+}
+
+// points returns the page points of the world points.
+func (c *ScaledCanvas) points(points []PointType) []PointType {
+	page := make([]PointType, len(points))
+	for j, p := range points {
+		page[j] = PointType{X: c.X(p.X), Y: c.Y(p.Y)}
+	}
+	return page
+}
@@ -0,0 +1,137 @@
+package fpdf
+
+// CellBorder selects the sides of a cell that are framed. The sides combine
+// with |, as in CellBorderLeft | CellBorderRight.
+type CellBorder uint8
+
+const (
+	// CellBorderLeft frames the left side of the cell
+	CellBorderLeft CellBorder = 1 << iota
+	// CellBorderTop frames the top of the cell
+	CellBorderTop
+	// CellBorderRight frames the right side of the cell
+	CellBorderRight
+	// CellBorderBottom frames the bottom of the cell
+	CellBorderBottom
+	// CellBorderAll frames the four sides of the cell
+	CellBorderAll = CellBorderLeft | CellBorderTop | CellBorderRight | CellBorderBottom
+)
+
+// String returns the border string of CellFormat() that frames the sides of
+// b: "1" for all of them, otherwise some of "L", "T", "R" and "B".
+func (b CellBorder) String() string {
+	if b&CellBorderAll == CellBorderAll {
+		return BorderFull
+	}
+	var s string
+	for j, side := range []string{BorderLeft, BorderTop, BorderRight, BorderBottom} {
+		if b&(1<<j) != 0 {
+			s += side
+		}
+	}
+	return s
+}
+
+// CellAlign is the horizontal alignment of the text of a cell.
+type CellAlign uint8
+
+const (
+	// CellAlignLeft aligns the text with the left side of the cell
+	CellAlignLeft CellAlign = iota
+	// CellAlignCenter centers the text in the cell
+	CellAlignCenter
+	// CellAlignRight aligns the text with the right side of the cell
+	CellAlignRight
+	// CellAlignJustify stretches the spaces of the text to the width of the
+	// cell
+	CellAlignJustify
+)
+
+// String returns the horizontal alignment string of CellFormat() for a.
+func (a CellAlign) String() string {
+	switch a {
+	case CellAlignCenter:
+		return AlignCenter
+	case CellAlignRight:
+		return AlignRight
+	case CellAlignJustify:
+		return "J"
+	}
+	return AlignLeft
+}
+
+// CellVAlign is the vertical alignment of the text of a cell.
+type CellVAlign uint8
+
+const (
+	// CellVAlignMiddle centers the text vertically in the cell
+	CellVAlignMiddle CellVAlign = iota
+	// CellVAlignTop aligns the text with the top of the cell
+	CellVAlignTop
+	// CellVAlignBottom aligns the text with the bottom of the cell
+	CellVAlignBottom
+	// CellVAlignBaseline centers the baseline of the text vertically in the
+	// cell
+	CellVAlignBaseline
+)
+
+// String returns the vertical alignment string of CellFormat() for a.
+func (a CellVAlign) String() string {
+	switch a {
+	case CellVAlignTop:
+		return AlignTop
+	case CellVAlignBottom:
+		return AlignBottom
+	case CellVAlignBaseline:
+		return AlignBaseline
+	}
+	return ""
+}
+
+// CellLineMove is where the current position goes after a cell.
+type CellLineMove int
+
+const (
+	// CellMoveRight moves to the right of the cell
+	CellMoveRight CellLineMove = LineBreakNone
+	// CellMoveNextLine moves to the beginning of the next line
+	CellMoveNextLine CellLineMove = LineBreakNormal
+	// CellMoveBelow moves below the cell, at its left side
+	CellMoveBelow CellLineMove = LineBreakBelow
+)
+
+// CellOptions are the options of CellOpt(). The zero value prints the text
+// left aligned and vertically centered in a transparent cell without border,
+// and moves to the right of the cell.
+type CellOptions struct {
+	Border   CellBorder
+	Align    CellAlign
+	VAlign   CellVAlign
+	LineMove CellLineMove
+	Fill     bool   // paint the background with the fill color
+	Link     int    // identifier returned by AddLink(), 0 for no internal link
+	LinkStr  string // target URL, empty for no external link
+}
+
+// CellOpt prints a cell as CellFormat() does, with its options typed rather
+// than given as string and integer flags, so that a misspelt border or
+// alignment does not go unnoticed.
+func (f *Fpdf) CellOpt(w, h float64, txtStr string, opt CellOptions) {
+	f.CellFormat(w, h, txtStr, opt.Border.String(), int(opt.LineMove),
+		opt.Align.String()+opt.VAlign.String(), opt.Fill, opt.Link, opt.LinkStr)
+}
+
+// ImagePlacement holds the options of ImageOpt(): how the image is read and
+// rendered, whether it flows with the text and where it links to.
+type ImagePlacement struct {
+	ImageOptions
+	Flow    bool   // advance the current position below the image, breaking the page if needed
+	Link    int    // identifier returned by AddLink(), 0 for no internal link
+	LinkStr string // target URL, empty for no external link
+}
+
+// ImageOpt puts an image in the current page as ImageOptions() does, with
+// the positional flow and link arguments gathered in opt.
+func (f *Fpdf) ImageOpt(imageNameStr string, x, y, w, h float64, opt ImagePlacement) {
+	f.ImageOptions(imageNameStr, x, y, w, h, opt.Flow, opt.ImageOptions, opt.Link, opt.LinkStr)
+}
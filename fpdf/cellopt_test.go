@@ -0,0 +1,110 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestCellOpt(t *testing.T) {
+	page := func(fn func(pdf *fpdf.Fpdf)) string {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(false)
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "", 12)
+		fn(pdf)
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	for _, c := range []struct {
+		opt           fpdf.CellOptions
+		border, align string
+		ln            int
+	}{
+		{fpdf.CellOptions{}, "", "L", 0},
+		{fpdf.CellOptions{Border: fpdf.CellBorderAll, Fill: true}, "1", "L", 0},
+		{fpdf.CellOptions{Border: fpdf.CellBorderLeft | fpdf.CellBorderBottom, Align: fpdf.CellAlignRight,
+			VAlign: fpdf.CellVAlignTop, LineMove: fpdf.CellMoveNextLine}, "LB", "RT", 1},
+		{fpdf.CellOptions{Align: fpdf.CellAlignCenter, VAlign: fpdf.CellVAlignBaseline, LineMove: fpdf.CellMoveBelow,
+			LinkStr: "https://example.com"}, "", fpdf.AlignCenter + fpdf.AlignBaseline, 2},
+	} {
+		got := page(func(pdf *fpdf.Fpdf) {
+			pdf.CellOpt(40, 10, "Cell", c.opt)
+			pdf.CellOpt(40, 10, "Next", fpdf.CellOptions{})
+		})
+		want := page(func(pdf *fpdf.Fpdf) {
+			pdf.CellFormat(40, 10, "Cell", c.border, c.ln, c.align, c.opt.Fill, 0, c.opt.LinkStr)
+			pdf.CellFormat(40, 10, "Next", "", 0, "", false, 0, "")
+		})
+		if got != want {
+			t.Errorf("CellOpt with %+v differs from CellFormat with %q, %q, %d", c.opt, c.border, c.align, c.ln)
+		}
+	}
+
+	// Partial borders are stroked side by side
+	out := page(func(pdf *fpdf.Fpdf) {
+		pdf.CellOpt(40, 10, "", fpdf.CellOptions{Border: fpdf.CellBorderLeft | fpdf.CellBorderRight})
+	})
+	if n := strings.Count(out, " l S"); n != 2 {
+		t.Errorf("got %d border lines, want 2", n)
+	}
+}
+
+func TestCellOptJustify(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.SetFont("dejavu", "", 12)
+	pdf.AddPage()
+	pdf.CellOpt(300, 20, "justified text", fpdf.CellOptions{Align: fpdf.CellAlignJustify, VAlign: fpdf.CellVAlignTop})
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// The space is stretched in a TJ array, as without a vertical alignment
+	if !strings.Contains(buf.String(), "] TJ") {
+		t.Errorf("justified text with a vertical alignment is not stretched")
+	}
+}
+
+func TestCellBorderString(t *testing.T) {
+	for b, want := range map[fpdf.CellBorder]string{
+		0: "", fpdf.CellBorderAll: "1", fpdf.CellBorderTop: "T",
+		fpdf.CellBorderLeft | fpdf.CellBorderTop | fpdf.CellBorderRight: "LTR",
+	} {
+		if got := b.String(); got != want {
+			t.Errorf("border %d: got %q, want %q", b, got, want)
+		}
+	}
+}
+
+func TestImageOpt(t *testing.T) {
+	page := func(fn func(pdf *fpdf.Fpdf)) string {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(false)
+		pdf.AddPage()
+		fn(pdf)
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	got := page(func(pdf *fpdf.Fpdf) {
+		pdf.ImageOpt(ImageFile("logo.png"), 10, 10, 30, 0, fpdf.ImagePlacement{
+			ImageOptions: fpdf.ImageOptions{ReadDpi: true}, Flow: true, LinkStr: "https://example.com"})
+		pdf.Rect(pdf.GetX(), pdf.GetY(), 1, 1, "D")
+	})
+	want := page(func(pdf *fpdf.Fpdf) {
+		pdf.ImageOptions(ImageFile("logo.png"), 10, 10, 30, 0, true, fpdf.ImageOptions{ReadDpi: true}, 0, "https://example.com")
+		pdf.Rect(pdf.GetX(), pdf.GetY(), 1, 1, "D")
+	})
+	if got != want {
+		t.Errorf("ImageOpt differs from ImageOptions")
+	}
+}
@@ -0,0 +1,184 @@
+package fpdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// cloneLinkType is an internal link of a cloned page whose target page has
+// not been cloned yet.
+type cloneLinkType struct {
+	src  *Fpdf
+	page int // target page in src
+	link int // link in f
+}
+
+// ClonePage appends a copy of page n of src to the document. The page keeps
+// its size and everything drawn on it, including the header and footer of
+// src, together with the fonts and images it uses and its links. This makes
+// it possible to generate the sections of a large document independently,
+// for example in separate goroutines each with its own Fpdf, and to assemble
+// the pages afterwards:
+//
+//	for n := 1; n <= section.PageCount(); n++ {
+//		pdf.ClonePage(section, n)
+//	}
+//
+// The header, footer and stationery functions of the document are not
+// called for cloned pages. Content can still be added to the cloned page
+// with the current font and colors until the next page is added.
+//
+// An internal link on the page is kept when its target page is cloned into
+// the document too, whether before or after; until then it points to the
+// first page. Page number aliases not yet replaced in src take the values of
+// the document. src must be complete up to page n and should not be modified
+// while ClonePage runs. Pages that use transparency, gradients, spot colors,
+// layers or templates cannot be cloned.
+func (f *Fpdf) ClonePage(src *Fpdf, n int) {
+	if f.err != nil {
+		return
+	}
+	if src == nil || n < 1 || n > src.page {
+		f.errorf("ClonePage", "page %d does not exist in the source document", n)
+		return
+	}
+	if src.err != nil {
+		f.errorf("ClonePage", "source document has an error: %s", src.err.Error())
+		return
+	}
+	content := src.pages[n].String()
+	for _, op := range []string{" gs\n", " sh\n", " BDC\n", "/CS", "/TPL"} {
+		if Contains(content, op) {
+			f.errorf("ClonePage", "page %d uses resources that cannot be cloned", n)
+			return
+		}
+	}
+
+	for key, def := range src.fonts {
+		if Contains(content, "/F"+def.i+" ") {
+			f.cloneFont(src, key, def)
+		}
+	}
+	for key, info := range src.images {
+		if Contains(content, "/I"+info.i+" Do") {
+			f.cloneImage(key, info)
+		}
+	}
+
+	// Add the page without header, stationery and origin box, then draw the
+	// content of the source page in its own graphics state.
+	w, h := src.pageSizePt(n)
+	header, stationery, originBox := f.headerFnc, f.stationery, f.originBox
+	f.headerFnc, f.stationery, f.originBox = nil, nil, ""
+	f.AddPageFormat(Portrait, PageSize{Wd: w, Ht: h})
+	f.headerFnc, f.stationery, f.originBox = header, stationery, originBox
+	if f.err != nil {
+		return
+	}
+	f.out("q")
+	f.out(content)
+	f.out("Q")
+	if box, ok := src.pageOrigins[n]; ok {
+		// Content added to the page uses the origin box of the source.
+		f.setPageOrigin(box)
+	}
+	f.pageBody[f.page].cloned = true
+	f.pageBody[f.page].used = true
+	for box, pb := range src.pageBoxes[n] {
+		f.pageBoxes[f.page][box] = pb
+	}
+	if e, ok := src.pageExtents[n]; ok {
+		r := src.k / f.k
+		f.extend(e.minX*r, e.minY*r, e.maxX*r, e.maxY*r)
+	}
+
+	for _, pl := range src.pageLinks[n] {
+		if pl.link != 0 {
+			target := src.links[pl.link]
+			pl.link = f.AddLink()
+			f.cloneLinks = append(f.cloneLinks, cloneLinkType{src: src, page: target.page, link: pl.link})
+			target.page = 1
+			target.x *= src.k / f.k
+			target.y *= src.k / f.k
+			f.links[pl.link] = target
+		}
+		f.pageLinks[f.page] = append(f.pageLinks[f.page], pl)
+	}
+
+	// Resolve the links of all cloned pages that point to this one.
+	if f.clonedPages == nil {
+		f.clonedPages = make(map[*Fpdf]map[int]int)
+	}
+	if f.clonedPages[src] == nil {
+		f.clonedPages[src] = make(map[int]int)
+	}
+	f.clonedPages[src][n] = f.page
+	pending := f.cloneLinks[:0]
+	for _, cl := range f.cloneLinks {
+		if page, ok := f.clonedPages[cl.src][cl.page]; ok {
+			f.links[cl.link].page = page
+		} else {
+			pending = append(pending, cl)
+		}
+	}
+	f.cloneLinks = pending
+}
+
+// cloneFont makes the font def of src available to the document under the
+// same identifier.
+func (f *Fpdf) cloneFont(src *Fpdf, key string, def fontDefType) {
+	for _, have := range f.fonts {
+		if have.i == def.i {
+			// Same font: the runes used in src must be part of the subset.
+			for r, v := range def.usedRunes {
+				have.usedRunes[r] = v
+			}
+			return
+		}
+	}
+	if _, taken := f.fonts[key]; taken {
+		key += "#" + def.i
+	}
+	runes := make(map[int]int, len(def.usedRunes))
+	for r, v := range def.usedRunes {
+		runes[r] = v
+	}
+	def.usedRunes = runes
+	if def.Diff != "" {
+		def.DiffN = 0
+		for j, str := range f.diffs {
+			if str == def.Diff {
+				def.DiffN = j + 1
+				break
+			}
+		}
+		if def.DiffN == 0 {
+			f.diffs = append(f.diffs, def.Diff)
+			def.DiffN = len(f.diffs)
+		}
+	}
+	f.fonts[key] = def
+	for _, file := range []string{def.File, def.Name} {
+		if info, ok := src.fontFiles[file]; ok {
+			if _, ok = f.fontFiles[file]; !ok {
+				info.n = 0
+				f.fontFiles[file] = info
+			}
+		}
+	}
+}
+
+// cloneImage makes the image info of src available to the document under the
+// same identifier.
+func (f *Fpdf) cloneImage(key string, info *ImageInfoType) {
+	for _, have := range f.images {
+		if have.i == info.i {
+			return
+		}
+	}
+	if _, taken := f.images[key]; taken {
+		key += "#" + info.i
+	}
+	img := *info
+	img.n = 0
+	f.images[key] = &img
+}
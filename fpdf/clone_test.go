@@ -0,0 +1,56 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestClonePage(t *testing.T) {
+	src := NewDocPdfTest()
+	src.SetFont("Arial", "", 12)
+	src.AddPage()
+	link := src.AddLink()
+	src.CellFormat(40, 10, "Section A", "", 1, "L", false, link, "")
+	src.AddPage()
+	src.SetLink(link, 0, -1)
+	src.Image(ImageFile("logo.png"), 10, 30, 30, 0, false, "", 0, "")
+
+	headers := 0
+	dst := NewDocPdfTest()
+	dst.SetCompression(false)
+	dst.SetFont("Times", "", 12)
+	dst.SetHeaderFunc(func() { headers++ })
+	dst.AddPage()
+	dst.Cell(40, 10, "Cover")
+	for n := 1; n <= 2; n++ {
+		dst.ClonePage(src, n)
+	}
+	dst.Cell(40, 10, "Added after cloning")
+
+	var buf bytes.Buffer
+	if err := dst.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if got, want := dst.PageCount(), 3; got != want {
+		t.Errorf("got %d pages, want %d", got, want)
+	}
+	if headers != 1 {
+		t.Errorf("header called %d times, want once", headers)
+	}
+	for _, s := range []string{"(Section A)Tj", "(Added after cloning)Tj", "/BaseFont /Helvetica", "/Subtype /Image"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+	// The link points to the clone of its target page, the third page.
+	if !strings.Contains(out, "/Dest [7 0 R") {
+		t.Errorf("link not moved to the cloned page")
+	}
+
+	dst.ClonePage(src, 3)
+	if dst.Error() == nil {
+		t.Errorf("expecting error for missing page")
+	}
+}
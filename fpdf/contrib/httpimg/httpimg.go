@@ -1,9 +1,14 @@
 package httpimg
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	fpdf "github.com/tinywasm/pdf/fpdf"
 )
@@ -49,3 +54,141 @@ func Register(f httpimgPdf, urlStr, tp string) (info *fpdf.ImageInfoType) {
 
 	return f.RegisterImageReader(urlStr, tp, resp.Body)
 }
+
+// Options limits the download of an image by RegisterImageURL().
+type Options struct {
+	Timeout  time.Duration // limit for the whole request, 0 for DefaultTimeout
+	MaxBytes int64         // size limit of the image, 0 for DefaultMaxBytes
+	Cache    Cache         // downloaded images shared between documents, nil for none
+}
+
+// Defaults applied by RegisterImageURL() to the zero values of Options.
+const (
+	DefaultTimeout  = 30 * time.Second
+	DefaultMaxBytes = 10 << 20
+)
+
+// Cache stores downloaded images by URL, together with their image type
+// ("jpg", "png" or "gif"), so that documents generated repeatedly do not
+// download them again. It must be safe for concurrent use.
+type Cache interface {
+	Get(urlStr string) (data []byte, tp string, ok bool)
+	Put(urlStr string, data []byte, tp string)
+}
+
+// NewMemoryCache returns a Cache that keeps images in memory for the life of
+// the program.
+func NewMemoryCache() Cache {
+	return &memoryCache{images: make(map[string]cachedImage)}
+}
+
+type cachedImage struct {
+	data []byte
+	tp   string
+}
+
+type memoryCache struct {
+	mu     sync.Mutex
+	images map[string]cachedImage
+}
+
+func (c *memoryCache) Get(urlStr string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	img, ok := c.images[urlStr]
+	return img.data, img.tp, ok
+}
+
+func (c *memoryCache) Put(urlStr string, data []byte, tp string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.images[urlStr] = cachedImage{data: data, tp: tp}
+}
+
+// RegisterImageURL downloads the image at urlStr and registers it like
+// Register(), under the URL as name. Unlike Register(), the download is
+// bounded by the timeout and size limit of opts, and the response must
+// succeed with a JPEG, PNG or GIF image whose content matches the
+// Content-Type header, so that a page of HTML or an oversized file returned
+// by the server is reported as an error rather than embedded or read into
+// memory.
+func RegisterImageURL(f httpimgPdf, urlStr string, opts Options) (info *fpdf.ImageInfoType) {
+	info = f.GetImageInfo(urlStr)
+	if info != nil {
+		return
+	}
+	if opts.Cache != nil {
+		if data, tp, ok := opts.Cache.Get(urlStr); ok {
+			return f.RegisterImageReader(urlStr, tp, bytes.NewReader(data))
+		}
+	}
+	data, tp, err := download(urlStr, opts)
+	if err != nil {
+		f.SetError(err)
+		return
+	}
+	if opts.Cache != nil {
+		opts.Cache.Put(urlStr, data, tp)
+	}
+	return f.RegisterImageReader(urlStr, tp, bytes.NewReader(data))
+}
+
+// download fetches and validates the image at urlStr.
+func download(urlStr string, opts Options) (data []byte, tp string, err error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	client := &http.Client{Timeout: opts.Timeout}
+	resp, err := client.Get(urlStr)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = errors.New("image download failed: " + resp.Status + ": " + urlStr)
+		return
+	}
+	tooLarge := errors.New("image exceeds " + strconv.FormatInt(opts.MaxBytes, 10) + " bytes: " + urlStr)
+	if resp.ContentLength > opts.MaxBytes {
+		err = tooLarge
+		return
+	}
+	data, err = io.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes+1))
+	if err != nil {
+		return
+	}
+	if int64(len(data)) > opts.MaxBytes {
+		err = tooLarge
+		return
+	}
+	// The content must be an image of a supported type, the one announced
+	// by the server if any.
+	sniffed := http.DetectContentType(data)
+	tp = imageTypes[sniffed]
+	if tp == "" {
+		err = errors.New("unsupported image content " + sniffed + ": " + urlStr)
+		return
+	}
+	if header := resp.Header.Get("Content-Type"); header != "" {
+		mediaType, _, _ := strings.Cut(header, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType == "image/jpg" {
+			mediaType = "image/jpeg"
+		}
+		if mediaType != sniffed {
+			err = errors.New("image content " + sniffed + " does not match Content-Type " + header + ": " + urlStr)
+			return
+		}
+	}
+	return
+}
+
+// imageTypes maps the detected MIME types of supported images to image types.
+var imageTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
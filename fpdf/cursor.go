@@ -0,0 +1,284 @@
+package fpdf
+
+import "bytes"
+
+// CursorState is a snapshot of the writing position and text state of a
+// document, taken by SaveCursor() and given back to RestoreCursor() or
+// RollbackCursor().
+type CursorState struct {
+	page, pages int     // current page and page count
+	k           float64 // scale factor of the unit the lengths are in
+	x, y        float64 // current position
+	cMargin     float64
+	lineWidth   float64
+	// font
+	fontFamily, fontStyle string
+	fontSynth             string
+	textTransform         TextTransform
+	decoration            DecorationStyle
+	decorationColor       string
+	textBg                colorType
+	textBgPad             float64
+	underline, strikeout  bool
+	fontSizePt, fontSize  float64
+	currentFont           fontDefType
+	isCurrentUTF8         bool
+	// colors
+	draw, fill, text colorType
+	colorFlag        bool
+	// geometry of the current page
+	w, h, wPt, hPt   float64
+	pageBreakTrigger float64
+	curOrientation   orientationType
+	curPageSize      PageSize
+	autoHt           bool
+	// for RollbackCursor
+	current, last pageStateType // current and last page
+	layer         int
+	outlines      int
+	sections      int
+}
+
+// pageStateType records the content of a page for RollbackCursor().
+type pageStateType struct {
+	content     []byte // copy of the content of a page that grows with its content
+	contentLen  int
+	body        pageBodyType
+	links       int
+	attachments int
+	textObjs    int
+	textMap     int
+	redactions  int
+	extent      extentType
+	extended    bool
+}
+
+// SaveCursor returns the current page, position, font, colors, line width
+// and cell margin so that they can be given back with RestoreCursor() or
+// RollbackCursor(). This lets layout helpers write content tentatively, for
+// example to measure the height of a block or to find out whether it fits on
+// the current page, and return to where they started even if page breaks
+// occurred.
+func (f *Fpdf) SaveCursor() (c CursorState) {
+	c = CursorState{
+		page: f.page, pages: f.PageCount(),
+		k: f.k,
+		x: f.x, y: f.y,
+		cMargin:   f.cMargin,
+		lineWidth: f.lineWidth,
+
+		fontFamily: f.fontFamily, fontStyle: f.fontStyle, fontSynth: f.fontSynth,
+		underline: f.underline, strikeout: f.strikeout,
+		fontSizePt: f.fontSizePt, fontSize: f.fontSize,
+		currentFont:   f.currentFont,
+		isCurrentUTF8: f.isCurrentUTF8,
+		textTransform: f.textTransform,
+
+		decoration: f.decoration, decorationColor: f.decorationColor,
+		textBg: f.textBg, textBgPad: f.textBgPad,
+
+		draw: f.color.draw, fill: f.color.fill, text: f.color.text,
+		colorFlag: f.colorFlag,
+
+		w: f.w, h: f.h, wPt: f.wPt, hPt: f.hPt,
+		pageBreakTrigger: f.pageBreakTrigger,
+		curOrientation:   f.curOrientation,
+		curPageSize:      f.curPageSize,
+		autoHt:           f.autoHt,
+
+		layer:    f.layer.currentLayer,
+		outlines: len(f.outlines),
+		sections: len(f.sections),
+	}
+	if f.page > 0 {
+		c.current = f.pageState(f.page, f.autoHt)
+		// A page break ends the last page, which is not the current one after
+		// SetPage().
+		c.last = f.pageState(c.pages, f.autoHt && c.pages == f.page)
+	}
+	return
+}
+
+// pageState returns the state of the content of page n. The content itself
+// is copied if the page grows with its content, because it is moved when its
+// final height is known.
+func (f *Fpdf) pageState(n int, grows bool) (s pageStateType) {
+	s.contentLen = f.pages[n].Len()
+	if grows {
+		s.content = append([]byte(nil), f.pages[n].Bytes()...)
+	}
+	s.body = f.pageBody[n]
+	s.links = len(f.pageLinks[n])
+	s.attachments = len(f.pageAttachments[n])
+	s.textObjs = len(f.textObjs[n])
+	s.textMap = len(f.textMap[n])
+	s.redactions = len(f.redactions[n])
+	s.extent, s.extended = f.pageExtents[n]
+	return
+}
+
+// setPageState gives page n back the state s of its content.
+func (f *Fpdf) setPageState(n int, s pageStateType) {
+	if s.content != nil {
+		f.pages[n] = bytes.NewBuffer(s.content)
+	} else {
+		f.pages[n].Truncate(s.contentLen)
+	}
+	f.pageBody[n] = s.body
+	f.pageLinks[n] = f.pageLinks[n][:s.links]
+	f.pageAttachments[n] = f.pageAttachments[n][:s.attachments]
+	if s.textObjs < len(f.textObjs[n]) {
+		f.textObjs[n] = f.textObjs[n][:s.textObjs]
+	}
+	if s.textMap < len(f.textMap[n]) {
+		f.textMap[n] = f.textMap[n][:s.textMap]
+	}
+	if s.redactions < len(f.redactions[n]) {
+		f.redactions[n] = f.redactions[n][:s.redactions]
+	}
+	if s.extended {
+		f.pageExtents[n] = s.extent
+	} else {
+		delete(f.pageExtents, n)
+	}
+}
+
+// RestoreCursor makes the page, position, font, colors, line width and cell
+// margin saved by SaveCursor() current again. The content written since then
+// is kept: a page break that occurred in between leaves the added pages in
+// the document, and writing continues on the saved page as after SetPage().
+func (f *Fpdf) RestoreCursor(c CursorState) {
+	if f.err != nil {
+		return
+	}
+	if c.page > f.PageCount() {
+		f.errorf("RestoreCursor", "page %d of the saved state no longer exists", c.page)
+		return
+	}
+	f.restoreCursor(c)
+	if f.page > 0 {
+		f.outState()
+	}
+}
+
+// RollbackCursor returns the document to the state saved by SaveCursor(),
+// as RestoreCursor() does, and discards everything written since then: the
+// content added to the saved page, including its footer if a page break
+// occurred, the pages added after it, and the bookmarks and sections begun
+// since. Links created with AddLink() and anchors are kept, and must not
+// refer to discarded pages.
+func (f *Fpdf) RollbackCursor(c CursorState) {
+	if f.err != nil {
+		return
+	}
+	if c.pages > f.PageCount() {
+		f.errorf("RollbackCursor", "the saved state is not part of the document")
+		return
+	}
+	for n := c.pages + 1; n <= f.PageCount(); n++ {
+		delete(f.pageSizes, n)
+		delete(f.pageBoxes, n)
+		delete(f.pageOrigins, n)
+		delete(f.pageExtents, n)
+		delete(f.textObjs, n)
+		delete(f.textMap, n)
+		delete(f.redactions, n)
+	}
+	if f.PageCount() > c.pages {
+		f.pages = f.pages[:c.pages+1]
+		f.pageLinks = f.pageLinks[:c.pages+1]
+		f.pageAttachments = f.pageAttachments[:c.pages+1]
+		f.pageBody = f.pageBody[:c.pages+1]
+	}
+	if c.page > 0 {
+		r := c.k / f.k
+		c.last.extent, c.current.extent = c.last.extent.scale(r), c.current.extent.scale(r)
+		f.setPageState(c.pages, c.last)
+		f.setPageState(c.page, c.current)
+		f.state = 2
+	} else if f.state == 2 {
+		f.state = 1
+	}
+	if c.outlines < len(f.outlines) {
+		f.outlines = f.outlines[:c.outlines]
+	}
+	if c.sections < len(f.sections) {
+		f.sections = f.sections[:c.sections]
+	}
+	f.layer.currentLayer = c.layer
+	// The content of the page ends in the saved state again, so it need not
+	// be written.
+	f.restoreCursor(c)
+}
+
+// restoreCursor sets the fields saved in c. The lengths are converted to the
+// current unit, which WithUnit() may have changed since.
+func (f *Fpdf) restoreCursor(c CursorState) {
+	r := c.k / f.k
+	f.page = c.page
+	f.x, f.y = c.x*r, c.y*r
+	f.cMargin = c.cMargin * r
+	f.lineWidth = c.lineWidth * r
+
+	f.fontFamily, f.fontStyle, f.fontSynth = c.fontFamily, c.fontStyle, c.fontSynth
+	f.textTransform = c.textTransform
+	f.decoration, f.decorationColor = c.decoration, c.decorationColor
+	f.textBg, f.textBgPad = c.textBg, c.textBgPad*r
+	f.underline, f.strikeout = c.underline, c.strikeout
+	f.fontSizePt, f.fontSize = c.fontSizePt, c.fontSize*r
+	f.currentFont = c.currentFont
+	f.isCurrentUTF8 = c.isCurrentUTF8
+
+	f.color.draw, f.color.fill, f.color.text = c.draw, c.fill, c.text
+	f.colorFlag = c.colorFlag
+
+	f.w, f.h, f.wPt, f.hPt = c.w*r, c.h*r, c.wPt, c.hPt
+	f.pageBreakTrigger = c.pageBreakTrigger * r
+	f.curOrientation = c.curOrientation
+	f.curPageSize = c.curPageSize
+	f.autoHt = c.autoHt
+}
+
+// BeginDryRun starts laying out content for measurement only. The content
+// written until EndDryRun() is called goes through the complete layout logic,
+// including line wrapping, automatic page breaks, headers and footers, but
+// it is discarded by EndDryRun(), which returns the document to the state it
+// was in when BeginDryRun() was called. This makes it possible to decide
+// whether a section fits on the current page before writing it.
+//
+// Header and footer functions run as usual during a dry run; they can use
+// InDryRun() to skip side effects such as counting pages. Images and fonts
+// used during a dry run remain registered. Dry runs cannot be nested.
+func (f *Fpdf) BeginDryRun() {
+	if f.err != nil {
+		return
+	}
+	if f.dryRun != nil {
+		f.errorf("BeginDryRun", "a dry run is already in progress")
+		return
+	}
+	c := f.SaveCursor()
+	f.dryRun = &c
+}
+
+// EndDryRun ends the dry run started by BeginDryRun() and discards the
+// content written during it. It returns the number of pages the content
+// spans, 1 if it fits on the page where the dry run started, and the current
+// vertical position when the dry run ended, on the last of these pages.
+func (f *Fpdf) EndDryRun() (pages int, y float64) {
+	if f.dryRun == nil {
+		f.errorf("EndDryRun", "no dry run is in progress")
+		return
+	}
+	c := *f.dryRun
+	f.dryRun = nil
+	pages, y = 1+f.PageCount()-c.pages, f.y
+	f.RollbackCursor(c)
+	return
+}
+
+// InDryRun returns whether content is being laid out between BeginDryRun()
+// and EndDryRun().
+func (f *Fpdf) InDryRun() bool {
+	return f.dryRun != nil
+}
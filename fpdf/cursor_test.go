@@ -0,0 +1,137 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestRollbackCursor(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-30)
+		pdf.CellFormat(0, 10, "FOOTER", "", 0, "C", false, 0, "")
+	})
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.CellFormat(0, 20, "kept", "", 1, "", false, 0, "")
+	c := pdf.SaveCursor()
+	x, y := pdf.GetXY()
+
+	// Dry run a block that overflows the page to measure it.
+	pdf.SetFont("Courier", "B", 20)
+	pdf.SetTextColor(255, 0, 0)
+	for j := 0; j < 50; j++ {
+		pdf.CellFormat(0, 20, "DRYRUN", "", 1, "", false, 0, "")
+	}
+	if pdf.PageCount() != 2 {
+		t.Fatalf("expected the dry run to break the page, got %d pages", pdf.PageCount())
+	}
+	pdf.RollbackCursor(c)
+	if pdf.PageCount() != 1 || pdf.PageNo() != 1 {
+		t.Errorf("pages not discarded: got %d pages, on page %d", pdf.PageCount(), pdf.PageNo())
+	}
+	if gx, gy := pdf.GetXY(); gx != x || gy != y {
+		t.Errorf("position not restored: got=%.2f %.2f expected=%.2f %.2f", gx, gy, x, y)
+	}
+	if size, _ := pdf.GetFontSize(); size != 12 {
+		t.Errorf("font size not restored: got=%.2f", size)
+	}
+	if r, g, b := pdf.GetTextColor(); r != 0 || g != 0 || b != 0 {
+		t.Errorf("text color not restored: got=%d %d %d", r, g, b)
+	}
+	pdf.CellFormat(0, 20, "after", "", 1, "", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "DRYRUN") {
+		t.Errorf("output contains discarded content")
+	}
+	if n := strings.Count(out, "(FOOTER)"); n != 1 {
+		t.Errorf("footer written %d times, expected once", n)
+	}
+	for _, s := range []string{"(kept)", "(after)", "/Count 1"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
+
+func TestRestoreCursor(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.SetDrawColor(0, 0, 255)
+	c := pdf.SaveCursor()
+	pdf.SetDrawColor(255, 0, 0)
+	pdf.SetCellMargin(5)
+	pdf.AddPage()
+	pdf.RestoreCursor(c)
+	if pdf.PageCount() != 2 || pdf.PageNo() != 1 {
+		t.Errorf("expected page 1 of 2, got page %d of %d", pdf.PageNo(), pdf.PageCount())
+	}
+	if r, g, b := pdf.GetDrawColor(); r != 0 || g != 0 || b != 255 {
+		t.Errorf("draw color not restored: got=%d %d %d", r, g, b)
+	}
+	if m := pdf.GetCellMargin(); m == 5 {
+		t.Errorf("cell margin not restored")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// The restored state is written back to page 1.
+	if n := strings.Count(buf.String(), "0.000 0.000 1.000 RG"); n != 2 {
+		t.Errorf("restored draw color written %d times, expected twice", n)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.SetY(700)
+	section := func() {
+		pdf.MultiCell(0, 20, strings.Repeat("Lorem ipsum dolor sit amet. ", 40), "", "", false)
+	}
+
+	pdf.BeginDryRun()
+	if !pdf.InDryRun() {
+		t.Errorf("expected a dry run in progress")
+	}
+	section()
+	pages, y := pdf.EndDryRun()
+	if pages != 2 || y <= 28.35 || y >= 700 {
+		t.Errorf("unexpected measure: got %d pages, y=%.2f", pages, y)
+	}
+	if pdf.InDryRun() || pdf.PageCount() != 1 || pdf.GetY() != 700 {
+		t.Errorf("dry run not discarded: got %d pages, y=%.2f", pdf.PageCount(), pdf.GetY())
+	}
+
+	// The section fits on a new page.
+	pdf.SetY(100)
+	pdf.BeginDryRun()
+	section()
+	pages, y = pdf.EndDryRun()
+	if pages != 1 {
+		t.Errorf("expected the section to fit, got %d pages", pages)
+	}
+	section()
+	if pdf.GetY() != y {
+		t.Errorf("measured y=%.2f, written y=%.2f", y, pdf.GetY())
+	}
+
+	pdf.EndDryRun()
+	if !pdf.Err() {
+		t.Errorf("expected error for EndDryRun without BeginDryRun")
+	}
+}
@@ -0,0 +1,79 @@
+package fpdf
+
+// SetDebugLayout turns on or off the layout overlay that eases the debugging
+// of page layouts. While it is on, the border of every cell written with
+// CellFormat() and its variants is drawn in red, with a small cross at the
+// position where the cell starts, and every page that ends is overlaid with
+// translucent bands over its margins, a blue baseline grid, spaced by the
+// current font size, that runs from the top margin to the bottom margin, and
+// a cross at the final current position.
+//
+// The overlay is drawn on top of the content and the footer of a page if the
+// mode is on when the page ends, so that it can be turned on or off for
+// single pages. It changes neither the current position nor the graphics
+// state and is not counted in the extents returned by GetContentExtents().
+// Nothing is written while the mode is off, which is the default, so it can
+// stay in the code of an application and be turned off in production.
+func (f *Fpdf) SetDebugLayout(on bool) {
+	f.debugLayout = on
+}
+
+// GetDebugLayout returns whether the layout overlay set with SetDebugLayout()
+// is on.
+func (f *Fpdf) GetDebugLayout() bool {
+	return f.debugLayout
+}
+
+// debugCross returns the operators that draw a small cross centered on
+// (x, y) with the current stroke settings.
+func (f *Fpdf) debugCross(x, y float64) string {
+	const size = 3 // half the width of the cross in points
+	x, y = x*f.k, (f.h-y)*f.k
+	b := append(appendFloats(nil, f.prec.Path, x-size, y), "m "...)
+	b = append(appendFloats(b, f.prec.Path, x+size, y), "l "...)
+	b = append(appendFloats(b, f.prec.Path, x, y-size), "m "...)
+	return string(append(appendFloats(b, f.prec.Path, x, y+size), "l S "...))
+}
+
+// debugCell returns the operators that outline the cell of width w and
+// height h at the current position when the layout overlay is on.
+func (f *Fpdf) debugCell(w, h float64) string {
+	if !f.debugLayout {
+		return ""
+	}
+	b := appendFloats([]byte("q 0.3 w 1 0 0 RG [2 1] 0 d "), f.prec.Path, f.x*f.k, (f.h-f.y)*f.k, w*f.k, -h*f.k)
+	return string(b) + "re S " + f.debugCross(f.x, f.y) + "Q "
+}
+
+// debugPage overlays the current page with its margins, a baseline grid and
+// the current position when the layout overlay is on. It is called when the
+// page ends, after its footer has been written.
+func (f *Fpdf) debugPage() {
+	if !f.debugLayout || f.err != nil {
+		return
+	}
+	k := f.k
+	alpha, blendMode := f.alpha, f.blendMode
+	f.out("q")
+	f.SetAlpha(0.25, "Normal")
+	f.alpha, f.blendMode = alpha, blendMode
+	// Margins, as the page rectangle less the area within the margins
+	f.put("1 0.6 0 rg 0 0 ")
+	f.putF64s(f.prec.Path, f.w*k, f.h*k)
+	f.put("re ")
+	f.putF64s(f.prec.Path, f.lMargin*k, f.bMargin*k, (f.w-f.lMargin-f.rMargin)*k, (f.h-f.tMargin-f.bMargin)*k)
+	f.put("re f*\n")
+	f.out("0 0 1 RG 0.2 w [] 0 d")
+	if step := f.fontSize; step > 0 {
+		var s fmtBuffer
+		for y := f.tMargin + step; y <= f.h-f.bMargin; y += step {
+			s.Write(appendFloats(nil, f.prec.Path, f.lMargin*k, (f.h-y)*k))
+			s.printf("m ")
+			s.Write(appendFloats(nil, f.prec.Path, (f.w-f.rMargin)*k, (f.h-y)*k))
+			s.printf("l ")
+		}
+		s.printf("S")
+		f.out(s.String())
+	}
+	f.outf("1 0 0 RG 0.5 w %sQ", f.debugCross(f.x, f.y))
+}
@@ -0,0 +1,55 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetDebugLayout(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetDebugLayout(true)
+	pdf.AddPage()
+	pdf.SetXY(50, 60)
+	pdf.CellFormat(100, 20, "debug", "", 0, "", false, 0, "")
+	if x, y := pdf.GetXY(); x != 150 || y != 60 {
+		t.Errorf("current position moved: got=%.2f %.2f", x, y)
+	}
+	box, _ := pdf.GetContentExtents(1)
+	// Page 2 is written without the overlay.
+	pdf.AddPage()
+	pdf.SetDebugLayout(false)
+	pdf.SetXY(50, 60)
+	pdf.CellFormat(100, 20, "plain", "", 0, "", false, 0, "")
+	if alpha, mode := pdf.GetAlpha(); alpha != 1 || mode != "Normal" {
+		t.Errorf("alpha not restored: got=%.3f %s", alpha, mode)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "1 0 0 RG [2 1] 0 d 50.00 781.89 100.00 -20.00 re S"); n != 1 {
+		t.Errorf("cell outline written %d times, expected once", n)
+	}
+	if n := strings.Count(out, "1 0.6 0 rg"); n != 1 {
+		t.Errorf("margins written %d times, expected once", n)
+	}
+	if !strings.Contains(out, "/ca 0.250 /CA 0.250") {
+		t.Errorf("output does not contain the overlay transparency")
+	}
+	// The final position of page 1 is marked after the cell.
+	if !strings.Contains(out, "147.00 781.89 m 153.00 781.89 l") {
+		t.Errorf("output does not contain the current position marker")
+	}
+	// The text alone gives the extents.
+	if box.X <= 50 || math.Abs(box.Wd-pdf.GetStringWidth("debug")) > 1e-9 {
+		t.Errorf("overlay counted in extents: got=%+v", box)
+	}
+}
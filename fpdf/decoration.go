@@ -0,0 +1,90 @@
+package fpdf
+
+import "math"
+
+// DecorationLine is the kind of line drawn by the underline and strikeout
+// styles, see SetDecorationStyle().
+type DecorationLine int
+
+const (
+	// DecorationSolid draws a single line.
+	DecorationSolid DecorationLine = iota
+	// DecorationDouble draws two parallel lines.
+	DecorationDouble
+	// DecorationWavy draws a wavy line.
+	DecorationWavy
+)
+
+// DecorationStyle describes how the lines of underlined and struck out text,
+// selected with the "U" and "S" font styles, are drawn.
+type DecorationStyle struct {
+	Underline DecorationLine // kind of underline
+	Strikeout DecorationLine // kind of strikeout line
+	// Color is the color of the lines. If nil, they are drawn in the text
+	// color.
+	Color *RGBType
+}
+
+// SetDecorationStyle sets how the lines of underlined and struck out text are
+// drawn: single, double or wavy, and in the text color or a color of their
+// own. The style applies to the text printed after it is set, so that each
+// fragment of a paragraph written with Write() can have its own, and stays
+// in effect until it is changed. The thickness of the lines is set with
+// SetUnderlineThickness(). The default is a solid line in the text color.
+func (f *Fpdf) SetDecorationStyle(style DecorationStyle) {
+	f.decoration = style
+	f.decorationColor = ""
+	if style.Color != nil {
+		c := style.Color
+		f.decorationColor = f.rgbColorValue(c.R, c.G, c.B, "g", "rg").str
+	}
+}
+
+// GetDecorationStyle returns the style set with SetDecorationStyle().
+func (f *Fpdf) GetDecorationStyle() DecorationStyle {
+	return f.decoration
+}
+
+// decorationLine returns the operators that draw a line of kind line and
+// thickness t, in points, below the baseline position (x, y), in points,
+// over width w, in points.
+func (f *Fpdf) decorationLine(line DecorationLine, x, y, w, t float64) (s string) {
+	switch line {
+	case DecorationDouble:
+		b := append(appendFloats(nil, f.prec.Path, x, y, w, -t), "re f "...)
+		s = string(append(appendFloats(b, f.prec.Path, x, y-2*t, w, -t), "re f"...))
+	case DecorationWavy:
+		// Half waves of a cubic curve whose peaks are at amplitude a.
+		a := math.Max(t, 0.5)
+		p := 2 * a
+		var buf fmtBuffer
+		buf.Write(appendFloats(nil, f.prec.Path, t))
+		buf.printf("w ")
+		buf.Write(appendFloats(nil, f.prec.Curve, x, y-a))
+		buf.printf("m")
+		for j := 0; float64(j)*p < w; j++ {
+			x0 := x + float64(j)*p
+			x1 := math.Min(x0+p, x+w)
+			peak := y - a + 4*a/3
+			if j%2 == 1 {
+				peak = y - a - 4*a/3
+			}
+			buf.printf(" ")
+			buf.Write(appendFloats(nil, f.prec.Curve, x0+(x1-x0)/3, peak, x0+2*(x1-x0)/3, peak, x1, y-a))
+			buf.printf("c")
+		}
+		buf.printf(" S")
+		s = buf.String()
+	default:
+		s = string(append(appendFloats(nil, f.prec.Path, x, y, w, -t), "re f"...))
+	}
+	switch {
+	case line == DecorationWavy && f.decorationColor != "":
+		s = sprintf("q %s %s Q", strokeColorStr(f.decorationColor), s)
+	case line == DecorationWavy:
+		s = sprintf("q %s %s Q", strokeColorStr(f.color.text.str), s)
+	case f.decorationColor != "":
+		s = sprintf("q %s %s Q", f.decorationColor, s)
+	}
+	return
+}
@@ -0,0 +1,76 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetDecorationStyle(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "U", 12)
+	pdf.AddPage()
+	pdf.SetTextColor(0, 0, 255)
+	pdf.Write(20, "solid ")
+	pdf.SetDecorationStyle(fpdf.DecorationStyle{Underline: fpdf.DecorationDouble, Color: &fpdf.RGBType{R: 255}})
+	pdf.Write(20, "double ")
+	pdf.SetDecorationStyle(fpdf.DecorationStyle{Underline: fpdf.DecorationWavy})
+	pdf.Write(20, "wavy")
+	if style := pdf.GetDecorationStyle(); style.Underline != fpdf.DecorationWavy || style.Color != nil {
+		t.Errorf("unexpected style: %+v", style)
+	}
+	pdf.SetFont("", "S", 0)
+	pdf.SetDecorationStyle(fpdf.DecorationStyle{Strikeout: fpdf.DecorationDouble, Color: &fpdf.RGBType{G: 128}})
+	pdf.Text(50, 200, "struck out")
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, " re f") || strings.Contains(line, " c S") {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 decorated texts, got %d", len(lines))
+	}
+	// The solid underline is drawn in the text color.
+	if !strings.HasPrefix(lines[0], "q 0.000 0.000 1.000 rg ") || strings.Count(lines[0], "re f") != 1 {
+		t.Errorf("unexpected solid underline: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "q 1.000 0.000 0.000 rg ") || strings.Count(lines[1], "re f") != 2 {
+		t.Errorf("unexpected double underline: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], "q 0.000 0.000 1.000 RG ") || !strings.Contains(lines[2], " c S Q") {
+		t.Errorf("unexpected wavy underline: %s", lines[2])
+	}
+	if !strings.Contains(lines[3], "q 0.000 0.502 0.000 rg ") || strings.Count(lines[3], "re f") != 2 {
+		t.Errorf("unexpected double strikeout: %s", lines[3])
+	}
+}
+
+func TestJustifiedUnderlineWidth(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.SetFont("dejavu", "U", 12)
+	pdf.AddPage()
+	pdf.SetCellMargin(0)
+	pdf.CellFormat(300, 20, "justified text", "", 1, "J", false, 0, "")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// The underline spans the stretched text.
+	if !strings.Contains(buf.String(), " 300.00 -") {
+		t.Errorf("underline does not span the cell")
+	}
+}
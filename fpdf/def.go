@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"io/fs"
 	"math"
 	"path"
 
@@ -25,6 +26,9 @@ type gradientType struct {
 	clr1Str, clr2Str  string
 	x1, y1, x2, y2, r float64
 	objNum            int
+	stops             []GradientStop // color stops, replacing clr1Str and clr2Str when set
+	cmyk              bool
+	extend            GradientExtend
 }
 
 type RootDirectoryType string // RootDirectoryType is the root directory of the executable default is "." but test can set it to a different directory
@@ -134,8 +138,8 @@ const (
 	AlignBottom = "B"
 	// AlignMiddle aligns the cell to the middle
 	AlignMiddle = "M"
-	// AlignBaseline aligns the cell to the baseline
-	AlignBaseline = "B"
+	// AlignBaseline centers the baseline of the text in the cell
+	AlignBaseline = "A"
 )
 
 type colorMode int
@@ -174,6 +178,18 @@ type SizeType struct {
 // PageSize specifies the dimensions and properties of a page.
 // Wd and Ht specify the horizontal and vertical extents in points.
 // AutoHt indicates if the page height should grow automatically based on content.
+//
+// A page with AutoHt set has no fixed height: content is laid out from the
+// top without automatic page breaks until the largest page height supported
+// by PDF is reached (see SetRollMode()), and when the page ends its height is
+// set to the current vertical position plus the bottom margin, as on the
+// continuous paper of a thermal printer. Content flowing with Cell(),
+// MultiCell(), Write(), Ln() or Image() moves the current position; elements
+// drawn elsewhere should be followed by SetY() so that they fall inside the
+// page. The footer is printed once the page has its final height, so SetY()
+// with a negative value places it below the content. Until then Ht serves as
+// the page height. Pages with an origin box set by SetOriginBox() keep the
+// size of the box.
 type PageSize struct {
 	Wd, Ht float64
 	AutoHt bool // For cases where page size needs to grow automatically (e.g., thermal printer paper)
@@ -214,20 +230,21 @@ func (ps PageSize) Height() float64 {
 // Changes to this structure should be reflected in its GobEncode and GobDecode
 // methods.
 type ImageInfoType struct {
-	data  []byte  // Raw image data
-	smask []byte  // Soft Mask, an 8bit per-pixel transparency mask
-	n     int     // Image object number
-	w     float64 // Width
-	h     float64 // Height
-	cs    string  // Color space
-	pal   []byte  // Image color palette
-	bpc   int     // Bits Per Component
-	f     string  // Image filter
-	dp    string  // DecodeParms
-	trns  []int   // Transparency mask
-	scale float64 // Document scale factor
-	dpi   float64 // Dots-per-inch found from image file (png only)
-	i     string  // SHA-1 checksum of the above values.
+	data  []byte   // Raw image data
+	smask []byte   // Soft Mask, an 8bit per-pixel transparency mask
+	n     int      // Image object number
+	w     float64  // Width
+	h     float64  // Height
+	cs    string   // Color space
+	pal   []byte   // Image color palette
+	bpc   int      // Bits Per Component
+	f     string   // Image filter
+	dp    string   // DecodeParms
+	trns  []int    // Transparency mask
+	scale float64  // Document scale factor
+	dpi   float64  // Dots-per-inch found from image file (png only)
+	i     string   // SHA-1 checksum of the above values.
+	tint  *RGBType // Colors rendered as shades of this color, see ImageOptions
 }
 
 type idEncoder struct {
@@ -295,6 +312,143 @@ func (f *Fpdf) UnitToPointConvert(u float64) (pt float64) {
 	return u * f.k
 }
 
+// unitScale returns the number of points in one u.
+func unitScale(u unit) (k float64, ok bool) {
+	switch u {
+	case POINT:
+		return 1.0, true
+	case MM:
+		return 72.0 / 25.4, true
+	case CM:
+		return 72.0 / 2.54, true
+	case IN:
+		return 72.0, true
+	}
+	return 0, false
+}
+
+// WithUnit calls fn with u (POINT, MM, CM or IN) as the unit of measure for
+// all coordinates, sizes, margins and positions, and then restores the
+// previous unit. This allows mixed-unit layouts, for example points for
+// typography and millimeters for page furniture, without converting every
+// value by hand:
+//
+//	pdf.WithUnit(fpdf.POINT, func() {
+//		pdf.Rect(72, 72, 144, 36, "D")
+//	})
+//
+// The current position and other state values are converted on entry and on
+// exit, so a position reached inside fn is preserved in the outer unit.
+// WithUnit calls may be nested.
+func (f *Fpdf) WithUnit(u unit, fn func()) {
+	if f.err != nil {
+		return
+	}
+	k, ok := unitScale(u)
+	if !ok {
+		f.errorf("WithUnit", "invalid unit: %s", string(u))
+		return
+	}
+	prevUnit, prevK := f.unitType, f.k
+	f.rescale(u, k)
+	fn()
+	f.rescale(prevUnit, prevK)
+}
+
+// RectU is Rect() with x, y, w and h expressed in the unit u instead of the
+// unit of the document:
+//
+//	pdf.RectU(fpdf.MM, 10, 10, 50, 20, "D")
+func (f *Fpdf) RectU(u unit, x, y, w, h float64, styleStr string) {
+	f.WithUnit(u, func() {
+		f.Rect(x, y, w, h, styleStr)
+	})
+}
+
+// LineU is Line() with the coordinates expressed in the unit u instead of the
+// unit of the document.
+func (f *Fpdf) LineU(u unit, x1, y1, x2, y2 float64) {
+	f.WithUnit(u, func() {
+		f.Line(x1, y1, x2, y2)
+	})
+}
+
+// TextU is Text() with x and y expressed in the unit u instead of the unit of
+// the document.
+func (f *Fpdf) TextU(u unit, x, y float64, txtStr string) {
+	f.WithUnit(u, func() {
+		f.Text(x, y, txtStr)
+	})
+}
+
+// rescale changes the scale factor to k and converts every stored value that
+// is expressed in user units accordingly.
+func (f *Fpdf) rescale(u unit, k float64) {
+	r := f.k / k
+	f.unitType = u
+	f.k = k
+	f.w *= r
+	f.h *= r
+	f.lMargin *= r
+	f.tMargin *= r
+	f.rMargin *= r
+	f.bMargin *= r
+	f.cMargin *= r
+	f.x *= r
+	f.y *= r
+	f.lasth *= r
+	f.lineWidth *= r
+	f.fontSize *= r
+	f.ws *= r
+	f.textBgPad *= r
+	f.pageBreakTrigger *= r
+	f.pageTop.X *= r
+	f.pageTop.Y *= r
+	for j := range f.exclusions {
+		ez := &f.exclusions[j]
+		ez.x, ez.y, ez.wd, ez.ht = ez.x*r, ez.y*r, ez.wd*r, ez.ht*r
+	}
+	for name, a := range f.anchors {
+		a.x, a.y = a.x*r, a.y*r
+		f.anchors[name] = a
+	}
+	for name, p := range f.placeholders {
+		p.x, p.y, p.wd, p.ht = p.x*r, p.y*r, p.wd*r, p.ht*r
+		f.placeholders[name] = p
+	}
+	for j := range f.links {
+		f.links[j].x *= r
+		f.links[j].y *= r
+	}
+	for j := range f.outlines {
+		f.outlines[j].y *= r
+	}
+	for j := range f.gradientPaints {
+		gp := &f.gradientPaints[j]
+		gp.x, gp.y, gp.w, gp.h = gp.x*r, gp.y*r, gp.w*r, gp.h*r
+	}
+	for n, e := range f.pageExtents {
+		f.pageExtents[n] = e.scale(r)
+	}
+	for _, objs := range f.textObjs {
+		for j := range objs {
+			objs[j].box = objs[j].box.scale(r)
+		}
+	}
+	for _, entries := range f.textMap {
+		for j := range entries {
+			e := &entries[j].entry
+			e.X, e.Y, e.W, e.H = e.X*r, e.Y*r, e.W*r, e.H*r
+		}
+	}
+	for _, boxes := range f.redactions {
+		for j := range boxes {
+			boxes[j] = boxes[j].scale(r)
+		}
+	}
+	f.keepNext.extent = f.keepNext.extent.scale(r)
+}
+
 // Extent returns the width and height of the image in the units of the Fpdf
 // object.
 func (info *ImageInfoType) Extent() (wd, ht float64) {
@@ -329,13 +483,17 @@ type fontFileType struct {
 
 type linkType struct {
 	x, y, wd, ht float64
-	link         int    // Auto-generated internal link ID or...
-	linkStr      string // ...application-provided external link string
+	link         int          // Auto-generated internal link ID or...
+	linkStr      string       // ...application-provided external link string
+	filePage     int          // page of the external PDF file linkStr, 0 for a URL
+	options      *LinkOptions // appearance set by LinkOptions() and similar, nil for none
 }
 
 type intLinkType struct {
 	page int
-	y    float64
+	x, y float64
+	zoom float64 // zoom percentage of an "XYZ" destination, 0 to keep the current zoom
+	fit  string  // destination type set by SetLinkXYZ() or SetLinkFit(), "" for SetLink()
 }
 
 // outlineType is used for a sidebar outline of bookmarks
@@ -402,6 +560,8 @@ type Fpdf struct {
 	pages            []*bytes.Buffer                             // slice[page] of page content; 1-based
 	state            int                                         // current document state
 	compress         bool                                        // compression flag
+	optimize         bool                                        // collapse redundant operators of page content
+	strict           bool                                        // validate the values passed to the methods
 	k                float64                                     // scale factor (number of points in user unit)
 	defOrientation   orientationType                             // default orientation
 	curOrientation   orientationType                             // current orientation
@@ -432,6 +592,7 @@ type Fpdf struct {
 	coreFonts        map[string]bool                             // array of core font names
 	fonts            map[string]fontDefType                      // array of used fonts
 	fontFiles        map[string]fontFileType                     // array of font files
+	fontEmbedding    map[string]FontEmbedding                    // embedding policy by font family, see SetFontEmbedding
 	diffs            []string                                    // array of encoding differences
 	fontFamily       string                                      // current font family
 	fontStyle        string                                      // current font style
@@ -455,7 +616,9 @@ type Fpdf struct {
 	inHeader         bool                                        // flag set when processing header
 	headerFnc        func()                                      // function provided by app and called to write header
 	headerHomeMode   bool                                        // set position to home after headerFnc is called
+	pageTop          PointType                                   // position where content begins on the current page, after the header
 	inFooter         bool                                        // flag set when processing footer
+	inTemplate       bool                                        // flag set while CreateTemplate draws a template
 	footerFnc        func()                                      // function provided by app and called to write footer
 	footerFncLpi     func(bool)                                  // function provided by app and called to write footer with last page flag
 	zoomMode         string                                      // zoom display mode
@@ -467,6 +630,7 @@ type Fpdf struct {
 	subject          string                                      // subject
 	author           string                                      // author
 	lang             string                                      // lang
+	fileID           []byte                                      // identifier of the file, see SetFileID()
 	keywords         string                                      // keywords
 	creator          string                                      // creator
 	creationDate     pdfTime                                     // override for document CreationDate value
@@ -482,6 +646,7 @@ type Fpdf struct {
 	blendMode        string                                      // current blend mode
 	alpha            float64                                     // current transpacency
 	gradientList     []gradientType                              // slice[idx] of gradient records
+	gradientMap      map[string]int                              // map into gradientList
 	clipNest         int                                         // Number of active clipping contexts
 	transformNest    int                                         // Number of active transformation contexts
 	err              error                                       // Set if error occurs during life cycle of instance
@@ -490,15 +655,69 @@ type Fpdf struct {
 	catalogSort      bool                                        // sort resource catalogs in document
 	nJs              int                                         // JavaScript object number
 	javascript       *string                                     // JavaScript code to include in the PDF
+	stats            *Stats                                      // counters of the work done, see SetStats
+	sanitize         bool                                        // leave out JavaScript, external links and attachments
 	colorFlag        bool                                        // indicates whether fill and text colors are different
 	color            struct {
 		// Composite values of colors
 		draw, fill, text colorType
 	}
-	spotColorMap           map[string]spotColorType // Map of named ink-based colors
-	outputIntents          []OutputIntentType       // OutputIntents
-	outputIntentStartN     int                      // Start object number for
-	userUnderlineThickness float64                  // A custom user underline thickness multiplier.
+	spotColorMap           map[string]spotColorType   // Map of named ink-based colors
+	outputIntents          []OutputIntentType         // OutputIntents
+	outputIntentStartN     int                        // Start object number for
+	userUnderlineThickness float64                    // A custom user underline thickness multiplier.
+	exclusions             []exclusionType            // areas that flowing content must not print over
+	anchors                map[string]anchorType      // named positions recorded by MarkAnchor
+	placeholders           map[string]placeholderType // boxes reserved by AddPlaceholder
+	warnings               []error                    // non-fatal problems, see GetErrors
+	missingGlyphs          map[string]struct{}        // font name + rune already reported as missing
+	fallbackFont           string                     // key of the font printing the characters missing from the current font
+	multiCellCont          bool                       // close and reopen MultiCell borders at page breaks
+	multiCellMarker        func(closing bool)         // called at MultiCell page breaks in continuation mode
+	justifyLastLine        bool                       // justify the last line of WriteAligned with "J"
+	stationery             *Template                  // letterhead drawn under page content
+	stationeryScope        StationeryScope            // pages that receive the stationery
+	imposition             Imposition                 // arrangement of pages on output sheets
+	templates              []templateType             // templates, 1-based by Template.id
+	imposed                []imposedPage              // position of each page on the sheets, set at output
+	pageBody               []pageBodyType             // extent of the content of each page, 1-based
+	dropBlankPages         bool                       // leave out pages without content when closing
+	keepNext               keepNextType               // content dropped if the page breaks right after it
+	padMultiple            int                        // pad the page count to a multiple of this when closing
+	padStamp               string                     // text printed on padding pages
+	clonedPages            map[*Fpdf]map[int]int      // pages copied by ClonePage, by source document and page
+	cloneLinks             []cloneLinkType            // links of cloned pages to pages not cloned yet
+	originBox              string                     // page box whose corner is the origin of new pages
+	pageOrigins            map[int]PageBox            // origin box in points of the pages that have one
+	rollMode               bool                       // all pages grow with their content
+	autoHt                 bool                       // the current page grows with its content
+	imageLayers            map[string]int             // layer of the images placed on one, by image identifier
+	paletteObjs            map[string]int             // object number of the palettes written, by palette
+	gradientPaints         []gradientPaintType        // gradients added as paint, by paint identifier
+	patternList            []patternType              // shading patterns used by gradient paints
+	pageExtents            map[int]extentType         // bounding box of the content of each page
+	textObjs               map[int][]textObjType      // text objects written to each page
+	textMap                map[int][]textMapType      // text printed by OverlayText on each page
+	redactions             map[int][]extentType       // regions of each page whose text is removed
+	cellBuf                []byte                     // scratch space of CellFormat()
+	numBuf                 []byte                     // scratch space of putF64()
+	debugLayout            bool                       // overlay the layout on cells and pages
+	dryRun                 *CursorState               // state saved by BeginDryRun, nil outside of a dry run
+	sections               []sectionType              // sections begun by BeginSection
+	aliasSectionNbStr      string                     // alias for the number of pages of each section
+	fontFS                 fs.FS                      // file system from which fonts are loaded when first used
+	fontFSIndex            map[string]string          // font files of fontFS, by normalized name and extension
+	resourceFS             fs.FS                      // file system from which files are read, see SetResourceFS
+	fontSynthesis          bool                       // synthesize missing bold and italic styles
+	fontSynth              string                     // styles of the current font that are synthesized
+	textTransform          TextTransform              // transformation of the letters of printed text
+	decoration             DecorationStyle            // style of the underline and strikeout lines
+	decorationColor        string                     // color of the underline and strikeout lines, or empty
+	textBg                 colorType                  // color painted behind text, see SetTextBackgroundColor
+	textBgPad              float64                    // padding of the text background
+	locale                 *Locale                    // conventions used to format numbers and dates, or nil
+	mirrorDepth            int                        // nesting of left-to-right layouts within right-to-left mode
+	prec                   Precision                  // decimal places of the numbers of page content
 
 	fmt struct {
 		buf []byte       // buffer used to format numbers.
@@ -650,22 +869,23 @@ func (f *FontDescType) Pointers() []any {
 }
 
 type fontDefType struct {
-	Tp           string        // "Core", "TrueType", ...
-	Name         string        // "Courier-Bold", ...
-	Desc         FontDescType  // Font descriptor
-	Up           int           // Underline position
-	Ut           int           // Underline thickness
-	Cw           []int         // Character width by ordinal
-	Enc          string        // "cp1252", ...
-	Diff         string        // Differences from reference encoding
-	File         string        // "Redressed.z"
-	Size1, Size2 int           // Type1 values
-	OriginalSize int           // Size of uncompressed font file
-	N            int           // Set by font loader
-	DiffN        int           // Position of diff in app array, set by font loader
-	i            string        // 1-based position in font list, set by font loader, not this program
-	utf8File     *utf8FontFile // UTF-8 font
-	usedRunes    map[int]int   // Array of used runes
+	Tp           string           // "Core", "TrueType", ...
+	Name         string           // "Courier-Bold", ...
+	Desc         FontDescType     // Font descriptor
+	Up           int              // Underline position
+	Ut           int              // Underline thickness
+	Cw           []int            // Character width by ordinal
+	Enc          string           // "cp1252", ...
+	Diff         string           // Differences from reference encoding
+	File         string           // "Redressed.z"
+	Size1, Size2 int              // Type1 values
+	OriginalSize int              // Size of uncompressed font file
+	N            int              // Set by font loader
+	DiffN        int              // Position of diff in app array, set by font loader
+	i            string           // 1-based position in font list, set by font loader, not this program
+	utf8File     *utf8FontFile    // UTF-8 font
+	usedRunes    map[int]int      // Array of used runes
+	type3        []type3GlyphType // glyphs of a Type3 font, by code
 }
 
 func (f *fontDefType) Schema() []fmt.Field {
@@ -0,0 +1,73 @@
+package fpdf
+
+import "math"
+
+// DimensionLine draws the dimension of the distance between the points
+// (x1, y1) and (x2, y2) of a technical drawing: an extension line from each
+// point, a dimension line with arrowheads between them at the distance
+// offset from the points and labelStr centered above the dimension line.
+// Positive offsets place the dimension line to the left of the direction from
+// the first point to the second, as seen on the page, so that the dimension
+// of a horizontal distance measured from left to right is above it. If
+// labelStr is empty, the distance is written in the unit of measure
+// specified in New() with two decimals.
+//
+// The lines are drawn with the current draw color and line width, and the
+// label with the current font and text color, rotated along the dimension
+// line and kept readable from the bottom or the right of the page. The size
+// of the arrowheads and the gaps are proportional to the font size.
+func (f *Fpdf) DimensionLine(x1, y1, x2, y2, offset float64, labelStr string) {
+	if f.err != nil {
+		return
+	}
+	length := math.Hypot(x2-x1, y2-y1)
+	if length == 0 {
+		f.errorf("DimensionLine", "the points of a dimension must differ")
+		return
+	}
+	if labelStr == "" {
+		labelStr = f.fmtF64(length, 2)
+	}
+	// Unit vector from the first point to the second one and its normal
+	// towards the dimension line.
+	ux, uy := (x2-x1)/length, (y2-y1)/length
+	nx, ny := uy, -ux
+	if offset < 0 {
+		nx, ny, offset = -nx, -ny, -offset
+	}
+	arrow := f.fontSize * 0.6
+	gap := arrow / 3
+
+	// Extension lines start a little away from the points and end a little
+	// beyond the dimension line.
+	for _, p := range [][2]float64{{x1, y1}, {x2, y2}} {
+		if offset > gap {
+			f.Line(p[0]+nx*gap, p[1]+ny*gap, p[0]+nx*(offset+gap), p[1]+ny*(offset+gap))
+		}
+	}
+	ax, ay := x1+nx*offset, y1+ny*offset
+	bx, by := x2+nx*offset, y2+ny*offset
+	f.Line(ax, ay, bx, by)
+	// Open arrowheads pointing outwards at both ends.
+	x, y := f.x, f.y
+	for _, e := range [][3]float64{{ax, ay, 1}, {bx, by, -1}} {
+		dx, dy := ux*e[2]*arrow, uy*e[2]*arrow
+		f.MoveTo(e[0]+dx-dy/3, e[1]+dy+dx/3)
+		f.LineTo(e[0], e[1])
+		f.LineTo(e[0]+dx+dy/3, e[1]+dy-dx/3)
+		f.DrawPath("D")
+	}
+	f.x, f.y = x, y
+
+	// The label reads from left to right, or from bottom to top on vertical
+	// lines, whatever the order of the points.
+	rx, ry := ux, uy
+	if rx < 0 || (rx == 0 && ry > 0) {
+		rx, ry = -rx, -ry
+	}
+	mx, my := (ax+bx)/2, (ay+by)/2
+	f.TransformBegin()
+	f.TransformRotate(math.Atan2(-ry, rx)*180/math.Pi, mx, my)
+	f.Text(mx-f.GetStringWidth(labelStr)/2, my-gap, labelStr)
+	f.TransformEnd()
+}
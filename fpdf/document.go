@@ -2,7 +2,9 @@ package fpdf
 
 import (
 	"bytes"
+	"encoding/hex"
 	"io"
+	"math"
 	"sort"
 
 	. "github.com/tinywasm/fmt"
@@ -43,7 +45,7 @@ func (f *Fpdf) AddPageFormat(orientationStr orientationType, size PageSize) {
 		f.open()
 	}
 	familyStr := f.fontFamily
-	style := f.fontStyle
+	style := f.fontStyle + f.fontSynth
 	if f.underline {
 		style += "U"
 	}
@@ -58,20 +60,14 @@ func (f *Fpdf) AddPageFormat(orientationStr orientationType, size PageSize) {
 	cf := f.colorFlag
 
 	if f.page > 0 {
-		f.inFooter = true
-		// Page footer avoid double call on footer.
-		if f.footerFnc != nil {
-			f.footerFnc()
-
-		} else if f.footerFncLpi != nil {
-			f.footerFncLpi(false) // not last page.
-		}
-		f.inFooter = false
+		f.dropKeptWithNext()
+		f.pageFooter(false) // not last page.
 		// Close page
 		f.endpage()
 	}
 	// Start new page
 	f.beginpage(orientationStr, size)
+	f.applyOriginBox()
 	// 	Set line cap style to current value
 	// f.out("2 J")
 	f.outf("%d J", f.capStyle)
@@ -102,6 +98,7 @@ func (f *Fpdf) AddPageFormat(orientationStr orientationType, size PageSize) {
 	}
 	f.color.text = tc
 	f.colorFlag = cf
+	f.putStationery()
 	// 	Page header
 	if f.headerFnc != nil {
 		f.inHeader = true
@@ -111,6 +108,7 @@ func (f *Fpdf) AddPageFormat(orientationStr orientationType, size PageSize) {
 			f.SetHomeXY()
 		}
 	}
+	f.pageTop = PointType{X: f.x, Y: f.y}
 	// 	Restore line width
 	if f.lineWidth != lw {
 		f.lineWidth = lw
@@ -134,6 +132,7 @@ func (f *Fpdf) AddPageFormat(orientationStr orientationType, size PageSize) {
 	}
 	f.color.text = tc
 	f.colorFlag = cf
+	f.pageBody[f.page].start = f.pages[f.page].Len()
 }
 
 // AddPage adds a new page to the document. If a page is already present, the
@@ -165,6 +164,15 @@ func (f *Fpdf) PageNo() int {
 	return f.page
 }
 
+// AtPageTop returns true if the current position is still where content
+// begins on the current page, that is, immediately below the header. It can be
+// used to suppress elements such as dividers or spacing that should never be
+// the first thing on a page.
+func (f *Fpdf) AtPageTop() bool {
+	const eps = 1e-9
+	return f.page > 0 && math.Abs(f.x-f.pageTop.X) < eps && math.Abs(f.y-f.pageTop.Y) < eps
+}
+
 // GetPageSize returns the current page's width and height. This is the paper's
 // size. To compute the size of the area being used, subtract the margins (see
 // GetMargins()).
@@ -179,27 +187,12 @@ func (f *Fpdf) GetPageSize() (width, height float64) {
 // art and artbox box types are case insensitive. See SetPageBox() for a method
 // that specifies the coordinates and extent of the page box individually.
 func (f *Fpdf) SetPageBoxRec(t string, pb PageBox) {
-	switch Convert(t).ToLower().String() {
-	case "trim":
-		fallthrough
-	case "trimbox":
-		t = "TrimBox"
-	case "crop":
-		fallthrough
-	case "cropbox":
-		t = "CropBox"
-	case "bleed":
-		fallthrough
-	case "bleedbox":
-		t = "BleedBox"
-	case "art":
-		fallthrough
-	case "artbox":
-		t = "ArtBox"
-	default:
-		f.err = Errf("%s is not a valid page box type", t)
+	name := pageBoxName(t)
+	if name == "" || name == "MediaBox" {
+		f.errorf("SetPageBoxRec", "%s is not a valid page box type", t)
 		return
 	}
+	t = name
 
 	pb.X = pb.X * f.k
 	pb.Y = pb.Y * f.k
@@ -221,6 +214,65 @@ func (f *Fpdf) SetPageBox(t string, x, y, wd, ht float64) {
 	f.SetPageBoxRec(t, PageBox{SizeType{Wd: wd, Ht: ht}, PointType{X: x, Y: y}})
 }
 
+// GetPageBox returns the effective page box t of page pageNo (one-based), in
+// the unit of measure specified in New() and in the form accepted by
+// SetPageBox(): X and Y locate the lower left corner of the box relative to
+// the lower left corner of the page. Allowable types are those of
+// SetPageBox() and media or mediabox. A box that has not been set takes its
+// default value from the PDF specification: the media box is the page size,
+// the crop box defaults to the media box and the bleed, trim and art boxes
+// default to the crop box.
+func (f *Fpdf) GetPageBox(pageNo int, t string) (pb PageBox) {
+	name := pageBoxName(t)
+	if name == "" {
+		f.errorf("GetPageBox", "%s is not a valid page box type", t)
+		return
+	}
+	if pageNo < 1 || pageNo > f.PageCount() {
+		f.errorf("GetPageBox", "page %d does not exist", pageNo)
+		return
+	}
+	box := f.effectivePageBox(pageNo, name)
+	pb.X = box.X / f.k
+	pb.Y = box.Y / f.k
+	pb.Wd = (box.Wd - box.X) / f.k
+	pb.Ht = (box.Ht - box.Y) / f.k
+	return
+}
+
+// effectivePageBox returns the page box name of page n in points, with the
+// upper right corner in Wd and Ht as stored by SetPageBoxRec(), falling back
+// to the default box when it has not been set.
+func (f *Fpdf) effectivePageBox(n int, name string) PageBox {
+	boxes := f.pageBoxes[n]
+	box, ok := boxes[name]
+	if !ok && name != "MediaBox" && name != "CropBox" {
+		box, ok = boxes["CropBox"]
+	}
+	if !ok {
+		box.Wd, box.Ht = f.pageSizePt(n)
+	}
+	return box
+}
+
+// pageBoxName returns the PDF name of page box type t, or "" if t is not
+// valid.
+func pageBoxName(t string) string {
+	switch Convert(t).ToLower().String() {
+	case "trim", "trimbox":
+		return "TrimBox"
+	case "crop", "cropbox":
+		return "CropBox"
+	case "bleed", "bleedbox":
+		return "BleedBox"
+	case "art", "artbox":
+		return "ArtBox"
+	case "media", "mediabox":
+		return "MediaBox"
+	}
+	return ""
+}
+
 // SetPage sets the current page to that of a valid page in the PDF document.
 // pageNum is one-based. The SetPage() example demonstrates this method.
 func (f *Fpdf) SetPage(pageNum int) {
@@ -365,6 +417,7 @@ func (f *Fpdf) SetAutoPageBreak(auto bool, margin float64) {
 	f.autoPageBreak = auto
 	f.bMargin = margin
 	f.pageBreakTrigger = f.h - margin
+	f.autoHtBreakTrigger()
 }
 
 // SetProtection applies certain constraints on the finished PDF document.
@@ -390,6 +443,82 @@ func (f *Fpdf) SetProtection(actionFlag byte, userPassStr, ownerPassStr string)
 		return
 	}
 	f.protect.setProtection(actionFlag, userPassStr, ownerPassStr)
+	f.protectVersion()
+}
+
+// SetCertificateProtection encrypts the finished PDF document for the
+// recipients whose X.509 certificates are given by certs, so that it can only
+// be opened with the private key of one of them and no password has to be
+// shared. Each certificate is given in DER or PEM form and must hold an RSA
+// public key. The document is encrypted with a 128-bit key and requires PDF
+// version 1.4.
+//
+// actionFlag restricts the operations of the recipients as described for
+// SetProtection().
+func (f *Fpdf) SetCertificateProtection(actionFlag byte, certs ...[]byte) {
+	if f.err != nil {
+		return
+	}
+	if err := f.protect.setCertificateProtection(actionFlag, certs); err != nil {
+		f.errorf("SetCertificateProtection", "%v", err)
+		return
+	}
+	if f.pdfVersion < pdfVers1_4 {
+		f.pdfVersion = pdfVers1_4
+	}
+	f.protectVersion()
+}
+
+// SetEncryptMetadata sets whether the XMP metadata set with SetXmpMetadata()
+// is encrypted along with the content of a document protected with
+// SetProtection() or SetCertificateProtection(). It is encrypted by default.
+// Leaving it unencrypted lets archive and search systems read the metadata
+// without opening the document.
+//
+// An unencrypted metadata stream requires version 4 of the security handler,
+// with a 128-bit key, and PDF version 1.5. It can be set before or after the
+// protection.
+func (f *Fpdf) SetEncryptMetadata(encrypt bool) {
+	if f.err != nil {
+		return
+	}
+	if err := f.protect.setEncryptMetadata(encrypt); err != nil {
+		f.errorf("SetEncryptMetadata", "%v", err)
+		return
+	}
+	f.protectVersion()
+}
+
+// SetFileID sets the identifier of the document, written in the trailer as
+// both elements of its ID array, by which applications recognize the file
+// and its later versions. If id is empty, a random identifier of 16 bytes is
+// generated; a fixed one keeps the output of a document reproducible. By
+// default only protected documents have an identifier, which is empty.
+//
+// The standard security handler of SetProtection() derives the encryption
+// key from the identifier, so it can be set before or after the protection.
+func (f *Fpdf) SetFileID(id []byte) {
+	if f.err != nil {
+		return
+	}
+	if len(id) == 0 {
+		var err error
+		if id, err = randomFileID(); err != nil {
+			f.errorf("SetFileID", "%v", err)
+			return
+		}
+	}
+	f.fileID = append([]byte(nil), id...)
+	if err := f.protect.setFileID(f.fileID); err != nil {
+		f.errorf("SetFileID", "%v", err)
+	}
+}
+
+// protectVersion raises the PDF version to the one the protection requires.
+func (f *Fpdf) protectVersion() {
+	if f.protect.encrypted && f.protect.plainMetadata && f.pdfVersion < pdfVers1_5 {
+		f.pdfVersion = pdfVers1_5
+	}
 }
 
 // OutputAndClose sends the PDF document to the writer specified by w. This
@@ -425,7 +554,7 @@ func (f *Fpdf) OutputFileAndClose(fileStr string) error {
 	// Use the writeFile function to write the content
 	err := f.writeFile(fileStr, buf.Bytes())
 	if err != nil {
-		f.err = err
+		f.setError("OutputFileAndClose", err)
 		return f.err
 	}
 
@@ -446,7 +575,7 @@ func (f *Fpdf) Output(w io.Writer) error {
 	}
 	_, err := f.buffer.WriteTo(w)
 	if err != nil {
-		f.err = err
+		f.setError("Output", err)
 	}
 	return f.err
 }
@@ -465,7 +594,7 @@ func (f *Fpdf) getpagesizestr(sizeStr string) (size PageSize) {
 		size.Ht /= f.k
 
 	} else {
-		f.err = Errf("unknown page size %s", sizeStr)
+		f.errorf("GetPageSizeStr", "unknown page size %s", sizeStr)
 	}
 	return
 }
@@ -488,11 +617,14 @@ func (f *Fpdf) beginpage(newPageOrientation orientationType, size PageSize) {
 	f.pages = append(f.pages, bytes.NewBufferString(""))
 	f.pageLinks = append(f.pageLinks, make([]linkType, 0))
 	f.pageAttachments = append(f.pageAttachments, []annotationAttach{})
+	f.pageBody = append(f.pageBody, pageBodyType{end: -1})
 	f.state = 2
-	f.x = f.lMargin
+	f.x = f.lineStart()
 	f.y = f.tMargin
 	f.fontFamily = ""
-	if newPageOrientation != f.curOrientation || size.Wd != f.curPageSize.Wd || size.Ht != f.curPageSize.Ht {
+	// Pages with an origin box or growing with their content change f.h.
+	_, boxed := f.pageOrigins[f.page-1]
+	if boxed || f.autoHt || size.AutoHt || newPageOrientation != f.curOrientation || size.Wd != f.curPageSize.Wd || size.Ht != f.curPageSize.Ht {
 		// New size or orientation
 		// size is in points, convert to user units for f.w and f.h
 		if newPageOrientation == Portrait {
@@ -508,6 +640,8 @@ func (f *Fpdf) beginpage(newPageOrientation orientationType, size PageSize) {
 		f.curOrientation = newPageOrientation
 		f.curPageSize = size
 	}
+	f.autoHt = size.AutoHt || f.rollMode
+	f.autoHtBreakTrigger()
 	if newPageOrientation != f.defOrientation || size.Wd != f.defPageSize.Wd || size.Ht != f.defPageSize.Ht {
 		// Store the actual page dimensions (after orientation is applied) in points
 		// size is already in points, so no conversion needed
@@ -520,6 +654,25 @@ func (f *Fpdf) beginpage(newPageOrientation orientationType, size PageSize) {
 	}
 }
 
+// pageFooter writes the footer of the current page, unless the page was
+// copied from another document by ClonePage().
+func (f *Fpdf) pageFooter(lastPage bool) {
+	f.endPageBody()
+	if f.pageBody[f.page].cloned {
+		return
+	}
+	f.growPage()
+	f.inFooter = true
+	// Page footer avoid double call on footer.
+	if f.footerFnc != nil {
+		f.footerFnc()
+	} else if f.footerFncLpi != nil {
+		f.footerFncLpi(lastPage)
+	}
+	f.inFooter = false
+	f.debugPage()
+}
+
 func (f *Fpdf) endpage() {
 	f.EndLayer()
 	f.state = 1
@@ -555,7 +708,15 @@ func (f *Fpdf) putpages() {
 		// Replace number of pages
 		f.RegisterAlias(f.aliasNbPagesStr, sprintf("%d", nb))
 	}
+	f.replaceSectionAliases()
 	f.replaceAliases()
+	if f.optimize {
+		f.optimizePages()
+	}
+	if f.imposition.n != 0 {
+		f.putimposedpages()
+		return
+	}
 	// f.defPageSize is already in points, no need to multiply by f.k
 	if f.defOrientation == Portrait {
 		wPt = f.defPageSize.Wd
@@ -564,6 +725,10 @@ func (f *Fpdf) putpages() {
 		wPt = f.defPageSize.Ht
 		hPt = f.defPageSize.Wd
 	}
+	// The pages inherit the resources and the most common page size from
+	// the Pages root, so that only the pages of another size repeat them.
+	defW, defH := wPt, hPt
+	wPt, hPt = f.commonPageSize(nb, wPt, hPt)
 	pagesObjectNumbers := make([]int, nb+1) // 1-based
 	for n := 1; n <= nb; n++ {
 		// Page
@@ -572,37 +737,20 @@ func (f *Fpdf) putpages() {
 		f.out("<</Type /Page")
 		f.out("/Parent 1 0 R")
 		pageSize, ok = f.pageSizes[n]
-		if ok {
+		if ok && (pageSize.Wd != wPt || pageSize.Ht != hPt) {
 			f.outf("/MediaBox [0 0 %.2f %.2f]", pageSize.Wd, pageSize.Ht)
+		} else if !ok && (defW != wPt || defH != hPt) {
+			f.outf("/MediaBox [0 0 %.2f %.2f]", defW, defH)
 		}
 		for t, pb := range f.pageBoxes[n] {
 			f.outf("/%s [%.2f %.2f %.2f %.2f]", t, pb.X, pb.Y, pb.Wd, pb.Ht)
 		}
-		f.out("/Resources 2 0 R")
 		// Links
 		if len(f.pageLinks[n])+len(f.pageAttachments[n]) > 0 {
 			var annots fmtBuffer
 			annots.printf("/Annots [")
-			for _, pl := range f.pageLinks[n] {
-				annots.printf("<</Type /Annot /Subtype /Link /Rect [%.2f %.2f %.2f %.2f] /Border [0 0 0] ",
-					pl.x, pl.y, pl.x+pl.wd, pl.y-pl.ht)
-				if pl.link == 0 {
-					annots.printf("/A <</S /URI /URI %s>>>>", f.textstring(pl.linkStr))
-				} else {
-					l := f.links[pl.link]
-					var sz PageSize
-					var h float64
-					sz, ok = f.pageSizes[l.page]
-					if ok {
-						h = sz.Ht
-					} else {
-						h = hPt
-					}
-					// dbg("h [%.2f], l.y [%.2f] f.k [%.2f]\n", h, l.y, f.k)
-					annots.printf("/Dest [%d 0 R /XYZ 0 %.2f null]>>", 1+2*l.page, h-l.y*f.k)
-				}
-			}
-			f.putAttachmentAnnotationLinks(&annots, n)
+			f.putLinkAnnots(&annots, n, 0, 0)
+			f.putAttachmentAnnotationLinks(&annots, n, 0, 0)
 			annots.printf("]")
 			f.out(annots.String())
 		}
@@ -613,16 +761,7 @@ func (f *Fpdf) putpages() {
 		f.out("endobj")
 		// Page content
 		f.newobj()
-		if f.compress {
-			mem := xmem.compress(f.pages[n].Bytes())
-			data := mem.bytes()
-			f.outf("<</Filter /FlateDecode /Length %d>>", len(data))
-			f.putstream(data)
-			mem.release()
-		} else {
-			f.outf("<</Length %d>>", f.pages[n].Len())
-			f.putstream(f.pages[n].Bytes())
-		}
+		f.putcontentstream("", f.pages[n].Bytes())
 		f.out("endobj")
 	}
 	// Pages root
@@ -638,10 +777,75 @@ func (f *Fpdf) putpages() {
 	f.out(kids.String())
 	f.outf("/Count %d", nb)
 	f.outf("/MediaBox [0 0 %.2f %.2f]", wPt, hPt)
+	f.out("/Resources 2 0 R")
 	f.out(">>")
 	f.out("endobj")
 }
 
+// commonPageSize returns the width and height in points of the size most of
+// the nb pages have, the default size wPt by hPt in case of a tie.
+func (f *Fpdf) commonPageSize(nb int, wPt, hPt float64) (float64, float64) {
+	type size struct{ wd, ht float64 }
+	def := size{wPt, hPt}
+	count := make(map[size]int)
+	for n := 1; n <= nb; n++ {
+		if sz, ok := f.pageSizes[n]; ok {
+			count[size{sz.Wd, sz.Ht}]++
+		} else {
+			count[def]++
+		}
+	}
+	best := def
+	for sz, c := range count {
+		// Ties between other sizes are broken by size for a stable output
+		if c > count[best] || c == count[best] && best != def && (sz.wd < best.wd || sz.wd == best.wd && sz.ht < best.ht) {
+			best = sz
+		}
+	}
+	return best.wd, best.ht
+}
+
+// putLinkAnnots writes the link annotations of page n, moved by dx and dy
+// points, to annots.
+func (f *Fpdf) putLinkAnnots(annots *fmtBuffer, n int, dx, dy float64) {
+	for _, pl := range f.pageLinks[n] {
+		if f.sanitize && pl.link == 0 {
+			continue
+		}
+		x, y := pl.x+dx, pl.y+dy
+		annots.printf("<</Type /Annot /Subtype /Link /Rect [%.2f %.2f %.2f %.2f] ",
+			x, y, x+pl.wd, y-pl.ht)
+		if pl.options == nil {
+			annots.printf("/Border [0 0 0] ")
+		} else {
+			f.putLinkOptions(annots, pl.options)
+		}
+		if pl.filePage > 0 {
+			annots.printf("/A <</S /GoToR /F %s /D [%d /Fit]>>>>", f.textstring(pl.linkStr), pl.filePage-1)
+		} else if pl.link == 0 {
+			annots.printf("/A <</S /URI /URI %s>>>>", f.textstring(pl.linkStr))
+		} else {
+			annots.printf("/Dest %s>>", f.linkDest(f.links[pl.link]))
+		}
+	}
+}
+
+// putcontentstream writes a stream object holding b, compressed if
+// compression is enabled. dict holds any entries of the stream dictionary
+// besides the filter and length.
+func (f *Fpdf) putcontentstream(dict string, b []byte) {
+	if f.compress {
+		mem := f.compressStream(b)
+		data := mem.bytes()
+		f.outf("<<%s/Filter /FlateDecode /Length %d>>", dict, len(data))
+		f.putstream(data)
+		mem.release()
+	} else {
+		f.outf("<<%s/Length %d>>", dict, len(b))
+		f.putstream(b)
+	}
+}
+
 func (f *Fpdf) putimages() {
 	var keyList []string
 	var key string
@@ -657,6 +861,7 @@ func (f *Fpdf) putimages() {
 	// Maintain a list of inserted image SHA-1 hashes, with their
 	// corresponding object ID number.
 	insertedImages := map[string]int{}
+	f.paletteObjs = make(map[string]int)
 
 	for _, key = range keyList {
 		image := f.images[key]
@@ -671,22 +876,43 @@ func (f *Fpdf) putimages() {
 			image.n = insertedImageObjN
 		} else {
 			f.putimage(image)
+			f.countImage(image)
 			insertedImages[image.i] = image.n
 		}
 	}
 }
 
 func (f *Fpdf) putimage(info *ImageInfoType) {
+	// The colors of a tinted image are converted by a function written
+	// before the image.
+	base := "/DeviceRGB"
+	cs := "/" + info.cs
+	if info.tint != nil {
+		if info.cs == "Indexed" {
+			base = f.puttintfunction("DeviceRGB", *info.tint)
+		} else {
+			cs = f.puttintfunction(info.cs, *info.tint)
+		}
+	}
 	f.newobj()
 	info.n = f.n
 	f.out("<</Type /XObject")
 	f.out("/Subtype /Image")
 	f.outf("/Width %d", int(info.w))
 	f.outf("/Height %d", int(info.h))
+	// Images with the same palette share it. A new palette follows the image
+	// and its soft mask.
+	palObj, palFound := f.paletteObjs[string(info.pal)]
 	if info.cs == "Indexed" {
-		f.outf("/ColorSpace [/Indexed /DeviceRGB %d %d 0 R]", len(info.pal)/3-1, f.n+1)
+		if !palFound {
+			palObj = f.n + 1
+			if len(info.smask) > 0 {
+				palObj++
+			}
+		}
+		f.outf("/ColorSpace [/Indexed %s %d %d 0 R]", base, len(info.pal)/3-1, palObj)
 	} else {
-		f.outf("/ColorSpace /%s", info.cs)
+		f.outf("/ColorSpace %s", cs)
 		if info.cs == "DeviceCMYK" {
 			f.out("/Decode [1 0 1 0 1 0 1 0]")
 		}
@@ -726,10 +952,11 @@ func (f *Fpdf) putimage(info *ImageInfoType) {
 		f.putimage(smask)
 	}
 	// 	Palette
-	if info.cs == "Indexed" {
+	if info.cs == "Indexed" && !palFound {
+		f.paletteObjs[string(info.pal)] = palObj
 		f.newobj()
 		if f.compress {
-			mem := xmem.compress(info.pal)
+			mem := f.compressStream(info.pal)
 			pal := mem.bytes()
 			f.outf("<</Filter /FlateDecode /Length %d>>", len(pal))
 			f.putstream(pal)
@@ -781,6 +1008,7 @@ func (f *Fpdf) putresourcedict() {
 	f.out(">>")
 	f.out("/XObject <<")
 	f.putxobjectdict()
+	f.puttemplatedict()
 	f.out(">>")
 	count := len(f.blendList)
 	if count > 1 {
@@ -798,6 +1026,13 @@ func (f *Fpdf) putresourcedict() {
 		}
 		f.out(">>")
 	}
+	if len(f.patternList) > 0 {
+		f.out("/Pattern <<")
+		for j, p := range f.patternList {
+			f.outf("/P%d %d 0 R", j+1, p.objNum)
+		}
+		f.out(">>")
+	}
 	// Layers
 	f.layerPutResourceDict()
 	f.spotColorPutResourceDict()
@@ -820,6 +1055,11 @@ func (f *Fpdf) putGradients() {
 	for j := 1; j < count; j++ {
 		var f1 int
 		gr := f.gradientList[j]
+		if len(gr.stops) > 0 {
+			f.putGradientStops(gr)
+			f.gradientList[j].objNum = f.n
+			continue
+		}
 		if gr.tp == 2 || gr.tp == 3 {
 			f.newobj()
 			f.outf("<</FunctionType 2 /Domain [0.0 1.0] /C0 [%s] /C1 [%s] /N 1>>", gr.clr1Str, gr.clr2Str)
@@ -847,12 +1087,14 @@ func (f *Fpdf) putresources() {
 	f.layerPutLayers()
 	f.putBlendModes()
 	f.putGradients()
+	f.putPatterns()
 	f.putSpotColors()
 	f.putfonts()
 	if f.err != nil {
 		return
 	}
 	f.putimages()
+	f.puttemplates()
 	// 	Resource dictionary
 	f.offsets[2] = f.buffer.Len()
 	f.out("2 0 obj")
@@ -865,12 +1107,50 @@ func (f *Fpdf) putresources() {
 		f.newobj()
 		f.protect.objNum = f.n
 		f.out("<<")
-		f.out("/Filter /Standard")
-		f.out("/V 1")
-		f.out("/R 2")
-		f.outf("/O (%s)", f.escape(string(f.protect.oValue)))
-		f.outf("/U (%s)", f.escape(string(f.protect.uValue)))
-		f.outf("/P %d", f.protect.pValue)
+		recipients := func() {
+			f.put("/Recipients [")
+			for _, r := range f.protect.recipients {
+				f.put("<" + hex.EncodeToString(r) + ">")
+			}
+			f.out("]")
+		}
+		switch {
+		case len(f.protect.recipients) > 0 && f.protect.plainMetadata:
+			f.out("/Filter /Adobe.PubSec")
+			f.out("/SubFilter /adbe.pkcs7.s5")
+			f.out("/V 4")
+			f.out("/Length 128")
+			f.out("/CF << /DefaultCryptFilter << /CFM /V2 /Length 16 /AuthEvent /DocOpen")
+			recipients()
+			f.out("/EncryptMetadata false >> >>")
+			f.out("/StmF /DefaultCryptFilter")
+			f.out("/StrF /DefaultCryptFilter")
+		case len(f.protect.recipients) > 0:
+			f.out("/Filter /Adobe.PubSec")
+			f.out("/SubFilter /adbe.pkcs7.s4")
+			f.out("/V 2")
+			f.out("/Length 128")
+			recipients()
+		case f.protect.plainMetadata:
+			f.out("/Filter /Standard")
+			f.out("/V 4")
+			f.out("/R 4")
+			f.out("/Length 128")
+			f.out("/CF << /StdCF << /CFM /V2 /Length 16 /AuthEvent /DocOpen >> >>")
+			f.out("/StmF /StdCF")
+			f.out("/StrF /StdCF")
+			f.outf("/O (%s)", f.escape(string(f.protect.oValue)))
+			f.outf("/U (%s)", f.escape(string(f.protect.uValue)))
+			f.outf("/P %d", f.protect.pValue)
+			f.out("/EncryptMetadata false")
+		default:
+			f.out("/Filter /Standard")
+			f.out("/V 1")
+			f.out("/R 2")
+			f.outf("/O (%s)", f.escape(string(f.protect.oValue)))
+			f.outf("/U (%s)", f.escape(string(f.protect.uValue)))
+			f.outf("/P %d", f.protect.pValue)
+		}
 		f.out(">>")
 		f.out("endobj")
 	}
@@ -904,7 +1184,7 @@ func (f *Fpdf) putcatalog() {
 	f.out("/Pages 1 0 R")
 	f.putOutputIntents()
 	if f.lang != "" {
-		f.outf("/Lang (%s)", f.lang)
+		f.outf("/Lang %s", f.textstring(f.lang))
 	}
 	switch f.zoomMode {
 	case "fullpage":
@@ -947,7 +1227,7 @@ func (f *Fpdf) putcatalog() {
 	//	-> Embedded files
 	f.out("/Names <<")
 	// JavaScript
-	if f.javascript != nil {
+	if f.javascript != nil && !f.sanitize {
 		f.outf("/JavaScript %d 0 R", f.nJs)
 	}
 	// Embedded files
@@ -957,6 +1237,8 @@ func (f *Fpdf) putcatalog() {
 
 func (f *Fpdf) putheader() {
 	f.outf("%%PDF-%s", f.pdfVersion)
+	// A comment of bytes above 127, as the specification recommends, so that
+	// transfer programs handle the file as binary rather than as text
 	f.out("%µ¶")
 }
 
@@ -966,6 +1248,12 @@ func (f *Fpdf) puttrailer() {
 	f.outf("/Info %d 0 R", f.n-1)
 	if f.protect.encrypted {
 		f.outf("/Encrypt %d 0 R", f.protect.objNum)
+	}
+	switch {
+	case len(f.fileID) > 0:
+		id := hex.EncodeToString(f.fileID)
+		f.outf("/ID [<%s><%s>]", id, id)
+	case f.protect.encrypted:
 		f.out("/ID [()()]")
 	}
 }
@@ -977,7 +1265,13 @@ func (f *Fpdf) putxmp() {
 	f.newobj()
 	f.nXMP = f.n
 	f.outf("<< /Type /Metadata /Subtype /XML /Length %d >>", len(f.xmp))
-	f.putstream(f.xmp)
+	if f.protect.plainMetadata {
+		f.out("stream")
+		f.out(string(f.xmp))
+		f.out("endstream")
+	} else {
+		f.putstream(f.xmp)
+	}
 	f.out("endobj")
 }
 
@@ -1022,7 +1316,9 @@ func (f *Fpdf) putbookmarks() {
 			if o.last != -1 {
 				f.outf("/Last %d 0 R", n+o.last)
 			}
-			f.outf("/Dest [%d 0 R /XYZ 0 %.2f null]", 1+2*o.p, (f.h-o.y)*f.k)
+			_, yPt := f.pagePoint(o.p, 0, o.y)
+			obj, _, y := f.destPage(o.p, 0, yPt)
+			f.outf("/Dest [%d 0 R /XYZ 0 %.2f null]", obj, y)
 			f.out("/Count 0>>")
 			f.out("endobj")
 		}
@@ -1043,11 +1339,11 @@ func (f *Fpdf) putOutputIntents() {
 	for index, oi := range f.outputIntents {
 		infoSegment := ""
 		if oi.Info != "" {
-			infoSegment = Sprintf("/Info (%s) ", oi.Info)
+			infoSegment = "/Info " + f.textstring(oi.Info) + " "
 		}
 		f.outf(
-			`<< /Type /OutputIntent /S /%s /OutputConditionIdentifier (%s) %s/DestOutputProfile %d 0 R >>`,
-			oi.SubtypeIdent, oi.OutputConditionIdentifier, infoSegment, f.outputIntentStartN+index,
+			`<< /Type /OutputIntent /S /%s /OutputConditionIdentifier %s %s/DestOutputProfile %d 0 R >>`,
+			escapeName(string(oi.SubtypeIdent)), f.textstring(oi.OutputConditionIdentifier), infoSegment, f.outputIntentStartN+index,
 		)
 	}
 	f.out("]")
@@ -1061,7 +1357,7 @@ func (f *Fpdf) putOutputIntentStreams() {
 	f.outputIntentStartN = f.n + 1
 	for _, oi := range f.outputIntents {
 		f.newobj()
-		mem := xmem.compress(oi.ICCProfile)
+		mem := f.compressStream(oi.ICCProfile)
 		compressedICC := mem.bytes()
 		f.outf("<< /N 3 /Alternate /DeviceRGB /Length %d /Filter /FlateDecode >>", len(compressedICC))
 		f.putstream(compressedICC)
@@ -1120,6 +1416,10 @@ func (f *Fpdf) enddoc() {
 	f.outf("%d", o)
 	f.out("%%EOF")
 	f.state = 3
+	if f.stats != nil {
+		f.stats.Pages = f.PageCount()
+		f.stats.Bytes = f.buffer.Len()
+	}
 }
 
 // GetDisplayMode returns the current display mode. See SetDisplayMode() for details.
@@ -1157,7 +1457,7 @@ func (f *Fpdf) SetDisplayMode(zoomStr, layoutStr string) {
 	case "fullpage", "fullwidth", "real", "default":
 		f.zoomMode = zoomStr
 	default:
-		f.err = Errf("incorrect zoom display mode: %s", zoomStr)
+		f.errorf("SetDisplayMode", "incorrect zoom display mode: %s", zoomStr)
 		return
 	}
 	switch layoutStr {
@@ -1165,7 +1465,7 @@ func (f *Fpdf) SetDisplayMode(zoomStr, layoutStr string) {
 		"TwoColumnLeft", "TwoColumnRight", "TwoPageLeft", "TwoPageRight":
 		f.layoutMode = layoutStr
 	default:
-		f.err = Errf("incorrect layout display mode: %s", layoutStr)
+		f.errorf("SetDisplayMode", "incorrect layout display mode: %s", layoutStr)
 		return
 	}
 }
@@ -1,7 +1,6 @@
 package fpdf
 
 import (
-	"bytes"
 	"math"
 
 	. "github.com/tinywasm/fmt"
@@ -29,6 +28,9 @@ func (f *Fpdf) GetX() float64 {
 // SetX defines the abscissa of the current position. If the passed value is
 // negative, it is relative to the right of the page.
 func (f *Fpdf) SetX(x float64) {
+	if !f.strictFinite("SetX", "x", x) {
+		return
+	}
 	if x >= 0 {
 		f.x = x
 	} else {
@@ -46,7 +48,10 @@ func (f *Fpdf) GetY() float64 {
 // the page.
 func (f *Fpdf) SetY(y float64) {
 	// dbg("SetY x %.2f, lMargin %.2f", f.x, f.lMargin)
-	f.x = f.lMargin
+	if !f.strictFinite("SetY", "y", y) {
+		return
+	}
+	f.x = f.lineStart()
 	if y >= 0 {
 		f.y = y
 	} else {
@@ -58,6 +63,9 @@ func (f *Fpdf) SetY(y float64) {
 // passed values are negative, they are relative respectively to the right and
 // bottom of the page.
 func (f *Fpdf) SetXY(x, y float64) {
+	if !f.strictFinite("SetXY", "x y", x, y) {
+		return
+	}
 	f.SetY(y)
 	f.SetX(x)
 }
@@ -67,6 +75,9 @@ func (f *Fpdf) SetXY(x, y float64) {
 // The method can be called before the first page is created. The value is
 // retained from page to page.
 func (f *Fpdf) SetDrawColor(r, g, b int) {
+	if !f.strictColor("SetDrawColor", "r g b", 255, r, g, b) {
+		return
+	}
 	f.setDrawColor(r, g, b)
 }
 
@@ -89,6 +100,9 @@ func (f *Fpdf) GetDrawColor() (int, int, int) {
 // -255). The method can be called before the first page is created and the
 // value is retained from page to page.
 func (f *Fpdf) SetFillColor(r, g, b int) {
+	if !f.strictColor("SetFillColor", "r g b", 255, r, g, b) {
+		return
+	}
 	f.setFillColor(r, g, b)
 }
 
@@ -111,6 +125,9 @@ func (f *Fpdf) GetFillColor() (int, int, int) {
 // components (0 - 255). The method can be called before the first page is
 // created. The value is retained from page to page.
 func (f *Fpdf) SetTextColor(r, g, b int) {
+	if !f.strictColor("SetTextColor", "r g b", 255, r, g, b) {
+		return
+	}
 	f.setTextColor(r, g, b)
 }
 
@@ -142,16 +159,61 @@ func (f *Fpdf) GetStringSymbolWidth(s string) int {
 	if f.err != nil {
 		return 0
 	}
+	if f.textTransform == TextTransformNone && !f.needsFallback(s) {
+		return fontSymbolWidth(&f.currentFont, s)
+	}
+	s = f.transformText(s)
+	w := fontSymbolWidth(&f.currentFont, s)
+	for _, c := range s {
+		switch {
+		case f.smallCap(int(c)):
+			w += f.capWidth(int(c), 0) - runeWidth(&f.currentFont, c)
+		case f.runeFont(c).i != f.currentFont.i:
+			// printed with the fallback font
+			w += f.charWidth(c) - fontSymbolWidth(&f.currentFont, string(c))
+		}
+	}
+	return w
+}
+
+// StringWidthStyled returns the length of a string in user units when printed
+// with the given font family, style and size in points, without changing the
+// current font. This lets layout code measure text in several fonts before
+// drawing anything. An empty familyStr means the current family and a size
+// of 0 means the current size. Core fonts are loaded as needed; other fonts
+// must have been added first, see SetFont().
+func (f *Fpdf) StringWidthStyled(s, familyStr, styleStr string, size float64) float64 {
+	if f.err != nil {
+		return 0
+	}
+	familyStr = Convert(familyStr).ToLower().String()
+	if familyStr == "" {
+		familyStr = f.fontFamily
+	}
+	styleStr = Convert(styleStr).ToUpper().Replace("U", "").Replace("S", "").String()
+	if styleStr == "IB" {
+		styleStr = "BI"
+	}
+	family, style, ok := f.resolveFont("StringWidthStyled", familyStr, styleStr)
+	if !ok {
+		return 0
+	}
+	if size == 0 {
+		size = f.fontSizePt
+	}
+	font := f.fonts[family+style]
+	return float64(fontSymbolWidth(&font, s)) * size / f.k / 1000
+}
+
+// fontSymbolWidth returns the length of a string in glyf units for font.
+func fontSymbolWidth(font *fontDefType, s string) int {
 	w := 0
-	if f.isCurrentUTF8 {
+	if font.Tp == "UTF8" {
 		for _, char := range s {
-			intChar := int(char)
-			if len(f.currentFont.Cw) >= intChar && f.currentFont.Cw[intChar] > 0 {
-				if f.currentFont.Cw[intChar] != 65535 {
-					w += f.currentFont.Cw[intChar]
-				}
-			} else if f.currentFont.Desc.MissingWidth != 0 {
-				w += f.currentFont.Desc.MissingWidth
+			if cw, ok := font.glyphWidth(char); ok {
+				w += cw
+			} else if font.Desc.MissingWidth != 0 {
+				w += font.Desc.MissingWidth
 			} else {
 				w += 500
 			}
@@ -161,7 +223,7 @@ func (f *Fpdf) GetStringSymbolWidth(s string) int {
 			if ch == 0 {
 				break
 			}
-			w += f.currentFont.Cw[ch]
+			w += font.Cw[ch]
 		}
 	}
 	return w
@@ -171,6 +233,9 @@ func (f *Fpdf) GetStringSymbolWidth(s string) int {
 // The method can be called before the first page is created. The value is
 // retained from page to page.
 func (f *Fpdf) SetLineWidth(width float64) {
+	if !f.strictLength("SetLineWidth", "width", width) {
+		return
+	}
 	f.setLineWidth(width)
 }
 
@@ -262,6 +327,10 @@ func (f *Fpdf) SetLineJoinStyle(styleStr string) {
 // that PDF creates nice line joins at the angles, rather than just
 // overlaying the lines.
 func (f *Fpdf) MoveTo(x, y float64) {
+	if !f.strictFinite("MoveTo", "x y", x, y) {
+		return
+	}
+	f.extend(x, y, x, y)
 	f.point(x, y)
 	f.x, f.y = x, y
 }
@@ -272,8 +341,16 @@ func (f *Fpdf) MoveTo(x, y float64) {
 //
 // The MoveTo() example demonstrates this method.
 func (f *Fpdf) LineTo(x, y float64) {
+	if !f.strictFinite("LineTo", "x y", x, y) {
+		return
+	}
+	f.extend(x, y, x, y)
+	f.lineTo(x, y)
+}
+
+func (f *Fpdf) lineTo(x, y float64) {
 	// f.outf("%.2f %.2f l", x*f.k, (f.h-y)*f.k)
-	const prec = 2
+	prec := f.prec.Path
 	f.putF64(x*f.k, prec)
 	f.put(" ")
 
@@ -292,8 +369,12 @@ func (f *Fpdf) LineTo(x, y float64) {
 //
 // The MoveTo() example demonstrates this method.
 func (f *Fpdf) CurveTo(cx, cy, x, y float64) {
+	if !f.strictFinite("CurveTo", "cx cy x y", cx, cy, x, y) {
+		return
+	}
+	f.extendPoints(PointType{cx, cy}, PointType{x, y})
 	// f.outf("%.5f %.5f %.5f %.5f v", cx*f.k, (f.h-cy)*f.k, x*f.k, (f.h-y)*f.k)
-	const prec = 5
+	prec := f.prec.Curve
 	f.putF64(cx*f.k, prec)
 	f.put(" ")
 	f.putF64((f.h-cy)*f.k, prec)
@@ -315,6 +396,10 @@ func (f *Fpdf) CurveTo(cx, cy, x, y float64) {
 //
 // The MoveTo() example demonstrates this method.
 func (f *Fpdf) CurveBezierCubicTo(cx0, cy0, cx1, cy1, x, y float64) {
+	if !f.strictFinite("CurveBezierCubicTo", "cx0 cy0 cx1 cy1 x y", cx0, cy0, cx1, cy1, x, y) {
+		return
+	}
+	f.extendPoints(PointType{cx0, cy0}, PointType{cx1, cy1}, PointType{x, y})
 	f.curve(cx0, cy0, cx1, cy1, x, y)
 	f.x, f.y = x, y
 }
@@ -369,8 +454,101 @@ func (f *Fpdf) ArcTo(x, y, rx, ry, degRotate, degStart, degEnd float64) {
 	f.arc(x, y, rx, ry, degRotate, degStart, degEnd, "", true)
 }
 
+// EllipticalArcTo creates an elliptical arc from the current stylus location
+// to the point (x, y), which becomes the new stylus location, in the way of
+// the arc command of SVG paths. rx and ry specify the radii of the ellipse
+// and degRotate the angle, in degrees, by which its x axis is rotated. Of the
+// four arcs of the two ellipses that join the points, largeArc selects one of
+// the arcs greater than 180 degrees, and sweep one drawn in the direction of
+// increasing angles, that is clockwise on the page. If the radii are too
+// small for the ellipse to reach (x, y), they are scaled up; if either of them
+// is zero, a straight line is created.
+//
+// The MoveTo() example demonstrates this method.
+func (f *Fpdf) EllipticalArcTo(rx, ry, degRotate float64, largeArc, sweep bool, x, y float64) {
+	f.extend(x, y, x, y)
+	for _, c := range ellipticalArcCurves(f.x, f.y, rx, ry, degRotate, largeArc, sweep, x, y) {
+		f.extendPoints(PointType{c[0], c[1]}, PointType{c[2], c[3]})
+		f.curve(c[0], c[1], c[2], c[3], c[4], c[5])
+	}
+	if rx == 0 || ry == 0 {
+		f.lineTo(x, y)
+	}
+	f.x, f.y = x, y
+}
+
+// ellipticalArcCurves returns the cubic Bézier curves, as control points and
+// end point, that approximate the elliptical arc from (x0, y0) to (x, y)
+// described with EllipticalArcTo(). No curves are returned if a radius is zero
+// or the points are the same.
+func ellipticalArcCurves(x0, y0, rx, ry, degRotate float64, largeArc, sweep bool, x, y float64) (curves [][6]float64) {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || (x0 == x && y0 == y) {
+		return
+	}
+	// Conversion to the center of the ellipse and the angles of the ends of
+	// the arc, as described in the implementation notes of the SVG
+	// specification.
+	sinPhi, cosPhi := math.Sincos(degRotate * math.Pi / 180)
+	dx, dy := (x0-x)/2, (y0-y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx *= math.Sqrt(l)
+		ry *= math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	co := math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		co = -co
+	}
+	cx1, cy1 := co*rx*y1/ry, -co*ry*x1/rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (x0+x)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (y0+y)/2
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	t1 := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	dt := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && dt > 0 {
+		dt -= 2 * math.Pi
+	} else if sweep && dt < 0 {
+		dt += 2 * math.Pi
+	}
+
+	// One curve per quarter of ellipse at most
+	n := int(math.Ceil(math.Abs(dt) / (math.Pi / 2)))
+	delta := dt / float64(n)
+	alpha := 4.0 / 3 * math.Tan(delta/4)
+	point := func(t float64) (px, py, tx, ty float64) {
+		sin, cos := math.Sincos(t)
+		px = cx + rx*cosPhi*cos - ry*sinPhi*sin
+		py = cy + rx*sinPhi*cos + ry*cosPhi*sin
+		tx = -rx*cosPhi*sin - ry*sinPhi*cos
+		ty = -rx*sinPhi*sin + ry*cosPhi*cos
+		return
+	}
+	px, py, tx, ty := point(t1)
+	for j := 1; j <= n; j++ {
+		qx, qy, ux, uy := point(t1 + float64(j)*delta)
+		if j == n {
+			qx, qy = x, y
+		}
+		curves = append(curves, [6]float64{px + alpha*tx, py + alpha*ty, qx - alpha*ux, qy - alpha*uy, qx, qy})
+		px, py, tx, ty = qx, qy, ux, uy
+	}
+	return
+}
+
 func (f *Fpdf) arc(x, y, rx, ry, degRotate, degStart, degEnd float64,
 	styleStr string, path bool) {
+	if degRotate != 0 {
+		r := math.Max(rx, ry)
+		f.extend(x-r, y-r, x+r, y+r)
+	} else {
+		f.extend(x-rx, y-ry, x+rx, y+ry)
+	}
 	x *= f.k
 	y = (f.h - y) * f.k
 	rx *= f.k
@@ -390,7 +568,7 @@ func (f *Fpdf) arc(x, y, rx, ry, degRotate, degStart, degEnd float64,
 		//	f.outf("q %.5f %.5f %.5f %.5f %.5f %.5f cm",
 		//		math.Cos(a), -1*math.Sin(a),
 		//		math.Sin(a), math.Cos(a), x, y)
-		const prec = 5
+		prec := f.prec.Matrix
 		f.put("q ")
 		f.putF64(cos, prec)
 		f.put(" ")
@@ -418,7 +596,7 @@ func (f *Fpdf) arc(x, y, rx, ry, degRotate, degStart, degEnd float64,
 	if path {
 		if f.x != sx || f.y != sy {
 			// Draw connecting line to start point
-			f.LineTo(sx, sy)
+			f.lineTo(sx, sy)
 		}
 	} else {
 		f.point(sx, sy)
@@ -458,37 +636,51 @@ func (f *Fpdf) arc(x, y, rx, ry, degRotate, degStart, degEnd float64,
 // precisely on the page, but it is usually easier to use Cell(), MultiCell()
 // or Write() which are the standard methods to print text.
 func (f *Fpdf) Text(x, y float64, txtStr string) {
+	if !f.strictFinite("Text", "x y", x, y) {
+		return
+	}
 	var txt2 string
+	txtStr = f.transformText(txtStr)
 	if f.isCurrentUTF8 {
+		if f.isRTL || hasRTL(txtStr) {
+			txtStr = visualOrder(txtStr, f.isRTL)
+		}
 		if f.isRTL {
-			txtStr = reverseText(txtStr)
 			x -= f.GetStringWidth(txtStr)
 		}
-		txt2 = f.escape(utf8toutf16(txtStr, false))
-		for _, uni := range txtStr {
-			f.currentFont.usedRunes[int(uni)] = int(uni)
-		}
+		txt2 = f.escape(f.encodeText(txtStr))
+		f.useRunes("Text", txtStr)
 	} else {
 		txt2 = f.escape(txtStr)
 	}
-	s := sprintf("BT %.2f %.2f Td (%s) Tj ET", x*f.k, (f.h-y)*f.k, txt2)
+	f.extendText(x, y, txtStr)
+	obj := sprintf("%s %s %s", f.textBegin(x*f.k, (f.h-y)*f.k, ""), f.textShow(txtStr, txt2, " Tj"), f.textEnd())
+	var pre, post string
+	if f.textBg.str != "" {
+		pre = f.textBackground(x, y, f.decorationWidth(txtStr))
+	}
 	if f.underline && txtStr != "" {
-		s += " " + f.dounderline(x, y, txtStr)
+		post += " " + f.dounderline(x, y, f.decorationWidth(txtStr))
 	}
 	if f.strikeout && txtStr != "" {
-		s += " " + f.dostrikeout(x, y, txtStr)
+		post += " " + f.dostrikeout(x, y, f.decorationWidth(txtStr))
 	}
 	if f.colorFlag {
-		s = sprintf("q %s %s Q", f.color.text.str, s)
+		pre = sprintf("q %s %s", f.color.text.str, pre)
+		post += " Q"
 	}
-	f.out(s)
+	f.outText(pre, obj, post, x, y, txtStr)
 }
 
 // Line draws a line between points (x1, y1) and (x2, y2) using the current
 // draw color, line width and cap style.
 func (f *Fpdf) Line(x1, y1, x2, y2 float64) {
+	if !f.strictFinite("Line", "x1 y1 x2 y2", x1, y1, x2, y2) {
+		return
+	}
+	f.extend(x1, y1, x2, y2)
 	// f.outf("%.2f %.2f m %.2f %.2f l S", x1*f.k, (f.h-y1)*f.k, x2*f.k, (f.h-y2)*f.k)
-	const prec = 2
+	prec := f.prec.Path
 	f.putF64(x1*f.k, prec)
 	f.put(" ")
 	f.putF64((f.h-y1)*f.k, prec)
@@ -532,8 +724,12 @@ func fillDrawOp(styleStr string) (opStr string) {
 // draw color and line width centered on the rectangle's perimeter. Filling
 // uses the current fill color.
 func (f *Fpdf) Rect(x, y, w, h float64, styleStr string) {
+	if !f.strictFinite("Rect", "x y", x, y) || !f.strictLength("Rect", "w h", w, h) {
+		return
+	}
+	f.extend(x, y, x+w, y+h)
 	// f.outf("%.2f %.2f %.2f %.2f re %s", x*f.k, (f.h-y)*f.k, w*f.k, -h*f.k, fillDrawOp(styleStr))
-	const prec = 2
+	prec := f.prec.Path
 	f.putF64(x*f.k, prec)
 	f.put(" ")
 	f.putF64((f.h-y)*f.k, prec)
@@ -577,6 +773,7 @@ func (f *Fpdf) RoundedRect(x, y, w, h, r float64, corners string, stylestr strin
 // RoundedRect() for more details. This method is demonstrated in the
 // RoundedRect() example.
 func (f *Fpdf) RoundedRectExt(x, y, w, h, rTL, rTR, rBR, rBL float64, stylestr string) {
+	f.extend(x, y, x+w, y+h)
 	f.roundedRectPath(x, y, w, h, rTL, rTR, rBR, rBL)
 	f.out(fillDrawOp(stylestr))
 	f.out("Q")
@@ -589,6 +786,9 @@ func (f *Fpdf) RoundedRectExt(x, y, w, h, rTL, rTR, rBR, rBL float64, stylestr s
 // the current draw color and line width centered on the circle's perimeter.
 // Filling uses the current fill color.
 func (f *Fpdf) Circle(x, y, r float64, styleStr string) {
+	if !f.strictFinite("Circle", "x y", x, y) || !f.strictLength("Circle", "r", r) {
+		return
+	}
 	f.Ellipse(x, y, r, r, 0, styleStr)
 }
 
@@ -605,6 +805,9 @@ func (f *Fpdf) Circle(x, y, r float64, styleStr string) {
 //
 // The Circle() example demonstrates this method.
 func (f *Fpdf) Ellipse(x, y, rx, ry, degRotate float64, styleStr string) {
+	if !f.strictFinite("Ellipse", "x y degRotate", x, y, degRotate) || !f.strictLength("Ellipse", "rx ry", rx, ry) {
+		return
+	}
 	f.arc(x, y, rx, ry, degRotate, 0, 360, styleStr, false)
 }
 
@@ -619,7 +822,8 @@ func (f *Fpdf) Ellipse(x, y, rx, ry, degRotate float64, styleStr string) {
 // Filling uses the current fill color.
 func (f *Fpdf) Polygon(points []PointType, styleStr string) {
 	if len(points) > 2 {
-		const prec = 5
+		f.extendPoints(points...)
+		prec := f.prec.Curve
 		for j, pt := range points {
 			if j == 0 {
 				f.point(pt.X, pt.Y)
@@ -658,6 +862,7 @@ func (f *Fpdf) Beziergon(points []PointType, styleStr string) {
 	if len(points) < 4 {
 		return
 	}
+	f.extendPoints(points...)
 	f.point(points[0].XY())
 
 	points = points[1:]
@@ -672,12 +877,46 @@ func (f *Fpdf) Beziergon(points []PointType, styleStr string) {
 	f.DrawPath(styleStr)
 }
 
+// SmoothPolyline draws a smooth curve through the series of points, for
+// example the data points of a line chart. Each pair of consecutive points
+// is joined with a cubic Bézier curve whose control points make the curve
+// tangent, at each point, to the line between its neighbors, as in a
+// Catmull-Rom spline. tension ranges from 0, which joins the points with
+// straight lines, to 1, which gives the Catmull-Rom curve; values between
+// them flatten the curve.
+//
+// The x and y fields of the points use the units established in New(). The
+// curve is not closed: filling it fills the area between the curve and the
+// line from its last point to its first one.
+//
+// styleStr can be "F" for filled, "D" for outlined only, or "DF" or "FD" for
+// outlined and filled. An empty string will be replaced with "D". Drawing uses
+// the current draw color and line width centered on the curve. Filling uses
+// the current fill color.
+func (f *Fpdf) SmoothPolyline(points []PointType, tension float64, styleStr string) {
+	if len(points) < 2 {
+		return
+	}
+	f.extendPoints(points...)
+	f.point(points[0].XY())
+	t := tension / 6
+	for j := 1; j < len(points); j++ {
+		// The first and last points are their own outer neighbors.
+		p0 := points[max(j-2, 0)]
+		p1, p2 := points[j-1], points[j]
+		p3 := points[min(j+1, len(points)-1)]
+		f.curve(p1.X+(p2.X-p0.X)*t, p1.Y+(p2.Y-p0.Y)*t,
+			p2.X-(p3.X-p1.X)*t, p2.Y-(p3.Y-p1.Y)*t, p2.X, p2.Y)
+	}
+	f.DrawPath(styleStr)
+}
+
 // point outputs current point
 func (f *Fpdf) point(x, y float64) {
 	// f.outf("%.2f %.2f m", x*f.k, (f.h-y)*f.k)
-	f.putF64(x*f.k, 2)
+	f.putF64(x*f.k, f.prec.Path)
 	f.put(" ")
-	f.putF64((f.h-y)*f.k, 2)
+	f.putF64((f.h-y)*f.k, f.prec.Path)
 	f.put(" m\n")
 }
 
@@ -686,7 +925,7 @@ func (f *Fpdf) curve(cx0, cy0, cx1, cy1, x, y float64) {
 	// Thanks, Robert Lillack, for straightening this out
 	// f.outf("%.5f %.5f %.5f %.5f %.5f %.5f c", cx0*f.k, (f.h-cy0)*f.k, cx1*f.k,
 	// 	(f.h-cy1)*f.k, x*f.k, (f.h-y)*f.k)
-	const prec = 5
+	prec := f.prec.Curve
 	f.putF64(cx0*f.k, prec)
 	f.put(" ")
 	f.putF64((f.h-cy0)*f.k, prec)
@@ -715,10 +954,11 @@ func (f *Fpdf) curve(cx0, cy0, cx1, cy1, x, y float64) {
 //
 // The Circle() example demonstrates this method.
 func (f *Fpdf) Curve(x0, y0, cx, cy, x1, y1 float64, styleStr string) {
+	f.extendPoints(PointType{x0, y0}, PointType{cx, cy}, PointType{x1, y1})
 	f.point(x0, y0)
 	// f.outf("%.5f %.5f %.5f %.5f v %s", cx*f.k, (f.h-cy)*f.k, x1*f.k, (f.h-y1)*f.k,
 	// 	fillDrawOp(styleStr))
-	const prec = 5
+	prec := f.prec.Curve
 	f.putF64(cx*f.k, prec)
 	f.put(" ")
 	f.putF64((f.h-cy)*f.k, prec)
@@ -733,6 +973,7 @@ func (f *Fpdf) Curve(x0, y0, cx, cy, x1, y1 float64, styleStr string) {
 // the same function as CurveBezierCubic() but has a nonstandard argument order.
 // It is retained to preserve backward compatibility.
 func (f *Fpdf) CurveCubic(x0, y0, cx0, cy0, x1, y1, cx1, cy1 float64, styleStr string) {
+	f.extendPoints(PointType{x0, y0}, PointType{cx0, cy0}, PointType{cx1, cy1}, PointType{x1, y1})
 	// f.point(x0, y0)
 	// f.outf("%.5f %.5f %.5f %.5f %.5f %.5f c %s", cx0*f.k, (f.h-cy0)*f.k,
 	// cx1*f.k, (f.h-cy1)*f.k, x1*f.k, (f.h-y1)*f.k, fillDrawOp(styleStr))
@@ -759,7 +1000,7 @@ func (f *Fpdf) CurveBezierCubic(x0, y0, cx0, cy0, cx1, cy1, x1, y1 float64, styl
 	f.point(x0, y0)
 	//	f.outf("%.5f %.5f %.5f %.5f %.5f %.5f c %s", cx0*f.k, (f.h-cy0)*f.k,
 	//		cx1*f.k, (f.h-cy1)*f.k, x1*f.k, (f.h-y1)*f.k, fillDrawOp(styleStr))
-	const prec = 5
+	prec := f.prec.Curve
 	f.putF64(cx0*f.k, prec)
 	f.put(" ")
 	f.putF64((f.h-cy0)*f.k, prec)
@@ -825,21 +1066,22 @@ func (f *Fpdf) SetAlpha(alpha float64, blendModeStr string) {
 	case "":
 		bl.modeStr = "Normal"
 	default:
-		f.err = Errf("unrecognized blend mode \"%s\"", blendModeStr)
+		f.errorf("SetAlpha", "unrecognized blend mode \"%s\"", blendModeStr)
 		return
 	}
 	if alpha < 0.0 || alpha > 1.0 {
-		f.err = Errf("alpha value (0.0 - 1.0) is out of range: %.3f", alpha)
+		f.errorf("SetAlpha", "alpha value (0.0 - 1.0) is out of range: %.3f", alpha)
 		return
 	}
 	f.alpha = alpha
 	f.blendMode = blendModeStr
 	alphaStr := sprintf("%.3f", alpha)
-	keyStr := sprintf("%s %s", alphaStr, blendModeStr)
+	// The key uses the canonical mode so that "" and "Normal" share a state
+	keyStr := sprintf("%s %s", alphaStr, bl.modeStr)
 	pos, ok := f.blendMap[keyStr]
 	if !ok {
 		pos = len(f.blendList) // at least 1
-		f.blendList = append(f.blendList, blendModeType{alphaStr, alphaStr, blendModeStr, 0})
+		f.blendList = append(f.blendList, blendModeType{alphaStr, alphaStr, bl.modeStr, 0})
 		f.blendMap[keyStr] = pos
 	}
 	if len(f.blendMap) > 0 && f.pdfVersion < pdfVers1_4 {
@@ -849,8 +1091,9 @@ func (f *Fpdf) SetAlpha(alpha float64, blendModeStr string) {
 }
 
 func (f *Fpdf) gradientClipStart(x, y, w, h float64) {
+	f.extend(x, y, x+w, y+h)
 	{
-		const prec = 2
+		prec := f.prec.Path
 		// Save current graphic state and set clipping area
 		// f.outf("q %.2f %.2f %.2f %.2f re W n", x*f.k, (f.h-y)*f.k, w*f.k, -h*f.k)
 		f.put("q ")
@@ -864,7 +1107,7 @@ func (f *Fpdf) gradientClipStart(x, y, w, h float64) {
 		f.put(" re W n\n")
 	}
 	{
-		const prec = 5
+		prec := f.prec.Matrix
 		// Set up transformation matrix for gradient
 		// f.outf("%.5f 0 0 %.5f %.5f %.5f cm", w*f.k, h*f.k, x*f.k, (f.h-(y+h))*f.k)
 		f.putF64(w*f.k, prec)
@@ -884,11 +1127,10 @@ func (f *Fpdf) gradientClipEnd() {
 }
 
 func (f *Fpdf) gradient(tp, r1, g1, b1, r2, g2, b2 int, x1, y1, x2, y2, r float64) {
-	pos := len(f.gradientList)
 	clr1 := f.rgbColorValue(r1, g1, b1, "", "")
 	clr2 := f.rgbColorValue(r2, g2, b2, "", "")
-	f.gradientList = append(f.gradientList, gradientType{tp, clr1.str, clr2.str,
-		x1, y1, x2, y2, r, 0})
+	pos := f.addGradient(gradientType{tp: tp, clr1Str: clr1.str, clr2Str: clr2.str,
+		x1: x1, y1: y1, x2: x2, y2: y2, r: r})
 	f.outf("/Sh%d sh", pos)
 }
 
@@ -950,7 +1192,7 @@ func (f *Fpdf) RadialGradient(x, y, w, h float64, r1, g1, b1, r2, g2, b2 int, x1
 func (f *Fpdf) ClipRect(x, y, w, h float64, outline bool) {
 	f.clipNest++
 	// f.outf("q %.2f %.2f %.2f %.2f re W %s", x*f.k, (f.h-y)*f.k, w*f.k, -h*f.k, strIf(outline, "S", "n"))
-	const prec = 2
+	prec := f.prec.Path
 	f.put("q ")
 	f.putF64(x*f.k, prec)
 	f.put(" ")
@@ -973,7 +1215,7 @@ func (f *Fpdf) ClipRect(x, y, w, h float64, outline bool) {
 func (f *Fpdf) ClipText(x, y float64, txtStr string, outline bool) {
 	f.clipNest++
 	// f.outf("q BT %.5f %.5f Td %d Tr (%s) Tj ET", x*f.k, (f.h-y)*f.k, intIf(outline, 5, 7), f.escape(txtStr))
-	const prec = 5
+	prec := f.prec.Text
 	f.put("q BT ")
 	f.putF64(x*f.k, prec)
 	f.put(" ")
@@ -989,7 +1231,7 @@ func (f *Fpdf) clipArc(x1, y1, x2, y2, x3, y3 float64) {
 	h := f.h
 	// f.outf("%.5f %.5f %.5f %.5f %.5f %.5f c ", x1*f.k, (h-y1)*f.k,
 	// 	x2*f.k, (h-y2)*f.k, x3*f.k, (h-y3)*f.k)
-	const prec = 5
+	prec := f.prec.Curve
 	f.putF64(x1*f.k, prec)
 	f.put(" ")
 	f.putF64((h-y1)*f.k, prec)
@@ -1036,7 +1278,7 @@ func (f *Fpdf) roundedRectPath(x, y, w, h, rTL, rTR, rBR, rBL float64) {
 	hp := f.h
 	myArc := (4.0 / 3.0) * (math.Sqrt2 - 1.0)
 	// f.outf("q %.5f %.5f m", (x+rTL)*k, (hp-y)*k)
-	const prec = 5
+	prec := f.prec.Curve
 	f.put("q ")
 	f.putF64((x+rTL)*k, prec)
 	f.put(" ")
@@ -1104,7 +1346,7 @@ func (f *Fpdf) ClipEllipse(x, y, rx, ry float64, outline bool) {
 	//		(x+rx)*k, (h-(y-ly))*k,
 	//		(x+lx)*k, (h-(y-ry))*k,
 	//		x*k, (h-(y-ry))*k)
-	const prec = 5
+	prec := f.prec.Curve
 	f.put("q ")
 	f.putF64((x+rx)*k, prec)
 	f.put(" ")
@@ -1206,7 +1448,8 @@ func (f *Fpdf) ClipPolygon(points []PointType, outline bool) {
 	k := f.k
 	s.printf("q ")
 	for j, pt := range points {
-		s.printf("%.5f %.5f %s ", pt.X*k, (h-pt.Y)*k, strIf(j == 0, "m", "l"))
+		s.Write(appendFloats(nil, f.prec.Curve, pt.X*k, (h-pt.Y)*k))
+		s.printf("%s ", strIf(j == 0, "m", "l"))
 	}
 	s.printf("h W %s", strIf(outline, "S", "n"))
 	f.out(s.String())
@@ -1224,7 +1467,7 @@ func (f *Fpdf) ClipEnd() {
 			f.clipNest--
 			f.out("Q")
 		} else {
-			f.err = Errf("error attempting to end clip operation out of sequence")
+			f.errorf("ClipEnd", "error attempting to end clip operation out of sequence")
 		}
 	}
 }
@@ -1253,16 +1496,13 @@ func (f *Fpdf) SetDashPattern(dashArray []float64, dashPhase float64) {
 }
 
 func (f *Fpdf) outputDashPattern() {
-	var buf bytes.Buffer
-	buf.WriteByte('[')
+	b := []byte{'['}
 	for i, value := range f.dashArray {
 		if i > 0 {
-			buf.WriteByte(' ')
+			b = append(b, ' ')
 		}
-		buf.WriteString(Convert(value).Round(2).String())
+		b = appendFloat(b, value, 2)
 	}
-	buf.WriteString("] ")
-	buf.WriteString(Convert(f.dashPhase).Round(2).String())
-	buf.WriteString(" d")
-	f.outbuf(&buf)
+	b = append(appendFloat(append(b, "] "...), f.dashPhase, 2), " d"...)
+	f.out(string(b))
 }
@@ -0,0 +1,271 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSmoothPolyline(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	points := []fpdf.PointType{{X: 0, Y: 100}, {X: 100, Y: 0}, {X: 200, Y: 100}}
+	pdf.SmoothPolyline(points, 1, "D")
+	pdf.SmoothPolyline(points, 0, "D")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		// Tangent at the middle point is parallel to the line between the
+		// first and last points.
+		"16.66667 758.55667 66.66667 841.89000 100.00000 841.89000 c",
+		"133.33333 841.89000 183.33333 758.55667 200.00000 741.89000 c",
+		// Without tension the control points are the end points.
+		"0.00000 741.89000 100.00000 841.89000 100.00000 841.89000 c",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
+
+func TestSetFillHatch(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	black := fpdf.RGBType{}
+	pdf.SetFillHatch(fpdf.HatchDiagonal, 8, 1, black)
+	pdf.Rect(50, 50, 100, 100, "F")
+	pdf.SetFillHatch(fpdf.HatchDots, 6, 2, fpdf.RGBType{R: 255})
+	pdf.Polygon([]fpdf.PointType{{X: 200, Y: 50}, {X: 300, Y: 150}, {X: 200, Y: 150}}, "F")
+	pdf.SetFillHatch(fpdf.HatchDiagonal, 8, 1, black)
+	pdf.Rect(50, 200, 100, 100, "F")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"/Pattern cs /P1 scn",
+		"/Pattern cs /P2 scn",
+		"/PatternType 1 /PaintType 1 /TilingType 1 /BBox [0 0 8.000 8.000] /XStep 8.000 /YStep 8.000",
+		"0.000 G 1.000 w\n0.000 0.000 m 8.000 8.000 l\n",
+		"1.000 0.000 0.000 RG 2.000 w\n1 J\n3.000 3.000 m 3.000 3.000 l\nS",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+	if got := strings.Count(out, "/PatternType 1"); got != 2 {
+		t.Errorf("invalid number of patterns: got=%d, want=2", got)
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetFillHatch(fpdf.HatchCross, 0, 1, black)
+	if !pdf.Err() {
+		t.Errorf("expected error for a zero hatch spacing")
+	}
+}
+
+func TestDimensionLine(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetXY(30, 40)
+	pdf.DimensionLine(100, 200, 200, 200, 20, "")
+	pdf.DimensionLine(300, 300, 300, 200, -20, "1 m")
+	if x, y := pdf.GetXY(); x != 30 || y != 40 {
+		t.Errorf("position changed: got=%.2f, %.2f", x, y)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		// Extension line from 2pt below the first point to 2pt above the
+		// dimension line, 20pt above the points.
+		"100.00 643.89 m 100.00 663.89 l S",
+		"100.00 661.89 m 200.00 661.89 l S",
+		// Arrowhead at the start of the dimension line.
+		"106.00 659.89 m\n100.00 661.89 l\n106.00 663.89 l\nS",
+		"(100.00) Tj",
+		// The upward dimension with a negative offset is 20pt to the right.
+		"(1 m) Tj",
+		"320.00 541.89 m 320.00 641.89 l S",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
+
+func TestScaledCanvas(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddPage()
+	c := fpdf.NewScaledCanvas(pdf, 10, 20, 100, 50, 0, 0, 10, 5, false)
+	for _, tc := range []struct{ got, want float64 }{
+		{c.X(0), 10}, {c.X(10), 110}, {c.Y(0), 70}, {c.Y(5), 20}, {c.Wd(1), 10}, {c.Ht(1), 10},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("invalid mapping: got=%.2f, want=%.2f", tc.got, tc.want)
+		}
+	}
+	// Equal scales center the world square in the region.
+	c = fpdf.NewScaledCanvas(pdf, 10, 20, 100, 50, 0, 0, 10, 10, true)
+	if x, y := c.XY(0, 0); x != 35 || y != 70 {
+		t.Errorf("invalid uniform mapping: got=%.2f, %.2f, want=35.00, 70.00", x, y)
+	}
+	if x, y := c.XY(10, 10); x != 85 || y != 20 {
+		t.Errorf("invalid uniform mapping: got=%.2f, %.2f, want=85.00, 20.00", x, y)
+	}
+	c.ClipBegin()
+	c.Rect(0, 0, 10, 10, "D")
+	c.Circle(5, 5, 5, "D")
+	c.Polygon([]fpdf.PointType{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 5, Y: 10}}, "D")
+	c.ClipEnd()
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+
+	fpdf.NewScaledCanvas(pdf, 10, 20, 100, 50, 0, 0, 0, 10, false)
+	if !pdf.Err() {
+		t.Errorf("expected error for an empty world rectangle")
+	}
+}
+
+func TestShapes(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.RegularPolygon(100, 100, 50, 6, 0, "D")
+	pdf.Star(200, 100, 50, 20, 5, "F")
+	pdf.SetXY(30, 40)
+	pdf.Sector(300, 100, 50, 0, 90, "FD")
+	if x, y := pdf.GetXY(); x != 30 || y != 40 {
+		t.Errorf("position changed: got=%.2f, %.2f", x, y)
+	}
+	pdf.RoundedPolygon([]fpdf.PointType{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}, {X: 0, Y: 100}}, 10, "D")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		// The first vertex of the hexagon and of the star are at the top.
+		"100.00 791.89 m",
+		"200.00 791.89 m",
+		// The sector starts at its center.
+		"300.00 741.89 m\n350.00 741.89 l",
+		// The upper right corner of the square is a quarter circle.
+		"90.00000 841.89000 l\n95.52285 841.89000 100.00000 837.41285 100.00000 831.89000 c",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	pdf.RegularPolygon(100, 100, 50, 2, 0, "D")
+	if !pdf.Err() {
+		t.Errorf("expected error for a polygon with 2 sides")
+	}
+}
+
+func TestEllipticalArcTo(t *testing.T) {
+	sig, err := fpdf.SVGBasicParse([]byte(`<svg width="100pt" height="100pt"><path d="M10 50 a40 40 0 0 1 80 0"/></svg>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg := sig.Segments[0][1]
+	if seg.Cmd != 'A' || seg.Arg != [6]float64{40, 40, 0, 90, 50} || seg.LargeArc || !seg.Sweep {
+		t.Errorf("invalid arc segment: got=%c %v large=%v sweep=%v", seg.Cmd, seg.Arg, seg.LargeArc, seg.Sweep)
+	}
+
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetXY(0, 0)
+	pdf.SVGBasicDraw(&sig, 0, "D")
+	// Large arc counter-clockwise from the bottom of the circle of radius 50
+	// centered at (250, 50) to its left, through its right and top.
+	pdf.MoveTo(250, 100)
+	pdf.EllipticalArcTo(50, 50, 0, true, false, 200, 50)
+	pdf.DrawPath("D")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		// The semicircle reaches its top halfway.
+		"50.00000 831.89000 c",
+		"90.00000 791.89000 c",
+		// Three quarters of a circle, in three curves.
+		"300.00000 791.89000 c",
+		"250.00000 841.89000 c",
+		"200.00000 791.89000 c",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	p := fpdf.NewPath().MoveTo(0, 0).LineTo(100, 0).LineTo(100, 50).Close()
+	if got := p.Length(); math.Abs(got-(150+math.Hypot(100, 50))) > 1e-9 {
+		t.Errorf("invalid length: got=%.3f", got)
+	}
+	// A semicircle bulging below the line from (0, 0) to (100, 0).
+	arc := fpdf.NewPath().MoveTo(0, 0).EllipticalArcTo(50, 50, 0, false, false, 100, 0)
+	if got := arc.Length(); math.Abs(got-50*math.Pi) > 0.1 {
+		t.Errorf("invalid arc length: got=%.3f, want=%.3f", got, 50*math.Pi)
+	}
+	if x, y, w, h := arc.BoundingBox(); math.Abs(x) > 1e-9 || math.Abs(y) > 1e-9 ||
+		math.Abs(w-100) > 1e-9 || math.Abs(h-50) > 0.1 {
+		t.Errorf("invalid bounding box: got=%.3f %.3f %.3f %.3f", x, y, w, h)
+	}
+
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetXY(30, 40)
+	p.Stroke(pdf)
+	p.Draw(pdf, "DF")
+	fpdf.NewPath().MoveTo(0, 0).CurveTo(50, 100, 100, 0).Fill(pdf)
+	p.Clip(pdf, false)
+	pdf.ClipEnd()
+	if x, y := pdf.GetXY(); x != 30 || y != 40 {
+		t.Errorf("position changed: got=%.2f, %.2f", x, y)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"0.00000 841.89000 m\n100.00000 841.89000 l\n100.00000 791.89000 l\nh\nS\n",
+		"h\nB\n",
+		"0.00000 841.89000 m\n33.33333 775.22333 66.66667 775.22333 100.00000 841.89000 c\nf\n",
+		"q 0.00000 841.89000 m\n",
+		"h\nW n\nQ\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
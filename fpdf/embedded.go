@@ -12,14 +12,14 @@ import (
 //go:embed font_embed/*.json font_embed/*.map
 var embFS embed.FS
 
-func (f *Fpdf) coreFontReader(familyStr, styleStr string) (r io.ReadCloser) {
+func (f *Fpdf) coreFontReader(method, familyStr, styleStr string) (r io.ReadCloser) {
 	key := familyStr + styleStr
 	key = Convert(key).ToLower().String()
 	emb, err := embFS.Open("font_embed/" + key + ".json")
 	if err == nil {
 		r = emb
-	} else if f.err == nil {
-		f.err = Err("core font definition", key, "missing")
+	} else {
+		f.errorf(method, "core font definition %s missing", key)
 	}
 	return
 }
@@ -0,0 +1,104 @@
+package fpdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// PdfError is the error recorded by Fpdf methods. Besides the underlying
+// error it identifies the method that failed and the page and position at
+// the time of the failure, for example:
+//
+//	CellFormat: page 12 at (35.2, 210.1): font has not been set
+//
+// Page is 0 if no page had been added yet.
+type PdfError struct {
+	Method string  // name of the Fpdf method that failed
+	Page   int     // current page number
+	X, Y   float64 // current position, in the unit of measure specified in New()
+	Err    error   // underlying error
+}
+
+// Error satisfies the error interface.
+func (e *PdfError) Error() string {
+	if e.Page == 0 {
+		return e.Method + ": " + e.Err.Error()
+	}
+	return Sprintf("%s: page %d at (%.1f, %.1f): %s", e.Method, e.Page, e.X, e.Y, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *PdfError) Unwrap() error {
+	return e.Err
+}
+
+// newError wraps err with the method name and the current page and position.
+func (f *Fpdf) newError(method string, err error) *PdfError {
+	return &PdfError{Method: method, Page: f.page, X: f.x, Y: f.y, Err: err}
+}
+
+// errorf sets the internal error, with context, unless one is already set.
+// The message is built with Sprintf so that every argument is formatted.
+func (f *Fpdf) errorf(method, fmtStr string, args ...any) {
+	if f.err == nil {
+		f.err = f.newError(method, Err(Sprintf(fmtStr, args...)))
+	}
+}
+
+// setError sets the internal error to err, with context, unless one is
+// already set or err is nil. An error that already has context is set as is.
+func (f *Fpdf) setError(method string, err error) {
+	if f.err != nil || err == nil {
+		return
+	}
+	if _, ok := err.(*PdfError); !ok {
+		err = f.newError(method, err)
+	}
+	f.err = err
+}
+
+// warnf records a non-fatal problem that does not halt PDF generation.
+func (f *Fpdf) warnf(method, fmtStr string, args ...any) {
+	f.warnings = append(f.warnings, f.newError(method, Err(Sprintf(fmtStr, args...))))
+}
+
+// GetErrors returns the non-fatal warnings accumulated while generating the
+// document, such as characters missing from a font or images that extend
+// beyond the page, in the order they occurred. If generation has been halted
+// by an error, that error is returned last. See also Error().
+func (f *Fpdf) GetErrors() []error {
+	errs := make([]error, 0, len(f.warnings)+1)
+	errs = append(errs, f.warnings...)
+	if f.err != nil {
+		errs = append(errs, f.err)
+	}
+	return errs
+}
+
+// useRunes marks the characters of s as used by the current UTF-8 font, or
+// by the fallback font for those it prints, so that they are included in the
+// subset of the font, and warns once per font about characters that have no
+// glyph.
+func (f *Fpdf) useRunes(method, s string) {
+	font := f.currentFont
+	for _, r := range s {
+		c := int(r)
+		used := f.runeFont(r)
+		if cid := used.cid(r); cid >= 0 {
+			used.usedRunes[cid] = cid
+		}
+		if c < 32 || fontHasGlyph(used, r) {
+			continue
+		}
+		key := font.Name + string(r)
+		if _, seen := f.missingGlyphs[key]; seen {
+			continue
+		}
+		if f.missingGlyphs == nil {
+			f.missingGlyphs = make(map[string]struct{})
+		}
+		f.missingGlyphs[key] = struct{}{}
+		// %04X would cut the code of a character beyond the BMP
+		code := strIf(c > 0xffff, Sprintf("%X", c), Sprintf("%04X", c))
+		f.warnf(method, "font %s has no glyph for %q (U+%s)", font.Name, string(r), code)
+	}
+}
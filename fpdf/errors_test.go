@@ -0,0 +1,77 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestPdfErrorContext(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetXY(35.2, 210.1)
+	pdf.CellFormat(40, 10, "text", "", 0, "L", false, 0, "")
+
+	var perr *fpdf.PdfError
+	if !errors.As(pdf.Error(), &perr) {
+		t.Fatalf("expecting *PdfError, got %v", pdf.Error())
+	}
+	if perr.Method != "CellFormat" || perr.Page != 1 || !floatEqual(perr.X, 35.2) || !floatEqual(perr.Y, 210.1) {
+		t.Errorf("unexpected context: %+v", perr)
+	}
+	want := "CellFormat: page 1 at (35.2, 210.1): font has not been set; unable to render text"
+	if got := perr.Error(); got != want {
+		t.Errorf("unexpected message:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestPdfErrorSetters(t *testing.T) {
+	for _, c := range []struct {
+		method string
+		fn     func(pdf *fpdf.Fpdf)
+	}{
+		{"RegisterImageOptionsReader", func(pdf *fpdf.Fpdf) {
+			pdf.RegisterImageOptionsReader("bad", fpdf.ImageOptions{ImageType: "png"}, bytes.NewReader([]byte("not a png")))
+		}},
+		{"SetDrawSpotColor", func(pdf *fpdf.Fpdf) { pdf.SetDrawSpotColor("missing", 100) }},
+		{"GetPageSizeStr", func(pdf *fpdf.Fpdf) { pdf.GetPageSizeStr("B12") }},
+		{"AddFont", func(pdf *fpdf.Fpdf) { pdf.AddFont("missing", "", "missing.json") }},
+		{"SetErrorf", func(pdf *fpdf.Fpdf) { pdf.SetErrorf("halted by %s", "application") }},
+		{"SetError", func(pdf *fpdf.Fpdf) { pdf.SetError(io.ErrUnexpectedEOF) }},
+	} {
+		pdf := NewDocPdfTest()
+		pdf.AddPage()
+		c.fn(pdf)
+		var perr *fpdf.PdfError
+		if !errors.As(pdf.Error(), &perr) || perr.Method != c.method {
+			t.Errorf("%s: expecting *PdfError with context, got %v", c.method, pdf.Error())
+		}
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddUTF8Font("dejavu", "", FontFile("DejaVuSansCondensed.ttf"))
+	pdf.SetFont("dejavu", "", 12)
+	pdf.AddPage()
+	pdf.Cell(40, 10, "中 中")
+	pdf.ImageOptions(ImageFile("logo.png"), 180, 10, 60, 0, false, fpdf.ImageOptions{}, 0, "")
+	if err := pdf.Output(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := pdf.GetErrors()
+	if len(errs) != 2 {
+		t.Fatalf("expecting one missing glyph and one clipped image warning, got %v", errs)
+	}
+	var perr *fpdf.PdfError
+	if !errors.As(errs[0], &perr) || perr.Method != "CellFormat" {
+		t.Errorf("unexpected missing glyph warning: %v", errs[0])
+	}
+	if !errors.As(errs[1], &perr) || perr.Method != "Image" {
+		t.Errorf("unexpected clipped image warning: %v", errs[1])
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"testing"
 	"time"
 
 	tf "github.com/tinywasm/fmt"
 
 	fpdf "github.com/tinywasm/pdf/fpdf"
+	"github.com/tinywasm/pdf/pdftest"
 )
 
 var rootTestDir fpdf.RootDirectoryType
@@ -110,23 +112,6 @@ func Filename(baseStr string) string {
 	return PdfFile(baseStr + ".pdf")
 }
 
-// referenceCompare compares the specified file with the file's reference copy
-// located in the 'reference' subdirectory. All bytes of the two files are
-// compared except for the value of the /CreationDate field in the PDF. This
-// function succeeds if both files are equivalent except for their
-// /CreationDate values or if the reference file does not exist.
-func referenceCompare(fileStr string) (err error) {
-	var refFileStr, refDirStr, dirStr, baseFileStr string
-	dirStr, baseFileStr = filepath.Split(fileStr)
-	refDirStr = filepath.Join(dirStr, "reference")
-	err = os.MkdirAll(refDirStr, 0755)
-	if err == nil {
-		refFileStr = filepath.Join(refDirStr, baseFileStr)
-		err = fpdf.ComparePDFFiles(fileStr, refFileStr, false)
-	}
-	return
-}
-
 // Summary generates a predictable report for use by test examples. If the
 // specified error is nil, the filename delimiters are normalized and the
 // filename printed to standard output with a success message. If the specified
@@ -144,27 +129,19 @@ func Summary(err error, fileStr string) {
 	}
 }
 
-// SummaryCompare generates a predictable report for use by test examples. If
-// the specified error is nil, the generated file is compared with a reference
-// copy for byte-for-byte equality. If the files match, then the filename
-// delimiters are normalized and the filename printed to standard output with a
-// success message. If the files do not match, this condition is reported on
-// standard output. If the specified error is not nil, its String() value is
-// printed to standard output.
-func SummaryCompare(err error, fileStr string) {
+// SummaryCompare generates a predictable report for use by test examples,
+// relative to the test directory, as Summary() does. If the specified error
+// is nil, the file is first compared with its reference copy by
+// pdftest.ReferenceCompare(), and t fails if they differ.
+func SummaryCompare(t testing.TB, err error, fileStr string) {
+	t.Helper()
 	if err == nil {
-		err = referenceCompare(fileStr)
-	}
-	if err == nil {
-		// Convert absolute path to relative path for consistent output
-		if relPath, relErr := filepath.Rel(string(rootTestDir), fileStr); relErr == nil {
-			fileStr = relPath
+		if err = pdftest.ReferenceCompare(fileStr); err != nil {
+			t.Error(err)
+			return
 		}
-		fileStr = filepath.ToSlash(fileStr)
-		fmt.Printf("Successfully generated %s\n", fileStr)
-	} else {
-		fmt.Println(err)
 	}
+	Summary(err, fileStr)
 }
 
 // ExampleFilename tests the Filename() and Summary() functions.
@@ -0,0 +1,113 @@
+package fpdf
+
+// exclusionType describes a rectangular area that flowing content avoids
+type exclusionType struct {
+	page         int // 1-based page number, or 0 for every page
+	x, y, wd, ht float64
+}
+
+// AddExclusionZone reserves a rectangular area of the page that text flow
+// never prints over, such as the logo area of a letterhead or a pre-printed
+// form field. The rectangle is of width w and height h and its upper left
+// corner is positioned at point (x, y), all in the unit of measure specified
+// in New().
+//
+// A cell printed by CellFormat() or a flowing Image() that would overlap the
+// zone is moved down to the bottom of the zone. The lines of MultiCell() and
+// Write() flow around it instead: each line is narrowed to the free part of
+// the line beside the zone, and is moved down only when less than an em is
+// left free. If moving content down pushes it past the page break trigger,
+// the usual automatic page break takes place. Header and footer functions
+// are not affected.
+//
+// If allPages is true the zone applies to every page of the document,
+// otherwise it applies only to the current page, in which case a page must
+// already have been added.
+func (f *Fpdf) AddExclusionZone(x, y, w, h float64, allPages bool) {
+	if f.err != nil {
+		return
+	}
+	if w <= 0 || h <= 0 {
+		f.errorf("AddExclusionZone", "invalid exclusion zone size: %.2f x %.2f", w, h)
+		return
+	}
+	page := 0
+	if !allPages {
+		if f.page == 0 {
+			f.errorf("AddExclusionZone", "exclusion zone for current page requires a page; call AddPage first")
+			return
+		}
+		page = f.page
+	}
+	f.exclusions = append(f.exclusions, exclusionType{page: page, x: x, y: y, wd: w, ht: h})
+}
+
+// ClearExclusionZones removes all exclusion zones, including those that apply
+// to every page. See AddExclusionZone() for more details.
+func (f *Fpdf) ClearExclusionZones() {
+	f.exclusions = nil
+}
+
+// exclusionY returns the first ordinate at or below y where a box of width w
+// and height h at abscissa x does not overlap any exclusion zone of the
+// current page.
+func (f *Fpdf) exclusionY(x, w, y, h float64) float64 {
+	if len(f.exclusions) == 0 || f.inHeader || f.inFooter {
+		return y
+	}
+	for moved := true; moved; {
+		moved = false
+		for _, ez := range f.exclusions {
+			if ez.page != 0 && ez.page != f.page {
+				continue
+			}
+			if x < ez.x+ez.wd && x+w > ez.x && y < ez.y+ez.ht && y+h > ez.y {
+				y = ez.y + ez.ht
+				moved = true
+			}
+		}
+	}
+	return y
+}
+
+// exclusionSpan returns the abscissa and width of the part of a line of
+// height h at the current ordinate, spanning w from abscissa x, that lies
+// beside the exclusion zones of the current page. When less than an em is
+// left free, the current ordinate is moved below the zones in the way.
+func (f *Fpdf) exclusionSpan(x, w, h float64) (float64, float64) {
+	if len(f.exclusions) == 0 || f.inHeader || f.inFooter {
+		return x, w
+	}
+	for {
+		x0, x1 := x, x+w
+		hit := false
+		bottom := 0.0 // highest bottom of the zones in the way
+		for moved := true; moved; {
+			moved = false
+			for _, ez := range f.exclusions {
+				if ez.page != 0 && ez.page != f.page {
+					continue
+				}
+				if x0 < ez.x+ez.wd && x1 > ez.x && f.y < ez.y+ez.ht && f.y+h > ez.y {
+					if !hit || ez.y+ez.ht < bottom {
+						bottom = ez.y + ez.ht
+					}
+					hit = true
+					if ez.x <= x0 {
+						x0 = ez.x + ez.wd
+					} else {
+						x1 = ez.x
+					}
+					moved = true
+				}
+			}
+		}
+		if !hit {
+			return x, w
+		}
+		if x1-x0-2*f.cMargin >= f.fontSize {
+			return x0, x1 - x0
+		}
+		f.y = bottom
+	}
+}
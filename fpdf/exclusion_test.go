@@ -0,0 +1,120 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestAddExclusionZone(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddExclusionZone(150, 5, 50, 30, true)
+	pdf.AddPage()
+
+	// The default top margin lies inside the zone: the line moves below it.
+	pdf.CellFormat(0, 10, "First line", "", 1, "L", false, 0, "")
+	if got, want := pdf.GetY(), 45.0; !floatEqual(got, want) {
+		t.Errorf("line not moved below zone: got y=%v, want %v", got, want)
+	}
+
+	// A narrow cell beside the zone stays in place, a cell reaching into it
+	// moves below it.
+	pdf.AddPage()
+	_, top, _, _ := pdf.GetMargins()
+	pdf.CellFormat(20, 10, "A", "", 0, "L", false, 0, "")
+	if got := pdf.GetY(); !floatEqual(got, top) {
+		t.Errorf("cell beside zone moved: got y=%v, want %v", got, top)
+	}
+	pdf.SetX(140)
+	pdf.CellFormat(20, 10, "B", "", 1, "L", false, 0, "")
+	if got, want := pdf.GetY(), 45.0; !floatEqual(got, want) {
+		t.Errorf("cell not moved below zone: got y=%v, want %v", got, want)
+	}
+
+	// A left column beside the zone is not moved.
+	pdf.AddPage()
+	pdf.MultiCell(60, 5, "Left column", "", "L", false)
+	if got, want := pdf.GetY(), top+5; !floatEqual(got, want) {
+		t.Errorf("column beside zone moved: got y=%v, want %v", got, want)
+	}
+
+	pdf.ClearExclusionZones()
+	pdf.AddPage()
+	pdf.CellFormat(0, 10, "Free", "", 1, "L", false, 0, "")
+	if got, want := pdf.GetY(), top+10; !floatEqual(got, want) {
+		t.Errorf("cleared zone still applied: got y=%v, want %v", got, want)
+	}
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddExclusionZoneCurrentPage(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddExclusionZone(10, 10, 50, 30, false)
+	if pdf.Error() == nil {
+		t.Fatal("expecting error for current page zone without a page")
+	}
+	pdf.ClearError()
+
+	pdf.AddPage()
+	pdf.AddExclusionZone(10, 10, 50, 30, false)
+	pdf.AddPage()
+	pdf.CellFormat(0, 10, "Second page", "", 1, "L", false, 0, "")
+	_, top, _, _ := pdf.GetMargins()
+	if got, want := pdf.GetY(), top+10; !floatEqual(got, want) {
+		t.Errorf("zone leaked to next page: got y=%v, want %v", got, want)
+	}
+}
+
+func TestExclusionZoneFlow(t *testing.T) {
+	const zoneX, zoneBottom = 150.0, 35.0
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	for _, c := range []struct {
+		name string
+		fn   func(pdf *fpdf.Fpdf)
+	}{
+		{"MultiCell", func(pdf *fpdf.Fpdf) {
+			pdf.MultiCell(0, 5, text, "", "L", false)
+		}},
+		{"Write", func(pdf *fpdf.Fpdf) {
+			pdf.Write(5, text)
+		}},
+	} {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(false)
+		pdf.SetFont("Courier", "", 12)
+		pdf.AddPage()
+		pdf.AddExclusionZone(zoneX, 5, 50, 30, false)
+		c.fn(pdf)
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		_, ph := pdf.GetPageSize()
+		k := pdf.GetConversionRatio()
+		beside, below := 0, 0
+		for _, m := range regexp.MustCompile(`BT ([\d.]+) ([\d.]+) Td \((.*?)\) ?Tj ET`).FindAllStringSubmatch(buf.String(), -1) {
+			x, _ := strconv.ParseFloat(m[1], 64)
+			y, _ := strconv.ParseFloat(m[2], 64)
+			right := x/k + pdf.GetStringWidth(m[3])
+			if ph-y/k < zoneBottom {
+				beside++
+				if right > zoneX {
+					t.Errorf("%s: line %q overlaps the zone", c.name, m[3])
+				}
+			} else if right > zoneX {
+				below++
+			}
+		}
+		if beside == 0 || below == 0 {
+			t.Errorf("%s: text does not flow around the zone: %d lines beside, %d wide lines below", c.name, beside, below)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package fpdf
+
+import "math"
+
+// extentType is the bounding box of the content of a page, in the unit of
+// measure specified in New().
+type extentType struct {
+	minX, minY, maxX, maxY float64
+}
+
+// scale returns e with its coordinates multiplied by r.
+func (e extentType) scale(r float64) extentType {
+	return extentType{e.minX * r, e.minY * r, e.maxX * r, e.maxY * r}
+}
+
+// extend grows the bounding box of the content of the current page to
+// include the rectangle with corners (x0, y0) and (x1, y1).
+func (f *Fpdf) extend(x0, y0, x1, y1 float64) {
+	if f.page < 1 {
+		return
+	}
+	if f.pageExtents == nil {
+		f.pageExtents = make(map[int]extentType)
+	}
+	e, ok := f.pageExtents[f.page]
+	if !ok {
+		e = extentType{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	}
+	e.minX = math.Min(e.minX, math.Min(x0, x1))
+	e.minY = math.Min(e.minY, math.Min(y0, y1))
+	e.maxX = math.Max(e.maxX, math.Max(x0, x1))
+	e.maxY = math.Max(e.maxY, math.Max(y0, y1))
+	f.pageExtents[f.page] = e
+}
+
+// extendPoints grows the bounding box of the content of the current page to
+// include points.
+func (f *Fpdf) extendPoints(points ...PointType) {
+	for _, pt := range points {
+		f.extend(pt.X, pt.Y, pt.X, pt.Y)
+	}
+}
+
+// GetContentExtents returns the bounding box of the content drawn on page
+// pageNo, in the unit of measure specified in New() from the upper left
+// corner of the page, and whether the page has any. It can be used to trim
+// the size of a page to its content, to detect content drawn beyond the
+// margins or to center content after it has been drawn.
+//
+// The box covers the text, cells, lines, shapes, paths, images and gradients
+// drawn with the methods of Fpdf, including the header and footer, at the
+// position where they are drawn before any transformation set with
+// TransformBegin() is applied. The width of lines is not included, the
+// height of text is that of the font size and curves are bounded by their
+// control points.
+func (f *Fpdf) GetContentExtents(pageNo int) (box PageBox, ok bool) {
+	e, ok := f.pageExtents[pageNo]
+	if !ok {
+		return
+	}
+	box.X, box.Y = e.minX, e.minY
+	box.Wd, box.Ht = e.maxX-e.minX, e.maxY-e.minY
+	return
+}
+
+// extendText grows the bounding box of the content of the current page to
+// include txtStr written from its baseline at (x, y) with the current font.
+func (f *Fpdf) extendText(x, y float64, txtStr string) {
+	f.extend(x, y-.8*f.fontSize, x+f.GetStringWidth(txtStr), y+.2*f.fontSize)
+}
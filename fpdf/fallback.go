@@ -0,0 +1,158 @@
+package fpdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// SetFallbackFont sets the UTF-8 font that prints the characters the current
+// UTF-8 font has no glyph for, such as emoji in user-generated content. Text
+// is printed in the current font, and each run of characters that only the
+// fallback font has a glyph for is printed in the fallback font at the same
+// size and on the same baseline. String widths and line breaks take the
+// fallback glyphs into account.
+//
+// A monochrome emoji font, such as Noto Emoji, gives outline glyphs for the
+// emoji, including those beyond the Basic Multilingual Plane. Color glyphs,
+// stored as bitmaps in the CBDT or sbix tables of color emoji fonts, are not
+// supported.
+//
+// The font must have been added with AddUTF8Font() or a related method. See
+// AddFont for details about familyStr and styleStr. An empty familyStr removes
+// the fallback font.
+func (f *Fpdf) SetFallbackFont(familyStr, styleStr string) {
+	if f.err != nil {
+		return
+	}
+	if familyStr == "" {
+		f.fallbackFont = ""
+		return
+	}
+	key := getFontKey(familyStr, styleStr)
+	font, ok := f.fonts[key]
+	switch {
+	case !ok:
+		f.errorf("SetFallbackFont", "undefined font: %s %s", familyStr, styleStr)
+	case font.Tp != "UTF8":
+		f.errorf("SetFallbackFont", "fallback font %s %s is not a UTF-8 font", familyStr, styleStr)
+	default:
+		f.fallbackFont = key
+	}
+}
+
+// fallback returns the fallback font of the current font, or nil if there is
+// none.
+func (f *Fpdf) fallback() *fontDefType {
+	if f.fallbackFont == "" || !f.isCurrentUTF8 {
+		return nil
+	}
+	font, ok := f.fonts[f.fallbackFont]
+	if !ok || font.i == f.currentFont.i {
+		return nil
+	}
+	return &font
+}
+
+// runeFont returns the font that prints r: the current font, or the fallback
+// font if only the latter has a glyph for r.
+func (f *Fpdf) runeFont(r rune) *fontDefType {
+	if fb := f.fallback(); fb != nil && !fontHasGlyph(&f.currentFont, r) && fontHasGlyph(fb, r) {
+		return fb
+	}
+	return &f.currentFont
+}
+
+// needsFallback returns whether part of txtStr is printed with the fallback
+// font.
+func (f *Fpdf) needsFallback(txtStr string) bool {
+	fb := f.fallback()
+	if fb == nil {
+		return false
+	}
+	for _, r := range txtStr {
+		if !fontHasGlyph(&f.currentFont, r) && fontHasGlyph(fb, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// charWidth returns the width of r in glyph units when it is printed with the
+// current font, or with the fallback font if only the latter has a glyph for
+// it. A character that no font has a glyph for takes the missing width of the
+// current font.
+func (f *Fpdf) charWidth(r rune) int {
+	if w, ok := f.currentFont.glyphWidth(r); ok {
+		return w
+	}
+	if fb := f.fallback(); fb != nil {
+		if w, ok := fb.glyphWidth(r); ok {
+			return w
+		}
+	}
+	return f.currentFont.Desc.MissingWidth
+}
+
+// cid returns the code of r in the font: r itself, or, for a character beyond
+// the Basic Multilingual Plane, the code assigned to it when the UTF-8 font
+// was parsed. It returns -1 if the font has no code for r.
+func (font *fontDefType) cid(r rune) int {
+	switch {
+	case r < 0:
+		return -1
+	case r < 0x10000:
+		return int(r)
+	case font.utf8File != nil:
+		if cid, ok := font.utf8File.smpCIDs[int(r)]; ok {
+			return cid
+		}
+	}
+	return -1
+}
+
+// glyphWidth returns the width of r in glyph units in the font, and whether
+// the font has a glyph for it.
+func (font *fontDefType) glyphWidth(r rune) (w int, ok bool) {
+	cid := font.cid(r)
+	if cid < 0 || cid >= len(font.Cw) || font.Cw[cid] == 0 {
+		return 0, false
+	}
+	if font.Cw[cid] == 65535 { // zero width
+		return 0, true
+	}
+	return font.Cw[cid], true
+}
+
+// encodeText returns txtStr encoded for the current UTF-8 font: the two-byte
+// codes of its characters, in the font that prints each of them.
+func (f *Fpdf) encodeText(txtStr string) string {
+	b := make([]byte, 0, 2*len(txtStr))
+	for _, r := range txtStr {
+		cid := max(f.runeFont(r).cid(r), 0)
+		b = append(b, byte(cid>>8), byte(cid))
+	}
+	return string(b)
+}
+
+// toUnicodeCMap returns the ToUnicode CMap of the UTF-8 font, which maps its
+// codes back to the characters they print for text extraction.
+func (font *fontDefType) toUnicodeCMap() string {
+	var chars []string
+	for _, r := range keySortInt(font.utf8File.smpCIDs) {
+		cid := font.utf8File.smpCIDs[r]
+		if _, used := font.usedRunes[cid]; used {
+			rr := r - 0x10000
+			chars = append(chars, Sprintf("<%04X> <%04X%04X>", cid, 0xd800+rr>>10, 0xdc00+rr&0x3ff))
+		}
+	}
+	if len(chars) == 0 {
+		return toUnicode
+	}
+	// The characters beyond the BMP follow the identity range, which they
+	// override.
+	var s fmtBuffer
+	for j := 0; j < len(chars); j += 100 {
+		part := chars[j:min(j+100, len(chars))]
+		s.printf("%d beginbfchar\n%s\nendbfchar\n", len(part), Convert(part).Join("\n").String())
+	}
+	return Convert(toUnicode).Replace("endbfrange\n", "endbfrange\n"+s.String()).String()
+}
@@ -0,0 +1,77 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetFallbackFont(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("calligra", "", "calligra.ttf")
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.AddPage()
+	pdf.SetFont("calligra", "", 12)
+	pdf.SetFallbackFont("dejavu", "")
+
+	want := pdf.GetStringWidth("Hello ") + pdf.StringWidthStyled("😀☺", "dejavu", "", 0)
+	if got := pdf.GetStringWidth("Hello 😀☺"); math.Abs(got-want) > 1e-9 {
+		t.Errorf("string width: got %.3f, want %.3f", got, want)
+	}
+	if missing := pdf.MissingGlyphs("", "", "Hello 😀"); len(missing) != 0 {
+		t.Errorf("missing glyphs: got %q", missing)
+	}
+	pdf.Cell(0, 20, "Hello 😀☺ world")
+	pdf.Ln(-1)
+	pdf.MultiCell(100, 14, "Emoji 😀 in a MultiCell that wraps ☺ over lines", "", "L", false)
+	pdf.Write(14, "Write 😀 too")
+	if len(pdf.GetErrors()) != 0 {
+		t.Fatalf("unexpected errors: %v", pdf.GetErrors())
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// The runs of emoji switch to the fallback font and back.
+	if n := strings.Count(out, " 12.00 Tf ("); n < 8 {
+		t.Errorf("expected font switches around the emoji runs, got %d", n)
+	}
+	// Text extraction maps the code of the emoji back to U+1F600.
+	if !strings.Contains(out, "<D83DDE00>") {
+		t.Errorf("ToUnicode CMap has no entry for U+1F600")
+	}
+}
+
+func TestSetFallbackFontErrors(t *testing.T) {
+	for _, family := range []string{"nosuchfont", "helvetica"} {
+		pdf := NewDocPdfTest()
+		pdf.SetFont("helvetica", "", 12)
+		pdf.SetFallbackFont(family, "")
+		if pdf.Error() == nil {
+			t.Errorf("%s: expected error", family)
+		}
+	}
+}
+
+// A character beyond the Basic Multilingual Plane that the font has no glyph
+// for is reported, not fatal.
+func TestMissingSupplementaryGlyph(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddUTF8Font("calligra", "", "calligra.ttf")
+	pdf.AddPage()
+	pdf.SetFont("calligra", "", 12)
+	pdf.MultiCell(50, 5, "smile 😀", "", "L", false)
+	pdf.Write(5, "smile 😀")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if errs := pdf.GetErrors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "U+1F600") {
+		t.Errorf("expected one missing glyph warning, got %v", errs)
+	}
+}
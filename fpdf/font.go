@@ -2,6 +2,7 @@ package fpdf
 
 import (
 	"bufio"
+	"bytes"
 	"compress/zlib"
 	"encoding/binary"
 	"encoding/json"
@@ -28,55 +29,71 @@ func loadMap(encodingFileStr string) (encList encListType, err error) {
 	f, err = os.Open(encodingFileStr)
 	if err == nil {
 		defer f.Close()
-		for j := range encList {
-			encList[j].uv = -1
-			encList[j].name = ".notdef"
-		}
-		scanner := bufio.NewScanner(f)
-		var enc encType
-		var pos int
-		var parts []string
-		for scanner.Scan() {
-			// "!3F U+003F question"
-			// _, err = Sscanf(scanner.Text(), "!%x U+%x %s", &pos, &enc.uv, &enc.name)
-			parts = Convert(scanner.Text()).Split()
-			if len(parts) >= 3 && HasPrefix(parts[0], "!") && HasPrefix(parts[1], "U+") {
-				pos, err = Convert(parts[0][1:]).Int(16)
-				if err == nil {
-					enc.uv, err = Convert(parts[1][2:]).Int(16)
-				}
-				if err == nil {
-					enc.name = parts[2]
-				}
-			} else {
-				// skip or error? Sscanf would return error if format doesn't match
-				// assuming we skip invalid lines or lines not matching format
-				continue
-			}
+		encList, err = readMap(f)
+	}
+	return
+}
 
+// readMap reads an encoding map in the format of the .map files from r.
+func readMap(r io.Reader) (encList encListType, err error) {
+	for j := range encList {
+		encList[j].uv = -1
+		encList[j].name = ".notdef"
+	}
+	scanner := bufio.NewScanner(r)
+	var enc encType
+	var pos int
+	var parts []string
+	for scanner.Scan() {
+		// "!3F U+003F question"
+		// _, err = Sscanf(scanner.Text(), "!%x U+%x %s", &pos, &enc.uv, &enc.name)
+		parts = Convert(scanner.Text()).Split()
+		if len(parts) >= 3 && HasPrefix(parts[0], "!") && HasPrefix(parts[1], "U+") {
+			pos, err = Convert(parts[0][1:]).Int(16)
+			if err == nil {
+				enc.uv, err = Convert(parts[1][2:]).Int(16)
+			}
 			if err == nil {
-				if pos < 256 {
-					encList[pos] = enc
-				} else {
-					err = Err("map position", pos, "exceeds 0xFF")
-					return
-				}
+				enc.name = parts[2]
+			}
+		} else {
+			// skip or error? Sscanf would return error if format doesn't match
+			// assuming we skip invalid lines or lines not matching format
+			continue
+		}
+
+		if err == nil {
+			if pos < 256 {
+				encList[pos] = enc
 			} else {
+				err = Err("map position", pos, "exceeds 0xFF")
 				return
 			}
-		}
-		if err = scanner.Err(); err != nil {
+		} else {
 			return
 		}
 	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
 	return
 }
 
 // getInfoFromTrueType returns information from a TrueType font
 func getInfoFromTrueType(fileStr string, msgWriter io.Writer, embed bool, encList encListType) (info fontInfoType, err error) {
+	var data []byte
+	data, err = os.ReadFile(fileStr)
+	if err != nil {
+		return
+	}
+	return getInfoFromTrueTypeBytes(data, msgWriter, embed, encList)
+}
+
+// getInfoFromTrueTypeBytes returns information from the TrueType font data
+func getInfoFromTrueTypeBytes(data []byte, msgWriter io.Writer, embed bool, encList encListType) (info fontInfoType, err error) {
 	info.Widths = make([]int, 256)
 	var ttf TtfType
-	ttf, err = TtfParse(fileStr, os.ReadFile)
+	ttf, err = TtfParseBytes(data)
 	if err != nil {
 		return
 	}
@@ -85,10 +102,7 @@ func getInfoFromTrueType(fileStr string, msgWriter io.Writer, embed bool, encLis
 			err = Err("font license embedding", "denied")
 			return
 		}
-		info.Data, err = os.ReadFile(fileStr)
-		if err != nil {
-			return
-		}
+		info.Data = data
 		info.OriginalSize = len(info.Data)
 	}
 	k := 1000.0 / float64(ttf.UnitsPerEm)
@@ -264,6 +278,12 @@ func makeFontEncoding(encList encListType, refEncFileStr string) (diffStr string
 	if refList, err = loadMap(refEncFileStr); err != nil {
 		return
 	}
+	return fontEncodingDiff(encList, refList), nil
+}
+
+// fontEncodingDiff returns the differences of encList from the reference
+// encoding refList
+func fontEncodingDiff(encList, refList encListType) string {
 	var buf fmtBuffer
 	last := 0
 	for j := 32; j < 256; j++ {
@@ -275,13 +295,13 @@ func makeFontEncoding(encList encListType, refEncFileStr string) (diffStr string
 			buf.printf("/%s ", encList[j].name)
 		}
 	}
-	diffStr = Convert(buf.String()).TrimSpace().String()
-	return
+	return Convert(buf.String()).TrimSpace().String()
 }
 
-func makeDefinitionFile(fileStr, tpStr, encodingFileStr string, embed bool, encList encListType, info fontInfoType) error {
-	var err error
-	var def fontDefType
+// makeFontDef returns the font definition of type tpStr described by info,
+// with the encoding encStr whose differences from the reference encoding are
+// diffStr.
+func makeFontDef(tpStr, encStr, diffStr string, info fontInfoType) (def fontDefType) {
 	def.Tp = tpStr
 	def.Name = info.FontName
 	makeFontDescriptor(&info)
@@ -291,17 +311,22 @@ func makeDefinitionFile(fileStr, tpStr, encodingFileStr string, embed bool, encL
 	def.Up = info.UnderlinePosition
 	def.Ut = info.UnderlineThickness
 	def.Cw = info.Widths
-	def.Enc = baseNoExt(encodingFileStr)
-	// fmt.Printf("encodingFileStr [%s], def.Enc [%s]\n", encodingFileStr, def.Enc)
-	// fmt.Printf("reference [%s]\n", filepath.Join(filepath.Dir(encodingFileStr), "cp1252.map"))
-	def.Diff, err = makeFontEncoding(encList, filepath.Join(filepath.Dir(encodingFileStr), "cp1252.map"))
-	if err != nil {
-		return err
-	}
+	def.Enc = encStr
+	def.Diff = diffStr
 	def.File = info.File
 	def.Size1 = int(info.Size1)
 	def.Size2 = int(info.Size2)
 	def.OriginalSize = info.OriginalSize
+	return
+}
+
+func makeDefinitionFile(fileStr, tpStr, encodingFileStr string, embed bool, encList encListType, info fontInfoType) error {
+	// fmt.Printf("reference [%s]\n", filepath.Join(filepath.Dir(encodingFileStr), "cp1252.map"))
+	diffStr, err := makeFontEncoding(encList, filepath.Join(filepath.Dir(encodingFileStr), "cp1252.map"))
+	if err != nil {
+		return err
+	}
+	def := makeFontDef(tpStr, baseNoExt(encodingFileStr), diffStr, info)
 	// printf("Font definition file [%s]\n", fileStr)
 	var buf []byte
 	buf, err = json.Marshal(def)
@@ -419,3 +444,58 @@ func MakeFont(fontFileStr, encodingFileStr, dstDirStr string, msgWriter io.Write
 	Fprintf(msgWriter, "Font definition file successfully generated: %s\n", defFileStr)
 	return nil
 }
+
+// MakeFontBytes is like MakeFont() but works in memory: it returns the font
+// definition in JSON format and, if embed is true, the compressed font data
+// that MakeFont() would write to the .json and .z files. They can be passed
+// to AddFontFromBytes(). See AddFontFromTrueType() to add a font directly.
+//
+// fontBytes is the content of a TrueType file or of an OpenType file based
+// on TrueType outlines.
+//
+// encodingMap is the content of the encoding file that corresponds to the
+// font, such as "cp1250.map". If it is nil, the cp1252 encoding of the core
+// fonts is used.
+//
+// msgWriter is the writer that is called to display messages, such as the
+// characters of the encoding that are missing from the font. Use nil to turn
+// off messages.
+func MakeFontBytes(fontBytes, encodingMap []byte, msgWriter io.Writer, embed bool) (jsonBytes, zBytes []byte, err error) {
+	if msgWriter == nil {
+		msgWriter = io.Discard
+	}
+	refMap, err := embFS.ReadFile("font_embed/cp1252.map")
+	if err != nil {
+		return
+	}
+	encStr := ""
+	if encodingMap == nil {
+		encodingMap, encStr = refMap, "cp1252"
+	}
+	var encList, refList encListType
+	if encList, err = readMap(bytes.NewReader(encodingMap)); err != nil {
+		return
+	}
+	if refList, err = readMap(bytes.NewReader(refMap)); err != nil {
+		return
+	}
+	var info fontInfoType
+	info, err = getInfoFromTrueTypeBytes(fontBytes, msgWriter, embed, encList)
+	if err != nil {
+		return
+	}
+	if embed {
+		info.File = info.FontName + ".z"
+		var buf bytes.Buffer
+		cmp := zlib.NewWriter(&buf)
+		if _, err = cmp.Write(info.Data); err != nil {
+			return
+		}
+		if err = cmp.Close(); err != nil {
+			return
+		}
+		zBytes = buf.Bytes()
+	}
+	jsonBytes, err = json.Marshal(makeFontDef("TrueType", encStr, fontEncodingDiff(encList, refList), info))
+	return
+}
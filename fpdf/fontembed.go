@@ -0,0 +1,127 @@
+package fpdf
+
+import "sort"
+
+// FontEmbedding specifies how much of the file of a font is embedded in the
+// document, see SetFontEmbedding().
+type FontEmbedding int
+
+const (
+	// FontEmbedSubset embeds only the glyphs used in the document. This is
+	// the default. Fonts added with AddUTF8Font() are subset; the others are
+	// embedded whole as with FontEmbedFull.
+	FontEmbedSubset FontEmbedding = iota
+	// FontEmbedFull embeds the whole font file, so that the document can be
+	// edited with the glyphs that it does not use yet.
+	FontEmbedFull
+	// FontEmbedNone embeds only the metrics of the font, for fonts whose
+	// license does not permit embedding. The viewer displays the text with
+	// the font of the same PostScript name installed on the system, or with
+	// a substitute. The glyphs of a font added with AddUTF8Font() are then
+	// addressed by their number in the font file, so the installed font is
+	// to be the same version as the file.
+	FontEmbedNone
+)
+
+// SetFontEmbedding sets how the fonts of family familyStr, in every style,
+// are embedded when the document is output. The standard fonts are never
+// embedded. An invalid policy is an error.
+//
+// The flags of the descriptor of a font added with AddUTF8Font() follow the
+// policy: the font is declared symbolic when it is embedded, as its glyphs
+// are selected by their number, and nonsymbolic when it is not, so that
+// viewers substitute a font of the standard Latin character set.
+func (f *Fpdf) SetFontEmbedding(familyStr string, policy FontEmbedding) {
+	if f.err != nil {
+		return
+	}
+	if policy < FontEmbedSubset || policy > FontEmbedNone {
+		f.errorf("SetFontEmbedding", "invalid font embedding policy: %d", policy)
+		return
+	}
+	if f.fontEmbedding == nil {
+		f.fontEmbedding = make(map[string]FontEmbedding)
+	}
+	f.fontEmbedding[getFontKey(familyStr, "")] = policy
+}
+
+// fontEmbeddingOf returns the embedding policy of the font of key fontkey,
+// a lowercase family followed by an uppercase style as made by getFontKey.
+func (f *Fpdf) fontEmbeddingOf(fontkey string) FontEmbedding {
+	family := fontkey
+	for len(family) > 0 && (family[len(family)-1] == 'B' || family[len(family)-1] == 'I') {
+		family = family[:len(family)-1]
+	}
+	return f.fontEmbedding[family]
+}
+
+// The text of a UTF-8 font that is not embedded is written with the codes of
+// an embedded one, which the glyph CMap maps to the numbers of the glyphs in
+// the font file. The viewer finds the glyphs by these numbers in the font
+// installed on the system, since a font program that is not embedded can
+// only be addressed by glyph number.
+const (
+	glyphCMapName      = "Fpdf-Glyphs"
+	glyphCIDSystemInfo = "<</Registry (Adobe) /Ordering (Identity) /Supplement 0>>"
+)
+
+// glyphCodes returns the codes of the characters printed with the UTF-8 font
+// and the numbers of their glyphs in the font file.
+func (font *fontDefType) glyphCodes() (codes []int, glyphs map[int]int) {
+	glyphs = make(map[int]int)
+	for _, code := range keySortInt(font.usedRunes) {
+		if glyph, ok := font.utf8File.charSymbolDictionary[code]; ok && code > 0 {
+			codes = append(codes, code)
+			glyphs[code] = glyph
+		}
+	}
+	return
+}
+
+// glyphCMap returns the glyph CMap of the UTF-8 font, which maps the codes of
+// its text to the numbers of the glyphs in the font file.
+func (font *fontDefType) glyphCMap() string {
+	codes, glyphs := font.glyphCodes()
+	var s fmtBuffer
+	s.printf("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	s.printf("/CIDSystemInfo %s def\n/CMapName /%s def\n/CMapType 1 def\n", glyphCIDSystemInfo, glyphCMapName)
+	s.printf("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for j := 0; j < len(codes); j += 100 {
+		part := codes[j:min(j+100, len(codes))]
+		s.printf("%d begincidchar\n", len(part))
+		for _, code := range part {
+			s.printf("<%04X> %d\n", code, glyphs[code])
+		}
+		s.printf("endcidchar\n")
+	}
+	s.printf("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+	return s.String()
+}
+
+// glyphWidths returns the /W entry of the UTF-8 font that is not embedded,
+// with the widths of the glyphs it prints by glyph number.
+func (font *fontDefType) glyphWidths() string {
+	codes, glyphs := font.glyphCodes()
+	widths := make(map[int]int)
+	for _, code := range codes {
+		if code < len(font.Cw) {
+			if w := font.Cw[code]; w != 65535 {
+				widths[glyphs[code]] = w
+			} else {
+				widths[glyphs[code]] = 0
+			}
+		}
+	}
+	keys := make([]int, 0, len(widths))
+	for glyph := range widths {
+		keys = append(keys, glyph)
+	}
+	sort.Ints(keys)
+	var s fmtBuffer
+	s.printf("/W [")
+	for _, glyph := range keys {
+		s.printf("%d [%d] ", glyph, widths[glyph])
+	}
+	s.printf("]")
+	return s.String()
+}
@@ -0,0 +1,157 @@
+package fpdf
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// fontStyleSuffixes lists the endings of the names of font files for each
+// style, after the name of the family.
+var fontStyleSuffixes = map[string][]string{
+	"":   {"", "regular", "book", "roman"},
+	"B":  {"b", "bold"},
+	"I":  {"i", "italic", "oblique"},
+	"BI": {"bi", "ib", "bolditalic", "boldoblique"},
+}
+
+// SetFontFS sets a file system, such as an embed.FS, from which fonts are
+// loaded when they are first used, so that applications can ship their fonts
+// inside the executable with go:embed:
+//
+//	//go:embed fonts
+//	var fonts embed.FS
+//
+//	pdf.SetFontFS(fonts)
+//	pdf.SetFont("DejaVu Sans", "B", 12) // loads fonts/DejaVuSans-Bold.ttf
+//
+// When SetFont() is called with a family and style that have not been added,
+// the file system is searched for a TrueType font (.ttf) or a font definition
+// file made by makefont (.json) whose name is the family followed by the
+// style, ignoring case, spaces, hyphens and underscores. The style is
+// written as in "DejaVuSansB" or in full as in "DejaVuSans-Bold",
+// "DejaVuSans-Italic" or "DejaVuSans-Oblique" and "DejaVuSans-BoldItalic";
+// a regular font can also end with "Regular". The font file named by a
+// definition file is read from the file system as well. Fonts of the file
+// system take precedence over the core fonts of the same name.
+//
+// SetFont() also accepts the path of a font file in the file system, or in
+// the font directory if no file system has been set or the file is not in
+// it, in which case the family is the name of the file without extension.
+func (f *Fpdf) SetFontFS(fsys fs.FS) {
+	f.fontFS = fsys
+	f.fontFSIndex = nil
+}
+
+// fontFileKey returns name, the name of a font file without extension or the
+// name of a font family, normalized for lookups.
+func fontFileKey(name string) string {
+	return Convert(name).ToLower().Replace("#20", "").Replace(" ", "").Replace("-", "").Replace("_", "").String()
+}
+
+// fontFSFile returns the path in the file system set with SetFontFS() of the
+// font file with key key, as returned by fontFileKey(), and extension ext.
+func (f *Fpdf) fontFSFile(key, ext string) (name string, ok bool) {
+	if f.fontFSIndex == nil {
+		f.fontFSIndex = make(map[string]string)
+		fs.WalkDir(f.fontFS, ".", func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				e := Convert(path.Ext(p)).ToLower().String()
+				if e == ".ttf" || e == ".json" || e == ".z" {
+					k := fontFileKey(p[len(path.Dir(p))+1:len(p)-len(e)]) + e
+					if _, dup := f.fontFSIndex[k]; !dup {
+						f.fontFSIndex[k] = p
+					}
+				}
+			}
+			return nil
+		})
+	}
+	name, ok = f.fontFSIndex[key+ext]
+	return
+}
+
+// loadFontFS adds the font of family familyStr and style styleStr from the
+// file system set with SetFontFS(), and returns whether it was found there.
+func (f *Fpdf) loadFontFS(familyStr, styleStr string) bool {
+	if f.fontFS == nil {
+		return false
+	}
+	key := fontFileKey(familyStr)
+	for _, suffix := range fontStyleSuffixes[styleStr] {
+		for _, ext := range []string{".ttf", ".json"} {
+			if name, ok := f.fontFSFile(key+suffix, ext); ok {
+				f.addFontFS(familyStr, styleStr, name)
+				return f.err == nil
+			}
+		}
+	}
+	return false
+}
+
+// addFontFS adds the font of family familyStr and style styleStr from the
+// file name of the file system set with SetFontFS().
+func (f *Fpdf) addFontFS(familyStr, styleStr, name string) {
+	data, err := fs.ReadFile(f.fontFS, name)
+	if err != nil {
+		f.setError("SetFont", err)
+		return
+	}
+	if Convert(path.Ext(name)).ToLower().String() == ".ttf" {
+		f.addFontFromBytes("SetFont", familyStr, styleStr, nil, nil, data)
+	} else {
+		f.AddFontFromReader(familyStr, styleStr, bytes.NewReader(data))
+	}
+}
+
+// readFontFS returns the content of the font file name, the base name of a
+// file given by a font definition file, from the file system set with
+// SetFontFS().
+func (f *Fpdf) readFontFS(name string) (data []byte, ok bool) {
+	if f.fontFS == nil {
+		return
+	}
+	ext := Convert(path.Ext(name)).ToLower().String()
+	p, ok := f.fontFSFile(fontFileKey(name[:len(name)-len(ext)]), ext)
+	if !ok {
+		return
+	}
+	data, err := fs.ReadFile(f.fontFS, p)
+	return data, err == nil
+}
+
+// addFontFile adds the font of the file fileStr, a path ending with ".ttf" or
+// ".json", with style styleStr unless it has been added already, and returns
+// its family.
+func (f *Fpdf) addFontFile(fileStr, styleStr string) (familyStr string) {
+	ext := Convert(path.Ext(fileStr)).ToLower().String()
+	familyStr = path.Base(fileStr[:len(fileStr)-len(ext)])
+	styleStr = Convert(styleStr).ToUpper().Replace("U", "").Replace("S", "").String()
+	if _, ok := f.fonts[getFontKey(familyStr, styleStr)]; ok {
+		return
+	}
+	if f.fontFS != nil {
+		if _, err := fs.Stat(f.fontFS, fileStr); err == nil {
+			f.addFontFS(familyStr, styleStr, fileStr)
+			return
+		}
+	}
+	if ext == ".ttf" {
+		f.AddUTF8Font(familyStr, styleStr, fileStr)
+	} else {
+		f.AddFont(familyStr, styleStr, fileStr)
+	}
+	return
+}
+
+// isFontFile returns whether s is the name of a font file rather than of a
+// font family.
+func isFontFile(s string) bool {
+	switch Convert(path.Ext(s)).ToLower().String() {
+	case ".ttf", ".json":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSetFontFS(t *testing.T) {
+	ttf, err := os.ReadFile(FontFile("DejaVuSansCondensed-Bold.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := os.ReadFile(FontFile("calligra.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, err := os.ReadFile(FontFile("calligra.z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"fonts/DejaVuSansCondensed-Bold.ttf": {Data: ttf},
+		"fonts/type1/Calligra.json":          {Data: def},
+		"fonts/type1/calligra.z":             {Data: z},
+	}
+
+	pdf := NewDocPdfTest()
+	pdf.SetFontFS(fsys)
+	pdf.AddPage()
+	pdf.SetFont("DejaVu Sans Condensed", "B", 14)
+	pdf.Cell(0, 10, "Bold from the file system")
+	pdf.Ln(10)
+	pdf.SetFont("calligra", "", 14)
+	pdf.Cell(0, 10, "Type1 definition from the file system")
+	pdf.Ln(10)
+	// A path in the file system sets the family to the name of the file.
+	pdf.SetFont("fonts/DejaVuSansCondensed-Bold.ttf", "I", 12)
+	if family := pdf.GetFontFamily(); family != "dejavusanscondensed-bold" {
+		t.Errorf("unexpected family: %s", family)
+	}
+	pdf.Cell(0, 10, "By path")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"/BaseFont /utf8dejavu#20sans#20condensedB", "/BaseFont /CalligrapherRegular", "/FontFile2"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	// A style missing from the file system is not a core font either.
+	pdf = NewDocPdfTest()
+	pdf.SetFontFS(fsys)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetFont("DejaVu Sans Condensed", "I", 12)
+	if !pdf.Err() {
+		t.Errorf("expected error for a missing style")
+	}
+
+	// Without a file system, a path is read from the font directory.
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetFont("DejaVuSansCondensed.ttf", "", 12)
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+	if family := pdf.GetFontFamily(); family != "dejavusanscondensed" {
+		t.Errorf("unexpected family: %s", family)
+	}
+}
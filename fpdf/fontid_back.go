@@ -4,8 +4,8 @@ package fpdf
 
 import (
 	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
-	. "github.com/tinywasm/fmt"
 )
 
 func generateImageID(info *ImageInfoType) (string, error) {
@@ -28,7 +28,7 @@ func generateImageID(info *ImageInfoType) (string, error) {
 	enc.f64(info.dpi)
 	enc.str(info.i)
 
-	return Sprintf("%x", sha.Sum(nil)), nil
+	return hex.EncodeToString(sha.Sum(nil)), nil
 }
 
 // generateFontID generates a font Id from the font definition
@@ -36,5 +36,6 @@ func generateFontID(fdt fontDefType) (string, error) {
 	// file can be different if generated in different instance
 	fdt.File = ""
 	b, err := json.Marshal(&fdt)
-	return Sprintf("%x", sha1.Sum(b)), err
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:]), err
 }
@@ -26,7 +26,7 @@ import (
 //
 // zFileBytes contain all bytes of Z file.
 func (f *Fpdf) AddFontFromBytes(familyStr, styleStr string, jsonFileBytes, zFileBytes []byte) {
-	f.addFontFromBytes(fontFamilyEscape(familyStr), styleStr, jsonFileBytes, zFileBytes, nil)
+	f.addFontFromBytes("AddFontFromBytes", familyStr, styleStr, jsonFileBytes, zFileBytes, nil)
 }
 
 // AddUTF8FontFromBytes  imports a TrueType font with utf-8 symbols from static
@@ -45,10 +45,41 @@ func (f *Fpdf) AddFontFromBytes(familyStr, styleStr string, jsonFileBytes, zFile
 //
 // zFileBytes contain all bytes of Z file.
 func (f *Fpdf) AddUTF8FontFromBytes(familyStr, styleStr string, utf8Bytes []byte) {
-	f.addFontFromBytes(fontFamilyEscape(familyStr), styleStr, nil, nil, utf8Bytes)
+	f.addFontFromBytes("AddUTF8FontFromBytes", familyStr, styleStr, nil, nil, utf8Bytes)
 }
 
-func (f *Fpdf) addFontFromBytes(familyStr, styleStr string, jsonFileBytes, zFileBytes, utf8Bytes []byte) {
+// AddFontFromTrueType imports a TrueType font, or an OpenType font based on
+// TrueType outlines, from the bytes of its file and makes it available in
+// codepage mode, like the fonts added with AddFont(). The font definition is
+// generated in memory with MakeFontBytes() and the font is embedded, so that
+// no definition file has to be generated with the makefont utility.
+//
+// See AddFont() for details about familyStr and styleStr.
+//
+// encodingMap is the content of the encoding file that corresponds to the
+// font, such as "cp1250.map", whose code page must then be used to translate
+// text with UnicodeTranslator(). If it is nil, the cp1252 encoding of the
+// core fonts is used.
+func (f *Fpdf) AddFontFromTrueType(familyStr, styleStr string, fontBytes, encodingMap []byte) {
+	f.addFontFromTrueType("AddFontFromTrueType", familyStr, styleStr, fontBytes, encodingMap)
+}
+
+func (f *Fpdf) addFontFromTrueType(method, familyStr, styleStr string, fontBytes, encodingMap []byte) {
+	if f.err != nil {
+		return
+	}
+	if _, ok := f.fonts[getFontKey(familyStr, styleStr)]; ok {
+		return
+	}
+	jsonBytes, zBytes, err := MakeFontBytes(fontBytes, encodingMap, nil, true)
+	if err != nil {
+		f.setError(method, err)
+		return
+	}
+	f.addFontFromBytes(method, familyStr, styleStr, jsonBytes, zBytes, nil)
+}
+
+func (f *Fpdf) addFontFromBytes(method, familyStr, styleStr string, jsonFileBytes, zFileBytes, utf8Bytes []byte) {
 	if f.err != nil {
 		return
 	}
@@ -113,7 +144,7 @@ func (f *Fpdf) addFontFromBytes(familyStr, styleStr string, jsonFileBytes, zFile
 		err := unmarshalFontDef(jsonFileBytes, &info)
 
 		if err != nil {
-			f.err = err
+			f.setError(method, err)
 		}
 
 		if f.err != nil {
@@ -121,7 +152,7 @@ func (f *Fpdf) addFontFromBytes(familyStr, styleStr string, jsonFileBytes, zFile
 		}
 
 		if info.i, err = generateFontID(info); err != nil {
-			f.err = err
+			f.setError(method, err)
 			return
 		}
 
@@ -184,7 +215,6 @@ func (f *Fpdf) AddFontFromReader(familyStr, styleStr string, r io.Reader) {
 		return
 	}
 	// dbg("Adding family [%s], style [%s]", familyStr, styleStr)
-	familyStr = fontFamilyEscape(familyStr)
 	var ok bool
 	fontkey := getFontKey(familyStr, styleStr)
 	_, ok = f.fonts[fontkey]
@@ -231,7 +261,70 @@ func (f *Fpdf) GetFontDesc(familyStr, styleStr string) FontDescType {
 	if familyStr == "" {
 		return f.currentFont.Desc
 	}
-	return f.fonts[getFontKey(fontFamilyEscape(familyStr), styleStr)].Desc
+	return f.fonts[getFontKey(familyStr, styleStr)].Desc
+}
+
+// HasGlyph returns whether the font has a glyph for r. If familyStr is empty
+// the current font is used; a core font that has not been used yet is loaded.
+// For a font that is not UTF-8, r is a code of its encoding, such as cp1252.
+// It returns false if the font is not available.
+// See AddFont for details about familyStr and styleStr.
+func (f *Fpdf) HasGlyph(familyStr, styleStr string, r rune) bool {
+	font, ok := f.glyphFont(familyStr, styleStr)
+	return ok && fontHasGlyph(&font, r)
+}
+
+// MissingGlyphs returns the characters of txtStr that the font has no glyph
+// for, each once, in the order in which they first appear. Control
+// characters, such as newlines, are not reported. Checking the text before it
+// is printed makes it possible to choose another font for it rather than to
+// discover empty boxes in the document. For the current font, the characters
+// printed with the fallback font, see SetFallbackFont(), are not missing. All
+// the characters are reported if the font is not available. See HasGlyph()
+// for details about familyStr and styleStr.
+func (f *Fpdf) MissingGlyphs(familyStr, styleStr, txtStr string) (missing []rune) {
+	font, ok := f.glyphFont(familyStr, styleStr)
+	seen := make(map[rune]bool)
+	for _, r := range txtStr {
+		if familyStr == "" && ok {
+			font = *f.runeFont(r)
+		}
+		if r < 32 || seen[r] || (ok && fontHasGlyph(&font, r)) {
+			continue
+		}
+		seen[r] = true
+		missing = append(missing, r)
+	}
+	return
+}
+
+// glyphFont returns the font inspected by HasGlyph() and MissingGlyphs(),
+// loading it if it is a core font that has not been used yet.
+func (f *Fpdf) glyphFont(familyStr, styleStr string) (font fontDefType, ok bool) {
+	if familyStr == "" {
+		return f.currentFont, f.currentFont.Cw != nil
+	}
+	familyStr = Convert(familyStr).ToLower().String()
+	styleStr = Convert(styleStr).ToUpper().String()
+	if styleStr == "IB" {
+		styleStr = "BI"
+	}
+	if font, ok = f.fonts[familyStr+styleStr]; ok {
+		return
+	}
+	if familyStr != "arial" && !f.coreFonts[familyStr] || f.err != nil {
+		return
+	}
+	if familyStr, styleStr, ok = f.resolveFont("HasGlyph", familyStr, styleStr); ok {
+		font, ok = f.fonts[familyStr+styleStr]
+	}
+	return
+}
+
+// fontHasGlyph returns whether font has a glyph for r.
+func fontHasGlyph(font *fontDefType, r rune) bool {
+	_, ok := font.glyphWidth(r)
+	return ok
 }
 
 // SetFont sets the font used to print character strings. It is mandatory to
@@ -264,11 +357,16 @@ func (f *Fpdf) GetFontDesc(familyStr, styleStr string) FontDescType {
 func (f *Fpdf) SetFont(familyStr, styleStr string, size float64) {
 	// dbg("SetFont x %.2f, lMargin %.2f", f.x, f.lMargin)
 
-	if f.err != nil {
+	if f.err != nil || !f.strictLength("SetFont", "size", size) {
 		return
 	}
 	// dbg("SetFont")
-	familyStr = fontFamilyEscape(familyStr)
+	if isFontFile(familyStr) {
+		familyStr = f.addFontFile(familyStr, styleStr)
+		if f.err != nil {
+			return
+		}
+	}
 	var ok bool
 	if familyStr == "" {
 		familyStr = f.fontFamily
@@ -291,42 +389,16 @@ func (f *Fpdf) SetFont(familyStr, styleStr string, size float64) {
 		size = f.fontSizePt
 	}
 
-	// Test if font is already loaded
-	fontKey := familyStr + styleStr
-	_, ok = f.fonts[fontKey]
+	styleStr, synth := f.synthesizedStyle(familyStr, styleStr)
+	familyStr, styleStr, ok = f.resolveFont("SetFont", familyStr, styleStr)
 	if !ok {
-		// Test if one of the core fonts
-		if familyStr == "arial" {
-			familyStr = "helvetica"
-		}
-		_, ok = f.coreFonts[familyStr]
-		if ok {
-			if familyStr == "symbol" {
-				familyStr = "zapfdingbats"
-			}
-			if familyStr == "zapfdingbats" {
-				styleStr = ""
-			}
-			fontKey = familyStr + styleStr
-			_, ok = f.fonts[fontKey]
-			if !ok {
-				rdr := f.coreFontReader(familyStr, styleStr)
-				if f.err == nil {
-					defer rdr.Close()
-					f.AddFontFromReader(familyStr, styleStr, rdr)
-				}
-				if f.err != nil {
-					return
-				}
-			}
-		} else {
-			f.err = Errf("undefined font: %s %s", familyStr, styleStr)
-			return
-		}
+		return
 	}
+	fontKey := familyStr + styleStr
 	// Select it
 	f.fontFamily = familyStr
 	f.fontStyle = styleStr
+	f.fontSynth = synth
 	f.fontSizePt = size
 	f.fontSize = size / f.k
 	f.currentFont = f.fonts[fontKey]
@@ -340,6 +412,45 @@ func (f *Fpdf) SetFont(familyStr, styleStr string, size float64) {
 	}
 }
 
+// resolveFont returns the family and style under which the font is
+// registered, loading it first if it is one of the core fonts. familyStr must
+// be lower case and styleStr upper case without "U" or "S". If the font is
+// not available, the error is recorded on behalf of method.
+func (f *Fpdf) resolveFont(method, familyStr, styleStr string) (family, style string, ok bool) {
+	// Test if font is already loaded
+	if _, ok = f.fonts[familyStr+styleStr]; ok {
+		return familyStr, styleStr, true
+	}
+	if f.loadFontFS(familyStr, styleStr) {
+		return familyStr, styleStr, true
+	}
+	// Test if one of the core fonts
+	if familyStr == "arial" {
+		familyStr = "helvetica"
+	}
+	if _, ok = f.coreFonts[familyStr]; !ok {
+		f.errorf(method, "undefined font: %s %s", familyStr, styleStr)
+		return
+	}
+	if familyStr == "symbol" {
+		familyStr = "zapfdingbats"
+	}
+	if familyStr == "zapfdingbats" {
+		styleStr = ""
+	}
+	if _, ok = f.fonts[familyStr+styleStr]; !ok {
+		rdr := f.coreFontReader(method, familyStr, styleStr)
+		if f.err == nil {
+			defer rdr.Close()
+			f.AddFontFromReader(familyStr, styleStr, rdr)
+		}
+		if f.err != nil {
+			return familyStr, styleStr, false
+		}
+	}
+	return familyStr, styleStr, true
+}
+
 // GetFontFamily returns the family of the current font. See SetFont() for details.
 func (f *Fpdf) GetFontFamily() string {
 	return f.fontFamily
@@ -347,8 +458,10 @@ func (f *Fpdf) GetFontFamily() string {
 
 // GetFontStyle returns the style of the current font. See SetFont() for details.
 func (f *Fpdf) GetFontStyle() string {
-	styleStr := f.fontStyle
-
+	styleStr := f.fontStyle + f.fontSynth
+	if styleStr == "IB" {
+		styleStr = "BI"
+	}
 	if f.underline {
 		styleStr += "U"
 	}
@@ -367,6 +480,9 @@ func (f *Fpdf) SetFontStyle(styleStr string) {
 // SetFontSize defines the size of the current font. Size is specified in
 // points (1/ 72 inch). See also SetFontUnitSize().
 func (f *Fpdf) SetFontSize(size float64) {
+	if !f.strictLength("SetFontSize", "size", size) {
+		return
+	}
 	f.fontSizePt = size
 	f.fontSize = size / f.k
 	if f.page > 0 {
@@ -377,6 +493,9 @@ func (f *Fpdf) SetFontSize(size float64) {
 // SetFontUnitSize defines the size of the current font. Size is specified in
 // the unit of measure specified in New(). See also SetFontSize().
 func (f *Fpdf) SetFontUnitSize(size float64) {
+	if !f.strictLength("SetFontUnitSize", "size", size) {
+		return
+	}
 	f.fontSizePt = size * f.k
 	f.fontSize = size
 	if f.page > 0 {
@@ -391,6 +510,48 @@ func (f *Fpdf) GetFontSize() (ptSize, unitSize float64) {
 	return f.fontSizePt, f.fontSize
 }
 
+// coreFontMetrics holds the ascender, descender, cap height and x-height of
+// the core fonts in glyph units, as published in their AFM files. The
+// embedded core font definitions carry widths only.
+var coreFontMetrics = map[string][4]int{
+	"Courier":               {629, -157, 562, 426},
+	"Courier-Bold":          {629, -157, 562, 439},
+	"Courier-BoldOblique":   {629, -157, 562, 439},
+	"Courier-Oblique":       {629, -157, 562, 426},
+	"Helvetica":             {718, -207, 718, 523},
+	"Helvetica-Bold":        {718, -207, 718, 532},
+	"Helvetica-BoldOblique": {718, -207, 718, 532},
+	"Helvetica-Oblique":     {718, -207, 718, 523},
+	"Times-Roman":           {683, -217, 662, 450},
+	"Times-Bold":            {683, -217, 676, 461},
+	"Times-BoldItalic":      {683, -217, 669, 462},
+	"Times-Italic":          {683, -217, 653, 441},
+	"ZapfDingbats":          {820, -143, 820, 820},
+}
+
+// GetFontMetrics returns the vertical metrics of the current font at the
+// current size, in the unit of measure specified in New(). ascent is the
+// height above the baseline reached by the tallest glyphs, descent is the
+// depth below the baseline (a negative value, as in FontDescType), capHeight
+// is the height of flat capital letters such as "H" and xHeight the height of
+// lowercase letters such as "x".
+//
+// Definitions of fonts added with AddFont() or AddUTF8Font() do not record
+// the x-height, so for those fonts it is estimated as 70% of the cap height,
+// which is close for common text faces.
+func (f *Fpdf) GetFontMetrics() (ascent, descent, capHeight, xHeight float64) {
+	scale := f.fontSize / 1000
+	if m, ok := coreFontMetrics[f.currentFont.Name]; ok {
+		return float64(m[0]) * scale, float64(m[1]) * scale, float64(m[2]) * scale, float64(m[3]) * scale
+	}
+	desc := f.currentFont.Desc
+	ascent = float64(desc.Ascent) * scale
+	descent = float64(desc.Descent) * scale
+	capHeight = float64(desc.CapHeight) * scale
+	xHeight = capHeight * 0.7
+	return
+}
+
 // GetFontLoader returns the loader used to read font files (.json and .z) from
 // an arbitrary source.
 func (f *Fpdf) GetFontLoader() FontLoader {
@@ -425,9 +586,11 @@ func (f *Fpdf) SetFontLoader(loader FontLoader) {
 //
 // fileStr specifies the base name with ".json" extension of the font
 // definition file to be added. The file will be loaded from the font directory
-// specified in the call to New() or SetFontLocation().
+// specified in the call to New() or SetFontLocation(). fileStr can also name
+// a TrueType file with the ".ttf" or ".otf" extension, which is converted with
+// the cp1252 encoding as by AddFontFromTrueType(), without a definition file.
 func (f *Fpdf) AddFont(familyStr, styleStr, fileStr string) {
-	f.addFont(fontFamilyEscape(familyStr), styleStr, fileStr, false)
+	f.addFont("AddFont", familyStr, styleStr, fileStr, false)
 }
 
 // AddUTF8Font imports a TrueType font with utf-8 symbols and makes it available.
@@ -451,10 +614,10 @@ func (f *Fpdf) AddFont(familyStr, styleStr, fileStr string) {
 // definition file to be added. The file will be loaded from the font directory
 // specified in the call to New() or SetFontLocation().
 func (f *Fpdf) AddUTF8Font(familyStr, styleStr, fileStr string) {
-	f.addFont(fontFamilyEscape(familyStr), styleStr, fileStr, true)
+	f.addFont("AddUTF8Font", familyStr, styleStr, fileStr, true)
 }
 
-func (f *Fpdf) addFont(familyStr, styleStr, fileStr string, isUTF8 bool) {
+func (f *Fpdf) addFont(method, familyStr, styleStr, fileStr string, isUTF8 bool) {
 	if fileStr == "" {
 		if isUTF8 {
 			fileStr = Convert(familyStr).Replace(" ", "").String() + Convert(styleStr).ToLower().String() + ".ttf"
@@ -477,21 +640,21 @@ func (f *Fpdf) addFont(familyStr, styleStr, fileStr string, isUTF8 bool) {
 		}
 		originalSize, err = f.fileSize(fileStr)
 		if err != nil {
-			f.SetError(err)
+			f.setError(method, err)
 			return
 		}
 		Type := "UTF8"
 		var utf8Bytes []byte
 		utf8Bytes, err = f.readFile(fileStr)
 		if err != nil {
-			f.SetError(err)
+			f.setError(method, err)
 			return
 		}
 		reader := fileReader{readerPosition: 0, array: utf8Bytes}
 		utf8File := newUTF8Font(&reader)
 		err = utf8File.parseFile()
 		if err != nil {
-			f.SetError(err)
+			f.setError(method, err)
 			return
 		}
 
@@ -533,6 +696,18 @@ func (f *Fpdf) addFont(familyStr, styleStr, fileStr string, isUTF8 bool) {
 			fontType: "UTF8",
 		}
 	} else {
+		if ext := Convert(path.Ext(fileStr)).ToLower().String(); ext == ".ttf" || ext == ".otf" {
+			if !filepath.IsAbs(fileStr) {
+				fileStr = path.Join(f.fontsPath, fileStr)
+			}
+			data, err := f.readFile(fileStr)
+			if err != nil {
+				f.setError(method, err)
+				return
+			}
+			f.addFontFromTrueType(method, familyStr, styleStr, data, nil)
+			return
+		}
 		if f.fontLoader != nil {
 			reader, err := f.fontLoader.Open(fileStr)
 			if err == nil {
@@ -551,7 +726,7 @@ func (f *Fpdf) addFont(familyStr, styleStr, fileStr string, isUTF8 bool) {
 		}
 		data, err := f.readFile(fileStr)
 		if err != nil {
-			f.err = err
+			f.setError(method, err)
 			return
 		}
 
@@ -572,6 +747,9 @@ func (f *Fpdf) SetFontLocation(fontDirStr string) {
 }
 
 func (f *Fpdf) loadFontFile(name string) ([]byte, error) {
+	if data, ok := f.readFontFS(name); ok {
+		return data, nil
+	}
 	if f.fontLoader != nil {
 		reader, err := f.fontLoader.Open(name)
 		if err == nil {
@@ -610,9 +788,16 @@ func (f *Fpdf) putfonts() {
 		if f.catalogSort {
 			sort.SliceStable(fileList, func(i, j int) bool { return fileList[i] < fileList[j] })
 		}
+		// The files of the fonts that are not embedded are left out
+		embed := make(map[string]bool)
+		for key, font := range f.fonts {
+			if font.File != "" && f.fontEmbeddingOf(key) != FontEmbedNone {
+				embed[font.File] = true
+			}
+		}
 		for _, file = range fileList {
 			info = f.fontFiles[file]
-			if info.fontType != "UTF8" {
+			if info.fontType != "UTF8" && embed[file] {
 				f.newobj()
 				info.n = f.n
 				f.fontFiles[file] = info
@@ -625,7 +810,7 @@ func (f *Fpdf) putfonts() {
 					var err error
 					font, err = f.loadFontFile(file)
 					if err != nil {
-						f.err = err
+						f.setError("Close", err)
 						return
 					}
 				}
@@ -646,6 +831,9 @@ func (f *Fpdf) putfonts() {
 				f.out(">>")
 				f.putstream(font)
 				f.out("endobj")
+				if f.stats != nil {
+					f.stats.Fonts++
+				}
 			}
 		}
 	}
@@ -661,11 +849,12 @@ func (f *Fpdf) putfonts() {
 		}
 		for _, key = range keyList {
 			font = f.fonts[key]
+			embedding := f.fontEmbeddingOf(key)
 			// Font objects
 			font.N = f.n + 1
 			f.fonts[key] = font
 			tp := font.Tp
-			name := font.Name
+			name := escapeName(font.Name)
 			switch tp {
 			case "Core":
 				// Core font
@@ -719,24 +908,47 @@ func (f *Fpdf) putfonts() {
 				s.printf("/ItalicAngle %d ", font.Desc.ItalicAngle)
 				s.printf("/StemV %d ", font.Desc.StemV)
 				s.printf("/MissingWidth %d ", font.Desc.MissingWidth)
-				var suffix string
-				if tp != "Type1" {
-					suffix = "2"
+				if embedding != FontEmbedNone {
+					var suffix string
+					if tp != "Type1" {
+						suffix = "2"
+					}
+					s.printf("/FontFile%s %d 0 R", suffix, f.fontFiles[font.File].n)
 				}
-				s.printf("/FontFile%s %d 0 R>>", suffix, f.fontFiles[font.File].n)
+				s.WriteString(">>")
 				f.out(s.String())
 				f.out("endobj")
 			case "UTF8":
-				fontName := "utf8" + font.Name
+				fontName := escapeName("utf8" + font.Name)
 				usedRunes := font.usedRunes
 				delete(usedRunes, 0)
+				start := nanotime()
 				utf8FontStream := font.utf8File.GenerateCutFont(usedRunes)
+				if f.stats != nil {
+					f.stats.SubsetTime += nanotime() - start
+					f.stats.Fonts++
+				}
+				if embedding == FontEmbedFull {
+					// Subsetting also maps the characters to the glyphs of the font
+					utf8FontStream = font.utf8File.fileReader.array
+				}
 				utf8FontSize := len(utf8FontStream)
 				CodeSignDictionary := font.utf8File.CodeSymbolDictionary
 				delete(CodeSignDictionary, 0)
+				encoding := "/Identity-H"
+				if embedding == FontEmbedNone {
+					// The viewer looks the font up by its PostScript name, and
+					// its glyphs by their number in the installed font, to
+					// which the glyph CMap, written after the descriptor,
+					// maps the codes of the text.
+					if name := font.utf8File.postScriptName; name != "" {
+						fontName = escapeName(name)
+					}
+					encoding = Convert(f.n+6).String() + " 0 R"
+				}
 
 				f.newobj()
-				f.out(Sprintf("<</Type /Font\n/Subtype /Type0\n/BaseFont /%s\n/Encoding /Identity-H\n/DescendantFonts [%d 0 R]\n/ToUnicode %d 0 R>>\nendobj", fontName, f.n+1, f.n+2))
+				f.out(Sprintf("<</Type /Font\n/Subtype /Type0\n/BaseFont /%s\n/Encoding %s\n/DescendantFonts [%d 0 R]\n/ToUnicode %d 0 R>>\nendobj", fontName, encoding, f.n+1, f.n+2))
 
 				f.newobj()
 				f.out("<</Type /Font\n/Subtype /CIDFontType2\n/BaseFont /" + fontName + "\n" +
@@ -744,18 +956,28 @@ func (f *Fpdf) putfonts() {
 				if font.Desc.MissingWidth != 0 {
 					f.out("/DW " + Convert(font.Desc.MissingWidth).String())
 				}
-				f.generateCIDFontMap(&font, font.utf8File.LastRune)
-				f.out("/CIDToGIDMap " + Convert(f.n+4).String() + " 0 R>>")
+				if embedding == FontEmbedNone {
+					f.out(font.glyphWidths())
+					f.out(">>")
+				} else {
+					f.generateCIDFontMap(&font, font.utf8File.LastRune)
+					f.out("/CIDToGIDMap " + Convert(f.n+4).String() + " 0 R>>")
+				}
 				f.out("endobj")
 
+				cmap := font.toUnicodeCMap()
 				f.newobj()
-				f.out("<</Length " + Convert(len(toUnicode)).String() + ">>")
-				f.putstream([]byte(toUnicode))
+				f.out("<</Length " + Convert(len(cmap)).String() + ">>")
+				f.putstream([]byte(cmap))
 				f.out("endobj")
 
 				// CIDInfo
 				f.newobj()
-				f.out("<</Registry (Adobe)\n/Ordering (UCS)\n/Supplement 0>>")
+				if embedding == FontEmbedNone {
+					f.out(glyphCIDSystemInfo)
+				} else {
+					f.out("<</Registry (Adobe)\n/Ordering (UCS)\n/Supplement 0>>")
+				}
 				f.out("endobj")
 
 				// Font descriptor
@@ -765,28 +987,48 @@ func (f *Fpdf) putfonts() {
 				s.printf(" /Descent %d", font.Desc.Descent)
 				s.printf(" /CapHeight %d", font.Desc.CapHeight)
 				v := font.Desc.Flags
-				v = v | 4
-				v = v &^ 32
+				if embedding == FontEmbedNone {
+					// Substituted by a font of the standard Latin character set
+					v = v&^4 | 32
+				} else {
+					v = v | 4
+					v = v &^ 32
+				}
 				s.printf(" /Flags %d", v)
 				s.printf("/FontBBox [%d %d %d %d] ", font.Desc.FontBBox.Xmin, font.Desc.FontBBox.Ymin,
 					font.Desc.FontBBox.Xmax, font.Desc.FontBBox.Ymax)
 				s.printf(" /ItalicAngle %d", font.Desc.ItalicAngle)
 				s.printf(" /StemV %d", font.Desc.StemV)
 				s.printf(" /MissingWidth %d", font.Desc.MissingWidth)
-				s.printf("/FontFile2 %d 0 R", f.n+2)
+				if embedding != FontEmbedNone {
+					s.printf("/FontFile2 %d 0 R", f.n+2)
+				}
 				s.printf(">>")
 				f.out(s.String())
 				f.out("endobj")
+				if embedding == FontEmbedNone {
+					cmap := font.glyphCMap()
+					f.newobj()
+					f.out("<</Type /CMap /CMapName /" + glyphCMapName + " /CIDSystemInfo " + glyphCIDSystemInfo +
+						" /Length " + Convert(len(cmap)).String() + ">>")
+					f.putstream([]byte(cmap))
+					f.out("endobj")
+					break
+				}
 
 				// Embed CIDToGIDMap
 				cidToGidMap := make([]byte, 256*256*2)
 
 				for cc, glyph := range CodeSignDictionary {
+					if embedding == FontEmbedFull {
+						// The glyphs keep their numbers in the whole font
+						glyph = font.utf8File.charSymbolDictionary[cc]
+					}
 					cidToGidMap[cc*2] = byte(glyph >> 8)
 					cidToGidMap[cc*2+1] = byte(glyph & 0xFF)
 				}
 
-				mem := xmem.compress(cidToGidMap)
+				mem := f.compressStream(cidToGidMap)
 				cidToGidMap = mem.bytes()
 				f.newobj()
 				f.out("<</Length " + Convert(len(cidToGidMap)).String() + "/Filter /FlateDecode>>")
@@ -795,7 +1037,7 @@ func (f *Fpdf) putfonts() {
 				mem.release()
 
 				//Font file
-				mem = xmem.compress(utf8FontStream)
+				mem = f.compressStream(utf8FontStream)
 				compressedFontStream := mem.bytes()
 				f.newobj()
 				f.out("<</Length " + Convert(len(compressedFontStream)).String())
@@ -805,8 +1047,10 @@ func (f *Fpdf) putfonts() {
 				f.putstream(compressedFontStream)
 				f.out("endobj")
 				mem.release()
+			case "Type3":
+				f.putType3Font(font)
 			default:
-				f.err = Errf("unsupported font type: %s", tp)
+				f.errorf("Close", "unsupported font type: %s", tp)
 				return
 			}
 		}
@@ -939,17 +1183,17 @@ func (f *Fpdf) loadfont(r io.Reader) (def fontDefType) {
 	var buf bytes.Buffer
 	_, err := buf.ReadFrom(r)
 	if err != nil {
-		f.err = err
+		f.setError("AddFontFromReader", err)
 		return
 	}
 	err = unmarshalFontDef(buf.Bytes(), &def)
 	if err != nil {
-		f.err = err
+		f.setError("AddFontFromReader", err)
 		return
 	}
 
 	if def.i, err = generateFontID(def); err != nil {
-		f.err = err
+		f.setError("AddFontFromReader", err)
 	}
 	// dump(def)
 	return
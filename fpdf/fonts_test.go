@@ -1,6 +1,19 @@
 package fpdf_test
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
 
 // Test_SetUnderlineThickness demonstrates how to adjust the text
 // underline thickness.
@@ -20,7 +33,251 @@ func Test_SetUnderlineThickness(t *testing.T) {
 
 	fileStr := Filename("Test_UnderlineThickness")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_UnderlineThickness.pdf
 }
+
+func TestStringWidthStyled(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Arial", "", 12)
+
+	pdf.SetFont("Arial", "B", 20)
+	want := pdf.GetStringWidth("Heading")
+	pdf.SetFont("Arial", "", 12)
+
+	if got := pdf.StringWidthStyled("Heading", "Arial", "B", 20); !floatEqual(got, want) {
+		t.Errorf("invalid width: got=%v, want=%v", got, want)
+	}
+	if got, want := pdf.GetFontStyle(), ""; got != want {
+		t.Errorf("current font style changed: got=%q, want=%q", got, want)
+	}
+	if got, _ := pdf.GetFontSize(); !floatEqual(got, 12) {
+		t.Errorf("current font size changed: got=%v", got)
+	}
+	if got := pdf.StringWidthStyled("Code", "Courier", "", 10); math.Abs(got-4*0.6*10/pdf.GetConversionRatio()) > 1e-9 {
+		t.Errorf("invalid monospaced width: got=%v", got)
+	}
+}
+
+func TestMakeFontBytes(t *testing.T) {
+	dir := t.TempDir()
+	err := fpdf.MakeFont(FontFile("calligra.ttf"), FontFile("cp1252.map"), dir, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := os.ReadFile(filepath.Join(dir, "calligra.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantZ, err := os.ReadFile(filepath.Join(dir, "calligra.z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ttf, err := os.ReadFile(FontFile("calligra.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotJSON, gotZ, err := fpdf.MakeFontBytes(ttf, nil, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotZ, wantZ) {
+		t.Errorf("compressed font differs from the .z file")
+	}
+	var got, want map[string]any
+	if err := json.Unmarshal(gotJSON, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(wantJSON, &want); err != nil {
+		t.Fatal(err)
+	}
+	// Only the name of the font file differs, being unknown in memory.
+	if got["File"] != "CalligrapherRegular.z" {
+		t.Errorf("unexpected file name: %v", got["File"])
+	}
+	delete(got, "File")
+	delete(want, "File")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("definition differs:\ngot  %s\nwant %s", gotJSON, wantJSON)
+	}
+
+	if _, _, err := fpdf.MakeFontBytes([]byte("not a font"), nil, nil, true); err == nil {
+		t.Errorf("no error for an invalid font")
+	}
+}
+
+func TestAddFontTrueType(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddFont("calligra", "", "calligra.ttf")
+	pdf.AddPage()
+	pdf.SetFont("calligra", "", 16)
+	pdf.Cell(40, 10, "Hello, world")
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"/Subtype /TrueType", "/BaseFont /CalligrapherRegular", "/FontFile2", "(Hello, world)"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%q missing from the document", s)
+		}
+	}
+}
+
+func TestSetFontEmbedding(t *testing.T) {
+	ttf, err := os.ReadFile(FontFile("DejaVuSansCondensed.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := func(policy fpdf.FontEmbedding) (string, *fpdf.PDFReader) {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(false)
+		pdf.AddUTF8Font("DejaVu", "", FontFile("DejaVuSansCondensed.ttf"))
+		pdf.AddUTF8Font("DejaVu", "B", FontFile("DejaVuSansCondensed-Bold.ttf"))
+		pdf.AddFont("calligra", "", "calligra.ttf")
+		pdf.SetFontEmbedding("dejavu", policy)
+		pdf.SetFontEmbedding("calligra", policy)
+		pdf.AddPage()
+		pdf.SetFont("DejaVu", "", 12)
+		pdf.Cell(40, 10, "Привет")
+		pdf.SetFont("DejaVu", "B", 12)
+		pdf.Cell(40, 10, "мир")
+		pdf.SetFont("calligra", "", 12)
+		pdf.Cell(40, 10, "Hello")
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		r, err := fpdf.ReadPDF(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.String(), r
+	}
+	for _, tc := range []struct {
+		policy    fpdf.FontEmbedding
+		fontFiles int
+		glyphMaps int
+		flags     string
+	}{
+		{fpdf.FontEmbedSubset, 3, 2, "/Flags 4"},
+		{fpdf.FontEmbedFull, 3, 2, "/Flags 4"},
+		{fpdf.FontEmbedNone, 0, 0, "/Flags 32"},
+	} {
+		out, r := output(tc.policy)
+		if n := strings.Count(out, "/FontFile2"); n != tc.fontFiles {
+			t.Errorf("policy %d: got %d font files, want %d", tc.policy, n, tc.fontFiles)
+		}
+		if n := strings.Count(out, "/CIDToGIDMap"); n != tc.glyphMaps {
+			t.Errorf("policy %d: got %d glyph maps, want %d", tc.policy, n, tc.glyphMaps)
+		}
+		if !strings.Contains(out, tc.flags) {
+			t.Errorf("policy %d: %q missing from the document", tc.policy, tc.flags)
+		}
+		full := strings.Contains(out, "/Length1 "+strconv.Itoa(len(ttf))+"\n")
+		if full != (tc.policy == fpdf.FontEmbedFull) {
+			t.Errorf("policy %d: whole font file embedded: %t", tc.policy, full)
+		}
+		if text, err := r.PageText(1); err != nil || text != "Привет мир Hello" {
+			t.Errorf("policy %d: got text %q, %v", tc.policy, text, err)
+		}
+	}
+
+	pdf := NewDocPdfTest()
+	pdf.SetFontEmbedding("dejavu", fpdf.FontEmbedding(7))
+	if !pdf.Err() {
+		t.Error("no error for an invalid policy")
+	}
+}
+
+// The text of a UTF-8 font that is not embedded leads to the glyphs of the
+// installed font, which the viewer finds by its PostScript name.
+func TestFontEmbedNoneGlyphs(t *testing.T) {
+	ttf, err := os.ReadFile(FontFile("DejaVuSansCondensed.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := fpdf.TtfParseBytes(ttf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const text = "Привет AV"
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("DejaVu", "", FontFile("DejaVuSansCondensed.ttf"))
+	pdf.SetFontEmbedding("dejavu", fpdf.FontEmbedNone)
+	pdf.AddPage()
+	pdf.SetFont("DejaVu", "", 12)
+	pdf.Cell(40, 10, text)
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "/BaseFont /"+rec.PostScriptName+"\n"); n != 2 {
+		t.Errorf("got %d fonts named %s, want 2", n, rec.PostScriptName)
+	}
+	if strings.Contains(out, "/Identity-H") {
+		t.Error("codes of the text taken as glyph numbers")
+	}
+	widths := " " + out[strings.Index(out, "/W [")+4:]
+	widths = widths[:strings.Index(widths, "]\n")]
+	glyphs := make(map[rune]int)
+	for _, m := range regexp.MustCompile(`<([0-9A-F]{4})> (\d+)\n`).FindAllStringSubmatch(out, -1) {
+		code, _ := strconv.ParseUint(m[1], 16, 32)
+		glyphs[rune(code)], _ = strconv.Atoi(m[2])
+	}
+	for _, r := range text {
+		want := int(rec.Chars[uint16(r)])
+		if got, ok := glyphs[r]; !ok || got != want || want == 0 {
+			t.Errorf("%q: got glyph %d, want %d of the font file", r, got, want)
+		}
+		if !strings.Contains(widths, " "+strconv.Itoa(want)+" [") {
+			t.Errorf("%q: no width for glyph %d", r, want)
+		}
+	}
+	r, err := fpdf.ReadPDF(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.PageText(1); err != nil || got != text {
+		t.Errorf("got text %q, %v", got, err)
+	}
+}
+
+func TestHasGlyph(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.SetFont("dejavu", "", 12)
+	for _, c := range []struct {
+		family string
+		r      rune
+		want   bool
+	}{
+		{"", 'A', true},
+		{"", 'Ж', true},
+		{"", '😀', true},
+		{"", '🦀', false},
+		{"dejavu", 'é', true},
+		{"helvetica", 'A', true},
+		{"helvetica", 'Ж', false},
+		{"nosuchfont", 'A', false},
+	} {
+		if got := pdf.HasGlyph(c.family, "", c.r); got != c.want {
+			t.Errorf("%q %q: got %v, want %v", c.family, c.r, got, c.want)
+		}
+	}
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+	got := pdf.MissingGlyphs("", "", "Hi 🦀\nhé 日本 🦀")
+	if want := []rune{'🦀', '日', '本'}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingGlyphs: got %q, want %q", got, want)
+	}
+}
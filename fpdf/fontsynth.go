@@ -0,0 +1,94 @@
+package fpdf
+
+import . "github.com/tinywasm/fmt"
+
+// fauxObliqueSkew is the tangent of the 12° slant given to synthesized
+// oblique text.
+const fauxObliqueSkew = 0.21256
+
+// SetFontStyleSynthesis controls whether SetFont() synthesizes the bold and
+// italic styles of a font family when they have not been added, instead of
+// setting an error. Synthesized bold text is drawn with its outline stroked
+// in the text color as well as filled, and synthesized italic text is slanted
+// by 12 degrees. A family whose bold and italic style is missing uses its
+// bold or italic style, if one exists, with the other one synthesized.
+//
+// Synthesis only applies to fonts added to the document, such as those of
+// AddUTF8Font(); the core fonts have all their styles. It does not change the
+// width of text. GetFontStyle() returns the style that was set, synthesized
+// or not. The default is false.
+func (f *Fpdf) SetFontStyleSynthesis(on bool) {
+	f.fontSynthesis = on
+}
+
+// synthesizedStyle returns the style of family familyStr to select for style
+// styleStr and the part of styleStr to synthesize, if synthesis is enabled
+// and styleStr is not available.
+func (f *Fpdf) synthesizedStyle(familyStr, styleStr string) (style, synth string) {
+	if !f.fontSynthesis || styleStr == "" {
+		return styleStr, ""
+	}
+	if _, ok := f.fonts[familyStr+styleStr]; ok || f.coreFonts[familyStr] || familyStr == "arial" {
+		return styleStr, ""
+	}
+	if f.loadFontFS(familyStr, styleStr) {
+		return styleStr, ""
+	}
+	var bases []string
+	switch styleStr {
+	case "BI":
+		bases = []string{"B", "I", ""}
+	default:
+		bases = []string{""}
+	}
+	for _, base := range bases {
+		if _, ok := f.fonts[familyStr+base]; ok || f.loadFontFS(familyStr, base) {
+			return base, Convert(styleStr).Replace(base, "", 1).String()
+		}
+	}
+	return styleStr, ""
+}
+
+// textBegin returns the operators that begin a text object with ops and
+// position the text at (x, y) in points, synthesizing the style of the
+// current font if needed. The text object is ended by textEnd().
+func (f *Fpdf) textBegin(x, y float64, ops string) string {
+	if f.fontSynth == "" {
+		b := appendFloats(append([]byte("BT "), ops...), f.prec.Text, x, y)
+		return string(append(b, "Td"...))
+	}
+	var s fmtBuffer
+	if Contains(f.fontSynth, "B") {
+		s.printf("q %s %.2f w BT 2 Tr ", strokeColorStr(f.color.text.str), f.fontSizePt*0.03)
+	} else {
+		s.printf("BT ")
+	}
+	skew := 0.0
+	if Contains(f.fontSynth, "I") {
+		skew = fauxObliqueSkew
+	}
+	s.printf("%s1 0 %s 1 ", ops, f.fmtF64(skew, f.prec.Matrix))
+	s.Write(appendFloats(nil, f.prec.Text, x, y))
+	s.WriteString("Tm")
+	return s.String()
+}
+
+// textEnd returns the operators that end a text object begun by textBegin().
+func (f *Fpdf) textEnd() string {
+	if Contains(f.fontSynth, "B") {
+		return "ET Q"
+	}
+	return "ET"
+}
+
+// strokeColorStr returns the operators that set the stroke color to the
+// fill color set by clrStr.
+func strokeColorStr(clrStr string) string {
+	fields := Convert(clrStr).Split(" ")
+	for j, s := range fields {
+		if s != "" && s[0] >= 'a' && s[0] <= 'z' {
+			fields[j] = Convert(s).ToUpper().String()
+		}
+	}
+	return Convert(fields).Join(" ").String()
+}
@@ -0,0 +1,57 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetFontStyleSynthesis(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.AddPage()
+	pdf.SetFont("dejavu", "B", 12)
+	if !pdf.Err() {
+		t.Fatalf("expected error for a missing style without synthesis")
+	}
+
+	pdf = NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFontStyleSynthesis(true)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.SetTextColor(255, 0, 0)
+	pdf.SetFont("dejavu", "B", 12)
+	if style := pdf.GetFontStyle(); style != "B" {
+		t.Errorf("unexpected style: %s", style)
+	}
+	pdf.AddPage()
+	pdf.Text(50, 100, "bold")
+	// The synthesized style carries over to the next page.
+	pdf.AddPage()
+	pdf.Text(50, 100, "bold")
+	pdf.SetFont("", "I", 0)
+	pdf.CellFormat(100, 20, "italic", "", 1, "", false, 0, "")
+	pdf.SetFont("", "", 0)
+	pdf.Text(50, 200, "regular")
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "q 1.000 0.000 0.000 RG 0.36 w BT 2 Tr 1 0 0.00000 1 50.00 741.89 Tm"); n != 2 {
+		t.Errorf("synthesized bold written %d times, expected twice", n)
+	}
+	if !strings.Contains(out, "BT 1 0 0.21256 1 ") {
+		t.Errorf("output does not contain synthesized italic")
+	}
+	if !strings.Contains(out, "BT 50.00 641.89 Td") {
+		t.Errorf("output does not contain regular text")
+	}
+}
@@ -0,0 +1,952 @@
+package fpdf
+
+import (
+	"encoding/binary"
+	"math"
+	"path"
+	"path/filepath"
+	"unicode/utf16"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// FontVariation selects an instance of a variable font, for
+// AddUTF8FontVariation() and InstantiateFont(). The zero value selects the
+// default instance.
+type FontVariation struct {
+	// Instance is the name of a named instance of the font, such as "Medium"
+	// or "Bold Condensed", as returned by FontVariations().
+	Instance string
+	// Axes gives the values of variation axes by tag, such as
+	// {"wght": 500, "wdth": 75}, overriding those of Instance. Values are
+	// clamped to the range of their axis.
+	Axes map[string]float64
+}
+
+// FontAxis describes a variation axis of a variable font.
+type FontAxis struct {
+	Tag               string // tag of the axis, such as "wght" or "wdth"
+	Min, Default, Max float64
+}
+
+// fontInstance is a named instance of a variable font.
+type fontInstance struct {
+	name   string
+	coords []float64
+}
+
+// FontVariations returns the variation axes and the names of the named
+// instances of the TrueType font data. A font that is not variable has no
+// axes.
+func FontVariations(data []byte) (axes []FontAxis, instances []string, err error) {
+	tables, err := sfntTables(data)
+	if err != nil {
+		return
+	}
+	var list []fontInstance
+	axes, list, err = parseFvar(tables)
+	for _, inst := range list {
+		instances = append(instances, inst.name)
+	}
+	return
+}
+
+// InstantiateFont returns a static TrueType font with the glyphs and metrics
+// of the instance v of the variable TrueType font data, applying the glyph
+// variations (gvar) and, if present, the advance width variations (HVAR) of
+// the font. The variation tables are dropped and the weight class is set
+// from the wght axis. A font that is not variable is returned unchanged.
+func InstantiateFont(data []byte, v FontVariation) ([]byte, error) {
+	tables, err := sfntTables(data)
+	if err != nil {
+		return nil, err
+	}
+	axes, instances, err := parseFvar(tables)
+	if err != nil || len(axes) == 0 {
+		return data, err
+	}
+	coords := make([]float64, len(axes))
+	for i, a := range axes {
+		coords[i] = a.Default
+	}
+	if v.Instance != "" {
+		found := false
+		for _, inst := range instances {
+			if Convert(inst.name).ToLower().String() == Convert(v.Instance).ToLower().String() {
+				copy(coords, inst.coords)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, Errf("font instance %q not found", v.Instance)
+		}
+	}
+	for tag, value := range v.Axes {
+		found := false
+		for i, a := range axes {
+			if a.Tag == tag {
+				coords[i] = value
+				found = true
+			}
+		}
+		if !found {
+			return nil, Errf("font has no variation axis %q", tag)
+		}
+	}
+	norm := make([]float64, len(axes))
+	for i, a := range axes {
+		norm[i] = normalizeAxis(coords[i], a)
+	}
+	if avar, ok := tables["avar"]; ok {
+		applyAvar(avar, norm)
+	}
+	return instantiateTables(tables, axes, coords, norm)
+}
+
+// AddUTF8FontVariation imports an instance of a variable TrueType font with
+// utf-8 symbols, such as the Medium or Bold instance of a font file that
+// provides all weights, and makes it available like AddUTF8Font() does. The
+// instance is selected by v, see FontVariation; a static font is generated
+// for it with InstantiateFont(), from which the glyphs used in the document
+// are embedded.
+//
+// See AddUTF8Font() for details about familyStr, styleStr and fileStr. Each
+// instance is added with its own family or style, for example:
+//
+//	pdf.AddUTF8FontVariation("inter", "", "Inter.ttf", fpdf.FontVariation{Instance: "Regular"})
+//	pdf.AddUTF8FontVariation("inter", "B", "Inter.ttf", fpdf.FontVariation{Axes: map[string]float64{"wght": 700}})
+func (f *Fpdf) AddUTF8FontVariation(familyStr, styleStr, fileStr string, v FontVariation) {
+	if f.err != nil {
+		return
+	}
+	if !filepath.IsAbs(fileStr) {
+		fileStr = path.Join(f.fontsPath, fileStr)
+	}
+	data, err := f.readFile(fileStr)
+	if err != nil {
+		f.setError("AddUTF8FontVariation", err)
+		return
+	}
+	f.AddUTF8FontVariationFromBytes(familyStr, styleStr, data, v)
+}
+
+// AddUTF8FontVariationFromBytes is like AddUTF8FontVariation() but reads the
+// variable font from utf8Bytes.
+func (f *Fpdf) AddUTF8FontVariationFromBytes(familyStr, styleStr string, utf8Bytes []byte, v FontVariation) {
+	if f.err != nil {
+		return
+	}
+	if _, ok := f.fonts[getFontKey(familyStr, styleStr)]; ok {
+		return
+	}
+	data, err := InstantiateFont(utf8Bytes, v)
+	if err != nil {
+		f.setError("AddUTF8FontVariationFromBytes", err)
+		return
+	}
+	f.addFontFromBytes("AddUTF8FontVariationFromBytes", familyStr, styleStr, nil, nil, data)
+}
+
+// sfntReader reads big-endian values from font data, recording an error
+// instead of panicking when it reads beyond the end.
+type sfntReader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *sfntReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.b) {
+		r.err = Errf("font table truncated")
+		return make([]byte, max(n, 0))
+	}
+	b := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *sfntReader) u8() int  { return int(r.bytes(1)[0]) }
+func (r *sfntReader) i8() int  { return int(int8(r.bytes(1)[0])) }
+func (r *sfntReader) u16() int { return int(binary.BigEndian.Uint16(r.bytes(2))) }
+func (r *sfntReader) i16() int { return int(int16(binary.BigEndian.Uint16(r.bytes(2)))) }
+func (r *sfntReader) u32() int { return int(binary.BigEndian.Uint32(r.bytes(4))) }
+func (r *sfntReader) i32() int { return int(int32(binary.BigEndian.Uint32(r.bytes(4)))) }
+
+// fixed reads a 16.16 fixed-point number.
+func (r *sfntReader) fixed() float64 { return float64(r.i32()) / 65536 }
+
+// f2dot14 reads a 2.14 fixed-point number.
+func (r *sfntReader) f2dot14() float64 { return float64(r.i16()) / 16384 }
+
+// sfntTables returns the tables of the TrueType font data by tag.
+func sfntTables(data []byte) (map[string][]byte, error) {
+	r := &sfntReader{b: data}
+	switch version := r.u32(); version {
+	case 0x00010000, 0x74727565: // TrueType, "true"
+	case 0x4F54544F: // "OTTO"
+		return nil, Errf("fonts based on PostScript outlines are not supported")
+	default:
+		if r.err == nil {
+			return nil, Errf("not a TrueType font")
+		}
+	}
+	numTables := r.u16()
+	r.bytes(6)
+	tables := make(map[string][]byte, numTables)
+	for j := 0; j < numTables; j++ {
+		tag := string(r.bytes(4))
+		r.u32() // checksum
+		offset, length := r.u32(), r.u32()
+		if offset+length > len(data) {
+			return nil, Errf("font table %s truncated", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	return tables, r.err
+}
+
+// parseFvar returns the axes and named instances of the font variations
+// table, if any.
+func parseFvar(tables map[string][]byte) (axes []FontAxis, instances []fontInstance, err error) {
+	fvar, ok := tables["fvar"]
+	if !ok {
+		return
+	}
+	r := &sfntReader{b: fvar}
+	r.bytes(4) // version
+	axesOffset := r.u16()
+	r.u16()
+	axisCount, axisSize := r.u16(), r.u16()
+	instanceCount, instanceSize := r.u16(), r.u16()
+	for j := 0; j < axisCount; j++ {
+		r.pos = axesOffset + j*axisSize
+		axes = append(axes, FontAxis{Tag: string(r.bytes(4)), Min: r.fixed(), Default: r.fixed(), Max: r.fixed()})
+	}
+	for j := 0; j < instanceCount; j++ {
+		r.pos = axesOffset + axisCount*axisSize + j*instanceSize
+		inst := fontInstance{name: sfntName(tables["name"], r.u16())}
+		r.u16() // flags
+		for range axes {
+			inst.coords = append(inst.coords, r.fixed())
+		}
+		instances = append(instances, inst)
+	}
+	return axes, instances, r.err
+}
+
+// sfntName returns the string nameID of the naming table, preferring its
+// Windows Unicode version.
+func sfntName(name []byte, nameID int) (s string) {
+	r := &sfntReader{b: name}
+	r.u16() // format
+	count, storage := r.u16(), r.u16()
+	for j := 0; j < count && r.err == nil; j++ {
+		platform, encoding := r.u16(), r.u16()
+		r.u16() // language
+		id, length, offset := r.u16(), r.u16(), r.u16()
+		if id != nameID || storage+offset+length > len(name) {
+			continue
+		}
+		str := name[storage+offset : storage+offset+length]
+		switch {
+		case platform == 3 || platform == 0:
+			units := make([]uint16, len(str)/2)
+			for k := range units {
+				units[k] = binary.BigEndian.Uint16(str[2*k:])
+			}
+			return string(utf16.Decode(units))
+		case platform == 1 && encoding == 0 && s == "":
+			s = string(str)
+		}
+	}
+	return s
+}
+
+// normalizeAxis returns the value v of axis a in normalized coordinates,
+// from -1 at the minimum of the axis to 0 at its default and 1 at its
+// maximum.
+func normalizeAxis(v float64, a FontAxis) float64 {
+	v = math.Max(a.Min, math.Min(a.Max, v))
+	switch {
+	case v < a.Default && a.Default > a.Min:
+		return (v - a.Default) / (a.Default - a.Min)
+	case v > a.Default && a.Max > a.Default:
+		return (v - a.Default) / (a.Max - a.Default)
+	}
+	return 0
+}
+
+// applyAvar maps the normalized coordinates norm with the segment maps of
+// the axis variations table avar.
+func applyAvar(avar []byte, norm []float64) {
+	r := &sfntReader{b: avar}
+	r.bytes(6) // version, reserved
+	axisCount := r.u16()
+	for i := 0; i < axisCount && i < len(norm) && r.err == nil; i++ {
+		n := r.u16()
+		from, to := make([]float64, n), make([]float64, n)
+		for j := 0; j < n; j++ {
+			from[j], to[j] = r.f2dot14(), r.f2dot14()
+		}
+		if r.err != nil {
+			return
+		}
+		for j := 1; j < n; j++ {
+			if norm[i] <= from[j] {
+				if from[j] > from[j-1] {
+					norm[i] = to[j-1] + (norm[i]-from[j-1])*(to[j]-to[j-1])/(from[j]-from[j-1])
+				} else {
+					norm[i] = to[j]
+				}
+				break
+			}
+		}
+	}
+}
+
+// tupleScalar returns the scalar by which the deltas of a tuple variation
+// with the peak coordinates peak, and the intermediate region from start to
+// end if they are not nil, apply at the normalized coordinates norm.
+func tupleScalar(norm, peak, start, end []float64) float64 {
+	scalar := 1.0
+	for i, p := range peak {
+		if p == 0 || i >= len(norm) {
+			continue
+		}
+		v := norm[i]
+		s, e := math.Min(p, 0), math.Max(p, 0)
+		if start != nil {
+			s, e = start[i], end[i]
+			if s > p || p > e || (s < 0 && e > 0) {
+				continue
+			}
+		}
+		switch {
+		case v == p:
+		case v <= s || v >= e:
+			return 0
+		case v < p:
+			scalar *= (v - s) / (p - s)
+		default:
+			scalar *= (e - v) / (e - p)
+		}
+	}
+	return scalar
+}
+
+// sfntGlyph is a glyph outline decoded from the glyf table.
+type sfntGlyph struct {
+	contours     int    // number of contours, negative for a composite glyph
+	bbox         [4]int // xMin, yMin, xMax, yMax
+	endPts       []int  // last point of each contour
+	instructions []byte // hinting instructions of a simple glyph
+	flags        []byte // flags of each point
+	x, y         []int  // coordinates of each point
+	data         []byte // original data
+	components   []int  // offsets of the arguments of each component in data
+}
+
+// points returns the number of points of g that take deltas, not counting
+// the phantom points: its outline points or its components.
+func (g *sfntGlyph) points() int {
+	if g.contours < 0 {
+		return len(g.components)
+	}
+	return len(g.x)
+}
+
+// parseGlyph decodes the glyph data of the glyf table.
+func parseGlyph(data []byte) (g sfntGlyph, err error) {
+	g.data = data
+	if len(data) == 0 {
+		return
+	}
+	r := &sfntReader{b: data}
+	g.contours = r.i16()
+	g.bbox = [4]int{r.i16(), r.i16(), r.i16(), r.i16()}
+	if g.contours < 0 {
+		for more := true; more && r.err == nil; {
+			flags := r.u16()
+			r.u16() // glyph index
+			g.components = append(g.components, r.pos)
+			if flags&symbolWords != 0 {
+				r.bytes(4)
+			} else {
+				r.bytes(2)
+			}
+			switch {
+			case flags&symbolScale != 0:
+				r.bytes(2)
+			case flags&symbolAllScale != 0:
+				r.bytes(4)
+			case flags&symbol2x2 != 0:
+				r.bytes(8)
+			}
+			more = flags&symbolContinue != 0
+		}
+		return g, r.err
+	}
+	for j := 0; j < g.contours; j++ {
+		g.endPts = append(g.endPts, r.u16())
+	}
+	n := 0
+	if g.contours > 0 {
+		n = g.endPts[g.contours-1] + 1
+	}
+	g.instructions = r.bytes(r.u16())
+	for len(g.flags) < n && r.err == nil {
+		flag := byte(r.u8())
+		g.flags = append(g.flags, flag)
+		if flag&0x08 != 0 { // repeat
+			for k := r.u8(); k > 0; k-- {
+				g.flags = append(g.flags, flag)
+			}
+		}
+	}
+	g.flags = g.flags[:min(n, len(g.flags))]
+	g.x = glyphCoords(r, g.flags, 0x02, 0x10)
+	g.y = glyphCoords(r, g.flags, 0x04, 0x20)
+	return g, r.err
+}
+
+// glyphCoords reads the coordinates of a simple glyph along one axis, whose
+// flags tell if they are short and, for short ones, positive, or, for long
+// ones, the same as the previous coordinate.
+func glyphCoords(r *sfntReader, flags []byte, short, same byte) []int {
+	coords := make([]int, len(flags))
+	v := 0
+	for j, flag := range flags {
+		switch {
+		case flag&short != 0 && flag&same != 0:
+			v += r.u8()
+		case flag&short != 0:
+			v -= r.u8()
+		case flag&same == 0:
+			v += r.i16()
+		}
+		coords[j] = v
+	}
+	return coords
+}
+
+// encode returns the glyph data of g, with the coordinates of a simple glyph
+// or the offsets dx, dy of the components of a composite glyph updated.
+func (g *sfntGlyph) encode(dx, dy []int) []byte {
+	var b []byte
+	put16 := func(v int) { b = append(b, byte(v>>8), byte(v)) }
+	if g.contours < 0 {
+		b = append(b, g.data[:10]...)
+		pos := 10
+		for j, argPos := range g.components {
+			flags := int(binary.BigEndian.Uint16(g.data[pos:]))
+			r := &sfntReader{b: g.data, pos: argPos}
+			var a1, a2 int
+			if flags&symbolWords != 0 {
+				a1, a2 = r.i16(), r.i16()
+			} else if flags&0x0002 != 0 { // ARGS_ARE_XY_VALUES
+				a1, a2 = r.i8(), r.i8()
+			} else {
+				a1, a2 = r.u8(), r.u8()
+			}
+			if flags&0x0002 != 0 {
+				a1, a2 = a1+dx[j], a2+dy[j]
+			}
+			put16(flags | symbolWords)
+			b = append(b, g.data[pos+2:pos+4]...) // glyph index
+			put16(a1)
+			put16(a2)
+			end := len(g.data)
+			if j+1 < len(g.components) {
+				end = g.components[j+1] - 4
+			} else {
+				end = r.pos
+				switch {
+				case flags&symbolScale != 0:
+					end += 2
+				case flags&symbolAllScale != 0:
+					end += 4
+				case flags&symbol2x2 != 0:
+					end += 8
+				}
+			}
+			b = append(b, g.data[r.pos:end]...) // transformation
+			pos = end
+		}
+		return append(b, g.data[pos:]...) // instructions
+	}
+	x, y := make([]int, len(g.x)), make([]int, len(g.y))
+	for j := range g.x {
+		x[j], y[j] = g.x[j]+dx[j], g.y[j]+dy[j]
+	}
+	put16(g.contours)
+	if len(x) > 0 {
+		g.bbox = [4]int{x[0], y[0], x[0], y[0]}
+		for j := range x {
+			g.bbox = [4]int{min(g.bbox[0], x[j]), min(g.bbox[1], y[j]), max(g.bbox[2], x[j]), max(g.bbox[3], y[j])}
+		}
+	}
+	for _, v := range g.bbox {
+		put16(v)
+	}
+	for _, v := range g.endPts {
+		put16(v)
+	}
+	put16(len(g.instructions))
+	b = append(b, g.instructions...)
+	for j, flag := range g.flags {
+		if j == 0 {
+			flag &= 0x41 // on curve, overlap
+		} else {
+			flag &= 0x01
+		}
+		b = append(b, flag)
+	}
+	for _, coords := range [][]int{x, y} {
+		prev := 0
+		for _, v := range coords {
+			put16(v - prev)
+			prev = v
+		}
+	}
+	return b
+}
+
+// iup interpolates the deltas of the points of the contours of a simple glyph
+// that a tuple variation does not reference, given as nil in deltas, from
+// those of the neighboring referenced points of the same contour.
+func iup(coords []int, deltas []*float64, endPts []int) []float64 {
+	out := make([]float64, len(coords))
+	start := 0
+	for _, end := range endPts {
+		var refs []int
+		for j := start; j <= end && j < len(coords); j++ {
+			if deltas[j] != nil {
+				refs = append(refs, j)
+				out[j] = *deltas[j]
+			}
+		}
+		if len(refs) == 0 {
+			start = end + 1
+			continue
+		}
+		for k, r1 := range refs {
+			r2 := refs[(k+1)%len(refs)]
+			// the points from r1 to r2, going around the contour
+			for j := r1 + 1; ; j++ {
+				if j > end {
+					j = start
+				}
+				if j == r2 {
+					break
+				}
+				out[j] = iupPoint(coords[j], coords[r1], coords[r2], *deltas[r1], *deltas[r2])
+			}
+		}
+		start = end + 1
+	}
+	return out
+}
+
+// iupPoint returns the delta of a point at c between referenced points at c1
+// and c2 with deltas d1 and d2.
+func iupPoint(c, c1, c2 int, d1, d2 float64) float64 {
+	if c1 > c2 {
+		c1, c2, d1, d2 = c2, c1, d2, d1
+	}
+	switch {
+	case c1 == c2:
+		if d1 == d2 {
+			return d1
+		}
+		return 0
+	case c <= c1:
+		return d1
+	case c >= c2:
+		return d2
+	}
+	return d1 + float64(c-c1)*(d2-d1)/float64(c2-c1)
+}
+
+// packedPoints reads packed point numbers, returning nil for all points.
+func packedPoints(r *sfntReader) []int {
+	count := r.u8()
+	if count&0x80 != 0 {
+		count = (count&0x7f)<<8 | r.u8()
+	}
+	if count == 0 {
+		return nil
+	}
+	points := make([]int, 0, count)
+	p := 0
+	for len(points) < count && r.err == nil {
+		control := r.u8()
+		for k := control&0x7f + 1; k > 0 && len(points) < count; k-- {
+			if control&0x80 != 0 {
+				p += r.u16()
+			} else {
+				p += r.u8()
+			}
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// packedDeltas reads n packed deltas.
+func packedDeltas(r *sfntReader, n int) []int {
+	deltas := make([]int, 0, n)
+	for len(deltas) < n && r.err == nil {
+		control := r.u8()
+		for k := control&0x3f + 1; k > 0 && len(deltas) < n; k-- {
+			switch {
+			case control&0x80 != 0:
+				deltas = append(deltas, 0)
+			case control&0x40 != 0:
+				deltas = append(deltas, r.i16())
+			default:
+				deltas = append(deltas, r.i8())
+			}
+		}
+	}
+	return deltas
+}
+
+// glyphDeltas returns the deltas at the normalized coordinates norm of the
+// points of glyph g and of its four phantom points, from its variation data
+// in the gvar table.
+func glyphDeltas(data []byte, g *sfntGlyph, axisCount int, shared [][]float64, norm []float64) (dx, dy []float64, err error) {
+	n := g.points() + 4
+	dx, dy = make([]float64, n), make([]float64, n)
+	if len(data) == 0 {
+		return
+	}
+	r := &sfntReader{b: data}
+	count := r.u16()
+	dataPos := r.u16()
+	var sharedPoints []int
+	body := &sfntReader{b: data, pos: dataPos}
+	if count&0x8000 != 0 {
+		sharedPoints = packedPoints(body)
+	}
+	tuple := func() []float64 {
+		t := make([]float64, axisCount)
+		for i := range t {
+			t[i] = r.f2dot14()
+		}
+		return t
+	}
+	for j := 0; j < count&0x0fff && r.err == nil; j++ {
+		size, index := r.u16(), r.u16()
+		var peak, start, end []float64
+		if index&0x8000 != 0 {
+			peak = tuple()
+		} else if index&0x0fff < len(shared) {
+			peak = shared[index&0x0fff]
+		} else {
+			return nil, nil, Errf("invalid shared tuple index %d", index&0x0fff)
+		}
+		if index&0x4000 != 0 {
+			start, end = tuple(), tuple()
+		}
+		next := body.pos + size
+		scalar := tupleScalar(norm, peak, start, end)
+		if scalar == 0 {
+			body.pos = next
+			continue
+		}
+		points := sharedPoints
+		if index&0x2000 != 0 {
+			points = packedPoints(body)
+		}
+		all := points == nil
+		if all {
+			points = make([]int, n)
+			for k := range points {
+				points[k] = k
+			}
+		}
+		deltas := packedDeltas(body, 2*len(points))
+		body.pos = next
+		if body.err != nil {
+			return nil, nil, body.err
+		}
+		if all || g.contours <= 0 {
+			for k, p := range points {
+				if p < n {
+					dx[p] += scalar * float64(deltas[k])
+					dy[p] += scalar * float64(deltas[len(points)+k])
+				}
+			}
+			continue
+		}
+		// The points that are not referenced are interpolated.
+		refX, refY := make([]*float64, n), make([]*float64, n)
+		for k, p := range points {
+			if p < n {
+				x, y := float64(deltas[k]), float64(deltas[len(points)+k])
+				refX[p], refY[p] = &x, &y
+			}
+		}
+		ix := iup(g.x, refX[:g.points()], g.endPts)
+		iy := iup(g.y, refY[:g.points()], g.endPts)
+		for p := 0; p < n; p++ {
+			switch {
+			case p < g.points():
+				dx[p] += scalar * ix[p]
+				dy[p] += scalar * iy[p]
+			case refX[p] != nil:
+				dx[p] += scalar * *refX[p]
+				dy[p] += scalar * *refY[p]
+			}
+		}
+	}
+	return dx, dy, r.err
+}
+
+// hvarAdvance returns the delta of the advance width of glyph gid at the
+// normalized coordinates norm from the horizontal metrics variations table
+// hvar.
+func hvarAdvance(hvar []byte, gid int, norm []float64) float64 {
+	r := &sfntReader{b: hvar}
+	r.bytes(4) // version
+	storeOffset, mapOffset := r.u32(), r.u32()
+	outer, inner := 0, gid
+	if mapOffset != 0 {
+		r.pos = mapOffset
+		format, entryFormat := r.u8(), r.u8()
+		count := r.u16()
+		if format == 1 {
+			count = count<<16 | r.u16()
+		}
+		if count == 0 {
+			return 0
+		}
+		size := (entryFormat>>4)&3 + 1
+		r.pos += min(gid, count-1) * size
+		entry := 0
+		for k := 0; k < size; k++ {
+			entry = entry<<8 | r.u8()
+		}
+		innerBits := entryFormat&0x0f + 1
+		outer, inner = entry>>innerBits, entry&(1<<innerBits-1)
+	}
+	// item variation store
+	r.pos = storeOffset
+	r.u16() // format
+	regionsOffset := r.u32()
+	dataCount := r.u16()
+	if outer >= dataCount {
+		return 0
+	}
+	r.pos = storeOffset + 6 + 4*outer
+	dataOffset := r.u32()
+	r.pos = storeOffset + dataOffset
+	itemCount, wordCount, regionCount := r.u16(), r.u16(), r.u16()
+	regionIndexes := make([]int, regionCount)
+	for k := range regionIndexes {
+		regionIndexes[k] = r.u16()
+	}
+	if inner >= itemCount {
+		return 0
+	}
+	long := wordCount&0x8000 != 0
+	wordCount &= 0x7fff
+	rowSize := 2*wordCount + (regionCount - wordCount)
+	if long {
+		rowSize *= 2
+	}
+	r.pos += inner * rowSize
+	deltas := make([]int, regionCount)
+	for k := range deltas {
+		switch {
+		case k < wordCount && long:
+			deltas[k] = r.i32()
+		case k < wordCount || long:
+			deltas[k] = r.i16()
+		default:
+			deltas[k] = r.i8()
+		}
+	}
+	// variation region list
+	r.pos = storeOffset + regionsOffset
+	axisCount := r.u16()
+	r.u16() // region count
+	regions := r.pos
+	delta := 0.0
+	for k, region := range regionIndexes {
+		r.pos = regions + region*axisCount*6
+		start, peak, end := make([]float64, axisCount), make([]float64, axisCount), make([]float64, axisCount)
+		for i := 0; i < axisCount; i++ {
+			start[i], peak[i], end[i] = r.f2dot14(), r.f2dot14(), r.f2dot14()
+		}
+		delta += tupleScalar(norm, peak, start, end) * float64(deltas[k])
+	}
+	if r.err != nil {
+		return 0
+	}
+	return delta
+}
+
+// instantiateTables returns the static font of the variable font tables at
+// the user coordinates coords of axes, normalized as norm.
+func instantiateTables(tables map[string][]byte, axes []FontAxis, coords, norm []float64) ([]byte, error) {
+	head, hhea, maxp, hmtx := tables["head"], tables["hhea"], tables["maxp"], tables["hmtx"]
+	glyf, loca, gvar := tables["glyf"], tables["loca"], tables["gvar"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 || glyf == nil || loca == nil {
+		return nil, Errf("font tables missing")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	longLoca := binary.BigEndian.Uint16(head[50:]) != 0
+	offset := func(gid int) int {
+		if longLoca {
+			return int(binary.BigEndian.Uint32(loca[4*gid:]))
+		}
+		return 2 * int(binary.BigEndian.Uint16(loca[2*gid:]))
+	}
+	if (longLoca && len(loca) < 4*numGlyphs+4) || (!longLoca && len(loca) < 2*numGlyphs+2) ||
+		numMetrics == 0 || len(hmtx) < 4*numMetrics+2*(numGlyphs-numMetrics) {
+		return nil, Errf("font tables truncated")
+	}
+
+	// gvar header
+	var shared [][]float64
+	var gvarData func(gid int) []byte
+	if gvar != nil {
+		r := &sfntReader{b: gvar}
+		r.bytes(4) // version
+		axisCount, sharedCount := r.u16(), r.u16()
+		sharedOffset := r.u32()
+		r.u16() // glyph count
+		flags, dataOffset := r.u16(), r.u32()
+		offsets := make([]int, numGlyphs+1)
+		for gid := range offsets {
+			if flags&1 != 0 {
+				offsets[gid] = r.u32()
+			} else {
+				offsets[gid] = 2 * r.u16()
+			}
+		}
+		r.pos = sharedOffset
+		for j := 0; j < sharedCount; j++ {
+			t := make([]float64, axisCount)
+			for i := range t {
+				t[i] = r.f2dot14()
+			}
+			shared = append(shared, t)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		gvarData = func(gid int) []byte {
+			start, end := dataOffset+offsets[gid], dataOffset+offsets[gid+1]
+			if end <= start || end > len(gvar) {
+				return nil
+			}
+			return gvar[start:end]
+		}
+	}
+
+	var glyfOut, locaOut, hmtxOut []byte
+	advanceMax := 0
+	for gid := 0; gid < numGlyphs; gid++ {
+		start, end := offset(gid), offset(gid+1)
+		if start > end || end > len(glyf) {
+			return nil, Errf("invalid glyph offset for glyph %d", gid)
+		}
+		advance := int(binary.BigEndian.Uint16(hmtx[4*min(gid, numMetrics-1):]))
+		var lsb int
+		if gid < numMetrics {
+			lsb = int(int16(binary.BigEndian.Uint16(hmtx[4*gid+2:])))
+		} else {
+			lsb = int(int16(binary.BigEndian.Uint16(hmtx[4*numMetrics+2*(gid-numMetrics):])))
+		}
+		data := glyf[start:end]
+		g, err := parseGlyph(data)
+		if err != nil {
+			return nil, err
+		}
+		if gvarData != nil {
+			fdx, fdy, err := glyphDeltas(gvarData(gid), &g, len(axes), shared, norm)
+			if err != nil {
+				return nil, err
+			}
+			n := g.points()
+			dx, dy := make([]int, n+4), make([]int, n+4)
+			for j := range dx {
+				dx[j], dy[j] = int(math.Round(fdx[j])), int(math.Round(fdy[j]))
+			}
+			if len(data) > 0 {
+				xMin := g.bbox[0]
+				data = g.encode(dx, dy)
+				if g.contours >= 0 {
+					// the left side bearing follows the outline and the
+					// origin, the first phantom point
+					lsb += int(int16(binary.BigEndian.Uint16(data[2:]))) - xMin - dx[n]
+				}
+			}
+			if hvar, ok := tables["HVAR"]; ok {
+				advance += int(math.Round(hvarAdvance(hvar, gid, norm)))
+			} else {
+				advance += dx[n+1] - dx[n]
+			}
+			advance = max(advance, 0)
+		}
+		advanceMax = max(advanceMax, advance)
+		locaOut = append(locaOut, packUint32(len(glyfOut))...)
+		glyfOut = append(glyfOut, data...)
+		for len(glyfOut)%4 != 0 {
+			glyfOut = append(glyfOut, 0)
+		}
+		hmtxOut = append(hmtxOut, packUint16(advance)...)
+		hmtxOut = append(hmtxOut, packUint16(lsb)...)
+	}
+	locaOut = append(locaOut, packUint32(len(glyfOut))...)
+
+	out := &utf8FontFile{outTablesData: make(map[string][]byte)}
+	for tag, data := range tables {
+		switch tag {
+		case "fvar", "gvar", "avar", "cvar", "HVAR", "VVAR", "MVAR", "STAT", "DSIG":
+		default:
+			out.outTablesData[tag] = data
+		}
+	}
+	head = append([]byte{}, head...)
+	copy(head[8:], []byte{0, 0, 0, 0}) // checksum adjustment
+	copy(head[50:], packUint16(1))     // long loca offsets
+	hhea = append([]byte{}, hhea...)
+	copy(hhea[10:], packUint16(advanceMax))
+	copy(hhea[34:], packUint16(numGlyphs))
+	out.outTablesData["head"] = head
+	out.outTablesData["hhea"] = hhea
+	out.outTablesData["glyf"] = glyfOut
+	out.outTablesData["loca"] = locaOut
+	out.outTablesData["hmtx"] = hmtxOut
+	if os2, ok := tables["OS/2"]; ok && len(os2) >= 8 {
+		os2 = append([]byte{}, os2...)
+		for i, a := range axes {
+			switch a.Tag {
+			case "wght":
+				copy(os2[4:], packUint16(int(math.Round(math.Max(1, math.Min(1000, coords[i]))))))
+			case "wdth":
+				copy(os2[6:], packUint16(widthClass(coords[i])))
+			}
+		}
+		out.outTablesData["OS/2"] = os2
+	}
+	return out.assembleTables(), nil
+}
+
+// widthClass returns the OS/2 width class, from 1 (ultra-condensed) to 9
+// (ultra-expanded), closest to the width wdth in percent of the normal width.
+func widthClass(wdth float64) int {
+	widths := []float64{50, 62.5, 75, 87.5, 100, 112.5, 125, 150, 200}
+	class := 1
+	for i, w := range widths {
+		if math.Abs(wdth-w) < math.Abs(wdth-widths[class-1]) {
+			class = i + 1
+		}
+	}
+	return class
+}
@@ -0,0 +1,224 @@
+package fpdf
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"testing"
+	"unicode/utf16"
+)
+
+// variableFont returns a variable version of the font file fileStr with a
+// wght axis from 100 to 900 and the named instances "Regular" and "Bold". At
+// wght 900, the outline points of every glyph are moved 50 units right and
+// its advance width grows by 100 units.
+func variableFont(t *testing.T, fileStr string) []byte {
+	data, err := os.ReadFile(fileStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := sfntTables(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	put16 := func(b []byte, v ...int) []byte {
+		for _, n := range v {
+			b = append(b, byte(n>>8), byte(n))
+		}
+		return b
+	}
+	put32 := func(b []byte, v ...int) []byte {
+		for _, n := range v {
+			b = append(b, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+		}
+		return b
+	}
+
+	var name, storage []byte
+	names := map[int]string{1: "Varia", 2: "Regular", 6: "Varia-Regular", 256: "Weight", 257: "Bold"}
+	name = put16(name, 0, len(names), 6+12*len(names))
+	for _, id := range []int{1, 2, 6, 256, 257} {
+		str := utf16.Encode([]rune(names[id]))
+		name = put16(name, 3, 1, 0x409, id, 2*len(str), len(storage))
+		for _, u := range str {
+			storage = put16(storage, int(u))
+		}
+	}
+	tables["name"] = append(name, storage...)
+
+	var fvar []byte
+	fvar = put16(fvar, 1, 0, 16, 2, 1, 20, 2, 8)
+	fvar = append(fvar, "wght"...)
+	fvar = put32(fvar, 100<<16, 400<<16, 900<<16)
+	fvar = put16(fvar, 0, 256)
+	fvar = put16(fvar, 2, 0)
+	fvar = put32(fvar, 400<<16)
+	fvar = put16(fvar, 257, 0)
+	fvar = put32(fvar, 900<<16)
+	tables["fvar"] = fvar
+
+	numGlyphs := int(binary.BigEndian.Uint16(tables["maxp"][4:]))
+	longLoca := binary.BigEndian.Uint16(tables["head"][50:]) != 0
+	offset := func(gid int) int {
+		if longLoca {
+			return int(binary.BigEndian.Uint32(tables["loca"][4*gid:]))
+		}
+		return 2 * int(binary.BigEndian.Uint16(tables["loca"][2*gid:]))
+	}
+	var offsets, glyphData []byte
+	for gid := 0; gid < numGlyphs; gid++ {
+		offsets = put32(offsets, len(glyphData))
+		g, err := parseGlyph(tables["glyf"][offset(gid):offset(gid+1)])
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := g.points()
+		// one tuple at wght 900 applying to all points
+		var deltas []byte
+		deltas = append(deltas, 0)
+		for left := n; left > 0; left -= 64 {
+			deltas = append(deltas, byte(min(left, 64)-1))
+			for j := 0; j < min(left, 64); j++ {
+				deltas = append(deltas, 50)
+			}
+		}
+		deltas = append(deltas, 0x80)             // pp1
+		deltas = put16(append(deltas, 0x40), 100) // pp2
+		deltas = append(deltas, 0x81)             // pp3, pp4
+		for left := n + 4; left > 0; left -= 64 {
+			deltas = append(deltas, byte(0x80|(min(left, 64)-1)))
+		}
+		glyphData = put16(glyphData, 1, 10, len(deltas), 0xa000, 0x4000)
+		glyphData = append(glyphData, deltas...)
+	}
+	offsets = put32(offsets, len(glyphData))
+	var gvar []byte
+	gvar = put16(gvar, 1, 0, 1, 0)
+	gvar = put32(gvar, 20+len(offsets))
+	gvar = put16(gvar, numGlyphs, 1)
+	gvar = put32(gvar, 20+len(offsets))
+	gvar = append(append(gvar, offsets...), glyphData...)
+	tables["gvar"] = gvar
+
+	return (&utf8FontFile{outTablesData: tables}).assembleTables()
+}
+
+// glyphMetrics returns the first x coordinate, the advance width and the left
+// side bearing of glyph gid of the font data.
+func glyphMetrics(t *testing.T, data []byte, gid int) (x, advance, lsb int) {
+	tables, err := sfntTables(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loca, hmtx := tables["loca"], tables["hmtx"]
+	start, end := int(binary.BigEndian.Uint32(loca[4*gid:])), int(binary.BigEndian.Uint32(loca[4*gid+4:]))
+	g, err := parseGlyph(tables["glyf"][start:end])
+	if err != nil || len(g.x) == 0 {
+		t.Fatalf("glyph %d: %v", gid, err)
+	}
+	return g.x[0], int(binary.BigEndian.Uint16(hmtx[4*gid:])), int(int16(binary.BigEndian.Uint16(hmtx[4*gid+2:])))
+}
+
+func TestFontVariations(t *testing.T) {
+	axes, instances, err := FontVariations(variableFont(t, "fonts/DejaVuSansCondensed.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(axes) != 1 || axes[0] != (FontAxis{"wght", 100, 400, 900}) {
+		t.Errorf("axes: got %v", axes)
+	}
+	if len(instances) != 2 || instances[0] != "Regular" || instances[1] != "Bold" {
+		t.Errorf("instances: got %q", instances)
+	}
+	data, _ := os.ReadFile("fonts/DejaVuSansCondensed.ttf")
+	if axes, _, err = FontVariations(data); err != nil || len(axes) != 0 {
+		t.Errorf("static font: got axes %v, error %v", axes, err)
+	}
+}
+
+func TestInstantiateFont(t *testing.T) {
+	font := variableFont(t, "fonts/DejaVuSansCondensed.ttf")
+	const gid = 36 // A
+	static, err := InstantiateFont(font, FontVariation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, advance, lsb := glyphMetrics(t, static, gid)
+	for _, c := range []struct {
+		v     FontVariation
+		shift int
+	}{
+		{FontVariation{Instance: "bold"}, 50},
+		{FontVariation{Axes: map[string]float64{"wght": 650}}, 25},
+		{FontVariation{Instance: "Bold", Axes: map[string]float64{"wght": 400}}, 0},
+		{FontVariation{Axes: map[string]float64{"wght": 2000}}, 50},
+		{FontVariation{Axes: map[string]float64{"wght": 100}}, 0},
+	} {
+		inst, err := InstantiateFont(font, c.v)
+		if err != nil {
+			t.Fatalf("%v: %v", c.v, err)
+		}
+		gotX, gotAdvance, gotLsb := glyphMetrics(t, inst, gid)
+		if gotX != x+c.shift || gotAdvance != advance+2*c.shift || gotLsb != lsb+c.shift {
+			t.Errorf("%v: got x %d, advance %d, lsb %d, want %d, %d, %d", c.v,
+				gotX, gotAdvance, gotLsb, x+c.shift, advance+2*c.shift, lsb+c.shift)
+		}
+		tables, _ := sfntTables(inst)
+		if _, ok := tables["gvar"]; ok {
+			t.Errorf("%v: gvar table kept", c.v)
+		}
+	}
+	bold, _ := InstantiateFont(font, FontVariation{Instance: "Bold"})
+	tables, _ := sfntTables(bold)
+	if weight := binary.BigEndian.Uint16(tables["OS/2"][4:]); weight != 900 {
+		t.Errorf("weight class: got %d, want 900", weight)
+	}
+
+	for _, v := range []FontVariation{{Instance: "Black"}, {Axes: map[string]float64{"wdth": 75}}} {
+		if _, err := InstantiateFont(font, v); err == nil {
+			t.Errorf("%v: expected error", v)
+		}
+	}
+	if _, err := InstantiateFont([]byte("not a font"), FontVariation{}); err == nil {
+		t.Errorf("invalid data: expected error")
+	}
+}
+
+func TestAddUTF8FontVariation(t *testing.T) {
+	font := variableFont(t, "fonts/DejaVuSansCondensed.ttf")
+	pdf := New()
+	pdf.AddPage()
+	pdf.AddUTF8FontVariationFromBytes("varia", "", font, FontVariation{Instance: "Regular"})
+	pdf.AddUTF8FontVariationFromBytes("varia", "B", font, FontVariation{Instance: "Bold"})
+	pdf.SetFont("varia", "", 12)
+	regular := pdf.GetStringWidth("AAA")
+	pdf.Cell(0, 10, "AAA")
+	pdf.SetFont("varia", "B", 12)
+	bold := pdf.GetStringWidth("AAA")
+	pdf.Cell(0, 10, "AAA")
+	if bold <= regular {
+		t.Errorf("bold width %.2f not greater than regular width %.2f", bold, regular)
+	}
+	if err := pdf.Output(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIUP(t *testing.T) {
+	// a square contour whose first and third points are moved
+	d0, d2 := 10.0, 30.0
+	got := iup([]int{0, 100, 200, 100}, []*float64{&d0, nil, &d2, nil}, []int{3})
+	want := []float64{10, 20, 30, 20}
+	for j := range want {
+		if got[j] != want[j] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	// a single referenced point moves the whole contour
+	got = iup([]int{0, 100, 200}, []*float64{nil, &d2, nil}, []int{2})
+	for _, d := range got {
+		if d != 30 {
+			t.Fatalf("got %v", got)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"image/color"
 	"image/jpeg"
 	"io"
+	"io/fs"
 	"math"
 
 	. "github.com/tinywasm/fmt"
@@ -30,24 +31,15 @@ func New(options ...any) (f *Fpdf) {
 
 	var size = PageSize{0, 0, false}
 	var initType *InitType
+	var resourceFS fs.FS
 
 	// Set default values
 	f.defOrientation = Portrait
+	f.prec = DefaultPrecision
 	f.rootDirectory = "."
 	f.fontsDirName = "fonts"
 	f.unitType = MM
-	// Initialize writeFile with a function that returns an error by default
-	f.writeFile = func(filePath string, content []byte) error {
-		return Errf("writeFile function not configured for this environment")
-	}
-	// Initialize readFile with a function that returns an error by default
-	f.readFile = func(filePath string) ([]byte, error) {
-		return nil, Errf("readFile function not configured for this environment")
-	}
-	// Initialize fileSize with a function that returns an error by default
-	f.fileSize = func(filePath string) (int64, error) {
-		return 0, Errf("fileSize function not configured for this environment")
-	}
+	f.initIO()
 
 	for _, opt := range options {
 		switch v := opt.(type) {
@@ -71,9 +63,13 @@ func New(options ...any) (f *Fpdf) {
 			f.readFile = v
 		case FileSizeFunc:
 			f.fileSize = v
-
+		case fs.FS:
+			resourceFS = v
 		}
 	}
+	if resourceFS != nil {
+		f.SetResourceFS(resourceFS)
+	}
 	if initType != nil {
 		f.defOrientation = initType.OrientationStr
 		if f.defOrientation == "" {
@@ -106,6 +102,7 @@ func New(options ...any) (f *Fpdf) {
 	f.links = append(f.links, intLinkType{}) // links[0] is unused (1-based)
 	f.pageAttachments = make([][]annotationAttach, 0, 8)
 	f.pageAttachments = append(f.pageAttachments, []annotationAttach{}) //
+	f.pageBody = make([]pageBodyType, 1, 8)                             // pageBody[0] is unused (1-based)
 	f.aliasMap = make(map[string]string)
 	f.inHeader = false
 	f.inFooter = false
@@ -132,17 +129,9 @@ func New(options ...any) (f *Fpdf) {
 		"zapfdingbats": true,
 	}
 	// Scale factor
-	switch f.unitType {
-	case POINT:
-		f.k = 1.0
-	case MM:
-		f.k = 72.0 / 25.4
-	case CM:
-		f.k = 72.0 / 2.54
-	case IN:
-		f.k = 72.0
-	default:
-		f.err = Err("format", "invalid")
+	var ok bool
+	if f.k, ok = unitScale(f.unitType); !ok {
+		f.errorf("New", "invalid unit of measure: %s", string(f.unitType))
 		return
 	}
 	f.stdPageSizes = make(map[string]PageSize)
@@ -186,7 +175,7 @@ func New(options ...any) (f *Fpdf) {
 		f.w = f.defPageSize.Ht / f.k
 		f.h = f.defPageSize.Wd / f.k
 	default:
-		f.err = Err("format", "invalid")
+		f.errorf("New", "invalid orientation: %s", string(f.defOrientation))
 		return
 	}
 	f.curOrientation = f.defOrientation
@@ -219,6 +208,7 @@ func New(options ...any) (f *Fpdf) {
 	f.alpha = 1
 	f.gradientList = make([]gradientType, 0, 8)
 	f.gradientList = append(f.gradientList, gradientType{}) // gradientList[0] is unused
+	f.gradientMap = make(map[string]int)
 	// Set default PDF version number
 	f.pdfVersion = pdfVers1_3
 	f.SetProducer("FPDF "+cnFpdfVersion, true)
@@ -260,9 +250,7 @@ func (f *Fpdf) ClearError() {
 // See the documentation for printing in the standard fmt package for details
 // about fmtStr and args.
 func (f *Fpdf) SetErrorf(fmtStr string, args ...any) {
-	if f.err == nil {
-		f.err = Errf(fmtStr, args...)
-	}
+	f.errorf("SetErrorf", fmtStr, args...)
 }
 
 // String satisfies the fmt.Stringer interface and summarizes the Fpdf
@@ -274,9 +262,7 @@ func (f *Fpdf) String() string {
 // SetError sets an error to halt PDF generation. This may facilitate error
 // handling by application. See also Ok(), Err() and Error().
 func (f *Fpdf) SetError(err error) {
-	if f.err == nil && err != nil {
-		f.err = err
-	}
+	f.setError("SetError", err)
 }
 
 // Error returns the internal Fpdf error; this will be nil if no error has occurred.
@@ -443,13 +429,29 @@ func (f *Fpdf) AliasNbPages(aliasStr string) {
 	f.aliasNbPagesStr = aliasStr
 }
 
-// RTL enables right-to-left mode
+// RTL enables right-to-left mode. The text of UTF-8 fonts is reversed, and the
+// layout is mirrored: lines start at the right margin, where Ln(), SetY() and
+// AddPage() place the current position, each cell of CellFormat(),
+// CellMultiline() and MultiCell() ends at the current abscissa and the next
+// one is placed to its left, so that the columns of a table follow each other
+// from right to left, and cells without horizontal alignment are aligned to
+// the right. Write() flows from the mirrored position from left to right.
+// Borders, explicit alignments and the coordinates given to drawing methods
+// are not mirrored. A position at the start of a line moves to the right
+// margin.
 func (f *Fpdf) RTL() {
+	if f.page > 0 && !f.isRTL && f.x == f.lMargin {
+		f.x = f.w - f.rMargin
+	}
 	f.isRTL = true
 }
 
-// LTR disables right-to-left mode
+// LTR disables right-to-left mode. A position at the start of a line moves to
+// the left margin.
 func (f *Fpdf) LTR() {
+	if f.page > 0 && f.mirrored() && f.x == f.w-f.rMargin {
+		f.x = f.lMargin
+	}
 	f.isRTL = false
 }
 
@@ -465,9 +467,9 @@ func (f *Fpdf) open() {
 func (f *Fpdf) Close() {
 	if f.err == nil {
 		if f.clipNest > 0 {
-			f.err = Errf("clip procedure must be explicitly ended")
+			f.errorf("Close", "clip procedure must be explicitly ended")
 		} else if f.transformNest > 0 {
-			f.err = Errf("transformation procedure must be explicitly ended")
+			f.errorf("Close", "transformation procedure must be explicitly ended")
 		}
 	}
 	if f.err != nil {
@@ -482,17 +484,20 @@ func (f *Fpdf) Close() {
 			return
 		}
 	}
-	// Page footer
-	f.inFooter = true
-	if f.footerFnc != nil {
-		f.footerFnc()
-	} else if f.footerFncLpi != nil {
-		f.footerFncLpi(true)
+	f.padPages()
+	if f.err != nil {
+		return
 	}
-	f.inFooter = false
+	// Page footer
+	f.pageFooter(true)
 
 	// Close page
 	f.endpage()
+	f.removeBlankPages()
+	f.checkContent()
+	if f.err != nil {
+		return
+	}
 	// Close document
 	f.enddoc()
 }
@@ -571,7 +576,7 @@ func (f *Fpdf) SetLink(link int, y float64, page int) {
 	if page == -1 {
 		page = f.page
 	}
-	f.links[link] = intLinkType{page, y}
+	f.links[link] = intLinkType{page: page, y: y}
 }
 
 // newLink adds a new clickable link on current page
@@ -581,8 +586,9 @@ func (f *Fpdf) newLink(x, y, w, h float64, link int, linkStr string) {
 	// linkList = make([]linkType, 0, 8)
 	// f.pageLinks[f.page] = linkList
 	// }
+	xPt, yPt := f.pagePoint(f.page, x, y)
 	f.pageLinks[f.page] = append(f.pageLinks[f.page],
-		linkType{x * f.k, f.hPt - y*f.k, w * f.k, h * f.k, link, linkStr})
+		linkType{x: xPt, y: yPt, wd: w * f.k, ht: h * f.k, link: link, linkStr: linkStr})
 }
 
 // Link puts a link on a rectangular area of the page. Text or image links are
@@ -684,20 +690,40 @@ func (f *Fpdf) SetTextRenderingMode(mode int) {
 //
 // linkStr is a target URL or empty for no external link. A non--zero value for
 // link takes precedence over linkStr.
+//
+// CellOpt() takes the same options typed.
 func (f *Fpdf) CellFormat(w, h float64, txtStr, borderStr string, ln int,
 	alignStr string, fill bool, link int, linkStr string) {
 	// dbg("CellFormat. h = %.2f, borderStr = %s", h, borderStr)
-	if f.err != nil {
+	if f.err != nil || !f.strictLength("CellFormat", "w h", w, h) {
+		return
+	}
+	if f.mirrored() {
+		f.mirror(w, ln, func(w float64) {
+			f.CellFormat(w, h, txtStr, borderStr, ln, mirrorAlign(alignStr), fill, link, linkStr)
+		})
 		return
 	}
+	txtStr = f.transformText(txtStr)
 
 	if f.currentFont.Name == "" {
-		f.err = Errf("font has not been set; unable to render text")
+		f.errorf("CellFormat", "font has not been set; unable to render text")
 		return
 	}
 
-	borderStr = Convert(borderStr).ToLower().String()
+	for j := 0; j < len(borderStr); j++ {
+		// Converted only if needed, which spares an allocation per cell
+		if c := borderStr[j]; c >= 'a' && c <= 'z' {
+			borderStr = Convert(borderStr).ToUpper().String()
+			break
+		}
+	}
 	k := f.k
+	zoneW := w // width checked against exclusion zones
+	if zoneW == 0 {
+		zoneW = f.w - f.rMargin - f.x
+	}
+	f.y = f.exclusionY(f.x, zoneW, f.y, h)
 	if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() {
 		// Automatic page break
 		x := f.x
@@ -712,6 +738,7 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr, borderStr string, ln int,
 			return
 		}
 		f.x = x
+		f.y = f.exclusionY(f.x, zoneW, f.y, h)
 		if ws > 0 {
 			f.ws = ws
 			// f.outf("%.3f Tw", ws*k)
@@ -722,7 +749,14 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr, borderStr string, ln int,
 	if w == 0 {
 		w = f.w - f.rMargin - f.x
 	}
-	var s fmtBuffer
+	if fill || len(borderStr) > 0 {
+		f.extend(f.x, f.y, f.x+w, f.y+h)
+	}
+	// The operators are appended to scratch space kept between calls rather
+	// than formatted, since tables call CellFormat in hot loops.
+	s := f.cellBuf[:0]
+	var objStart, objEnd int // bytes of s spanned by the text object
+	var textX, textY float64 // baseline origin of the text
 	if h > 0 && (fill || borderStr == "1") {
 		var op string
 		if fill {
@@ -738,7 +772,8 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr, borderStr string, ln int,
 			op = "S"
 		}
 		/// dbg("(CellFormat) f.x %.2f f.k %.2f", f.x, f.k)
-		s.printf("%.2f %.2f %.2f %.2f re %s ", f.x*k, (f.h-f.y)*k, w*k, -h*k, op)
+		s = appendFloats(s, f.prec.Path, f.x*k, (f.h-f.y)*k, w*k, -h*k)
+		s = append(append(append(s, "re "...), op...), ' ')
 	}
 	if len(borderStr) > 0 && borderStr != "1" {
 		// fmt.Printf("border is '%s', no fill\n", borderStr)
@@ -749,18 +784,19 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr, borderStr string, ln int,
 		right := (x + w) * k
 		bottom := (f.h - (y + h)) * k
 		if Contains(borderStr, "L") {
-			s.printf("%.2f %.2f m %.2f %.2f l S ", left, top, left, bottom)
+			s = appendLine(s, f.prec.Path, left, top, left, bottom)
 		}
 		if Contains(borderStr, "T") {
-			s.printf("%.2f %.2f m %.2f %.2f l S ", left, top, right, top)
+			s = appendLine(s, f.prec.Path, left, top, right, top)
 		}
 		if Contains(borderStr, "R") {
-			s.printf("%.2f %.2f m %.2f %.2f l S ", right, top, right, bottom)
+			s = appendLine(s, f.prec.Path, right, top, right, bottom)
 		}
 		if Contains(borderStr, "B") {
-			s.printf("%.2f %.2f m %.2f %.2f l S ", left, bottom, right, bottom)
+			s = appendLine(s, f.prec.Path, left, bottom, right, bottom)
 		}
 	}
+	s = append(s, f.debugCell(w, h)...)
 	if len(txtStr) > 0 {
 		var dx, dy float64
 		// Horizontal alignment
@@ -792,70 +828,103 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr, borderStr string, ln int,
 		default:
 			dy = 0
 		}
+		var decorationW float64
+		if f.underline || f.strikeout || f.textBg.str != "" {
+			decorationW = f.decorationWidth(txtStr)
+			if (f.ws != 0 || Contains(alignStr, "J")) && f.isCurrentUTF8 && Contains(txtStr, " ") {
+				// The spaces stretch the text to the width of the cell
+				decorationW = float64(int(math.Ceil((w-2*f.cMargin)*1000/f.fontSize))) * f.fontSize / 1000
+			}
+		}
+		s = append(s, f.textBackground(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, decorationW)...)
 		if f.colorFlag {
-			s.printf("q %s ", f.color.text.str)
+			s = append(append(append(s, "q "...), f.color.text.str...), ' ')
 		}
 		//If multibyte, Tw has no effect - do word spacing using an adjustment before each space
-		if (f.ws != 0 || alignStr == "J") && f.isCurrentUTF8 { // && f.ws != 0
-			if f.isRTL {
-				txtStr = reverseText(txtStr)
+		if (f.ws != 0 || Contains(alignStr, "J")) && f.isCurrentUTF8 { // && f.ws != 0
+			if f.isRTL || hasRTL(txtStr) {
+				txtStr = visualOrder(txtStr, f.isRTL)
 			}
 			wmax := int(math.Ceil((w - 2*f.cMargin) * 1000 / f.fontSize))
-			for _, uni := range txtStr {
-				f.currentFont.usedRunes[int(uni)] = int(uni)
-			}
-			space := f.escape(utf8toutf16(" ", false))
+			f.useRunes("CellFormat", txtStr)
+			space := f.escape(f.encodeText(" "))
 			strSize := f.GetStringSymbolWidth(txtStr)
-			s.printf("BT 0 Tw %.2f %.2f Td [", (f.x+dx)*k, (f.h-(f.y+.5*h+.3*f.fontSize))*k)
+			objStart = len(s)
+			s = append(append(s, f.textBegin((f.x+dx)*k, (f.h-(f.y+.5*h+.3*f.fontSize))*k, "0 Tw ")...), " ["...)
 			t := Convert(txtStr).Split(" ")
 			shift := float64((wmax - strSize)) / float64(len(t)-1)
 			numt := len(t)
 			for i := 0; i < numt; i++ {
-				tx := t[i]
-				tx = "(" + f.escape(utf8toutf16(tx, false)) + ")"
-				s.printf("%s ", tx)
+				if f.textTransform == TextTransformSmallCaps || f.needsFallback(t[i]) {
+					// Font changes cannot appear in a TJ array
+					s = append(append(append(s, "] TJ "...), f.textShow(t[i], "", "Tj")...), " ["...)
+				} else {
+					s = append(f.appendEncoded(append(s, '('), t[i]), ") "...)
+				}
 				if (i + 1) < numt {
-					s.printf("%.3f(%s) ", -shift, space)
+					s = append(append(append(appendFloat(s, -shift, 3), '('), space...), ") "...)
 				}
 			}
-			s.printf("] TJ ET")
+			s = append(append(s, "] TJ "...), f.textEnd()...)
+			objEnd = len(s)
 		} else {
-			var txt2 string
 			if f.isCurrentUTF8 {
-				if f.isRTL {
-					txtStr = reverseText(txtStr)
+				if f.isRTL || hasRTL(txtStr) {
+					txtStr = visualOrder(txtStr, f.isRTL)
 				}
-				txt2 = f.escape(utf8toutf16(txtStr, false))
-				for _, uni := range txtStr {
-					f.currentFont.usedRunes[int(uni)] = int(uni)
-				}
-			} else {
-
-				txt2 = Convert(txtStr).Replace("\\", "\\\\").Replace("(", "\\(").Replace(")", "\\)").String()
+				f.useRunes("CellFormat", txtStr)
 			}
 			bt := (f.x + dx) * k
 			td := (f.h - (f.y + dy + .5*h + .3*f.fontSize)) * k
-			s.printf("BT %.2f %.2f Td (%s)Tj ET", bt, td, txt2)
-			//BT %.2F %.2F Td (%s) Tj ET',(f.x+dx)*k,(f.h-(f.y+.5*h+.3*f.FontSize))*k,txt2);
+			objStart = len(s)
+			if f.fontSynth == "" && f.textTransform != TextTransformSmallCaps && !f.needsFallback(txtStr) {
+				// BT %.2f %.2f Td (%s)Tj ET
+				s = appendFloats(append(s, "BT "...), f.prec.Text, bt)
+				s = append(appendFloat(s, td, f.prec.Text), " Td ("...)
+				if f.isCurrentUTF8 {
+					s = f.appendEncoded(s, txtStr)
+				} else {
+					for j := 0; j < len(txtStr); j++ {
+						if c := txtStr[j]; c == '\r' {
+							s = append(s, c)
+						} else {
+							s = appendEscaped(s, c)
+						}
+					}
+				}
+				s = append(s, ")Tj ET"...)
+			} else {
+				var txt2 string
+				if f.isCurrentUTF8 {
+					txt2 = f.escape(f.encodeText(txtStr))
+				} else {
+					txt2 = Convert(txtStr).Replace("\\", "\\\\").Replace("(", "\\(").Replace(")", "\\)").String()
+				}
+				s = append(append(append(s, f.textBegin(bt, td, "")...), ' '), f.textShow(txtStr, txt2, "Tj")...)
+				s = append(append(s, ' '), f.textEnd()...)
+			}
+			objEnd = len(s)
 		}
 
 		if f.underline {
-			s.printf(" %s", f.dounderline(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, txtStr))
+			s = append(append(s, ' '), f.dounderline(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, decorationW)...)
 		}
 		if f.strikeout {
-			s.printf(" %s", f.dostrikeout(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, txtStr))
+			s = append(append(s, ' '), f.dostrikeout(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, decorationW)...)
 		}
 		if f.colorFlag {
-			s.printf(" Q")
+			s = append(s, " Q"...)
 		}
+		f.extendText(f.x+dx, f.y+dy+.5*h+.3*f.fontSize, txtStr)
+		textX, textY = f.x+dx, f.y+dy+.5*h+.3*f.fontSize
 		if link > 0 || len(linkStr) > 0 {
 			f.newLink(f.x+dx, f.y+dy+.5*h-.5*f.fontSize, f.GetStringWidth(txtStr), f.fontSize, link, linkStr)
 		}
 	}
-	str := s.String()
-	if len(str) > 0 {
-		f.out(str)
+	if len(s) > 0 {
+		f.writeText(s, objStart, objEnd, textX, textY, txtStr)
 	}
+	f.cellBuf = s[:0]
 	f.lasth = h
 	if ln > 0 {
 		// Go to next line
@@ -868,15 +937,77 @@ func (f *Fpdf) CellFormat(w, h float64, txtStr, borderStr string, ln int,
 	}
 }
 
-// Revert string to use in RTL languages
-func reverseText(text string) string {
-	oldText := []rune(text)
-	newText := make([]rune, len(oldText))
-	length := len(oldText) - 1
-	for i, r := range oldText {
-		newText[length-i] = r
+// CellMultiline prints a cell like CellFormat() but splits txtStr at each
+// newline character and stacks the resulting lines inside the cell, instead of
+// printing the newlines as ordinary glyphs. All arguments have the same
+// meaning as in CellFormat(), except that the cell grows taller than h when
+// its lines do not fit in it.
+//
+// Each line is given a height of 1.2 times the font size, so the cell is at
+// least that times the number of lines tall. The block of lines is positioned
+// vertically according to "T", "M" or "B" in alignStr (top, middle, bottom;
+// the default is middle) and each line is aligned horizontally according to
+// "L", "C" or "R".
+func (f *Fpdf) CellMultiline(w, h float64, txtStr, borderStr string, ln int,
+	alignStr string, fill bool, link int, linkStr string) {
+	if f.err != nil {
+		return
+	}
+	txtStr = Convert(txtStr).Replace("\r", "").String()
+	if !Contains(txtStr, "\n") {
+		f.CellFormat(w, h, txtStr, borderStr, ln, alignStr, fill, link, linkStr)
+		return
+	}
+	if f.mirrored() {
+		f.mirror(w, ln, func(w float64) {
+			f.CellMultiline(w, h, txtStr, borderStr, ln, mirrorAlign(alignStr), fill, link, linkStr)
+		})
+		return
+	}
+	if f.currentFont.Name == "" {
+		f.errorf("CellMultiline", "font has not been set; unable to render text")
+		return
+	}
+	if w == 0 {
+		w = f.w - f.rMargin - f.x
+	}
+	lines := Convert(txtStr).Split("\n")
+	lh := f.fontSize * 1.2
+	if n := float64(len(lines)); lh*n > h {
+		h = lh * n
+	}
+	// The empty cell draws the border and fill and takes care of page breaks
+	f.CellFormat(w, h, "", borderStr, 0, "", fill, link, linkStr)
+	x, y := f.x-w, f.y
+
+	top := y + (h-lh*float64(len(lines)))/2
+	switch {
+	case Contains(alignStr, "T"):
+		top = y
+	case Contains(alignStr, "B"):
+		top = y + h - lh*float64(len(lines))
+	}
+	align := "L"
+	switch {
+	case Contains(alignStr, "C"):
+		align = "C"
+	case Contains(alignStr, "R"):
+		align = "R"
+	}
+	for i, line := range lines {
+		f.x, f.y = x, top+float64(i)*lh
+		f.CellFormat(w, lh, line, "", 0, align, false, 0, "")
+	}
+
+	f.lasth = h
+	switch ln {
+	case 1:
+		f.x, f.y = f.lMargin, y+h
+	case 2:
+		f.x, f.y = x, y+h
+	default:
+		f.x, f.y = x+w, y
 	}
-	return string(newText)
 }
 
 // Cell is a simpler version of CellFormat with no fill, border, links or
@@ -887,8 +1018,11 @@ func (f *Fpdf) Cell(w, h float64, txtStr string) {
 
 // Cellf is a simpler printf-style version of CellFormat with no fill, border,
 // links or special alignment. See documentation for the fmt package for
-// details on fmtStr and args.
+// details on fmtStr and args. If a locale has been set with SetLocale(), the
+// numbers printed with %d, %f and %.2f (or any other precision) use its
+// decimal and group separators.
 func (f *Fpdf) Cellf(w, h float64, fmtStr string, args ...any) {
+	fmtStr, args = f.localizeArgs(fmtStr, args)
 	f.CellFormat(w, h, sprintf(fmtStr, args...), "", 0, "L", false, 0, "")
 }
 
@@ -907,7 +1041,7 @@ func (f *Fpdf) SplitLines(txt []byte, w float64) [][]byte {
 	lines := [][]byte{}
 	cw := f.currentFont.Cw
 	wmax := int(math.Ceil((w - 2*f.cMargin) * 1000 / f.fontSize))
-	s := []byte(Convert(string(txt)).Replace("\r", "").String())
+	s := []byte(Convert(f.transformText(string(txt))).Replace("\r", "").String())
 	nb := len(s)
 	for nb > 0 && s[nb-1] == '\n' {
 		nb--
@@ -919,7 +1053,7 @@ func (f *Fpdf) SplitLines(txt []byte, w float64) [][]byte {
 	l := 0
 	for i < nb {
 		c := s[i]
-		l += cw[c]
+		l += f.capWidth(int(c), cw[c])
 		if c == ' ' || c == '\t' || c == '\n' {
 			sep = i
 		}
@@ -950,6 +1084,12 @@ func (f *Fpdf) SplitLines(txt []byte, w float64) [][]byte {
 // soon as the text reaches the right border of the cell) or explicit (via the
 // \n character). As many cells as necessary are output, one below the other.
 //
+// Automatic breaks follow the Unicode line breaking rules: lines are broken at
+// spaces, after hyphens and between ideographs, but not at no-break spaces,
+// before closing punctuation or after opening brackets. A soft hyphen
+// (U+00AD) is printed as a hyphen where a word is broken at it and omitted
+// elsewhere.
+//
 // Text can be aligned, centered or justified. The cell block can be framed and
 // the background painted. See CellFormat() for more details.
 //
@@ -970,18 +1110,34 @@ func (f *Fpdf) SplitLines(txt []byte, w float64) [][]byte {
 // removed should call TrimRight(txtStr, "\r\n") before calling this
 // method.
 func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill bool) {
-	if f.err != nil {
+	if f.err != nil || !f.strictLength("MultiCell", "w h", w, h) {
+		return
+	}
+	if f.mirrored() {
+		f.mirror(w, 1, func(w float64) {
+			f.MultiCell(w, h, txtStr, borderStr, alignStr, fill)
+		})
 		return
 	}
 	// dbg("MultiCell")
+	txtStr = f.transformText(txtStr)
 	if alignStr == "" {
 		alignStr = "J"
 	}
-	cw := f.currentFont.Cw
 	if w == 0 {
 		w = f.w - f.rMargin - f.x
 	}
-	wmax := int(math.Ceil((w - 2*f.cMargin) * 1000 / f.fontSize))
+	// Each line is narrowed to the part of the column beside the exclusion
+	// zones, computed when the line starts.
+	colX := f.x
+	lineX, lineW := colX, w
+	wmax := 0
+	newLine := true
+	span := func() {
+		lineX, lineW = f.exclusionSpan(colX, w, h)
+		wmax = int(math.Ceil((lineW - 2*f.cMargin) * 1000 / f.fontSize))
+		newLine = false
+	}
 	s := Convert(txtStr).Replace("\r", "").String()
 	srune := []rune(s)
 
@@ -1032,14 +1188,75 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 			}
 		}
 	}
+	// cell outputs one line of the box. In continuation mode a bordered box
+	// that does not fit on the page is closed above the page break and
+	// reopened below it.
+	closeB := f.multiCellCont && Contains(borderStr, "B")
+	openT := f.multiCellCont && Contains(borderStr, "T")
+	reopen := false
+	cell := func(txt, align string, last bool) {
+		border := b
+		if reopen {
+			reopen = false
+			f.multiCellBreak()
+			if f.err != nil {
+				return
+			}
+			if openT {
+				border += "T"
+			}
+		}
+		if (closeB || openT) && !last && f.autoPageBreak && !f.inHeader && !f.inFooter &&
+			f.y+h <= f.pageBreakTrigger && f.y+2*h > f.pageBreakTrigger {
+			reopen = true
+			if closeB {
+				border += "B"
+			}
+		}
+		f.x = lineX
+		f.CellFormat(lineW, h, txt, border, 2, align, fill, 0, "")
+		if f.x == lineX {
+			f.x = colX
+		} else {
+			// moved to another column by the page break function
+			colX = f.x
+		}
+		newLine = true
+		if reopen && f.multiCellMarker != nil {
+			x, y := f.x, f.y
+			accept := f.acceptPageBreak
+			f.acceptPageBreak = func() bool { return false }
+			f.multiCellMarker(true)
+			f.acceptPageBreak = accept
+			f.x, f.y = x, y
+		}
+	}
+	// text returns the characters j to k of s as a line, which is broken
+	// there if broken is set.
+	text := func(j, k int, broken bool) string {
+		if f.isCurrentUTF8 {
+			return hyphenate(string(srune[j:k]), string(softHyphen), broken)
+		}
+		return hyphenate(s[j:k], "\xad", broken)
+	}
+	runes := srune // the characters of s are already decoded for a UTF-8 font
+	if !f.isCurrentUTF8 {
+		runes = f.breakRunes(s)
+	}
+	brk := lineBreaks(runes)
 	sep := -1
+	sepSpace := false // whether the line ends at a space, which is dropped
 	i := 0
 	j := 0
 	l := 0
 	ls := 0
 	ns := 0
+	nss := 0 // number of spaces in the line that ends at sep
 	nl := 1
 	for i < nb {
+		if newLine {
+			span()
+		}
 		// Get next character
 		var c rune
 		if f.isCurrentUTF8 {
@@ -1063,9 +1280,9 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 						newAlignStr = "L"
 					}
 				}
-				f.CellFormat(w, h, string(srune[j:i]), b, 2, newAlignStr, fill, 0, "")
+				cell(text(j, i, false), newAlignStr, false)
 			} else {
-				f.CellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "")
+				cell(text(j, i, false), alignStr, false)
 			}
 			i++
 			sep = -1
@@ -1078,19 +1295,16 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 			}
 			continue
 		}
-		if c == ' ' || isChinese(c) {
-			sep = i
-			ls = l
+		if c == ' ' {
 			ns++
+			if brk[i] {
+				sep, sepSpace, ls, nss = i, true, l, ns-1
+			}
+		} else if brk[i] && i > j {
+			sep, sepSpace, ls, nss = i, false, l, ns
 		}
-		if int(c) >= len(cw) {
-			f.err = Errf("character outside the supported range: %s", string(c))
-			return
-		}
-		if cw[int(c)] == 0 { //Marker width 0 used for missing symbols
-			l += f.currentFont.Desc.MissingWidth
-		} else if cw[int(c)] != 65535 { //Marker width 65535 used for zero width symbols
-			l += cw[int(c)]
+		if c != softHyphen { // invisible unless the line is broken there
+			l += f.capWidth(int(c), f.charWidth(c))
 		}
 		if l > wmax {
 			// Automatic line break
@@ -1102,15 +1316,11 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 					f.ws = 0
 					f.out("0 Tw")
 				}
-				if f.isCurrentUTF8 {
-					f.CellFormat(w, h, string(srune[j:i]), b, 2, alignStr, fill, 0, "")
-				} else {
-					f.CellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "")
-				}
+				cell(text(j, i, true), alignStr, false)
 			} else {
 				if alignStr == "J" {
-					if ns > 1 {
-						f.ws = float64((wmax-ls)/1000) * f.fontSize / float64(ns-1)
+					if nss > 0 {
+						f.ws = float64((wmax-ls)/1000) * f.fontSize / float64(nss)
 					} else {
 						f.ws = 0
 					}
@@ -1118,12 +1328,11 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 					f.putF64(f.ws*f.k, 3)
 					f.put(" Tw\n")
 				}
-				if f.isCurrentUTF8 {
-					f.CellFormat(w, h, string(srune[j:sep]), b, 2, alignStr, fill, 0, "")
-				} else {
-					f.CellFormat(w, h, s[j:sep], b, 2, alignStr, fill, 0, "")
+				cell(text(j, sep, true), alignStr, false)
+				i = sep
+				if sepSpace {
+					i++
 				}
-				i = sep + 1
 			}
 			sep = -1
 			j = i
@@ -1145,6 +1354,9 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 	if len(borderStr) > 0 && Contains(borderStr, "B") {
 		b += "B"
 	}
+	if newLine {
+		span()
+	}
 	if f.isCurrentUTF8 {
 		if alignStr == "J" {
 			if f.isRTL {
@@ -1153,19 +1365,80 @@ func (f *Fpdf) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill
 				alignStr = ""
 			}
 		}
-		f.CellFormat(w, h, string(srune[j:i]), b, 2, alignStr, fill, 0, "")
+		cell(text(j, i, false), alignStr, true)
 	} else {
-		f.CellFormat(w, h, s[j:i], b, 2, alignStr, fill, 0, "")
+		cell(text(j, i, false), alignStr, true)
 	}
 	f.x = f.lMargin
 }
 
+// SetMultiCellContinuation controls how MultiCell() draws a bordered box that
+// spans a page break. By default the lines of the box are simply split across
+// pages, leaving the box open at the bottom of one page and at the top of the
+// next. When on is true, the top and bottom borders requested for the box are
+// also drawn on each side of the page break, so that every page shows a closed
+// box.
+//
+// If markerFn is not nil, it is called twice at each such break: first with
+// closing set to true, just after the box has been closed, with the current
+// position below the box and automatic page breaks suspended; then with
+// closing set to false at the top of the new page, before the box is
+// reopened. It can be used to print a "(continued)" notice. The current
+// abscissa is restored after each call.
+func (f *Fpdf) SetMultiCellContinuation(on bool, markerFn func(closing bool)) {
+	f.multiCellCont = on
+	f.multiCellMarker = markerFn
+}
+
+// multiCellBreak starts a new page within a MultiCell box in continuation
+// mode, preserving the abscissa and word spacing like an automatic page break
+// in CellFormat().
+func (f *Fpdf) multiCellBreak() {
+	if !f.acceptPageBreak() {
+		return
+	}
+	x, ws := f.x, f.ws
+	if ws > 0 {
+		f.ws = 0
+		f.out("0 Tw")
+	}
+	f.AddPageFormat(f.curOrientation, f.curPageSize)
+	if f.err != nil {
+		return
+	}
+	if f.multiCellMarker != nil {
+		f.multiCellMarker(false)
+	}
+	f.x = x
+	if ws > 0 {
+		f.ws = ws
+		f.putF64(ws*f.k, 3)
+		f.put(" Tw\n")
+	}
+}
+
 // write outputs text in flowing mode
 func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 	// dbg("Write")
-	cw := f.currentFont.Cw
-	w := f.w - f.rMargin - f.x
-	wmax := (w - 2*f.cMargin) * 1000 / f.fontSize
+	if f.mirrored() {
+		// The text flows from the mirrored position, from left to right.
+		f.x = f.lMargin + f.w - f.rMargin - f.x
+		f.mirrorDepth++
+		f.write(h, txtStr, link, linkStr)
+		f.mirrorDepth--
+		f.x = f.lMargin + f.w - f.rMargin - f.x
+		return
+	}
+	txtStr = f.transformText(txtStr)
+	// Each line runs from the current abscissa to the right margin, narrowed
+	// to the part beside the exclusion zones when the line starts.
+	var w, wmax float64
+	newLine := true
+	span := func() {
+		f.x, w = f.exclusionSpan(f.x, f.w-f.rMargin-f.x, h)
+		wmax = (w - 2*f.cMargin) * 1000 / f.fontSize
+		newLine = false
+	}
 	s := Convert(txtStr).Replace("\r", "").String()
 	var nb int
 	if f.isCurrentUTF8 {
@@ -1177,42 +1450,46 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 	} else {
 		nb = len(s)
 	}
+	runes := f.breakRunes(s)
+	brk := lineBreaks(runes)
+	// text returns the characters j to k of s as a line, which is broken
+	// there if broken is set.
+	text := func(j, k int, broken bool) string {
+		if f.isCurrentUTF8 {
+			return hyphenate(string(runes[j:k]), string(softHyphen), broken)
+		}
+		return hyphenate(s[j:k], "\xad", broken)
+	}
 	sep := -1
+	sepSpace := false // whether the line ends at a space, which is dropped
 	i := 0
 	j := 0
 	l := 0.0
 	nl := 1
 	for i < nb {
-		// Get next character
-		var c rune
-		if f.isCurrentUTF8 {
-			c = []rune(s)[i]
-		} else {
-			c = rune(byte(s[i]))
+		if newLine {
+			span()
 		}
+		// Get next character
+		c := runes[i]
 		if c == '\n' {
 			// Explicit line break
-			if f.isCurrentUTF8 {
-				f.CellFormat(w, h, string([]rune(s)[j:i]), "", 2, "", false, link, linkStr)
-			} else {
-				f.CellFormat(w, h, s[j:i], "", 2, "", false, link, linkStr)
-			}
+			f.CellFormat(w, h, text(j, i, false), "", 2, "", false, link, linkStr)
 			i++
 			sep = -1
 			j = i
 			l = 0.0
-			if nl == 1 {
-				f.x = f.lMargin
-				w = f.w - f.rMargin - f.x
-				wmax = (w - 2*f.cMargin) * 1000 / f.fontSize
-			}
+			f.x = f.lMargin
+			newLine = true
 			nl++
 			continue
 		}
-		if c == ' ' {
-			sep = i
+		if brk[i] && (c == ' ' || i > j) {
+			sep, sepSpace = i, c == ' '
+		}
+		if c != softHyphen {
+			l += float64(f.capWidth(int(c), f.charWidth(c)))
 		}
-		l += float64(cw[int(c)])
 		if l > wmax {
 			// Automatic line break
 			if sep == -1 {
@@ -1220,8 +1497,7 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 					// Move to next line
 					f.x = f.lMargin
 					f.y += h
-					w = f.w - f.rMargin - f.x
-					wmax = (w - 2*f.cMargin) * 1000 / f.fontSize
+					newLine = true
 					i++
 					nl++
 					continue
@@ -1229,27 +1505,19 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 				if i == j {
 					i++
 				}
-				if f.isCurrentUTF8 {
-					f.CellFormat(w, h, string([]rune(s)[j:i]), "", 2, "", false, link, linkStr)
-				} else {
-					f.CellFormat(w, h, s[j:i], "", 2, "", false, link, linkStr)
-				}
+				f.CellFormat(w, h, text(j, i, true), "", 2, "", false, link, linkStr)
 			} else {
-				if f.isCurrentUTF8 {
-					f.CellFormat(w, h, string([]rune(s)[j:sep]), "", 2, "", false, link, linkStr)
-				} else {
-					f.CellFormat(w, h, s[j:sep], "", 2, "", false, link, linkStr)
+				f.CellFormat(w, h, text(j, sep, true), "", 2, "", false, link, linkStr)
+				i = sep
+				if sepSpace {
+					i++
 				}
-				i = sep + 1
 			}
 			sep = -1
 			j = i
 			l = 0.0
-			if nl == 1 {
-				f.x = f.lMargin
-				w = f.w - f.rMargin - f.x
-				wmax = (w - 2*f.cMargin) * 1000 / f.fontSize
-			}
+			f.x = f.lMargin
+			newLine = true
 			nl++
 		} else {
 			i++
@@ -1257,18 +1525,14 @@ func (f *Fpdf) write(h float64, txtStr string, link int, linkStr string) {
 	}
 	// Last chunk
 	if i != j {
-		if f.isCurrentUTF8 {
-			f.CellFormat(l/1000*f.fontSize, h, string([]rune(s)[j:]), "", 0, "", false, link, linkStr)
-		} else {
-			f.CellFormat(l/1000*f.fontSize, h, s[j:], "", 0, "", false, link, linkStr)
-		}
+		f.CellFormat(l/1000*f.fontSize, h, text(j, nb, false), "", 0, "", false, link, linkStr)
 	}
 }
 
 // Write prints text from the current position. When the right margin is
 // reached (or the \n character is met) a line break occurs and text continues
 // from the left margin. Upon method exit, the current position is left just at
-// the end of the text.
+// the end of the text. Lines are broken as described for MultiCell().
 //
 // It is possible to put a link on the text.
 //
@@ -1307,7 +1571,10 @@ func (f *Fpdf) WriteLinkID(h float64, displayStr string, linkID int) {
 // New().
 //
 // alignStr sees to horizontal alignment of the given textStr. The options are
-// "L", "C" and "R" (Left, Center, Right). The default is "L".
+// "L", "C", "R" and "J" (Left, Center, Right, Justified). The default is "L".
+// Justified lines start at the left margin and are stretched to width by
+// widening the spaces between words; the last line is left-aligned unless
+// SetJustifyLastLine(true) has been called.
 func (f *Fpdf) WriteAligned(width, lineHeight float64, textStr, alignStr string) {
 	lMargin, _, rMargin, _ := f.GetMargins()
 
@@ -1326,10 +1593,19 @@ func (f *Fpdf) WriteAligned(width, lineHeight float64, textStr, alignStr string)
 		}
 	}
 
-	for _, lineBt := range lines {
+	for i, lineBt := range lines {
 		lineStr := string(lineBt)
 		lineWidth := f.GetStringWidth(lineStr)
 
+		if alignStr == "J" {
+			last := i == len(lines)-1
+			if !last || f.justifyLastLine {
+				if f.writeJustified(width, lineHeight, lineStr, last) {
+					continue
+				}
+			}
+		}
+
 		switch alignStr {
 		case "C":
 			f.SetLeftMargin(lMargin + ((width - lineWidth) / 2))
@@ -1347,13 +1623,51 @@ func (f *Fpdf) WriteAligned(width, lineHeight float64, textStr, alignStr string)
 	}
 }
 
+// SetJustifyLastLine controls whether the last line of text written by
+// WriteAligned() with "J" alignment is justified like the other lines (true)
+// or left-aligned (false, the default).
+func (f *Fpdf) SetJustifyLastLine(on bool) {
+	f.justifyLastLine = on
+}
+
+// writeJustified prints lineStr at the left margin stretched to width and
+// reports whether it did so; a line without spaces cannot be stretched. The
+// current position moves to the next line unless last is true, in which case
+// it is left at the end of the line as with Write().
+func (f *Fpdf) writeJustified(width, lineHeight float64, lineStr string, last bool) bool {
+	for len(lineStr) > 0 && lineStr[len(lineStr)-1] == ' ' {
+		lineStr = lineStr[:len(lineStr)-1]
+	}
+	spaces := Count(lineStr, " ")
+	if spaces == 0 {
+		return false
+	}
+	ln := 1
+	if last {
+		ln = 0
+	}
+	f.x = f.lMargin
+	if f.isCurrentUTF8 {
+		// CellFormat spreads the free space over the word gaps itself
+		f.CellFormat(width, lineHeight, lineStr, "", ln, "J", false, 0, "")
+		return true
+	}
+	f.ws = (width - 2*f.cMargin - f.GetStringWidth(lineStr)) / float64(spaces)
+	f.putF64(f.ws*f.k, 3)
+	f.put(" Tw\n")
+	f.CellFormat(width, lineHeight, lineStr, "", ln, "L", false, 0, "")
+	f.ws = 0
+	f.out("0 Tw")
+	return true
+}
+
 // Ln performs a line break. The current abscissa goes back to the left margin
 // and the ordinate increases by the amount passed in parameter. A negative
 // value of h indicates the height of the last printed cell.
 //
 // This method is demonstrated in the example for MultiCell.
 func (f *Fpdf) Ln(h float64) {
-	f.x = f.lMargin
+	f.x = f.lineStart()
 	if h < 0 {
 		f.y += f.lasth
 	} else {
@@ -1376,12 +1690,29 @@ func (f *Fpdf) ImageTypeFromMime(mimeStr string) (tp string) {
 	case "image/gif":
 		tp = "gif"
 	default:
-		f.SetErrorf("unsupported image type: %s", mimeStr)
+		f.errorf("ImageTypeFromMime", "unsupported image type: %s", mimeStr)
 	}
 	return
 }
 
-func (f *Fpdf) imageOut(info *ImageInfoType, x, y, w, h float64, allowNegativeX, flow bool, link int, linkStr string) {
+func (f *Fpdf) imageOut(info *ImageInfoType, x, y, w, h float64, flow bool, options ImageOptions, link int, linkStr string) {
+	layer, inLayer := f.imageLayers[info.i]
+	crop := options.Crop
+	if crop.Wd <= 0 || crop.Ht <= 0 {
+		crop = ImageRect{Wd: info.w, Ht: info.h}
+	} else if crop.X < 0 || crop.Y < 0 || crop.X+crop.Wd > info.w+1e-9 || crop.Y+crop.Ht > info.h+1e-9 {
+		f.errorf("ImageOptions", "crop rectangle exceeds the image")
+		return
+	}
+	if options.Grayscale {
+		options.Tint = &RGBType{R: 255, G: 255, B: 255}
+	}
+	if options.Tint != nil {
+		info = f.tintedImage(info, *options.Tint)
+		if f.err != nil {
+			return
+		}
+	}
 	// Automatic width and height calculation if needed
 	if w == 0 && h == 0 {
 		// Put image at 96 dpi
@@ -1399,19 +1730,29 @@ func (f *Fpdf) imageOut(info *ImageInfoType, x, y, w, h float64, allowNegativeX,
 		h = -info.dpi
 	}
 	if w < 0 {
-		w = -info.w * 72.0 / w / f.k
+		w = -crop.Wd * 72.0 / w / f.k
 	}
 	if h < 0 {
-		h = -info.h * 72.0 / h / f.k
+		h = -crop.Ht * 72.0 / h / f.k
 	}
 	if w == 0 {
-		w = h * info.w / info.h
+		w = h * crop.Wd / crop.Ht
 	}
 	if h == 0 {
-		h = w * info.h / info.w
+		h = w * crop.Ht / crop.Wd
+	}
+	// A zero dimension of the image or its crop rectangle makes the size
+	// infinite or not a number
+	if !f.strictFinite("Image", "width height", w, h) {
+		return
 	}
 	// Flowing mode
 	if flow {
+		zoneX := x // abscissa checked against exclusion zones
+		if zoneX < 0 && !options.AllowNegativePosition {
+			zoneX = f.x
+		}
+		f.y = f.exclusionY(zoneX, w, f.y, h)
 		if f.y+h > f.pageBreakTrigger && !f.inHeader && !f.inFooter && f.acceptPageBreak() {
 			// Automatic page break
 			x2 := f.x
@@ -1420,28 +1761,52 @@ func (f *Fpdf) imageOut(info *ImageInfoType, x, y, w, h float64, allowNegativeX,
 				return
 			}
 			f.x = x2
+			f.y = f.exclusionY(zoneX, w, f.y, h)
 		}
 		y = f.y
 		f.y += h
 	}
-	if !allowNegativeX {
+	if !options.AllowNegativePosition {
 		if x < 0 {
 			x = f.x
 		}
 	}
+	if x < 0 || y < 0 || x+w > f.w || y+h > f.h {
+		f.warnf("Image", "image extends beyond the page and is clipped")
+	}
 	// dbg("h %.2f", h)
 	// q 85.04 0 0 NaN 28.35 NaN cm /I2 Do Q
 	// f.outf("q %.5f 0 0 %.5f %.5f %.5f cm /I%s Do Q", w*f.k, h*f.k, x*f.k, (f.h-(y+h))*f.k, info.i)
-	const prec = 5
+	f.extend(x, y, x+w, y+h)
+	if inLayer {
+		f.outf("/OC /OC%d BDC", layer)
+	}
+	// A cropped image is drawn at the size that gives the crop rectangle the
+	// requested size, clipped to that rectangle.
+	imgX, imgY, imgW, imgH := x, y, w, h
+	cropped := crop.Wd != info.w || crop.Ht != info.h
+	if cropped {
+		sx, sy := w/crop.Wd, h/crop.Ht
+		imgX, imgY = x-crop.X*sx, y-crop.Y*sy
+		imgW, imgH = info.w*sx, info.h*sy
+	}
+	prec := f.prec.Matrix
 	f.put("q ")
-	f.putF64(w*f.k, prec)
+	if cropped {
+		f.putF64s(f.prec.Path, x*f.k, (f.h-(y+h))*f.k, w*f.k, h*f.k)
+		f.put("re W n\n")
+	}
+	f.putF64(imgW*f.k, prec)
 	f.put(" 0 0 ")
-	f.putF64(h*f.k, prec)
+	f.putF64(imgH*f.k, prec)
 	f.put(" ")
-	f.putF64(x*f.k, prec)
+	f.putF64(imgX*f.k, prec)
 	f.put(" ")
-	f.putF64((f.h-(y+h))*f.k, prec)
+	f.putF64((f.h-(imgY+imgH))*f.k, prec)
 	f.put(" cm /I" + info.i + " Do Q\n")
+	if inLayer {
+		f.out("EMC")
+	}
 	if link > 0 || len(linkStr) > 0 {
 		f.newLink(x, y, w, h, link, linkStr)
 	}
@@ -1496,14 +1861,14 @@ func (f *Fpdf) Image(imageNameStr string, x, y, w, h float64, flow bool, tp stri
 // AddLink()), the image will be a clickable internal link. Otherwise, if
 // linkStr specifies a URL, the image will be a clickable external link.
 func (f *Fpdf) ImageOptions(imageNameStr string, x, y, w, h float64, flow bool, options ImageOptions, link int, linkStr string) {
-	if f.err != nil {
+	if f.err != nil || !f.strictFinite("ImageOptions", "x y w h", x, y, w, h) {
 		return
 	}
 	info := f.RegisterImageOptions(imageNameStr, options)
 	if f.err != nil {
 		return
 	}
-	f.imageOut(info, x, y, w, h, options.AllowNegativePosition, flow, link, linkStr)
+	f.imageOut(info, x, y, w, h, flow, options, link, linkStr)
 }
 
 // RegisterImageReader registers an image, reading it from Reader r, adding it
@@ -1533,10 +1898,28 @@ func (f *Fpdf) RegisterImageReader(imgName, tp string, r io.Reader) (info *Image
 //
 // AllowNegativePosition can be set to true in order to prevent the default
 // coercion of negative x values to the current x position.
+//
+// Crop, Tint and Grayscale apply when the image is placed with
+// ImageOptions(), without changing the registered image. Crop shows only a
+// rectangle of the image, in pixels from its upper left corner, which is
+// then the part sized by the w and h parameters. Tint renders the image in
+// shades of a color, from black for the darkest colors to the tint for white,
+// and Grayscale in shades of gray, as for thumbnails or disabled icons. A
+// tinted image is stored once more in the PDF file for each tint it is
+// placed with.
 type ImageOptions struct {
 	ImageType             string
 	ReadDpi               bool
 	AllowNegativePosition bool
+	Crop                  ImageRect // part of the image to place; the zero value places the whole image
+	Tint                  *RGBType  // color of the shades the image is rendered in, nil for its own colors
+	Grayscale             bool      // render the image in shades of gray, like a white Tint
+}
+
+// ImageRect is a rectangle of an image in pixels, X and Y locating its upper
+// left corner.
+type ImageRect struct {
+	X, Y, Wd, Ht float64
 }
 
 // RegisterImageOptionsReader registers an image, reading it from Reader r, adding it
@@ -1557,7 +1940,7 @@ func (f *Fpdf) RegisterImageOptionsReader(imgName string, options ImageOptions,
 
 	// First use of this image, get info
 	if options.ImageType == "" {
-		f.err = Errf("image type should be specified if reading from custom reader")
+		f.errorf("RegisterImageOptionsReader", "image type should be specified if reading from custom reader")
 		return
 	}
 	options.ImageType = Convert(options.ImageType).ToLower().String()
@@ -1572,13 +1955,15 @@ func (f *Fpdf) RegisterImageOptionsReader(imgName string, options ImageOptions,
 	case "gif":
 		info = f.parsegif(r)
 	default:
-		f.err = Errf("unsupported image type: %s", options.ImageType)
+		f.errorf("RegisterImageOptionsReader", "unsupported image type: %s", options.ImageType)
 	}
 	if f.err != nil {
 		return
 	}
 
-	if info.i, f.err = generateImageID(info); f.err != nil {
+	var err error
+	if info.i, err = generateImageID(info); err != nil {
+		f.setError("RegisterImageOptionsReader", err)
 		return
 	}
 	f.images[imgName] = info
@@ -1614,7 +1999,7 @@ func (f *Fpdf) RegisterImageOptions(fileStr string, options ImageOptions) (info
 
 	data, err := f.readFile(fileStr)
 	if err != nil {
-		f.err = err
+		f.setError("RegisterImageOptions", err)
 		return
 	}
 
@@ -1622,7 +2007,7 @@ func (f *Fpdf) RegisterImageOptions(fileStr string, options ImageOptions) (info
 	if options.ImageType == "" {
 		pos := LastIndex(fileStr, ".")
 		if pos < 0 {
-			f.err = Errf("image file has no extension and no type was specified: %s", fileStr)
+			f.errorf("RegisterImageOptions", "image file has no extension and no type was specified: %s", fileStr)
 			return
 		}
 		options.ImageType = fileStr[pos+1:]
@@ -1658,6 +2043,28 @@ func (f *Fpdf) escape(s string) string {
 	return s
 }
 
+// escapeName returns s escaped for a name object, without the leading slash:
+// the delimiters, the number sign and the bytes outside the printable ASCII
+// range are written as a number sign followed by their hexadecimal code, so
+// that a name made of user text cannot end early.
+func escapeName(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b []byte
+	for j := 0; j < len(s); j++ {
+		c := s[j]
+		switch c {
+		case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%', '#':
+		default:
+			if c > ' ' && c <= '~' {
+				b = append(b, c)
+				continue
+			}
+		}
+		b = append(b, '#', hexDigits[c>>4], hexDigits[c&15])
+	}
+	return string(b)
+}
+
 // textstring formats a text string
 func (f *Fpdf) textstring(s string) string {
 	if f.protect.encrypted {
@@ -1689,21 +2096,24 @@ func (f *Fpdf) SetUnderlineThickness(thickness float64) {
 	f.userUnderlineThickness = thickness
 }
 
-// Underline text
-func (f *Fpdf) dounderline(x, y float64, txt string) string {
+// Underline text of width w
+func (f *Fpdf) dounderline(x, y, w float64) string {
 	up := float64(f.currentFont.Up)
 	ut := float64(f.currentFont.Ut) * f.userUnderlineThickness
-	w := f.GetStringWidth(txt) + f.ws*float64(blankCount(txt))
-	return sprintf("%.2f %.2f %.2f %.2f re f", x*f.k,
-		(f.h-(y-up/1000*f.fontSize))*f.k, w*f.k, -ut/1000*f.fontSizePt)
+	return f.decorationLine(f.decoration.Underline, x*f.k,
+		(f.h-(y-up/1000*f.fontSize))*f.k, w*f.k, ut/1000*f.fontSizePt)
 }
 
-func (f *Fpdf) dostrikeout(x, y float64, txt string) string {
+func (f *Fpdf) dostrikeout(x, y, w float64) string {
 	up := float64(f.currentFont.Up)
 	ut := float64(f.currentFont.Ut)
-	w := f.GetStringWidth(txt) + f.ws*float64(blankCount(txt))
-	return sprintf("%.2f %.2f %.2f %.2f re f", x*f.k,
-		(f.h-(y+4*up/1000*f.fontSize))*f.k, w*f.k, -ut/1000*f.fontSizePt)
+	return f.decorationLine(f.decoration.Strikeout, x*f.k,
+		(f.h-(y+4*up/1000*f.fontSize))*f.k, w*f.k, ut/1000*f.fontSizePt)
+}
+
+// decorationWidth returns the width of the underline and strikeout of txt.
+func (f *Fpdf) decorationWidth(txt string) float64 {
+	return f.GetStringWidth(txt) + f.ws*float64(blankCount(txt))
 }
 
 func (f *Fpdf) newImageInfo() *ImageInfoType {
@@ -1721,14 +2131,14 @@ func (f *Fpdf) parsejpg(r io.Reader) (info *ImageInfoType) {
 	)
 	_, err = data.ReadFrom(r)
 	if err != nil {
-		f.err = err
+		f.setError("RegisterImageOptionsReader", err)
 		return
 	}
 	info.data = data.Bytes()
 
 	config, err := jpeg.DecodeConfig(bytes.NewReader(info.data))
 	if err != nil {
-		f.err = err
+		f.setError("RegisterImageOptionsReader", err)
 		return
 	}
 	info.w = float64(config.Width)
@@ -1743,7 +2153,7 @@ func (f *Fpdf) parsejpg(r io.Reader) (info *ImageInfoType) {
 	case color.CMYKModel:
 		info.cs = "DeviceCMYK"
 	default:
-		f.err = Errf("image JPEG buffer has unsupported color space (%v)", config.ColorModel)
+		f.errorf("RegisterImageOptionsReader", "image JPEG buffer has unsupported color space (%v)", config.ColorModel)
 		return
 	}
 	return
@@ -1753,7 +2163,7 @@ func (f *Fpdf) parsejpg(r io.Reader) (info *ImageInfoType) {
 func (f *Fpdf) parsepng(r io.Reader, readdpi bool) (info *ImageInfoType) {
 	buf, err := newRBuffer(r)
 	if err != nil {
-		f.err = err
+		f.setError("RegisterImageOptionsReader", err)
 		return
 	}
 	return f.parsepngstream(buf, readdpi)
@@ -1771,13 +2181,20 @@ func (f *Fpdf) newobj() {
 	f.outf("%d 0 obj", f.n)
 }
 
+// putstream writes the data b of a stream, encrypted if the document is
+// protected. b is left unchanged.
 func (f *Fpdf) putstream(b []byte) {
 	// dbg("putstream")
+	f.out("stream")
+	var w io.Writer = &f.buffer
+	if f.state == 2 {
+		w = f.pages[f.page]
+	}
 	if f.protect.encrypted {
-		f.protect.rc4(uint32(f.n), &b)
+		w = f.protect.streamWriter(uint32(f.n), w)
 	}
-	f.out("stream")
-	f.out(string(b))
+	must(w.Write(b))
+	f.out("")
 	f.out("endstream")
 }
 
@@ -1833,13 +2250,27 @@ func (f *Fpdf) outf(fmtStr string, args ...any) {
 }
 
 func (f *Fpdf) putF64(v float64, prec int) {
-	f.put(f.fmtF64(v, prec))
+	f.numBuf = appendFloat(f.numBuf[:0], v, prec)
+	if f.state == 2 {
+		f.pages[f.page].Write(f.numBuf)
+	} else {
+		f.buffer.Write(f.numBuf)
+	}
+}
+
+// putF64s writes each of vals with precision prec followed by a space.
+func (f *Fpdf) putF64s(prec int, vals ...float64) {
+	f.numBuf = appendFloats(f.numBuf[:0], prec, vals...)
+	if f.state == 2 {
+		f.pages[f.page].Write(f.numBuf)
+	} else {
+		f.buffer.Write(f.numBuf)
+	}
 }
 
 // fmtF64 converts the floating-point number f to a string with precision prec.
 func (f *Fpdf) fmtF64(v float64, prec int) string {
-	// Usar tinystring para formatear float con precisión
-	return Convert(v).Round(prec).String()
+	return string(appendFloat(nil, v, prec))
 }
 
 func (f *Fpdf) putInt(v int) {
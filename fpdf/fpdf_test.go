@@ -76,7 +76,7 @@ func Test_Basic(t *testing.T) {
 	pdf.Cell(40, 10, "Hello World!")
 	fileStr := Filename("Test_Basic")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Basic.pdf
 }
@@ -108,7 +108,7 @@ func Test_AddPage(t *testing.T) {
 	}
 	fileStr := Filename("Test_AddPage")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_AddPage.pdf
 }
@@ -184,7 +184,7 @@ func Test_MultiCell(t *testing.T) {
 	printChapter(2, "THE PROS AND CONS", TextFile("20k_c2.txt"))
 	fileStr := Filename("Test_MultiCell")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_MultiCell.pdf
 }
@@ -290,7 +290,7 @@ func Test_SetLeftMargin(t *testing.T) {
 	printChapter(2, "THE PROS AND CONS", TextFile("20k_c2.txt"))
 	fileStr := Filename("Test_SetLeftMargin_multicolumn")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetLeftMargin_multicolumn.pdf
 }
@@ -371,7 +371,7 @@ func Test_SplitLines_tables(t *testing.T) {
 
 	fileStr := Filename("Test_SplitLines_tables")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SplitLines_tables.pdf
 }
@@ -508,7 +508,7 @@ func Test_CellFormat_tables(t *testing.T) {
 	fancyTable()
 	fileStr := Filename("Test_CellFormat_tables")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_CellFormat_tables.pdf
 }
@@ -543,7 +543,7 @@ func Test_HTMLBasicNew(t *testing.T) {
 	html.Write(lineHt, htmlStr)
 	fileStr := Filename("Test_HTMLBasicNew")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_HTMLBasicNew.pdf
 }
@@ -557,7 +557,7 @@ func Test_AddFont(t *testing.T) {
 	pdf.Cell(0, 10, "Enjoy new fonts with FPDF!")
 	fileStr := Filename("Test_AddFont")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_AddFont.pdf
 }
@@ -591,7 +591,7 @@ func Test_WriteAligned(t *testing.T) {
 	pdf.WriteAligned(pageWidth, 35, line, "L")
 	fileStr := Filename("Test_WriteAligned")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_WriteAligned.pdf
 }
@@ -633,7 +633,7 @@ func Test_ImageOptions(t *testing.T) {
 	pdf.ImageOptions(ImageFile("logo.png"), -10, 50, 30, 0, false, opt, 0, "")
 	fileStr := Filename("Test_ImageOptions")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_ImageOptions.pdf
 }
@@ -663,7 +663,7 @@ func Test_RegisterImageOptionsReader(t *testing.T) {
 		}
 		err = pdf.OutputFileAndClose(pdfStr)
 	}
-	SummaryCompare(err, pdfStr)
+	SummaryCompare(t, err, pdfStr)
 	// Output:
 	// Successfully generated pdf/Test_RegisterImageOptionsReader.pdf
 }
@@ -723,7 +723,7 @@ func Test_SetAcceptPageBreakFunc(t *testing.T) {
 	}
 	fileStr := Filename("Test_SetAcceptPageBreakFunc_landscape")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetAcceptPageBreakFunc_landscape.pdf
 }
@@ -732,11 +732,14 @@ func Test_SetAcceptPageBreakFunc(t *testing.T) {
 func Test_SetKeywords(t *testing.T) {
 	var err error
 	fileStr := Filename("Test_SetKeywords")
+	// The font files are generated out of the source tree, whose font files
+	// the other examples compare with their references
+	fontDir := t.TempDir()
 	err = fpdf.MakeFont(FontFile("CalligrapherRegular.pfb"),
-		FontFile("cp1252.map"), FontsDirName(), nil, true)
+		FontFile("cp1252.map"), fontDir, nil, true)
 	if err == nil {
 		pdf := NewDocPdfTest()
-		pdf.SetFontLocation(FontsDirName())
+		pdf.SetFontLocation(fontDir)
 		pdf.SetTitle("世界", true)
 		pdf.SetAuthor("世界", true)
 		pdf.SetSubject("世界", true)
@@ -748,7 +751,7 @@ func Test_SetKeywords(t *testing.T) {
 		pdf.Writef(5, "\x95 %s \x95", pdf)
 		err = pdf.OutputFileAndClose(fileStr)
 	}
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetKeywords.pdf
 }
@@ -831,7 +834,7 @@ func Test_Circle(t *testing.T) {
 
 	fileStr := Filename("Test_Circle_figures")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Circle_figures.pdf
 }
@@ -883,7 +886,7 @@ func Test_SetAlpha(t *testing.T) {
 	}
 	fileStr := Filename("Test_SetAlpha_transparency")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetAlpha_transparency.pdf
 }
@@ -906,7 +909,7 @@ func Test_LinearGradient(t *testing.T) {
 	pdf.Rect(115, 120, 75, 75, "D")
 	fileStr := Filename("Test_LinearGradient_gradient")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_LinearGradient_gradient.pdf
 }
@@ -986,7 +989,7 @@ func Test_ClipText(t *testing.T) {
 
 	fileStr := Filename("Test_ClipText")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_ClipText.pdf
 }
@@ -1017,7 +1020,7 @@ func Test_PageSize(t *testing.T) {
 	}
 	fileStr := Filename("Test_PageSize")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// 0:   6.00 in,   6.00 in
 	// 1:  12.00 in,   3.00 in
@@ -1045,7 +1048,7 @@ func Test_Bookmark(t *testing.T) {
 	pdf.Cell(0, 6, "Paragraph 3")
 	fileStr := Filename("Test_Bookmark")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Bookmark.pdf
 }
@@ -1167,7 +1170,7 @@ func Test_TransformBegin(t *testing.T) {
 
 	fileStr := Filename("Test_TransformBegin")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_TransformBegin.pdf
 }
@@ -1265,7 +1268,7 @@ func Test_SplitLines(t *testing.T) {
 	}
 	fileStr := Filename("Test_Splitlines")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Splitlines.pdf
 }
@@ -1313,7 +1316,7 @@ func Test_SVGBasicWrite(t *testing.T) {
 	}
 	fileStr := Filename("Test_SVGBasicWrite")
 	err = pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SVGBasicWrite.pdf
 }
@@ -1363,7 +1366,7 @@ func Test_SVGBasicDraw(t *testing.T) {
 	}
 	fileStr := Filename("Test_SVGBasicDraw")
 	err = pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SVGBasicDraw.pdf
 }
@@ -1416,7 +1419,7 @@ func Test_CellFormat_align(t *testing.T) {
 	formatRect(pdf, recListBaseline)
 	fileStr := Filename("Test_CellFormat_align")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Generalized font loader reading calligra.json
 	// Generalized font loader reading calligra.z
@@ -1450,7 +1453,7 @@ func Test_CellFormat_codepageescape(t *testing.T) {
 	write("For\xe5rsj\xe6vnd\xf8gn / Efter\xe5rsj\xe6vnd\xf8gn")
 	fileStr := Filename("Test_CellFormat_codepageescape")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_CellFormat_codepageescape.pdf
 }
@@ -1492,7 +1495,7 @@ func Test_CellFormat_codepage(t *testing.T) {
 
 	fileStr := Filename("Test_CellFormat_codepage")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_CellFormat_codepage.pdf
 }
@@ -1506,7 +1509,7 @@ func Test_SetProtection(t *testing.T) {
 	pdf.Write(10, "Password-protected.")
 	fileStr := Filename("Test_SetProtection")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetProtection.pdf
 }
@@ -1556,7 +1559,7 @@ func Test_Polygon(t *testing.T) {
 	}
 	fileStr := Filename("Test_Polygon")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Polygon.pdf
 }
@@ -1596,7 +1599,7 @@ func Test_AddLayer(t *testing.T) {
 
 	fileStr := Filename("Test_AddLayer")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_AddLayer.pdf
 }
@@ -1639,7 +1642,7 @@ func Test_RegisterImageReader(t *testing.T) {
 	}
 	fileStr := Filename("Test_RegisterImageReader_url")
 	err = pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_RegisterImageReader_url.pdf
 
@@ -1728,7 +1731,7 @@ func Test_Beziergon(t *testing.T) {
 	pdf.Beziergon(curveList, "D")
 	fileStr := Filename("Test_Beziergon")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Beziergon.pdf
 
@@ -1747,7 +1750,7 @@ func Test_SetFontLoader(t *testing.T) {
 	pdf.Cell(0, 10, "Load fonts from any source")
 	fileStr := Filename("Test_SetFontLoader")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Generalized font loader reading calligra.json
 	// Generalized font loader reading calligra.z
@@ -1770,7 +1773,7 @@ func Test_MoveTo(t *testing.T) {
 	pdf.DrawPath("DF")
 	fileStr := Filename("Test_MoveTo_path")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_MoveTo_path.pdf
 }
@@ -1811,7 +1814,7 @@ func Test_SetLineJoinStyle(t *testing.T) {
 	}
 	fileStr := Filename("Test_SetLineJoinStyle_caps")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetLineJoinStyle_caps.pdf
 }
@@ -1887,7 +1890,7 @@ func Test_DrawPath(t *testing.T) {
 
 	fileStr := Filename("Test_DrawPath_fill")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_DrawPath_fill.pdf
 }
@@ -1901,7 +1904,7 @@ func Test_AddFontFromBytes(t *testing.T) {
 	pdf.Cell(40, 10, "Hello World With Embedded Font!")
 	fileStr := Filename("Test_EmbeddedFont")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_EmbeddedFont.pdf
 }
@@ -1944,7 +1947,7 @@ func Test_ClipRect(t *testing.T) {
 	}
 	fileStr := Filename("Test_ClippedTableCells")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_ClippedTableCells.pdf
 }
@@ -1995,7 +1998,7 @@ func Test_Rect(t *testing.T) {
 	}
 	fileStr := Filename("Test_WrappedTableCells")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_WrappedTableCells.pdf
 }
@@ -2009,7 +2012,7 @@ func Test_SetJavascript(t *testing.T) {
 	pdf.Write(10, "Auto-print.")
 	fileStr := Filename("Test_SetJavascript")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetJavascript.pdf
 }
@@ -2023,7 +2026,7 @@ func Test_AddSpotColor(t *testing.T) {
 	pdf.Rect(80, 40, 50, 50, "F")
 	fileStr := Filename("Test_AddSpotColor")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_AddSpotColor.pdf
 }
@@ -2055,7 +2058,7 @@ func Test_RegisterAlias(t *testing.T) {
 
 	fileStr := Filename("Test_RegisterAlias")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_RegisterAlias.pdf
 }
@@ -2089,7 +2092,7 @@ func Test_RegisterAlias_utf8(t *testing.T) {
 
 	fileStr := Filename("Test_RegisterAliasUTF8")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_RegisterAliasUTF8.pdf
 }
@@ -2143,7 +2146,7 @@ func Test_Grid(t *testing.T) {
 
 	fileStr := Filename("Test_Grid")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Grid.pdf
 }
@@ -2184,7 +2187,7 @@ func Test_SetPageBox(t *testing.T) {
 	pdf.Write(fontsize, "This will be displayed in cropped output")
 	fileStr := Filename("Test_PageBox")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_PageBox.pdf
 }
@@ -2234,7 +2237,7 @@ func Test_SubWrite(t *testing.T) {
 
 	fileStr := Filename("Test_SubWrite")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SubWrite.pdf
 }
@@ -2292,7 +2295,7 @@ func Test_SetPage(t *testing.T) {
 
 	fileStr := Filename("Test_SetPage")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetPage.pdf
 }
@@ -2327,7 +2330,7 @@ func Test_SetFillColor(t *testing.T) {
 
 	fileStr := Filename("Test_SetFillColor")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetFillColor.pdf
 }
@@ -2368,7 +2371,7 @@ func Test_TransformRotate(t *testing.T) {
 
 	fileStr := Filename("Test_RotateText")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_RotateText.pdf
 }
@@ -2410,7 +2413,7 @@ func Test_AddUTF8Font(t *testing.T) {
 
 		}
 	}
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_AddUTF8Font.pdf
 }
@@ -2458,7 +2461,7 @@ func Test_UTF8CutFont(t *testing.T) {
 			os.Remove(subFontFileStr)
 		}
 	}
-	SummaryCompare(err, pdfFileStr)
+	SummaryCompare(t, err, pdfFileStr)
 	// Output:
 	// Successfully generated pdf/Test_UTF8CutFont.pdf
 }
@@ -2510,7 +2513,7 @@ func Test_RoundedRect(t *testing.T) {
 
 	fileStr := Filename("Test_RoundedRect")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_RoundedRect.pdf
 }
@@ -2529,7 +2532,7 @@ func Test_Cell_strikeout(t *testing.T) {
 
 	fileStr := Filename("Test_Cell_strikeout")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_Cell_strikeout.pdf
 }
@@ -2564,7 +2567,7 @@ func Test_SetTextRenderingMode(t *testing.T) {
 
 	fileStr := Filename("Test_TextRenderingMode")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_TextRenderingMode.pdf
 }
@@ -2581,7 +2584,7 @@ func TestIssue0316(t *testing.T) {
 	pdf.Cell(40, 10, "Hello World!")
 	fileStr := Filename("TestIssue0316")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	pdf.AddPage()
 	if !bytes.Equal(fontBytes, ofontBytes) {
 		t.Fatal("Font data changed during pdf generation")
@@ -2654,7 +2657,7 @@ func Test_SetAttachments(t *testing.T) {
 
 	fileStr := Filename("Test_EmbeddedFiles")
 	err = pdf.OutputFileAndClose(fileStr)
-	summaryCompare(err, fileStr) // FIXME(sbinet): SetAttachments doesn't produce stable output across *Nix/Windows.
+	summaryCompare(t, err, fileStr) // FIXME(sbinet): SetAttachments doesn't produce stable output across *Nix/Windows.
 	// Output:
 	// Successfully generated pdf/Test_EmbeddedFiles.pdf
 }
@@ -2683,7 +2686,7 @@ func Test_AddAttachmentAnnotation(t *testing.T) {
 
 	fileStr := Filename("Test_FileAnnotations")
 	err = pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_FileAnnotations.pdf
 }
@@ -2699,7 +2702,7 @@ func Test_SetModificationDate(t *testing.T) {
 	pdf.SetModificationDate(time.Date(2000, 1, 2, 10, 22, 30, 0, time.UTC))
 	fileStr := Filename("Test_SetModificationDate")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetModificationDate.pdf
 }
@@ -2717,7 +2720,7 @@ func Test_RoundedRect_rotated(t *testing.T) {
 
 	fileStr := Filename("Test_RoundedRect_rotated")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_RoundedRect_rotated.pdf
 }
@@ -2775,7 +2778,7 @@ func Test_SetXmpMetadata(t *testing.T) {
 
 	fileStr := Filename("Test_SetXmpMetadata")
 	err := pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_SetXmpMetadata.pdf
 }
@@ -2798,7 +2801,7 @@ func Test_AddOutputIntent(t *testing.T) {
 	})
 	fileStr := Filename("Test_AddOutputIntent")
 	err = pdf.OutputFileAndClose(fileStr)
-	SummaryCompare(err, fileStr)
+	SummaryCompare(t, err, fileStr)
 	// Output:
 	// Successfully generated pdf/Test_AddOutputIntent.pdf
 }
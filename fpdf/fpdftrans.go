@@ -2,8 +2,6 @@ package fpdf
 
 import (
 	"math"
-
-	. "github.com/tinywasm/fmt"
 )
 
 // Routines in this file are translated from the work of Moritz Wagner and
@@ -59,7 +57,7 @@ func (f *Fpdf) TransformScaleXY(s, x, y float64) {
 // The TransformBegin() example demonstrates this method.
 func (f *Fpdf) TransformScale(scaleWd, scaleHt, x, y float64) {
 	if scaleWd == 0 || scaleHt == 0 {
-		f.err = Errf("scale factor cannot be zero")
+		f.errorf("TransformScale", "scale factor cannot be zero")
 		return
 	}
 	y = (f.h - y) * f.k
@@ -174,7 +172,7 @@ func (f *Fpdf) TransformSkewY(angleY, x, y float64) {
 // The TransformBegin() example demonstrates this method.
 func (f *Fpdf) TransformSkew(angleX, angleY, x, y float64) {
 	if angleX <= -90 || angleX >= 90 || angleY <= -90 || angleY >= 90 {
-		f.err = Errf("skew values must be between -90° and 90°")
+		f.errorf("TransformSkew", "skew values must be between -90° and 90°")
 		return
 	}
 	x *= f.k
@@ -194,10 +192,10 @@ func (f *Fpdf) TransformSkew(angleX, angleY, x, y float64) {
 // methods such as TransformRotate() and TransformMirrorVertical() instead.
 func (f *Fpdf) Transform(tm TransformMatrix) {
 	if f.transformNest > 0 {
-		f.outf("%.5f %.5f %.5f %.5f %.5f %.5f cm",
-			tm.A, tm.B, tm.C, tm.D, tm.E, tm.F)
+		f.putF64s(f.prec.Matrix, tm.A, tm.B, tm.C, tm.D, tm.E, tm.F)
+		f.put("cm\n")
 	} else if f.err == nil {
-		f.err = Errf("transformation context is not active")
+		f.errorf("Transform", "transformation context is not active")
 	}
 }
 
@@ -209,6 +207,6 @@ func (f *Fpdf) TransformEnd() {
 		f.transformNest--
 		f.out("Q")
 	} else {
-		f.err = Errf("error attempting to end transformation operation out of sequence")
+		f.errorf("TransformEnd", "error attempting to end transformation operation out of sequence")
 	}
 }
@@ -17,6 +17,27 @@ func floatEqual(a, b float64) bool {
 	return math.Abs(a-b) <= floatEpsilon
 }
 
+func TestAtPageTop(t *testing.T) {
+	pdf := NewDocPdfTest()
+	if pdf.AtPageTop() {
+		t.Errorf("no page added: got=true, want=false")
+	}
+	pdf.SetFont("Arial", "", 12)
+	pdf.SetHeaderFunc(func() {
+		pdf.Cell(0, 15, "Header")
+		pdf.Ln(20)
+	})
+	pdf.AddPage()
+	if got, want := pdf.AtPageTop(), true; got != want {
+		t.Errorf("after AddPage: got=%v, want=%v", got, want)
+	}
+	pdf.Cell(0, 10, "Body")
+	pdf.Ln(10)
+	if got, want := pdf.AtPageTop(), false; got != want {
+		t.Errorf("after content: got=%v, want=%v", got, want)
+	}
+}
+
 func TestGetAlpha(t *testing.T) {
 	pdf := NewDocPdfTest()
 	pdf.SetAlpha(0.17, "Luminosity")
@@ -303,6 +324,28 @@ func TestGetFontLocation(t *testing.T) {
 	}
 }
 
+func TestGetFontMetrics(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Helvetica", "", 10)
+
+	ascent, descent, capHeight, xHeight := pdf.GetFontMetrics()
+	_, size := pdf.GetFontSize()
+
+	// Helvetica: Ascent 718, Descent -207, CapHeight 718
+	if got, want := ascent, 0.718*size; math.Abs(got-want) > 1e-9 {
+		t.Errorf("invalid ascent: got=%v, want=%v", got, want)
+	}
+	if got, want := descent, -0.207*size; math.Abs(got-want) > 1e-9 {
+		t.Errorf("invalid descent: got=%v, want=%v", got, want)
+	}
+	if got, want := capHeight, 0.718*size; math.Abs(got-want) > 1e-9 {
+		t.Errorf("invalid capHeight: got=%v, want=%v", got, want)
+	}
+	if xHeight <= 0 || xHeight >= capHeight {
+		t.Errorf("invalid xHeight: got=%v", xHeight)
+	}
+}
+
 func TestGetFontSize(t *testing.T) {
 	pdf := NewDocPdfTest()
 	pdf.SetFontSize(19)
@@ -457,6 +500,36 @@ func TestGetModificationDate(t *testing.T) {
 	}
 }
 
+func TestGetPageBox(t *testing.T) {
+	pdf := fpdf.New(fpdf.POINT, "A4", "")
+	pdf.AddPage()
+	pdf.SetPageBox("trim", 36, 36, 523.28, 769.89)
+
+	trim := pdf.GetPageBox(1, "TrimBox")
+	if trim.X != 36 || trim.Y != 36 || !floatEqual(trim.Wd, 523.28) || !floatEqual(trim.Ht, 769.89) {
+		t.Errorf("invalid trim box: got=%+v", trim)
+	}
+
+	bleed := pdf.GetPageBox(1, "bleed")
+	if bleed.X != 0 || bleed.Y != 0 || !floatEqual(bleed.Wd, 595.28) || !floatEqual(bleed.Ht, 841.89) {
+		t.Errorf("bleed box does not default to the media box: got=%+v", bleed)
+	}
+
+	pdf.SetPageBox("crop", 18, 18, 559.28, 805.89)
+	bleed = pdf.GetPageBox(1, "bleed")
+	if bleed.X != 18 || bleed.Y != 18 || !floatEqual(bleed.Wd, 559.28) || !floatEqual(bleed.Ht, 805.89) {
+		t.Errorf("bleed box does not default to the crop box: got=%+v", bleed)
+	}
+
+	if pdf.GetPageBox(2, "trim"); !pdf.Err() {
+		t.Errorf("expected error for a missing page")
+	}
+	pdf.ClearError()
+	if pdf.GetPageBox(1, "paper"); !pdf.Err() {
+		t.Errorf("expected error for an invalid box type")
+	}
+}
+
 func TestGetPageSize(t *testing.T) {
 	pdf := fpdf.New(fpdf.POINT, "A4", "")
 
@@ -612,3 +685,29 @@ func TestGetY(t *testing.T) {
 		t.Errorf("invalid y coordinate: got=%v, want=%v", got, want)
 	}
 }
+
+func TestGetContentExtents(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.AddPage()
+	pdf.Rect(100, 200, 50, 20, "D")
+	pdf.Line(80, 250, 300, 260)
+	pdf.Circle(200, 400, 30, "F")
+	pdf.AddPage()
+	pdf.SetXY(50, 60)
+	pdf.CellFormat(100, 20, "", "1", 0, "", false, 0, "")
+	pdf.Image(ImageFile("logo.png"), 400, 500, 100, 0, false, "", 0, "")
+	pdf.AddPage()
+
+	box, ok := pdf.GetContentExtents(1)
+	if !ok || box.X != 80 || box.Y != 200 || box.Wd != 220 || box.Ht != 230 {
+		t.Errorf("invalid extents of page 1: got=%v %v", box, ok)
+	}
+	box, ok = pdf.GetContentExtents(2)
+	if !ok || box.X != 50 || box.Y != 60 || box.Wd != 450 {
+		t.Errorf("invalid extents of page 2: got=%v %v", box, ok)
+	}
+	if _, ok = pdf.GetContentExtents(3); ok {
+		t.Errorf("expected no extents for a blank page")
+	}
+}
@@ -5,6 +5,8 @@ package fpdf
 import (
 	"bytes"
 	"image"
+	"image/color"
+	"image/draw"
 	"image/gif"
 	"image/png"
 	"io"
@@ -14,20 +16,148 @@ import (
 func (f *Fpdf) parsegif(r io.Reader) (info *ImageInfoType) {
 	data, err := newRBuffer(r)
 	if err != nil {
-		f.err = err
+		f.setError("RegisterImageOptionsReader", err)
 		return
 	}
-	var img image.Image
-	img, err = gif.Decode(data)
+	g, err := gif.DecodeAll(data)
 	if err != nil {
-		f.err = err
+		f.setError("RegisterImageOptionsReader", err)
 		return
 	}
+	if len(g.Image) > 1 {
+		f.warnf("parsegif", "animated GIF has %d frames, only the first one is used; see RegisterGIFFrames", len(g.Image))
+	}
+	return f.gifpng(g.Image[0])
+}
+
+// gifpng converts a decoded GIF image to the image info of the equivalent
+// PNG image.
+func (f *Fpdf) gifpng(img image.Image) (info *ImageInfoType) {
 	pngBuf := new(bytes.Buffer)
-	err = png.Encode(pngBuf, img)
+	err := png.Encode(pngBuf, img)
 	if err != nil {
-		f.err = err
+		f.setError("RegisterImageOptionsReader", err)
 		return
 	}
 	return f.parsepngstream(&rbuffer{p: pngBuf.Bytes()}, false)
 }
+
+// parsegifframes extracts the info of every frame of an animated GIF, each
+// frame composed over the previous ones as an animation player shows it.
+func (f *Fpdf) parsegifframes(r io.Reader) (frames []*ImageInfoType) {
+	data, err := newRBuffer(r)
+	if err != nil {
+		f.setError("RegisterGIFFrames", err)
+		return
+	}
+	g, err := gif.DecodeAll(data)
+	if err != nil {
+		f.setError("RegisterGIFFrames", err)
+		return
+	}
+	if len(g.Image) == 0 {
+		f.errorf("RegisterGIFFrames", "GIF image has no frames")
+		return
+	}
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	// When every frame uses the same palette, typically the global one with
+	// the same transparent color, frames are composed on a paletted canvas so
+	// that each one is output as an indexed image with that palette, which is
+	// written only once.
+	palette := g.Image[0].Palette
+	for _, frame := range g.Image {
+		if !samePalette(frame.Palette, palette) {
+			palette = nil
+			break
+		}
+	}
+	var canvas draw.Image
+	if palette != nil {
+		canvas = image.NewPaletted(bounds, palette)
+		clearPaletted(canvas.(*image.Paletted), bounds, g.BackgroundIndex)
+	} else {
+		canvas = image.NewRGBA(bounds)
+	}
+
+	for j, frame := range g.Image {
+		var disposal byte
+		if j < len(g.Disposal) {
+			disposal = g.Disposal[j]
+		}
+		var previous draw.Image
+		if disposal == gif.DisposalPrevious {
+			previous = cloneCanvas(canvas)
+		}
+		rect := frame.Bounds().Intersect(bounds)
+		if dst, ok := canvas.(*image.Paletted); ok {
+			for y := rect.Min.Y; y < rect.Max.Y; y++ {
+				for x := rect.Min.X; x < rect.Max.X; x++ {
+					idx := frame.ColorIndexAt(x, y)
+					if _, _, _, a := palette[idx].RGBA(); a != 0 {
+						dst.SetColorIndex(x, y, idx)
+					}
+				}
+			}
+		} else {
+			draw.Draw(canvas, rect, frame, rect.Min, draw.Over)
+		}
+		info := f.gifpng(canvas)
+		if f.err != nil {
+			return nil
+		}
+		frames = append(frames, info)
+		switch disposal {
+		case gif.DisposalBackground:
+			if dst, ok := canvas.(*image.Paletted); ok {
+				clearPaletted(dst, rect, g.BackgroundIndex)
+			} else {
+				draw.Draw(canvas, rect, image.Transparent, image.Point{}, draw.Src)
+			}
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return
+}
+
+// samePalette reports whether the palettes a and b have the same colors.
+func samePalette(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for j := range a {
+		if a[j] != b[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// clearPaletted sets rect of img to its transparent color, or to the
+// background color if its palette has none.
+func clearPaletted(img *image.Paletted, rect image.Rectangle, background byte) {
+	idx := background
+	for j, c := range img.Palette {
+		if _, _, _, a := c.RGBA(); a == 0 {
+			idx = byte(j)
+			break
+		}
+	}
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetColorIndex(x, y, idx)
+		}
+	}
+}
+
+// cloneCanvas returns a copy of the canvas on which GIF frames are composed.
+func cloneCanvas(img draw.Image) draw.Image {
+	if p, ok := img.(*image.Paletted); ok {
+		c := image.NewPaletted(p.Rect, p.Palette)
+		copy(c.Pix, p.Pix)
+		return c
+	}
+	c := image.NewRGBA(img.Bounds())
+	draw.Draw(c, c.Rect, img, image.Point{}, draw.Src)
+	return c
+}
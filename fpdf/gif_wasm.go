@@ -8,6 +8,12 @@ import (
 
 // parsegif is a stub for WASM that returns an error
 func (f *Fpdf) parsegif(r io.Reader) (info *ImageInfoType) {
-	f.SetErrorf("GIF images are not supported in WASM")
+	f.errorf("RegisterImageOptionsReader", "GIF images are not supported in WASM")
+	return nil
+}
+
+// parsegifframes is a stub for WASM that returns an error
+func (f *Fpdf) parsegifframes(r io.Reader) (frames []*ImageInfoType) {
+	f.errorf("RegisterGIFFrames", "GIF images are not supported in WASM")
 	return nil
 }
@@ -0,0 +1,78 @@
+package fpdf
+
+import (
+	"bytes"
+	"math"
+)
+
+// RegisterGIFFrames registers every frame of the animated GIF image fileStr
+// as a separate image, as shown by an animation player at that point, and
+// returns the image names to be passed to ImageOptions(): fileStr followed by
+// "#1", "#2" and so on. Frames that are identical are stored only once in
+// the PDF file, as are the palettes they share. Registering a GIF image with
+// RegisterImageOptions() or ImageOptions() only uses its first frame.
+func (f *Fpdf) RegisterGIFFrames(fileStr string) (names []string) {
+	if f.err != nil {
+		return
+	}
+	if _, ok := f.images[fileStr+"#1"]; ok {
+		for j := 1; ; j++ {
+			name := sprintf("%s#%d", fileStr, j)
+			if _, ok = f.images[name]; !ok {
+				return
+			}
+			names = append(names, name)
+		}
+	}
+	data, err := f.readFile(fileStr)
+	if err != nil {
+		f.setError("RegisterGIFFrames", err)
+		return
+	}
+	frames := f.parsegifframes(bytes.NewReader(data))
+	if f.err != nil {
+		return
+	}
+	for j, info := range frames {
+		if info.i, err = generateImageID(info); err != nil {
+			f.setError("RegisterGIFFrames", err)
+			return nil
+		}
+		name := sprintf("%s#%d", fileStr, j+1)
+		f.images[name] = info
+		names = append(names, name)
+	}
+	return
+}
+
+// AddGIFFramePages adds pages that show the frames of the animated GIF image
+// fileStr in order, perPage frames per page. perPage takes the values
+// accepted by NUp(): 1 gives a page per frame and larger values a contact
+// sheet of frames laid out left to right and top to bottom. Each frame is
+// scaled to fit its cell within the page margins and centered in it.
+func (f *Fpdf) AddGIFFramePages(fileStr string, perPage int) {
+	grid := NUp(perPage)
+	if grid.cols == 0 {
+		f.errorf("AddGIFFramePages", "unsupported number of frames per page: %d", perPage)
+		return
+	}
+	names := f.RegisterGIFFrames(fileStr)
+	var cellW, cellH float64
+	for j, name := range names {
+		cell := j % perPage
+		if cell == 0 {
+			f.AddPage()
+			if f.err != nil {
+				return
+			}
+			cellW = (f.w - f.lMargin - f.rMargin) / float64(grid.cols)
+			cellH = (f.h - f.tMargin - f.bMargin) / float64(grid.rows)
+		}
+		info := f.images[name]
+		scale := math.Min(cellW/info.w, cellH/info.h)
+		w, h := info.w*scale, info.h*scale
+		x := f.lMargin + float64(cell%grid.cols)*cellW + (cellW-w)/2
+		y := f.tMargin + float64(cell/grid.cols)*cellH + (cellH-h)/2
+		f.ImageOptions(name, x, y, w, h, false, ImageOptions{}, 0, "")
+	}
+}
@@ -0,0 +1,93 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+// writeAnimatedGIF writes a 3 frame animation to a temporary file: a red
+// square, a blue corner that is then cleared and a single transparent pixel.
+func writeAnimatedGIF(t *testing.T) string {
+	palette := color.Palette{color.Transparent, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	red := image.NewPaletted(image.Rect(0, 0, 8, 8), palette)
+	for j := range red.Pix {
+		red.Pix[j] = 1
+	}
+	blue := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	for j := range blue.Pix {
+		blue.Pix[j] = 2
+	}
+	dot := image.NewPaletted(image.Rect(7, 7, 8, 8), palette)
+	anim := &gif.GIF{
+		Image:    []*image.Paletted{red, blue, dot},
+		Delay:    []int{10, 10, 10},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone},
+		Config:   image.Config{ColorModel: palette, Width: 8, Height: 8},
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+	fileStr := filepath.Join(t.TempDir(), "anim.gif")
+	if err := os.WriteFile(fileStr, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileStr
+}
+
+func TestAddGIFFramePages(t *testing.T) {
+	fileStr := writeAnimatedGIF(t)
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	names := pdf.RegisterGIFFrames(fileStr)
+	if got := len(names); got != 3 {
+		t.Fatalf("invalid number of frames: got=%d, want=3", got)
+	}
+	if got, want := names[1], fileStr+"#2"; got != want {
+		t.Errorf("invalid frame name: got=%s, want=%s", got, want)
+	}
+	pdf.AddGIFFramePages(fileStr, 1)
+	pdf.AddGIFFramePages(fileStr, 4)
+	if got := pdf.PageCount(); got != 4 {
+		t.Errorf("invalid page count: got=%d, want=4", got)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if got := strings.Count(out, "/Subtype /Image"); got != 3 {
+		t.Errorf("invalid number of images: got=%d, want=3", got)
+	}
+	// All frames use the global palette, which is written once.
+	palettes := map[string]bool{}
+	for _, s := range strings.Split(out, "/Indexed /DeviceRGB ")[1:] {
+		palettes[s[:strings.Index(s, "]")]] = true
+	}
+	if got := len(palettes); got != 1 {
+		t.Errorf("invalid number of palettes: got=%d, want=1", got)
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddGIFFramePages(fileStr, 3)
+	if !pdf.Err() {
+		t.Errorf("expected error for an unsupported number of frames per page")
+	}
+}
+
+func TestAnimatedGIFWarning(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.RegisterImageOptions(writeAnimatedGIF(t), fpdf.ImageOptions{})
+	if got := len(pdf.GetErrors()); got != 1 {
+		t.Errorf("invalid number of warnings: got=%d, want=1", got)
+	}
+}
@@ -0,0 +1,252 @@
+package fpdf
+
+import (
+	"math"
+	"sort"
+)
+
+// GradientStop is a color at a position of a gradient drawn with
+// LinearGradientStops() or RadialGradientStops().
+type GradientStop struct {
+	Pos        float64 // position from 0 at the start of the gradient to 1 at its end
+	R, G, B    int     // color of an RGB gradient, 0 to 255
+	C, M, Y, K byte    // color of a CMYK gradient, 0 to 100
+}
+
+// GradientExtend specifies how a gradient continues beyond its start and
+// end.
+type GradientExtend int
+
+const (
+	// GradientPad continues with the first and last colors.
+	GradientPad GradientExtend = iota
+	// GradientReflect repeats the gradient, reversing every other repetition.
+	GradientReflect
+	// GradientRepeat repeats the gradient.
+	GradientRepeat
+	// GradientNone leaves the area beyond the gradient unpainted.
+	GradientNone
+)
+
+// GradientOptions specifies the colors of a gradient drawn with
+// LinearGradientStops() or RadialGradientStops().
+type GradientOptions struct {
+	Stops  []GradientStop // at least two colors, in increasing order of position
+	CMYK   bool           // use the C, M, Y and K colors of the stops instead of R, G and B
+	Extend GradientExtend // how the gradient continues beyond its ends
+}
+
+// LinearGradientStops draws a rectangular area with a linear gradient like
+// LinearGradient(), through any number of colors. The gradient vector from
+// (x1, y1) to (x2, y2), in the normalized coordinates of the rectangle
+// described with LinearGradient(), goes from position 0 to position 1 of the
+// color stops of options. Two stops at the same position produce a sharp
+// change of color.
+func (f *Fpdf) LinearGradientStops(x, y, w, h float64, x1, y1, x2, y2 float64, options GradientOptions) {
+	f.gradientStops("LinearGradientStops", 2, x, y, w, h, x1, y1, x2, y2, 0, options)
+}
+
+// RadialGradientStops draws a rectangular area with a radial gradient like
+// RadialGradient(), through any number of colors. Position 0 of the color
+// stops of options is at the origin point (x1, y1) and position 1 at the
+// circle with center (x2, y2) and radius r, in the normalized coordinates of
+// the rectangle described with RadialGradient(). When the gradient is
+// reflected or repeated, the repetitions extend outwards only.
+func (f *Fpdf) RadialGradientStops(x, y, w, h float64, x1, y1, x2, y2, r float64, options GradientOptions) {
+	f.gradientStops("RadialGradientStops", 3, x, y, w, h, x1, y1, x2, y2, r, options)
+}
+
+func (f *Fpdf) gradientStops(method string, tp int, x, y, w, h, x1, y1, x2, y2, r float64, options GradientOptions) {
+	if f.err != nil || !f.checkGradientOptions(method, options) {
+		return
+	}
+	f.gradientClipStart(x, y, w, h)
+	f.outf("/Sh%d sh", f.addGradient(stopsGradient(tp, x1, y1, x2, y2, r, options)))
+	f.gradientClipEnd()
+}
+
+// checkGradientOptions reports whether the color stops and the extend mode
+// of options are valid, setting the error of method if not.
+func (f *Fpdf) checkGradientOptions(method string, options GradientOptions) bool {
+	stops := options.Stops
+	if len(stops) < 2 {
+		f.errorf(method, "a gradient needs at least two color stops")
+		return false
+	}
+	if !sort.SliceIsSorted(stops, func(i, j int) bool { return stops[i].Pos < stops[j].Pos }) ||
+		stops[0].Pos < 0 || stops[len(stops)-1].Pos > 1 {
+		f.errorf(method, "color stop positions must increase from 0 to 1")
+		return false
+	}
+	if options.Extend < GradientPad || options.Extend > GradientNone {
+		f.errorf(method, "invalid gradient extend mode: %d", options.Extend)
+		return false
+	}
+	return true
+}
+
+// stopsGradient returns the record of a gradient with the color stops of
+// options.
+func stopsGradient(tp int, x1, y1, x2, y2, r float64, options GradientOptions) gradientType {
+	return gradientType{tp: tp, x1: x1, y1: y1, x2: x2, y2: y2, r: r,
+		stops: append([]GradientStop(nil), options.Stops...), cmyk: options.CMYK, extend: options.Extend}
+}
+
+// key returns a string identifying the shading written for gr, with its
+// coordinates and colors rounded as they are written. The colors of CMYK
+// stops have four components and those of RGB stops three.
+func (gr gradientType) key() string {
+	var b fmtBuffer
+	b.printf("%d %s %s %.5f %.5f %.5f %.5f %.5f %d", gr.tp, gr.clr1Str, gr.clr2Str,
+		gr.x1, gr.y1, gr.x2, gr.y2, gr.r, int(gr.extend))
+	for _, s := range gr.stops {
+		b.printf(" %.5f:%s", s.Pos, gr.stopColor(s))
+	}
+	return b.String()
+}
+
+// addGradient returns the index in f.gradientList of the gradient gr, which
+// is added unless an identical one was added before, so that drawing the same
+// gradient many times writes its shading once.
+func (f *Fpdf) addGradient(gr gradientType) int {
+	key := gr.key()
+	if pos, ok := f.gradientMap[key]; ok {
+		return pos
+	}
+	pos := len(f.gradientList)
+	f.gradientList = append(f.gradientList, gr)
+	f.gradientMap[key] = pos
+	return pos
+}
+
+// stopColor returns the components of the color of stop s.
+func (gr gradientType) stopColor(s GradientStop) string {
+	if gr.cmyk {
+		return sprintf("%.3f %.3f %.3f %.3f", float64(s.C)/100, float64(s.M)/100, float64(s.Y)/100, float64(s.K)/100)
+	}
+	return sprintf("%.3f %.3f %.3f", float64(s.R)/255, float64(s.G)/255, float64(s.B)/255)
+}
+
+// putGradientStops writes the shading of a gradient with color stops and
+// its functions.
+func (f *Fpdf) putGradientStops(gr gradientType) {
+	// The colors between positions 0 and 1 are given by a stitching function
+	// of one interpolation per pair of stops, with the first and last colors
+	// before the first stop and after the last one.
+	stops := gr.stops
+	if stops[0].Pos > 0 {
+		stops = append([]GradientStop{stops[0]}, stops...)
+		stops[0].Pos = 0
+	}
+	if stops[len(stops)-1].Pos < 1 {
+		last := stops[len(stops)-1]
+		last.Pos = 1
+		stops = append(stops, last)
+	}
+	var functions, bounds, encode fmtBuffer
+	segments := 0
+	for j := 1; j < len(stops); j++ {
+		if stops[j].Pos == stops[j-1].Pos {
+			continue
+		}
+		f.newobj()
+		f.outf("<</FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1>>", gr.stopColor(stops[j-1]), gr.stopColor(stops[j]))
+		f.out("endobj")
+		functions.printf("%d 0 R ", f.n)
+		if segments > 0 {
+			bounds.printf("%.5f ", stops[j-1].Pos)
+		}
+		encode.printf("0 1 ")
+		segments++
+	}
+	fn := f.n
+	if segments > 1 {
+		f.newobj()
+		f.outf("<</FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s]>>",
+			functions.String(), bounds.String(), encode.String())
+		f.out("endobj")
+		fn = f.n
+	}
+
+	// Reflected and repeated gradients extend the shading over as many
+	// repetitions as needed to cover the unit square, each of them drawing
+	// the colors of positions 0 to 1 forwards or backwards.
+	t0, t1 := 0.0, 1.0
+	if gr.extend == GradientReflect || gr.extend == GradientRepeat {
+		t0, t1 = gr.repetitions()
+		functions.Reset()
+		bounds.Reset()
+		encode.Reset()
+		for t := t0; t < t1; t++ {
+			functions.printf("%d 0 R ", fn)
+			if t > t0 {
+				bounds.printf("%.0f ", t)
+			}
+			if gr.extend == GradientReflect && int(math.Abs(t))%2 == 1 {
+				encode.printf("1 0 ")
+			} else {
+				encode.printf("0 1 ")
+			}
+		}
+		f.newobj()
+		f.outf("<</FunctionType 3 /Domain [%.0f %.0f] /Functions [%s] /Bounds [%s] /Encode [%s]>>",
+			t0, t1, functions.String(), bounds.String(), encode.String())
+		f.out("endobj")
+		fn = f.n
+	}
+
+	cs := "DeviceRGB"
+	if gr.cmyk {
+		cs = "DeviceCMYK"
+	}
+	extend := "true true"
+	if gr.extend == GradientNone {
+		extend = "false false"
+	}
+	p := func(t float64) (float64, float64) { return gr.x1 + t*(gr.x2-gr.x1), gr.y1 + t*(gr.y2-gr.y1) }
+	f.newobj()
+	f.outf("<</ShadingType %d /ColorSpace /%s", gr.tp, cs)
+	ax, ay := p(t0)
+	bx, by := p(t1)
+	if gr.tp == 2 {
+		f.outf("/Coords [%.5f %.5f %.5f %.5f] /Domain [%.0f %.0f] /Function %d 0 R /Extend [%s]>>",
+			ax, ay, bx, by, t0, t1, fn, extend)
+	} else {
+		f.outf("/Coords [%.5f %.5f %.5f %.5f %.5f %.5f] /Domain [%.0f %.0f] /Function %d 0 R /Extend [%s]>>",
+			ax, ay, t0*gr.r, bx, by, t1*gr.r, t0, t1, fn, extend)
+	}
+	f.out("endobj")
+}
+
+// repetitions returns the range of gradient positions, in whole
+// repetitions, that covers the unit square of the gradient.
+func (gr gradientType) repetitions() (t0, t1 float64) {
+	corners := [][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+	t0, t1 = 0, 1
+	if gr.tp == 2 {
+		dx, dy := gr.x2-gr.x1, gr.y2-gr.y1
+		d2 := dx*dx + dy*dy
+		if d2 == 0 {
+			return
+		}
+		for _, c := range corners {
+			t := ((c[0]-gr.x1)*dx + (c[1]-gr.y1)*dy) / d2
+			t0 = math.Min(t0, math.Floor(t))
+			t1 = math.Max(t1, math.Ceil(t))
+		}
+		return
+	}
+	// Circles grow by r for every repetition, moving their center away from
+	// the origin by the distance between the origin and the circle center.
+	if gr.r <= 0 {
+		return
+	}
+	shift := math.Hypot(gr.x2-gr.x1, gr.y2-gr.y1)
+	for _, c := range corners {
+		dist := math.Hypot(c[0]-gr.x1, c[1]-gr.y1)
+		if gr.r > shift {
+			t1 = math.Max(t1, math.Ceil(dist/(gr.r-shift)))
+		}
+	}
+	return
+}
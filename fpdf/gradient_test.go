@@ -0,0 +1,145 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestGradientStops(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	stops := []fpdf.GradientStop{
+		{Pos: 0, R: 255},
+		{Pos: 0.5, G: 255},
+		{Pos: 0.5, B: 255},
+		{Pos: 0.8, R: 255, G: 255, B: 255},
+	}
+	pdf.LinearGradientStops(10, 10, 100, 50, 0, 0, 1, 0, fpdf.GradientOptions{Stops: stops})
+	pdf.LinearGradientStops(10, 70, 100, 50, 0.25, 0, 0.5, 0,
+		fpdf.GradientOptions{Stops: stops, Extend: fpdf.GradientReflect})
+	pdf.RadialGradientStops(10, 130, 100, 100, 0.5, 0.5, 0.5, 0.5, 0.25, fpdf.GradientOptions{
+		Stops:  []fpdf.GradientStop{{Pos: 0, C: 100}, {Pos: 1, K: 100}},
+		CMYK:   true,
+		Extend: fpdf.GradientNone,
+	})
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		// The sharp change at 0.5 and the padding after 0.8.
+		"/C0 [1.000 0.000 0.000] /C1 [0.000 1.000 0.000]",
+		"/C0 [1.000 1.000 1.000] /C1 [1.000 1.000 1.000]",
+		"/FunctionType 3 /Domain [0 1] /Functions [",
+		"/Bounds [0.50000 0.80000 ] /Encode [0 1 0 1 0 1 ]",
+		// Reflected over 4 repetitions, from 0.25 back to 0 and on to 1.
+		"/Domain [-1 3] /Functions [",
+		"/Bounds [0 1 2 ] /Encode [1 0 0 1 1 0 0 1 ]",
+		"/Coords [0.00000 0.00000 1.00000 0.00000] /Domain [-1 3]",
+		"/ShadingType 3 /ColorSpace /DeviceCMYK",
+		"/C0 [1.000 0.000 0.000 0.000] /C1 [0.000 0.000 0.000 1.000]",
+		"/Extend [false false]",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	for _, stops := range [][]fpdf.GradientStop{
+		{{Pos: 0}},
+		{{Pos: 0.5}, {Pos: 0.2}},
+		{{Pos: 0}, {Pos: 1.5}},
+	} {
+		pdf = NewDocPdfTest()
+		pdf.AddPage()
+		pdf.LinearGradientStops(10, 10, 100, 50, 0, 0, 1, 0, fpdf.GradientOptions{Stops: stops})
+		if !pdf.Err() {
+			t.Errorf("expected error for color stops %v", stops)
+		}
+	}
+}
+
+func TestGradientPaint(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 36)
+	stops := []fpdf.GradientStop{{Pos: 0, R: 200}, {Pos: 0.5, G: 200}, {Pos: 1, B: 200}}
+	paint := pdf.AddLinearGradientPaint(50, 50, 300, 40, 0, 0, 1, 0, fpdf.GradientOptions{Stops: stops})
+	pdf.SetTextGradient(paint)
+	pdf.SetXY(50, 50)
+	pdf.Cell(300, 40, "Headline")
+	pdf.SetDrawGradient(paint)
+	pdf.SetLineWidth(4)
+	pdf.Line(50, 95, 350, 95)
+	pdf.SetFillGradient(paint)
+	pdf.Rect(50, 100, 300, 40, "F")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"q /Pattern cs /P1 scn BT",
+		"/Pattern CS /P1 SCN",
+		"/Pattern <<\n/P1 ",
+		"/PatternType 2 /Shading ",
+		"/Matrix [300.00000 0.00000 0.00000 40.00000 50.00000 751.89000]>>",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+	// The text, the line and the rectangle share one pattern.
+	if got := strings.Count(out, "/PatternType 2"); got != 1 {
+		t.Errorf("invalid number of patterns: got=%d, want=1", got)
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetFillGradient(0)
+	if !pdf.Err() {
+		t.Errorf("expected error for an unknown gradient paint")
+	}
+}
+
+func TestGraphicsStateDedup(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	for j := 0; j < 100; j++ {
+		pdf.SetAlpha(0.5, "")
+		pdf.Rect(10, 10, 20, 20, "F")
+		pdf.SetAlpha(0.5, "Normal")
+		pdf.SetAlpha(1, "Normal")
+		pdf.LinearGradient(10, float64(j), 50, 10, 255, 0, 0, 0, 0, 255, 0, 0, 1, 0)
+		pdf.LinearGradientStops(70, float64(j), 50, 10, 0, 0, 1, 0,
+			fpdf.GradientOptions{Stops: []fpdf.GradientStop{{Pos: 0, R: 255}, {Pos: 1, B: 255}}})
+	}
+	pdf.RadialGradient(10, 120, 50, 50, 255, 0, 0, 0, 0, 255, 0.5, 0.5, 0.5, 0.5, 0.5)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for s, want := range map[string]int{
+		"/Type /ExtGState": 2,
+		"/ShadingType 2":   2,
+		"/ShadingType 3":   1,
+	} {
+		if n := strings.Count(out, s); n != want {
+			t.Errorf("%q written %d times, want %d", s, n, want)
+		}
+	}
+	if strings.Contains(out, "/BM />>") {
+		t.Errorf("empty blend mode written")
+	}
+}
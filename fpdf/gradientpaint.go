@@ -0,0 +1,128 @@
+package fpdf
+
+type gradientPaintType struct {
+	gradient   int // index in gradientList
+	x, y, w, h float64
+}
+
+type patternType struct {
+	gradient int        // index in gradientList
+	page     int        // page the pattern was set on
+	matrix   [6]float64 // from the unit square of the gradient to the page, in points
+	tile     []byte     // content of the tile of a tiling pattern, see SetFillHatch
+	step     float64    // size of the tile of a tiling pattern, in points
+	objNum   int
+}
+
+// AddLinearGradientPaint adds a linear gradient, with the color stops of
+// options, that can be used in place of a color with SetDrawGradient(),
+// SetFillGradient() and SetTextGradient(). The gradient spans the rectangle
+// (x, y, w, h) of the page it is set on, and the gradient vector (x1, y1) to
+// (x2, y2) is given in the normalized coordinates of that rectangle as
+// described with LinearGradient(). Unlike LinearGradient(), nothing is drawn:
+// the gradient paints the lines, areas or text that are drawn with it.
+//
+// The returned identifier is passed to the Set...Gradient() methods.
+func (f *Fpdf) AddLinearGradientPaint(x, y, w, h, x1, y1, x2, y2 float64, options GradientOptions) int {
+	return f.addGradientPaint("AddLinearGradientPaint", 2, x, y, w, h, x1, y1, x2, y2, 0, options)
+}
+
+// AddRadialGradientPaint adds a radial gradient, with the color stops of
+// options, that can be used in place of a color like the gradients of
+// AddLinearGradientPaint(). The origin point (x1, y1), the circle center
+// (x2, y2) and the radius r are given in the normalized coordinates of the
+// rectangle (x, y, w, h) as described with RadialGradient().
+func (f *Fpdf) AddRadialGradientPaint(x, y, w, h, x1, y1, x2, y2, r float64, options GradientOptions) int {
+	return f.addGradientPaint("AddRadialGradientPaint", 3, x, y, w, h, x1, y1, x2, y2, r, options)
+}
+
+func (f *Fpdf) addGradientPaint(method string, tp int, x, y, w, h, x1, y1, x2, y2, r float64, options GradientOptions) int {
+	if f.err != nil || !f.checkGradientOptions(method, options) {
+		return -1
+	}
+	pos := f.addGradient(stopsGradient(tp, x1, y1, x2, y2, r, options))
+	f.gradientPaints = append(f.gradientPaints, gradientPaintType{gradient: pos, x: x, y: y, w: w, h: h})
+	return len(f.gradientPaints) - 1
+}
+
+// SetDrawGradient sets the paint of lines and outlines to the gradient paint
+// id returned by AddLinearGradientPaint() or AddRadialGradientPaint(). It
+// remains in effect until another draw color or gradient is set.
+func (f *Fpdf) SetDrawGradient(id int) {
+	if n, ok := f.gradientPattern("SetDrawGradient", id); ok {
+		f.color.draw.str = sprintf("/Pattern CS /P%d SCN", n)
+		if f.page > 0 {
+			f.out(f.color.draw.str)
+		}
+	}
+}
+
+// SetFillGradient sets the paint of filled areas and cell backgrounds to the
+// gradient paint id returned by AddLinearGradientPaint() or
+// AddRadialGradientPaint(). It remains in effect until another fill color or
+// gradient is set.
+func (f *Fpdf) SetFillGradient(id int) {
+	if n, ok := f.gradientPattern("SetFillGradient", id); ok {
+		f.color.fill.str = sprintf("/Pattern cs /P%d scn", n)
+		f.colorFlag = f.color.fill.str != f.color.text.str
+		if f.page > 0 {
+			f.out(f.color.fill.str)
+		}
+	}
+}
+
+// SetTextGradient sets the paint of text to the gradient paint id returned by
+// AddLinearGradientPaint() or AddRadialGradientPaint(), so that headlines can
+// be drawn with a gradient without clipping. The gradient spans its
+// rectangle, not each string, so that the text written in the rectangle
+// shares one gradient. It remains in effect until another text color or
+// gradient is set.
+func (f *Fpdf) SetTextGradient(id int) {
+	if n, ok := f.gradientPattern("SetTextGradient", id); ok {
+		f.color.text.str = sprintf("/Pattern cs /P%d scn", n)
+		f.colorFlag = f.color.fill.str != f.color.text.str
+	}
+}
+
+// gradientPattern returns the number of the pattern that paints the gradient
+// paint id on the current page, adding it if needed.
+func (f *Fpdf) gradientPattern(method string, id int) (int, bool) {
+	if f.err != nil {
+		return 0, false
+	}
+	if id < 0 || id >= len(f.gradientPaints) {
+		f.errorf(method, "invalid gradient paint: %d", id)
+		return 0, false
+	}
+	gp := f.gradientPaints[id]
+	// Patterns are positioned on the page independently of the
+	// transformations in effect, so the rectangle of the gradient is
+	// converted to points from the lower left corner of the page.
+	llx, lly := f.pagePoint(f.page, gp.x, gp.y+gp.h)
+	matrix := [6]float64{gp.w * f.k, 0, 0, gp.h * f.k, llx, lly}
+	for j, p := range f.patternList {
+		if p.tile == nil && p.gradient == gp.gradient && p.page == f.page && p.matrix == matrix {
+			return j + 1, true
+		}
+	}
+	f.patternList = append(f.patternList, patternType{gradient: gp.gradient, page: f.page, matrix: matrix})
+	return len(f.patternList), true
+}
+
+// putPatterns writes the shading patterns of the gradient paints and the
+// tiling patterns of the hatch fills.
+func (f *Fpdf) putPatterns() {
+	for j, p := range f.patternList {
+		if p.tile != nil {
+			f.putHatchPattern(p)
+			f.patternList[j].objNum = f.n
+			continue
+		}
+		m := p.matrix
+		f.newobj()
+		f.outf("<</PatternType 2 /Shading %d 0 R /Matrix [%.5f %.5f %.5f %.5f %.5f %.5f]>>",
+			f.gradientList[p.gradient].objNum, m[0], m[1], m[2], m[3], m[4], m[5])
+		f.out("endobj")
+		f.patternList[j].objNum = f.n
+	}
+}
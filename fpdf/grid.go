@@ -2,8 +2,6 @@ package fpdf
 
 import (
 	"math"
-
-	. "github.com/tinywasm/fmt"
 )
 
 // RGBType holds fields for red, green and blue color components (0..255)
@@ -58,7 +56,7 @@ type TickFormatFncType func(val float64, precision int) string
 
 // defaultFormatter returns the string form of val with precision decimal places.
 func defaultFormatter(val float64, precision int) string {
-	return Convert(val).Round(precision).String()
+	return string(appendFloat(nil, val, precision))
 }
 
 // GridType assists with the generation of graphs. It allows the application to
@@ -0,0 +1,111 @@
+package fpdf
+
+import "bytes"
+
+// HatchStyle is the pattern of lines or dots of a hatch fill set with
+// SetFillHatch().
+type HatchStyle int
+
+const (
+	// HatchDiagonal fills with lines rising from left to right.
+	HatchDiagonal HatchStyle = iota
+	// HatchBackDiagonal fills with lines falling from left to right.
+	HatchBackDiagonal
+	// HatchDiagonalCross fills with the lines of HatchDiagonal and
+	// HatchBackDiagonal.
+	HatchDiagonalCross
+	// HatchHorizontal fills with horizontal lines.
+	HatchHorizontal
+	// HatchVertical fills with vertical lines.
+	HatchVertical
+	// HatchCross fills with a grid of horizontal and vertical lines.
+	HatchCross
+	// HatchDots fills with a grid of round dots.
+	HatchDots
+)
+
+// SetFillHatch sets the paint of filled areas, such as those of Rect(),
+// Polygon() and cell backgrounds, to a hatch of lines or dots in color, so
+// that the series of a chart remain distinguishable when printed in black
+// and white. spacing is the distance between the lines or dots and
+// lineWidth their width, in the unit of measure specified in New(). The
+// area between the lines is left unpainted: fill it first with a plain
+// color for a background. The hatch remains in effect until another fill
+// color is set.
+func (f *Fpdf) SetFillHatch(style HatchStyle, spacing, lineWidth float64, color RGBType) {
+	if f.err != nil {
+		return
+	}
+	if spacing <= 0 || lineWidth <= 0 {
+		f.errorf("SetFillHatch", "invalid hatch spacing or line width")
+		return
+	}
+	s := spacing * f.k
+	var tile fmtBuffer
+	clr := f.rgbColorValue(color.R, color.G, color.B, "G", "RG")
+	tile.printf("%s %.3f w\n", clr.str, lineWidth*f.k)
+	line := func(x0, y0, x1, y1 float64) {
+		tile.printf("%.3f %.3f m %.3f %.3f l\n", x0*s, y0*s, x1*s, y1*s)
+	}
+	// Diagonal lines are repeated beyond the corners of the tile so that
+	// they join those of the neighboring tiles.
+	diagonal := func() {
+		line(0, 0, 1, 1)
+		line(-0.5, 0.5, 0.5, 1.5)
+		line(0.5, -0.5, 1.5, 0.5)
+	}
+	backDiagonal := func() {
+		line(0, 1, 1, 0)
+		line(-0.5, 0.5, 0.5, -0.5)
+		line(0.5, 1.5, 1.5, 0.5)
+	}
+	switch style {
+	case HatchDiagonal:
+		diagonal()
+	case HatchBackDiagonal:
+		backDiagonal()
+	case HatchDiagonalCross:
+		diagonal()
+		backDiagonal()
+	case HatchHorizontal:
+		line(0, 0.5, 1, 0.5)
+	case HatchVertical:
+		line(0.5, 0, 0.5, 1)
+	case HatchCross:
+		line(0, 0.5, 1, 0.5)
+		line(0.5, 0, 0.5, 1)
+	case HatchDots:
+		// A line of length zero with round caps draws a dot.
+		tile.printf("1 J\n")
+		line(0.5, 0.5, 0.5, 0.5)
+	default:
+		f.errorf("SetFillHatch", "invalid hatch style: %d", style)
+		return
+	}
+	tile.printf("S")
+	n := 0
+	for j, p := range f.patternList {
+		if p.step == s && bytes.Equal(p.tile, tile.Bytes()) {
+			n = j + 1
+			break
+		}
+	}
+	if n == 0 {
+		f.patternList = append(f.patternList, patternType{tile: tile.Bytes(), step: s})
+		n = len(f.patternList)
+	}
+	f.color.fill.str = sprintf("/Pattern cs /P%d scn", n)
+	f.colorFlag = f.color.fill.str != f.color.text.str
+	if f.page > 0 {
+		f.out(f.color.fill.str)
+	}
+}
+
+// putHatchPattern writes the tiling pattern of a hatch fill.
+func (f *Fpdf) putHatchPattern(p patternType) {
+	f.newobj()
+	f.outf("<</Type /Pattern /PatternType 1 /PaintType 1 /TilingType 1 /BBox [0 0 %.3f %.3f] /XStep %.3f /YStep %.3f /Resources <<>> /Length %d>>",
+		p.step, p.step, p.step, p.step, len(p.tile))
+	f.putstream(p.tile)
+	f.out("endobj")
+}
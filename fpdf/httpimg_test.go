@@ -1,6 +1,11 @@
 package fpdf_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
 	fpdf "github.com/tinywasm/pdf/fpdf"
 	"github.com/tinywasm/pdf/fpdf/contrib/httpimg"
 )
@@ -20,3 +25,56 @@ func ExampleRegister() {
 	// Output:
 	// Successfully generated pdf/contrib_httpimg_Register.pdf
 }
+
+func TestRegisterImageURL(t *testing.T) {
+	logo, err := os.ReadFile(ImageFile("logo.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(logo)
+		case "/mislabeled.png":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(logo)
+		case "/page.png":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>Not found</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cache := httpimg.NewMemoryCache()
+	for j := 0; j < 2; j++ {
+		pdf := NewDocPdfTest()
+		info := httpimg.RegisterImageURL(pdf, srv.URL+"/logo.png", httpimg.Options{Cache: cache})
+		if err := pdf.Error(); err != nil || info == nil {
+			t.Fatalf("image not registered: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("cached image downloaded again: got=%d requests, want=1", requests)
+	}
+
+	for _, c := range []struct {
+		path string
+		opts httpimg.Options
+	}{
+		{"/logo.png", httpimg.Options{MaxBytes: int64(len(logo)) - 1}},
+		{"/mislabeled.png", httpimg.Options{}},
+		{"/page.png", httpimg.Options{}},
+		{"/missing.png", httpimg.Options{}},
+	} {
+		pdf := NewDocPdfTest()
+		httpimg.RegisterImageURL(pdf, srv.URL+c.path, c.opts)
+		if pdf.Ok() {
+			t.Errorf("expected error for %s", c.path)
+		}
+	}
+}
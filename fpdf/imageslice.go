@@ -0,0 +1,84 @@
+package fpdf
+
+import "math"
+
+// ImageInsets gives the width of the borders of an image, in pixels, that
+// ImageNineSlice() keeps from being stretched.
+type ImageInsets struct {
+	Top, Right, Bottom, Left float64
+}
+
+// ImageNineSlice places the registered image imageNameStr in the rectangle
+// (x, y, w, h) as a scalable frame. The image is divided by insets into
+// nine parts: the corners are drawn at the natural size of the image, the
+// top and bottom edges are stretched horizontally, the left and right edges
+// vertically, and the center in both directions. This draws frames, buttons
+// and callout bubbles of any size from a small image without distorting
+// their corners. If the rectangle is smaller than the corners, they are
+// scaled down to fit.
+func (f *Fpdf) ImageNineSlice(imageNameStr string, x, y, w, h float64, insets ImageInsets) {
+	if f.err != nil {
+		return
+	}
+	info := f.RegisterImageOptions(imageNameStr, ImageOptions{})
+	if f.err != nil {
+		return
+	}
+	if insets.Left < 0 || insets.Right < 0 || insets.Top < 0 || insets.Bottom < 0 ||
+		insets.Left+insets.Right >= info.w || insets.Top+insets.Bottom >= info.h {
+		f.errorf("ImageNineSlice", "insets exceed the image")
+		return
+	}
+	// Size of an image pixel on the page, reduced if the corners do not fit.
+	px := info.Width() / info.w
+	px = math.Min(px, w/(insets.Left+insets.Right))
+	px = math.Min(px, h/(insets.Top+insets.Bottom))
+
+	// Source columns and rows of the image, and where they go on the page.
+	srcX := []float64{0, insets.Left, info.w - insets.Right, info.w}
+	srcY := []float64{0, insets.Top, info.h - insets.Bottom, info.h}
+	dstX := []float64{x, x + insets.Left*px, x + w - insets.Right*px, x + w}
+	dstY := []float64{y, y + insets.Top*px, y + h - insets.Bottom*px, y + h}
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			crop := ImageRect{X: srcX[col], Y: srcY[row], Wd: srcX[col+1] - srcX[col], Ht: srcY[row+1] - srcY[row]}
+			cellW, cellH := dstX[col+1]-dstX[col], dstY[row+1]-dstY[row]
+			if crop.Wd <= 0 || crop.Ht <= 0 || cellW <= 0 || cellH <= 0 {
+				continue
+			}
+			f.imageOut(info, dstX[col], dstY[row], cellW, cellH, false,
+				ImageOptions{AllowNegativePosition: true, Crop: crop}, 0, "")
+		}
+	}
+}
+
+// ImageTile fills the rectangle (x, y, w, h) with copies of the registered
+// image imageNameStr, side by side from the upper left corner, for
+// backgrounds and patterns. Each copy is tileW wide and tileH high; if both
+// are zero the image has its natural size, and if one is zero it is
+// computed to keep the proportions of the image. Copies at the right and
+// bottom edges are clipped to the rectangle.
+func (f *Fpdf) ImageTile(imageNameStr string, x, y, w, h, tileW, tileH float64) {
+	if f.err != nil {
+		return
+	}
+	info := f.RegisterImageOptions(imageNameStr, ImageOptions{})
+	if f.err != nil {
+		return
+	}
+	switch {
+	case tileW <= 0 && tileH <= 0:
+		tileW, tileH = info.Width(), info.Height()
+	case tileW <= 0:
+		tileW = tileH * info.w / info.h
+	case tileH <= 0:
+		tileH = tileW * info.h / info.w
+	}
+	f.ClipRect(x, y, w, h, false)
+	for ty := y; ty < y+h; ty += tileH {
+		for tx := x; tx < x+w; tx += tileW {
+			f.imageOut(info, tx, ty, tileW, tileH, false, ImageOptions{AllowNegativePosition: true}, 0, "")
+		}
+	}
+	f.ClipEnd()
+}
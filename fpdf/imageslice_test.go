@@ -0,0 +1,58 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestImageNineSlice(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	logo := ImageFile("logo.png")
+	info := pdf.RegisterImageOptions(logo, fpdf.ImageOptions{})
+	pdf.ImageNineSlice(logo, 50, 50, 400, 300, fpdf.ImageInsets{Top: 4, Right: 4, Bottom: 4, Left: 4})
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if got := strings.Count(out, " re W n"); got != 9 {
+		t.Errorf("invalid number of slices: got=%d, want=9", got)
+	}
+	// The upper left corner keeps the natural size of its pixels.
+	px := 1.0 // a pixel is a point at 72 dpi
+	ff := func(v float64, prec int) string { return strconv.FormatFloat(v, 'f', prec, 64) }
+	corner := "50.00 " + ff(841.89-50-4*px, 2) + " " + ff(4*px, 2) + " " + ff(4*px, 2) + " re W n"
+	if !strings.Contains(out, corner) {
+		t.Errorf("output does not contain %q", corner)
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.ImageNineSlice(logo, 10, 10, 50, 50, fpdf.ImageInsets{Left: info.Width(), Right: 1})
+	if !pdf.Err() {
+		t.Errorf("expected error for insets exceeding the image")
+	}
+}
+
+func TestImageTile(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.ImageTile(ImageFile("logo.png"), 50, 50, 100, 50, 30, 20)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// 4 columns of 3 rows, clipped to the rectangle.
+	if got := strings.Count(buf.String(), " Do Q"); got != 12 {
+		t.Errorf("invalid number of tiles: got=%d, want=12", got)
+	}
+}
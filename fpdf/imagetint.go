@@ -0,0 +1,47 @@
+package fpdf
+
+// tintedImage returns the variant of the registered image info rendered in
+// shades of tint, registering it on first use.
+func (f *Fpdf) tintedImage(info *ImageInfoType, tint RGBType) *ImageInfoType {
+	switch info.cs {
+	case "DeviceRGB", "DeviceGray", "DeviceCMYK", "Indexed":
+	default:
+		f.errorf("ImageOptions", "images in color space %s cannot be tinted", info.cs)
+		return info
+	}
+	id := info.i + sprintf("t%d_%d_%d", tint.R, tint.G, tint.B)
+	key := "\x00" + id
+	if tinted, ok := f.images[key]; ok {
+		return tinted
+	}
+	tinted := *info
+	tinted.i = id
+	tinted.n = 0
+	tinted.tint = &tint
+	f.images[key] = &tinted
+	return &tinted
+}
+
+// puttintfunction writes the PostScript calculator function that converts
+// the colors of an image in color space cs to shades of tint, and returns the
+// color space to be used by the image in its place.
+func (f *Fpdf) puttintfunction(cs string, tint RGBType) string {
+	var names, domain, lum string
+	switch cs {
+	case "DeviceGray":
+		names, domain = "/L", "0 1"
+	case "DeviceCMYK":
+		names, domain = "/C /M /Y /K", "0 1 0 1 0 1 0 1"
+		lum = "exch 0.11 mul add exch 0.59 mul add exch 0.3 mul add 1 exch sub dup 0 lt {pop 0} if"
+	default:
+		names, domain = "/R /G /B", "0 1 0 1 0 1"
+		lum = "0.11 mul exch 0.59 mul add exch 0.3 mul add"
+	}
+	r, g, b := float64(tint.R)/255, float64(tint.G)/255, float64(tint.B)/255
+	code := sprintf("{%s dup %.3f mul exch dup %.3f mul exch %.3f mul}", lum, r, g, b)
+	f.newobj()
+	f.outf("<</FunctionType 4 /Domain [%s] /Range [0 1 0 1 0 1] /Length %d>>", domain, len(code))
+	f.putstream([]byte(code))
+	f.out("endobj")
+	return sprintf("[/DeviceN [%s] /DeviceRGB %d 0 R]", names, f.n)
+}
@@ -0,0 +1,78 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestImageOptionsCrop(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	logo := ImageFile("logo.jpg")
+	info := pdf.RegisterImageOptions(logo, fpdf.ImageOptions{})
+	imgW, imgH := info.Width(), info.Height()
+	// Right half of the image, 100pt wide.
+	pdf.ImageOptions(logo, 50, 50, 100, 0, false,
+		fpdf.ImageOptions{Crop: fpdf.ImageRect{X: imgW / 2, Wd: imgW / 2, Ht: imgH}}, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	clipHt := 100 * imgH / (imgW / 2)
+	ff := func(v float64, prec int) string { return strconv.FormatFloat(v, 'f', prec, 64) }
+	for _, s := range []string{
+		"50.00 " + ff(841.89-50-clipHt, 2) + " 100.00 " + ff(clipHt, 2) + " re W n",
+		"200.00000 0 0 " + ff(clipHt, 5) + " -50.00000 ",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.ImageOptions(logo, 10, 10, 30, 0, false,
+		fpdf.ImageOptions{Crop: fpdf.ImageRect{X: 1, Wd: imgW, Ht: imgH}}, 0, "")
+	if !pdf.Err() {
+		t.Errorf("expected error for a crop rectangle exceeding the image")
+	}
+}
+
+func TestImageOptionsTint(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	for _, name := range []string{"logo.jpg", "logo.gif"} {
+		pdf.ImageOptions(ImageFile(name), 10, 10, 30, 0, false, fpdf.ImageOptions{}, 0, "")
+		pdf.ImageOptions(ImageFile(name), 10, 50, 30, 0, false, fpdf.ImageOptions{Grayscale: true}, 0, "")
+		pdf.ImageOptions(ImageFile(name), 10, 90, 30, 0, false, fpdf.ImageOptions{Grayscale: true}, 0, "")
+		pdf.ImageOptions(ImageFile(name), 10, 130, 30, 0, false, fpdf.ImageOptions{Tint: &fpdf.RGBType{R: 255, G: 128}}, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// Each image, its gray and its orange variant.
+	if got := strings.Count(out, "/Subtype /Image"); got != 6 {
+		t.Errorf("invalid number of images: got=%d, want=6", got)
+	}
+	for _, s := range []string{
+		"/ColorSpace [/DeviceN [/R /G /B] /DeviceRGB ",
+		"/ColorSpace [/Indexed [/DeviceN [/R /G /B] /DeviceRGB ",
+		"{0.11 mul exch 0.59 mul add exch 0.3 mul add dup 1.000 mul exch dup 1.000 mul exch 1.000 mul}",
+		"dup 1.000 mul exch dup 0.502 mul exch 0.000 mul}",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package fpdf
+
+import "math"
+
+// Imposition describes how the pages of a document are arranged on the
+// sheets that are actually output. Use NUp() or Booklet to obtain one and
+// pass it to Impose().
+type Imposition struct {
+	n          int // pages per sheet side
+	cols, rows int // grid of pages on a sheet side, 0 if n is not supported
+	booklet    bool
+}
+
+// Booklet places pages two per sheet side in saddle-stitch signature order:
+// the sheets, printed on both sides, stacked, folded in the middle and
+// stapled, read as a booklet. Blank pages are added at the end when the page
+// count is not a multiple of four.
+var Booklet = Imposition{n: 2, cols: 2, rows: 1, booklet: true}
+
+// NUp returns an imposition that places n consecutive pages on each sheet,
+// left to right and top to bottom. n must be a square (4, 9, 16) or twice a
+// square (2, 8, 18); 2-up places two pages side by side, 8-up four columns
+// of two rows.
+func NUp(n int) Imposition {
+	for s := 1; s*s <= n; s++ {
+		if s*s == n {
+			return Imposition{n: n, cols: s, rows: s}
+		}
+		if 2*s*s == n {
+			return Imposition{n: n, cols: 2 * s, rows: s}
+		}
+	}
+	return Imposition{n: n}
+}
+
+// imposedPage records where a page lands on the imposed output.
+type imposedPage struct {
+	sheet int     // 1-based sheet number
+	x, y  float64 // lower left corner of the page on the sheet, in points
+}
+
+// Impose arranges the pages of the document on larger sheets when it is
+// output, for printing several pages per sheet or for binding as a booklet.
+// Pages are generated as usual; imposition is a post-processing step applied
+// by Output(), so Impose() can be called at any time before that.
+//
+// Every sheet is divided into equal cells the size of the largest page, so
+// 2-up A5 pages result in A4 landscape sheets and 2-up A4 pages in A3
+// landscape sheets. Pages are centered in their cell at their original size.
+// The pages are drawn as templates. Links, file attachment annotations and
+// bookmarks are carried over to the position of their page on the sheet, and
+// the crop, bleed, trim and art boxes of a sheet hold those of its pages.
+//
+// Passing the zero Imposition turns imposition off.
+func (f *Fpdf) Impose(imp Imposition) {
+	if f.err != nil {
+		return
+	}
+	if f.state == 3 {
+		f.errorf("Impose", "document has already been output")
+		return
+	}
+	if imp.n != 0 && imp.cols == 0 {
+		f.errorf("Impose", "unsupported number of pages per sheet: %d", imp.n)
+		return
+	}
+	f.imposition = imp
+}
+
+// pageSizePt returns the width and height of page n in points.
+func (f *Fpdf) pageSizePt(n int) (w, h float64) {
+	if sz, ok := f.pageSizes[n]; ok {
+		return sz.Wd, sz.Ht
+	}
+	if f.defOrientation == Portrait {
+		return f.defPageSize.Wd, f.defPageSize.Ht
+	}
+	return f.defPageSize.Ht, f.defPageSize.Wd
+}
+
+// imposeLayout assigns the pages of the document to sheets according to the
+// current imposition. It returns the page numbers on each sheet in cell
+// order, 0 standing for a blank cell, and the sheet size in points. The
+// position of every page is stored in f.imposed.
+func (f *Fpdf) imposeLayout() (sheets [][]int, sheetW, sheetH float64) {
+	imp := f.imposition
+	nb := f.page
+	var cellW, cellH float64
+	for n := 1; n <= nb; n++ {
+		w, h := f.pageSizePt(n)
+		cellW = math.Max(cellW, w)
+		cellH = math.Max(cellH, h)
+	}
+	sheetW = float64(imp.cols) * cellW
+	sheetH = float64(imp.rows) * cellH
+
+	var order []int
+	if imp.booklet {
+		// Outer pages on the front of the outer sheet, inner pages on its
+		// back, continuing inwards.
+		count := (nb + 3) / 4 * 4
+		for i := 0; i < count/2; i++ {
+			if i%2 == 0 {
+				order = append(order, count-i, i+1)
+			} else {
+				order = append(order, i+1, count-i)
+			}
+		}
+	} else {
+		for n := 1; n <= nb; n++ {
+			order = append(order, n)
+		}
+		for len(order)%imp.n != 0 {
+			order = append(order, 0)
+		}
+	}
+
+	f.imposed = make([]imposedPage, nb+1)
+	for len(order) > 0 {
+		sheet := order[:imp.n]
+		order = order[imp.n:]
+		for j, n := range sheet {
+			if n > nb {
+				sheet[j] = 0
+				continue
+			}
+			if n == 0 {
+				continue
+			}
+			w, h := f.pageSizePt(n)
+			col, row := float64(j%imp.cols), float64(j/imp.cols)
+			f.imposed[n] = imposedPage{
+				sheet: len(sheets) + 1,
+				x:     col*cellW + (cellW-w)/2,
+				y:     sheetH - (row+1)*cellH + (cellH-h)/2,
+			}
+		}
+		sheets = append(sheets, sheet)
+	}
+	return
+}
+
+// destPage returns the object number of the output page that shows page n,
+// and converts the point (xPt, yPt) on page n, in points from the lower left
+// corner, to that output page.
+func (f *Fpdf) destPage(n int, xPt, yPt float64) (obj int, x, y float64) {
+	if f.imposed == nil {
+		return 1 + 2*n, xPt, yPt
+	}
+	p := f.imposed[n]
+	return 1 + 2*p.sheet, p.x + xPt, p.y + yPt
+}
+
+// putimposedpages writes the sheets of an imposed document. The pages are
+// added to the templates, written with the other resources, and drawn on the
+// sheets.
+func (f *Fpdf) putimposedpages() {
+	sheets, sheetW, sheetH := f.imposeLayout()
+	tpls := make([]*Template, f.page+1)
+	for n := 1; n <= f.page; n++ {
+		w, h := f.pageSizePt(n)
+		tpls[n] = f.addTemplate(templateType{box: [4]float64{0, 0, w, h}, content: f.pages[n].Bytes()})
+	}
+	kids := make([]int, len(sheets))
+	for s, sheet := range sheets {
+		f.newobj()
+		kids[s] = f.n
+		f.out("<</Type /Page")
+		f.out("/Parent 1 0 R")
+		f.outf("/MediaBox [0 0 %.2f %.2f]", sheetW, sheetH)
+		for _, t := range []string{"CropBox", "BleedBox", "TrimBox", "ArtBox"} {
+			if pb, ok := f.sheetBox(sheet, t); ok {
+				f.outf("/%s [%.2f %.2f %.2f %.2f]", t, pb.X, pb.Y, pb.Wd, pb.Ht)
+			}
+		}
+		var annots, content fmtBuffer
+		for _, n := range sheet {
+			if n == 0 {
+				continue
+			}
+			p := f.imposed[n]
+			content.printf("q 1 0 0 1 ")
+			content.Write(appendFloats(nil, f.prec.Matrix, p.x, p.y))
+			content.printf("cm /TPL%d Do Q\n", tpls[n].id)
+			f.putLinkAnnots(&annots, n, p.x, p.y)
+			f.putAttachmentAnnotationLinks(&annots, n, p.x, p.y)
+		}
+		if annots.Len() > 0 {
+			f.out("/Annots [" + annots.String() + "]")
+		}
+		if f.pdfVersion > pdfVers1_3 {
+			f.out("/Group <</Type /Group /S /Transparency /CS /DeviceRGB>>")
+		}
+		f.outf("/Contents %d 0 R>>", f.n+1)
+		f.out("endobj")
+		f.newobj()
+		f.putcontentstream("", content.Bytes())
+		f.out("endobj")
+	}
+	// Pages root
+	f.offsets[1] = f.buffer.Len()
+	f.out("1 0 obj")
+	f.out("<</Type /Pages")
+	var kidsStr fmtBuffer
+	kidsStr.printf("/Kids [")
+	for _, kid := range kids {
+		kidsStr.printf("%d 0 R ", kid)
+	}
+	kidsStr.printf("]")
+	f.out(kidsStr.String())
+	f.outf("/Count %d", len(sheets))
+	f.outf("/MediaBox [0 0 %.2f %.2f]", sheetW, sheetH)
+	f.out("/Resources 2 0 R")
+	f.out(">>")
+	f.out("endobj")
+}
+
+// sheetBox returns the page box t of a sheet: the smallest box that holds the
+// boxes t of the pages on the sheet, in points, or of their media box for
+// those that have none. It reports false if none of the pages has a box t.
+func (f *Fpdf) sheetBox(sheet []int, t string) (box PageBox, ok bool) {
+	first := true
+	for _, n := range sheet {
+		if n == 0 {
+			continue
+		}
+		p := f.imposed[n]
+		pb, set := f.pageBoxes[n][t]
+		if !set {
+			pb.Wd, pb.Ht = f.pageSizePt(n)
+			pb.X, pb.Y = 0, 0
+		}
+		ok = ok || set
+		x0, y0, x1, y1 := p.x+pb.X, p.y+pb.Y, p.x+pb.Wd, p.y+pb.Ht
+		if first {
+			box.X, box.Y, box.Wd, box.Ht = x0, y0, x1, y1
+			first = false
+			continue
+		}
+		box.X, box.Y = math.Min(box.X, x0), math.Min(box.Y, y0)
+		box.Wd, box.Ht = math.Max(box.Wd, x1), math.Max(box.Ht, y1)
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+// imposedPages returns the page numbers drawn on the sheets of pdf, with
+// their horizontal offsets, in output order.
+func imposedPages(t *testing.T, pdf *fpdf.Fpdf) (out string, pages []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out = buf.String()
+	re := regexp.MustCompile(`q 1 0 0 1 ([\d.]+) [\d.]+ cm /TPL(\d+) Do Q`)
+	for _, m := range re.FindAllStringSubmatch(out, -1) {
+		pages = append(pages, m[2]+"@"+m[1])
+	}
+	return
+}
+
+func TestImposeNUp(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	for range 5 {
+		pdf.AddPage()
+		pdf.Cell(40, 10, "Page")
+	}
+	pdf.Impose(fpdf.NUp(2))
+	out, pages := imposedPages(t, pdf)
+	want := "1@0.00000 2@595.28000 3@0.00000 4@595.28000 5@0.00000"
+	if got := strings.Join(pages, " "); got != want {
+		t.Errorf("got pages %q, want %q", got, want)
+	}
+	if !strings.Contains(out, "/Count 3") || !strings.Contains(out, "/MediaBox [0 0 1190.56 841.89]") {
+		t.Errorf("expecting three A3 landscape sheets")
+	}
+}
+
+func TestImposeBooklet(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	for range 5 {
+		pdf.AddPage()
+	}
+	pdf.Impose(fpdf.Booklet)
+	out, pages := imposedPages(t, pdf)
+	// Sides (8 1) (2 7) (6 3) (4 5) with pages 6 to 8 blank.
+	want := "1@595.28000 2@0.00000 3@595.28000 4@0.00000 5@595.28000"
+	if got := strings.Join(pages, " "); got != want {
+		t.Errorf("got pages %q, want %q", got, want)
+	}
+	if !strings.Contains(out, "/Count 4") {
+		t.Errorf("expecting four sheet sides")
+	}
+}
+
+func TestImposeUnsupported(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.Impose(fpdf.NUp(3))
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for 3-up imposition")
+	}
+}
+
+func TestImposeBoxesAndAttachments(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	pdf.SetPageBox("trim", 10, 10, 190, 277)
+	pdf.AddPage()
+	pdf.AddPage()
+	pdf.AddAttachmentAnnotation(&fpdf.Attachment{Content: []byte("data"), Filename: "data.txt"}, 10, 10, 20, 20)
+	pdf.Impose(fpdf.NUp(2))
+	out, pages := imposedPages(t, pdf)
+	if got := strings.Join(pages, " "); got != "1@0.00000 2@595.28000" {
+		t.Errorf("unexpected pages %q", got)
+	}
+	if !strings.Contains(out, "/TrimBox [28.35 28.35 1162.21 813.54]") {
+		t.Errorf("trim box of the pages not carried over to the sheet")
+	}
+	if !strings.Contains(out, "/Subtype /FileAttachment /Rect [623.63 813.54 680.32 756.85]") {
+		t.Errorf("attachment not moved to the position of its page on the sheet")
+	}
+}
@@ -0,0 +1,80 @@
+package fpdf
+
+import (
+	"sort"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// FontInfo describes a font of the document, as listed by FontList().
+type FontInfo struct {
+	Key  string // family in lowercase followed by the style, e.g. "arialB"
+	Name string // PostScript name, e.g. "Helvetica-Bold"
+	Type string // "Core", "TrueType", "Type1" or "UTF8"
+	// Used tells whether the font is selected on any page.
+	Used bool
+}
+
+// ImageInfo describes an image of the document, as listed by ImageList().
+type ImageInfo struct {
+	Name          string // name under which the image was registered
+	Width, Height int    // size in pixels
+	ColorSpace    string // "DeviceRGB", "DeviceGray", "Indexed" or "DeviceCMYK"
+	Bpc           int    // bits per component
+	Filter        string // compression of the data, e.g. "DCTDecode" for JPEG
+	// Used tells whether the image is drawn on any page.
+	Used bool
+}
+
+// The methods below give read-only access to the structure of the document,
+// for applications to check what they generate without parsing the PDF.
+
+// ObjectCount returns the number of objects of the PDF file once the
+// document has been output, or the number of objects written so far.
+func (f *Fpdf) ObjectCount() int {
+	return f.n
+}
+
+// PageContentString returns the content stream of page n, 1-based, before
+// compression: the operators that draw the page, such as "BT ... (Hello)Tj ET"
+// for text. It returns "" if the page does not exist.
+func (f *Fpdf) PageContentString(n int) string {
+	if n < 1 || n >= len(f.pages) || f.pages[n] == nil {
+		return ""
+	}
+	return f.pages[n].String()
+}
+
+// FontList returns the fonts added to the document, sorted by key.
+func (f *Fpdf) FontList() []FontInfo {
+	list := make([]FontInfo, 0, len(f.fonts))
+	for key, def := range f.fonts {
+		list = append(list, FontInfo{Key: key, Name: def.Name, Type: def.Tp, Used: f.pagesUse("/F" + def.i + " ")})
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Key < list[b].Key })
+	return list
+}
+
+// ImageList returns the images registered in the document, sorted by name.
+func (f *Fpdf) ImageList() []ImageInfo {
+	list := make([]ImageInfo, 0, len(f.images))
+	for name, info := range f.images {
+		list = append(list, ImageInfo{
+			Name: name, Width: int(info.w), Height: int(info.h),
+			ColorSpace: info.cs, Bpc: info.bpc, Filter: info.f,
+			Used: f.pagesUse("/I" + info.i + " Do"),
+		})
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list
+}
+
+// pagesUse tells whether the content of any page holds op.
+func (f *Fpdf) pagesUse(op string) bool {
+	for n := 1; n < len(f.pages); n++ {
+		if Contains(f.PageContentString(n), op) {
+			return true
+		}
+	}
+	return false
+}
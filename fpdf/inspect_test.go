@@ -0,0 +1,62 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestInspect(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetFont("Arial", "", 12)
+	pdf.Cell(40, 10, "Hello")
+	pdf.SetFont("Courier", "B", 12)
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.Image(ImageFile("logo.png"), 10, 10, 30, 0, false, "", 0, "")
+	pdf.RegisterImage(ImageFile("logo.jpg"), "")
+
+	if got := pdf.PageContentString(1); !strings.Contains(got, "(Hello)Tj") {
+		t.Errorf("text missing from the content of page 1: %q", got)
+	}
+	if got := pdf.PageContentString(3); got != "" {
+		t.Errorf("got content %q for a missing page", got)
+	}
+
+	fonts := pdf.FontList()
+	want := []fpdf.FontInfo{
+		{Key: "courierB", Name: "Courier-Bold", Type: "Core", Used: true},
+		{Key: "helvetica", Name: "Helvetica", Type: "Core", Used: true},
+	}
+	if len(fonts) != len(want) {
+		t.Fatalf("got fonts %+v, want %+v", fonts, want)
+	}
+	for j := range want {
+		if fonts[j] != want[j] {
+			t.Errorf("font %d: got %+v, want %+v", j, fonts[j], want[j])
+		}
+	}
+
+	images := pdf.ImageList()
+	if len(images) != 2 {
+		t.Fatalf("got images %+v, want 2", images)
+	}
+	if png := images[1]; !strings.HasSuffix(png.Name, "logo.png") || !png.Used || png.Width == 0 || png.Bpc != 8 {
+		t.Errorf("got image %+v for logo.png", png)
+	}
+	if jpg := images[0]; !strings.HasSuffix(jpg.Name, "logo.jpg") || jpg.Used || jpg.Filter != "DCTDecode" {
+		t.Errorf("got image %+v for the unused logo.jpg", jpg)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if n := pdf.ObjectCount(); !strings.Contains(buf.String(), "/Size "+strconv.Itoa(n+1)) {
+		t.Errorf("got %d objects, not matching the cross-reference table", n)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !wasm
+
+package fpdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// initIO sets the functions with which a document reads and writes files
+// until others are given to New(). Outside of the browser there are none:
+// they must be given with WriteFileFunc, ReadFileFunc and FileSizeFunc, or
+// replaced with a file system, see SetResourceFS().
+func (f *Fpdf) initIO() {
+	f.writeFile = func(filePath string, content []byte) error {
+		return Errf("writeFile function not configured for this environment")
+	}
+	f.readFile = func(filePath string) ([]byte, error) {
+		return nil, Errf("readFile function not configured for this environment")
+	}
+	f.fileSize = func(filePath string) (int64, error) {
+		return 0, Errf("fileSize function not configured for this environment")
+	}
+}
@@ -0,0 +1,84 @@
+//go:build wasm
+
+package fpdf
+
+import (
+	"bytes"
+	"path"
+	"syscall/js"
+
+	"github.com/tinywasm/fetch"
+	. "github.com/tinywasm/fmt"
+)
+
+// initIO sets the functions with which a document reads and writes files
+// until others are given to New(). In the browser, files are read with
+// BrowserFetch() and written with BrowserDownload(), so that documents work
+// without any configuration.
+func (f *Fpdf) initIO() {
+	f.writeFile = BrowserDownload
+	f.readFile = BrowserFetch
+	f.fileSize = func(filePath string) (int64, error) {
+		data, err := BrowserFetch(filePath)
+		return int64(len(data)), err
+	}
+}
+
+// BrowserDownload offers content to the user of the browser as a download
+// named after the last element of filePath. It can be given to New() as a
+// WriteFileFunc, and is the default in the browser, so that
+// OutputFileAndClose() downloads the document.
+func BrowserDownload(filePath string, content []byte) error {
+	document := js.Global().Get("document")
+	if document.IsUndefined() {
+		return Errf("BrowserDownload: no document to download %s from", filePath)
+	}
+	data := js.Global().Get("Uint8Array").New(len(content))
+	js.CopyBytesToJS(data, content)
+	blob := js.Global().Get("Blob").New([]any{data}, map[string]any{"type": "application/pdf"})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+	link := document.Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", path.Base(filePath))
+	link.Call("click")
+	js.Global().Get("URL").Call("revokeObjectURL", url)
+	return nil
+}
+
+// BrowserFetch returns the content of the file at filePath, a URL relative
+// to the page or absolute, read with an HTTP GET request. It can be given to
+// New() as a ReadFileFunc, and is the default in the browser, so that fonts
+// and images are loaded from the server of the page. It must not be called
+// from the goroutine of the JavaScript event loop, which it would block.
+func BrowserFetch(filePath string) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	ch := make(chan result, 1)
+	fetch.Get(filePath).Send(func(resp *fetch.Response, err error) {
+		switch {
+		case err != nil:
+			ch <- result{err: err}
+		case resp.Status != 200:
+			ch <- result{err: Errf("error fetching file %s: status %d", filePath, resp.Status)}
+		default:
+			ch <- result{data: resp.Body()}
+		}
+	})
+	res := <-ch
+	return res.data, res.err
+}
+
+// OutputToUint8Array closes the document and returns its content as a
+// JavaScript Uint8Array, ready to be wrapped in a Blob, posted to a worker or
+// sent with fetch.
+func (f *Fpdf) OutputToUint8Array() (js.Value, error) {
+	var buf bytes.Buffer
+	if err := f.Output(&buf); err != nil {
+		return js.Undefined(), err
+	}
+	data := js.Global().Get("Uint8Array").New(buf.Len())
+	js.CopyBytesToJS(data, buf.Bytes())
+	return data, nil
+}
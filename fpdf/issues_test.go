@@ -38,7 +38,7 @@ var summaryCompare = SummaryCompare
 
 func init() {
 	if runtime.GOOS == "windows" {
-		summaryCompare = Summary
+		summaryCompare = func(t testing.TB, err error, fileStr string) { Summary(err, fileStr) }
 	}
 }
 
@@ -235,7 +235,7 @@ func TestAFMFontParser(t *testing.T) {
 	err := fpdf.MakeFont(
 		FontFile("cmmi10.pfb"),
 		FontFile("cp1252.map"),
-		FontsDirName(),
+		t.TempDir(),
 		nil, embed,
 	)
 	if err != nil {
@@ -17,7 +17,7 @@ func (f *Fpdf) SetJavascript(script string) {
 }
 
 func (f *Fpdf) putjavascript() {
-	if f.javascript == nil {
+	if f.javascript == nil || f.sanitize {
 		return
 	}
 
@@ -0,0 +1,83 @@
+package fpdf
+
+// LabelSheet describes a sheet of adhesive labels: a grid of Cols by Rows
+// labels of Width by Height, the first one Left and Top from the top left
+// corner of the sheet, with HGap between the columns and VGap between the
+// rows. Dimensions are in millimeters, as printed on the packs of labels,
+// whatever the unit of the document.
+type LabelSheet struct {
+	Name           string
+	PageWd, PageHt float64 // size of the sheet
+	Cols, Rows     int
+	Width, Height  float64 // size of a label
+	Left, Top      float64 // position of the first label
+	HGap, VGap     float64
+}
+
+// Common Avery label sheets. The L-series are A4 sheets, the others US
+// Letter sheets.
+var (
+	// AveryL7160 holds 21 address labels of 63.5 x 38.1 mm
+	AveryL7160 = LabelSheet{Name: "Avery L7160", PageWd: 210, PageHt: 297, Cols: 3, Rows: 7,
+		Width: 63.5, Height: 38.1, Left: 7.25, Top: 15.15, HGap: 2.5}
+	// AveryL7161 holds 18 address labels of 63.5 x 46.6 mm
+	AveryL7161 = LabelSheet{Name: "Avery L7161", PageWd: 210, PageHt: 297, Cols: 3, Rows: 6,
+		Width: 63.5, Height: 46.6, Left: 7.25, Top: 8.7, HGap: 2.5}
+	// AveryL7163 holds 14 parcel labels of 99.1 x 38.1 mm
+	AveryL7163 = LabelSheet{Name: "Avery L7163", PageWd: 210, PageHt: 297, Cols: 2, Rows: 7,
+		Width: 99.1, Height: 38.1, Left: 4.65, Top: 15.15, HGap: 2.5}
+	// AveryL7651 holds 65 mini labels of 38.1 x 21.2 mm
+	AveryL7651 = LabelSheet{Name: "Avery L7651", PageWd: 210, PageHt: 297, Cols: 5, Rows: 13,
+		Width: 38.1, Height: 21.2, Left: 4.75, Top: 10.7, HGap: 2.5}
+	// Avery5160 holds 30 address labels of 1 x 2 5/8 inches
+	Avery5160 = LabelSheet{Name: "Avery 5160", PageWd: 215.9, PageHt: 279.4, Cols: 3, Rows: 10,
+		Width: 66.675, Height: 25.4, Left: 4.7625, Top: 12.7, HGap: 3.175}
+	// Avery5163 holds 10 shipping labels of 2 x 4 inches
+	Avery5163 = LabelSheet{Name: "Avery 5163", PageWd: 215.9, PageHt: 279.4, Cols: 2, Rows: 5,
+		Width: 101.6, Height: 50.8, Left: 3.96875, Top: 12.7, HGap: 4.7625}
+)
+
+// PerSheet returns the number of labels on a sheet.
+func (s LabelSheet) PerSheet() int {
+	return s.Cols * s.Rows
+}
+
+// Labels prints n labels on sheets laid out as s. Each sheet is a new page
+// of the size of the sheet, and the labels fill it left to right, then top to
+// bottom. The first skip labels of the first sheet are left blank, so that a
+// partly used sheet can be printed again.
+//
+// draw is called for each label with its index, from 0 to n-1, and its
+// rectangle in the unit of the document. What it draws is clipped to the
+// label, and automatic page breaks are off while it runs.
+func (f *Fpdf) Labels(s LabelSheet, skip, n int, draw func(i int, x, y, w, h float64)) {
+	if f.err != nil {
+		return
+	}
+	if s.Cols < 1 || s.Rows < 1 || s.Width <= 0 || s.Height <= 0 || s.PageWd <= 0 || s.PageHt <= 0 {
+		f.errorf("Labels", "invalid label sheet %q", s.Name)
+		return
+	}
+	if skip < 0 || skip >= s.PerSheet() {
+		f.errorf("Labels", "cannot skip %d labels of a sheet of %d", skip, s.PerSheet())
+		return
+	}
+	mm := 72 / 25.4 / f.k
+	auto, margin := f.GetAutoPageBreak()
+	f.SetAutoPageBreak(false, margin)
+	defer f.SetAutoPageBreak(auto, margin)
+	for i := 0; i < n && f.err == nil; i++ {
+		pos := (skip + i) % s.PerSheet()
+		if i == 0 || pos == 0 {
+			// AddPageFormat takes the size in points
+			f.AddPageFormat(Portrait, PageSize{Wd: s.PageWd * 72 / 25.4, Ht: s.PageHt * 72 / 25.4})
+		}
+		col, row := pos%s.Cols, pos/s.Cols
+		x := (s.Left + float64(col)*(s.Width+s.HGap)) * mm
+		y := (s.Top + float64(row)*(s.Height+s.VGap)) * mm
+		w, h := s.Width*mm, s.Height*mm
+		f.ClipRect(x, y, w, h, false)
+		draw(i, x, y, w, h)
+		f.ClipEnd()
+	}
+}
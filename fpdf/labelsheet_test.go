@@ -0,0 +1,71 @@
+package fpdf_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestLabels(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetFont("Arial", "", 10)
+	type label struct {
+		page       int
+		x, y, w, h float64
+	}
+	var labels []label
+	pdf.Labels(fpdf.AveryL7163, 3, 12, func(i int, x, y, w, h float64) {
+		if i != len(labels) {
+			t.Errorf("got label %d, want %d", i, len(labels))
+		}
+		labels = append(labels, label{pdf.PageNo(), x, y, w, h})
+		pdf.SetXY(x, y)
+		pdf.Cell(w, h, "Label")
+	})
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 12 || pdf.PageCount() != 2 {
+		t.Fatalf("got %d labels on %d pages, want 12 on 2", len(labels), pdf.PageCount())
+	}
+	mm := 72 / 25.4
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	// The first label is the second one of the second row
+	first := labels[0]
+	if first.page != 1 || !near(first.x, (4.65+99.1+2.5)*mm) || !near(first.y, (15.15+38.1)*mm) ||
+		!near(first.w, 99.1*mm) || !near(first.h, 38.1*mm) {
+		t.Errorf("got first label %+v", first)
+	}
+	// The 11 remaining labels of the sheet are followed by one on a new sheet
+	if last := labels[11]; last.page != 2 || !near(last.x, 4.65*mm) || !near(last.y, 15.15*mm) {
+		t.Errorf("got last label %+v, want the first of the second sheet", last)
+	}
+	if auto, _ := pdf.GetAutoPageBreak(); !auto {
+		t.Error("automatic page breaks not restored")
+	}
+	if pdf.PageCount() != 2 {
+		t.Errorf("label text broke the page")
+	}
+}
+
+func TestLabelsInvalid(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.Labels(fpdf.AveryL7160, 21, 1, func(int, float64, float64, float64, float64) {})
+	if pdf.Error() == nil {
+		t.Error("no error for skipping a whole sheet")
+	}
+	pdf = NewDocPdfTest()
+	pdf.Labels(fpdf.LabelSheet{Name: "empty"}, 0, 1, func(int, float64, float64, float64, float64) {})
+	if pdf.Error() == nil {
+		t.Error("no error for a sheet without labels")
+	}
+}
+
+func TestLabelsSheetSize(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.IN)
+	pdf.Labels(fpdf.Avery5160, 0, 1, func(int, float64, float64, float64, float64) {})
+	if w, h, _ := pdf.PageSize(1); math.Abs(w-8.5) > 1e-6 || math.Abs(h-11) > 1e-6 {
+		t.Errorf("got a sheet of %.2f x %.2f in, want 8.5 x 11 in", w, h)
+	}
+}
@@ -6,7 +6,30 @@ package fpdf
 type layerType struct {
 	name    string
 	visible bool
-	objNum  int // object number
+	objNum  int  // object number
+	group   bool // label in the layer panel without content of its own
+	parent  int  // layer or group under which this one is listed, -1 for none
+	options LayerOptions
+}
+
+// LayerState is the state of a layer when the document is printed or
+// exported.
+type LayerState int
+
+const (
+	// LayerStateDefault leaves the state to the visibility of the layer.
+	LayerStateDefault LayerState = iota
+	// LayerOn includes the layer.
+	LayerOn
+	// LayerOff leaves the layer out.
+	LayerOff
+)
+
+// LayerOptions specifies how a layer added with AddLayerOptions() is used.
+type LayerOptions struct {
+	Intent string     // "View" (default) for layers to show or hide, "Design" for layers of design work, or "All" for both
+	Print  LayerState // state of the layer when the document is printed
+	Export LayerState // state of the layer when the document is exported to another format
 }
 
 type layerRecType struct {
@@ -28,16 +51,65 @@ func (f *Fpdf) layerInit() {
 // to BeginLayer().
 func (f *Fpdf) AddLayer(name string, visible bool) (layerID int) {
 	layerID = len(f.layer.list)
-	f.layer.list = append(f.layer.list, layerType{name: name, visible: visible})
+	f.layer.list = append(f.layer.list, layerType{name: name, visible: visible, parent: -1})
 	return
 }
 
+// AddLayerOptions defines a layer like AddLayer() with the intent and the
+// print and export states given in options. A watermark that is seen on
+// screen but never printed, for example, is placed on a layer with
+// LayerOptions{Print: LayerOff}. Note that viewers apply the print and
+// export states only when the corresponding events occur, so the layer keeps
+// its visible state in the layer panel.
+func (f *Fpdf) AddLayerOptions(name string, visible bool, options LayerOptions) (layerID int) {
+	switch options.Intent {
+	case "", "View", "Design", "All":
+	default:
+		f.errorf("AddLayerOptions", "invalid layer intent: %s", options.Intent)
+		return -1
+	}
+	layerID = f.AddLayer(name, visible)
+	f.layer.list[layerID].options = options
+	return
+}
+
+// AddLayerGroup defines a label under which layers are grouped in the layer
+// panel of the document reader, see SetLayerParent(). A group has no content
+// and cannot be passed to BeginLayer(); it is shown even when none of its
+// layers is visible.
+func (f *Fpdf) AddLayerGroup(name string) (groupID int) {
+	groupID = len(f.layer.list)
+	f.layer.list = append(f.layer.list, layerType{name: name, group: true, parent: -1})
+	return
+}
+
+// SetLayerParent lists the layer or group id under parentID in the layer
+// panel. A layer listed under another layer is hidden by the reader whenever
+// its parent is hidden. A parentID of -1 lists the layer at the top level.
+func (f *Fpdf) SetLayerParent(id, parentID int) {
+	if id < 0 || id >= len(f.layer.list) || parentID < -1 || parentID >= len(f.layer.list) {
+		f.errorf("SetLayerParent", "undefined layer: %d", id)
+		return
+	}
+	for p := parentID; p >= 0; p = f.layer.list[p].parent {
+		if p == id {
+			f.errorf("SetLayerParent", "layer %d cannot be nested in itself", id)
+			return
+		}
+	}
+	f.layer.list[id].parent = parentID
+}
+
 // BeginLayer is called to begin adding content to the specified layer. All
 // content added to the page between a call to BeginLayer and a call to
 // EndLayer is added to the layer specified by id. See AddLayer for more
 // details.
 func (f *Fpdf) BeginLayer(id int) {
 	f.EndLayer()
+	if id >= 0 && id < len(f.layer.list) && f.layer.list[id].group {
+		f.errorf("BeginLayer", "layer %d is a group", id)
+		return
+	}
 	if id >= 0 && id < len(f.layer.list) {
 		f.outf("/OC /OC%d BDC", id)
 		f.layer.currentLayer = id
@@ -53,6 +125,30 @@ func (f *Fpdf) EndLayer() {
 	}
 }
 
+// SetImageLayer places every following placement of the registered image
+// imageNameStr on the layer id, as if each call to ImageOptions() for it were
+// enclosed by BeginLayer() and EndLayer(), without ending the layer that is
+// active at the time. An id of -1 leaves the image out of any layer again.
+func (f *Fpdf) SetImageLayer(imageNameStr string, id int) {
+	info, ok := f.images[imageNameStr]
+	if !ok {
+		f.errorf("SetImageLayer", "image %s is not registered", imageNameStr)
+		return
+	}
+	if id == -1 {
+		delete(f.imageLayers, info.i)
+		return
+	}
+	if id < 0 || id >= len(f.layer.list) || f.layer.list[id].group {
+		f.errorf("SetImageLayer", "undefined layer: %d", id)
+		return
+	}
+	if f.imageLayers == nil {
+		f.imageLayers = make(map[string]int)
+	}
+	f.imageLayers[info.i] = id
+}
+
 // OpenLayerPane advises the document reader to open the layer pane when the
 // document is initially displayed.
 func (f *Fpdf) OpenLayerPane() {
@@ -70,18 +166,50 @@ func (f *Fpdf) layerEndDoc() {
 
 func (f *Fpdf) layerPutLayers() {
 	for j, l := range f.layer.list {
+		if l.group {
+			continue
+		}
 		f.newobj()
 		f.layer.list[j].objNum = f.n
-		f.outf("<</Type /OCG /Name %s>>", f.textstring(utf8toutf16(l.name)))
+		var dict fmtBuffer
+		dict.printf("<</Type /OCG /Name %s", f.textstring(utf8toutf16(l.name)))
+		switch l.options.Intent {
+		case "Design":
+			dict.printf(" /Intent /Design")
+		case "All":
+			dict.printf(" /Intent [/View /Design]")
+		}
+		if l.options.Print != LayerStateDefault || l.options.Export != LayerStateDefault {
+			dict.printf(" /Usage <<")
+			if l.options.Print != LayerStateDefault {
+				dict.printf("/Print <</PrintState /%s>>", l.options.Print.name())
+			}
+			if l.options.Export != LayerStateDefault {
+				dict.printf("/Export <</ExportState /%s>>", l.options.Export.name())
+			}
+			dict.printf(">>")
+		}
+		dict.printf(">>")
+		f.out(dict.String())
 		f.out("endobj")
 	}
 }
 
+// name returns the PDF name of a print or export state.
+func (s LayerState) name() string {
+	if s == LayerOff {
+		return "OFF"
+	}
+	return "ON"
+}
+
 func (f *Fpdf) layerPutResourceDict() {
 	if len(f.layer.list) > 0 {
 		f.out("/Properties <<")
 		for j, layer := range f.layer.list {
-			f.outf("/OC%d %d 0 R", j, layer.objNum)
+			if !layer.group {
+				f.outf("/OC%d %d 0 R", j, layer.objNum)
+			}
 		}
 		f.out(">>")
 	}
@@ -92,15 +220,58 @@ func (f *Fpdf) layerPutCatalog() {
 	if len(f.layer.list) > 0 {
 		onStr := ""
 		offStr := ""
+		printStr := ""
+		exportStr := ""
 		for _, layer := range f.layer.list {
+			if layer.group {
+				continue
+			}
 			onStr += sprintf("%d 0 R ", layer.objNum)
 			if !layer.visible {
 				offStr += sprintf("%d 0 R ", layer.objNum)
 			}
+			if layer.options.Print != LayerStateDefault {
+				printStr += sprintf("%d 0 R ", layer.objNum)
+			}
+			if layer.options.Export != LayerStateDefault {
+				exportStr += sprintf("%d 0 R ", layer.objNum)
+			}
+		}
+		// Viewers apply the usage of the layers on the events listed in /AS.
+		asStr := ""
+		if printStr != "" {
+			asStr += sprintf("<</Event /Print /OCGs [%s] /Category [/Print]>>", printStr)
+		}
+		if exportStr != "" {
+			asStr += sprintf("<</Event /Export /OCGs [%s] /Category [/Export]>>", exportStr)
+		}
+		if asStr != "" {
+			asStr = " /AS [" + asStr + "]"
 		}
-		f.outf("/OCProperties <</OCGs [%s] /D <</OFF [%s] /Order [%s]>>>>", onStr, offStr, onStr)
+		f.outf("/OCProperties <</OCGs [%s] /D <</OFF [%s] /Order [%s]%s>>>>", onStr, offStr, f.layerOrder(-1), asStr)
 		if f.layer.openLayerPane {
 			f.out("/PageMode /UseOC")
 		}
 	}
 }
+
+// layerOrder returns the entries of the /Order array of the layer panel for
+// the layers and groups listed under parent.
+func (f *Fpdf) layerOrder(parent int) string {
+	var order fmtBuffer
+	for j, layer := range f.layer.list {
+		if layer.parent != parent {
+			continue
+		}
+		children := f.layerOrder(j)
+		if layer.group {
+			order.printf("[%s %s] ", f.textstring(utf8toutf16(layer.name)), children)
+			continue
+		}
+		order.printf("%d 0 R ", layer.objNum)
+		if children != "" {
+			order.printf("[%s] ", children)
+		}
+	}
+	return order.String()
+}
@@ -0,0 +1,101 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestLayerOptions(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	group := pdf.AddLayerGroup("Marks")
+	base := pdf.AddLayer("Base", true)
+	watermark := pdf.AddLayerOptions("Watermark", true, fpdf.LayerOptions{Print: fpdf.LayerOff})
+	guides := pdf.AddLayerOptions("Guides", false, fpdf.LayerOptions{Intent: "Design", Export: fpdf.LayerOn})
+	pdf.SetLayerParent(watermark, group)
+	pdf.SetLayerParent(guides, group)
+	pdf.AddPage()
+	for _, l := range []int{base, watermark, guides} {
+		pdf.BeginLayer(l)
+		pdf.Cell(0, 10, "Layer")
+		pdf.Ln(10)
+	}
+	pdf.EndLayer()
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"<</Type /OCG /Name (\xfe\xff\x00B\x00a\x00s\x00e)>>",
+		"/Usage <</Print <</PrintState /OFF>>>>>>",
+		"/Intent /Design /Usage <</Export <</ExportState /ON>>>>>>",
+		"/Order [[(\xfe\xff\x00M\x00a\x00r\x00k\x00s) 6 0 R 7 0 R ] 5 0 R ]",
+		"/AS [<</Event /Print /OCGs [6 0 R ] /Category [/Print]>><</Event /Export /OCGs [7 0 R ] /Category [/Export]>>]",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
+
+func TestLayerErrors(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddLayerOptions("Bad", true, fpdf.LayerOptions{Intent: "Print"})
+	if !pdf.Err() {
+		t.Errorf("expected error for an invalid intent")
+	}
+
+	pdf = NewDocPdfTest()
+	a := pdf.AddLayer("A", true)
+	b := pdf.AddLayer("B", true)
+	pdf.SetLayerParent(b, a)
+	pdf.SetLayerParent(a, b)
+	if !pdf.Err() {
+		t.Errorf("expected error for a layer nested in itself")
+	}
+
+	pdf = NewDocPdfTest()
+	group := pdf.AddLayerGroup("Group")
+	pdf.AddPage()
+	pdf.BeginLayer(group)
+	if !pdf.Err() {
+		t.Errorf("expected error for content added to a group")
+	}
+}
+
+func TestSetImageLayer(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	watermark := pdf.AddLayerOptions("Watermark", true, fpdf.LayerOptions{Print: fpdf.LayerOff})
+	logo := ImageFile("logo.png")
+	pdf.RegisterImageOptions(logo, fpdf.ImageOptions{})
+	pdf.SetImageLayer(logo, watermark)
+	pdf.AddPage()
+	pdf.ImageOptions(logo, 10, 10, 30, 0, false, fpdf.ImageOptions{}, 0, "")
+	pdf.SetImageLayer(logo, -1)
+	pdf.ImageOptions(logo, 10, 50, 30, 0, false, fpdf.ImageOptions{}, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if got := strings.Count(out, "/OC /OC0 BDC\nq "); got != 1 {
+		t.Errorf("invalid number of image placements on the layer: got=%d, want=1", got)
+	}
+	if got := strings.Count(out, " Do Q\nEMC\n"); got != 1 {
+		t.Errorf("invalid number of layers ended after an image: got=%d, want=1", got)
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.SetImageLayer("missing.png", 0)
+	if !pdf.Err() {
+		t.Errorf("expected error for an image that is not registered")
+	}
+}
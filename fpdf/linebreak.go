@@ -0,0 +1,241 @@
+package fpdf
+
+import (
+	"unicode"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// Line breaking classes of the Unicode line breaking algorithm (UAX #14),
+// reduced to those that matter for the text laid out by MultiCell(), Write()
+// and SplitText().
+const (
+	lbAL = iota // alphabetic and other characters
+	lbBK        // mandatory break, such as a newline
+	lbSP        // space
+	lbZW        // zero width space, a break opportunity
+	lbWJ        // word joiner, preventing breaks on both sides
+	lbGL        // non-breaking ("glue") characters, such as the no-break space
+	lbBA        // break after, such as the soft hyphen
+	lbHY        // hyphen-minus
+	lbBB        // break before
+	lbOP        // opening punctuation
+	lbCL        // closing punctuation
+	lbCP        // closing parenthesis
+	lbEX        // exclamation and interrogation
+	lbIS        // infix numeric separator
+	lbSY        // symbols allowing a break after, such as the slash
+	lbNS        // non-starters, such as small kana
+	lbQU        // quotation marks
+	lbNU        // digits
+	lbPR        // prefix numeric, such as currency symbols
+	lbPO        // postfix numeric, such as the percent sign
+	lbID        // ideographs, breakable before and after
+	lbCM        // combining marks
+)
+
+// softHyphen marks a place where a word may be hyphenated.
+const softHyphen = '\u00ad'
+
+// asciiBreakClass holds the line breaking classes of the ASCII characters,
+// which make up most text, to spare lineBreakClass() the Unicode tables.
+var asciiBreakClass = func() (cls [0x80]int8) {
+	for r := range cls {
+		cls[r] = int8(runeBreakClass(rune(r)))
+	}
+	return
+}()
+
+// lineBreakClass returns the line breaking class of r.
+func lineBreakClass(r rune) int {
+	if r >= 0 && r < 0x80 {
+		return int(asciiBreakClass[r])
+	}
+	return runeBreakClass(r)
+}
+
+// runeBreakClass returns the line breaking class of r from the Unicode
+// tables.
+func runeBreakClass(r rune) int {
+	switch r {
+	case '\n', '\v', '\f', '\r', 0x2028, 0x2029:
+		return lbBK
+	case ' ':
+		return lbSP
+	case 0x200b:
+		return lbZW
+	case 0x2060, 0xfeff:
+		return lbWJ
+	case 0xa0, 0x202f, 0x2007, 0x2011, 0x034f, 0x0f0c:
+		return lbGL
+	case '\t', softHyphen, 0x2010, 0x2012, 0x2013, 0x2014, 0x1680, 0x2000, 0x2001, 0x2002,
+		0x2003, 0x2004, 0x2005, 0x2006, 0x2008, 0x2009, 0x200a, '|':
+		return lbBA
+	case '-':
+		return lbHY
+	case 0xb4, 0x2c8, 0x2cc:
+		return lbBB
+	case ')', ']':
+		return lbCP
+	case '!', '?', 0xff01, 0xff1f, 0x203c, 0x2047, 0x2048, 0x2049:
+		return lbEX
+	case ',', '.', ':', ';', 0x37e, 0x589, 0x60c, 0x60d, 0x7f8, 0x2044, 0xfe10, 0xfe13, 0xfe14:
+		return lbIS
+	case '/':
+		return lbSY
+	case '"', '\'', 0xab, 0xbb, 0x2018, 0x2019, 0x201b, 0x201c, 0x201d, 0x201f, 0x2039, 0x203a:
+		return lbQU
+	case '$', '+', '\\', 0xa3, 0xa5, 0xb1, 0x20ac, 0x2116, 0x2212:
+		return lbPR
+	case '%', 0xa2, 0xb0, 0x2030, 0x2031, 0x2032, 0x2033, 0x2103, 0x2109, 0xffe0:
+		return lbPO
+	case 0x3001, 0x3002, 0xff0c, 0xff0e, 0xff61, 0xff64:
+		return lbCL
+	case 0x3005, 0x301c, 0x303b, 0x309b, 0x309c, 0x309d, 0x309e, 0x30a0, 0x30fb, 0x30fc,
+		0x30fd, 0x30fe, 0xff1a, 0xff1b, 0xff65, 0xff70:
+		return lbNS
+	}
+	switch {
+	case r < 0x20 || r >= 0x7f && r < 0xa0:
+		return lbAL
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r):
+		return lbCM
+	case unicode.Is(unicode.Ps, r):
+		return lbOP
+	case unicode.Is(unicode.Pe, r):
+		return lbCL
+	case unicode.Is(unicode.Pi, r), unicode.Is(unicode.Pf, r):
+		return lbQU
+	case unicode.IsDigit(r):
+		return lbNU
+	case isCJK(r):
+		if Contains(verticalSmall, string(r)) {
+			return lbNS
+		}
+		return lbID
+	}
+	return lbAL
+}
+
+// lineBreaks returns, for each rune of s, whether a line may be broken
+// before it, following the pair rules of the Unicode line breaking algorithm
+// (UAX #14): there is no break before closing punctuation, after opening
+// punctuation, around no-break spaces and word joiners or inside numbers, and
+// there is a break after spaces, hyphens and soft hyphens and around
+// ideographs. Spaces are not put at the start of a line but dropped at its
+// end, so the value of a space tells whether the line may end at it, and the
+// rune after the spaces reports no break.
+// A newline is reported as a break opportunity after it; where the line must
+// end is left to the caller.
+func lineBreaks(s []rune) []bool {
+	cls := make([]int8, len(s))
+	for i, r := range s {
+		cls[i] = int8(lineBreakClass(r))
+		// A combining mark takes the class of its base (LB9), or is
+		// alphabetic if it has none (LB10).
+		if cls[i] == lbCM {
+			cls[i] = lbAL
+			if i > 0 && cls[i-1] != lbSP && cls[i-1] != lbBK && cls[i-1] != lbZW {
+				cls[i] = cls[i-1]
+			}
+		}
+	}
+	brk := make([]bool, len(s))
+	pre := lbBK // class before the spaces preceding the current rune
+	for i := 1; i < len(s); i++ {
+		a, b := int(cls[i-1]), int(cls[i])
+		if a != lbSP {
+			pre = a
+		}
+		if a == lbAL && b == lbAL {
+			continue // inside a word, the most frequent pair (LB28)
+		}
+		brk[i] = lineBreakPair(a, b, pre)
+	}
+	// A line ends at a run of spaces if it may be broken after the run, and
+	// this break is reported at the spaces only.
+	for i := len(s) - 1; i >= 0; i-- {
+		if cls[i] == lbSP {
+			brk[i] = i+1 == len(s) || brk[i+1] || cls[i+1] == lbBK
+			if i+1 < len(s) && cls[i+1] != lbSP && cls[i+1] != lbBK {
+				brk[i+1] = false
+			}
+		}
+	}
+	return brk
+}
+
+// lineBreakPair returns whether a line may be broken between a rune of class
+// a and one of class b. pre is the class of the last rune before a that is
+// not a space, or a itself.
+func lineBreakPair(a, b, pre int) bool {
+	switch {
+	case a == lbBK:
+		return true // LB4, LB5
+	case b == lbBK, b == lbSP, b == lbZW:
+		return false // LB6, LB7
+	case pre == lbZW:
+		return true // LB8
+	case a == lbWJ, b == lbWJ, a == lbGL:
+		return false // LB11, LB12
+	case b == lbGL:
+		return a == lbSP || a == lbBA || a == lbHY // LB12a
+	case b == lbCL, b == lbCP, b == lbEX, b == lbIS, b == lbSY:
+		return false // LB13
+	case pre == lbOP:
+		return false // LB14
+	case pre == lbQU && b == lbOP:
+		return false // LB15
+	case (pre == lbCL || pre == lbCP) && b == lbNS:
+		return false // LB16
+	case a == lbSP:
+		return true // LB18
+	case a == lbQU, b == lbQU:
+		return false // LB19
+	case b == lbBA, b == lbHY, b == lbNS, a == lbBB:
+		return false // LB21
+	case a == lbAL && b == lbNU, a == lbNU && b == lbAL:
+		return false // LB23
+	case a == lbPR && (b == lbID || b == lbAL), (a == lbAL || a == lbNU) && b == lbPO:
+		return false // LB24
+	case (a == lbCL || a == lbCP || a == lbNU) && (b == lbPO || b == lbPR),
+		(a == lbPO || a == lbPR) && (b == lbOP || b == lbNU),
+		(a == lbHY || a == lbIS || a == lbSY || a == lbNU || a == lbOP) && b == lbNU:
+		return false // LB25
+	case a == lbAL && b == lbAL, a == lbIS && b == lbAL:
+		return false // LB28, LB29
+	case a == lbSY && b == lbAL:
+		return false // tailored to keep paths and URLs together
+	case (a == lbAL || a == lbNU) && b == lbOP, a == lbCP && (b == lbAL || b == lbNU):
+		return false // LB30
+	}
+	return true // LB31
+}
+
+// breakRunes returns the characters of s, a string in the encoding of the
+// current font, for lineBreaks().
+func (f *Fpdf) breakRunes(s string) []rune {
+	if f.isCurrentUTF8 {
+		return []rune(s)
+	}
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+	return runes
+}
+
+// hyphenate returns txt, a line of text broken by MultiCell(), Write() or
+// SplitText(), without its soft hyphens shy, ending with a hyphen if the line
+// was broken at one.
+func hyphenate(txt, shy string, broken bool) string {
+	if !Contains(txt, shy) {
+		return txt
+	}
+	hyphen := broken && len(txt) >= len(shy) && txt[len(txt)-len(shy):] == shy
+	txt = Convert(txt).Replace(shy, "").String()
+	if hyphen {
+		txt += "-"
+	}
+	return txt
+}
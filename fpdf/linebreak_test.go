@@ -0,0 +1,42 @@
+package fpdf
+
+import "testing"
+
+func TestLineBreaks(t *testing.T) {
+	// In want, "|" marks a break before a rune and "~" a space at which a
+	// line may not end.
+	for _, c := range []struct {
+		text, want string
+	}{
+		{"hello world", "hello world"},
+		{"a (b) c", "a (b) c"},
+		{"( x", "(~x"},
+		{"quoi ?", "quoi~?"},
+		{"well-known", "well-|known"},
+		{"-5 and $100", "-5 and $100"},
+		{"a\u00a0b", "a\u00a0b"},
+		{"extra\u00adordinary", "extra\u00ad|ordinary"},
+		{"http://a.b/c", "http://a.b/c"},
+		{"日本語", "日|本|語"},
+		{"です。次", "で|す。|次"},
+		{"ちょっと", "ちょっ|と"},
+		{"「日本」", "「日|本」"},
+		{"word日本", "word|日|本"},
+	} {
+		runes := []rune(c.text)
+		brk := lineBreaks(runes)
+		var got []rune
+		for i, r := range runes {
+			switch {
+			case r == ' ' && !brk[i]:
+				r = '~'
+			case r != ' ' && brk[i]:
+				got = append(got, '|')
+			}
+			got = append(got, r)
+		}
+		if string(got) != c.want {
+			t.Errorf("%q: got %q, want %q", c.text, string(got), c.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package fpdf
+
+// LinkOptions specifies the appearance and behavior of a link placed with
+// LinkOptions(), LinkStringOptions() or LinkFile(). The zero value gives the
+// same invisible link as Link().
+type LinkOptions struct {
+	BorderWidth float64 // border width in the unit of measure specified in New(), 0 for none
+	BorderStyle string  // "S" solid (default), "D" dashed, "B" beveled, "I" inset or "U" underline
+	BorderColor RGBType // border color
+	Highlight   string  // look when clicked: "I" invert (default), "N" none, "O" outline or "P" push
+	Tooltip     string  // text shown by the viewer when the pointer rests on the link
+}
+
+// LinkOptions puts a link to an internal destination on a rectangular area
+// of the page like Link(), with a visible border, highlight mode or tooltip
+// as set in options. link is the value returned by AddLink().
+func (f *Fpdf) LinkOptions(x, y, w, h float64, link int, options LinkOptions) {
+	f.newLinkOptions("LinkOptions", x, y, w, h, link, "", 0, options)
+}
+
+// LinkStringOptions puts a link to the URL linkStr on a rectangular area of
+// the page like LinkString(), with a visible border, highlight mode or
+// tooltip as set in options.
+func (f *Fpdf) LinkStringOptions(x, y, w, h float64, linkStr string, options LinkOptions) {
+	f.newLinkOptions("LinkStringOptions", x, y, w, h, 0, linkStr, 0, options)
+}
+
+// LinkFile puts a link on a rectangular area of the page that opens page
+// (1-based) of the external PDF file fileStr. A relative path is resolved
+// by the viewer against the location of the document. options sets the
+// appearance of the link; see LinkOptions().
+func (f *Fpdf) LinkFile(x, y, w, h float64, fileStr string, page int, options LinkOptions) {
+	if page < 1 {
+		f.errorf("LinkFile", "invalid page number: %d", page)
+		return
+	}
+	f.newLinkOptions("LinkFile", x, y, w, h, 0, fileStr, page, options)
+}
+
+// newLinkOptions validates options and adds the link to the current page.
+// A non-zero filePage makes linkStr the path of an external PDF file.
+func (f *Fpdf) newLinkOptions(method string, x, y, w, h float64, link int, linkStr string, filePage int, options LinkOptions) {
+	if f.err != nil {
+		return
+	}
+	switch options.BorderStyle {
+	case "", "S", "D", "B", "I", "U":
+	default:
+		f.errorf(method, "invalid link border style: %s", options.BorderStyle)
+		return
+	}
+	switch options.Highlight {
+	case "", "N", "I", "O", "P":
+	default:
+		f.errorf(method, "invalid link highlight mode: %s", options.Highlight)
+		return
+	}
+	options.BorderWidth *= f.k
+	f.newLink(x, y, w, h, link, linkStr)
+	pl := &f.pageLinks[f.page][len(f.pageLinks[f.page])-1]
+	pl.filePage = filePage
+	pl.options = &options
+}
+
+// putLinkOptions writes the appearance entries of a link annotation.
+func (f *Fpdf) putLinkOptions(annots *fmtBuffer, opt *LinkOptions) {
+	if opt.BorderWidth > 0 {
+		style := opt.BorderStyle
+		if style == "" {
+			style = "S"
+		}
+		annots.printf("/BS <</W %.2f /S /%s", opt.BorderWidth, style)
+		if style == "D" {
+			annots.printf(" /D [3 2]")
+		}
+		annots.printf(">> ")
+		annots.printf("/C [%.3f %.3f %.3f] ", float64(opt.BorderColor.R)/255,
+			float64(opt.BorderColor.G)/255, float64(opt.BorderColor.B)/255)
+	} else {
+		annots.printf("/Border [0 0 0] ")
+	}
+	if opt.Highlight != "" {
+		annots.printf("/H /%s ", opt.Highlight)
+	}
+	if opt.Tooltip != "" {
+		annots.printf("/Contents %s ", f.textstring(utf8toutf16(opt.Tooltip)))
+	}
+}
+
+// SetLinkXYZ defines the page and position a link points to, like SetLink(),
+// together with the zoom level. The viewer shows the point (x, y) of the page
+// at the upper left corner of the window. x and y are in the unit of measure
+// specified in New(); -1 indicates the current position, and a page of -1 the
+// current page. zoom is a percentage, 100 showing the page at its actual
+// size; 0 keeps the zoom level of the viewer.
+func (f *Fpdf) SetLinkXYZ(link, page int, x, y, zoom float64) {
+	if link < 1 || link >= len(f.links) {
+		f.errorf("SetLinkXYZ", "undefined link: %d", link)
+		return
+	}
+	if x == -1 {
+		x = f.x
+	}
+	if y == -1 {
+		y = f.y
+	}
+	if page == -1 {
+		page = f.page
+	}
+	f.links[link] = intLinkType{page: page, x: x, y: y, zoom: zoom, fit: "XYZ"}
+}
+
+// SetLinkFit defines the page a link points to and how the viewer fits it in
+// the window. fitStr is "Fit" to show the whole page, "FitH" to fit the width
+// of the page with the ordinate pos at the top of the window, or "FitV" to
+// fit the height of the page with the abscissa pos at the left of the
+// window. pos is in the unit of measure specified in New() and is ignored
+// for "Fit". A page of -1 indicates the current page.
+func (f *Fpdf) SetLinkFit(link, page int, fitStr string, pos float64) {
+	if link < 1 || link >= len(f.links) {
+		f.errorf("SetLinkFit", "undefined link: %d", link)
+		return
+	}
+	if page == -1 {
+		page = f.page
+	}
+	l := intLinkType{page: page, fit: fitStr}
+	switch fitStr {
+	case "Fit":
+	case "FitH":
+		l.y = pos
+	case "FitV":
+		l.x = pos
+	default:
+		f.errorf("SetLinkFit", "invalid destination type: %s", fitStr)
+		return
+	}
+	f.links[link] = l
+}
+
+// linkDest returns the explicit destination of an internal link.
+func (f *Fpdf) linkDest(l intLinkType) string {
+	xPt, yPt := f.pagePoint(l.page, l.x, l.y)
+	obj, x, y := f.destPage(l.page, xPt, yPt)
+	switch l.fit {
+	case "XYZ":
+		zoom := "null"
+		if l.zoom > 0 {
+			zoom = sprintf("%.2f", l.zoom/100)
+		}
+		return sprintf("[%d 0 R /XYZ %.2f %.2f %s]", obj, x, y, zoom)
+	case "Fit":
+		return sprintf("[%d 0 R /Fit]", obj)
+	case "FitH":
+		return sprintf("[%d 0 R /FitH %.2f]", obj, y)
+	case "FitV":
+		return sprintf("[%d 0 R /FitV %.2f]", obj, x)
+	}
+	return sprintf("[%d 0 R /XYZ 0 %.2f null]", obj, y)
+}
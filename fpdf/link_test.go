@@ -0,0 +1,93 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestLinkOptions(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	link := pdf.AddLink()
+	pdf.SetLink(link, 0, 1)
+	pdf.LinkOptions(10, 10, 50, 20, link, fpdf.LinkOptions{
+		BorderWidth: 1,
+		BorderStyle: "D",
+		BorderColor: fpdf.RGBType{R: 255},
+		Highlight:   "P",
+		Tooltip:     "Back to top",
+	})
+	pdf.LinkStringOptions(10, 40, 50, 20, "https://example.com", fpdf.LinkOptions{Highlight: "O"})
+	pdf.LinkFile(10, 70, 50, 20, "annex.pdf", 3, fpdf.LinkOptions{})
+	pdf.LinkString(10, 100, 50, 20, "https://example.org")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"/BS <</W 1.00 /S /D /D [3 2]>> /C [1.000 0.000 0.000] /H /P /Contents (\xfe\xff\x00B",
+		"/Border [0 0 0] /H /O /A <</S /URI /URI (https://example.com)>>",
+		"/Border [0 0 0] /A <</S /GoToR /F (annex.pdf) /D [2 /Fit]>>",
+		"/Border [0 0 0] /A <</S /URI /URI (https://example.org)>>",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.LinkStringOptions(10, 10, 50, 20, "https://example.com", fpdf.LinkOptions{Highlight: "X"})
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for invalid highlight mode")
+	}
+}
+
+func TestSetLinkXYZ(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	_, h := pdf.GetPageSize()
+	links := make([]int, 5)
+	for j := range links {
+		links[j] = pdf.AddLink()
+		pdf.Link(10, 10+30*float64(j), 50, 20, links[j])
+	}
+	pdf.SetLink(links[0], 100, 1)
+	pdf.SetLinkXYZ(links[1], 1, 20, 100, 150)
+	pdf.SetLinkFit(links[2], 1, "Fit", 0)
+	pdf.SetLinkFit(links[3], 1, "FitH", 100)
+	pdf.SetLinkXYZ(links[4], -1, 20, 100, 0)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	top := strconv.FormatFloat(h-100, 'f', 2, 64)
+	for _, s := range []string{
+		"/Dest [3 0 R /XYZ 0 " + top + " null]",
+		"/Dest [3 0 R /XYZ 20.00 " + top + " 1.50]",
+		"/Dest [3 0 R /Fit]",
+		"/Dest [3 0 R /FitH " + top + "]",
+		"/Dest [3 0 R /XYZ 20.00 " + top + " null]",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetLinkFit(pdf.AddLink(), 1, "FitB", 0)
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for unsupported destination type")
+	}
+}
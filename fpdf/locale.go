@@ -0,0 +1,231 @@
+package fpdf
+
+import (
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/time"
+)
+
+// Locale holds the conventions used to format numbers, amounts of money and
+// dates in the language of a document, see SetLocale().
+type Locale struct {
+	Tag      string // language tag, such as "de-CH"
+	Decimal  string // decimal separator
+	Group    string // separator of groups of three digits
+	Currency string // currency symbol, such as "€" or "CHF"
+	// CurrencyAfter places the currency symbol after the amount rather than
+	// before it.
+	CurrencyAfter bool
+	// CurrencySpace separates the currency symbol from the amount with a
+	// space.
+	CurrencySpace bool
+	// DateLayout is the layout of FormatDate(), written with 2006 (year), 01
+	// (month) and 02 (day).
+	DateLayout string
+}
+
+// locales lists the locales known to SetLocale(), by lowercase tag.
+var locales = map[string]Locale{
+	"en-us": {Tag: "en-US", Decimal: ".", Group: ",", Currency: "$", DateLayout: "01/02/2006"},
+	"en-gb": {Tag: "en-GB", Decimal: ".", Group: ",", Currency: "£", DateLayout: "02/01/2006"},
+	"de-de": {Tag: "de-DE", Decimal: ",", Group: ".", Currency: "€", CurrencyAfter: true, CurrencySpace: true, DateLayout: "02.01.2006"},
+	"de-at": {Tag: "de-AT", Decimal: ",", Group: ".", Currency: "€", CurrencySpace: true, DateLayout: "02.01.2006"},
+	"de-ch": {Tag: "de-CH", Decimal: ".", Group: "’", Currency: "CHF", CurrencySpace: true, DateLayout: "02.01.2006"},
+	"fr-fr": {Tag: "fr-FR", Decimal: ",", Group: " ", Currency: "€", CurrencyAfter: true, CurrencySpace: true, DateLayout: "02/01/2006"},
+	"fr-ch": {Tag: "fr-CH", Decimal: ",", Group: " ", Currency: "CHF", CurrencyAfter: true, CurrencySpace: true, DateLayout: "02.01.2006"},
+	"it-it": {Tag: "it-IT", Decimal: ",", Group: ".", Currency: "€", CurrencyAfter: true, CurrencySpace: true, DateLayout: "02/01/2006"},
+	"es-es": {Tag: "es-ES", Decimal: ",", Group: ".", Currency: "€", CurrencyAfter: true, CurrencySpace: true, DateLayout: "02/01/2006"},
+	"pt-br": {Tag: "pt-BR", Decimal: ",", Group: ".", Currency: "R$", CurrencySpace: true, DateLayout: "02/01/2006"},
+	"nl-nl": {Tag: "nl-NL", Decimal: ",", Group: ".", Currency: "€", CurrencySpace: true, DateLayout: "02-01-2006"},
+	"ja-jp": {Tag: "ja-JP", Decimal: ".", Group: ",", Currency: "¥", DateLayout: "2006/01/02"},
+}
+
+// SetLocale selects the conventions used to format numbers, amounts of money
+// and dates by FormatNumber(), FormatCurrency(), FormatDate() and Cellf(),
+// from a language tag such as "de-CH" or "fr-FR", ignoring case. The known
+// tags are en-US, en-GB, de-DE, de-AT, de-CH, fr-FR, fr-CH, it-IT, es-ES,
+// pt-BR, nl-NL and ja-JP; other locales can be set with SetLocaleFormat(). An
+// unknown tag sets the document error. An empty tag removes the locale.
+func (f *Fpdf) SetLocale(tag string) {
+	if tag == "" {
+		f.locale = nil
+		return
+	}
+	l, ok := locales[Convert(tag).ToLower().Replace("_", "-").String()]
+	if !ok {
+		f.errorf("SetLocale", "unknown locale: %s", tag)
+		return
+	}
+	f.locale = &l
+}
+
+// SetLocaleFormat sets the conventions of a locale that is not known to
+// SetLocale().
+func (f *Fpdf) SetLocaleFormat(l Locale) {
+	f.locale = &l
+}
+
+// GetLocale returns the locale set with SetLocale() or SetLocaleFormat(), and
+// whether one is set. Without a locale, numbers are formatted as in en-US.
+func (f *Fpdf) GetLocale() (l Locale, ok bool) {
+	if f.locale == nil {
+		return locales["en-us"], false
+	}
+	return *f.locale, true
+}
+
+// FormatNumber returns v with decimals digits after the decimal separator and
+// its integer part grouped by thousands, as in "1’234.50" in de-CH.
+func (f *Fpdf) FormatNumber(v float64, decimals int) string {
+	return f.localizeDigits(sprintf("%."+sprintf("%d", max(decimals, 0))+"f", v))
+}
+
+// FormatCurrency returns the amount v with two decimals and the currency
+// symbol of the locale, as in "CHF 1’234.50" in de-CH or "1.234,50 €" in
+// de-DE.
+func (f *Fpdf) FormatCurrency(v float64) string {
+	l, _ := f.GetLocale()
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	s := f.FormatNumber(v, 2)
+	space := ""
+	if l.CurrencySpace {
+		space = " "
+	}
+	if l.CurrencyAfter {
+		return sign + s + space + l.Currency
+	}
+	return sign + l.Currency + space + s
+}
+
+// FormatDate returns the date of nano, in nanoseconds since the Unix epoch in
+// UTC, written with the date layout of the locale, as in "14.11.2023" in
+// de-CH.
+func (f *Fpdf) FormatDate(nano int64) string {
+	l, _ := f.GetLocale()
+	iso := time.FormatISO8601(nano) // 2006-01-02T15:04:05Z
+	if len(iso) < 10 {
+		return ""
+	}
+	// The layout is scanned once so that digits of the date are not replaced.
+	var out string
+	for s := l.DateLayout; s != ""; {
+		switch {
+		case len(s) >= 4 && s[:4] == "2006":
+			out, s = out+iso[0:4], s[4:]
+		case len(s) >= 2 && s[:2] == "01":
+			out, s = out+iso[5:7], s[2:]
+		case len(s) >= 2 && s[:2] == "02":
+			out, s = out+iso[8:10], s[2:]
+		default:
+			out, s = out+s[:1], s[1:]
+		}
+	}
+	return out
+}
+
+// localizeDigits returns s, a number as formatted by the fmt package, with
+// the decimal and group separators of the locale.
+func (f *Fpdf) localizeDigits(s string) string {
+	l, _ := f.GetLocale()
+	sign := ""
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	intPart, frac := s, ""
+	for j := 0; j < len(s); j++ {
+		if s[j] == '.' {
+			intPart, frac = s[:j], l.Decimal+s[j+1:]
+			break
+		}
+	}
+	grouped := ""
+	for len(intPart) > 3 {
+		grouped = l.Group + intPart[len(intPart)-3:] + grouped
+		intPart = intPart[:len(intPart)-3]
+	}
+	return sign + intPart + grouped + frac
+}
+
+// localizeArgs returns fmtStr and args for Cellf() with the numbers printed
+// by the verbs %d, %f and %.Nf formatted with the conventions of the locale,
+// if one is set. Verbs with flags or a width are left unchanged.
+func (f *Fpdf) localizeArgs(fmtStr string, args []any) (string, []any) {
+	if f.locale == nil {
+		return fmtStr, args
+	}
+	var out []byte
+	localized := make([]any, len(args))
+	copy(localized, args)
+	argNum := 0
+	for j := 0; j < len(fmtStr); j++ {
+		if fmtStr[j] != '%' {
+			out = append(out, fmtStr[j])
+			continue
+		}
+		k := j + 1
+		for k < len(fmtStr) && Contains("+-# 0123456789.", fmtStr[k:k+1]) {
+			k++
+		}
+		if k == len(fmtStr) {
+			out = append(out, fmtStr[j:]...)
+			break
+		}
+		spec := fmtStr[j : k+1]
+		if fmtStr[k] == '%' {
+			out = append(out, spec...)
+			j = k
+			continue
+		}
+		if argNum < len(args) {
+			if s, ok := f.localizeArg(spec, args[argNum]); ok {
+				localized[argNum] = s
+				spec = "%s"
+			}
+		}
+		out = append(out, spec...)
+		argNum++
+		j = k
+	}
+	return string(out), localized
+}
+
+// localizeArg returns arg formatted with the verb spec and the conventions
+// of the locale, and whether spec is a verb that is localized.
+func (f *Fpdf) localizeArg(spec string, arg any) (string, bool) {
+	switch spec[len(spec)-1] {
+	case 'd':
+		if spec != "%d" {
+			return "", false
+		}
+		switch arg.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return f.localizeDigits(sprintf("%d", arg)), true
+		}
+	case 'f':
+		if spec != "%f" && (len(spec) < 4 || spec[1] != '.' || !isDigits(spec[2:len(spec)-1])) {
+			return "", false
+		}
+		if spec == "%f" {
+			spec = "%.6f"
+		}
+		switch v := arg.(type) {
+		case float64:
+			return f.localizeDigits(sprintf(spec, v)), true
+		case float32:
+			return f.localizeDigits(sprintf(spec, float64(v))), true
+		}
+	}
+	return "", false
+}
+
+// isDigits returns whether s is made of decimal digits only.
+func isDigits(s string) bool {
+	for j := 0; j < len(s); j++ {
+		if s[j] < '0' || s[j] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
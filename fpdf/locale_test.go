@@ -0,0 +1,59 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetLocale(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	if got := pdf.FormatNumber(1234567.891, 2); got != "1,234,567.89" {
+		t.Errorf("unexpected default number: %s", got)
+	}
+	const nano = 1700000000 * 1e9 // 2023-11-14T22:13:20Z
+	for _, c := range []struct {
+		tag, number, currency, date string
+	}{
+		{"de-CH", "1’234.50", "-CHF 1’234.50", "14.11.2023"},
+		{"de-DE", "1.234,50", "-1.234,50 €", "14.11.2023"},
+		{"fr-fr", "1 234,50", "-1 234,50 €", "14/11/2023"},
+		{"en_US", "1,234.50", "-$1,234.50", "11/14/2023"},
+	} {
+		pdf.SetLocale(c.tag)
+		if got := pdf.FormatNumber(1234.5, 2); got != c.number {
+			t.Errorf("%s: FormatNumber: got %q, want %q", c.tag, got, c.number)
+		}
+		if got := pdf.FormatCurrency(-1234.5); got != c.currency {
+			t.Errorf("%s: FormatCurrency: got %q, want %q", c.tag, got, c.currency)
+		}
+		if got := pdf.FormatDate(nano); got != c.date {
+			t.Errorf("%s: FormatDate: got %q, want %q", c.tag, got, c.date)
+		}
+	}
+	if got := pdf.FormatNumber(999, 0); got != "999" {
+		t.Errorf("unexpected small number: %s", got)
+	}
+	pdf.SetLocale("xx-YY")
+	if !pdf.Err() {
+		t.Errorf("expected error for an unknown locale")
+	}
+}
+
+func TestCellfLocale(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.SetLocale("de-DE")
+	pdf.Cellf(0, 20, "Total: %.2f for %d items, %s, 100%%, %5.1f", 12345.678, 1500, "1.5", 2.24)
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "(Total: 12.345,68 for 1.500 items, 1.5, 100%,   2.2"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output does not contain %q", want)
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -11,11 +13,15 @@ func TestMakefont(t *testing.T) {
 	var err error
 	const expect = "Font definition file successfully generated"
 	// Make sure makefont utility has been built before generating font definition file
-	err = exec.Command("go", "build").Run()
+	// The utility and the font files it generates are kept out of the source
+	// tree
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "makefont")
+	err = exec.Command("go", "build", "-o", bin).Run()
 	if err != nil {
 		t.Fatal(err)
 	}
-	out, err = exec.Command("./makefont", "--dst=../fonts", "--embed",
+	out, err = exec.Command(bin, "--dst="+dir, "--embed",
 		"--enc=../fonts/cp1252.map", "../fonts/calligra.ttf").CombinedOutput()
 	if err != nil {
 		t.Fatal(err)
@@ -23,4 +29,7 @@ func TestMakefont(t *testing.T) {
 	if !strings.Contains(string(out), expect) {
 		t.Fatalf("Unexpected output from makefont")
 	}
+	if _, err = os.Stat(filepath.Join(dir, "calligra.z")); err != nil {
+		t.Fatal(err)
+	}
 }
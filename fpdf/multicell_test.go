@@ -0,0 +1,140 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMultiCellContinuation(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	_, pageH := pdf.GetPageSize()
+	_, bMargin := pdf.GetAutoPageBreak()
+
+	var calls []bool
+	var closingY float64
+	pdf.SetMultiCellContinuation(true, func(closing bool) {
+		calls = append(calls, closing)
+		if closing {
+			closingY = pdf.GetY()
+			pdf.SetFont("Arial", "I", 8)
+			pdf.CellFormat(0, 4, "(continued)", "", 2, "R", false, 0, "")
+			pdf.SetFont("Arial", "", 12)
+		}
+	})
+	pdf.SetY(pageH - bMargin - 22)
+	pdf.MultiCell(0, 5, strings.Repeat("Line of text\n", 10), "1", "L", false)
+
+	if got := pdf.PageCount(); got != 2 {
+		t.Fatalf("expecting the box to span 2 pages, got %d", got)
+	}
+	if len(calls) != 2 || !calls[0] || calls[1] {
+		t.Errorf("unexpected marker calls: %v", calls)
+	}
+	if closingY > pageH-bMargin {
+		t.Errorf("box closed below the page break trigger: y=%v", closingY)
+	}
+	if err := pdf.Output(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCellMultiline(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 10)
+	pdf.AddPage()
+	pdf.SetXY(20, 30)
+	pdf.CellMultiline(50, 12, "First line\nSecond line", "1", 0, "C", false, 0, "")
+	if x, y := pdf.GetXY(); !floatEqual(x, 70) || !floatEqual(y, 30) {
+		t.Errorf("unexpected position after ln=0: (%v, %v)", x, y)
+	}
+	pdf.CellMultiline(50, 12, "A\nB", "1", 1, "T", false, 0, "")
+	left, _, _, _ := pdf.GetMargins()
+	if x, y := pdf.GetXY(); !floatEqual(x, left) || !floatEqual(y, 42) {
+		t.Errorf("unexpected position after ln=1: (%v, %v)", x, y)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"(First line)Tj", "(Second line)Tj"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("output does not contain %s", want)
+		}
+	}
+}
+
+func TestCellMultilineGrows(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 10)
+	pdf.AddPage()
+	pdf.SetXY(20, 30)
+	// Five lines of 1.2 times the font size do not fit in 10
+	lh := pdf.PointConvert(10) * 1.2
+	pdf.CellMultiline(50, 10, "A\nB\nC\nD\nE", "1", 2, "T", false, 0, "")
+	if _, y := pdf.GetXY(); !floatEqual(y, 30+5*lh) {
+		t.Errorf("cell not grown to its lines: y=%v, want %v", y, 30+5*lh)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	_, ph := pdf.GetPageSize()
+	k := pdf.GetConversionRatio()
+	var base []float64
+	for _, m := range regexp.MustCompile(`BT [\d.]+ ([\d.]+) Td \(\w\)Tj ET`).FindAllSubmatch(buf.Bytes(), -1) {
+		v, _ := strconv.ParseFloat(string(m[1]), 64)
+		base = append(base, ph-v/k)
+	}
+	if len(base) != 5 {
+		t.Fatalf("unexpected lines: %v", base)
+	}
+	for i := 1; i < len(base); i++ {
+		if d := base[i] - base[i-1]; math.Abs(d-lh) > 0.01 {
+			t.Errorf("lines %d and %d are %v apart, want %v", i-1, i, d, lh)
+		}
+	}
+}
+
+func TestWriteAlignedJustify(t *testing.T) {
+	const txt = "The quick brown fox jumps over the lazy dog. The quick brown fox jumps over the lazy dog again."
+	for _, utf8 := range []bool{false, true} {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(false)
+		if utf8 {
+			pdf.AddUTF8Font("dejavu", "", FontFile("DejaVuSansCondensed.ttf"))
+			pdf.SetFont("dejavu", "", 12)
+		} else {
+			pdf.SetFont("Arial", "", 12)
+		}
+		pdf.AddPage()
+		_, top := pdf.GetXY()
+		pdf.WriteAligned(80, 6, txt, "J")
+		if _, y := pdf.GetXY(); y <= top {
+			t.Errorf("utf8=%v: expecting several lines, y=%v", utf8, y)
+		}
+
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		// codepage fonts use the Tw operator, UTF-8 fonts TJ adjustments
+		spacing := regexp.MustCompile(`[1-9][0-9]*\.[0-9]+ Tw`)
+		if utf8 {
+			spacing = regexp.MustCompile(`\] TJ`)
+		}
+		if !spacing.Match(buf.Bytes()) {
+			t.Errorf("utf8=%v: output has no word spacing", utf8)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package fpdf
+
+import "bytes"
+
+// SetContentOptimization enables or disables the optimization of the content
+// of pages when the document is output. The optimization removes the
+// operators that have no effect: settings of colors, line width, dash
+// pattern, font and other parameters to the value already in effect, saves
+// and restores of the graphics state with nothing drawn between them, and
+// text moves immediately followed by another one, which are merged. The
+// appearance of the pages is unchanged. The settings that fpdf repeats for
+// every cell make the content of tables about a fifth smaller.
+//
+// Optimization is disabled by default.
+func (f *Fpdf) SetContentOptimization(optimize bool) {
+	f.optimize = optimize
+}
+
+// contentOp is an operator of a content stream with its operands.
+type contentOp struct {
+	start, end int    // offsets of the operands and the operator in the content
+	op         string // operator
+	args       []any  // operands, as read by pdfLexer
+	repl       []byte // replacement of the operands and operator, if not nil
+	drop       bool   // whether the operator is removed
+}
+
+// stateParams maps the operators that set a parameter of the graphics state
+// to the parameter they set. Setting a color space resets the color.
+var stateParams = map[string]string{
+	"g": "fill", "rg": "fill", "k": "fill", "cs": "fill", "sc": "fill", "scn": "fill",
+	"G": "stroke", "RG": "stroke", "K": "stroke", "CS": "stroke", "SC": "stroke", "SCN": "stroke",
+	"w": "w", "J": "J", "j": "j", "M": "M", "d": "d", "ri": "ri", "i": "i", "gs": "gs",
+	"Tc": "Tc", "Tw": "Tw", "Tz": "Tz", "TL": "TL", "Tf": "Tf", "Tr": "Tr", "Ts": "Ts",
+}
+
+// neutralOps lists the operators that set no parameter of stateParams.
+var neutralOps = map[string]bool{
+	"m": true, "l": true, "c": true, "v": true, "y": true, "h": true, "re": true,
+	"S": true, "s": true, "f": true, "F": true, "f*": true, "B": true, "B*": true,
+	"b": true, "b*": true, "n": true, "W": true, "W*": true, "sh": true, "cm": true,
+	"BT": true, "ET": true, "Td": true, "TD": true, "Tm": true, "T*": true,
+	"Tj": true, "TJ": true, "'": true, "Do": true, "BI": true,
+	"BMC": true, "BDC": true, "EMC": true, "MP": true, "DP": true,
+}
+
+// optimizePages optimizes the content of every page, see
+// SetContentOptimization().
+func (f *Fpdf) optimizePages() {
+	for n := 1; n <= f.page; n++ {
+		content := optimizeContent(f.pages[n].Bytes(), f.prec.Text)
+		if len(content) < f.pages[n].Len() {
+			f.pages[n] = bytes.NewBuffer(content)
+		}
+	}
+}
+
+// optimizeContent returns the content stream data without its redundant
+// operators. Merged text moves are written with prec decimal places. data is
+// returned unchanged if it cannot be read.
+func optimizeContent(data []byte, prec int) []byte {
+	ops, ok := contentOps(data)
+	if !ok {
+		return data
+	}
+	dropRedundantState(data, ops)
+	dropEmptySaves(ops)
+	mergeMoves(ops, prec)
+	out := make([]byte, 0, len(data))
+	end := 0
+	newline := false
+	for _, op := range ops {
+		// Each operator keeps the white space preceding it, with the line
+		// breaks of the operators dropped before it
+		space := data[end:op.start]
+		end = op.end
+		if op.drop {
+			newline = newline || bytes.IndexByte(space, '\n') >= 0
+			continue
+		}
+		if newline && bytes.IndexByte(space, '\n') < 0 {
+			space = []byte{'\n'}
+		}
+		newline = false
+		out = append(out, space...)
+		if op.repl != nil {
+			out = append(out, op.repl...)
+		} else {
+			out = append(out, data[op.start:op.end]...)
+		}
+	}
+	return append(out, data[end:]...)
+}
+
+// contentOps returns the operators of the content stream data, and whether
+// it could be read.
+func contentOps(data []byte) ([]contentOp, bool) {
+	lex := pdfLexer{data: data}
+	var ops []contentOp
+	var args []any
+	start := -1
+	for {
+		lex.skipSpace()
+		if lex.pos >= len(data) {
+			return ops, true
+		}
+		if start < 0 {
+			start = lex.pos
+		}
+		v, err := lex.object()
+		if err != nil {
+			return nil, false
+		}
+		op, ok := v.(pdfOp)
+		if !ok {
+			args = append(args, v)
+			continue
+		}
+		if op == "BI" {
+			lex.skipInlineImage()
+		}
+		ops = append(ops, contentOp{start: start, end: lex.pos, op: string(op), args: args})
+		args, start = nil, -1
+	}
+}
+
+// dropRedundantState drops the operators of ops that set a parameter of the
+// graphics state to the value it already has, following the saves and
+// restores of the state.
+func dropRedundantState(data []byte, ops []contentOp) {
+	state := make(map[string]string)
+	var stack []map[string]string
+	for j := range ops {
+		op := &ops[j]
+		switch op.op {
+		case "q":
+			saved := make(map[string]string, len(state))
+			for k, v := range state {
+				saved[k] = v
+			}
+			stack = append(stack, saved)
+			continue
+		case "Q":
+			if len(stack) == 0 {
+				clear(state)
+			} else {
+				state, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+			continue
+		case `"`:
+			// Shows text after setting the word and character spacing
+			delete(state, "Tw")
+			delete(state, "Tc")
+			continue
+		}
+		param, ok := stateParams[op.op]
+		if !ok {
+			if !neutralOps[op.op] {
+				// An unknown operator may set anything
+				clear(state)
+			}
+			continue
+		}
+		set := string(data[op.start:op.end])
+		if state[param] == set {
+			op.drop = true
+			continue
+		}
+		if param == "gs" {
+			// A graphics state dictionary may set any parameter but colors
+			for k := range state {
+				if k != "fill" && k != "stroke" {
+					delete(state, k)
+				}
+			}
+		}
+		state[param] = set
+	}
+}
+
+// dropEmptySaves drops the saves and restores of the graphics state of ops
+// between which nothing is drawn, with the parameters set between them.
+func dropEmptySaves(ops []contentOp) {
+	type save struct {
+		at      int  // index of the q operator
+		painted bool // whether anything is drawn before the restore
+	}
+	var stack []save
+	for j := range ops {
+		op := &ops[j]
+		if op.drop {
+			continue
+		}
+		switch {
+		case op.op == "q":
+			stack = append(stack, save{at: j})
+		case op.op == "Q":
+			if len(stack) == 0 {
+				continue
+			}
+			s := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !s.painted {
+				for k := s.at; k <= j; k++ {
+					ops[k].drop = true
+				}
+			} else if len(stack) > 0 {
+				stack[len(stack)-1].painted = true
+			}
+		case stateParams[op.op] != "" || op.op == "cm":
+		default:
+			if len(stack) > 0 {
+				stack[len(stack)-1].painted = true
+			}
+		}
+	}
+}
+
+// mergeMoves merges the text moves of ops that immediately follow one
+// another into one move, written with prec decimal places.
+func mergeMoves(ops []contentOp, prec int) {
+	prev := -1
+	for j := range ops {
+		op := &ops[j]
+		if op.drop {
+			continue
+		}
+		tx, ty, ok := moveArgs(op)
+		if !ok {
+			prev = -1
+			continue
+		}
+		if prev >= 0 {
+			px, py, _ := moveArgs(&ops[prev])
+			tx, ty = tx+px, ty+py
+			ops[prev].drop = true
+			op.args = []any{tx, ty}
+			op.repl = append(appendFloats(nil, prec, tx, ty), "Td"...)
+		}
+		prev = j
+	}
+}
+
+// moveArgs returns the operands of op if it is a Td operator.
+func moveArgs(op *contentOp) (tx, ty float64, ok bool) {
+	if op.op != "Td" || len(op.args) != 2 {
+		return 0, 0, false
+	}
+	tx, ok1 := op.args[0].(float64)
+	ty, ok2 := op.args[1].(float64)
+	return tx, ty, ok1 && ok2
+}
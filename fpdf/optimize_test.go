@@ -0,0 +1,76 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func optimizeTable(optimize bool) *fpdf.Fpdf {
+	pdf := NewDocPdfTest()
+	pdf.SetContentOptimization(optimize)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 10)
+	for r := 0; r < 20; r++ {
+		for c := 0; c < 4; c++ {
+			pdf.SetFillColor(230, 230, 230)
+			pdf.SetDrawColor(0, 0, 0)
+			pdf.SetLineWidth(0.2)
+			pdf.CellFormat(40, 6, strings.Repeat("x", r+c), "1", 0, "L", r%2 == 0, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	return pdf
+}
+
+func TestContentOptimization(t *testing.T) {
+	plain, optimized := optimizeTable(false), optimizeTable(true)
+	want, got := readBack(t, plain), readBack(t, optimized)
+	wantRuns, err := want.TextRuns(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotRuns, err := got.TextRuns(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotRuns) != len(wantRuns) {
+		t.Fatalf("got %d text runs, want %d", len(gotRuns), len(wantRuns))
+	}
+	for j := range wantRuns {
+		if gotRuns[j] != wantRuns[j] {
+			t.Errorf("text run %d: got %+v, want %+v", j, gotRuns[j], wantRuns[j])
+		}
+	}
+	before, after := len(plain.PageContentString(1)), len(optimized.PageContentString(1))
+	if after > before*4/5 {
+		t.Errorf("content of %d bytes optimized to %d bytes", before, after)
+	}
+}
+
+func TestContentOptimizationOperators(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetContentOptimization(true)
+	pdf.AddPage()
+	pdf.RawWriteStr("0.500 g 0.500 g 1 w q 1 w 2 w Q 1 w 0 0 10 10 re f")
+	pdf.RawWriteStr("q 1 0 0 rg q 2 w Q Q q 0 g 0 0 5 5 re f Q 0.500 g")
+	pdf.RawWriteStr("BT 10 20 Td 5 -5.5 Td 1 1 Td (a) Tj 1 1 Td ET")
+	pdf.RawWriteStr("/GS1 gs 1 w /GS1 gs 1 w xyz 0.500 g")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := pdf.PageContentString(1)
+	for _, s := range []string{
+		"0.500 g 1 w 0 0 10 10 re f\n",
+		"\nq 0 g 0 0 5 5 re f Q\n",
+		"BT 16.00 15.50 Td (a) Tj 1 1 Td ET\n",
+		"/GS1 gs 1 w xyz 0.500 g\n",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("content does not contain %q:\n%s", s, got)
+		}
+	}
+}
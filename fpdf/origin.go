@@ -0,0 +1,68 @@
+package fpdf
+
+// SetOriginBox makes the crop box or the trim box of the pages added from now
+// on their drawing area. t is "crop" or "trim" (or "cropbox", "trimbox", case
+// insensitive); an empty string restores the default, the media box.
+//
+// On such a page, the coordinates of all drawing methods are relative to the
+// upper left corner of the box, and GetPageSize() as well as the margins and
+// automatic page breaks refer to the size of the box rather than to the
+// paper. This simplifies the layout of documents printed with bleed: set the
+// trim box with SetPageBox() before adding the page, then lay out the content
+// as if the page had the trimmed size. Content may still extend past the box
+// into the bleed area, using negative coordinates for the left and top edges.
+//
+// The box is read when each page is added, falling back to the crop box and
+// then to the media box if it has not been set.
+func (f *Fpdf) SetOriginBox(t string) {
+	if t == "" {
+		f.originBox = ""
+		return
+	}
+	name := pageBoxName(t)
+	if name != "CropBox" && name != "TrimBox" {
+		f.errorf("SetOriginBox", "%s is not a valid origin box type", t)
+		return
+	}
+	f.originBox = name
+}
+
+// applyOriginBox moves the origin of the page just begun to the corner of the
+// box selected by SetOriginBox() and sets the page size to that of the box.
+func (f *Fpdf) applyOriginBox() {
+	if f.originBox == "" {
+		return
+	}
+	f.setPageOrigin(f.effectivePageBox(f.page, f.originBox))
+}
+
+// setPageOrigin moves the origin of the current page to the upper left
+// corner of box, given in points as stored by SetPageBoxRec().
+func (f *Fpdf) setPageOrigin(box PageBox) {
+	f.wPt = box.Wd - box.X
+	f.hPt = box.Ht - box.Y
+	f.w = f.wPt / f.k
+	f.h = f.hPt / f.k
+	f.pageBreakTrigger = f.h - f.bMargin
+	if f.pageOrigins == nil {
+		f.pageOrigins = make(map[int]PageBox)
+	}
+	f.pageOrigins[f.page] = box
+	f.put("1 0 0 1 ")
+	f.putF64s(f.prec.Matrix, box.X, box.Y)
+	f.put("cm\n")
+}
+
+// pagePoint converts the point (x, y) of page n, in the unit of measure
+// specified in New() from the upper left corner of the page or of its origin
+// box, to points from the lower left corner of the paper.
+func (f *Fpdf) pagePoint(n int, x, y float64) (xPt, yPt float64) {
+	if o, ok := f.pageOrigins[n]; ok {
+		return o.X + x*f.k, o.Ht - y*f.k
+	}
+	if n == f.page {
+		return x * f.k, f.hPt - y*f.k
+	}
+	_, h := f.pageSizePt(n)
+	return x * f.k, h - y*f.k
+}
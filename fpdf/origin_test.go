@@ -0,0 +1,54 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetOriginBox(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetPageBox("trim", 20, 30, 500, 700)
+	pdf.SetOriginBox("trim")
+	pdf.AddPage()
+
+	if w, h := pdf.GetPageSize(); w != 500 || h != 700 {
+		t.Errorf("invalid page size: got=%v x %v, want=500 x 700", w, h)
+	}
+	link := pdf.AddLink()
+	pdf.SetLink(link, 100, 1)
+	pdf.Link(10, 10, 50, 20, link)
+
+	pdf.SetOriginBox("")
+	pdf.AddPage()
+	if w, h := pdf.GetPageSize(); w != 595.28 || h != 841.89 {
+		t.Errorf("invalid page size after reset: got=%v x %v, want=595.28 x 841.89", w, h)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"1 0 0 1 20.00000 30.00000 cm",
+		"/Rect [30.00 720.00 80.00 700.00]",
+		"/Dest [3 0 R /XYZ 0 630.00 null]",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+	if strings.Count(out, " cm\n") != 1 {
+		t.Errorf("origin applied to the second page")
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.SetOriginBox("bleed")
+	if !pdf.Err() {
+		t.Errorf("expected error for an invalid origin box type")
+	}
+}
@@ -0,0 +1,245 @@
+package fpdf
+
+import "math"
+
+type pathSegType struct {
+	op  byte         // 'm': move, 'l': line, 'c': cubic Bézier curve, 'h': close
+	pts [3]PointType // end point of moves and lines; control points and end point of curves
+}
+
+// Path is a figure built from lines and curves independently of any page,
+// so that it can be drawn, filled or used as a clipping area any number of
+// times, and measured. Its methods that add segments return the path so that
+// they can be chained:
+//
+//	p := fpdf.NewPath().MoveTo(10, 10).LineTo(50, 10).CurveTo(60, 30, 50, 50).Close()
+//	p.Draw(pdf, "DF")
+//
+// Coordinates use the units established in New() of the document the path is
+// drawn on.
+type Path struct {
+	segs  []pathSegType
+	start PointType // start of the current subpath
+	cur   PointType // current point
+}
+
+// NewPath returns an empty path.
+func NewPath() *Path {
+	return &Path{}
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (p *Path) MoveTo(x, y float64) *Path {
+	p.start = PointType{X: x, Y: y}
+	p.cur = p.start
+	p.segs = append(p.segs, pathSegType{op: 'm', pts: [3]PointType{p.cur}})
+	return p
+}
+
+// LineTo adds a line from the current point to (x, y).
+func (p *Path) LineTo(x, y float64) *Path {
+	p.cur = PointType{X: x, Y: y}
+	p.segs = append(p.segs, pathSegType{op: 'l', pts: [3]PointType{p.cur}})
+	return p
+}
+
+// CurveTo adds a quadratic Bézier curve from the current point to (x, y)
+// with the control point (cx, cy), like Fpdf.CurveTo().
+func (p *Path) CurveTo(cx, cy, x, y float64) *Path {
+	// The cubic curve with the same shape has its control points two thirds
+	// of the way from the ends to the quadratic control point.
+	return p.CurveBezierCubicTo(p.cur.X+(cx-p.cur.X)*2/3, p.cur.Y+(cy-p.cur.Y)*2/3,
+		x+(cx-x)*2/3, y+(cy-y)*2/3, x, y)
+}
+
+// CurveBezierCubicTo adds a cubic Bézier curve from the current point to
+// (x, y) with the control points (cx0, cy0) and (cx1, cy1), like
+// Fpdf.CurveBezierCubicTo().
+func (p *Path) CurveBezierCubicTo(cx0, cy0, cx1, cy1, x, y float64) *Path {
+	p.segs = append(p.segs, pathSegType{op: 'c', pts: [3]PointType{{X: cx0, Y: cy0}, {X: cx1, Y: cy1}, {X: x, Y: y}}})
+	p.cur = PointType{X: x, Y: y}
+	return p
+}
+
+// EllipticalArcTo adds an elliptical arc from the current point to (x, y),
+// like Fpdf.EllipticalArcTo().
+func (p *Path) EllipticalArcTo(rx, ry, degRotate float64, largeArc, sweep bool, x, y float64) *Path {
+	if rx == 0 || ry == 0 {
+		return p.LineTo(x, y)
+	}
+	for _, c := range ellipticalArcCurves(p.cur.X, p.cur.Y, rx, ry, degRotate, largeArc, sweep, x, y) {
+		p.CurveBezierCubicTo(c[0], c[1], c[2], c[3], c[4], c[5])
+	}
+	return p
+}
+
+// Close adds a line from the current point to the start of the current
+// subpath and joins them.
+func (p *Path) Close() *Path {
+	p.segs = append(p.segs, pathSegType{op: 'h'})
+	p.cur = p.start
+	return p
+}
+
+// Draw draws the path on the current page of pdf with the current draw color
+// and line width, fills it with the current fill color, or both. styleStr is
+// used as in Fpdf.DrawPath().
+func (p *Path) Draw(pdf *Fpdf, styleStr string) {
+	if len(p.segs) > 0 {
+		x, y, w, h := p.BoundingBox()
+		pdf.extend(x, y, x+w, y+h)
+	}
+	p.put(pdf, "", fillDrawOp(styleStr))
+}
+
+// Stroke draws the outline of the path, like Draw() with styleStr "D".
+func (p *Path) Stroke(pdf *Fpdf) {
+	p.Draw(pdf, "D")
+}
+
+// Fill fills the path, like Draw() with styleStr "F".
+func (p *Path) Fill(pdf *Fpdf) {
+	p.Draw(pdf, "F")
+}
+
+// Clip begins a clipping operation within the path, like Fpdf.ClipPolygon().
+// outline is true to draw its border. Call Fpdf.ClipEnd() to restore
+// unclipped operations.
+func (p *Path) Clip(pdf *Fpdf, outline bool) {
+	pdf.clipNest++
+	p.put(pdf, "q ", "W "+strIf(outline, "S", "n"))
+}
+
+// put writes the segments of the path between prefix and the painting
+// operator op.
+func (p *Path) put(f *Fpdf, prefix, op string) {
+	if len(p.segs) == 0 {
+		return
+	}
+	var s fmtBuffer
+	h, k := f.h, f.k
+	s.printf("%s", prefix)
+	for _, seg := range p.segs {
+		switch seg.op {
+		case 'm', 'l':
+			s.Write(appendFloats(nil, f.prec.Curve, seg.pts[0].X*k, (h-seg.pts[0].Y)*k))
+			s.printf("%s\n", string(seg.op))
+		case 'c':
+			for _, pt := range seg.pts {
+				s.Write(appendFloats(nil, f.prec.Curve, pt.X*k, (h-pt.Y)*k))
+			}
+			s.printf("c\n")
+		case 'h':
+			s.printf("h\n")
+		}
+	}
+	s.printf("%s", op)
+	f.out(s.String())
+}
+
+// BoundingBox returns the smallest rectangle, given by its upper left corner
+// and its size, that contains the path. Control points of curves that lie
+// outside of the curves are not included.
+func (p *Path) BoundingBox() (x, y, w, h float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	add := func(pt PointType) {
+		minX, maxX = math.Min(minX, pt.X), math.Max(maxX, pt.X)
+		minY, maxY = math.Min(minY, pt.Y), math.Max(maxY, pt.Y)
+	}
+	p.walk(func(p0 PointType, seg pathSegType) {
+		switch seg.op {
+		case 'm', 'l':
+			add(seg.pts[0])
+		case 'c':
+			add(seg.pts[2])
+			// Extremes of the curve are where a coordinate of its derivative
+			// is zero.
+			for _, t := range append(cubicExtremes(p0.X, seg.pts[0].X, seg.pts[1].X, seg.pts[2].X),
+				cubicExtremes(p0.Y, seg.pts[0].Y, seg.pts[1].Y, seg.pts[2].Y)...) {
+				add(cubicPoint(p0, seg.pts, t))
+			}
+		}
+	})
+	if math.IsInf(minX, 1) {
+		return
+	}
+	return minX, minY, maxX - minX, maxY - minY
+}
+
+// Length returns the length of the outline of the path. Curves are measured
+// along a polyline that follows them closely.
+func (p *Path) Length() (length float64) {
+	const steps = 64
+	p.walk(func(p0 PointType, seg pathSegType) {
+		switch seg.op {
+		case 'l', 'h':
+			length += math.Hypot(seg.pts[0].X-p0.X, seg.pts[0].Y-p0.Y)
+		case 'c':
+			prev := p0
+			for j := 1; j <= steps; j++ {
+				pt := cubicPoint(p0, seg.pts, float64(j)/steps)
+				length += math.Hypot(pt.X-prev.X, pt.Y-prev.Y)
+				prev = pt
+			}
+		}
+	})
+	return
+}
+
+// walk calls fnc for each segment of the path with the point where the
+// segment starts. The end point of the segments that close a subpath is the
+// start of the subpath.
+func (p *Path) walk(fnc func(p0 PointType, seg pathSegType)) {
+	var cur, start PointType
+	for _, seg := range p.segs {
+		switch seg.op {
+		case 'm':
+			start = seg.pts[0]
+		case 'h':
+			seg.pts[0] = start
+		}
+		fnc(cur, seg)
+		if seg.op == 'c' {
+			cur = seg.pts[2]
+		} else {
+			cur = seg.pts[0]
+		}
+	}
+}
+
+// cubicPoint returns the point at parameter t of the cubic Bézier curve from
+// p0 with control points pts[0] and pts[1] to pts[2].
+func cubicPoint(p0 PointType, pts [3]PointType, t float64) PointType {
+	u := 1 - t
+	a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+	return PointType{
+		X: a*p0.X + b*pts[0].X + c*pts[1].X + d*pts[2].X,
+		Y: a*p0.Y + b*pts[0].Y + c*pts[1].Y + d*pts[2].Y,
+	}
+}
+
+// cubicExtremes returns the parameters between 0 and 1 at which the
+// one-dimensional cubic Bézier curve with coefficients v0 to v3 has a zero
+// derivative.
+func cubicExtremes(v0, v1, v2, v3 float64) (ts []float64) {
+	// The derivative is a*t² + b*t + c.
+	a := 3 * (-v0 + 3*v1 - 3*v2 + v3)
+	b := 6 * (v0 - 2*v1 + v2)
+	c := 3 * (v1 - v0)
+	var roots []float64
+	if math.Abs(a) < 1e-12 {
+		if b != 0 {
+			roots = append(roots, -c/b)
+		}
+	} else if d := b*b - 4*a*c; d >= 0 {
+		sq := math.Sqrt(d)
+		roots = append(roots, (-b+sq)/(2*a), (-b-sq)/(2*a))
+	}
+	for _, t := range roots {
+		if t > 0 && t < 1 {
+			ts = append(ts, t)
+		}
+	}
+	return
+}
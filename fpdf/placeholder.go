@@ -0,0 +1,181 @@
+package fpdf
+
+import (
+	"math"
+	"sort"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// placeholderType describes a box reserved by AddPlaceholder, AddTextField
+// or AddImageSlot
+type placeholderType struct {
+	page         int
+	x, y, wd, ht float64
+	kind         placeholderKind
+	align        string // alignment of the text of a text field
+}
+
+// placeholderKind tells how a placeholder is filled
+type placeholderKind int
+
+const (
+	placeholderBox   placeholderKind = iota // filled by FillPlaceholder
+	placeholderText                         // text field filled by FillFields
+	placeholderImage                        // image slot filled by FillFields
+)
+
+// AddPlaceholder reserves a box of width w and height h at the current
+// position and advances the current abscissa by w, as an empty Cell() would.
+// The content of the box is drawn later with FillPlaceholder(), typically
+// once values such as a chapter page count or an invoice total are known.
+//
+// Unlike RegisterAlias(), which substitutes text when the document is
+// written, a placeholder can receive any content: text in any font, lines,
+// images and so on.
+func (f *Fpdf) AddPlaceholder(name string, w, h float64) {
+	f.addPlaceholder("AddPlaceholder", name, w, h, placeholderType{kind: placeholderBox})
+}
+
+// addPlaceholder reserves the box of p under name for method.
+func (f *Fpdf) addPlaceholder(method, name string, w, h float64, p placeholderType) {
+	if f.err != nil {
+		return
+	}
+	if f.page == 0 {
+		f.errorf(method, "placeholder %s requires a page; call AddPage first", name)
+		return
+	}
+	if f.inTemplate {
+		f.errorf(method, "placeholder %s cannot be added to a template", name)
+		return
+	}
+	if w <= 0 || h <= 0 {
+		f.errorf(method, "invalid placeholder size: %.2f x %.2f", w, h)
+		return
+	}
+	if _, ok := f.placeholders[name]; ok {
+		f.errorf(method, "placeholder %s is already defined", name)
+		return
+	}
+	if f.placeholders == nil {
+		f.placeholders = make(map[string]placeholderType)
+	}
+	p.page, p.x, p.y, p.wd, p.ht = f.page, f.x, f.y, w, h
+	f.placeholders[name] = p
+	f.x += w
+}
+
+// FillPlaceholder calls fn to draw the content of the box reserved by
+// AddPlaceholder() under name. The box may lie on any page already added;
+// its content is appended to that page. fn receives the position and size
+// of the box and is called with the current position set to its upper left
+// corner. Drawing is clipped to the box and automatic page breaks are
+// suspended. The current page, position and graphics state are restored
+// afterwards.
+//
+//	pdf.AddPlaceholder("total", 30, 6)
+//	// ... add more rows and pages ...
+//	pdf.FillPlaceholder("total", func(x, y, w, h float64) {
+//		pdf.CellFormat(w, h, total, "", 0, "R", false, 0, "")
+//	})
+func (f *Fpdf) FillPlaceholder(name string, fn func(x, y, w, h float64)) {
+	if f.err != nil {
+		return
+	}
+	p, ok := f.placeholders[name]
+	if !ok {
+		f.errorf("FillPlaceholder", "undefined placeholder: %s", name)
+		return
+	}
+	x, y := f.x, f.y
+	f.onPage(p.page, func() {
+		f.ClipRect(p.x, p.y, p.wd, p.ht, false)
+		f.x, f.y = p.x, p.y
+		fn(p.x, p.y, p.wd, p.ht)
+		f.ClipEnd()
+		// ClipEnd restores the graphics state saved by ClipRect, so write
+		// back any change fn made
+		f.outState()
+	})
+	f.x, f.y = x, y
+}
+
+// AddTextField reserves a box of width w and height h at the current
+// position for the text bound to name by FillFields(), and advances the
+// current abscissa by w. The text is printed in a single line with the font
+// and color current when FillFields() is called, aligned in the box by
+// alignStr as CellFormat() does, and clipped to it.
+func (f *Fpdf) AddTextField(name string, w, h float64, alignStr string) {
+	f.addPlaceholder("AddTextField", name, w, h, placeholderType{kind: placeholderText, align: alignStr})
+}
+
+// AddImageSlot reserves a box of width w and height h at the current position
+// for the image bound to name by FillFields(), and advances the current
+// abscissa by w. The image is scaled to fit in the box, keeping its aspect
+// ratio, and centered in it.
+func (f *Fpdf) AddImageSlot(name string, w, h float64) {
+	f.addPlaceholder("AddImageSlot", name, w, h, placeholderType{kind: placeholderImage})
+}
+
+// FillFields fills the text fields and image slots added with
+// AddTextField() and AddImageSlot() with the values of data, for mail-merge
+// style generation. The value of a text field is printed as with Sprint;
+// the value of an image slot is the name of an image, as passed to
+// ImageOptions(). Fields without a value are left empty and values without a
+// field are ignored, so that records can hold more data than a letter
+// shows.
+//
+// The fields are released once filled, so that the same fields, typically
+// added by a function drawing a letter, can be added again on the next page
+// for the next record:
+//
+//	for _, rec := range records {
+//		pdf.AddPage()
+//		drawLetter(pdf) // adds the "name", "address" and "logo" fields
+//		pdf.FillFields(rec)
+//	}
+//
+// Fields belong to a page and cannot be added to a Template created by
+// CreateTemplate().
+func (f *Fpdf) FillFields(data map[string]any) {
+	if f.err != nil {
+		return
+	}
+	var names []string
+	for name, p := range f.placeholders {
+		if p.kind != placeholderBox {
+			names = append(names, name)
+		}
+	}
+	// Sorted for the content of the pages to be reproducible
+	sort.Strings(names)
+	for _, name := range names {
+		p := f.placeholders[name]
+		if v, ok := data[name]; ok {
+			switch p.kind {
+			case placeholderText:
+				f.FillPlaceholder(name, func(x, y, w, h float64) {
+					f.CellFormat(w, h, Sprint(v), "", 0, p.align, false, 0, "")
+				})
+			case placeholderImage:
+				f.fillImageSlot(name, Sprint(v))
+			}
+		}
+		delete(f.placeholders, name)
+	}
+}
+
+// fillImageSlot places the image imgName in the image slot name.
+func (f *Fpdf) fillImageSlot(name, imgName string) {
+	options := ImageOptions{ReadDpi: true}
+	info := f.RegisterImageOptions(imgName, options)
+	if f.err != nil {
+		return
+	}
+	f.FillPlaceholder(name, func(x, y, w, h float64) {
+		scale := math.Min(w/info.Width(), h/info.Height())
+		imgW, imgH := info.Width()*scale, info.Height()*scale
+		f.ImageOptions(imgName, x+(w-imgW)/2, y+(h-imgH)/2, imgW, imgH, false, options, 0, "")
+	})
+}
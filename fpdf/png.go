@@ -15,7 +15,7 @@ func (f *Fpdf) pngColorSpace(ct byte) (colspace string, colorVal int) {
 	case 3:
 		colspace = "Indexed"
 	default:
-		f.err = Errf("unknown color type in PNG buffer: %d", ct)
+		f.errorf("RegisterImageOptionsReader", "unknown color type in PNG buffer: %d", ct)
 	}
 	return
 }
@@ -24,13 +24,13 @@ func (f *Fpdf) parsepngstream(r *rbuffer, readdpi bool) (info *ImageInfoType) {
 	info = f.newImageInfo()
 	// 	Check signature
 	if string(r.Next(8)) != "\x89PNG\x0d\x0a\x1a\x0a" {
-		f.err = Errf("not a PNG buffer")
+		f.errorf("RegisterImageOptionsReader", "not a PNG buffer")
 		return
 	}
 	// Read header chunk
 	_ = r.Next(4)
 	if string(r.Next(4)) != "IHDR" {
-		f.err = Errf("incorrect PNG buffer")
+		f.errorf("RegisterImageOptionsReader", "incorrect PNG buffer")
 		return
 	}
 	w := r.i32()
@@ -49,15 +49,15 @@ func (f *Fpdf) parsepngstream(r *rbuffer, readdpi bool) (info *ImageInfoType) {
 		return
 	}
 	if r.u8() != 0 {
-		f.err = Errf("'unknown compression method in PNG buffer")
+		f.errorf("RegisterImageOptionsReader", "unknown compression method in PNG buffer")
 		return
 	}
 	if r.u8() != 0 {
-		f.err = Errf("'unknown filter method in PNG buffer")
+		f.errorf("RegisterImageOptionsReader", "unknown filter method in PNG buffer")
 		return
 	}
 	if r.u8() != 0 {
-		f.err = Errf("interlacing not supported in PNG buffer")
+		f.errorf("RegisterImageOptionsReader", "interlacing not supported in PNG buffer")
 		return
 	}
 	_ = r.Next(4)
@@ -134,7 +134,7 @@ func (f *Fpdf) parsepngstream(r *rbuffer, readdpi bool) (info *ImageInfoType) {
 		}
 	}
 	if colspace == "Indexed" && len(pal) == 0 {
-		f.err = Errf("missing palette in PNG buffer")
+		f.errorf("RegisterImageOptionsReader", "missing palette in PNG buffer")
 	}
 	info.w = float64(w)
 	info.h = float64(h)
@@ -149,7 +149,7 @@ func (f *Fpdf) parsepngstream(r *rbuffer, readdpi bool) (info *ImageInfoType) {
 		// Separate alpha and color channels
 		mem, err := xmem.uncompress(data)
 		if err != nil {
-			f.err = err
+			f.setError("RegisterImageOptionsReader", err)
 			return
 		}
 		data = mem.bytes()
@@ -202,7 +202,7 @@ func (f *Fpdf) parsepngstream(r *rbuffer, readdpi bool) (info *ImageInfoType) {
 			}
 		}
 
-		xc := xmem.compress(color.bytes())
+		xc := f.compressStream(color.bytes())
 		data = xc.copy()
 		xc.release()
 
@@ -210,7 +210,7 @@ func (f *Fpdf) parsepngstream(r *rbuffer, readdpi bool) (info *ImageInfoType) {
 		// has been compressed.
 		mem.release()
 
-		xa := xmem.compress(alpha.bytes())
+		xa := f.compressStream(alpha.bytes())
 		info.smask = xa.copy()
 		xa.release()
 
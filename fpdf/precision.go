@@ -0,0 +1,43 @@
+package fpdf
+
+// Precision holds the number of decimal places of the numbers written to the
+// content of pages, by class of operation. Positions are written in points,
+// so 2 places are a precision of about 0.0035 mm. More places draw technical
+// drawings more faithfully, fewer make smaller files. See SetPrecision().
+type Precision struct {
+	Text   int // position of printed text
+	Path   int // end points of lines and corners of rectangles and cells
+	Curve  int // points of curves, polygons, rounded shapes and paths
+	Matrix int // coefficients of transformation matrices, images included
+}
+
+// DefaultPrecision is the precision of a new document. The points of curves
+// and the coefficients of matrices have more places because their errors are
+// magnified by the curve or by the scale of the transformation.
+var DefaultPrecision = Precision{Text: 2, Path: 2, Curve: 5, Matrix: 5}
+
+// maxPrecision is the most decimal places SetPrecision() accepts, beyond the
+// precision of the viewers.
+const maxPrecision = 10
+
+// SetPrecision sets the number of decimal places of the numbers written to
+// the content of pages from now on. A precision outside 0 to 10 is an error.
+// For example, a drawing that needs 6 places while its text keeps 2:
+//
+//	p := pdf.GetPrecision()
+//	p.Path, p.Curve = 6, 6
+//	pdf.SetPrecision(p)
+func (f *Fpdf) SetPrecision(p Precision) {
+	for _, v := range []int{p.Text, p.Path, p.Curve, p.Matrix} {
+		if v < 0 || v > maxPrecision {
+			f.errorf("SetPrecision", "invalid precision %d, must be 0 to %d", v, maxPrecision)
+			return
+		}
+	}
+	f.prec = p
+}
+
+// GetPrecision returns the precision set with SetPrecision().
+func (f *Fpdf) GetPrecision() Precision {
+	return f.prec
+}
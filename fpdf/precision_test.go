@@ -0,0 +1,52 @@
+package fpdf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetPrecision(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT, fpdf.PageSize{Wd: 100, Ht: 100})
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	if got := pdf.GetPrecision(); got != fpdf.DefaultPrecision {
+		t.Errorf("got precision %+v, want %+v", got, fpdf.DefaultPrecision)
+	}
+	draw := func() {
+		pdf.Line(1.1234567, 2, 3, 4)
+		pdf.Curve(0, 0, 1.1234567, 1, 2, 2, "D")
+		pdf.Text(1.1234567, 10, "Hi")
+		pdf.TransformBegin()
+		pdf.TransformScale(133.3333333, 100, 0, 0)
+		pdf.TransformEnd()
+		pdf.SetXY(1.1234567, 30)
+		pdf.CellFormat(10, 5, "", "L", 0, "", false, 0, "")
+		pdf.ClipText(1.1234567, 20, "Hi", false)
+		pdf.ClipEnd()
+	}
+	draw()
+	before := pdf.PageContentString(1)
+	for _, want := range []string{"1.12 98.00 m", "1.12346 99.00", "BT 1.12 90.00 Td", "1.33333 0.00000",
+		"1.12 70.00 m 1.12 65.00 l S", "q BT 1.12 80.00 Td"} {
+		if !strings.Contains(before, want) {
+			t.Errorf("default content lacks %q: %q", want, before)
+		}
+	}
+
+	pdf.SetPrecision(fpdf.Precision{Text: 0, Path: 6, Curve: 6, Matrix: 3})
+	draw()
+	after := strings.TrimPrefix(pdf.PageContentString(1), before)
+	for _, want := range []string{"1.123457 98.000000 m", "1.123457 99.000000", "BT 1 90 Td", "1.333 0.000",
+		"1.123457 70.000000 m 1.123457 65.000000 l S", "q BT 1 80 Td"} {
+		if !strings.Contains(after, want) {
+			t.Errorf("content lacks %q: %q", want, after)
+		}
+	}
+
+	pdf.SetPrecision(fpdf.Precision{Text: 11})
+	if pdf.Error() == nil {
+		t.Error("no error for an invalid precision")
+	}
+}
@@ -6,10 +6,23 @@
 package fpdf
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/md5"
+	crand "crypto/rand"
 	"crypto/rc4"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
 	"math/rand"
+
+	. "github.com/tinywasm/fmt"
 )
 
 // Advisory bitflag constants that control document activities
@@ -29,7 +42,12 @@ type protectType struct {
 	encryptionKey []byte
 	objNum        int
 	rc4cipher     *rc4.Cipher
-	rc4n          uint32 // Object number associated with rc4 cipher
+	rc4n          uint32   // Object number associated with rc4 cipher
+	recipients    [][]byte // PKCS#7 envelopes of the key, public-key security only
+	plainMetadata bool     // leave the XMP metadata unencrypted
+	fileID        []byte   // first element of the file identifier, see SetFileID()
+	streamBuf     []byte   // space in which the streams are encrypted, one at a time
+	setup         func() error
 }
 
 func (p *protectType) rc4(n uint32, buf *[]byte) {
@@ -40,6 +58,43 @@ func (p *protectType) rc4(n uint32, buf *[]byte) {
 	p.rc4cipher.XORKeyStream(*buf, *buf)
 }
 
+// streamChunk is the size of the pieces in which the streams are encrypted.
+const streamChunk = 32 << 10
+
+// encryptWriter encrypts what is written to it with a stream cipher and
+// writes the result to w, one piece at a time, so that encrypting a stream
+// needs no more memory than a piece and leaves the data written unchanged.
+type encryptWriter struct {
+	s   cipher.Stream
+	w   io.Writer
+	buf []byte
+}
+
+func (e *encryptWriter) Write(b []byte) (n int, err error) {
+	for len(b) > 0 {
+		k := min(len(b), len(e.buf))
+		e.s.XORKeyStream(e.buf[:k], b[:k])
+		if _, err = e.w.Write(e.buf[:k]); err != nil {
+			return
+		}
+		n += k
+		b = b[k:]
+	}
+	return
+}
+
+// streamWriter returns a writer that encrypts the data of a stream of object
+// n and writes it to w. All the security handlers written by fpdf encrypt
+// with RC4. The streams are written one after the other, so that the writers
+// share the space of the document in which they encrypt.
+func (p *protectType) streamWriter(n uint32, w io.Writer) io.Writer {
+	c, _ := rc4.NewCipher(p.objectKey(n))
+	if p.streamBuf == nil {
+		p.streamBuf = make([]byte, streamChunk)
+	}
+	return &encryptWriter{s: c, w: w, buf: p.streamBuf}
+}
+
 func (p *protectType) objectKey(n uint32) []byte {
 	var nbuf, b []byte
 	nbuf = make([]byte, 8)
@@ -47,7 +102,7 @@ func (p *protectType) objectKey(n uint32) []byte {
 	b = append(b, p.encryptionKey...)
 	b = append(b, nbuf[0], nbuf[1], nbuf[2], 0, 0)
 	s := md5.Sum(b)
-	return s[0:10]
+	return s[0:min(len(p.encryptionKey)+5, 16)]
 }
 
 func oValueGen(userPass, ownerPass []byte) (v []byte) {
@@ -69,7 +124,42 @@ func (p *protectType) uValueGen() (v []byte) {
 	return
 }
 
+// setEncryptMetadata sets whether the XMP metadata is encrypted along with
+// the rest of the document, and derives the encryption key again if the
+// protection has been set already.
+func (p *protectType) setEncryptMetadata(encrypt bool) error {
+	p.plainMetadata = !encrypt
+	if p.setup == nil {
+		return nil
+	}
+	return p.setup()
+}
+
+// setFileID sets the first element of the file identifier, which the key of
+// the standard security handler is derived from, and derives the encryption
+// key again if the protection has been set already.
+func (p *protectType) setFileID(id []byte) error {
+	p.fileID = id
+	if p.setup == nil {
+		return nil
+	}
+	return p.setup()
+}
+
+// randomFileID returns a random file identifier of 16 bytes.
+func randomFileID() ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := crand.Read(id); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
 func (p *protectType) setProtection(privFlag byte, userPassStr, ownerPassStr string) {
+	p.setup = func() error {
+		p.setProtection(privFlag, userPassStr, ownerPassStr)
+		return nil
+	}
 	privFlag = 192 | (privFlag & (CnProtectCopy | CnProtectModify | CnProtectPrint | CnProtectAnnotForms))
 	p.padding = []byte{
 		0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41,
@@ -88,13 +178,197 @@ func (p *protectType) setProtection(privFlag byte, userPassStr, ownerPassStr str
 	userPass = append(userPass, p.padding...)[0:32]
 	ownerPass = append(ownerPass, p.padding...)[0:32]
 	p.encrypted = true
+	p.recipients = nil
+	if p.plainMetadata {
+		p.setProtectionR4(privFlag, userPass, ownerPass)
+		return
+	}
 	p.oValue = oValueGen(userPass, ownerPass)
 	var buf []byte
 	buf = append(buf, userPass...)
 	buf = append(buf, p.oValue...)
 	buf = append(buf, privFlag, 0xff, 0xff, 0xff)
+	buf = append(buf, p.fileID...)
 	sum := md5.Sum(buf)
 	p.encryptionKey = sum[0:5]
 	p.uValue = p.uValueGen()
 	p.pValue = -(int(privFlag^255) + 1)
 }
+
+// setProtectionR4 sets up revision 4 of the standard security handler, with
+// a 128-bit key, which the metadata can be left unencrypted with. userPass
+// and ownerPass are padded.
+func (p *protectType) setProtectionR4(privFlag byte, userPass, ownerPass []byte) {
+	// O value: the padded user password encrypted with the owner password
+	sum := md5.Sum(ownerPass)
+	for j := 0; j < 50; j++ {
+		sum = md5.Sum(sum[:])
+	}
+	p.oValue = rc4Rounds(sum[:], userPass)
+	// encryption key
+	var buf []byte
+	buf = append(buf, userPass...)
+	buf = append(buf, p.oValue...)
+	buf = append(buf, privFlag, 0xff, 0xff, 0xff)
+	buf = append(buf, p.fileID...)
+	buf = append(buf, 0xff, 0xff, 0xff, 0xff) // metadata not encrypted
+	sum = md5.Sum(buf)
+	for j := 0; j < 50; j++ {
+		sum = md5.Sum(sum[:])
+	}
+	p.encryptionKey = append([]byte(nil), sum[:]...)
+	// U value: the padding and the file identifier encrypted with the key,
+	// padded to 32 bytes
+	sum = md5.Sum(append(append([]byte(nil), p.padding...), p.fileID...))
+	p.uValue = append(rc4Rounds(p.encryptionKey, sum[:]), make([]byte, 16)...)
+	p.pValue = -(int(privFlag^255) + 1)
+}
+
+// rc4Rounds encrypts b with key, then 19 more times with key xor-ed with the
+// number of the round, as revisions 3 and 4 of the standard security handler
+// do.
+func rc4Rounds(key, b []byte) []byte {
+	v := append([]byte(nil), b...)
+	k := make([]byte, len(key))
+	for round := 0; round < 20; round++ {
+		for j := range key {
+			k[j] = key[j] ^ byte(round)
+		}
+		c, _ := rc4.NewCipher(k)
+		c.XORKeyStream(v, v)
+	}
+	return v
+}
+
+// Object identifiers of the PKCS#7 envelopes of public-key security.
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEnvelopedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+	oidRSAEncryption = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidAES128CBC     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+)
+
+type pkcs7IssuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type pkcs7RecipientInfo struct {
+	Version         int
+	IssuerAndSerial pkcs7IssuerAndSerial
+	KeyAlgorithm    pkix.AlgorithmIdentifier
+	EncryptedKey    []byte
+}
+
+type pkcs7EncryptedContent struct {
+	ContentType asn1.ObjectIdentifier
+	Algorithm   pkix.AlgorithmIdentifier
+	Content     []byte `asn1:"tag:0"`
+}
+
+type pkcs7EnvelopedData struct {
+	Version    int
+	Recipients []pkcs7RecipientInfo `asn1:"set"`
+	Content    pkcs7EncryptedContent
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     pkcs7EnvelopedData `asn1:"explicit,tag:0"`
+}
+
+// setCertificateProtection sets up public-key security for the recipients
+// whose X.509 certificates, in DER or PEM form, are certs: a random seed and
+// the permissions are enveloped for each of them with PKCS#7, and the 128-bit
+// encryption key is derived from the seed and the envelopes.
+func (p *protectType) setCertificateProtection(privFlag byte, certs [][]byte) error {
+	if len(certs) == 0 {
+		return Err("no recipient certificate")
+	}
+	p.setup = func() error {
+		return p.setCertificateProtection(privFlag, certs)
+	}
+	privFlag = 192 | (privFlag & (CnProtectCopy | CnProtectModify | CnProtectPrint | CnProtectAnnotForms))
+	pValue := -(int(privFlag^255) + 1)
+	message := make([]byte, 24)
+	if _, err := crand.Read(message[:20]); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(message[20:], uint32(int32(pValue)))
+	var recipients [][]byte
+	h := sha1.New()
+	h.Write(message[:20])
+	for _, data := range certs {
+		if block, _ := pem.Decode(data); block != nil {
+			data = block.Bytes
+		}
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return err
+		}
+		envelope, err := pkcs7Envelope(message, cert)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, envelope)
+		h.Write(envelope)
+	}
+	if p.plainMetadata {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	*p = protectType{
+		encrypted:     true,
+		pValue:        pValue,
+		encryptionKey: h.Sum(nil)[:16],
+		recipients:    recipients,
+		plainMetadata: p.plainMetadata,
+		fileID:        p.fileID,
+		setup:         p.setup,
+	}
+	return nil
+}
+
+// pkcs7Envelope returns the DER encoding of a PKCS#7 enveloped data object
+// that holds message for the owner of cert: message is encrypted with a
+// random AES key, which is encrypted with the RSA public key of cert.
+func pkcs7Envelope(message []byte, cert *x509.Certificate) ([]byte, error) {
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, Errf("certificate of %s has no RSA public key", cert.Subject.CommonName)
+	}
+	key := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := crand.Read(key); err != nil {
+		return nil, err
+	}
+	if _, err := crand.Read(iv); err != nil {
+		return nil, err
+	}
+	block, _ := aes.NewCipher(key)
+	pad := aes.BlockSize - len(message)%aes.BlockSize
+	content := append(append([]byte{}, message...), make([]byte, pad)...)
+	for j := len(message); j < len(content); j++ {
+		content[j] = byte(pad)
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(content, content)
+	encryptedKey, err := rsa.EncryptPKCS1v15(crand.Reader, pub, key)
+	if err != nil {
+		return nil, err
+	}
+	ivParam, _ := asn1.Marshal(iv)
+	return asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidEnvelopedData,
+		Content: pkcs7EnvelopedData{
+			Recipients: []pkcs7RecipientInfo{{
+				IssuerAndSerial: pkcs7IssuerAndSerial{asn1.RawValue{FullBytes: cert.RawIssuer}, cert.SerialNumber},
+				KeyAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue},
+				EncryptedKey:    encryptedKey,
+			}},
+			Content: pkcs7EncryptedContent{
+				ContentType: oidData,
+				Algorithm:   pkix.AlgorithmIdentifier{Algorithm: oidAES128CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+				Content:     content,
+			},
+		},
+	})
+}
@@ -0,0 +1,334 @@
+//go:build !wasm
+
+package fpdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"regexp"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// selfSignedCert returns a self-signed certificate for the key in DER form.
+func selfSignedCert(t *testing.T, key any, pub any) []byte {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Reader"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, pub, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestSetCertificateProtection(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der := selfSignedCert(t, key, &key.PublicKey)
+	pdf := New()
+	pdf.SetCompression(false)
+	pdf.SetCertificateProtection(CnProtectPrint, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.Cell(40, 10, "Secret")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) {
+		t.Errorf("expected PDF version 1.4, got %q", out[:8])
+	}
+	if !bytes.Contains(out, []byte("/Filter /Adobe.PubSec")) || bytes.Contains(out, []byte("(Secret)")) {
+		t.Fatalf("document not encrypted for the recipient")
+	}
+
+	// Open the envelope with the private key as a reader would.
+	m := regexp.MustCompile(`/Recipients \[<([0-9a-f]+)>\]`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("no recipients in the encryption dictionary")
+	}
+	envelope, _ := hex.DecodeString(string(m[1]))
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(envelope, &info); err != nil {
+		t.Fatal(err)
+	}
+	content := info.Content.Content
+	aesKey, err := rsa.DecryptPKCS1v15(nil, key, info.Content.Recipients[0].EncryptedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(content.Algorithm.Parameters.FullBytes, &iv); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := aes.NewCipher(aesKey)
+	message := make([]byte, len(content.Content))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(message, content.Content)
+	if p := int32(binary.BigEndian.Uint32(message[20:])); int(p) != pdf.protect.pValue {
+		t.Errorf("permissions: got %d, want %d", p, pdf.protect.pValue)
+	}
+	h := sha1.New()
+	h.Write(message[:20])
+	h.Write(envelope)
+	encKey := h.Sum(nil)[:16]
+	if !bytes.Equal(encKey, pdf.protect.encryptionKey) {
+		t.Fatalf("derived key differs from the encryption key")
+	}
+
+	if !bytes.Contains(decryptStreams(out, encKey), []byte("(Secret)Tj")) {
+		t.Errorf("page content not decrypted with the derived key")
+	}
+}
+
+// decryptStreams returns the streams of the PDF document out decrypted with
+// the encryption key.
+func decryptStreams(out, key []byte) []byte {
+	var streams []byte
+	for _, m := range regexp.MustCompile(`(?s)\n(\d+) 0 obj\n<<[^>]*>>\nstream\n(.*?)\nendstream`).FindAllSubmatch(out, -1) {
+		n, _ := strconv.Atoi(string(m[1]))
+		p := protectType{encryptionKey: key}
+		b := append([]byte{}, m[2]...)
+		p.rc4(uint32(n), &b)
+		streams = append(streams, b...)
+	}
+	return streams
+}
+
+func TestSetCertificateProtectionErrors(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, certs := range map[string][][]byte{
+		"none":    nil,
+		"invalid": {[]byte("not a certificate")},
+		"ecdsa":   {selfSignedCert(t, ecKey, &ecKey.PublicKey)},
+	} {
+		pdf := New()
+		pdf.SetCertificateProtection(0, certs...)
+		if pdf.Error() == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSetEncryptMetadata(t *testing.T) {
+	const xmp = `<x:xmpmeta xmlns:x="adobe:ns:meta/">archive record</x:xmpmeta>`
+	padding := []byte{
+		0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41,
+		0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+		0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80,
+		0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+	}
+	for _, before := range []bool{true, false} {
+		pdf := New()
+		pdf.SetCompression(false)
+		pdf.SetXmpMetadata([]byte(xmp))
+		if before {
+			pdf.SetEncryptMetadata(false)
+		}
+		pdf.SetProtection(CnProtectPrint, "", "owner")
+		if !before {
+			pdf.SetEncryptMetadata(false)
+		}
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "", 12)
+		pdf.Cell(40, 10, "Secret")
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.Bytes()
+		if !bytes.HasPrefix(out, []byte("%PDF-1.5")) {
+			t.Errorf("expected PDF version 1.5, got %q", out[:8])
+		}
+		for _, s := range []string{xmp, "/V 4", "/R 4", "/EncryptMetadata false"} {
+			if !bytes.Contains(out, []byte(s)) {
+				t.Errorf("%s missing", s)
+			}
+		}
+
+		// Derive the key from the empty user password as a reader would.
+		p := pdf.protect
+		b := append(append([]byte{}, padding...), p.oValue...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(int32(p.pValue)))
+		b = append(b, 0xff, 0xff, 0xff, 0xff)
+		sum := md5.Sum(b)
+		for j := 0; j < 50; j++ {
+			sum = md5.Sum(sum[:])
+		}
+		if !bytes.Equal(sum[:], p.encryptionKey) {
+			t.Fatalf("key differs from the one derived from the user password")
+		}
+		check := md5.Sum(padding)
+		if u := rc4Rounds(sum[:], check[:]); !bytes.Equal(u, p.uValue[:16]) {
+			t.Errorf("U value does not authenticate the user password")
+		}
+		if !bytes.Contains(decryptStreams(out, sum[:]), []byte("(Secret)Tj")) {
+			t.Errorf("page content not decrypted with the derived key")
+		}
+	}
+}
+
+func TestSetEncryptMetadataCertificate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf := New()
+	pdf.SetXmpMetadata([]byte("<x:xmpmeta>record</x:xmpmeta>"))
+	pdf.SetCertificateProtection(0, selfSignedCert(t, key, &key.PublicKey))
+	pdf.SetEncryptMetadata(false)
+	pdf.AddPage()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	for _, s := range []string{"<x:xmpmeta>record</x:xmpmeta>", "/SubFilter /adbe.pkcs7.s5", "/EncryptMetadata false"} {
+		if !bytes.Contains(out, []byte(s)) {
+			t.Errorf("%s missing", s)
+		}
+	}
+	envelope := pdf.protect.recipients[0]
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(envelope, &info); err != nil {
+		t.Fatal(err)
+	}
+	aesKey, err := rsa.DecryptPKCS1v15(nil, key, info.Content.Recipients[0].EncryptedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var iv []byte
+	asn1.Unmarshal(info.Content.Content.Algorithm.Parameters.FullBytes, &iv)
+	block, _ := aes.NewCipher(aesKey)
+	message := make([]byte, len(info.Content.Content.Content))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(message, info.Content.Content.Content)
+	h := sha1.New()
+	h.Write(message[:20])
+	h.Write(envelope)
+	h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	if !bytes.Equal(h.Sum(nil)[:16], pdf.protect.encryptionKey) {
+		t.Errorf("derived key differs from the encryption key")
+	}
+}
+
+func TestEncryptedStreamMemory(t *testing.T) {
+	pdf := New()
+	pdf.SetProtection(0, "", "owner")
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<18) // 4 MiB
+	orig := append([]byte(nil), data...)
+	pdf.buffer.Grow(len(data) + 1024)
+	pdf.newobj()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	pdf.putstream(data)
+	runtime.ReadMemStats(&after)
+	if extra := after.TotalAlloc - before.TotalAlloc; extra > 1<<20 {
+		t.Errorf("encrypting a stream of %d bytes allocated %d bytes", len(data), extra)
+	}
+	if !bytes.Equal(data, orig) {
+		t.Fatal("stream data modified by the encryption")
+	}
+	out := pdf.buffer.Bytes()
+	start := bytes.Index(out, []byte("stream\n")) + len("stream\n")
+	if !bytes.HasSuffix(out, []byte("\nendstream\n")) || len(out)-start != len(data)+len("\nendstream\n") {
+		t.Fatalf("unexpected stream layout")
+	}
+	pdf.protect.rc4(uint32(pdf.n), &orig)
+	if !bytes.Equal(out[start:start+len(data)], orig) {
+		t.Error("stream not encrypted with the key of its object")
+	}
+
+	// The streams share the space in which they are encrypted.
+	small := []byte("BT (tiny) Tj ET")
+	runtime.ReadMemStats(&before)
+	for j := 0; j < 100; j++ {
+		pdf.newobj()
+		pdf.putstream(small)
+	}
+	runtime.ReadMemStats(&after)
+	if extra := after.TotalAlloc - before.TotalAlloc; extra > 512<<10 {
+		t.Errorf("encrypting 100 small streams allocated %d bytes", extra)
+	}
+}
+
+func TestSetFileID(t *testing.T) {
+	output := func(pdf *Fpdf) []byte {
+		pdf.SetCompression(false)
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "", 12)
+		pdf.Cell(40, 10, "Secret")
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	id := []byte("0123456789abcdef")
+	pdf := New()
+	pdf.SetFileID(id)
+	if out := output(pdf); !bytes.Contains(out, []byte("/ID [<30313233343536373839616263646566><30313233343536373839616263646566>]")) {
+		t.Errorf("fixed identifier missing from the trailer")
+	}
+	ids := regexp.MustCompile(`/ID \[<([0-9a-f]{32})><([0-9a-f]{32})>\]`)
+	var random [][]byte
+	for j := 0; j < 2; j++ {
+		pdf = New()
+		pdf.SetFileID(nil)
+		m := ids.FindSubmatch(output(pdf))
+		if m == nil || !bytes.Equal(m[1], m[2]) {
+			t.Fatalf("no random identifier in the trailer")
+		}
+		random = append(random, m[1])
+	}
+	if bytes.Equal(random[0], random[1]) {
+		t.Errorf("same random identifier twice")
+	}
+
+	// The key of the standard security handler is derived from the
+	// identifier, whether it is set before or after the protection
+	for _, before := range []bool{true, false} {
+		pdf = New()
+		if before {
+			pdf.SetFileID(id)
+		}
+		pdf.SetProtection(CnProtectPrint, "user", "owner")
+		if !before {
+			pdf.SetFileID(id)
+		}
+		out := output(pdf)
+		p := pdf.protect
+		b := append([]byte("user"), p.padding...)[:32]
+		b = append(b, p.oValue...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(int32(p.pValue)))
+		b = append(b, id...)
+		sum := md5.Sum(b)
+		if !bytes.Contains(decryptStreams(out, sum[:5]), []byte("(Secret)Tj")) {
+			t.Errorf("page content not decrypted with the key derived with the identifier")
+		}
+	}
+}
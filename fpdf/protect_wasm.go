@@ -2,6 +2,12 @@
 
 package fpdf
 
+import (
+	"io"
+
+	. "github.com/tinywasm/fmt"
+)
+
 // Advisory bitflag constants that control document activities
 const (
 	CnProtectPrint      = 4
@@ -18,11 +24,17 @@ type protectType struct {
 	padding       []byte
 	encryptionKey []byte
 	objNum        int
+	recipients    [][]byte
+	plainMetadata bool
 }
 
 func (p *protectType) rc4(n uint32, buf *[]byte) {
 }
 
+func (p *protectType) streamWriter(n uint32, w io.Writer) io.Writer {
+	return w
+}
+
 func (p *protectType) objectKey(n uint32) []byte {
 	return nil
 }
@@ -35,5 +47,21 @@ func (p *protectType) uValueGen() (v []byte) {
 	return nil
 }
 
+func (p *protectType) setEncryptMetadata(encrypt bool) error {
+	return nil
+}
+
+func (p *protectType) setFileID(id []byte) error {
+	return nil
+}
+
+func randomFileID() ([]byte, error) {
+	return nil, Err("random file identifiers are not supported in WebAssembly builds")
+}
+
 func (p *protectType) setProtection(privFlag byte, userPassStr, ownerPassStr string) {
 }
+
+func (p *protectType) setCertificateProtection(privFlag byte, certs [][]byte) error {
+	return Err("certificate encryption is not supported in WebAssembly builds")
+}
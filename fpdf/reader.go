@@ -0,0 +1,632 @@
+package fpdf
+
+import (
+	"bytes"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// PDFReader reads back PDF files such as the ones Fpdf writes: their pages,
+// the text runs of the pages and the document information. It is a minimal
+// parser meant for verifying the output of Fpdf, not a general PDF reader:
+// files must have a cross-reference table rather than a cross-reference
+// stream, must not be encrypted, and streams must be uncompressed or
+// compressed with FlateDecode.
+type PDFReader struct {
+	data    []byte
+	version string
+	offsets map[int]int // object number -> offset in data
+	objects map[int]any // objects read so far
+	trailer map[string]any
+	pages   []map[string]any // page dictionaries, with inherited attributes
+	fonts   map[pdfRef]*readFont
+}
+
+// PDFInfo is the document information dictionary of a PDF file, as returned
+// by PDFReader.Info(). Text values are converted to UTF-8; the dates are in
+// the PDF format, e.g. "D:20240630123456".
+type PDFInfo struct {
+	Title, Subject, Author, Keywords string
+	Creator, Producer                string
+	CreationDate, ModDate            string
+}
+
+// pdfRef is an indirect reference to an object, as in "12 0 R".
+type pdfRef struct {
+	num, gen int
+}
+
+// pdfName is a name object, such as /Type, without the slash.
+type pdfName string
+
+// pdfOp is an operator of a content stream, or a keyword such as "obj".
+type pdfOp string
+
+// pdfStream is a stream object: its dictionary and its data before decoding.
+type pdfStream struct {
+	dict map[string]any
+	data []byte
+}
+
+// ReadPDF parses the PDF file held in data. data is kept by the returned
+// reader and must not be modified while it is used.
+func ReadPDF(data []byte) (*PDFReader, error) {
+	r := &PDFReader{data: data, offsets: make(map[int]int), objects: make(map[int]any),
+		fonts: make(map[pdfRef]*readFont)}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, Err("ReadPDF: not a PDF file")
+	}
+	if end := bytes.IndexAny(data[5:], "\r\n"); end > 0 {
+		r.version = string(data[5 : 5+end])
+	}
+	if err := r.readXref(); err != nil {
+		return nil, err
+	}
+	if _, ok := r.trailer["Encrypt"]; ok {
+		return nil, Err("ReadPDF: encrypted documents are not supported")
+	}
+	root := r.dict(r.trailer["Root"])
+	if root == nil {
+		return nil, Err("ReadPDF: document catalog not found")
+	}
+	if err := r.readPages(r.resolve(root["Pages"]), map[string]any{}, 0); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Version returns the PDF version of the file, e.g. "1.3".
+func (r *PDFReader) Version() string {
+	return r.version
+}
+
+// PageCount returns the number of pages of the document.
+func (r *PDFReader) PageCount() int {
+	return len(r.pages)
+}
+
+// PageSize returns the width and height in points of page n, 1-based: the
+// size of its media box, or 0, 0 if the page does not exist.
+func (r *PDFReader) PageSize(n int) (wd, ht float64) {
+	if n < 1 || n > len(r.pages) {
+		return 0, 0
+	}
+	box, _ := r.resolve(r.pages[n-1]["MediaBox"]).([]any)
+	if len(box) != 4 {
+		return 0, 0
+	}
+	return r.number(box[2]) - r.number(box[0]), r.number(box[3]) - r.number(box[1])
+}
+
+// Info returns the document information dictionary of the file.
+func (r *PDFReader) Info() PDFInfo {
+	info := r.dict(r.trailer["Info"])
+	text := func(key string) string {
+		s, _ := r.resolve(info[key]).(string)
+		return decodeTextString(s)
+	}
+	return PDFInfo{
+		Title: text("Title"), Subject: text("Subject"), Author: text("Author"),
+		Keywords: text("Keywords"), Creator: text("Creator"), Producer: text("Producer"),
+		CreationDate: text("CreationDate"), ModDate: text("ModDate"),
+	}
+}
+
+// readXref reads the cross-reference tables and the trailer, following the
+// /Prev entries of incremental updates; entries of later updates prevail.
+func (r *PDFReader) readXref() error {
+	pos := bytes.LastIndex(r.data, []byte("startxref"))
+	if pos < 0 {
+		return Err("ReadPDF: startxref not found")
+	}
+	lex := pdfLexer{data: r.data, pos: pos + len("startxref")}
+	v, err := lex.object()
+	off, ok := v.(float64)
+	if err != nil || !ok {
+		return Err("ReadPDF: invalid startxref")
+	}
+	for seen := map[int]bool{}; ; {
+		if seen[int(off)] || off < 0 || int(off) >= len(r.data) {
+			return Errf("ReadPDF: invalid cross-reference offset %d", int(off))
+		}
+		seen[int(off)] = true
+		trailer, err := r.readXrefSection(int(off))
+		if err != nil {
+			return err
+		}
+		if r.trailer == nil {
+			r.trailer = trailer
+		}
+		prev, ok := trailer["Prev"].(float64)
+		if !ok {
+			return nil
+		}
+		off = prev
+	}
+}
+
+// readXrefSection reads the cross-reference table at offset off and returns
+// the trailer dictionary that follows it.
+func (r *PDFReader) readXrefSection(off int) (map[string]any, error) {
+	lex := pdfLexer{data: r.data, pos: off}
+	if v, _ := lex.object(); v != pdfOp("xref") {
+		return nil, Err("ReadPDF: cross-reference streams are not supported")
+	}
+	for {
+		v, err := lex.object()
+		if err != nil {
+			return nil, err
+		}
+		if v == pdfOp("trailer") {
+			break
+		}
+		first, ok := v.(float64)
+		v, err = lex.object()
+		count, ok2 := v.(float64)
+		if err != nil || !ok || !ok2 {
+			return nil, Err("ReadPDF: invalid cross-reference table")
+		}
+		for j := range int(count) {
+			ov, _ := lex.object()
+			lex.object() // generation
+			kind, _ := lex.object()
+			offset, ok := ov.(float64)
+			if !ok {
+				return nil, Err("ReadPDF: invalid cross-reference entry")
+			}
+			num := int(first) + j
+			if _, set := r.offsets[num]; !set && kind == pdfOp("n") {
+				r.offsets[num] = int(offset)
+			}
+		}
+	}
+	v, err := lex.object()
+	trailer, ok := v.(map[string]any)
+	if err != nil || !ok {
+		return nil, Err("ReadPDF: invalid trailer")
+	}
+	return trailer, nil
+}
+
+// readPages appends the pages of the page tree node to r.pages. Attributes
+// that pages inherit from their ancestors are copied into their dictionaries.
+func (r *PDFReader) readPages(node any, inherited map[string]any, depth int) error {
+	dict, ok := node.(map[string]any)
+	if !ok || depth > 64 {
+		return Err("ReadPDF: invalid page tree")
+	}
+	attrs := make(map[string]any, len(inherited))
+	for key, v := range inherited {
+		attrs[key] = v
+	}
+	for _, key := range []string{"MediaBox", "Resources", "Rotate", "CropBox"} {
+		if v, ok := dict[key]; ok {
+			attrs[key] = v
+		}
+	}
+	if dict["Type"] == pdfName("Page") {
+		page := make(map[string]any, len(dict)+len(attrs))
+		for key, v := range dict {
+			page[key] = v
+		}
+		for key, v := range attrs {
+			page[key] = v
+		}
+		r.pages = append(r.pages, page)
+		return nil
+	}
+	kids, _ := r.resolve(dict["Kids"]).([]any)
+	for _, kid := range kids {
+		if err := r.readPages(r.resolve(kid), attrs, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// object returns the indirect object numbered num, or nil if there is none.
+func (r *PDFReader) object(num int) any {
+	if v, ok := r.objects[num]; ok {
+		return v
+	}
+	off, ok := r.offsets[num]
+	if !ok || off < 0 || off >= len(r.data) {
+		return nil
+	}
+	r.objects[num] = nil // guards against loops through /Length
+	lex := pdfLexer{data: r.data, pos: off}
+	lex.object() // object number
+	lex.object() // generation
+	if v, _ := lex.object(); v != pdfOp("obj") {
+		return nil
+	}
+	v, err := lex.object()
+	if err != nil {
+		return nil
+	}
+	if dict, ok := v.(map[string]any); ok {
+		save := lex.pos
+		if kw, _ := lex.object(); kw == pdfOp("stream") {
+			v = r.readStream(dict, lex.pos)
+		} else {
+			lex.pos = save
+		}
+	}
+	r.objects[num] = v
+	return v
+}
+
+// readStream returns the stream of dictionary dict whose data follows the
+// "stream" keyword ending at pos.
+func (r *PDFReader) readStream(dict map[string]any, pos int) *pdfStream {
+	if pos < len(r.data) && r.data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(r.data) && r.data[pos] == '\n' {
+		pos++
+	}
+	length := int(r.number(dict["Length"]))
+	end := pos + length
+	if length <= 0 || end > len(r.data) || !bytes.HasPrefix(bytes.TrimLeft(r.data[end:], "\r\n "), []byte("endstream")) {
+		// Wrong /Length: the data ends before the endstream keyword
+		if i := bytes.Index(r.data[pos:], []byte("endstream")); i >= 0 {
+			end = pos + i
+			for end > pos && (r.data[end-1] == '\n' || r.data[end-1] == '\r') {
+				end--
+			}
+		} else {
+			end = pos
+		}
+	}
+	return &pdfStream{dict: dict, data: r.data[pos:end]}
+}
+
+// resolve returns the object v refers to if it is a reference, v otherwise.
+func (r *PDFReader) resolve(v any) any {
+	for range 8 {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = r.object(ref.num)
+	}
+	return nil
+}
+
+// dict returns the dictionary v is or refers to, the dictionary of a stream
+// included, or nil.
+func (r *PDFReader) dict(v any) map[string]any {
+	switch v := r.resolve(v).(type) {
+	case map[string]any:
+		return v
+	case *pdfStream:
+		return v.dict
+	}
+	return nil
+}
+
+// number returns the number v is or refers to, or 0.
+func (r *PDFReader) number(v any) float64 {
+	n, _ := r.resolve(v).(float64)
+	return n
+}
+
+// decode returns the data of stream s once decoded by its filters.
+func (r *PDFReader) decode(s *pdfStream) ([]byte, error) {
+	var filters []any
+	switch v := r.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{v}
+	case []any:
+		filters = v
+	}
+	data := s.data
+	for _, filter := range filters {
+		if r.resolve(filter) != pdfName("FlateDecode") {
+			return nil, Errf("ReadPDF: unsupported stream filter %v", r.resolve(filter))
+		}
+		mem, err := xmem.uncompress(data)
+		if err != nil {
+			return nil, err
+		}
+		data = mem.copy()
+		mem.release()
+	}
+	return data, nil
+}
+
+// decodeTextString converts a PDF text string, in UTF-16BE with a byte order
+// mark or in PDFDocEncoding, to UTF-8. PDFDocEncoding is taken as Latin-1,
+// which it matches for the printable characters but a few.
+func decodeTextString(s string) string {
+	if len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff {
+		return utf16beToString([]byte(s[2:]))
+	}
+	runes := make([]rune, len(s))
+	for j := 0; j < len(s); j++ {
+		runes[j] = rune(s[j])
+	}
+	return string(runes)
+}
+
+// utf16beToString converts UTF-16BE bytes to a string.
+func utf16beToString(b []byte) string {
+	runes := make([]rune, 0, len(b)/2)
+	for j := 0; j+1 < len(b); j += 2 {
+		c := rune(b[j])<<8 | rune(b[j+1])
+		if c >= 0xd800 && c < 0xdc00 && j+3 < len(b) {
+			lo := rune(b[j+2])<<8 | rune(b[j+3])
+			if lo >= 0xdc00 && lo < 0xe000 {
+				c = 0x10000 + (c-0xd800)<<10 + lo - 0xdc00
+				j += 2
+			}
+		}
+		runes = append(runes, c)
+	}
+	return string(runes)
+}
+
+// pdfLexer reads the objects of a PDF file or of a content stream.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelim(c byte) bool {
+	return c == '(' || c == ')' || c == '<' || c == '>' || c == '[' || c == ']' ||
+		c == '{' || c == '}' || c == '/' || c == '%'
+}
+
+// skipSpace skips white space and comments.
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// word reads regular characters, up to a space or a delimiter.
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// object reads the next object: a number as float64, a string as string, a
+// boolean, nil for null, a pdfName, a pdfRef, an array as []any, a
+// dictionary as map[string]any keyed by names without the slash, or a
+// pdfOp for any other word.
+func (l *pdfLexer) object() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, Err("ReadPDF: unexpected end of data")
+	}
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		l.pos++
+		return pdfName(decodeName(l.word())), nil
+	case c == '(':
+		return l.literalString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.dictionary()
+	case c == '<':
+		return l.hexString(), nil
+	case c == '[':
+		l.pos++
+		var arr []any
+		for {
+			l.skipSpace()
+			if l.pos < len(l.data) && l.data[l.pos] == ']' {
+				l.pos++
+				return arr, nil
+			}
+			v, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+		return l.numberOrRef()
+	case isPDFDelim(c):
+		l.pos++
+		return nil, Errf("ReadPDF: unexpected %q at offset %d", c, l.pos-1)
+	}
+	switch w := l.word(); w {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return pdfOp(w), nil
+	}
+}
+
+// skipInlineImage skips an inline image after its BI operator, up to and
+// including the EI operator.
+func (l *pdfLexer) skipInlineImage() {
+	for l.pos < len(l.data) {
+		if w, err := l.object(); err != nil || w == pdfOp("ID") {
+			break
+		}
+	}
+	for l.pos+2 < len(l.data) && !(isPDFSpace(l.data[l.pos]) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I') {
+		l.pos++
+	}
+	l.pos = min(l.pos+3, len(l.data))
+}
+
+// dictionary reads the entries of a dictionary after its "<<".
+func (l *pdfLexer) dictionary() (map[string]any, error) {
+	dict := make(map[string]any)
+	for {
+		l.skipSpace()
+		if bytes.HasPrefix(l.data[l.pos:], []byte(">>")) {
+			l.pos += 2
+			return dict, nil
+		}
+		key, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			return nil, Errf("ReadPDF: invalid dictionary key at offset %d", l.pos)
+		}
+		v, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		dict[string(name)] = v
+	}
+}
+
+// numberOrRef reads a number, or a reference if the number is followed by a
+// generation number and R.
+func (l *pdfLexer) numberOrRef() (any, error) {
+	start := l.pos
+	w := l.word()
+	n, err := Convert(w).Float64()
+	if err != nil {
+		return nil, Errf("ReadPDF: invalid number %q at offset %d", w, start)
+	}
+	if n != float64(int(n)) || n < 0 || Contains(w, ".") {
+		return n, nil
+	}
+	save := l.pos
+	l.skipSpace()
+	if l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		gen, err := Convert(l.word()).Int()
+		l.skipSpace()
+		if err == nil && l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+			(l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelim(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: int(n), gen: gen}, nil
+		}
+	}
+	l.pos = save
+	return n, nil
+}
+
+// literalString reads a string in parentheses, with its escapes.
+func (l *pdfLexer) literalString() string {
+	l.pos++ // (
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return string(buf)
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				break
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// Line continuation
+				if c == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					oct := int(c - '0')
+					for k := 0; k < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; k++ {
+						oct = oct*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(oct)
+				}
+			}
+		}
+		buf = append(buf, c)
+	}
+	return string(buf)
+}
+
+// hexString reads a string of hexadecimal digits in angle brackets.
+func (l *pdfLexer) hexString() string {
+	l.pos++ // <
+	var buf []byte
+	var hi byte
+	odd := false
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		l.pos++
+		var v byte
+		switch {
+		case c >= '0' && c <= '9':
+			v = c - '0'
+		case c >= 'a' && c <= 'f':
+			v = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			v = c - 'A' + 10
+		default:
+			continue
+		}
+		if odd {
+			buf = append(buf, hi<<4|v)
+		} else {
+			hi = v
+		}
+		odd = !odd
+	}
+	if odd {
+		buf = append(buf, hi<<4)
+	}
+	l.pos++ // >
+	return string(buf)
+}
+
+// decodeName replaces the #xx escapes of a name.
+func decodeName(s string) string {
+	if !Contains(s, "#") {
+		return s
+	}
+	var buf []byte
+	for j := 0; j < len(s); j++ {
+		if s[j] == '#' && j+2 < len(s) {
+			if v, err := Convert(s[j+1 : j+3]).Int(16); err == nil {
+				buf = append(buf, byte(v))
+				j += 2
+				continue
+			}
+		}
+		buf = append(buf, s[j])
+	}
+	return string(buf)
+}
@@ -0,0 +1,267 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+// readBack outputs pdf and parses the result.
+func readBack(t *testing.T, pdf *fpdf.Fpdf) *fpdf.PDFReader {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r, err := fpdf.ReadPDF(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestReadPDF(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(compress)
+		pdf.SetTitle("Résumé ✓", true)
+		pdf.SetAuthor("Jane Doe", false)
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "", 12)
+		pdf.Text(10, 20, "Hello (world) \xe9") // é in cp1252
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.Text(10, 30, "Bold")
+		pdf.AddUTF8Font("dejavu", "", FontFile("DejaVuSansCondensed.ttf"))
+		pdf.SetFont("dejavu", "", 10)
+		pdf.Text(10, 40, "Привет")
+		pdf.AddPageFormat(fpdf.Landscape, fpdf.PageSize{Wd: 300, Ht: 200})
+		pdf.Cell(40, 10, "Page two")
+		r := readBack(t, pdf)
+
+		if got := r.PageCount(); got != 2 {
+			t.Fatalf("got %d pages, want 2", got)
+		}
+		if wd, ht := r.PageSize(1); math.Abs(wd-595.28) > 0.01 || math.Abs(ht-841.89) > 0.01 {
+			t.Errorf("page 1 is %.2f x %.2f, want A4", wd, ht)
+		}
+		if wd, ht := r.PageSize(2); wd != 200 || ht != 300 {
+			t.Errorf("page 2 is %.2f x %.2f, want 200 x 300", wd, ht)
+		}
+		info := r.Info()
+		if info.Title != "Résumé ✓" || info.Author != "Jane Doe" || info.CreationDate != "D:20000101000000" {
+			t.Errorf("got info %+v", info)
+		}
+
+		runs, err := r.TextRuns(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs) != 3 {
+			t.Fatalf("got %d runs, want 3: %+v", len(runs), runs)
+		}
+		want := []struct {
+			text, font string
+			y          float64
+		}{
+			{"Hello (world) é", "Helvetica", 841.89 - 20*72/25.4},
+			{"Bold", "Helvetica-Bold", 841.89 - 30*72/25.4},
+			{"Привет", "utf8dejavu", 841.89 - 40*72/25.4},
+		}
+		for j, w := range want {
+			run := runs[j]
+			if run.Text != w.text || run.Font != w.font {
+				t.Errorf("run %d is %q in %s, want %q in %s", j, run.Text, run.Font, w.text, w.font)
+			}
+			if math.Abs(run.X-10*72/25.4) > 0.01 || math.Abs(run.Y-w.y) > 0.01 {
+				t.Errorf("run %d at (%.2f, %.2f), want (%.2f, %.2f)", j, run.X, run.Y, 10*72/25.4, w.y)
+			}
+		}
+		if runs[0].Size != 12 || runs[2].Size != 10 {
+			t.Errorf("got sizes %.2f and %.2f, want 12 and 10", runs[0].Size, runs[2].Size)
+		}
+		pdf.SetFont("Helvetica", "", 12)
+		if w := pdf.PointConvert(runs[0].Width); math.Abs(w-pdf.GetStringWidth("Hello (world) \xe9")) > 0.01 {
+			t.Errorf("got width %.2f, want %.2f", w, pdf.GetStringWidth("Hello (world) \xe9"))
+		}
+		if text, _ := r.PageText(2); text != "Page two" {
+			t.Errorf("got text %q on page 2", text)
+		}
+	}
+}
+
+func TestReadPDFPageText(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.CellFormat(30, 8, "Name", "", 0, "L", false, 0, "")
+	pdf.CellFormat(30, 8, "Qty", "", 1, "L", false, 0, "")
+	pdf.CellFormat(30, 8, "Apples", "", 0, "L", false, 0, "")
+	pdf.CellFormat(30, 8, "3", "", 1, "L", false, 0, "")
+	pdf.CellFormat(60, 8, "Justified text here", "", 1, "J", false, 0, "")
+	text, err := readBack(t, pdf).PageText(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Name Qty\nApples 3\nJustified text here"; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+}
+
+func TestReadPDFImposed(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Helvetica", "", 12)
+	for _, s := range []string{"First", "Second"} {
+		pdf.AddPage()
+		pdf.Text(10, 20, s)
+	}
+	pdf.Impose(fpdf.NUp(2))
+	r := readBack(t, pdf)
+	if r.PageCount() != 1 {
+		t.Fatalf("got %d sheets, want 1", r.PageCount())
+	}
+	runs, err := r.TextRuns(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Text != "First" || runs[1].Text != "Second" {
+		t.Fatalf("got runs %+v", runs)
+	}
+	if dx := runs[1].X - runs[0].X; math.Abs(dx-595.28) > 0.01 {
+		t.Errorf("second page shifted by %.2f, want 595.28", dx)
+	}
+}
+
+func TestReadPDFInheritedAttributes(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	label := fpdf.PageSize{Wd: 288, Ht: 144}
+	for j := 0; j < 5; j++ {
+		pdf.AddPageFormat(fpdf.Portrait, label)
+		pdf.Text(10, 20, "Label")
+	}
+	pdf.AddPage()
+	pdf.Text(10, 20, "Summary")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// The Pages root and the A4 page have a media box, the labels inherit it
+	if n := strings.Count(out, "/MediaBox"); n != 2 {
+		t.Errorf("got %d media boxes, want 2", n)
+	}
+	if n := strings.Count(out, "/Resources 2 0 R"); n != 1 {
+		t.Errorf("got %d references to the resources, want 1", n)
+	}
+	r, err := fpdf.ReadPDF(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, want := range map[int][2]float64{1: {288, 144}, 5: {288, 144}, 6: {595.28, 841.89}} {
+		if wd, ht := r.PageSize(n); wd != want[0] || ht != want[1] {
+			t.Errorf("page %d: got size %.2f x %.2f, want %.2f x %.2f", n, wd, ht, want[0], want[1])
+		}
+	}
+	if text, err := r.PageText(6); err != nil || text != "Summary" {
+		t.Errorf("page 6: got text %q, %v", text, err)
+	}
+}
+
+func TestReadPDFErrors(t *testing.T) {
+	if _, err := fpdf.ReadPDF([]byte("hello")); err == nil {
+		t.Error("no error for a file that is not a PDF")
+	}
+	if _, err := fpdf.ReadPDF([]byte("%PDF-1.3\n1 0 obj\n<<>>\nendobj\n")); err == nil {
+		t.Error("no error for a PDF without cross-reference table")
+	}
+	pdf := NewDocPdfTest()
+	pdf.SetProtection(0, "", "owner")
+	pdf.AddPage()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fpdf.ReadPDF(buf.Bytes()); err == nil {
+		t.Error("no error for an encrypted PDF")
+	}
+	r := readBack(t, NewDocPdfTest())
+	if _, err := r.TextRuns(r.PageCount() + 1); err == nil {
+		t.Error("no error for a missing page")
+	}
+}
+
+func TestUserStringEscaping(t *testing.T) {
+	const hostile = `x) /OpenAction [3 0 R /Fit] \ (`
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetTitle(hostile+"\r", false)
+	pdf.SetAuthor(hostile, true)
+	pdf.SetSubject(hostile, false)
+	pdf.SetKeywords(hostile, false)
+	pdf.SetCreator(hostile, false)
+	pdf.SetProducer(hostile, false)
+	pdf.SetLang(hostile)
+	pdf.AddSpotColor("Spot (1)/A", 0, 50, 100, 0)
+	pdf.AddUTF8Font("my font/x", "", FontFile("DejaVuSansCondensed.ttf"))
+	pdf.AddPage()
+	pdf.SetFont("my font/x", "", 12)
+	pdf.SetTextSpotColor("Spot (1)/A", 100)
+	pdf.Text(10, 20, "Hello")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"x) /OpenAction", "/Spot (1)", "/utf8my font"} {
+		if strings.Contains(out, s) {
+			t.Errorf("%q written unescaped", s)
+		}
+	}
+	r, err := fpdf.ReadPDF(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := r.Info()
+	if info.Title != hostile+"\r" || info.Author != hostile || info.Subject != hostile ||
+		info.Keywords != hostile || info.Creator != hostile || info.Producer != hostile {
+		t.Errorf("got info %+v", info)
+	}
+	runs, err := r.TextRuns(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Text != "Hello" || runs[0].Font != "utf8my font/x" {
+		t.Errorf("got runs %+v", runs)
+	}
+}
+
+func TestFontFamilyWithSpace(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("dejavu sans", "", FontFile("DejaVuSansCondensed.ttf"))
+	pdf.AddUTF8Font("dejavu sans", "B", FontFile("DejaVuSansCondensed-Bold.ttf"))
+	// The page setup and SetFontStyle set the current family again.
+	pdf.SetFont("dejavu sans", "", 12)
+	pdf.AddPage()
+	pdf.Text(10, 20, "Hello")
+	pdf.SetFontStyle("B")
+	pdf.AddPage()
+	pdf.Text(10, 20, "World")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if family := pdf.GetFontFamily(); family != "dejavu sans" {
+		t.Errorf("unexpected family: %s", family)
+	}
+	out := buf.String()
+	for _, s := range []string{"/BaseFont /utf8dejavu#20sans\n", "/BaseFont /utf8dejavu#20sansB\n"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,566 @@
+package fpdf
+
+import (
+	"math"
+	"sort"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// TextRun is a piece of text shown by a single text operator of a page, as
+// returned by PDFReader.TextRuns(). Positions are in points from the bottom
+// left corner of the page, as in the PDF file.
+type TextRun struct {
+	Text  string
+	X, Y  float64 // start of the baseline
+	Width float64 // horizontal advance of the text
+	Size  float64 // font size, scaled by the transformations
+	Font  string  // base font name, e.g. "Helvetica-Bold"
+}
+
+// readMatrix is a transformation matrix [a b c d e f] of a content stream.
+type readMatrix [6]float64
+
+var readIdentity = readMatrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n, the transformation m followed by n.
+func (m readMatrix) mul(n readMatrix) readMatrix {
+	return readMatrix{
+		m[0]*n[0] + m[1]*n[2], m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2], m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4], m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// apply returns the point (x, y) transformed by m.
+func (m readMatrix) apply(x, y float64) (float64, float64) {
+	return x*m[0] + y*m[2] + m[4], x*m[1] + y*m[3] + m[5]
+}
+
+// readFont is what PDFReader needs of a font to decode and measure text.
+type readFont struct {
+	name    string
+	twoByte bool            // codes are two bytes, as in Type0 fonts
+	unicode *readCMap       // ToUnicode CMap, if any
+	enc     *[256]rune      // encoding of a simple font without ToUnicode
+	widths  map[int]float64 // width of codes in thousandths of an em
+	dw      float64         // width of codes missing from widths
+}
+
+// readCMap maps codes to text as a ToUnicode CMap does.
+type readCMap struct {
+	chars  map[int]string
+	ranges []readCMapRange
+}
+
+type readCMapRange struct {
+	lo, hi int
+	dst    []rune // text of lo; the last rune is incremented along the range
+}
+
+// text returns the text of code.
+func (cm *readCMap) text(code int) (string, bool) {
+	if s, ok := cm.chars[code]; ok {
+		return s, true
+	}
+	for _, rg := range cm.ranges {
+		if code >= rg.lo && code <= rg.hi && len(rg.dst) > 0 {
+			dst := append([]rune(nil), rg.dst...)
+			dst[len(dst)-1] += rune(code - rg.lo)
+			return string(dst), true
+		}
+	}
+	return "", false
+}
+
+// readState is the part of the graphics state that text extraction follows.
+type readState struct {
+	ctm                      readMatrix
+	font                     *readFont
+	size, tc, tw, th, tl, rs float64
+}
+
+// TextRuns returns the text runs of page n, 1-based, in the order they are
+// shown, those of form XObjects such as templates included.
+func (r *PDFReader) TextRuns(n int) ([]TextRun, error) {
+	if n < 1 || n > len(r.pages) {
+		return nil, Errf("TextRuns: page %d does not exist", n)
+	}
+	page := r.pages[n-1]
+	var content []byte
+	contents := r.resolve(page["Contents"])
+	if arr, ok := contents.([]any); ok {
+		for _, c := range arr {
+			s, ok := r.resolve(c).(*pdfStream)
+			if !ok {
+				continue
+			}
+			data, err := r.decode(s)
+			if err != nil {
+				return nil, err
+			}
+			content = append(append(content, data...), '\n')
+		}
+	} else if s, ok := contents.(*pdfStream); ok {
+		data, err := r.decode(s)
+		if err != nil {
+			return nil, err
+		}
+		content = data
+	}
+	var runs []TextRun
+	st := readState{ctm: readIdentity, th: 1}
+	err := r.runContent(content, r.dict(page["Resources"]), st, &runs, 0)
+	return runs, err
+}
+
+// PageText returns the text of page n, 1-based, with its runs sorted in
+// lines from the top of the page and from left to right within a line.
+// Runs of a line are separated by a space where there is a gap between them.
+func (r *PDFReader) PageText(n int) (string, error) {
+	runs, err := r.TextRuns(n)
+	if err != nil {
+		return "", err
+	}
+	sort.SliceStable(runs, func(a, b int) bool {
+		if math.Abs(runs[a].Y-runs[b].Y) > math.Max(runs[a].Size, runs[b].Size)/2 {
+			return runs[a].Y > runs[b].Y
+		}
+		return runs[a].X < runs[b].X
+	})
+	var buf []byte
+	for j, run := range runs {
+		if j > 0 {
+			prev := runs[j-1]
+			if math.Abs(run.Y-prev.Y) > math.Max(run.Size, prev.Size)/2 {
+				buf = append(buf, '\n')
+			} else if run.X-(prev.X+prev.Width) > run.Size/5 {
+				buf = append(buf, ' ')
+			}
+		}
+		buf = append(buf, run.Text...)
+	}
+	return string(buf), nil
+}
+
+// runContent interprets the content stream data with resources res, and
+// appends the text runs it shows to runs.
+func (r *PDFReader) runContent(data []byte, res map[string]any, st readState, runs *[]TextRun, depth int) error {
+	if depth > 16 {
+		return Err("TextRuns: form XObjects nested too deeply")
+	}
+	lex := pdfLexer{data: data}
+	var stack []readState
+	var args []any
+	tm, tlm := readIdentity, readIdentity
+	num := func(j int) float64 {
+		if j < len(args) {
+			v, _ := args[j].(float64)
+			return v
+		}
+		return 0
+	}
+	matrix := func() readMatrix {
+		return readMatrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+	}
+	moveLine := func(tx, ty float64) {
+		tlm = readMatrix{1, 0, 0, 1, tx, ty}.mul(tlm)
+		tm = tlm
+	}
+	for {
+		lex.skipSpace()
+		if lex.pos >= len(data) {
+			return nil
+		}
+		v, err := lex.object()
+		if err != nil {
+			return err
+		}
+		op, ok := v.(pdfOp)
+		if !ok {
+			args = append(args, v)
+			continue
+		}
+		switch op {
+		case "q":
+			stack = append(stack, st)
+		case "Q":
+			if len(stack) > 0 {
+				st, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case "cm":
+			st.ctm = matrix().mul(st.ctm)
+		case "BT":
+			tm, tlm = readIdentity, readIdentity
+		case "Tf":
+			if len(args) != 2 {
+				break
+			}
+			if name, ok := args[0].(pdfName); ok {
+				st.font = r.font(r.dict(res["Font"])[string(name)])
+				st.size = num(1)
+			}
+		case "Tc":
+			st.tc = num(0)
+		case "Tw":
+			st.tw = num(0)
+		case "Tz":
+			st.th = num(0) / 100
+		case "TL":
+			st.tl = num(0)
+		case "Ts":
+			st.rs = num(0)
+		case "Td":
+			moveLine(num(0), num(1))
+		case "TD":
+			st.tl = -num(1)
+			moveLine(num(0), num(1))
+		case "Tm":
+			tm, tlm = matrix(), matrix()
+		case "T*":
+			moveLine(0, -st.tl)
+		case "Tj", "'", "\"", "TJ":
+			if op == "\"" && len(args) == 3 {
+				st.tw, st.tc = num(0), num(1)
+				args = args[2:]
+			}
+			if op != "Tj" && op != "TJ" {
+				moveLine(0, -st.tl)
+			}
+			var parts []any
+			if op == "TJ" && len(args) > 0 {
+				parts, _ = args[0].([]any)
+			} else if len(args) > 0 {
+				parts = args[len(args)-1:]
+			}
+			*runs = append(*runs, st.show(parts, &tm))
+		case "Do":
+			if len(args) != 1 {
+				break
+			}
+			if name, ok := args[0].(pdfName); ok {
+				xobj, _ := r.resolve(r.dict(res["XObject"])[string(name)]).(*pdfStream)
+				if xobj != nil && xobj.dict["Subtype"] == pdfName("Form") {
+					form, err := r.decode(xobj)
+					if err != nil {
+						return err
+					}
+					formRes := r.dict(xobj.dict["Resources"])
+					if formRes == nil {
+						formRes = res
+					}
+					formSt := st
+					if m, ok := r.resolve(xobj.dict["Matrix"]).([]any); ok && len(m) == 6 {
+						var fm readMatrix
+						for j := range fm {
+							fm[j] = r.number(m[j])
+						}
+						formSt.ctm = fm.mul(st.ctm)
+					}
+					if err = r.runContent(form, formRes, formSt, runs, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+		case "BI":
+			lex.skipInlineImage()
+		}
+		args = args[:0]
+	}
+}
+
+// show returns the run of the strings and adjustments of parts, as the
+// operands of TJ, and moves the text matrix tm past it.
+func (st *readState) show(parts []any, tm *readMatrix) TextRun {
+	trm := tm.mul(st.ctm)
+	x0, y0 := trm.apply(0, st.rs)
+	run := TextRun{X: x0, Y: y0, Size: st.size * math.Hypot(trm[2], trm[3])}
+	if st.font != nil {
+		run.Font = st.font.name
+	}
+	var text []byte
+	tx := 0.0
+	for _, part := range parts {
+		switch v := part.(type) {
+		case float64:
+			tx -= v / 1000 * st.size * st.th
+		case string:
+			step := 1
+			if st.font != nil && st.font.twoByte {
+				step = 2
+			}
+			for j := 0; j+step <= len(v); j += step {
+				code := int(v[j])
+				if step == 2 {
+					code = code<<8 | int(v[j+1])
+				}
+				w := 0.0
+				if st.font != nil {
+					text = append(text, st.font.text(code)...)
+					w = st.font.width(code)
+				} else {
+					text = append(text, string(rune(code))...)
+				}
+				adv := w/1000*st.size + st.tc
+				if step == 1 && code == ' ' {
+					adv += st.tw
+				}
+				tx += adv * st.th
+			}
+		}
+	}
+	run.Text = string(text)
+	*tm = readMatrix{1, 0, 0, 1, tx, 0}.mul(*tm)
+	x1, _ := tm.mul(st.ctm).apply(0, st.rs)
+	run.Width = x1 - x0
+	return run
+}
+
+// text returns the text of code.
+func (font *readFont) text(code int) string {
+	if font.unicode != nil {
+		if s, ok := font.unicode.text(code); ok {
+			return s
+		}
+	}
+	if font.enc != nil && code < 256 {
+		return string(font.enc[code])
+	}
+	return string(rune(code))
+}
+
+// width returns the width of code in thousandths of an em.
+func (font *readFont) width(code int) float64 {
+	if w, ok := font.widths[code]; ok {
+		return w
+	}
+	return font.dw
+}
+
+// font returns the font of the font dictionary v, loading it the first time.
+func (r *PDFReader) font(v any) *readFont {
+	ref, isRef := v.(pdfRef)
+	if isRef {
+		if font, ok := r.fonts[ref]; ok {
+			return font
+		}
+	}
+	dict := r.dict(v)
+	if dict == nil {
+		return nil
+	}
+	base, _ := r.resolve(dict["BaseFont"]).(pdfName)
+	font := &readFont{name: string(base), widths: make(map[int]float64)}
+	if s, ok := r.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := r.decode(s); err == nil {
+			font.unicode = parseToUnicode(data)
+		}
+	}
+	if dict["Subtype"] == pdfName("Type0") {
+		font.twoByte = true
+		desc, _ := r.resolve(dict["DescendantFonts"]).([]any)
+		if len(desc) > 0 {
+			r.cidWidths(font, r.dict(desc[0]))
+		}
+	} else {
+		font.enc = r.simpleEncoding(r.resolve(dict["Encoding"]))
+		first := int(r.number(dict["FirstChar"]))
+		widths, _ := r.resolve(dict["Widths"]).([]any)
+		for j, w := range widths {
+			font.widths[first+j] = r.number(w)
+		}
+		if len(widths) == 0 {
+			coreWidths(font)
+		}
+	}
+	if isRef {
+		r.fonts[ref] = font
+	}
+	return font
+}
+
+// cidWidths reads the widths of the CIDFont dictionary cid into font.
+func (r *PDFReader) cidWidths(font *readFont, cid map[string]any) {
+	font.dw = 1000
+	if dw, ok := r.resolve(cid["DW"]).(float64); ok {
+		font.dw = dw
+	}
+	w, _ := r.resolve(cid["W"]).([]any)
+	for j := 0; j+1 < len(w); {
+		first := int(r.number(w[j]))
+		if arr, ok := r.resolve(w[j+1]).([]any); ok {
+			for k, v := range arr {
+				font.widths[first+k] = r.number(v)
+			}
+			j += 2
+			continue
+		}
+		if j+2 >= len(w) {
+			break
+		}
+		last, width := int(r.number(w[j+1])), r.number(w[j+2])
+		for c := first; c <= last && c-first < 0x10000; c++ {
+			font.widths[c] = width
+		}
+		j += 3
+	}
+}
+
+// coreFontFiles maps the base names of the standard fonts to the
+// definitions embedded for them.
+var coreFontFiles = map[string]string{
+	"Helvetica": "helvetica", "Helvetica-Bold": "helveticab",
+	"Helvetica-Oblique": "helveticai", "Helvetica-BoldOblique": "helveticabi",
+	"Times-Roman": "times", "Times-Bold": "timesb",
+	"Times-Italic": "timesi", "Times-BoldItalic": "timesbi",
+	"Courier": "courier", "Courier-Bold": "courierb",
+	"Courier-Oblique": "courieri", "Courier-BoldOblique": "courierbi",
+	"ZapfDingbats": "zapfdingbats",
+}
+
+// coreWidths sets the widths of font, a standard font written without its
+// widths, from the definitions embedded in the package.
+func coreWidths(font *readFont) {
+	key, ok := coreFontFiles[font.name]
+	if !ok {
+		font.dw = 500
+		return
+	}
+	data, err := embFS.ReadFile("font_embed/" + key + ".json")
+	var def fontDefType
+	if err == nil {
+		err = unmarshalFontDef(data, &def)
+	}
+	if err != nil {
+		font.dw = 500
+		return
+	}
+	for code, w := range def.Cw {
+		font.widths[code] = float64(w)
+	}
+}
+
+// simpleEncoding returns the encoding of a simple font from its /Encoding
+// entry enc: WinAnsiEncoding, the cp1252 of Fpdf, with the /Differences of
+// an encoding dictionary applied.
+func (r *PDFReader) simpleEncoding(enc any) *[256]rune {
+	var table [256]rune
+	list, err := embeddedMap("cp1252")
+	for j := range table {
+		table[j] = rune(j)
+		if err == nil && list[j].uv >= 0 {
+			table[j] = rune(list[j].uv)
+		}
+	}
+	dict, _ := enc.(map[string]any)
+	diffs, _ := r.resolve(dict["Differences"]).([]any)
+	if len(diffs) == 0 {
+		return &table
+	}
+	names := glyphNames()
+	code := 0
+	for _, d := range diffs {
+		switch v := r.resolve(d).(type) {
+		case float64:
+			code = int(v)
+		case pdfName:
+			if code >= 0 && code < 256 {
+				if uv, ok := names[string(v)]; ok {
+					table[code] = uv
+				} else if len(v) == 7 && HasPrefix(string(v), "uni") {
+					if uv, err := Convert(string(v[3:])).Int(16); err == nil {
+						table[code] = rune(uv)
+					}
+				}
+			}
+			code++
+		}
+	}
+	return &table
+}
+
+// embeddedMap returns the encoding map embedded in the package for cpStr,
+// such as "cp1252".
+func embeddedMap(cpStr string) (encListType, error) {
+	rdr, err := embFS.Open("font_embed/" + cpStr + ".map")
+	if err != nil {
+		return encListType{}, err
+	}
+	defer rdr.Close()
+	return readMap(rdr)
+}
+
+// glyphNames returns the characters of the glyph names of the encoding maps
+// embedded in the package.
+func glyphNames() map[string]rune {
+	names := make(map[string]rune)
+	entries, _ := embFS.ReadDir("font_embed")
+	for _, entry := range entries {
+		name := entry.Name()
+		if !HasSuffix(name, ".map") {
+			continue
+		}
+		list, err := embeddedMap(name[:len(name)-4])
+		if err != nil {
+			continue
+		}
+		for _, enc := range list {
+			if enc.uv >= 0 {
+				names[enc.name] = rune(enc.uv)
+			}
+		}
+	}
+	return names
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a ToUnicode CMap.
+func parseToUnicode(data []byte) *readCMap {
+	cm := &readCMap{chars: make(map[int]string)}
+	lex := pdfLexer{data: data}
+	code := func(v any) int {
+		s, _ := v.(string)
+		c := 0
+		for j := 0; j < len(s); j++ {
+			c = c<<8 | int(s[j])
+		}
+		return c
+	}
+	dst := func(v any) string {
+		s, _ := v.(string)
+		return utf16beToString([]byte(s))
+	}
+	var args []any
+	for {
+		lex.skipSpace()
+		if lex.pos >= len(data) {
+			return cm
+		}
+		v, err := lex.object()
+		if err != nil {
+			return cm
+		}
+		op, ok := v.(pdfOp)
+		if !ok {
+			args = append(args, v)
+			continue
+		}
+		switch op {
+		case "endbfchar":
+			for j := 0; j+1 < len(args); j += 2 {
+				cm.chars[code(args[j])] = dst(args[j+1])
+			}
+		case "endbfrange":
+			for j := 0; j+2 < len(args); j += 3 {
+				lo, hi := code(args[j]), code(args[j+1])
+				if arr, ok := args[j+2].([]any); ok {
+					for k, d := range arr {
+						cm.chars[lo+k] = dst(d)
+					}
+				} else {
+					cm.ranges = append(cm.ranges, readCMapRange{lo: lo, hi: hi, dst: []rune(dst(args[j+2]))})
+				}
+			}
+		}
+		args = args[:0]
+	}
+}
@@ -0,0 +1,96 @@
+package fpdf
+
+// Paper of thermal receipt printers: pages 58 mm or 80 mm wide that grow
+// with their content, in points as AddPageFormat() takes them.
+var (
+	// Receipt58 is the 58 mm paper of mobile and small POS printers
+	Receipt58 = PageSize{Wd: 164.41, Ht: 841.89, AutoHt: true}
+	// Receipt80 is the 80 mm paper of most POS printers
+	Receipt80 = PageSize{Wd: 226.77, Ht: 841.89, AutoHt: true}
+)
+
+// ReceiptColumns returns the number of characters of the current font that
+// fit between the margins, the width of a line of a receipt printed with a
+// monospaced font such as Courier.
+func (f *Fpdf) ReceiptColumns() int {
+	charW := f.GetStringWidth("0")
+	if charW <= 0 {
+		return 0
+	}
+	return int((f.w - f.lMargin - f.rMargin) / charW)
+}
+
+// ReceiptLine prints left at the left margin and right flush with the right
+// margin on a line of its own, with the gap between them filled with the
+// repeated fill string, as in "Coffee ...... 3.50"; an empty fill leaves the
+// gap blank. A left text too long to share the line with right is wrapped,
+// and right is printed on the last line if it fits, on a line of its own
+// otherwise.
+func (f *Fpdf) ReceiptLine(left, right, fill string) {
+	if f.err != nil {
+		return
+	}
+	lineHt := f.fontSize * 1.2
+	w := f.w - f.lMargin - f.rMargin
+	rightW := f.GetStringWidth(right)
+	spaceW := f.GetStringWidth(" ")
+	textW := w - 2*f.cMargin
+	lines := f.SplitText(left, textW)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	for _, line := range lines[:len(lines)-1] {
+		f.SetX(f.lMargin)
+		f.CellFormat(w, lineHt, line, "", 1, "L", false, 0, "")
+	}
+	last := lines[len(lines)-1]
+	lastW := f.GetStringWidth(last)
+	if last != "" && right != "" && lastW+spaceW+rightW > textW {
+		f.SetX(f.lMargin)
+		f.CellFormat(w, lineHt, last, "", 1, "L", false, 0, "")
+		last, lastW = "", 0
+	}
+	// The fill keeps a space away from the texts
+	gap := textW - lastW - rightW
+	if last != "" {
+		last += " "
+		gap -= spaceW
+	}
+	if right != "" {
+		gap -= spaceW
+	}
+	if fillW := f.GetStringWidth(fill); fillW > 0 {
+		dots := []byte(last)
+		for range int(gap / fillW) {
+			dots = append(dots, fill...)
+		}
+		last = string(dots)
+	}
+	f.SetX(f.lMargin)
+	f.CellFormat(w, lineHt, last, "", 0, "L", false, 0, "")
+	f.SetX(f.lMargin)
+	f.CellFormat(w, lineHt, right, "", 1, "R", false, 0, "")
+}
+
+// ReceiptRule prints a line of the repeated char string across the margins,
+// such as "-" or "=", to separate the parts of a receipt.
+func (f *Fpdf) ReceiptRule(char string) {
+	f.ReceiptLine("", "", char)
+}
+
+// ReceiptCut marks where the paper is to be cut: a dashed line across the
+// whole width of the paper, with space above and below. The current position
+// moves below it, so that the next receipt can follow on the same roll.
+func (f *Fpdf) ReceiptCut() {
+	if f.err != nil {
+		return
+	}
+	gap := f.fontSize
+	y := f.y + gap
+	dashArray, dashPhase := f.dashArray, f.dashPhase
+	f.SetDashPattern([]float64{3 / f.k, 2 / f.k}, 0)
+	f.Line(0, y, f.w, y)
+	f.dashArray, f.dashPhase = dashArray, dashPhase
+	f.outputDashPattern()
+	f.SetY(y + gap)
+}
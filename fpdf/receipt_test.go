@@ -0,0 +1,64 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestReceipt(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetMargins(3, 3, 3)
+	pdf.SetAutoPageBreak(true, 3)
+	pdf.AddPageFormat(fpdf.Portrait, fpdf.Receipt80)
+	pdf.SetFont("Courier", "", 9)
+	// 74 mm between the margins, Courier characters are 0.6 em wide
+	if got := pdf.ReceiptColumns(); got != 38 {
+		t.Errorf("got %d columns, want 38", got)
+	}
+	pdf.ReceiptLine("Coffee", "3.50", ".")
+	pdf.ReceiptRule("-")
+	pdf.ReceiptLine("A very long product name that does not fit on one line", "12.00", ".")
+	pdf.ReceiptCut()
+	y := pdf.GetY()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	texts := regexp.MustCompile(`\((.*)\)Tj`).FindAllStringSubmatch(out, -1)
+	var lines []string
+	for _, m := range texts {
+		lines = append(lines, m[1])
+	}
+	if len(lines) < 5 {
+		t.Fatalf("got texts %q", lines)
+	}
+	// The fill leaves a space on each side and room for the cell margins
+	if !strings.HasPrefix(lines[0], "Coffee ....") || !strings.HasSuffix(lines[0], ".") || lines[1] != "3.50" {
+		t.Errorf("got line %q %q", lines[0], lines[1])
+	}
+	if n := len(lines[0]) + 1 + len(lines[1]); n > 38 || n < 36 {
+		t.Errorf("got a line of %d characters, want up to 38", n)
+	}
+	if strings.Trim(lines[2], "-") != "" || len(lines[2]) < 36 {
+		t.Errorf("got rule %q", lines[2])
+	}
+	// The long name is wrapped, the amount on its last line
+	if !strings.HasPrefix(lines[3], "A very long product name") || lines[len(lines)-1] != "12.00" {
+		t.Errorf("got wrapped lines %q", lines[3:])
+	}
+	if !strings.Contains(out, "[3.00 2.00] 0.00 d") || !strings.Contains(out, "[] 0.00 d") {
+		t.Error("cut mark not dashed, or dash pattern not restored")
+	}
+	// The page ends below the cut mark
+	if _, ht, _ := pdf.PageSize(1); math.Abs(ht-(y+3)) > 0.01 {
+		t.Errorf("got a page %.2f mm high, want %.2f mm", ht, y+3)
+	}
+}
@@ -0,0 +1,105 @@
+package fpdf
+
+// textObjType is a text object written to the content of a page: the bytes
+// it spans and the box of the text it prints, in the unit of measure
+// specified in New().
+type textObjType struct {
+	start, end int
+	box        extentType
+	redacted   bool
+}
+
+// Redact removes the text that overlaps the rectangle of width w and height h
+// whose upper left corner is at point (x, y) on the current page, and paints
+// the rectangle black. Unlike a black rectangle drawn over the text, which
+// leaves it in the page for text extraction and copying, redaction deletes
+// the text operators from the content of the page.
+//
+// The text printed by Text(), CellFormat(), MultiCell(), Write() and the
+// methods based on them is removed whole, even if it only partly overlaps
+// the rectangle: a cell, a line of MultiCell() or Write(), or the string of
+// Text(). Text printed later on the current page that overlaps the rectangle
+// is removed as well. Images, drawings and text brought in by templates or
+// ClonePage() are not affected.
+//
+// The position and size are in the unit of measure specified in New(). A
+// page must already have been added.
+func (f *Fpdf) Redact(x, y, w, h float64) {
+	if f.err != nil {
+		return
+	}
+	if w <= 0 || h <= 0 {
+		f.errorf("Redact", "invalid redaction size: %.2f x %.2f", w, h)
+		return
+	}
+	if f.page == 0 {
+		f.errorf("Redact", "redaction requires a page; call AddPage first")
+		return
+	}
+	if f.redactions == nil {
+		f.redactions = make(map[int][]extentType)
+	}
+	f.redactions[f.page] = append(f.redactions[f.page], extentType{x, y, x + w, y + h})
+	f.redactText(f.page)
+	f.extend(x, y, x+w, y+h)
+	f.put("q 0 g ")
+	f.putF64s(f.prec.Path, x*f.k, (f.h-y)*f.k, w*f.k, -h*f.k)
+	f.put("re f Q\n")
+}
+
+// outText writes the text object obj, printing txtStr from its baseline at
+// (x, y) with the current font, between pre and post, and records it so that
+// it can be redacted.
+func (f *Fpdf) outText(pre, obj, post string, x, y float64, txtStr string) {
+	f.writeText([]byte(pre+obj+post), len(pre), len(pre)+len(obj), x, y, txtStr)
+}
+
+// writeText adds the line b to the document, as outText() does, for the text
+// object spanning the bytes start to end of b.
+func (f *Fpdf) writeText(b []byte, start, end int, x, y float64, txtStr string) {
+	if f.state != 2 {
+		f.out(string(b))
+		return
+	}
+	offset := f.pages[f.page].Len()
+	must(f.pages[f.page].Write(b))
+	must(f.pages[f.page].WriteString("\n"))
+	if start == end {
+		return
+	}
+	if f.textObjs == nil {
+		f.textObjs = make(map[int][]textObjType)
+	}
+	box := extentType{x, y - .8*f.fontSize, x + f.GetStringWidth(txtStr), y + .2*f.fontSize}
+	f.textObjs[f.page] = append(f.textObjs[f.page], textObjType{start: offset + start, end: offset + end, box: box})
+	if len(f.redactions[f.page]) > 0 {
+		f.redactText(f.page)
+	}
+}
+
+// redactText blanks out the text objects of page n that overlap one of its
+// redactions. The bytes of each one are replaced with spaces, so the offsets
+// of the other content of the page are kept.
+func (f *Fpdf) redactText(n int) {
+	content := f.pages[n].Bytes()
+	objs := f.textObjs[n]
+	for j := range objs {
+		if objs[j].redacted {
+			continue
+		}
+		for _, r := range f.redactions[n] {
+			if objs[j].box.overlaps(r) {
+				for k := objs[j].start; k < objs[j].end; k++ {
+					content[k] = ' '
+				}
+				objs[j].redacted = true
+				break
+			}
+		}
+	}
+}
+
+// overlaps returns whether the boxes e and r share some area.
+func (e extentType) overlaps(r extentType) bool {
+	return e.minX < r.maxX && r.minX < e.maxX && e.minY < r.maxY && r.minY < e.maxY
+}
@@ -0,0 +1,74 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestRedact(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetXY(10, 10)
+	pdf.Cell(40, 10, "Public")
+	pdf.SetXY(10, 30)
+	pdf.SetTextColor(200, 0, 0)
+	pdf.SetFont("Helvetica", "U", 12)
+	pdf.Cell(40, 10, "Secret")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.MultiCell(60, 6, "Confidential salary\nPublic line", "", "L", false)
+	pdf.Redact(10, 30, 60, 6)
+	pdf.Text(20, 34, "Late")
+	pdf.Text(20, 80, "Kept")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"(Secret)", "(Confidential salary)", "(Late)"} {
+		if strings.Contains(out, s) {
+			t.Errorf("redacted text %s left in the page", s)
+		}
+	}
+	for _, s := range []string{"(Public)", "(Public line)", "(Kept)", "0 g 28.35 756.85 170.08 -17.01 re f Q"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%s missing from the page", s)
+		}
+	}
+}
+
+func TestRedactRollback(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	c := pdf.SaveCursor()
+	pdf.Redact(0, 0, 200, 200)
+	pdf.RollbackCursor(c)
+	pdf.Text(20, 20, "Visible")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "(Visible)") {
+		t.Errorf("text redacted by a rolled back redaction")
+	}
+}
+
+func TestRedactErrors(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.Redact(10, 10, 20, 20)
+	if pdf.Error() == nil {
+		t.Errorf("expected error without a page")
+	}
+	pdf = NewDocPdfTest(fpdf.MM)
+	pdf.AddPage()
+	pdf.Redact(10, 10, 0, 20)
+	if pdf.Error() == nil {
+		t.Errorf("expected error for an empty region")
+	}
+}
@@ -0,0 +1,94 @@
+package fpdf
+
+import (
+	"io/fs"
+	"path"
+)
+
+// SetResourceFS sets the file system from which the document reads the files
+// it uses: fonts and font definition files, images, ICC profiles and
+// attachments. It can be an embed.FS compiled into the executable, which
+// suits WebAssembly and other deployments without a file system, an
+// os.DirFS or any other fs.FS. The file system can also be given to New()
+// as an option.
+//
+// It replaces the functions given to New() with ReadFileFunc and
+// FileSizeFunc; the one given with WriteFileFunc is still used to write the
+// document with OutputFileAndClose(). Paths are resolved from the root of
+// fsys, and leading "./" and "/" are ignored, so that the paths built from
+// the root directory and the font directory given to New() work unchanged.
+// A nil fsys removes the file system and restores the default functions,
+// which read files with BrowserFetch() in the browser and fail elsewhere.
+//
+// See SetFontFS() to load fonts from a file system when they are first used.
+func (f *Fpdf) SetResourceFS(fsys fs.FS) {
+	f.resourceFS = fsys
+	if fsys == nil {
+		writeFile := f.writeFile
+		f.initIO()
+		f.writeFile = writeFile
+		return
+	}
+	f.readFile = func(filePath string) ([]byte, error) {
+		return fs.ReadFile(fsys, fsPath(filePath))
+	}
+	f.fileSize = func(filePath string) (int64, error) {
+		info, err := fs.Stat(fsys, fsPath(filePath))
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+}
+
+// GetResourceFS returns the file system set with SetResourceFS(), or nil.
+func (f *Fpdf) GetResourceFS() fs.FS {
+	return f.resourceFS
+}
+
+// fsPath returns filePath as a path that is valid in an fs.FS.
+func fsPath(filePath string) string {
+	p := path.Clean(filePath)
+	for len(p) > 1 && p[0] == '/' {
+		p = p[1:]
+	}
+	return p
+}
+
+// ReadResource returns the content of the file fileStr, read from the file
+// system set with SetResourceFS() or with the function given to New() with
+// ReadFileFunc.
+func (f *Fpdf) ReadResource(fileStr string) ([]byte, error) {
+	return f.readFile(fileStr)
+}
+
+// AttachmentFile returns an attachment, for SetAttachments() or
+// AddAttachmentAnnotation(), with the content of the file fileStr, read as
+// with ReadResource(). The attachment is named after the file. If the file
+// cannot be read, the error is set on the document.
+func (f *Fpdf) AttachmentFile(fileStr, description string) (a Attachment) {
+	if f.err != nil {
+		return
+	}
+	data, err := f.readFile(fileStr)
+	if err != nil {
+		f.errorf("AttachmentFile", "could not read %s: %v", fileStr, err)
+		return
+	}
+	return Attachment{Content: data, Filename: path.Base(fileStr), Description: description}
+}
+
+// AddOutputIntentFile adds an output intent like AddOutputIntent(), with
+// its ICC color profile read from the file fileStr as with ReadResource().
+func (f *Fpdf) AddOutputIntentFile(outputIntent OutputIntentType, fileStr string) {
+	if f.err != nil {
+		return
+	}
+	data, err := f.readFile(fileStr)
+	if err != nil {
+		f.errorf("AddOutputIntentFile", "could not read %s: %v", fileStr, err)
+		return
+	}
+	outputIntent.ICCProfile = data
+	f.AddOutputIntent(outputIntent)
+}
@@ -0,0 +1,61 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetResourceFS(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, file := range map[string]string{
+		"fonts/DejaVuSansCondensed.ttf": FontFile("DejaVuSansCondensed.ttf"),
+		"image/logo.png":                ImageFile("logo.png"),
+		"icc/sRGB2014.icc":              rootTestDir.MakePath("icc", "sRGB2014.icc"),
+	} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+
+	// Paths relative to the root directory "." resolve in the file system,
+	// without any function to read files.
+	pdf := fpdf.New(fpdf.POINT, fsys)
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.AddPage()
+	pdf.SetFont("dejavu", "", 12)
+	pdf.Cell(0, 20, "Ψ from an fs.FS")
+	pdf.Image("./image/logo.png", 10, 40, 50, 0, false, "", 0, "")
+	pdf.SetAttachments([]fpdf.Attachment{pdf.AttachmentFile("/icc/sRGB2014.icc", "profile")})
+	pdf.AddOutputIntentFile(fpdf.OutputIntentType{
+		SubtypeIdent:              fpdf.OutputIntent_GTS_PDFA1,
+		OutputConditionIdentifier: "sRGB",
+	}, "icc/sRGB2014.icc")
+	if pdf.GetResourceFS() == nil {
+		t.Errorf("expected the file system to be set")
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"/Subtype /Image", "/OutputIntents", "/EmbeddedFile"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+
+	pdf = fpdf.New(fsys)
+	pdf.AddPage()
+	pdf.Image("image/missing.png", 10, 10, 10, 0, false, "", 0, "")
+	if !pdf.Err() {
+		t.Errorf("expected error for a missing image")
+	}
+}
@@ -0,0 +1,54 @@
+package fpdf
+
+// mirrored returns whether cells are laid out from right to left, which is the
+// case in right-to-left mode except within the left-to-right layout that
+// mirror() wraps.
+func (f *Fpdf) mirrored() bool {
+	return f.isRTL && f.mirrorDepth == 0
+}
+
+// lineStart returns the abscissa where lines start: the left margin, or the
+// right margin in right-to-left mode.
+func (f *Fpdf) lineStart() float64 {
+	if f.mirrored() {
+		return f.w - f.rMargin
+	}
+	return f.lMargin
+}
+
+// mirror lays out with fn a box of width w that ends at the current abscissa
+// instead of starting there, as right-to-left mode requires. fn lays out the
+// box from left to right starting at f.x, with the width it is given; a w of
+// 0 extends the box to the left margin. Afterwards the current position is
+// moved as ln requires in right-to-left mode: to the left of the box (0), to
+// the start of the next line (1) or below the box (2).
+func (f *Fpdf) mirror(w float64, ln int, fn func(w float64)) {
+	if w == 0 {
+		w = f.x - f.lMargin
+	}
+	right := f.x
+	f.x = right - w
+	f.mirrorDepth++
+	fn(w)
+	f.mirrorDepth--
+	switch ln {
+	case 0:
+		f.x = right - w
+	case 1:
+		f.x = f.w - f.rMargin
+	default:
+		f.x = right
+	}
+}
+
+// mirrorAlign returns alignStr with right alignment added if it has no
+// horizontal alignment, since text starts at the right in right-to-left mode.
+func mirrorAlign(alignStr string) string {
+	for _, c := range alignStr {
+		switch c {
+		case 'L', 'C', 'R', 'J':
+			return alignStr
+		}
+	}
+	return "R" + alignStr
+}
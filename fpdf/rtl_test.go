@@ -0,0 +1,60 @@
+package fpdf_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestRTLMirroredLayout(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetMargins(20, 20, 40)
+	pdf.RTL()
+	pdf.AddPage()
+	pageW, _ := pdf.GetPageSize()
+	right := pageW - 40
+	near := func(a, b float64) bool { return math.Abs(a-b) < 0.01 }
+	if x := pdf.GetX(); !near(x, right) {
+		t.Fatalf("line does not start at the right margin: x=%.2f", x)
+	}
+
+	// The columns of a row follow each other from right to left.
+	pdf.CellFormat(100, 20, "first", "1", 0, "", false, 0, "")
+	if x := pdf.GetX(); !near(x, right-100) {
+		t.Errorf("unexpected position after a cell: x=%.2f", x)
+	}
+	pdf.CellFormat(50, 20, "second", "1", 2, "", false, 0, "")
+	if x, y := pdf.GetXY(); !near(x, right-100) || !near(y, 40) {
+		t.Errorf("unexpected position below a cell: x=%.2f y=%.2f", x, y)
+	}
+	pdf.Ln(-1)
+	if x := pdf.GetX(); !near(x, right) {
+		t.Errorf("Ln does not return to the right margin: x=%.2f", x)
+	}
+
+	// A cell of width 0 extends to the left margin.
+	pdf.CellFormat(0, 20, "full", "", 1, "", false, 0, "")
+	if x := pdf.GetX(); !near(x, right) {
+		t.Errorf("unexpected position after a full cell: x=%.2f", x)
+	}
+	y := pdf.GetY()
+	pdf.SetX(right - 50)
+	pdf.MultiCell(200, 20, "a paragraph long enough to wrap on a few lines of the cell", "", "", false)
+	if x := pdf.GetX(); !near(x, right) || pdf.GetY() <= y+20 {
+		t.Errorf("unexpected position after MultiCell: x=%.2f y=%.2f", x, pdf.GetY())
+	}
+
+	pdf.LTR()
+	if x := pdf.GetX(); !near(x, 20) {
+		t.Errorf("LTR does not return to the left margin: x=%.2f", x)
+	}
+	pdf.CellFormat(100, 20, "ltr", "", 0, "", false, 0, "")
+	if x := pdf.GetX(); !near(x, 120) {
+		t.Errorf("unexpected position in left-to-right mode: x=%.2f", x)
+	}
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+}
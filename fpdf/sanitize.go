@@ -0,0 +1,15 @@
+package fpdf
+
+// SetSanitize sets whether the document is written without the features that
+// can run code or reach outside of it when it is opened: the JavaScript set
+// with SetJavascript(), the links to URLs and to external files placed with
+// LinkString(), LinkFile() and similar methods, and the files embedded with
+// SetAttachments() and AddAttachmentAnnotation(). Links to destinations in
+// the document, bookmarks and the visible content of the pages are kept.
+//
+// The features are left out when the document is written, so the same code
+// can produce both the full document and a safe variant for untrusted
+// distribution. Sanitizing is off by default.
+func (f *Fpdf) SetSanitize(sanitize bool) {
+	f.sanitize = sanitize
+}
@@ -0,0 +1,43 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetSanitize(t *testing.T) {
+	for _, sanitize := range []bool{false, true} {
+		pdf := NewDocPdfTest()
+		pdf.SetCompression(false)
+		pdf.SetSanitize(sanitize)
+		pdf.SetJavascript("app.alert('hello');")
+		pdf.SetAttachments([]fpdf.Attachment{{Content: []byte("report"), Filename: "report.txt"}})
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "", 12)
+		internal := pdf.AddLink()
+		pdf.SetLink(internal, 0, 1)
+		pdf.Link(10, 10, 30, 10, internal)
+		pdf.LinkString(10, 30, 30, 10, "https://example.com/")
+		pdf.LinkFile(10, 50, 30, 10, "other.pdf", 1, fpdf.LinkOptions{})
+		pdf.AddAttachmentAnnotation(&fpdf.Attachment{Content: []byte("note"), Filename: "note.txt"}, 10, 70, 10, 10)
+		pdf.Cell(40, 10, "Visible")
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		for _, s := range []string{"/JavaScript", "/S /URI", "/S /GoToR", "/EmbeddedFile ", "/FileAttachment"} {
+			if got := strings.Contains(out, s); got == sanitize {
+				t.Errorf("sanitize %v: %s present %v", sanitize, s, got)
+			}
+		}
+		for _, s := range []string{"/Dest [", "(Visible)"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("sanitize %v: %s missing", sanitize, s)
+			}
+		}
+	}
+}
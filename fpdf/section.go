@@ -0,0 +1,170 @@
+package fpdf
+
+import . "github.com/tinywasm/fmt"
+
+// sectionType records the start of a section begun by BeginSection().
+type sectionType struct {
+	name  string
+	first int  // page where the section begins
+	top   bool // the section begins at the top of its first page
+}
+
+// SectionRange is the range of pages spanned by a section of a document.
+type SectionRange struct {
+	Name        string
+	First, Last int // first and last page of the section
+}
+
+// Pages returns the number of pages of the section.
+func (s SectionRange) Pages() int {
+	return s.Last - s.First + 1
+}
+
+// Pagination describes how the content of a document is paginated. It is
+// returned by GetPagination() and computed by the first pass of TwoPass().
+type Pagination struct {
+	Pages    int            // number of pages of the document
+	Sections []SectionRange // sections begun with BeginSection(), in order
+}
+
+// Section returns the range of pages of the section named name, and whether
+// it exists. If several sections have that name, the first one is returned.
+func (p Pagination) Section(name string) (s SectionRange, ok bool) {
+	for _, s = range p.Sections {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return SectionRange{}, false
+}
+
+// SectionOf returns the range of pages of the section that page n belongs
+// to, and whether there is one.
+func (p Pagination) SectionOf(n int) (s SectionRange, ok bool) {
+	for j := len(p.Sections) - 1; j >= 0; j-- {
+		if s = p.Sections[j]; s.First <= n && n <= s.Last {
+			return s, true
+		}
+	}
+	return SectionRange{}, false
+}
+
+// BeginSection begins a section named name, such as a chapter, on the
+// current page, or on the first page if none has been added yet. The section
+// ends where the next one begins, or at the end of the document. A section
+// that begins at the top of a page, for example just after AddPage(), leaves
+// the previous section on the pages before it; otherwise both sections share
+// the current page.
+//
+// Sections are reported by GetPagination() and give their page count to the
+// alias set with AliasSectionNbPages().
+func (f *Fpdf) BeginSection(name string) {
+	if f.err != nil {
+		return
+	}
+	s := sectionType{name: name, first: f.page, top: f.page == 0 || f.AtPageTop()}
+	if s.first == 0 {
+		s.first = 1
+	}
+	f.sections = append(f.sections, s)
+}
+
+// SectionPageNo returns the number of the current page within the current
+// section, starting at 1, or the page number if no section has begun.
+func (f *Fpdf) SectionPageNo() int {
+	if len(f.sections) == 0 {
+		return f.page
+	}
+	return f.page - f.sections[len(f.sections)-1].first + 1
+}
+
+// AliasSectionNbPages defines an alias for the number of pages of the
+// section that each page belongs to, so that footers can print "Page 3 of 8"
+// per chapter with SectionPageNo(). Like the alias of AliasNbPages(), it is
+// substituted as the document is closed, on each page with the count of its
+// own section, or of the document on pages before the first section. An
+// empty string is replaced with the string "{snb}".
+func (f *Fpdf) AliasSectionNbPages(aliasStr string) {
+	if aliasStr == "" {
+		aliasStr = "{snb}"
+	}
+	f.aliasSectionNbStr = aliasStr
+}
+
+// GetPagination returns the number of pages of the document and the range of
+// pages of its sections. The ranges are final once the document has been
+// closed.
+func (f *Fpdf) GetPagination() (p Pagination) {
+	p.Pages = f.PageCount()
+	for j, s := range f.sections {
+		r := SectionRange{Name: s.name, First: s.first, Last: p.Pages}
+		if j+1 < len(f.sections) {
+			next := f.sections[j+1]
+			r.Last = next.first
+			if next.top {
+				r.Last--
+			}
+			r.Last = max(r.First, r.Last)
+		}
+		p.Sections = append(p.Sections, r)
+	}
+	return
+}
+
+// replaceSectionAliases substitutes the alias set with AliasSectionNbPages()
+// on every page.
+func (f *Fpdf) replaceSectionAliases() {
+	if f.aliasSectionNbStr == "" {
+		return
+	}
+	p := f.GetPagination()
+	for n := 1; n <= f.page; n++ {
+		nb := p.Pages
+		if s, ok := p.SectionOf(n); ok {
+			nb = s.Pages()
+		}
+		nbStr := sprintf("%d", nb)
+		f.replacePageAlias(n, f.aliasSectionNbStr, nbStr)
+		f.replacePageAlias(n, utf8toutf16(f.aliasSectionNbStr, false), utf8toutf16(nbStr, false))
+	}
+}
+
+// replacePageAlias substitutes replacement for alias on page n.
+func (f *Fpdf) replacePageAlias(n int, alias, replacement string) {
+	s := f.pages[n].String()
+	if Contains(s, alias) {
+		s = Convert(s).Replace(alias, replacement).String()
+		f.pages[n].Truncate(0)
+		f.pages[n].WriteString(s)
+	}
+}
+
+// TwoPass builds a document twice with build, so that the content of every
+// page can depend on the pagination of the whole document, for example to
+// print "Page 3 of 8" per chapter or a table of contents with page numbers,
+// without reserving room for aliases. newDoc returns a new document with its
+// settings, such as page size and margins, and is called once for each pass.
+// build lays out the content and can set header and footer functions that
+// use the pagination.
+//
+// The first pass calls build with an empty Pagination and closes the
+// document to compute its pagination. The second pass calls build with that
+// pagination and returns the document, ready for Output(). build must lay
+// out the same content in both passes and use texts as wide as the final
+// ones in the first pass when they affect the layout, for example by
+// printing numbers with a fixed number of digits.
+//
+// If the first pass fails, its document is returned so that the error can be
+// examined.
+func TwoPass(newDoc func() *Fpdf, build func(pdf *Fpdf, p Pagination)) *Fpdf {
+	pdf := newDoc()
+	build(pdf, Pagination{})
+	pdf.Close()
+	if pdf.err != nil {
+		return pdf
+	}
+	p := pdf.GetPagination()
+	pdf = newDoc()
+	build(pdf, p)
+	return pdf
+}
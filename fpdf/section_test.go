@@ -0,0 +1,93 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+// writeChapters writes chapters of the given numbers of lines, each one
+// beginning on a new page.
+func writeChapters(pdf *fpdf.Fpdf, lines ...int) {
+	for j, n := range lines {
+		pdf.AddPage()
+		pdf.BeginSection(string(rune('A' + j)))
+		for k := 0; k < n; k++ {
+			pdf.CellFormat(0, 20, "line", "", 1, "", false, 0, "")
+		}
+	}
+}
+
+func TestAliasSectionNbPages(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AliasSectionNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-20)
+		pdf.CellFormat(0, 10, Sprintf("Page %d of {snb}", pdf.SectionPageNo()), "", 0, "C", false, 0, "")
+	})
+	// A page holds 38 lines.
+	writeChapters(pdf, 50, 10, 100)
+	p := pdf.GetPagination()
+	want := []fpdf.SectionRange{{Name: "A", First: 1, Last: 2}, {Name: "B", First: 3, Last: 3}, {Name: "C", First: 4, Last: 6}}
+	if p.Pages != 6 || len(p.Sections) != 3 {
+		t.Fatalf("unexpected pagination: %+v", p)
+	}
+	for j, s := range p.Sections {
+		if s != want[j] {
+			t.Errorf("section %d: got=%+v expected=%+v", j, s, want[j])
+		}
+	}
+	if s, ok := p.SectionOf(5); !ok || s.Name != "C" || s.Pages() != 3 {
+		t.Errorf("unexpected section of page 5: %+v", s)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"(Page 2 of 2)", "(Page 1 of 1)", "(Page 3 of 3)"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+	if strings.Contains(out, "{snb}") {
+		t.Errorf("output contains the alias")
+	}
+}
+
+func TestTwoPass(t *testing.T) {
+	passes := 0
+	pdf := fpdf.TwoPass(func() *fpdf.Fpdf {
+		pdf := NewDocPdfTest(fpdf.POINT)
+		pdf.SetCompression(false)
+		pdf.SetFont("Helvetica", "", 12)
+		return pdf
+	}, func(pdf *fpdf.Fpdf, p fpdf.Pagination) {
+		passes++
+		pdf.SetFooterFunc(func() {
+			s, _ := p.SectionOf(pdf.PageNo())
+			pdf.SetY(-20)
+			pdf.CellFormat(0, 10, Sprintf("Page %d of %d", pdf.SectionPageNo(), s.Pages()), "", 0, "C", false, 0, "")
+		})
+		writeChapters(pdf, 50, 10)
+	})
+	if passes != 2 {
+		t.Errorf("expected 2 passes, got %d", passes)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"(Page 1 of 2)", "(Page 2 of 2)", "(Page 1 of 1)"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
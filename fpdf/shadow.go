@@ -0,0 +1,57 @@
+package fpdf
+
+import "math"
+
+// maxShadowLayers limits the number of rectangles that DrawShadowRect()
+// stacks to soften the edge of a shadow.
+const maxShadowLayers = 16
+
+// DrawShadowRect draws the drop shadow of a box of width w and height h with
+// its upper left corner at (x, y) and corners rounded with radius r. The
+// shadow is the box moved offset to the right and down, in color, with an
+// edge that fades out over the width softness, half inside and half outside
+// of the moved box. Draw the shadow before the box so that the box covers
+// it.
+//
+// The opacity of the shadow where it is not faded is the alpha value set with
+// SetAlpha(), so SetAlpha(0.4, "Normal") followed by this method gives a
+// light shadow. The fade is approximated by stacking translucent rounded
+// rectangles, up to one per point of softness. The fill color and the alpha
+// value are left unchanged.
+func (f *Fpdf) DrawShadowRect(x, y, w, h, r, offset, softness float64, color RGBType) {
+	if f.err != nil {
+		return
+	}
+	if w <= 0 || h <= 0 || r < 0 || softness < 0 {
+		f.errorf("DrawShadowRect", "invalid shadow dimensions")
+		return
+	}
+	alpha, blendModeStr := f.GetAlpha()
+	fill, colorFlag := f.color.fill, f.colorFlag
+	x += offset
+	y += offset
+	n := int(math.Min(math.Ceil(softness*f.k), maxShadowLayers))
+	n = max(n, 1)
+	f.SetFillColor(color.R, color.G, color.B)
+	// Layer j covers the part of the shadow that is at least j layers deep,
+	// with the alpha value that raises the opacity there to j/n of the
+	// shadow's.
+	covered := 0.0
+	for j := 1; j <= n; j++ {
+		c := alpha * float64(j) / float64(n)
+		f.SetAlpha((c-covered)/(1-covered), blendModeStr)
+		covered = c
+		e := softness/2 - softness*(float64(j)-0.5)/float64(n)
+		if n == 1 {
+			e = 0
+		}
+		e = math.Max(e, -math.Min(w, h)/2)
+		er := math.Min(math.Max(r+e, 0), math.Min(w, h)/2+e)
+		f.RoundedRectExt(x-e, y-e, w+2*e, h+2*e, er, er, er, er, "F")
+	}
+	f.SetAlpha(alpha, blendModeStr)
+	f.color.fill, f.colorFlag = fill, colorFlag
+	if f.page > 0 {
+		f.out(fill.str)
+	}
+}
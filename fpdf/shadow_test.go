@@ -0,0 +1,55 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestDrawShadowRect(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFillColor(255, 255, 0)
+	pdf.DrawShadowRect(50, 50, 200, 100, 8, 4, 4, fpdf.RGBType{R: 80, G: 80, B: 80})
+	if alpha, mode := pdf.GetAlpha(); alpha != 1 || mode != "Normal" {
+		t.Errorf("alpha not restored: got=%.3f %s", alpha, mode)
+	}
+	if r, g, b := pdf.GetFillColor(); r != 255 || g != 255 || b != 0 {
+		t.Errorf("fill color not restored: got=%d %d %d", r, g, b)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// Four layers raise the opacity to a quarter, half, three quarters and
+	// all of the shadow's.
+	for _, s := range []string{
+		"/ca 0.250 /CA 0.250",
+		"/ca 0.333 /CA 0.333",
+		"/ca 0.500 /CA 0.500",
+		"/ca 1.000 /CA 1.000",
+		"0.314 g",
+		"1.000 1.000 0.000 rg",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+	// The outermost layer extends 1.5pt beyond the shadow box moved by 4pt,
+	// with its corner radius grown to 9.5pt.
+	if !strings.Contains(out, "62.00000 789.39000 m") {
+		t.Errorf("output does not contain the outermost layer")
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.DrawShadowRect(10, 10, -5, 10, 0, 1, 1, fpdf.RGBType{})
+	if !pdf.Err() {
+		t.Errorf("expected error for a negative width")
+	}
+}
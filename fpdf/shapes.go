@@ -0,0 +1,126 @@
+package fpdf
+
+import "math"
+
+// RegularPolygon draws a regular polygon with n sides, centered at (cx, cy)
+// and with its vertices on the circle of radius r. The first vertex is at the
+// top of the polygon, turned by rotation degrees counter-clockwise.
+//
+// styleStr can be "F" for filled, "D" for outlined only, or "DF" or "FD" for
+// outlined and filled. An empty string will be replaced with "D". Drawing uses
+// the current draw color and line width centered on the polygon's perimeter.
+// Filling uses the current fill color.
+func (f *Fpdf) RegularPolygon(cx, cy, r float64, n int, rotation float64, styleStr string) {
+	if n < 3 {
+		f.errorf("RegularPolygon", "a polygon needs at least 3 sides")
+		return
+	}
+	f.Polygon(starPoints(cx, cy, r, r, n, rotation), styleStr)
+}
+
+// Star draws a star with n points, centered at (cx, cy). The points of the
+// star are on the circle of radius rOuter and the vertices between them on
+// the circle of radius rInner. The first point is at the top of the star.
+// styleStr is used as in RegularPolygon().
+func (f *Fpdf) Star(cx, cy, rOuter, rInner float64, n int, styleStr string) {
+	if n < 2 {
+		f.errorf("Star", "a star needs at least 2 points")
+		return
+	}
+	f.Polygon(starPoints(cx, cy, rOuter, rInner, n, 0), styleStr)
+}
+
+// starPoints returns the vertices of a star with n points on the circle of
+// radius rOuter and n vertices between them on the circle of radius rInner,
+// or of a regular polygon with n sides if both radii are equal.
+func starPoints(cx, cy, rOuter, rInner float64, n int, rotation float64) []PointType {
+	var points []PointType
+	step := math.Pi / float64(n)
+	for j := 0; j < 2*n; j++ {
+		r := rOuter
+		if j%2 == 1 {
+			if rInner == rOuter {
+				continue
+			}
+			r = rInner
+		}
+		sin, cos := math.Sincos(math.Pi/2 + rotation*math.Pi/180 + float64(j)*step)
+		points = append(points, PointType{X: cx + r*cos, Y: cy - r*sin})
+	}
+	return points
+}
+
+// Sector draws a pie slice of the circle of radius r centered at (cx, cy),
+// between the angles degStart and degEnd. The angles are specified in degrees
+// and measured counter-clockwise from the 3 o'clock position. styleStr is
+// used as in RegularPolygon().
+func (f *Fpdf) Sector(cx, cy, r, degStart, degEnd float64, styleStr string) {
+	if degEnd < degStart {
+		degStart, degEnd = degEnd, degStart
+	}
+	if degEnd-degStart >= 360 {
+		f.Circle(cx, cy, r, styleStr)
+		return
+	}
+	x, y := f.x, f.y
+	f.MoveTo(cx, cy)
+	f.ArcTo(cx, cy, r, r, 0, degStart, degEnd)
+	f.ClosePath()
+	f.DrawPath(styleStr)
+	f.x, f.y = x, y
+}
+
+// RoundedPolygon draws a closed figure defined by a series of vertices like
+// Polygon(), with its corners rounded with radius r. The radius is reduced
+// at corners whose sides are too short for it. styleStr is used as in
+// RegularPolygon().
+func (f *Fpdf) RoundedPolygon(points []PointType, r float64, styleStr string) {
+	n := len(points)
+	if n < 3 {
+		return
+	}
+	// Each corner is cut at the distance cut from its vertex along both
+	// sides, and the cut is replaced with a Bézier approximation of an arc
+	// tangent to both sides.
+	type corner struct {
+		in, out PointType // ends of the arc
+		ctl     float64   // distance of the control points from the ends, as a share of cut
+	}
+	corners := make([]corner, n)
+	for j, v := range points {
+		prev, next := points[(j+n-1)%n], points[(j+1)%n]
+		lenIn := math.Hypot(v.X-prev.X, v.Y-prev.Y)
+		lenOut := math.Hypot(next.X-v.X, next.Y-v.Y)
+		c := corner{in: v, out: v}
+		if lenIn > 0 && lenOut > 0 && r > 0 {
+			inX, inY := (v.X-prev.X)/lenIn, (v.Y-prev.Y)/lenIn
+			outX, outY := (next.X-v.X)/lenOut, (next.Y-v.Y)/lenOut
+			// Angle by which the outline turns at the vertex
+			turn := math.Acos(math.Max(-1, math.Min(1, inX*outX+inY*outY)))
+			if turn > 1e-9 && turn < math.Pi-1e-9 {
+				cut := math.Min(r*math.Tan(turn/2), math.Min(lenIn, lenOut)/2)
+				rc := cut / math.Tan(turn/2)
+				c.in = PointType{X: v.X - inX*cut, Y: v.Y - inY*cut}
+				c.out = PointType{X: v.X + outX*cut, Y: v.Y + outY*cut}
+				c.ctl = 4.0 / 3 * math.Tan(turn/4) * rc / cut
+			}
+		}
+		corners[j] = c
+	}
+	f.point(corners[0].out.XY())
+	prec := f.prec.Curve
+	for j := 1; j <= n; j++ {
+		c, v := corners[j%n], points[j%n]
+		// f.outf("%.5f %.5f l", c.in.X*f.k, (f.h-c.in.Y)*f.k)
+		f.putF64(c.in.X*f.k, prec)
+		f.put(" ")
+		f.putF64((f.h-c.in.Y)*f.k, prec)
+		f.put(" l\n")
+		if c.in != c.out {
+			f.curve(c.in.X+(v.X-c.in.X)*c.ctl, c.in.Y+(v.Y-c.in.Y)*c.ctl,
+				c.out.X+(v.X-c.out.X)*c.ctl, c.out.Y+(v.Y-c.out.Y)*c.ctl, c.out.X, c.out.Y)
+		}
+	}
+	f.ClosePath()
+	f.DrawPath(styleStr)
+}
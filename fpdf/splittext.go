@@ -8,17 +8,29 @@ import (
 // SplitText splits UTF-8 encoded text into several lines using the current
 // font. Each line has its length limited to a maximum width given by w. This
 // function can be used to determine the total height of wrapped text for
-// vertical placement purposes.
+// vertical placement purposes. Lines are broken as by MultiCell().
 func (f *Fpdf) SplitText(txt string, w float64) (lines []string) {
-	cw := f.currentFont.Cw
+	s := []rune(f.transformText(txt)) // Return slice of UTF-8 runes
+	f.splitText(s, w, func(_, _ int, _ BreakType, line string) {
+		lines = append(lines, line)
+	})
+	return lines
+}
+
+// splitText breaks s into lines of width w as MultiCell() does and calls
+// emit with the rune offsets of each line in s, the reason it ends and its
+// text, without its soft hyphens and ending with a hyphen if it was broken
+// at one. Newlines at the end of s are ignored.
+func (f *Fpdf) splitText(s []rune, w float64, emit func(start, end int, brk BreakType, line string)) {
 	wmax := int(math.Ceil((w - 2*f.cMargin) * 1000 / f.fontSize))
-	s := []rune(txt) // Return slice of UTF-8 runes
 	nb := len(s)
 	for nb > 0 && s[nb-1] == '\n' {
 		nb--
 	}
 	s = s[0:nb]
+	brk := lineBreaks(s)
 	sep := -1
+	sepSpace := false // whether the line ends at a space, which is dropped
 	i := 0
 	j := 0
 	l := 0
@@ -26,26 +38,32 @@ func (f *Fpdf) SplitText(txt string, w float64) (lines []string) {
 	for i < nb {
 		c := s[i]
 
-		if int(c) >= len(cw) {
-			// Decimal representation of c is greater than the font width's array size so it can't be used as index.
-			l += cw[f.currentFont.Desc.MissingWidth]
+		if c == softHyphen {
+			// invisible unless the line is broken there
 		} else {
-			l += cw[c]
+			l += f.capWidth(int(c), f.charWidth(c))
 		}
 
-		if unicode.IsSpace(c) || isChinese(c) {
-			sep = i
+		if c == '\n' || c == ' ' && brk[i] {
+			sep, sepSpace = i, true
+		} else if brk[i] && i > j {
+			sep, sepSpace = i, false
 		}
 		if c == '\n' || l > wmax {
+			why := BreakWord
 			if sep == -1 {
 				if i == j {
 					i++
 				}
 				sep = i
 			} else {
-				i = sep + 1
+				why = breakType(s, sep, sepSpace)
+				i = sep
+				if sepSpace {
+					i++
+				}
 			}
-			lines = append(lines, string(s[j:sep]))
+			emit(j, sep, why, hyphenate(string(s[j:sep]), string(softHyphen), true))
 			sep = -1
 			j = i
 			l = 0
@@ -54,7 +72,94 @@ func (f *Fpdf) SplitText(txt string, w float64) (lines []string) {
 		}
 	}
 	if i != j {
-		lines = append(lines, string(s[j:i]))
+		emit(j, i, BreakEnd, hyphenate(string(s[j:i]), string(softHyphen), false))
 	}
+}
+
+// breakType returns why a line of s ends before the rune at index sep, a
+// break opportunity, or at it if it is a space or newline.
+func breakType(s []rune, sep int, space bool) BreakType {
+	switch {
+	case s[sep] == '\n':
+		return BreakForced
+	case space:
+		return BreakSpace
+	}
+	switch prev := s[sep-1]; {
+	case prev == softHyphen || unicode.Is(unicode.Pd, prev):
+		return BreakHyphen
+	case lineBreakClass(prev) == lbID || lineBreakClass(s[sep]) == lbID:
+		return BreakIdeographic
+	}
+	return BreakOther
+}
+
+// BreakType tells why a line returned by SplitTextDetailed() ends.
+type BreakType int
+
+const (
+	// BreakEnd marks the last line of the text.
+	BreakEnd BreakType = iota
+	// BreakForced marks a line ended by a newline character, which is not
+	// part of the line.
+	BreakForced
+	// BreakSpace marks a line wrapped at a space, which is not part of the
+	// line.
+	BreakSpace
+	// BreakHyphen marks a line wrapped after a hyphen, which is part of the
+	// line, or at a soft hyphen, shown as a hyphen at the end of the line.
+	BreakHyphen
+	// BreakIdeographic marks a line wrapped before or after an ideograph,
+	// such as a Chinese character.
+	BreakIdeographic
+	// BreakWord marks a line cut inside a word that does not fit on a line by
+	// itself.
+	BreakWord
+	// BreakOther marks a line wrapped at another break opportunity, for
+	// example after a tab or before an opening parenthesis.
+	BreakOther
+)
+
+// TextLine describes one line of text wrapped by SplitTextDetailed().
+type TextLine struct {
+	Text           string    // content of the line as SplitText() returns it, without the break character if it is a space or newline
+	Start, End     int       // rune offsets of the line in the original string
+	Width          float64   // width of Text in user units, excluding trailing spaces
+	TrailingSpaces int       // number of whitespace runes at the end of Text
+	Break          BreakType // reason the line ends
+}
+
+// SplitTextDetailed wraps UTF-8 encoded text like SplitText() but returns,
+// for each line, its position in the original string, its width and how it
+// was broken. This is meant for layout code that needs to map lines back to
+// the source text or to handle spacing itself, for example to justify lines.
+// The text of the lines is the one returned by SplitText(): soft hyphens
+// are removed and the transformation set with SetTextTransform() is
+// applied, so it may differ from the original string between Start and End.
+func (f *Fpdf) SplitTextDetailed(txt string, w float64) (lines []TextLine) {
+	s := []rune(f.transformText(txt))
+	f.splitText(s, w, func(start, end int, brk BreakType, text string) {
+		line := TextLine{Text: text, Start: start, End: end, Break: brk}
+		runes := []rune(text)
+		trimmed := len(runes)
+		for trimmed > 0 && unicode.IsSpace(runes[trimmed-1]) {
+			trimmed--
+		}
+		line.TrailingSpaces = len(runes) - trimmed
+		l := 0
+		for _, c := range runes[:trimmed] {
+			l += f.capWidth(int(c), f.charWidth(c))
+		}
+		line.Width = float64(l) * f.fontSize / 1000
+		lines = append(lines, line)
+	})
 	return lines
 }
+
+// runeWidth returns the width of c in glyph units for font.
+func runeWidth(font *fontDefType, c rune) int {
+	if w, ok := font.glyphWidth(c); ok {
+		return w
+	}
+	return font.Desc.MissingWidth
+}
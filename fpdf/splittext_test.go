@@ -0,0 +1,118 @@
+package fpdf_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSplitTextDetailed(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Courier", "", 10)
+	charW := pdf.GetStringWidth("x")
+	// room for exactly 10 characters
+	w := 10*charW + 2*pdf.GetCellMargin()
+
+	txt := "alpha beta well-knowns\nsupercalifragilistic end  "
+	lines := pdf.SplitTextDetailed(txt, w)
+
+	want := []struct {
+		text     string
+		start    int
+		brk      fpdf.BreakType
+		trailing int
+	}{
+		{"alpha beta", 0, fpdf.BreakSpace, 0},
+		{"well-", 11, fpdf.BreakHyphen, 0},
+		{"knowns", 16, fpdf.BreakForced, 0},
+		{"supercalif", 23, fpdf.BreakWord, 0},
+		{"ragilistic", 33, fpdf.BreakSpace, 0},
+		{"end  ", 44, fpdf.BreakEnd, 2},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected lines: %+v", lines)
+	}
+	runes := []rune(txt)
+	for i, line := range lines {
+		wl := want[i]
+		if line.Text != wl.text || line.Start != wl.start || line.Break != wl.brk || line.TrailingSpaces != wl.trailing {
+			t.Errorf("line %d: got %+v, want %+v", i, line, wl)
+		}
+		if string(runes[line.Start:line.End]) != line.Text {
+			t.Errorf("line %d: offsets [%d:%d] do not match %q", i, line.Start, line.End, line.Text)
+		}
+		trimmed := len([]rune(line.Text)) - line.TrailingSpaces
+		if math.Abs(line.Width-float64(trimmed)*charW) > 1e-9 {
+			t.Errorf("line %d: invalid width %v", i, line.Width)
+		}
+	}
+}
+
+func TestSplitTextLineBreaks(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Courier", "", 10)
+	// room for exactly 10 characters
+	w := 10*pdf.GetStringWidth("x") + 2*pdf.GetCellMargin()
+	for _, c := range []struct {
+		txt  string
+		want []string
+	}{
+		// no break at a no-break space
+		{"aaa bbb\u00a0ccc", []string{"aaa", "bbb\u00a0ccc"}},
+		// no break before closing punctuation
+		{"aaaaaa quoi ?", []string{"aaaaaa", "quoi ?"}},
+		// a soft hyphen is shown only where the line is broken
+		{"extra\u00adordinary", []string{"extra-", "ordinary"}},
+		{"co\u00adop", []string{"coop"}},
+	} {
+		lines := pdf.SplitText(c.txt, w)
+		if len(lines) != len(c.want) {
+			t.Errorf("%q: got %q, want %q", c.txt, lines, c.want)
+			continue
+		}
+		for i := range lines {
+			if lines[i] != c.want[i] {
+				t.Errorf("%q: got %q, want %q", c.txt, lines, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSplitTextDetailedMatchesSplitText(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Courier", "", 10)
+	// room for exactly 10 characters
+	w := 10*pdf.GetStringWidth("x") + 2*pdf.GetCellMargin()
+	for _, c := range []struct {
+		txt       string
+		transform fpdf.TextTransform
+		brk       fpdf.BreakType // reason the first line ends
+	}{
+		{"super\u00adcalifragilistic\u00adexpialidocious", fpdf.TextTransformNone, fpdf.BreakHyphen},
+		{"aaa bbb\u00a0ccc", fpdf.TextTransformNone, fpdf.BreakSpace},
+		{"\nalpha beta gamma", fpdf.TextTransformNone, fpdf.BreakForced},
+		{"alpha beta gamma", fpdf.TextTransformUppercase, fpdf.BreakSpace},
+	} {
+		pdf.SetTextTransform(c.transform)
+		want := pdf.SplitText(c.txt, w)
+		lines := pdf.SplitTextDetailed(c.txt, w)
+		if len(lines) != len(want) {
+			t.Errorf("%q: got %+v, want %q", c.txt, lines, want)
+			continue
+		}
+		for i, line := range lines {
+			if line.Text != want[i] {
+				t.Errorf("%q: line %d is %q, want %q", c.txt, i, line.Text, want[i])
+			}
+		}
+		if lines[0].Break != c.brk {
+			t.Errorf("%q: first line ends with break %d, want %d", c.txt, lines[0].Break, c.brk)
+		}
+	}
+	pdf.SetTextTransform(fpdf.TextTransformUppercase)
+	if lines := pdf.SplitTextDetailed("alpha beta", w); len(lines) != 1 || lines[0].Text != "ALPHA BETA" {
+		t.Errorf("transformation not applied: %+v", lines)
+	}
+}
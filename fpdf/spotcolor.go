@@ -1,9 +1,5 @@
 package fpdf
 
-import (
-	. "github.com/tinywasm/fmt"
-)
-
 func byteBound(v byte) byte {
 	if v > 100 {
 		return 100
@@ -17,6 +13,9 @@ func byteBound(v byte) byte {
 // 100. An error occurs if the specified name is already associated with a
 // color.
 func (f *Fpdf) AddSpotColor(nameStr string, c, m, y, k byte) {
+	if !f.strictColor("AddSpotColor", "c m y k", 100, int(c), int(m), int(y), int(k)) {
+		return
+	}
 	if f.err == nil {
 		_, ok := f.spotColorMap[nameStr]
 		if !ok {
@@ -31,16 +30,16 @@ func (f *Fpdf) AddSpotColor(nameStr string, c, m, y, k byte) {
 				},
 			}
 		} else {
-			f.err = Errf("name \"%s\" is already associated with a spot color", nameStr)
+			f.errorf("AddSpotColor", "name \"%s\" is already associated with a spot color", nameStr)
 		}
 	}
 }
 
-func (f *Fpdf) getSpotColor(nameStr string) (clr spotColorType, ok bool) {
+func (f *Fpdf) getSpotColor(method, nameStr string) (clr spotColorType, ok bool) {
 	if f.err == nil {
 		clr, ok = f.spotColorMap[nameStr]
 		if !ok {
-			f.err = Errf("spot color name \"%s\" is not registered", nameStr)
+			f.errorf(method, "spot color name \"%s\" is not registered", nameStr)
 		}
 	}
 	return
@@ -51,10 +50,13 @@ func (f *Fpdf) getSpotColor(nameStr string) (clr spotColorType, ok bool) {
 // The value for tint ranges from 0 (no intensity) to 100 (full intensity). It
 // is quietly bounded to this range.
 func (f *Fpdf) SetDrawSpotColor(nameStr string, tint byte) {
+	if !f.strictColor("SetDrawSpotColor", "tint", 100, int(tint)) {
+		return
+	}
 	var clr spotColorType
 	var ok bool
 
-	clr, ok = f.getSpotColor(nameStr)
+	clr, ok = f.getSpotColor("SetDrawSpotColor", nameStr)
 	if ok {
 		f.color.draw.mode = colorModeSpot
 		f.color.draw.spotStr = nameStr
@@ -70,10 +72,13 @@ func (f *Fpdf) SetDrawSpotColor(nameStr string, tint byte) {
 // The value for tint ranges from 0 (no intensity) to 100 (full intensity). It
 // is quietly bounded to this range.
 func (f *Fpdf) SetFillSpotColor(nameStr string, tint byte) {
+	if !f.strictColor("SetFillSpotColor", "tint", 100, int(tint)) {
+		return
+	}
 	var clr spotColorType
 	var ok bool
 
-	clr, ok = f.getSpotColor(nameStr)
+	clr, ok = f.getSpotColor("SetFillSpotColor", nameStr)
 	if ok {
 		f.color.fill.mode = colorModeSpot
 		f.color.fill.spotStr = nameStr
@@ -90,10 +95,13 @@ func (f *Fpdf) SetFillSpotColor(nameStr string, tint byte) {
 // The value for tint ranges from 0 (no intensity) to 100 (full intensity). It
 // is quietly bounded to this range.
 func (f *Fpdf) SetTextSpotColor(nameStr string, tint byte) {
+	if !f.strictColor("SetTextSpotColor", "tint", 100, int(tint)) {
+		return
+	}
 	var clr spotColorType
 	var ok bool
 
-	clr, ok = f.getSpotColor(nameStr)
+	clr, ok = f.getSpotColor("SetTextSpotColor", nameStr)
 	if ok {
 		f.color.text.mode = colorModeSpot
 		f.color.text.spotStr = nameStr
@@ -102,13 +110,13 @@ func (f *Fpdf) SetTextSpotColor(nameStr string, tint byte) {
 	}
 }
 
-func (f *Fpdf) returnSpotColor(clr colorType) (name string, c, m, y, k byte) {
+func (f *Fpdf) returnSpotColor(method string, clr colorType) (name string, c, m, y, k byte) {
 	var spotClr spotColorType
 	var ok bool
 
 	name = clr.spotStr
 	if name != "" {
-		spotClr, ok = f.getSpotColor(name)
+		spotClr, ok = f.getSpotColor(method, name)
 		if ok {
 			c = spotClr.val.c
 			m = spotClr.val.m
@@ -124,7 +132,7 @@ func (f *Fpdf) returnSpotColor(clr colorType) (name string, c, m, y, k byte) {
 // such as RGB is active. If no spot color has been set for drawing, zero
 // values are returned.
 func (f *Fpdf) GetDrawSpotColor() (name string, c, m, y, k byte) {
-	return f.returnSpotColor(f.color.draw)
+	return f.returnSpotColor("GetDrawSpotColor", f.color.draw)
 }
 
 // GetTextSpotColor returns the most recently used spot color information for
@@ -132,7 +140,7 @@ func (f *Fpdf) GetDrawSpotColor() (name string, c, m, y, k byte) {
 // type such as RGB is active. If no spot color has been set for text, zero
 // values are returned.
 func (f *Fpdf) GetTextSpotColor() (name string, c, m, y, k byte) {
-	return f.returnSpotColor(f.color.text)
+	return f.returnSpotColor("GetTextSpotColor", f.color.text)
 }
 
 // GetFillSpotColor returns the most recently used spot color information for
@@ -140,13 +148,13 @@ func (f *Fpdf) GetTextSpotColor() (name string, c, m, y, k byte) {
 // type such as RGB is active. If no fill spot color has been set, zero values
 // are returned.
 func (f *Fpdf) GetFillSpotColor() (name string, c, m, y, k byte) {
-	return f.returnSpotColor(f.color.fill)
+	return f.returnSpotColor("GetFillSpotColor", f.color.fill)
 }
 
 func (f *Fpdf) putSpotColors() {
 	for k, v := range f.spotColorMap {
 		f.newobj()
-		f.outf("[/Separation /%s", Convert(k).Replace(" ", "#20", -1))
+		f.outf("[/Separation /%s", escapeName(k))
 		f.out("/DeviceCMYK <<")
 		f.out("/Range [0 1 0 1 0 1 0 1] /C0 [0 0 0 0] ")
 		f.outf("/C1 [%.3f %.3f %.3f %.3f] ", float64(v.val.c)/100, float64(v.val.m)/100,
@@ -0,0 +1,63 @@
+package fpdf
+
+// StationeryScope selects the pages that receive the stationery set with
+// SetStationery().
+type StationeryScope int
+
+const (
+	// StationeryAllPages applies the stationery to every page.
+	StationeryAllPages StationeryScope = iota
+	// StationeryFirstPageOnly applies the stationery to the first page only.
+	StationeryFirstPageOnly
+	// StationeryOddPages applies the stationery to odd-numbered pages, the
+	// front sides when printing on both sides of the paper.
+	StationeryOddPages
+	// StationeryEvenPages applies the stationery to even-numbered pages.
+	StationeryEvenPages
+)
+
+// SetStationery sets the template drawn as the letterhead or other
+// pre-printed background of the pages selected by scope. tpl, created with
+// CreateTemplate() or imported from another PDF file with ImportPage(), is
+// drawn at its own size in the upper left corner of each selected page by
+// AddPage(), immediately after the page is started and before the header
+// function, so that it lies underneath the page content. Pass nil to remove
+// the stationery.
+//
+// Only one stationery is active at a time. To use different letterheads on
+// odd and even pages, set one with StationeryOddPages and the other from the
+// header function with UseTemplate().
+func (f *Fpdf) SetStationery(tpl *Template, scope StationeryScope) {
+	if tpl != nil {
+		if err := f.templateErr(tpl); err != nil {
+			f.setError("SetStationery", err)
+			return
+		}
+	}
+	f.stationery = tpl
+	f.stationeryScope = scope
+}
+
+// putStationery draws the stationery on the current page if its scope
+// selects the page.
+func (f *Fpdf) putStationery() {
+	if f.stationery == nil {
+		return
+	}
+	switch f.stationeryScope {
+	case StationeryFirstPageOnly:
+		if f.page != 1 {
+			return
+		}
+	case StationeryOddPages:
+		if f.page%2 == 0 {
+			return
+		}
+	case StationeryEvenPages:
+		if f.page%2 != 0 {
+			return
+		}
+	}
+	w, h := f.TemplateSize(f.stationery)
+	f.putTemplateUse(f.stationery, 0, 0, w, h)
+}
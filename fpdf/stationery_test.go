@@ -0,0 +1,100 @@
+package fpdf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetStationery(t *testing.T) {
+	tests := []struct {
+		scope fpdf.StationeryScope
+		want  []int
+	}{
+		{fpdf.StationeryAllPages, []int{1, 2, 3, 4}},
+		{fpdf.StationeryFirstPageOnly, []int{1}},
+		{fpdf.StationeryOddPages, []int{1, 3}},
+		{fpdf.StationeryEvenPages, []int{2, 4}},
+	}
+	for _, tt := range tests {
+		pdf := NewDocPdfTest()
+		pdf.SetFont("Arial", "", 12)
+		pdf.SetFillColor(10, 20, 30)
+		letterhead := pdf.CreateTemplate(210, 297, func() {
+			pdf.SetFont("Arial", "B", 20)
+			pdf.SetFillColor(200, 0, 0)
+			pdf.SetXY(10, 250)
+			pdf.CellFormat(0, 60, "ACME Corp.", "", 1, "L", true, 0, "")
+		})
+		pdf.SetFont("Arial", "", 12)
+		pdf.SetFillColor(10, 20, 30)
+		pdf.SetStationery(letterhead, tt.scope)
+		for range 4 {
+			pdf.AddPage()
+			left, top, _, _ := pdf.GetMargins()
+			if x, y := pdf.GetXY(); x != left || y != top {
+				t.Errorf("scope %d: position not restored: got (%v, %v)", tt.scope, x, y)
+			}
+			if got := pdf.GetFontStyle(); got != "" {
+				t.Errorf("scope %d: font style not restored: got %q", tt.scope, got)
+			}
+			if r, g, b := pdf.GetFillColor(); r != 10 || g != 20 || b != 30 {
+				t.Errorf("scope %d: fill color not restored: got (%d, %d, %d)", tt.scope, r, g, b)
+			}
+			pdf.Cell(40, 10, "Body")
+		}
+		if got := pdf.PageCount(); got != 4 {
+			t.Errorf("scope %d: stationery caused a page break: got %d pages", tt.scope, got)
+		}
+		r := readBack(t, pdf)
+		var pages []int
+		for n := 1; n <= r.PageCount(); n++ {
+			text, err := r.PageText(n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(text, "ACME Corp.") {
+				pages = append(pages, n)
+			}
+		}
+		if len(pages) != len(tt.want) {
+			t.Errorf("scope %d: got pages %v, want %v", tt.scope, pages, tt.want)
+		} else {
+			for i := range pages {
+				if pages[i] != tt.want[i] {
+					t.Errorf("scope %d: got pages %v, want %v", tt.scope, pages, tt.want)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestSetStationeryImportedPage(t *testing.T) {
+	src := NewDocPdfTest()
+	src.SetFont("Helvetica", "", 16)
+	src.AddPage()
+	src.Text(20, 20, "Letterhead from another file")
+	r := readBack(t, src)
+
+	pdf := NewDocPdfTest()
+	pdf.SetStationery(pdf.ImportPage(r, 1), fpdf.StationeryAllPages)
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.Text(20, 100, "Body")
+	out := readBack(t, pdf)
+	text, err := out.PageText(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "Letterhead from another file") || !strings.Contains(text, "Body") {
+		t.Errorf("unexpected page text: %q", text)
+	}
+
+	pdf = NewDocPdfTest()
+	pdf.SetStationery(&fpdf.Template{}, fpdf.StationeryAllPages)
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for a template not defined in the document")
+	}
+}
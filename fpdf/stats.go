@@ -0,0 +1,57 @@
+package fpdf
+
+// Stats holds counters about the work done to produce a document, to tune
+// the workloads of a server. See SetStats().
+type Stats struct {
+	Pages         int   // pages of the document written
+	Bytes         int   // size of the document written
+	Fonts         int   // font files embedded, including the subsets of UTF-8 fonts
+	Images        int   // images embedded, each counted once however often it is placed
+	ImageRawBytes int   // size of the pixels of the embedded images and of their alpha channels
+	ImageBytes    int   // size of the data of the embedded images, as stored in the document
+	CompressTime  int64 // nanoseconds spent compressing streams
+	SubsetTime    int64 // nanoseconds spent subsetting UTF-8 fonts
+}
+
+// SetStats makes the document count the work done to produce it in s, from
+// now on: the compression of images as they are registered and of the
+// streams written by Output() and related methods, and the subsetting,
+// embedding and writing done when the document is closed. Pages and Bytes are
+// set when the document is closed. Counting stops if s is nil.
+func (f *Fpdf) SetStats(s *Stats) {
+	f.stats = s
+}
+
+// compressStream compresses data like xmem.compress, counting the time it takes in
+// the stats of the document.
+func (f *Fpdf) compressStream(data []byte) *membuffer {
+	if f.stats == nil {
+		return xmem.compress(data)
+	}
+	start := nanotime()
+	mem := xmem.compress(data)
+	f.stats.CompressTime += nanotime() - start
+	return mem
+}
+
+// countImage counts the embedded image info in the stats of the document.
+func (f *Fpdf) countImage(info *ImageInfoType) {
+	if f.stats == nil {
+		return
+	}
+	components := 1
+	switch info.cs {
+	case "DeviceRGB":
+		components = 3
+	case "DeviceCMYK":
+		components = 4
+	}
+	w, h := int(info.w), int(info.h)
+	f.stats.Images++
+	f.stats.ImageRawBytes += (w*components*info.bpc + 7) / 8 * h
+	f.stats.ImageBytes += len(info.data)
+	if len(info.smask) > 0 {
+		f.stats.ImageRawBytes += w * h
+		f.stats.ImageBytes += len(info.smask)
+	}
+}
@@ -0,0 +1,35 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetStats(t *testing.T) {
+	var stats fpdf.Stats
+	pdf := NewDocPdfTest()
+	pdf.SetStats(&stats)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	for j := 0; j < 2; j++ {
+		pdf.AddPage()
+		pdf.SetFont("dejavu", "", 12)
+		pdf.Text(10, 20, "Stats")
+		pdf.Image(ImageFile("logo.png"), 10, 30, 30, 0, false, "", 0, "")
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Pages != 2 || stats.Bytes != buf.Len() || stats.Fonts != 1 || stats.Images != 1 {
+		t.Errorf("got %d pages, %d bytes, %d fonts, %d images, want 2, %d, 1, 1",
+			stats.Pages, stats.Bytes, stats.Fonts, stats.Images, buf.Len())
+	}
+	if stats.ImageBytes <= 0 || stats.ImageRawBytes <= stats.ImageBytes {
+		t.Errorf("image sizes: raw %d, stored %d", stats.ImageRawBytes, stats.ImageBytes)
+	}
+	if stats.CompressTime <= 0 || stats.SubsetTime <= 0 {
+		t.Errorf("times: compression %d ns, subsetting %d ns", stats.CompressTime, stats.SubsetTime)
+	}
+}
@@ -7,10 +7,13 @@ import (
 	. "github.com/tinywasm/fmt"
 )
 
-// SVGBasicSegmentType describes a single curve or position segment
+// SVGBasicSegmentType describes a single curve or position segment. The
+// arguments of an elliptical arc ('A') are rx, ry, the rotation and the end
+// point x, y; its flags are held in LargeArc and Sweep.
 type SVGBasicSegmentType struct {
-	Cmd byte // See http://www.w3.org/TR/SVG/paths.html for path command structure
-	Arg [6]float64
+	Cmd             byte // See http://www.w3.org/TR/SVG/paths.html for path command structure
+	Arg             [6]float64
+	LargeArc, Sweep bool // flags of an elliptical arc
 }
 
 func absolutizePath(segs []SVGBasicSegmentType) {
@@ -73,6 +76,14 @@ func absolutizePath(segs []SVGBasicSegmentType) {
 			segPtr.Arg[0] += y
 			segPtr.Cmd = 'V'
 			y += seg.Arg[0]
+		case 'A':
+			x = seg.Arg[3]
+			y = seg.Arg[4]
+		case 'a':
+			adjust(3, x, y)
+			segPtr.Cmd = 'A'
+			x = segPtr.Arg[3]
+			y = segPtr.Arg[4]
 		case 'z':
 			segPtr.Cmd = 'Z'
 		}
@@ -87,6 +98,7 @@ func normalizePath(s string) string {
 		Replace("H", " H ").Replace("h", " h ").
 		Replace("V", " V ").Replace("v", " v ").
 		Replace("Q", " Q ").Replace("q", " q ").
+		Replace("A", " A ").Replace("a", " a ").
 		Replace("Z", " Z ").Replace("z", " z ").
 		String()
 }
@@ -100,6 +112,7 @@ func pathParse(pathStr string, adjustToPt float64) (segs []SVGBasicSegmentType,
 		for j := 0; j < len(seg.Arg); j++ {
 			seg.Arg[j] = 0.0
 		}
+		seg.LargeArc, seg.Sweep = false, false
 		argJ = 0
 		argCount = n
 		prevArgCount = n
@@ -143,15 +156,36 @@ func pathParse(pathStr string, adjustToPt float64) (segs []SVGBasicSegmentType,
 					setup(4)
 				case 'V', 'v': // Absolute/relative vertical line to: y
 					setup(1)
+				case 'A', 'a': // Absolute/relative elliptical arc: rx, ry, rotation, large-arc, sweep, x, y
+					setup(7)
 				case 'Z', 'z': // closepath instruction (takes no arguments)
 					segs = append(segs, seg)
 				default:
 					err = Err("SVG path command", "invalid", Sprintf("at position %d got %s", j, str))
 				}
+			} else if arc := seg.Cmd == 'A' || seg.Cmd == 'a'; arc && (argJ == 3 || argJ == 4) {
+				// The flags of arcs are held apart from the arguments
+				var flag float64
+				if flag, err = Convert(str).Float64(); err == nil {
+					if argJ == 3 {
+						seg.LargeArc = flag != 0
+					} else {
+						seg.Sweep = flag != 0
+					}
+					argJ++
+					argCount--
+				}
 			} else {
-				seg.Arg[argJ], err = Convert(str).Float64()
+				argK := argJ
+				if arc && argJ > 4 {
+					argK -= 2
+				}
+				seg.Arg[argK], err = Convert(str).Float64()
+				// The rotation of arcs is not a length
+				if err == nil && !(arc && argJ == 2) {
+					seg.Arg[argK] *= adjustToPt
+				}
 				if err == nil {
-					seg.Arg[argJ] *= adjustToPt
 					argJ++
 					argCount--
 					if argCount == 0 {
@@ -54,6 +54,17 @@ func (f *Fpdf) SVGBasicWrite(sb *SVGBasicType, scale float64) {
 				newX, newY = val(2)
 				f.Curve(x, y, cx0, cy0, newX, newY, "D")
 				x, y = newX, newY
+			case 'A':
+				newX, newY = val(3)
+				for _, c := range ellipticalArcCurves(x, y, scale*seg.Arg[0], scale*seg.Arg[1], seg.Arg[2],
+					seg.LargeArc, seg.Sweep, newX, newY) {
+					f.CurveCubic(x, y, c[0], c[1], c[4], c[5], c[2], c[3], "D")
+					x, y = c[4], c[5]
+				}
+				if seg.Arg[0] == 0 || seg.Arg[1] == 0 {
+					f.Line(x, y, newX, newY)
+				}
+				x, y = newX, newY
 			case 'H':
 				newX = xval(0)
 				f.Line(x, y, newX, y)
@@ -66,7 +77,7 @@ func (f *Fpdf) SVGBasicWrite(sb *SVGBasicType, scale float64) {
 				f.Line(x, y, startX, startY)
 				x, y = startX, startY
 			default:
-				f.SetErrorf("Unexpected path command '%c'", seg.Cmd)
+				f.errorf("SVGBasicWrite", "Unexpected path command '%c'", seg.Cmd)
 			}
 		}
 	}
@@ -121,6 +132,9 @@ func (f *Fpdf) SVGBasicDraw(sb *SVGBasicType, scale float64, styleStr string) {
 				cx0, cy0 = val(0)
 				newX, newY = val(2)
 				f.CurveTo(cx0, cy0, newX, newY)
+			case 'A':
+				newX, newY = val(3)
+				f.EllipticalArcTo(scale*seg.Arg[0], scale*seg.Arg[1], seg.Arg[2], seg.LargeArc, seg.Sweep, newX, newY)
 			case 'H':
 				newX = xval(0)
 				f.LineTo(newX, f.GetY())
@@ -131,7 +145,7 @@ func (f *Fpdf) SVGBasicDraw(sb *SVGBasicType, scale float64, styleStr string) {
 				f.ClosePath()
 				f.DrawPath(styleStr)
 			default:
-				f.SetErrorf("Unexpected path command '%c'", seg.Cmd)
+				f.errorf("SVGBasicDraw", "Unexpected path command '%c'", seg.Cmd)
 			}
 		}
 	}
@@ -0,0 +1,355 @@
+package fpdf
+
+import (
+	"bytes"
+	"math"
+	"sort"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// Template is content stored once in the document as a form XObject and
+// placed any number of times, on any page, with UseTemplate(). It is
+// created by CreateTemplate() from drawing calls or by ImportPage() from a
+// page of an existing PDF file.
+type Template struct {
+	id   int     // 1-based index in f.templates, the number of its /TPL name
+	w, h float64 // size in points
+}
+
+// templateType is the content of a template, written when the document is
+// output.
+type templateType struct {
+	box     [4]float64 // bounding box in points
+	content []byte     // content stream
+	reader  *PDFReader // reader of an imported page, nil for drawn content
+	res     any        // resources of an imported page
+	objNum  int        // object number, set when output
+}
+
+// CreateTemplate calls fn to draw the content of a template of width w and
+// height h, in the unit of measure specified in New(), and returns the
+// template. While fn runs, the drawing methods write to the template rather
+// than to the current page, with coordinates measured from the upper left
+// corner of the template, and automatic page breaks do not take place. The
+// template may use the fonts, images and other templates of the document.
+//
+// Links and file attachments drawn by fn are not part of the template, and
+// placeholders, text fields, image slots and anchors cannot be added by fn
+// since the template has no page of its own to fill later. The font, colors and line width that fn leaves current remain current
+// afterwards. CreateTemplate can be called before the first page is added.
+func (f *Fpdf) CreateTemplate(w, h float64, fn func()) *Template {
+	if f.err != nil || !f.strictLength("CreateTemplate", "w h", w, h) {
+		return nil
+	}
+	page, state := f.page, f.state
+	x, y, pw, ph := f.x, f.y, f.w, f.h
+	wPt, hPt, trigger := f.wPt, f.hPt, f.pageBreakTrigger
+	accept := f.acceptPageBreak
+	f.acceptPageBreak = func() bool { return false }
+
+	// The template is drawn on a page past the last one, removed afterwards.
+	n := len(f.pages)
+	f.pages = append(f.pages, new(bytes.Buffer))
+	f.pageLinks = append(f.pageLinks, nil)
+	f.pageAttachments = append(f.pageAttachments, nil)
+	f.pageBody = append(f.pageBody, pageBodyType{end: -1})
+	f.page, f.state = n, 2
+	f.w, f.h = w, h
+	f.wPt, f.hPt = w*f.k, h*f.k
+	f.pageBreakTrigger = h
+	f.x, f.y = f.lMargin, f.tMargin
+	f.outState()
+	inTemplate := f.inTemplate
+	f.inTemplate = true
+	fn()
+	f.inTemplate = inTemplate
+	content := f.pages[n].Bytes()
+
+	f.pages = f.pages[:n]
+	f.pageLinks = f.pageLinks[:n]
+	f.pageAttachments = f.pageAttachments[:n]
+	f.pageBody = f.pageBody[:n]
+	delete(f.pageExtents, n)
+	delete(f.textObjs, n)
+	delete(f.textMap, n)
+	delete(f.redactions, n)
+	f.page, f.state = page, state
+	f.x, f.y, f.w, f.h = x, y, pw, ph
+	f.wPt, f.hPt, f.pageBreakTrigger = wPt, hPt, trigger
+	f.acceptPageBreak = accept
+	if f.state == 2 {
+		f.outState()
+	}
+	if f.err != nil {
+		return nil
+	}
+	return f.addTemplate(templateType{box: [4]float64{0, 0, w * f.k, h * f.k}, content: content})
+}
+
+// ImportPage returns a template showing page n, 1-based, of the PDF file
+// read by r, for instance to draw a letterhead or a form designed in another
+// application under the content of the pages. The template has the size of
+// the crop box of the page, or of its media box if it has none. Annotations
+// of the page, such as links and form fields, are not imported, and neither
+// is its rotation.
+func (f *Fpdf) ImportPage(r *PDFReader, n int) *Template {
+	if f.err != nil {
+		return nil
+	}
+	if r == nil || n < 1 || n > r.PageCount() {
+		f.errorf("ImportPage", "page %d does not exist in the source document", n)
+		return nil
+	}
+	page := r.pages[n-1]
+	box, _ := r.resolve(page["CropBox"]).([]any)
+	if len(box) != 4 {
+		box, _ = r.resolve(page["MediaBox"]).([]any)
+	}
+	if len(box) != 4 {
+		f.errorf("ImportPage", "page %d has no media box", n)
+		return nil
+	}
+	var streams []any
+	switch v := r.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		streams = []any{v}
+	case []any:
+		streams = v
+	}
+	var content []byte
+	for _, v := range streams {
+		s, ok := r.resolve(v).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := r.decode(s)
+		if err != nil {
+			f.setError("ImportPage", err)
+			return nil
+		}
+		content = append(append(content, data...), '\n')
+	}
+	return f.addTemplate(templateType{
+		box: [4]float64{
+			math.Min(r.number(box[0]), r.number(box[2])), math.Min(r.number(box[1]), r.number(box[3])),
+			math.Max(r.number(box[0]), r.number(box[2])), math.Max(r.number(box[1]), r.number(box[3])),
+		},
+		content: content,
+		reader:  r,
+		res:     page["Resources"],
+	})
+}
+
+// addTemplate adds tpl to the templates of the document.
+func (f *Fpdf) addTemplate(tpl templateType) *Template {
+	f.templates = append(f.templates, tpl)
+	return &Template{id: len(f.templates), w: tpl.box[2] - tpl.box[0], h: tpl.box[3] - tpl.box[1]}
+}
+
+// TemplateSize returns the width and height of tpl in the unit of measure
+// specified in New().
+func (f *Fpdf) TemplateSize(tpl *Template) (w, h float64) {
+	if tpl == nil {
+		return 0, 0
+	}
+	return tpl.w / f.k, tpl.h / f.k
+}
+
+// UseTemplate draws tpl on the current page with its upper left corner at
+// (x, y) and a width of w and a height of h. If w and h are both 0, tpl is
+// drawn at its own size; if one of them is 0, it is computed to keep the
+// proportions of tpl.
+func (f *Fpdf) UseTemplate(tpl *Template, x, y, w, h float64) {
+	if f.err != nil || !f.strictFinite("UseTemplate", "x y", x, y) || !f.strictLength("UseTemplate", "w h", w, h) {
+		return
+	}
+	if err := f.templateErr(tpl); err != nil {
+		f.setError("UseTemplate", err)
+		return
+	}
+	if f.page == 0 {
+		f.errorf("UseTemplate", "no page has been added")
+		return
+	}
+	tw, th := f.TemplateSize(tpl)
+	switch {
+	case w == 0 && h == 0:
+		w, h = tw, th
+	case w == 0 && th != 0:
+		w = h * tw / th
+	case h == 0 && tw != 0:
+		h = w * th / tw
+	}
+	f.putTemplateUse(tpl, x, y, w, h)
+	f.extend(x, y, x+w, y+h)
+}
+
+// putTemplateUse writes the operators that draw tpl on the current page at
+// (x, y) with a width of w and a height of h.
+func (f *Fpdf) putTemplateUse(tpl *Template, x, y, w, h float64) {
+	t := f.templates[tpl.id-1]
+	sx, sy := 1.0, 1.0
+	if tpl.w != 0 {
+		sx = w * f.k / tpl.w
+	}
+	if tpl.h != 0 {
+		sy = h * f.k / tpl.h
+	}
+	f.put("q ")
+	f.putF64(sx, f.prec.Matrix)
+	f.put(" 0 0 ")
+	f.putF64s(f.prec.Matrix, sy, x*f.k-t.box[0]*sx, (f.h-y-h)*f.k-t.box[1]*sy)
+	f.outf("cm /TPL%d Do Q", tpl.id)
+}
+
+// puttemplates writes the templates as form XObjects, each imported page
+// followed by the objects its resources refer to.
+func (f *Fpdf) puttemplates() {
+	imported := make(map[*PDFReader]map[pdfRef]int)
+	for j := range f.templates {
+		t := &f.templates[j]
+		box := sprintf("/BBox [%.2f %.2f %.2f %.2f] ", t.box[0], t.box[1], t.box[2], t.box[3])
+		if t.reader == nil {
+			f.newobj()
+			t.objNum = f.n
+			f.putcontentstream("/Type /XObject /Subtype /Form "+box+"/Resources 2 0 R ", t.content)
+			f.out("endobj")
+			continue
+		}
+		// The objects the resources refer to are numbered after the form,
+		// in the order in which they are written.
+		refs := imported[t.reader]
+		if refs == nil {
+			refs = make(map[pdfRef]int)
+			imported[t.reader] = refs
+		}
+		var queue []pdfRef
+		t.reader.collectRefs(t.res, refs, &queue, f.n+2)
+		f.newobj()
+		t.objNum = f.n
+		f.putcontentstream("/Type /XObject /Subtype /Form "+box+"/Resources "+f.importedValue(t.reader, t.res, refs)+" ", t.content)
+		f.out("endobj")
+		for _, ref := range queue {
+			f.newobj()
+			switch v := t.reader.object(ref.num).(type) {
+			case *pdfStream:
+				dict := make(map[string]any, len(v.dict))
+				for key, val := range v.dict {
+					if key != "Length" {
+						dict[key] = val
+					}
+				}
+				dict["Length"] = float64(len(v.data))
+				f.out(f.importedValue(t.reader, dict, refs))
+				f.putstream(v.data)
+			default:
+				f.out(f.importedValue(t.reader, v, refs))
+			}
+			f.out("endobj")
+		}
+	}
+}
+
+// collectRefs appends to queue the references reachable from v that are not
+// in refs yet, and numbers them in refs from next on.
+func (r *PDFReader) collectRefs(v any, refs map[pdfRef]int, queue *[]pdfRef, next int) {
+	for pending := []any{v}; len(pending) > 0; {
+		v, pending = pending[0], pending[1:]
+		switch v := v.(type) {
+		case pdfRef:
+			if _, ok := refs[v]; ok || r.object(v.num) == nil {
+				continue
+			}
+			refs[v] = next + len(*queue)
+			*queue = append(*queue, v)
+			pending = append(pending, r.object(v.num))
+		case *pdfStream:
+			pending = append(pending, v.dict)
+		case []any:
+			pending = append(pending, v...)
+		case map[string]any:
+			for _, key := range sortedKeys(v) {
+				pending = append(pending, v[key])
+			}
+		}
+	}
+}
+
+// importedValue formats v, a value read by r, for the current object, with
+// the references renumbered according to refs.
+func (f *Fpdf) importedValue(r *PDFReader, v any, refs map[pdfRef]int) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case float64:
+		if v == float64(int(v)) {
+			return f.fmtInt(int(v))
+		}
+		return f.fmtF64(v, 5)
+	case string:
+		return f.textstring(v)
+	case pdfName:
+		return "/" + escapeName(string(v))
+	case pdfRef:
+		if num, ok := refs[v]; ok {
+			return sprintf("%d 0 R", num)
+		}
+		return "null"
+	case []any:
+		var b fmtBuffer
+		b.printf("[")
+		for j, item := range v {
+			if j > 0 {
+				b.printf(" ")
+			}
+			b.printf("%s", f.importedValue(r, item, refs))
+		}
+		b.printf("]")
+		return b.String()
+	case map[string]any:
+		var b fmtBuffer
+		b.printf("<<")
+		for _, key := range sortedKeys(v) {
+			b.printf("/%s %s ", escapeName(key), f.importedValue(r, v[key], refs))
+		}
+		b.printf(">>")
+		return b.String()
+	case *pdfStream:
+		// A stream can only be referred to; one in place is dropped.
+		return "null"
+	}
+	return "null"
+}
+
+// sortedKeys returns the keys of dict in increasing order, for an output that
+// does not depend on the iteration order of maps.
+func sortedKeys(dict map[string]any) []string {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// puttemplatedict writes the templates to the XObject resource dictionary.
+func (f *Fpdf) puttemplatedict() {
+	for j, t := range f.templates {
+		f.outf("/TPL%d %d 0 R", j+1, t.objNum)
+	}
+}
+
+// templateErr returns an error for a template that is not defined in the
+// document, or nil.
+func (f *Fpdf) templateErr(tpl *Template) error {
+	if tpl == nil || tpl.id < 1 || tpl.id > len(f.templates) {
+		return Err("template is not defined in this document")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	stamp := pdf.CreateTemplate(40, 20, func() {
+		pdf.Rect(0, 0, 40, 20, "D")
+		pdf.Text(5, 12, "PAID")
+	})
+	if w, h := pdf.TemplateSize(stamp); !floatEqual(w, 40) || !floatEqual(h, 20) {
+		t.Errorf("unexpected template size: got (%v, %v)", w, h)
+	}
+	pdf.AddPage()
+	pdf.UseTemplate(stamp, 10, 10, 0, 0)
+	pdf.UseTemplate(stamp, 10, 50, 80, 0)
+	if box, ok := pdf.GetContentExtents(1); !ok || !floatEqual(box.Ht, 80) {
+		t.Errorf("unexpected content extents: %+v", box)
+	}
+	pdf.AddPage()
+	pdf.UseTemplate(stamp, 10, 10, 0, 0)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "/Subtype /Form"); n != 1 {
+		t.Errorf("template written %d times, want once", n)
+	}
+	for _, want := range []string{
+		"q 1.00000 0 0 1.00000 28.34646 756.85063 cm /TPL1 Do Q",
+		"q 2.00000 0 0 2.00000 28.34646 586.77189 cm /TPL1 Do Q",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+
+	pdf = NewDocPdfTest()
+	stamp = pdf.CreateTemplate(40, 20, func() {})
+	pdf.UseTemplate(stamp, 10, 10, 0, 0)
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for a template used before the first page")
+	}
+}
+
+func TestTemplateRejectsFields(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.CreateTemplate(40, 20, func() {
+		pdf.AddTextField("name", 30, 10, "L")
+	})
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for a text field added to a template")
+	}
+	// The template page is gone, filling must not reach it
+	pdf.FillFields(map[string]any{"name": "Ada"})
+
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.CreateTemplate(40, 20, func() {
+		pdf.MarkAnchor("corner")
+	})
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for an anchor marked in a template")
+	}
+	if _, _, _, ok := pdf.GetAnchor("corner"); ok {
+		t.Errorf("anchor recorded on the template page")
+	}
+	pdf.PlaceAt("corner", 0, 0, func() {})
+}
@@ -0,0 +1,53 @@
+package fpdf
+
+// SetTextBackgroundColor defines the color painted behind text, like a
+// highlighter. It is expressed in RGB components (0 - 255). Unlike the fill of
+// a cell, the background spans only the text printed with Text(), Cell(),
+// MultiCell() and Write(), from the ascent to the descent of the font, so that
+// a highlighted fragment can start and end anywhere in a paragraph written
+// with Write(). The background extends beyond the text by the padding set
+// with SetTextBackgroundPadding(). The value is retained from page to page
+// until ClearTextBackgroundColor() is called.
+func (f *Fpdf) SetTextBackgroundColor(r, g, b int) {
+	if !f.strictColor("SetTextBackgroundColor", "r g b", 255, r, g, b) {
+		return
+	}
+	f.textBg = f.rgbColorValue(r, g, b, "g", "rg")
+}
+
+// GetTextBackgroundColor returns the color set with SetTextBackgroundColor()
+// as RGB components (0 - 255), and whether it is set.
+func (f *Fpdf) GetTextBackgroundColor() (r, g, b int, ok bool) {
+	return f.textBg.ir, f.textBg.ig, f.textBg.ib, f.textBg.str != ""
+}
+
+// ClearTextBackgroundColor stops painting a background behind text.
+func (f *Fpdf) ClearTextBackgroundColor() {
+	f.textBg = colorType{}
+}
+
+// SetTextBackgroundPadding sets the distance, in the unit of measure specified
+// in New(), by which the background set with SetTextBackgroundColor() extends
+// beyond the text on every side. The default is 0.
+func (f *Fpdf) SetTextBackgroundPadding(pad float64) {
+	f.textBgPad = pad
+}
+
+// textBackground returns the operators that paint the text background behind
+// text of width w starting at baseline position (x, y), or an empty string if
+// no background is set.
+func (f *Fpdf) textBackground(x, y, w float64) string {
+	if f.textBg.str == "" {
+		return ""
+	}
+	ascent, descent, _, _ := f.GetFontMetrics()
+	if ascent == 0 {
+		// not defined, use the proportions of the core fonts
+		ascent, descent = 0.8*f.fontSize, -0.2*f.fontSize
+	}
+	f.extend(x-f.textBgPad, y-ascent-f.textBgPad, x+w+f.textBgPad, y-descent+f.textBgPad)
+	b := append(append([]byte("q "), f.textBg.str...), ' ')
+	b = appendFloats(b, f.prec.Path, (x-f.textBgPad)*f.k, (f.h-(y-descent+f.textBgPad))*f.k,
+		(w+2*f.textBgPad)*f.k, (ascent-descent+2*f.textBgPad)*f.k)
+	return string(append(b, "re f Q "...))
+}
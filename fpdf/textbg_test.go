@@ -0,0 +1,53 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetTextBackgroundColor(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.AddPage()
+	pdf.SetX(100)
+	y := pdf.GetY()
+	pdf.Write(20, "plain ")
+	pdf.SetTextBackgroundColor(255, 255, 0)
+	pdf.SetTextBackgroundPadding(1)
+	if r, g, b, ok := pdf.GetTextBackgroundColor(); !ok || r != 255 || g != 255 || b != 0 {
+		t.Errorf("unexpected background color: %d %d %d %v", r, g, b, ok)
+	}
+	pdf.Write(20, "marked")
+	pdf.ClearTextBackgroundColor()
+	pdf.Write(20, " plain")
+	if _, _, _, ok := pdf.GetTextBackgroundColor(); ok {
+		t.Errorf("background color not cleared")
+	}
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "q 1.000 1.000 0.000 rg "); n != 1 {
+		t.Fatalf("background painted %d times, expected once", n)
+	}
+	// The background spans the width of the marked text and the ascent and
+	// descent of Helvetica, plus the padding.
+	m := pdf.GetCellMargin()
+	x := 100 + pdf.GetStringWidth("plain ") + m - 1
+	w := pdf.GetStringWidth("marked") + 2
+	ascent, descent, _, _ := pdf.GetFontMetrics()
+	rect := Sprintf("%.2f %.2f %.2f %.2f re f Q BT", x, 841.89-(y+10+3-descent+1), w, ascent-descent+2)
+	if !strings.Contains(out, rect) {
+		t.Errorf("output does not contain %q", rect)
+	}
+}
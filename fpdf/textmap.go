@@ -0,0 +1,209 @@
+package fpdf
+
+import (
+	"io"
+	"sort"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// TextMapEntry is a piece of invisible text printed by OverlayText(), as
+// listed by TextMap().
+type TextMapEntry struct {
+	Page       int
+	X, Y, W, H float64 // box of the text, in the unit of measure specified in New()
+	Text       string  // text in UTF-8
+}
+
+// textMapType is an entry of the text map of a page with the index of its
+// text object in f.textObjs, to leave out the entries that were redacted.
+type textMapType struct {
+	entry TextMapEntry
+	obj   int
+}
+
+// OverlayText prints txtStr invisibly, with text rendering mode 3, in the
+// rectangle of width w and height h whose upper left corner is at point
+// (x, y) on the current page. The text is sized to the height of the box and
+// stretched or condensed to its width, so that selecting or searching it in a
+// viewer highlights the box. This is how a scanned page placed with Image()
+// is made searchable with the words an OCR engine found on it.
+//
+// The text is printed with the current font, whose size and the rendering
+// mode of the following text are left unchanged. Each call is recorded with
+// its box in the text map of the document, which TextMap() lists and
+// WriteTextMapJSON() and WriteHOCR() write as a sidecar file for indexing
+// systems.
+func (f *Fpdf) OverlayText(x, y, w, h float64, txtStr string) {
+	if f.err != nil || txtStr == "" {
+		return
+	}
+	if f.page == 0 {
+		f.errorf("OverlayText", "overlay text requires a page; call AddPage first")
+		return
+	}
+	if f.currentFont.Name == "" {
+		f.errorf("OverlayText", "font has not been set; unable to render text")
+		return
+	}
+	if w <= 0 || h <= 0 {
+		f.errorf("OverlayText", "invalid box size: %.2f x %.2f", w, h)
+		return
+	}
+	text := txtStr
+	txt2 := f.escape(txtStr)
+	if f.isCurrentUTF8 {
+		f.useRunes("OverlayText", txtStr)
+		txt2 = f.escape(f.encodeText(txtStr))
+	} else {
+		text = decodeCodepage(txtStr, f.currentFont.Enc)
+	}
+	sizePt := f.fontSizePt
+	f.SetFontUnitSize(h)
+	scale := 100.0
+	if tw := f.GetStringWidth(txtStr); tw > 0 {
+		scale = w / tw * 100
+	}
+	// The descent of most fonts is about a fifth of their size
+	base := y + .8*h
+	var obj fmtBuffer
+	obj.printf("BT 3 Tr ")
+	obj.Write(appendFloats(nil, f.prec.Text, scale))
+	obj.printf("Tz ")
+	obj.Write(appendFloats(nil, f.prec.Text, x*f.k, (f.h-base)*f.k))
+	obj.printf("Td %s ET", f.textShow(txtStr, txt2, "Tj"))
+	f.outText("q ", obj.String(), " Q", x, base, txtStr)
+	f.SetFontSize(sizePt)
+	if f.textMap == nil {
+		f.textMap = make(map[int][]textMapType)
+	}
+	f.textMap[f.page] = append(f.textMap[f.page], textMapType{
+		entry: TextMapEntry{Page: f.page, X: x, Y: y, W: w, H: h, Text: text},
+		obj:   len(f.textObjs[f.page]) - 1,
+	})
+}
+
+// TextMap returns the text printed by OverlayText(), page by page in the
+// order it was printed. Text removed by Redact() is left out.
+func (f *Fpdf) TextMap() []TextMapEntry {
+	pages := make([]int, 0, len(f.textMap))
+	for n := range f.textMap {
+		pages = append(pages, n)
+	}
+	sort.Ints(pages)
+	var list []TextMapEntry
+	for _, n := range pages {
+		for _, tm := range f.textMap[n] {
+			if tm.obj >= 0 && tm.obj < len(f.textObjs[n]) && f.textObjs[n][tm.obj].redacted {
+				continue
+			}
+			list = append(list, tm.entry)
+		}
+	}
+	return list
+}
+
+// WriteTextMapJSON writes the text map of the document to w in JSON, with
+// every page and the boxes of its text in points from the top left corner
+// of the page:
+//
+//	{"unit":"pt","pages":[{"page":1,"width":595.28,"height":841.89,
+//	 "text":[{"x":56.69,"y":70.87,"w":120.50,"h":14.17,"text":"Invoice"}]}]}
+func (f *Fpdf) WriteTextMapJSON(w io.Writer) error {
+	byPage := f.textMapByPage()
+	var s fmtBuffer
+	s.printf(`{"unit":"pt","pages":[`)
+	for n := 1; n <= f.PageCount(); n++ {
+		if n > 1 {
+			s.WriteByte(',')
+		}
+		wd, ht := f.pageSizePt(n)
+		s.printf(`{"page":%d,"width":%.2f,"height":%.2f,"text":[`, n, wd, ht)
+		for j, e := range byPage[n] {
+			if j > 0 {
+				s.WriteByte(',')
+			}
+			s.printf(`{"x":%.2f,"y":%.2f,"w":%.2f,"h":%.2f,"text":%s}`,
+				e.X*f.k, e.Y*f.k, e.W*f.k, e.H*f.k, jsonQuote(e.Text))
+		}
+		s.WriteString("]}")
+	}
+	s.WriteString("]}\n")
+	_, err := w.Write(s.Bytes())
+	return err
+}
+
+// WriteHOCR writes the text map of the document to w as an hOCR document, the
+// HTML format of OCR results: a page element per page and a line element per
+// call of OverlayText(), with their bounding boxes in points from the top
+// left corner of the page.
+func (f *Fpdf) WriteHOCR(w io.Writer) error {
+	byPage := f.textMapByPage()
+	var s fmtBuffer
+	s.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n" +
+		"<meta name=\"ocr-system\" content=\"tinypdf\">\n" +
+		"<meta name=\"ocr-capabilities\" content=\"ocr_page ocr_line\">\n</head>\n<body>\n")
+	for n := 1; n <= f.PageCount(); n++ {
+		wd, ht := f.pageSizePt(n)
+		s.printf("<div class=\"ocr_page\" id=\"page_%d\" title=\"bbox 0 0 %d %d; ppageno %d\">\n",
+			n, round(wd), round(ht), n-1)
+		for j, e := range byPage[n] {
+			s.printf("<span class=\"ocr_line\" id=\"line_%d_%d\" title=\"bbox %d %d %d %d\">%s</span>\n",
+				n, j+1, round(e.X*f.k), round(e.Y*f.k), round((e.X+e.W)*f.k), round((e.Y+e.H)*f.k),
+				htmlEscape(e.Text))
+		}
+		s.WriteString("</div>\n")
+	}
+	s.WriteString("</body>\n</html>\n")
+	_, err := w.Write(s.Bytes())
+	return err
+}
+
+// textMapByPage returns the entries of TextMap() by page number.
+func (f *Fpdf) textMapByPage() map[int][]TextMapEntry {
+	byPage := make(map[int][]TextMapEntry)
+	for _, e := range f.TextMap() {
+		byPage[e.Page] = append(byPage[e.Page], e)
+	}
+	return byPage
+}
+
+// decodeCodepage converts s, in the code page cpStr of a font that is not
+// UTF-8 such as "cp1252", to UTF-8. The code page of the core fonts is
+// assumed if cpStr is empty or unknown.
+func decodeCodepage(s, cpStr string) string {
+	list, err := embeddedMap(cpStr)
+	if err != nil {
+		list, err = embeddedMap("cp1252")
+	}
+	runes := make([]rune, len(s))
+	for j := 0; j < len(s); j++ {
+		runes[j] = rune(s[j])
+		if err == nil && list[s[j]].uv >= 0 {
+			runes[j] = rune(list[s[j]].uv)
+		}
+	}
+	return string(runes)
+}
+
+// jsonQuote returns s as a JSON string.
+func jsonQuote(s string) string {
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '"')
+	for j := 0; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20:
+			b = append(b, sprintf("\\u%04x", c)...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return string(append(b, '"'))
+}
+
+// htmlEscape escapes the characters of s that are special in HTML.
+func htmlEscape(s string) string {
+	return Convert(s).Replace("&", "&amp;").Replace("<", "&lt;").Replace(">", "&gt;").Replace("\"", "&quot;").String()
+}
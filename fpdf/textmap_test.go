@@ -0,0 +1,159 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestOverlayText(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.Image(ImageFile("logo.png"), 50, 50, 200, 0, false, "", 0, "")
+	pdf.OverlayText(60, 60, 120, 20, "Caf\xe9 <menu>")
+	pdf.AddUTF8Font("dejavu", "", FontFile("DejaVuSansCondensed.ttf"))
+	pdf.SetFont("dejavu", "", 12)
+	pdf.OverlayText(60, 90, 80, 10, "Привет")
+	if size, _ := pdf.GetFontSize(); size != 12 {
+		t.Errorf("font size changed to %.2f", size)
+	}
+	if !strings.Contains(pdf.PageContentString(1), "q BT 3 Tr ") {
+		t.Errorf("overlay text is not invisible: %q", pdf.PageContentString(1))
+	}
+
+	entries := pdf.TextMap()
+	if len(entries) != 2 || entries[0].Text != "Café <menu>" || entries[1].Text != "Привет" {
+		t.Fatalf("got text map %+v", entries)
+	}
+	if e := entries[0]; e.Page != 1 || e.X != 60 || e.Y != 60 || e.W != 120 || e.H != 20 {
+		t.Errorf("got box %+v", e)
+	}
+
+	r := readBack(t, pdf)
+	runs, err := r.TextRuns(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got runs %+v", runs)
+	}
+	if run := runs[0]; run.Text != "Café <menu>" || run.Size != 20 || math.Abs(run.Width-120) > 0.01 ||
+		run.X != 60 || math.Abs(run.Y-(841.89-76)) > 0.01 {
+		t.Errorf("got run %+v", run)
+	}
+	if run := runs[1]; run.Text != "Привет" || math.Abs(run.Width-80) > 0.01 {
+		t.Errorf("got run %+v", run)
+	}
+}
+
+func TestTextMapSidecars(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.OverlayText(10, 20, 100, 12, `Say "hi" & go`)
+	pdf.OverlayText(10, 40, 100, 12, "Secret")
+	pdf.Redact(5, 35, 200, 20)
+	pdf.AddPage()
+	pdf.AddPage()
+	pdf.OverlayText(30, 40, 50, 10, "Last")
+	if got := len(pdf.TextMap()); got != 2 {
+		t.Errorf("got %d entries after a redaction, want 2", got)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.WriteTextMapJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Unit  string
+		Pages []struct {
+			Page          int
+			Width, Height float64
+			Text          []struct {
+				X, Y, W, H float64
+				Text       string
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	if doc.Unit != "pt" || len(doc.Pages) != 3 || doc.Pages[1].Page != 2 || len(doc.Pages[1].Text) != 0 {
+		t.Fatalf("got %+v", doc)
+	}
+	if p := doc.Pages[0]; p.Width != 595.28 || p.Height != 841.89 || len(p.Text) != 1 || p.Text[0].Text != `Say "hi" & go` {
+		t.Errorf("got page %+v", p)
+	}
+	if box := doc.Pages[2].Text[0]; box.X != 30 || box.Y != 40 || box.W != 50 || box.H != 10 {
+		t.Errorf("got box %+v", box)
+	}
+
+	buf.Reset()
+	if err := pdf.WriteHOCR(&buf); err != nil {
+		t.Fatal(err)
+	}
+	hocr := buf.String()
+	for _, want := range []string{
+		`<div class="ocr_page" id="page_1" title="bbox 0 0 595 842; ppageno 0">`,
+		`<span class="ocr_line" id="line_1_1" title="bbox 10 20 110 32">Say &quot;hi&quot; &amp; go</span>`,
+		`<span class="ocr_line" id="line_3_1" title="bbox 30 40 80 50">Last</span>`,
+	} {
+		if !strings.Contains(hocr, want) {
+			t.Errorf("hOCR lacks %s:\n%s", want, hocr)
+		}
+	}
+	if strings.Contains(hocr, "Secret") {
+		t.Error("hOCR holds redacted text")
+	}
+}
+
+func TestOverlayTextRollback(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.OverlayText(10, 10, 50, 5, "Kept")
+	c := pdf.SaveCursor()
+	pdf.OverlayText(10, 20, 50, 5, "Dropped")
+	pdf.RollbackCursor(c)
+	if entries := pdf.TextMap(); len(entries) != 1 || entries[0].Text != "Kept" {
+		t.Errorf("got text map %+v", entries)
+	}
+	pdf = NewDocPdfTest()
+	pdf.AddPage()
+	pdf.OverlayText(10, 10, 50, 5, "No font")
+	if pdf.Error() == nil {
+		t.Error("no error without a font")
+	}
+}
+
+func TestTextMapDropBlankPages(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetDropBlankPages(true)
+	pdf.AddPage() // left empty, dropped
+	pdf.AddPage()
+	pdf.OverlayText(10, 20, 100, 12, "Kept")
+	pdf.OverlayText(10, 40, 100, 12, "Secret")
+	pdf.Redact(5, 35, 200, 20)
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	entries := pdf.TextMap()
+	if len(entries) != 1 || entries[0].Text != "Kept" || entries[0].Page != 1 {
+		t.Fatalf("got text map %+v", entries)
+	}
+
+	buf.Reset()
+	if err := pdf.WriteTextMapJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `{"page":1,"width":595.28,"height":841.89,"text":[{"x":10.00,"y":20.00,"w":100.00,"h":12.00,"text":"Kept"}]}`) {
+		t.Errorf("text missing from page 1: %s", buf.String())
+	}
+}
@@ -0,0 +1,151 @@
+package fpdf
+
+import "unicode"
+
+// TextTransform selects how the letters of text are rendered, see
+// SetTextTransform().
+type TextTransform int
+
+const (
+	// TextTransformNone renders text as it is given.
+	TextTransformNone TextTransform = iota
+	// TextTransformUppercase renders text in capitals.
+	TextTransformUppercase
+	// TextTransformLowercase renders text in lowercase letters.
+	TextTransformLowercase
+	// TextTransformSmallCaps renders lowercase letters as capitals reduced
+	// to the size given by smallCapsScale, keeping the other characters.
+	TextTransformSmallCaps
+)
+
+// smallCapsScale is the size of the capitals that replace lowercase letters
+// in small caps, relative to the font size.
+const smallCapsScale = 0.75
+
+// SetTextTransform sets how the letters of the text printed with Text(),
+// Cell(), MultiCell(), Write() and the methods based on them are rendered,
+// so that a style such as the capitals of a heading need not be written in
+// the text itself. The transformation is applied when the text is printed
+// and measured, by GetStringWidth() and the methods that wrap text, and
+// stays in effect until it is changed. The default is TextTransformNone.
+//
+// Only ASCII letters are transformed in fonts that are not UTF-8 fonts.
+func (f *Fpdf) SetTextTransform(tr TextTransform) {
+	f.textTransform = tr
+}
+
+// GetTextTransform returns the transformation set with SetTextTransform().
+func (f *Fpdf) GetTextTransform() TextTransform {
+	return f.textTransform
+}
+
+// mapLetters returns s with fnc applied to its letters, only to the ASCII ones
+// if the current font is not a UTF-8 font.
+func (f *Fpdf) mapLetters(s string, fnc func(rune) rune) string {
+	if f.isCurrentUTF8 {
+		r := []rune(s)
+		for j, c := range r {
+			r[j] = fnc(c)
+		}
+		return string(r)
+	}
+	b := []byte(s)
+	for j, c := range b {
+		if c < 0x80 {
+			b[j] = byte(fnc(rune(c)))
+		}
+	}
+	return string(b)
+}
+
+// transformText returns txtStr with the uppercase or lowercase
+// transformation set with SetTextTransform() applied. Small caps are
+// rendered by textShow().
+func (f *Fpdf) transformText(txtStr string) string {
+	switch f.textTransform {
+	case TextTransformUppercase:
+		return f.mapLetters(txtStr, unicode.ToUpper)
+	case TextTransformLowercase:
+		return f.mapLetters(txtStr, unicode.ToLower)
+	}
+	return txtStr
+}
+
+// smallCap returns whether character c is rendered as a reduced capital.
+func (f *Fpdf) smallCap(c int) bool {
+	return f.textTransform == TextTransformSmallCaps && unicode.IsLower(rune(c)) &&
+		(f.isCurrentUTF8 || c < 0x80)
+}
+
+// capWidth returns the width w of character c in the current font, or the
+// width of the reduced capital that replaces it in small caps.
+func (f *Fpdf) capWidth(c int, w int) int {
+	if !f.smallCap(c) {
+		return w
+	}
+	return int(float64(runeWidth(&f.currentFont, unicode.ToUpper(rune(c))))*smallCapsScale + 0.5)
+}
+
+// textShow returns the operators that show txtStr, escaped as txt2, in a
+// text object: txt2 followed by the operator op, or, when small caps are
+// rendered or part of the text is printed with the fallback font, the strings
+// of the runs of small caps, fallback and other characters each followed by
+// op.
+func (f *Fpdf) textShow(txtStr, txt2, op string) string {
+	if f.textTransform != TextTransformSmallCaps && !f.needsFallback(txtStr) {
+		return "(" + txt2 + ")" + op
+	}
+	// Runs of lowercase letters are shown in capitals with a smaller size,
+	// and runs of characters missing from the font in the fallback font.
+	var s fmtBuffer
+	runs := []rune(txtStr)
+	if !f.isCurrentUTF8 {
+		runs = runs[:0]
+		for _, b := range []byte(txtStr) {
+			runs = append(runs, rune(b))
+		}
+	}
+	for j := 0; j < len(runs); {
+		font := f.runeFont(runs[j])
+		small := f.smallCap(int(runs[j]))
+		k := j
+		for k < len(runs) && f.runeFont(runs[k]).i == font.i && f.smallCap(int(runs[k])) == small {
+			k++
+		}
+		run := runs[j:k]
+		switch {
+		case small:
+			for n, c := range run {
+				run[n] = unicode.ToUpper(c)
+			}
+			s.printf("/F%s %.2f Tf ", f.currentFont.i, f.fontSizePt*smallCapsScale)
+		case font.i != f.currentFont.i:
+			s.printf("/F%s %.2f Tf ", font.i, f.fontSizePt)
+		}
+		s.printf("(%s)%s ", f.escapeText(run), op)
+		if small || font.i != f.currentFont.i {
+			s.printf("/F%s %.2f Tf ", f.currentFont.i, f.fontSizePt)
+		}
+		j = k
+	}
+	str := s.String()
+	if str == "" {
+		return ""
+	}
+	return str[:len(str)-1]
+}
+
+// escapeText returns the characters of run encoded and escaped for a string
+// of the current font.
+func (f *Fpdf) escapeText(run []rune) string {
+	if f.isCurrentUTF8 {
+		txtStr := string(run)
+		f.useRunes("Text", txtStr)
+		return f.escape(f.encodeText(txtStr))
+	}
+	b := make([]byte, len(run))
+	for j, c := range run {
+		b[j] = byte(c)
+	}
+	return f.escape(string(b))
+}
@@ -0,0 +1,83 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetTextTransform(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	plain := pdf.GetStringWidth("Hello")
+
+	pdf.SetTextTransform(fpdf.TextTransformUppercase)
+	if w := pdf.GetStringWidth("Hello"); w != pdf.StringWidthStyled("HELLO", "", "", 0) {
+		t.Errorf("uppercase width not measured: got=%.2f", w)
+	}
+	pdf.Text(50, 100, "upper case")
+	pdf.SetTextTransform(fpdf.TextTransformLowercase)
+	pdf.CellFormat(100, 20, "LOWER Case", "", 1, "", false, 0, "")
+	pdf.SetTextTransform(fpdf.TextTransformSmallCaps)
+	small := pdf.GetStringWidth("Hello")
+	if upper := pdf.StringWidthStyled("HELLO", "", "", 0); small <= plain || small >= upper {
+		t.Errorf("unexpected small caps width: got=%.2f, expected between %.2f and %.2f", small, plain, upper)
+	}
+	pdf.CellFormat(100, 20, "Small Caps", "", 1, "", false, 0, "")
+	if pdf.GetTextTransform() != fpdf.TextTransformSmallCaps {
+		t.Errorf("transform not kept")
+	}
+	pdf.SetTextTransform(fpdf.TextTransformNone)
+	pdf.Text(50, 300, "As given")
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"(UPPER CASE) Tj",
+		"(lower case)Tj",
+		" 9.00 Tf (MALL)Tj /F",
+		" 12.00 Tf ( C)Tj /F",
+		" 9.00 Tf (APS)Tj /F",
+		"(As given) Tj",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q", s)
+		}
+	}
+}
+
+func TestSmallCapsMultiCell(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.SetFont("dejavu", "", 12)
+	pdf.AddPage()
+	pdf.SetTextTransform(fpdf.TextTransformSmallCaps)
+	txt := strings.Repeat("small caps ", 20)
+	lines := pdf.SplitText(txt, 200)
+	y := pdf.GetY()
+	pdf.MultiCell(200, 20, txt, "", "J", false)
+	if n := int((pdf.GetY() - y) / 20); n != len(lines) {
+		t.Errorf("MultiCell printed %d lines, SplitText returned %d", n, len(lines))
+	}
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "] TJ /F") {
+		t.Errorf("justified small caps not written")
+	}
+}
@@ -42,6 +42,11 @@ func (f *Fpdf) SetModificationDate(tm time.Time) {
 	f.modDate = pdfTime(tm)
 }
 
+// nanotime returns the current time in nanoseconds.
+func nanotime() int64 {
+	return time.Now().UnixNano()
+}
+
 // returns Now() if tm is zero
 func timeOrNow(tm pdfTime) time.Time {
 	t := time.Time(tm)
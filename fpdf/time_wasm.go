@@ -44,6 +44,11 @@ func (f *Fpdf) SetModificationDate(tm int64) {
 	f.modDate = pdfTime(tm)
 }
 
+// nanotime returns the current time in nanoseconds.
+func nanotime() int64 {
+	return time.Now()
+}
+
 // returns Now() if tm is zero
 func timeOrNow(tm pdfTime) int64 {
 	if tm == 0 {
@@ -0,0 +1,177 @@
+package fpdf
+
+import (
+	"sort"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// Type3Glyph is a glyph of a font added with AddType3Font().
+type Type3Glyph struct {
+	Code  byte    // code of the character the glyph is printed for
+	Width float64 // advance width, in thousandths of the font size
+	// Draw draws the glyph on g with the drawing methods of Fpdf, such as
+	// Line(), Rect(), Circle() and the path methods. See AddType3Font().
+	Draw func(g *Fpdf)
+	// Colored is true if Draw sets its own colors. Otherwise the glyph is
+	// painted in the color of the text and Draw must not set colors.
+	Colored bool
+}
+
+// type3GlyphType is a glyph of a Type3 font with the content stream that
+// draws it.
+type type3GlyphType struct {
+	code byte
+	proc []byte
+}
+
+// type3Ascent is the ordinate of the baseline from the top of the box in
+// which a glyph of a Type3 font is drawn, in thousandths of the font size.
+const type3Ascent = 800
+
+// AddType3Font adds the font family familyStr, in style styleStr, whose
+// glyphs are drawn with the drawing methods instead of being read from a
+// font file. It is selected with SetFont() like any other font, and the
+// characters of the strings printed with it are the codes of its glyphs.
+// This makes marks repeated in the text, such as the states of a checkbox or
+// a small logo, one character each instead of a path every time.
+//
+// The Draw function of each glyph is called once, with a document g whose
+// page is the box of the glyph: Width wide and 1000 high, in points, so that
+// a point is a thousandth of the font size. The baseline is 800 from the top
+// of the box. The glyph can only draw lines, shapes and paths; text, images,
+// gradients and transparency are errors.
+//
+//	pdf.AddType3Font("marks", "", []fpdf.Type3Glyph{{
+//		Code: 'x', Width: 800,
+//		Draw: func(g *fpdf.Fpdf) {
+//			g.SetLineWidth(80)
+//			g.Rect(100, 100, 600, 600, "D")
+//			g.Line(200, 200, 600, 600)
+//			g.Line(200, 600, 600, 200)
+//		},
+//	}})
+//	pdf.SetFont("marks", "", 12)
+//	pdf.Cell(5, 5, "x")
+func (f *Fpdf) AddType3Font(familyStr, styleStr string, glyphs []Type3Glyph) {
+	if f.err != nil {
+		return
+	}
+	fontkey := getFontKey(familyStr, styleStr)
+	if _, ok := f.fonts[fontkey]; ok {
+		return
+	}
+	if len(glyphs) == 0 {
+		f.errorf("AddType3Font", "font %s has no glyphs", familyStr)
+		return
+	}
+	cw := make([]int, 256)
+	font := fontDefType{
+		Tp:   "Type3",
+		Name: fontkey,
+		Up:   -100,
+		Ut:   50,
+		Cw:   cw,
+		Enc:  "cp1252",
+	}
+	bbox := fontBoxType{Ymin: type3Ascent - 1000, Ymax: type3Ascent}
+	for _, gl := range glyphs {
+		if gl.Width <= 0 || gl.Draw == nil {
+			f.errorf("AddType3Font", "glyph %d of font %s has no width or no drawing", gl.Code, familyStr)
+			return
+		}
+		if cw[gl.Code] != 0 {
+			f.errorf("AddType3Font", "glyph %d of font %s is defined twice", gl.Code, familyStr)
+			return
+		}
+		proc, err := drawType3Glyph(gl)
+		if err != nil {
+			f.errorf("AddType3Font", "glyph %d of font %s: %v", gl.Code, familyStr, err)
+			return
+		}
+		cw[gl.Code] = round(gl.Width)
+		bbox.Xmax = max(bbox.Xmax, cw[gl.Code])
+		font.type3 = append(font.type3, type3GlyphType{code: gl.Code, proc: proc})
+	}
+	sort.Slice(font.type3, func(i, j int) bool { return font.type3[i].code < font.type3[j].code })
+	font.Desc = FontDescType{Ascent: type3Ascent, Descent: type3Ascent - 1000, CapHeight: type3Ascent - 100,
+		FontBBox: bbox, MissingWidth: 0}
+	var err error
+	if font.i, err = generateFontID(font); err != nil {
+		f.setError("AddType3Font", err)
+		return
+	}
+	f.fonts[fontkey] = font
+}
+
+// drawType3Glyph returns the content stream of glyph gl of a Type3 font.
+func drawType3Glyph(gl Type3Glyph) ([]byte, error) {
+	g := New(POINT)
+	g.SetMargins(0, 0, 0)
+	g.SetAutoPageBreak(false, 0)
+	g.AddPageFormat(Portrait, PageSize{Wd: gl.Width, Ht: 1000})
+	start := g.pages[1].Len()
+	gl.Draw(g)
+	switch {
+	case g.err != nil:
+		return nil, g.err
+	case g.page != 1:
+		return nil, Err("pages cannot be added")
+	case len(g.fonts) > 0 || len(g.images) > 0 || len(g.blendList) > 1 || len(g.gradientList) > 1 || len(g.patternList) > 0:
+		return nil, Err("only lines, shapes and paths can be drawn")
+	}
+	var proc fmtBuffer
+	if gl.Colored {
+		proc.printf("%d 0 d0\n", round(gl.Width))
+	} else {
+		proc.printf("%d 0 0 %d %d %d d1\n", round(gl.Width), type3Ascent-1000, round(gl.Width), type3Ascent)
+	}
+	// The box of the glyph is moved down so that its baseline is at 0
+	proc.printf("1 0 0 1 0 %d cm\n", type3Ascent-1000)
+	proc.Write(g.pages[1].Bytes()[start:])
+	return proc.Bytes(), nil
+}
+
+// putType3Font writes the objects of Type3 font font: the font dictionary,
+// the dictionary of its glyphs and their content streams.
+func (f *Fpdf) putType3Font(font fontDefType) {
+	list, _ := embeddedMap("cp1252")
+	names := make([]string, len(font.type3))
+	used := make(map[string]bool)
+	var diffs, widths, procs fmtBuffer
+	first, last := int(font.type3[0].code), int(font.type3[len(font.type3)-1].code)
+	for j, gl := range font.type3 {
+		// The glyphs are named after the characters of their codes so that
+		// the text can be extracted
+		names[j] = sprintf("g%d", gl.code)
+		if e := list[gl.code]; e.uv >= 0 && e.name != "" && e.name != ".notdef" && !used[e.name] {
+			names[j] = e.name
+		}
+		used[names[j]] = true
+		diffs.printf("%d /%s ", gl.code, names[j])
+	}
+	for code := first; code <= last; code++ {
+		widths.printf("%d ", font.Cw[code])
+	}
+	f.newobj()
+	f.out("<</Type /Font /Subtype /Type3")
+	f.outf("/FontBBox [%d %d %d %d]", font.Desc.FontBBox.Xmin, font.Desc.FontBBox.Ymin,
+		font.Desc.FontBBox.Xmax, font.Desc.FontBBox.Ymax)
+	f.out("/FontMatrix [0.001 0 0 0.001 0 0]")
+	f.outf("/CharProcs %d 0 R", f.n+1)
+	f.outf("/Encoding <</Type /Encoding /Differences [%s]>>", diffs.String())
+	f.outf("/FirstChar %d /LastChar %d /Widths [%s]", first, last, widths.String())
+	f.out("/Resources <<>>>>")
+	f.out("endobj")
+	f.newobj()
+	for j := range font.type3 {
+		procs.printf("/%s %d 0 R ", names[j], f.n+1+j)
+	}
+	f.outf("<<%s>>", procs.String())
+	f.out("endobj")
+	for _, gl := range font.type3 {
+		f.newobj()
+		f.putcontentstream("", gl.proc)
+		f.out("endobj")
+	}
+}
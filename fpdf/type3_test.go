@@ -0,0 +1,91 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func checkbox(checked bool) fpdf.Type3Glyph {
+	code := byte('o')
+	if checked {
+		code = 'x'
+	}
+	return fpdf.Type3Glyph{Code: code, Width: 800, Draw: func(g *fpdf.Fpdf) {
+		g.SetLineWidth(60)
+		g.Rect(100, 100, 600, 600, "D")
+		if checked {
+			g.Line(200, 200, 600, 600)
+			g.Line(200, 600, 600, 200)
+		}
+	}}
+}
+
+func TestAddType3Font(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetCompression(false)
+	pdf.AddType3Font("marks", "", []fpdf.Type3Glyph{checkbox(true), checkbox(false), {
+		Code: 'r', Width: 500, Colored: true,
+		Draw: func(g *fpdf.Fpdf) {
+			g.SetFillColor(255, 0, 0)
+			g.Circle(250, 550, 200, "F")
+		},
+	}})
+	pdf.AddPage()
+	pdf.SetFont("marks", "", 20)
+	pdf.Cell(0, 10, "xoxr")
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+	// 800 + 800 + 500 thousandths of 20 points
+	if w, want := pdf.GetStringWidth("xor"), pdf.PointConvert(42); math.Abs(w-want) > 1e-9 {
+		t.Errorf("got width %.3f, want %.3f", w, want)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"/Subtype /Type3",
+		"/Differences [111 /o 114 /r 120 /x ]",
+		"/FirstChar 111 /LastChar 120 /Widths [800 0 0 500 0 0 0 0 0 800 ]",
+		"800 0 0 -200 800 800 d1\n1 0 0 1 0 -200 cm\n60.00 w\n",
+		"500 0 d0\n",
+		"1.000 0.000 0.000 rg",
+		"(xoxr)Tj",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%q missing from the document", s)
+		}
+	}
+	r, err := fpdf.ReadPDF(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, err := r.PageText(1); err != nil || text != "xoxr" {
+		t.Errorf("got text %q, %v", text, err)
+	}
+}
+
+func TestAddType3FontErrors(t *testing.T) {
+	for name, glyphs := range map[string][]fpdf.Type3Glyph{
+		"no glyphs":  nil,
+		"no drawing": {{Code: 'a', Width: 500}},
+		"no width":   {{Code: 'a', Draw: func(g *fpdf.Fpdf) {}}},
+		"twice":      {checkbox(true), checkbox(true)},
+		"text": {{Code: 'a', Width: 500, Draw: func(g *fpdf.Fpdf) {
+			g.SetFont("Helvetica", "", 500)
+			g.Text(0, 800, "a")
+		}}},
+	} {
+		pdf := NewDocPdfTest()
+		pdf.AddType3Font("marks", "", glyphs)
+		if !pdf.Err() {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
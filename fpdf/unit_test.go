@@ -0,0 +1,125 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	fpdf "github.com/tinywasm/pdf/fpdf"
+)
+
+func TestWithUnit(t *testing.T) {
+	pdf := NewDocPdfTest() // millimeters
+	pdf.AddPage()
+	pdf.SetXY(25.4, 50.8)
+
+	pdf.WithUnit(fpdf.POINT, func() {
+		x, y := pdf.GetXY()
+		if math.Abs(x-72) > 1e-9 || math.Abs(y-144) > 1e-9 {
+			t.Errorf("position not converted to points: got=(%v, %v), want=(72, 144)", x, y)
+		}
+		if _, _, unitStr := pdf.PageSize(0); unitStr != "pt" {
+			t.Errorf("unit not switched: got=%s, want=pt", unitStr)
+		}
+		pdf.Rect(72, 72, 144, 36, "D")
+		pdf.SetXY(144, 72)
+	})
+
+	x, y := pdf.GetXY()
+	if math.Abs(x-50.8) > 1e-9 || math.Abs(y-25.4) > 1e-9 {
+		t.Errorf("position not converted back: got=(%v, %v), want=(50.8, 25.4)", x, y)
+	}
+	if _, _, unitStr := pdf.PageSize(0); unitStr != "mm" {
+		t.Errorf("unit not restored: got=%s, want=mm", unitStr)
+	}
+
+	pdf.WithUnit("furlong", func() {})
+	if pdf.Error() == nil {
+		t.Errorf("expecting error for invalid unit")
+	}
+}
+
+func TestRectU(t *testing.T) {
+	pdf := NewDocPdfTest() // millimeters
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.RectU(fpdf.POINT, 72, 72, 144, 36, "D")
+	pdf.Rect(25.4, 25.4, 50.8, 12.7, "D")
+	pdf.LineU(fpdf.IN, 1, 1, 2, 2)
+	if _, _, unitStr := pdf.PageSize(0); unitStr != "mm" {
+		t.Errorf("unit not restored: got=%s, want=mm", unitStr)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rect := []byte("72.00 769.89 144.00 -36.00 re S")
+	if n := bytes.Count(buf.Bytes(), rect); n != 2 {
+		t.Errorf("got %d rectangles at %s, want 2", n, rect)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("72.00 769.89 m 144.00 697.89 l S")) {
+		t.Errorf("line not drawn in inches")
+	}
+}
+
+func TestWithUnitStoredValues(t *testing.T) {
+	pdf := NewDocPdfTest() // millimeters
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.Rect(25.4, 25.4, 25.4, 25.4, "D")
+	pdf.OverlayText(25.4, 76.2, 50.8, 5, "overlay")
+	id := pdf.AddLinearGradientPaint(25.4, 25.4, 25.4, 25.4, 0, 0, 1, 0,
+		fpdf.GradientOptions{Stops: []fpdf.GradientStop{{Pos: 0, R: 255}, {Pos: 1, B: 255}}})
+	pdf.SetFillGradient(id)
+
+	pdf.WithUnit(fpdf.POINT, func() {
+		if box, ok := pdf.GetContentExtents(1); !ok || math.Abs(box.X-72) > 1e-9 || math.Abs(box.Y-72) > 1e-9 {
+			t.Errorf("content extents not converted to points: got %+v", box)
+		}
+		if m := pdf.TextMap(); len(m) != 1 || math.Abs(m[0].X-72) > 1e-9 || math.Abs(m[0].Y-216) > 1e-9 {
+			t.Errorf("text map not converted to points: got %+v", m)
+		}
+		pdf.SetFillGradient(id)
+	})
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("/P2 ")) {
+		t.Errorf("gradient paint not converted to points: a second pattern was added")
+	}
+}
+
+func TestWithUnitPaddingAndCursor(t *testing.T) {
+	pdf := NewDocPdfTest() // millimeters
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.SetTextBackgroundColor(255, 255, 0)
+	pdf.SetTextBackgroundPadding(2.54)
+	pdf.SetXY(25.4, 50.8)
+	saved := pdf.SaveCursor()
+
+	pdf.WithUnit(fpdf.POINT, func() {
+		pdf.Text(72, 144, "Padded")
+		pdf.RestoreCursor(saved)
+		if x, y := pdf.GetXY(); math.Abs(x-72) > 1e-9 || math.Abs(y-144) > 1e-9 {
+			t.Errorf("cursor saved in millimeters not restored in points: got (%v, %v)", x, y)
+		}
+		pdf.SetXY(144, 216)
+		saved = pdf.SaveCursor()
+	})
+	pdf.RestoreCursor(saved)
+	if x, y := pdf.GetXY(); math.Abs(x-50.8) > 1e-9 || math.Abs(y-76.2) > 1e-9 {
+		t.Errorf("cursor saved in points not restored in millimeters: got (%v, %v)", x, y)
+	}
+
+	// The padding of 2.54 mm is 7.2 points on each side of the text.
+	if content := pdf.PageContentString(1); !strings.Contains(content, "64.80 ") {
+		t.Errorf("text background padding not converted to points: %s", content)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"math"
 	"sort"
+	"unicode"
 
 	. "github.com/tinywasm/fmt"
 )
@@ -40,6 +41,8 @@ type utf8FontFile struct {
 	DefaultWidth         float64
 	symbolData           map[int]map[string][]int
 	CodeSymbolDictionary map[int]int
+	smpCIDs              map[int]int // codes of the characters beyond the BMP, see assignSMPCIDs
+	postScriptName       string      // PostScript name of the name table, empty if none
 }
 
 type tableDescription struct {
@@ -314,6 +317,7 @@ func (utf *utf8FontFile) parseNAMETable() int {
 			}
 		}
 	}
+	utf.postScriptName = names[6]
 	return format
 }
 
@@ -474,6 +478,7 @@ func (utf *utf8FontFile) parseTables() {
 	symbolCharDictionary := make(map[int][]int)
 	charSymbolDictionary := make(map[int]int)
 	utf.generateSCCSDictionaries(runeCMAPPosition, symbolCharDictionary, charSymbolDictionary)
+	utf.assignSMPCIDs(symbolCharDictionary, charSymbolDictionary)
 
 	scale := 1000.0 / float64(utf.fontElementSize)
 	utf.parseHMTXTable(n, numSymbols, symbolCharDictionary, scale)
@@ -507,6 +512,7 @@ func (utf *utf8FontFile) generateCMAP() map[int][]int {
 	symbolCharDictionary := make(map[int][]int)
 	charSymbolDictionary := make(map[int]int)
 	utf.generateSCCSDictionaries(runeCmapPosition, symbolCharDictionary, charSymbolDictionary)
+	utf.assignSMPCIDs(symbolCharDictionary, charSymbolDictionary)
 
 	utf.charSymbolDictionary = charSymbolDictionary
 
@@ -905,6 +911,60 @@ func (utf *utf8FontFile) parseLOCATable(format, numSymbols int) {
 	}
 }
 
+// smpCIDFirst is the first code given to the characters beyond the Basic
+// Multilingual Plane, such as most emoji. The codes of a UTF-8 font are two
+// bytes long, so these characters get the codes that the font does not use
+// for a character of its own, starting with those of the surrogates, which
+// are not characters.
+const smpCIDFirst = 0xd800
+
+// assignSMPCIDs adds the characters beyond the Basic Multilingual Plane that
+// the format 12 cmap subtable of the font maps to glyphs, if any, to the
+// dictionaries, under the codes recorded in smpCIDs. The codes are assigned in
+// the order of the characters, so they are the same each time the font file
+// is parsed.
+func (utf *utf8FontFile) assignSMPCIDs(symbolCharDictionary map[int][]int, charSymbolDictionary map[int]int) {
+	utf.smpCIDs = make(map[int]int)
+	table, ok := utf.tableDescriptions["cmap"]
+	if !ok || table.position+table.size > len(utf.fileReader.array) {
+		return
+	}
+	cmap := utf.fileReader.array[table.position : table.position+table.size]
+	r := &sfntReader{b: cmap}
+	r.u16() // version
+	position := 0
+	for count := r.u16(); count > 0 && r.err == nil; count-- {
+		platform, encoding, offset := r.u16(), r.u16(), r.u32()
+		if (platform == 3 && encoding == 10) || platform == 0 {
+			if offset+2 <= len(cmap) && binary.BigEndian.Uint16(cmap[offset:]) == 12 {
+				position = offset
+				break
+			}
+		}
+	}
+	if position == 0 || r.err != nil {
+		return
+	}
+	r.pos = position + 12
+	cid := smpCIDFirst
+	for groups := r.u32(); groups > 0 && r.err == nil; groups-- {
+		first, last, symbol := r.u32(), r.u32(), r.u32()
+		for char := max(first, 0x10000); char <= last && char <= unicode.MaxRune; char++ {
+			for _, used := charSymbolDictionary[cid]; used; _, used = charSymbolDictionary[cid] {
+				cid++
+			}
+			if cid >= 0xfffe {
+				return
+			}
+			glyph := symbol + char - first
+			charSymbolDictionary[cid] = glyph
+			symbolCharDictionary[glyph] = append(symbolCharDictionary[glyph], cid)
+			utf.smpCIDs[char] = cid
+			cid++
+		}
+	}
+}
+
 func (utf *utf8FontFile) generateSCCSDictionaries(runeCmapPosition int, symbolCharDictionary map[int][]int, charSymbolDictionary map[int]int) {
 	maxRune := 0
 	utf.seek(runeCmapPosition + 2)
@@ -71,6 +71,12 @@ func utf8toutf16(s string, withBOM ...bool) string {
 		c1 := byte(s[i])
 		i++
 		switch {
+		case c1 >= 240:
+			// 4-byte character, beyond the BMP: surrogate pair
+			r := (rune(c1&0x07)<<18 | rune(s[i]&0x3F)<<12 | rune(s[i+1]&0x3F)<<6 | rune(s[i+2]&0x3F)) - 0x10000
+			i += 3
+			hi, lo := 0xD800+r>>10, 0xDC00+r&0x3FF
+			res = append(res, byte(hi>>8), byte(hi), byte(lo>>8), byte(lo))
 		case c1 >= 224:
 			// 3-byte character
 			c2 := byte(s[i])
@@ -221,17 +227,19 @@ func (f *Fpdf) UnicodeTranslatorFromDescriptor(cpStr string) (rep func(string) s
 		emb, err := embFS.Open("font_embed/" + cpStr + ".map")
 		if err == nil {
 			defer emb.Close()
-			rep, f.err = UnicodeTranslator(emb)
+			rep, err = UnicodeTranslator(emb)
 		} else {
 			// Use f.readFile to read the font descriptor file
 			var data []byte
-			data, f.err = f.readFile(PathJoin(f.fontsPath, cpStr, ".map").String())
-			if f.err == nil {
-				rep, f.err = UnicodeTranslatorFromBytes(data)
-			} else {
-				rep = doNothing
+			data, err = f.readFile(PathJoin(f.fontsPath, cpStr, ".map").String())
+			if err == nil {
+				rep, err = UnicodeTranslatorFromBytes(data)
 			}
 		}
+		if err != nil {
+			f.setError("UnicodeTranslatorFromDescriptor", err)
+			rep = doNothing
+		}
 	} else {
 		rep = doNothing
 	}
@@ -403,11 +411,3 @@ func isChinese(rune2 rune) bool {
 	}
 	return false
 }
-
-// Condition font family string to PDF name compliance. See section 5.3 (Names)
-// in https://resources.infosecinstitute.com/pdf-file-format-basic-structure/
-func fontFamilyEscape(familyStr string) (escStr string) {
-	escStr = Convert(familyStr).Replace(" ", "#20", -1).String()
-	// Additional replacements can take place here
-	return
-}
@@ -0,0 +1,118 @@
+package fpdf
+
+import (
+	"math"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// SetStrictValidation enables or disables the strict validation of the
+// values passed to the methods that position, draw and color. When it is
+// enabled, coordinates and lengths that are not finite numbers, negative
+// widths, heights, radii, line widths and font sizes, and color components
+// out of their range set the error of the document, naming the method and
+// the argument, instead of being written as invalid operators or quietly
+// clamped. RGB components range from 0 to 255, CMYK components and tints of
+// spot colors from 0 to 100.
+//
+// The content of the pages is also checked when the document is closed, so
+// that a number that is not finite written by any method, for instance the
+// size of an image computed from a zero dimension, is reported rather than
+// output.
+//
+// Validation is disabled by default.
+func (f *Fpdf) SetStrictValidation(strict bool) {
+	f.strict = strict
+}
+
+// strictFinite reports whether vals, the arguments of method named by the
+// words of names, are finite numbers. If one is not and strict validation is
+// enabled, the error of method is set. Without strict validation, it always
+// reports true.
+func (f *Fpdf) strictFinite(method, names string, vals ...float64) bool {
+	return f.strictNumbers(method, names, false, vals)
+}
+
+// strictLength is strictFinite for lengths, which cannot be negative either.
+func (f *Fpdf) strictLength(method, names string, vals ...float64) bool {
+	return f.strictNumbers(method, names, true, vals)
+}
+
+func (f *Fpdf) strictNumbers(method, names string, length bool, vals []float64) bool {
+	if !f.strict {
+		return true
+	}
+	for j, v := range vals {
+		switch {
+		case math.IsNaN(v) || math.IsInf(v, 0):
+			f.errorf(method, "%s is %s, not a finite number", Convert(names).Split()[j], f.fmtF64(v, 2))
+			return false
+		case length && v < 0:
+			f.errorf(method, "%s is negative: %s", Convert(names).Split()[j], f.fmtF64(v, 2))
+			return false
+		}
+	}
+	return true
+}
+
+// strictColor reports whether the color components comps, the arguments of
+// method named by the words of names, range from 0 to max. If one does not
+// and strict validation is enabled, the error of method is set. Without
+// strict validation, it always reports true.
+func (f *Fpdf) strictColor(method, names string, max int, comps ...int) bool {
+	if !f.strict {
+		return true
+	}
+	for j, v := range comps {
+		if v < 0 || v > max {
+			f.errorf(method, "color component %s is %d, out of the range 0 to %d", Convert(names).Split()[j], v, max)
+			return false
+		}
+	}
+	return true
+}
+
+// checkContent sets an error if the content of a page holds a number that is
+// not finite, when strict validation is enabled.
+func (f *Fpdf) checkContent() {
+	if !f.strict {
+		return
+	}
+	for n := 1; n <= f.page; n++ {
+		if w := nonFiniteNumber(f.pages[n].Bytes()); w != "" {
+			f.errorf("Close", "page %d: content holds the number %s, which is not finite", n, w)
+			return
+		}
+	}
+}
+
+// nonFiniteNumber returns the first word of the content stream data that is
+// a number that is not finite, as appendFloat() writes it, or "" if there is
+// none. The strings, names and inline images of data are skipped.
+func nonFiniteNumber(data []byte) string {
+	l := pdfLexer{data: data}
+	for {
+		l.skipSpace()
+		if l.pos >= len(data) {
+			return ""
+		}
+		switch c := data[l.pos]; {
+		case c == '(':
+			l.literalString()
+		case c == '<' && (l.pos+1 == len(data) || data[l.pos+1] != '<'):
+			l.hexString()
+		case c == '/':
+			l.pos++
+			l.word()
+		case isPDFDelim(c):
+			l.pos++
+		default:
+			switch w := l.word(); w {
+			case "NaN", "+Inf", "-Inf":
+				return w
+			case "BI":
+				l.skipInlineImage()
+			}
+		}
+	}
+}
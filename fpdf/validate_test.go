@@ -0,0 +1,65 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestSetStrictValidation(t *testing.T) {
+	for _, c := range []struct {
+		name, method, want string
+		draw               func(pdf *fpdf.Fpdf)
+	}{
+		{"coordinate", "SetXY", "x is NaN", func(pdf *fpdf.Fpdf) { pdf.SetXY(math.NaN(), 10) }},
+		{"line", "Line", "y2 is +Inf", func(pdf *fpdf.Fpdf) { pdf.Line(10, 10, 20, math.Inf(1)) }},
+		{"width", "Rect", "w is negative", func(pdf *fpdf.Fpdf) { pdf.Rect(10, 10, -5, 5, "D") }},
+		{"cell", "CellFormat", "w is negative", func(pdf *fpdf.Fpdf) { pdf.CellFormat(-20, 5, "x", "", 0, "", false, 0, "") }},
+		{"line width", "SetLineWidth", "width is negative", func(pdf *fpdf.Fpdf) { pdf.SetLineWidth(-1) }},
+		{"font size", "SetFontSize", "size is -Inf", func(pdf *fpdf.Fpdf) { pdf.SetFontSize(math.Inf(-1)) }},
+		{"rgb", "SetFillColor", "color component g is 300", func(pdf *fpdf.Fpdf) { pdf.SetFillColor(0, 300, 0) }},
+		{"cmyk", "AddSpotColor", "color component k is 120", func(pdf *fpdf.Fpdf) { pdf.AddSpotColor("ink", 0, 0, 0, 120) }},
+		{"image", "ImageOptions", "h is NaN", func(pdf *fpdf.Fpdf) {
+			pdf.ImageOptions(ImageFile("logo.png"), 10, 10, 30, math.NaN(), false, fpdf.ImageOptions{}, 0, "")
+		}},
+		{"content", "Close", "page 1: content holds the number NaN", func(pdf *fpdf.Fpdf) {
+			pdf.Polygon([]fpdf.PointType{{X: 10, Y: 10}, {X: math.NaN(), Y: 20}, {X: 30, Y: 10}}, "D")
+		}},
+	} {
+		for _, strict := range []bool{false, true} {
+			pdf := NewDocPdfTest()
+			pdf.SetStrictValidation(strict)
+			pdf.AddPage()
+			pdf.SetFont("Helvetica", "", 12)
+			c.draw(pdf)
+			err := pdf.Output(&bytes.Buffer{})
+			switch {
+			case !strict && err != nil:
+				t.Errorf("%s: error without strict validation: %v", c.name, err)
+			case strict && (err == nil || !strings.HasPrefix(err.Error(), c.method+":") || !strings.Contains(err.Error(), c.want)):
+				t.Errorf("%s: got error %v, want %q from %s", c.name, err, c.want, c.method)
+			}
+		}
+	}
+}
+
+func TestSetStrictValidationValid(t *testing.T) {
+	pdf := NewDocPdfTest()
+	pdf.SetStrictValidation(true)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetFillColor(255, 0, 0)
+	pdf.SetXY(20, 20)
+	// Words that read like numbers that are not finite in text and names
+	pdf.CellFormat(0, 10, "NaN +Inf -Inf", "1", 1, "", true, 0, "")
+	pdf.AddSpotColor("NaN", 0, 50, 100, 0)
+	pdf.SetDrawSpotColor("NaN", 100)
+	pdf.Circle(50, 50, 10, "D")
+	pdf.ImageOptions(ImageFile("logo.png"), 10, 80, 30, 0, false, fpdf.ImageOptions{}, 0, "")
+	if err := pdf.Output(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,199 @@
+package fpdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// verticalBaseline is the distance, as a fraction of the font size, from the
+// top of the em square of an upright CJK character to its baseline.
+const verticalBaseline = 0.88
+
+// verticalRotated lists the CJK characters whose horizontal forms are turned
+// a quarter turn clockwise in vertical writing: brackets, dashes and the
+// prolonged sound mark.
+const verticalRotated = "「」『』（）［］｛｝〈〉《》【】〔〕〖〗〘〙〚〛()[]{}ー－～〜…‥—―–"
+
+// verticalCorner lists the punctuation marks that are moved from the bottom
+// left of the em square, where horizontal fonts draw them, to its top right.
+const verticalCorner = "、。，．"
+
+// verticalSmall lists the small kana, which are moved slightly towards the
+// top right of the em square.
+const verticalSmall = "ぁぃぅぇぉっゃゅょゎゕゖァィゥェォッャュョヮヵヶㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ"
+
+// verticalNoStart lists the characters that must not begin a column. When one
+// of them falls at the bottom of a column, it is hung below the column rather
+// than moved to the next one.
+const verticalNoStart = "、。，．」』）］｝〉》】〕〗〙〛)]}!?！？ーぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ々ゝゞヽヾ・：；"
+
+// isCJK returns whether r is written upright in vertical writing: the
+// ideographs, kana, hangul and fullwidth forms.
+func isCJK(r rune) bool {
+	switch {
+	case r >= 0x1100 && r <= 0x11ff, // Hangul Jamo
+		r >= 0x2e80 && r <= 0x2fdf, // CJK radicals
+		r >= 0x3000 && r <= 0x303f, // CJK symbols and punctuation
+		r >= 0x3040 && r <= 0x30ff, // Hiragana and Katakana
+		r >= 0x3100 && r <= 0x31ff, // Bopomofo, Kanbun, Katakana extensions
+		r >= 0x3200 && r <= 0x4dbf, // enclosed CJK, CJK extension A
+		isChinese(r),
+		r >= 0x9fa6 && r <= 0x9fff, // end of the CJK unified ideographs
+		r >= 0xac00 && r <= 0xd7af, // Hangul syllables
+		r >= 0xf900 && r <= 0xfaff, // CJK compatibility ideographs
+		r >= 0xff01 && r <= 0xff60, // fullwidth forms
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x20000 && r <= 0x3ffff: // CJK extensions B and later
+		return true
+	}
+	return false
+}
+
+// verticalUnit is a piece of text that is laid out as a whole in a column.
+type verticalUnit struct {
+	text    string
+	kind    int // verticalUpright, verticalSideways or verticalAcross
+	advance float64
+}
+
+const (
+	verticalUpright  = iota // a CJK character, standing upright
+	verticalSideways        // a run of other text, turned clockwise
+	verticalAcross          // one or two digits set across the column
+)
+
+// verticalUnits splits a line of txtStr into the units of vertical writing:
+// CJK characters, which stand upright, and runs of other text, such as Latin
+// words, which are turned a quarter turn clockwise. Numbers of one or two
+// digits are set horizontally across the column (tate-chū-yoko).
+func (f *Fpdf) verticalUnits(line string) (units []verticalUnit) {
+	runes := []rune(line)
+	for j := 0; j < len(runes); {
+		r := runes[j]
+		if isCJK(r) && !Contains(verticalRotated, string(r)) {
+			units = append(units, verticalUnit{string(r), verticalUpright, f.fontSize})
+			j++
+			continue
+		}
+		// A rotated CJK character is a run of its own.
+		k := j + 1
+		if !isCJK(r) {
+			for k < len(runes) && !isCJK(runes[k]) {
+				k++
+			}
+		}
+		run := string(runes[j:k])
+		if k-j <= 2 && isDigits(run) {
+			units = append(units, verticalUnit{run, verticalAcross, f.fontSize})
+		} else {
+			units = append(units, verticalUnit{run, verticalSideways, f.GetStringWidth(run)})
+		}
+		j = k
+	}
+	return
+}
+
+// VerticalText lays out txtStr in vertical writing mode, as used for
+// Japanese, Chinese and Korean text, inside the box of width w and height h
+// whose upper left corner is (x, y). Characters are written from top to
+// bottom in columns that follow each other from right to left, the first
+// column being at the right edge of the box. Columns are lineHt apart, and a
+// newline in txtStr starts a new column.
+//
+// CJK characters stand upright. Other text, such as Latin words, is turned a
+// quarter turn clockwise, except numbers of one or two digits, which are set
+// across the column. Brackets, dashes and the prolonged sound mark are turned
+// too, and the ideographic comma and full stop and the small kana are moved
+// to the top right of their square, where vertical writing places them.
+// Closing punctuation and small kana never begin a column: they hang below
+// the column instead. A word that is too long for a column is cut.
+//
+// The text is drawn with Text(), so a UTF-8 font with CJK glyphs must have
+// been selected with SetFont(). Unlike Text(), the current position is not
+// used. VerticalText returns the text that did not fit in the box, so that it
+// can be continued on another page; it is empty if all the text was written.
+func (f *Fpdf) VerticalText(x, y, w, h, lineHt float64, txtStr string) (rest string) {
+	if lineHt <= 0 {
+		lineHt = f.fontSize * 1.5
+	}
+	lines := Convert(Convert(txtStr).Replace("\r", "").String()).Split("\n")
+	cx := x + w - lineHt/2 // center of the current column
+	for n, line := range lines {
+		units := f.verticalUnits(line)
+		// An empty line takes a column too.
+		for first := true; first || len(units) > 0; first = false {
+			if cx-lineHt/2 < x-0.001 {
+				return f.verticalRest(units, lines[n+1:])
+			}
+			units = f.verticalColumn(cx, y, h, units)
+			cx -= lineHt
+		}
+	}
+	return ""
+}
+
+// verticalColumn draws as many of units as fit in the column of height h
+// centered on cx, starting at y, and returns the units that remain.
+func (f *Fpdf) verticalColumn(cx, y, h float64, units []verticalUnit) []verticalUnit {
+	top := y
+	for j, u := range units {
+		if top+u.advance > y+h+0.001 && top > y {
+			if !(u.kind == verticalUpright && Contains(verticalNoStart, u.text)) {
+				return units[j:]
+			}
+		}
+		if u.kind == verticalSideways && u.advance > h {
+			// cut the part that fits in an empty column
+			runes := []rune(u.text)
+			k := len(runes)
+			for k > 1 && f.GetStringWidth(string(runes[:k])) > y+h-top {
+				k--
+			}
+			f.verticalDraw(cx, top, verticalUnit{string(runes[:k]), u.kind, 0})
+			cut := verticalUnit{string(runes[k:]), u.kind, f.GetStringWidth(string(runes[k:]))}
+			return append([]verticalUnit{cut}, units[j+1:]...)
+		}
+		f.verticalDraw(cx, top, u)
+		top += u.advance
+	}
+	return nil
+}
+
+// verticalDraw draws the unit u in the column centered on cx, with the top of
+// its square at top.
+func (f *Fpdf) verticalDraw(cx, top float64, u verticalUnit) {
+	fs := f.fontSize
+	switch u.kind {
+	case verticalUpright:
+		x, y := cx-f.GetStringWidth(u.text)/2, top+verticalBaseline*fs
+		switch {
+		case Contains(verticalCorner, u.text):
+			x, y = x+fs/2, y-fs/2
+		case Contains(verticalSmall, u.text):
+			x, y = x+0.1*fs, y-0.1*fs
+		}
+		f.Text(x, y, u.text)
+	case verticalAcross:
+		f.Text(cx-f.GetStringWidth(u.text)/2, top+verticalBaseline*fs, u.text)
+	default:
+		// The baseline of turned text is left of the center of the column
+		// so that the glyphs, from descent to ascent, are centered on it.
+		x := cx - 0.3*fs
+		f.TransformBegin()
+		f.TransformRotate(-90, x, top)
+		f.Text(x, top, u.text)
+		f.TransformEnd()
+	}
+}
+
+// verticalRest returns the text of units and lines that has not been written
+// by VerticalText().
+func (f *Fpdf) verticalRest(units []verticalUnit, lines []string) string {
+	var s string
+	for _, u := range units {
+		s += u.text
+	}
+	for _, line := range lines {
+		s += "\n" + line
+	}
+	return s
+}
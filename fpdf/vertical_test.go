@@ -0,0 +1,59 @@
+package fpdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestVerticalText(t *testing.T) {
+	pdf := NewDocPdfTest(fpdf.POINT)
+	pdf.SetCompression(false)
+	pdf.AddUTF8Font("dejavu", "", "DejaVuSansCondensed.ttf")
+	pdf.SetFont("dejavu", "", 10)
+	pdf.AddPage()
+	// Three columns are needed but the box holds two.
+	rest := pdf.VerticalText(100, 100, 40, 200, 20, "ab\nあ、12\nrest")
+	if rest != "rest" {
+		t.Errorf("unexpected rest: %q", rest)
+	}
+	if rest = pdf.VerticalText(100, 100, 40, 200, 20, "ab"); rest != "" {
+		t.Errorf("unexpected rest: %q", rest)
+	}
+	// A column that is too short continues in the next one.
+	if rest = pdf.VerticalText(200, 100, 20, 25, 20, "あいうえ"); rest != "うえ" {
+		t.Errorf("unexpected rest: %q", rest)
+	}
+	// The full stop hangs below a full column.
+	if rest = pdf.VerticalText(300, 100, 20, 20, 20, "あい。う"); rest != "う" {
+		t.Errorf("unexpected rest: %q", rest)
+	}
+	if pdf.Err() {
+		t.Fatal(pdf.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// The Latin run of the first column is turned clockwise, its baseline
+	// left of the center of the column.
+	if !strings.Contains(out, "0.00000 -1.00000 1.00000 0.00000 ") {
+		t.Errorf("Latin run not rotated")
+	}
+	if !strings.Contains(out, "BT 127.00 741.89 Td") {
+		t.Errorf("Latin run not at the right of the box")
+	}
+	// The second column starts with an upright character, on the baseline
+	// of its square.
+	if !strings.Contains(out, " 733.09 Td") {
+		t.Errorf("upright character not on the baseline")
+	}
+	// The comma is moved to the top of its square.
+	if !strings.Contains(out, " 728.09 Td") {
+		t.Errorf("comma not moved to the top")
+	}
+}
@@ -0,0 +1,194 @@
+package pdf
+
+import (
+	"strings"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+// inlineItem is a word or an image collected while in inline mode.
+type inlineItem struct {
+	// text
+	text   string
+	family string
+	style  string
+	size   float64 // font size in points
+	color  Color
+	spaceW float64 // width of the following space, 0 if none
+
+	// image
+	image string
+	imgW  float64
+	imgH  float64
+
+	w float64 // width in user units, excluding the trailing space
+}
+
+// inlineLayout collects the fragments of an Inline block until EndInline
+// lays them out.
+type inlineLayout struct {
+	items []inlineItem
+
+	// font and text color in effect when Inline was called, restored by
+	// EndInline
+	family string
+	style  string
+	size   float64
+	r      int
+	g      int
+	b      int
+}
+
+// Inline starts inline mode: text added with AddText and images added with
+// AddImage are collected instead of being drawn as separate blocks. EndInline
+// then renders them on shared lines, aligned on a common baseline and wrapped
+// at the right margin.
+//
+//	doc.Inline()
+//	doc.AddText("Status:").Draw()
+//	doc.AddImage("ok-icon").Height(4).Draw()
+//	doc.AddText("approved").Bold().Draw()
+//	doc.EndInline()
+func (d *Document) Inline() *Document {
+	if d.inline == nil {
+		f := d.internal
+		layout := &inlineLayout{family: f.GetFontFamily(), style: f.GetFontStyle()}
+		layout.size, _ = f.GetFontSize()
+		layout.r, layout.g, layout.b = f.GetTextColor()
+		d.inline = layout
+	}
+	return d
+}
+
+// EndInline renders the fragments collected since Inline and moves to the
+// start of the next line.
+func (d *Document) EndInline() *Document {
+	layout := d.inline
+	if layout == nil {
+		return d
+	}
+	d.inline = nil
+
+	f := d.internal
+	pageW, _ := f.GetPageSize()
+	lMargin, _, rMargin, _ := f.GetMargins()
+	right := pageW - rMargin
+
+	x := f.GetX()
+	y := f.GetY()
+	items := layout.items
+	for len(items) > 0 {
+		n := inlineLineLen(items, right-x)
+		line := items[:n]
+		items = items[n:]
+
+		ascent, descent := inlineLineMetrics(f, line)
+		if y2 := inlineBreak(f, y, ascent+descent); y2 != y {
+			x, y = f.GetX(), y2
+		}
+		baseline := y + ascent
+		for _, it := range line {
+			if it.image != "" {
+				f.Image(it.image, x, baseline-it.imgH, it.imgW, it.imgH, false, "", 0, "")
+			} else {
+				f.SetFont(it.family, it.style, it.size)
+				f.SetTextColor(it.color.R, it.color.G, it.color.B)
+				f.Text(x, baseline, it.text)
+			}
+			x += it.w + it.spaceW
+		}
+		y = baseline + descent
+		x = lMargin
+	}
+
+	if layout.family != "" {
+		f.SetFont(layout.family, layout.style, layout.size)
+	}
+	f.SetTextColor(layout.r, layout.g, layout.b)
+	f.SetXY(lMargin, y)
+	return d
+}
+
+// addText splits text into words carrying the given font, measured without
+// changing the current font of the document.
+func (l *inlineLayout) addText(f *fpdf.Fpdf, text, family, style string, size float64, color Color) {
+	spaceW := f.StringWidthStyled(" ", family, style, size)
+	words := strings.Fields(text)
+	for j, word := range words {
+		it := inlineItem{
+			text:   word,
+			family: family,
+			style:  style,
+			size:   size,
+			color:  color,
+			w:      f.StringWidthStyled(word, family, style, size),
+		}
+		if j < len(words)-1 || strings.HasSuffix(text, " ") {
+			it.spaceW = spaceW
+		}
+		l.items = append(l.items, it)
+	}
+}
+
+// addImage appends an image whose missing dimension is derived from the
+// aspect ratio of the image.
+func (l *inlineLayout) addImage(f *fpdf.Fpdf, name string, w, h float64) {
+	info := f.RegisterImageOptions(name, fpdf.ImageOptions{ReadDpi: true})
+	if info == nil {
+		return
+	}
+	switch {
+	case w == 0 && h == 0:
+		w, h = info.Width(), info.Height()
+	case w == 0:
+		w = h * info.Width() / info.Height()
+	case h == 0:
+		h = w * info.Height() / info.Width()
+	}
+	l.items = append(l.items, inlineItem{image: name, imgW: w, imgH: h, w: w})
+}
+
+// inlineLineLen returns how many items fit within width. At least one item
+// is always placed so that an oversized word or image cannot stall the
+// layout.
+func inlineLineLen(items []inlineItem, width float64) int {
+	var used, pending float64
+	for j, it := range items {
+		if j > 0 && used+pending+it.w > width {
+			return j
+		}
+		used += pending + it.w
+		pending = it.spaceW
+	}
+	return len(items)
+}
+
+// inlineLineMetrics returns the height above and below the baseline needed
+// by a line. Text uses the same proportions as a cell of 1.2 times the font
+// size; images sit on the baseline.
+func inlineLineMetrics(f *fpdf.Fpdf, line []inlineItem) (ascent, descent float64) {
+	k := f.GetConversionRatio()
+	for _, it := range line {
+		if it.image != "" {
+			ascent = max(ascent, it.imgH)
+			continue
+		}
+		size := it.size / k
+		ascent = max(ascent, 0.9*size)
+		descent = max(descent, 0.3*size)
+	}
+	return
+}
+
+// inlineBreak starts a new page when a line of height h does not fit below
+// y, returning the ordinate where the line starts.
+func inlineBreak(f *fpdf.Fpdf, y, h float64) float64 {
+	auto, bMargin := f.GetAutoPageBreak()
+	_, pageH := f.GetPageSize()
+	f.SetY(y)
+	if !auto || y+h <= pageH-bMargin || f.AtPageTop() {
+		return y
+	}
+	f.AddPage()
+	return f.GetY()
+}
@@ -0,0 +1,67 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	doc := NewDocument()
+	doc.AddPage()
+	f := doc.internal
+	_, top := f.GetXY()
+
+	doc.Inline()
+	doc.AddText("Status:").Draw()
+	doc.AddImage("fpdf/image/logo.png").Height(6).Draw()
+	doc.AddText("approved").Bold().Draw()
+	doc.EndInline()
+
+	x, y := f.GetXY()
+	lMargin, _, _, _ := f.GetMargins()
+	if x != lMargin {
+		t.Errorf("expecting x at left margin after EndInline, got %v", x)
+	}
+	if oneLine := y - top; oneLine < 6 || oneLine > 12 {
+		t.Errorf("expecting a single line sized by the image, got height %v", oneLine)
+	}
+
+	doc.Inline()
+	for i := 0; i < 40; i++ {
+		doc.AddText("wrapping words ").Draw()
+	}
+	doc.EndInline()
+	if _, y2 := f.GetXY(); y2-y < 10 {
+		t.Errorf("expecting the fragments to wrap onto several lines, got height %v", y2-y)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+}
+
+func TestInlineRestoresFont(t *testing.T) {
+	doc := NewDocument()
+	doc.AddPage()
+	f := doc.internal
+	f.SetFont("Helvetica", "I", 11)
+	f.SetTextColor(10, 20, 30)
+
+	doc.Inline()
+	doc.AddText("x").Bold().Size(20).Color(200, 0, 0).Draw()
+	if family, style := f.GetFontFamily(), f.GetFontStyle(); family != "helvetica" || style != "I" {
+		t.Errorf("collecting a fragment changed the font to %s %s", family, style)
+	}
+	doc.EndInline()
+
+	if family, style := f.GetFontFamily(), f.GetFontStyle(); family != "helvetica" || style != "I" {
+		t.Errorf("expecting helvetica I after EndInline, got %s %s", family, style)
+	}
+	if size, _ := f.GetFontSize(); size != 11 {
+		t.Errorf("expecting size 11 after EndInline, got %v", size)
+	}
+	if r, g, b := f.GetTextColor(); r != 10 || g != 20 || b != 30 {
+		t.Errorf("expecting text color 10 20 30 after EndInline, got %d %d %d", r, g, b)
+	}
+}
@@ -0,0 +1,245 @@
+package pdf
+
+import (
+	"math"
+	"sort"
+)
+
+// InvoiceParty is the seller or the buyer of an invoice.
+type InvoiceParty struct {
+	Name    string
+	Address []string // lines of the postal address
+	TaxID   string   // VAT or tax number, empty for none
+	Email   string   // empty for none
+}
+
+// InvoiceItem is a line of an invoice.
+type InvoiceItem struct {
+	Description string
+	Quantity    float64
+	UnitPrice   float64
+	TaxRate     float64 // in percent, e.g. 8.1
+}
+
+// Amount returns the price of the line before tax.
+func (it InvoiceItem) Amount() float64 {
+	return roundCents(it.Quantity * it.UnitPrice)
+}
+
+// InvoiceTax is the tax of the lines of an invoice sharing a tax rate.
+type InvoiceTax struct {
+	Rate   float64 // in percent
+	Base   float64 // amount of the lines before tax
+	Amount float64 // tax due, rounded to cents
+}
+
+// Invoice holds the content of an invoice drawn by AddInvoice. Amounts are
+// printed in the currency of the document locale, and the dates, in
+// nanoseconds since the Unix epoch, in its date layout.
+type Invoice struct {
+	Title        string // "Invoice" when empty
+	Number       string
+	Date         int64 // 0 to omit it
+	DueDate      int64 // 0 to omit it
+	Seller       InvoiceParty
+	Buyer        InvoiceParty
+	Items        []InvoiceItem
+	PaymentTerms string // e.g. "Payable within 30 days", empty for none
+	Notes        string // may contain line breaks, empty for none
+}
+
+// Subtotal returns the amount of the invoice before tax.
+func (inv Invoice) Subtotal() float64 {
+	var sum float64
+	for _, it := range inv.Items {
+		sum += it.Amount()
+	}
+	return roundCents(sum)
+}
+
+// Taxes returns the tax due for each tax rate of the items, by increasing
+// rate. Items without tax rate are left out.
+func (inv Invoice) Taxes() []InvoiceTax {
+	var taxes []InvoiceTax
+	for _, it := range inv.Items {
+		if it.TaxRate == 0 {
+			continue
+		}
+		j := sort.Search(len(taxes), func(j int) bool { return taxes[j].Rate >= it.TaxRate })
+		if j == len(taxes) || taxes[j].Rate != it.TaxRate {
+			taxes = append(taxes, InvoiceTax{})
+			copy(taxes[j+1:], taxes[j:])
+			taxes[j] = InvoiceTax{Rate: it.TaxRate}
+		}
+		taxes[j].Base += it.Amount()
+	}
+	for j := range taxes {
+		taxes[j].Base = roundCents(taxes[j].Base)
+		taxes[j].Amount = roundCents(taxes[j].Base * taxes[j].Rate / 100)
+	}
+	return taxes
+}
+
+// Total returns the amount due: the subtotal and the taxes.
+func (inv Invoice) Total() float64 {
+	total := inv.Subtotal()
+	for _, tax := range inv.Taxes() {
+		total += tax.Amount
+	}
+	return roundCents(total)
+}
+
+// roundCents rounds v to two decimals.
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// invoiceGap is the vertical space between the blocks of an invoice.
+const invoiceGap = 6
+
+// AddInvoice draws inv across the text area: the title with the number and
+// dates, the seller and buyer side by side, a table of the items, the
+// subtotal, the tax by rate and the total, then the payment terms and the
+// notes. The title is printed in the Header1 style of the FontConfig, the
+// headings of the blocks in the Header3 style and the rest in the Body
+// style, so that a document theme restyles its invoices.
+func (d *Document) AddInvoice(inv Invoice) *Document {
+	f := d.internal
+	cfg := d.theme.FontConfig
+	pageW, _ := f.GetPageSize()
+	lMargin, _, rMargin, _ := f.GetMargins()
+	w := pageW - lMargin - rMargin
+	f.SetX(lMargin)
+
+	title := inv.Title
+	if title == "" {
+		title = "Invoice"
+	}
+	d.addHeading(cfg.Header1, title)
+	var meta []string
+	if inv.Number != "" {
+		meta = append(meta, "Number: "+inv.Number)
+	}
+	if inv.Date != 0 {
+		meta = append(meta, "Date: "+d.FormatDate(inv.Date))
+	}
+	if inv.DueDate != 0 {
+		meta = append(meta, "Due date: "+d.FormatDate(inv.DueDate))
+	}
+	lineHt := d.applyTextStyle(cfg.Body)
+	for _, line := range meta {
+		f.CellFormat(0, lineHt, line, "", 1, "L", false, 0, "")
+	}
+	f.Ln(invoiceGap)
+
+	// Seller and buyer side by side
+	top := f.GetY()
+	half := (w - invoiceGap) / 2
+	bottom := top
+	for j, p := range []struct {
+		heading string
+		party   InvoiceParty
+	}{{"From", inv.Seller}, {"Bill to", inv.Buyer}} {
+		x := lMargin + float64(j)*(half+invoiceGap)
+		f.SetXY(x, top)
+		headHt := d.applyTextStyle(cfg.Header3)
+		f.CellFormat(half, headHt, p.heading, "", 2, "L", false, 0, "")
+		d.applyTextStyle(cfg.Body)
+		lines := append([]string{p.party.Name}, p.party.Address...)
+		if p.party.TaxID != "" {
+			lines = append(lines, "Tax ID: "+p.party.TaxID)
+		}
+		if p.party.Email != "" {
+			lines = append(lines, p.party.Email)
+		}
+		for _, line := range lines {
+			for _, part := range f.SplitText(line, half) {
+				f.CellFormat(half, lineHt, part, "", 2, "L", false, 0, "")
+			}
+		}
+		bottom = max(bottom, f.GetY())
+	}
+	f.SetXY(lMargin, bottom)
+	f.Ln(invoiceGap)
+
+	// Items
+	qtyDecimals := 0
+	for _, it := range inv.Items {
+		if it.Quantity != math.Trunc(it.Quantity) {
+			qtyDecimals = 2
+		}
+	}
+	d.applyTextStyle(cfg.Body)
+	table := d.AddTable().
+		AddColumn("Description").
+		AddColumn("Quantity").Number(qtyDecimals).AlignRight().
+		AddColumn("Unit price").Currency().AlignRight().
+		AddColumn("Tax").AlignRight().Suffix("%").
+		AddColumn("Amount").Currency().AlignRight().
+		ShrinkToFit()
+	for _, it := range inv.Items {
+		table.AddRow(it.Description, it.Quantity, it.UnitPrice, d.FormatNumber(it.TaxRate, taxDecimals(it.TaxRate)), it.Amount())
+	}
+	table.Draw()
+	f.Ln(invoiceGap)
+
+	// Totals, kept together on the right
+	taxes := inv.Taxes()
+	type total struct{ label, amount string }
+	totals := []total{{"Subtotal", d.FormatCurrency(inv.Subtotal())}}
+	for _, tax := range taxes {
+		rate := d.FormatNumber(tax.Rate, taxDecimals(tax.Rate))
+		totals = append(totals, total{
+			"Tax " + rate + "% on " + d.FormatCurrency(tax.Base),
+			d.FormatCurrency(tax.Amount),
+		})
+	}
+	totals = append(totals, total{"Total", d.FormatCurrency(inv.Total())})
+	d.keepTogether(float64(len(totals)) * lineHt)
+	labelW, amountW := w*0.35, w*0.2
+	for j, t := range totals {
+		last := j == len(totals)-1
+		if last {
+			bold := cfg.Body
+			bold.Font = FontBold
+			d.applyTextStyle(bold)
+		}
+		f.SetX(lMargin + w - labelW - amountW)
+		border := ""
+		if last {
+			border = "T"
+		}
+		f.CellFormat(labelW, lineHt, t.label, border, 0, "L", false, 0, "")
+		f.CellFormat(amountW, lineHt, t.amount, border, 1, "R", false, 0, "")
+	}
+	d.applyTextStyle(cfg.Body)
+
+	for _, block := range []struct{ heading, text string }{
+		{"Payment terms", inv.PaymentTerms},
+		{"Notes", inv.Notes},
+	} {
+		if block.text == "" {
+			continue
+		}
+		f.Ln(invoiceGap)
+		d.keepTogether(cfg.Header3.LineHeight + lineHt)
+		d.addHeading(cfg.Header3, block.heading)
+		d.applyTextStyle(cfg.Body)
+		f.MultiCell(0, lineHt, block.text, "", "L", false)
+	}
+	f.Ln(cfg.Body.SpaceAfter)
+	f.SetTextColor(0, 0, 0)
+	return d
+}
+
+// taxDecimals returns the number of decimals printed for the tax rate, such
+// as 1 for 8.1% and 0 for 20%.
+func taxDecimals(rate float64) int {
+	for n := 0; n < 3; n++ {
+		p := math.Pow(10, float64(n))
+		if math.Abs(rate*p-math.Round(rate*p)) < 1e-9 {
+			return n
+		}
+	}
+	return 3
+}
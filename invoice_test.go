@@ -0,0 +1,82 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testInvoice() Invoice {
+	return Invoice{
+		Number: "2024-017",
+		Date:   1700000000e9,
+		Seller: InvoiceParty{Name: "Acme Tools", Address: []string{"1 Main Street", "Springfield"}, TaxID: "CHE-123.456.789"},
+		Buyer:  InvoiceParty{Name: "Jane Roe", Email: "jane@example.com"},
+		Items: []InvoiceItem{
+			{Description: "Hammer", Quantity: 2, UnitPrice: 12.5, TaxRate: 8.1},
+			{Description: "Manual", Quantity: 1, UnitPrice: 20, TaxRate: 2.6},
+			{Description: "Saw", Quantity: 1, UnitPrice: 30.25, TaxRate: 8.1},
+			{Description: "Delivery", Quantity: 1, UnitPrice: 5},
+		},
+		PaymentTerms: "Payable within 30 days",
+		Notes:        "Thank you for your order.",
+	}
+}
+
+func TestInvoiceTotals(t *testing.T) {
+	inv := testInvoice()
+	if got := inv.Subtotal(); got != 80.25 {
+		t.Errorf("got subtotal %v, want 80.25", got)
+	}
+	want := []InvoiceTax{{2.6, 20, 0.52}, {8.1, 55.25, 4.48}}
+	taxes := inv.Taxes()
+	if len(taxes) != len(want) {
+		t.Fatalf("got taxes %v, want %v", taxes, want)
+	}
+	for j := range want {
+		if taxes[j] != want[j] {
+			t.Errorf("tax %d: got %+v, want %+v", j, taxes[j], want[j])
+		}
+	}
+	if got := inv.Total(); got != 85.25 {
+		t.Errorf("got total %v, want 85.25", got)
+	}
+}
+
+func TestAddInvoice(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	doc.AddInvoice(testInvoice())
+	if err := doc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, text := range []string{
+		"(Invoice)", "(Number: 2024-017)", "(From)", "(Bill to)", "(Acme Tools)",
+		"(Tax ID: CHE-123.456.789)", "(jane@example.com)", "(Hammer)", "(8.1%)",
+		"(Subtotal)", "(Tax 8.1% on ", "(Total)", "(Payment terms)", "(Thank you for your order.)",
+	} {
+		if !strings.Contains(out, text) {
+			t.Errorf("%s missing from the invoice", text)
+		}
+	}
+	// The title follows the Header1 style of the theme
+	cfg := doc.FontConfig()
+	cfg.Header1.Size = 30
+	doc = NewDocument().SetFontConfig(cfg)
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	doc.AddInvoice(testInvoice())
+	buf.Reset()
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), " 30.00 Tf") {
+		t.Error("title not printed in the Header1 size of the theme")
+	}
+}
@@ -0,0 +1,185 @@
+package pdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// ListItem is an entry of a list drawn by BulletList or NumberedList. Items
+// holds the entries of a list nested under it.
+type ListItem struct {
+	Text  string
+	Items []ListItem
+}
+
+// ListItems returns items without nested lists, one per text.
+func ListItems(texts ...string) []ListItem {
+	items := make([]ListItem, len(texts))
+	for i, text := range texts {
+		items[i].Text = text
+	}
+	return items
+}
+
+// ListOptions configures the lists drawn by BulletList and NumberedList. The
+// zero value draws the list in the Body style of the theme, with a hanging
+// indent of 6 units per level.
+type ListOptions struct {
+	Style      TextStyle // style of the items; the zero value uses the Body style
+	Indent     float64   // indentation of each nesting level; 0 means 6
+	LabelWidth float64   // width of the bullet or number column; 0 means Indent
+	SpaceAfter float64   // vertical space added after each item
+
+	// Bullets are the glyphs of BulletList for each nesting level, repeated
+	// for deeper levels. When empty, a disc, a circle and a square are drawn
+	// in turn, which do not depend on the glyphs of the font.
+	Bullets []string
+
+	// Start is the number of the first item of NumberedList; 0 means 1.
+	Start int
+	// Continue makes NumberedList number its first item after the last
+	// top-level item of the previous numbered list, ignoring Start, so that a
+	// list interrupted by other content keeps its numbering.
+	Continue bool
+	// Number returns the label of the item numbered n at nesting level
+	// level, starting at 0. When nil, levels are numbered "1.", "a.", "i."
+	// in turn.
+	Number func(level, n int) string
+}
+
+// listKind selects the labels drawn by drawList.
+type listKind int
+
+const (
+	listBullet listKind = iota
+	listNumbered
+)
+
+// BulletList draws items as a bulleted list, with the items of nested lists
+// indented under their parent. Lines that wrap are aligned with the text of
+// their item, and an item moves to the next page with its bullet.
+func (d *Document) BulletList(items []ListItem, opts ListOptions) *Document {
+	return d.drawList(listBullet, items, opts)
+}
+
+// NumberedList draws items as a numbered list, with the items of nested
+// lists indented under their parent and numbered on their own. Numbering
+// continues across page breaks, and across lists with ListOptions.Continue.
+func (d *Document) NumberedList(items []ListItem, opts ListOptions) *Document {
+	return d.drawList(listNumbered, items, opts)
+}
+
+func (d *Document) drawList(kind listKind, items []ListItem, opts ListOptions) *Document {
+	if opts.Style == (TextStyle{}) {
+		opts.Style = d.theme.Body
+	}
+	if opts.Indent == 0 {
+		opts.Indent = 6
+	}
+	if opts.LabelWidth == 0 {
+		opts.LabelWidth = opts.Indent
+	}
+	start := opts.Start
+	if start == 0 {
+		start = 1
+	}
+	if kind == listNumbered && opts.Continue {
+		start = d.listNext
+	}
+	lineHt := d.applyTextStyle(opts.Style)
+	lMargin, _, _, _ := d.internal.GetMargins()
+	next := d.drawListItems(kind, items, opts, lineHt, lMargin, 0, start)
+	if kind == listNumbered {
+		d.listNext = next
+	}
+	d.internal.Ln(opts.Style.SpaceAfter)
+	d.internal.SetTextColor(0, 0, 0)
+	return d
+}
+
+// drawListItems draws the items of nesting level level, starting at x and
+// numbered from n, and returns the number following the last one.
+func (d *Document) drawListItems(kind listKind, items []ListItem, opts ListOptions, lineHt, x float64, level, n int) int {
+	f := d.internal
+	for _, item := range items {
+		// Keep the label on the page of the first line of the item.
+		d.keepTogether(lineHt)
+		f.SetX(x)
+		label := ""
+		switch {
+		case kind == listNumbered && opts.Number != nil:
+			label = opts.Number(level, n)
+		case kind == listNumbered:
+			label = listNumber(level, n)
+		case len(opts.Bullets) > 0:
+			label = opts.Bullets[level%len(opts.Bullets)]
+		default:
+			d.drawListBullet(level, x+opts.LabelWidth/2, f.GetY()+lineHt/2)
+		}
+		f.CellFormat(opts.LabelWidth, lineHt, label, "", 0, "R", false, 0, "")
+		f.MultiCell(0, lineHt, item.Text, "", "L", false)
+		if len(item.Items) > 0 {
+			d.drawListItems(kind, item.Items, opts, lineHt, x+opts.Indent, level+1, 1)
+		}
+		f.Ln(opts.SpaceAfter)
+		n++
+	}
+	return n
+}
+
+// drawListBullet draws the default bullet of level level centered on (x, y)
+// in the text color.
+func (d *Document) drawListBullet(level int, x, y float64) {
+	f := d.internal
+	_, size := f.GetFontSize()
+	r := size / 8
+	tr, tg, tb := f.GetTextColor()
+	fr, fg, fb := f.GetFillColor()
+	dr, dg, db := f.GetDrawColor()
+	f.SetFillColor(tr, tg, tb)
+	f.SetDrawColor(tr, tg, tb)
+	switch level % 3 {
+	case 0:
+		f.Circle(x, y, r, "F")
+	case 1:
+		f.Circle(x, y, r, "D")
+	default:
+		f.Rect(x-r, y-r, 2*r, 2*r, "F")
+	}
+	f.SetFillColor(fr, fg, fb)
+	f.SetDrawColor(dr, dg, db)
+}
+
+// listNumber returns the default label of the item numbered n at nesting
+// level level: "1.", "a." and "i." in turn.
+func listNumber(level, n int) string {
+	switch level % 3 {
+	case 1:
+		return listLetters(n) + "."
+	case 2:
+		return Convert(listRoman(n)).ToLower().String() + "."
+	}
+	return Sprintf("%d.", n)
+}
+
+// listLetters returns n as lowercase letters: a to z, then aa, ab and so on.
+func listLetters(n int) string {
+	s := ""
+	for ; n > 0; n = (n - 1) / 26 {
+		s = string(rune('a'+(n-1)%26)) + s
+	}
+	return s
+}
+
+// listRoman returns n in Roman numerals.
+func listRoman(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	numerals := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	s := ""
+	for i, v := range values {
+		for n >= v {
+			s += numerals[i]
+			n -= v
+		}
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBulletList(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	doc.BulletList([]ListItem{
+		{Text: "first"},
+		{Text: "second", Items: ListItems("nested one", "nested two")},
+	}, ListOptions{})
+	doc.BulletList(ListItems("dash"), ListOptions{Bullets: []string{"-"}})
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+	out := buf.String()
+	// Two discs and two circles are drawn, each with four curves.
+	if n := strings.Count(out, " c\n"); n < 16 {
+		t.Errorf("expected four bullets, found %d curves", n)
+	}
+	if !strings.Contains(out, "-)Tj") {
+		t.Errorf("custom bullet not written")
+	}
+}
+
+func TestNumberedList(t *testing.T) {
+	doc := NewDocument()
+	doc.AddPage()
+	x0 := doc.internal.GetX()
+	var labels []string
+	opts := ListOptions{Number: func(level, n int) string {
+		label := listNumber(level, n)
+		labels = append(labels, label)
+		return label
+	}}
+	items := make([]ListItem, 60)
+	for i := range items {
+		items[i].Text = strings.Repeat("item text ", 20)
+	}
+	items[0].Items = ListItems("a", "b", "c")
+	doc.NumberedList(items, opts)
+	if doc.internal.PageCount() < 2 {
+		t.Fatalf("expected the list to span pages, got %d", doc.internal.PageCount())
+	}
+	if doc.internal.GetX() != x0 {
+		t.Errorf("position not restored to the margin: x=%.2f", doc.internal.GetX())
+	}
+	opts.Continue = true
+	doc.NumberedList(ListItems("continued"), opts)
+
+	want := []string{"1.", "a.", "b.", "c.", "2."}
+	if strings.Join(labels[:5], " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected labels: %v", labels[:5])
+	}
+	if last := labels[len(labels)-1]; last != "61." {
+		t.Errorf("numbering not continued: got %s", last)
+	}
+	if doc.internal.Err() {
+		t.Fatal(doc.internal.Error())
+	}
+}
+
+func TestListNumber(t *testing.T) {
+	for _, c := range []struct {
+		level, n int
+		want     string
+	}{{0, 12, "12."}, {1, 1, "a."}, {1, 28, "ab."}, {2, 4, "iv."}, {2, 1994, "mcmxciv."}, {3, 3, "3."}} {
+		if got := listNumber(c.level, c.n); got != c.want {
+			t.Errorf("listNumber(%d, %d): got %q, want %q", c.level, c.n, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package pdf
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// Logger receives the messages a Document logs while it loads fonts and
+// images and writes its output. Each message comes with alternating keys and
+// values that give its details, as with log/slog, so that a *slog.Logger can
+// be used as it is:
+//
+//	doc.SetLogger(slog.Default())
+//
+// Routine steps are logged at the Debug level, the non-fatal warnings of the
+// document at the Warn level and failures at the Error level.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// SetLogger sets the logger of the document. A nil logger discards the
+// messages.
+func (d *Document) SetLogger(l Logger) *Document {
+	if l == nil {
+		l = funcLogger{}
+	}
+	d.logger = l
+	return d
+}
+
+// SetLog sets a function that receives the arguments of Log as they are, and
+// the messages of the Info, Warn and Error levels followed by their keys and
+// values. A nil function discards them.
+//
+// Deprecated: use SetLogger, which also receives the Debug messages.
+func (d *Document) SetLog(fn func(...any)) *Document {
+	d.logger = funcLogger{print: fn}
+	return d
+}
+
+// Log writes a message, made of the values of message separated by spaces,
+// at the Info level. The function set with SetLog receives message as it is.
+func (d *Document) Log(message ...any) {
+	if l, ok := d.logger.(funcLogger); ok {
+		if l.print != nil {
+			l.print(message...)
+		}
+		return
+	}
+	parts := make([]string, len(message))
+	for j, m := range message {
+		parts[j] = Sprint(m)
+	}
+	d.logger.Info(Convert(parts).Join(" ").String())
+}
+
+// funcLogger is a Logger that passes the messages from the Info level up to a
+// function, such as fmt.Println, followed by their keys and values.
+type funcLogger struct {
+	print func(message ...any)
+}
+
+func (l funcLogger) Debug(msg string, args ...any) {}
+func (l funcLogger) Info(msg string, args ...any)  { l.log(msg, args) }
+func (l funcLogger) Warn(msg string, args ...any)  { l.log(msg, args) }
+func (l funcLogger) Error(msg string, args ...any) { l.log(msg, args) }
+
+func (l funcLogger) log(msg string, args []any) {
+	if l.print == nil {
+		return
+	}
+	l.print(append([]any{msg}, args...)...)
+}
+
+// logLine returns msg followed by its key and value pairs in args, as
+// key=value words.
+func logLine(msg string, args []any) string {
+	line := msg
+	for j := 0; j < len(args); j += 2 {
+		if j+1 == len(args) {
+			line += " " + Sprint(args[j])
+			break
+		}
+		line += " " + Sprint(args[j]) + "=" + Sprint(args[j+1])
+	}
+	return line
+}
@@ -0,0 +1,60 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// A *slog.Logger is a Logger as it is.
+var _ Logger = (*slog.Logger)(nil)
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	doc := NewDocument().SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	doc.SetResourceFS(fstest.MapFS{})
+	doc.RegisterFont("Body", "fonts/missing.ttf")
+	doc.Load(func(err error) {
+		if err == nil {
+			t.Fatal("expected error for a missing font")
+		}
+	})
+	doc.AddPage()
+	doc.AddText("Hello").Draw()
+	if err := doc.OutputTo(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	log := buf.String()
+	for _, s := range []string{
+		`level=DEBUG msg="default font not found, using the built-in Arial"`,
+		`level=ERROR msg="font loading failed" family=Body path=fonts/missing.ttf`,
+		`level=DEBUG msg="PDF written"`,
+	} {
+		if !strings.Contains(log, s) {
+			t.Errorf("log has no %q:\n%s", s, log)
+		}
+	}
+}
+
+func TestSetLog(t *testing.T) {
+	var lines []string
+	doc := NewDocument().SetLog(func(message ...any) {
+		var parts []string
+		for _, m := range message {
+			parts = append(parts, fmt.Sprint(m))
+		}
+		lines = append(lines, strings.Join(parts, "|"))
+	})
+	doc.Log("ready", 3)
+	doc.logger.Error("failed", "path", "a.pdf", "dangling")
+	doc.logger.Debug("not printed")
+	want := []string{"ready|3", "failed|path|a.pdf|dangling"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	// A nil logger discards the messages.
+	doc.SetLogger(nil).Log("discarded")
+}
@@ -0,0 +1,61 @@
+package pdf
+
+import "iter"
+
+// MailMerge produces form letters: the same template, filled with the values
+// of each of a series of records.
+type MailMerge struct {
+	template func(d *Document)
+	shared   map[string][]byte
+}
+
+// NewMailMerge returns a MailMerge drawing each letter with template, which
+// adds the fields to fill with AddTextField and AddImageSlot. The letter
+// starts on a new page when template is called, and template may add more
+// pages. Once it returns, the fields are filled with the values of a record
+// by FillFields: a record maps the names of the fields to their values.
+func NewMailMerge(template func(d *Document)) *MailMerge {
+	return &MailMerge{template: template, shared: make(map[string][]byte)}
+}
+
+// Merge appends to d a letter per record, each starting on a new page, and
+// returns d; slices.Values iterates over the records of a slice. The fonts
+// and images are loaded in d once for all the letters. Merge stops at the
+// first error of d.
+func (m *MailMerge) Merge(d *Document, records iter.Seq[map[string]any]) *Document {
+	for record := range records {
+		if d.Err() != nil {
+			break
+		}
+		m.letter(d, record)
+	}
+	return d
+}
+
+// Each creates a document holding the letter of each record and passes it,
+// with the index of the record, to fn, which typically writes it with
+// OutputTo. The files of the fonts and images are read once for all the
+// documents. Each returns the first error of a document or of fn, and stops
+// there.
+func (m *MailMerge) Each(records iter.Seq[map[string]any], fn func(i int, d *Document) error) error {
+	i := 0
+	for record := range records {
+		d := newDocument(m.shared)
+		m.letter(d, record)
+		if err := d.Err(); err != nil {
+			return err
+		}
+		if err := fn(i, d); err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+// letter draws the letter of record in d.
+func (m *MailMerge) letter(d *Document, record map[string]any) {
+	d.AddPage()
+	m.template(d)
+	d.FillFields(record)
+}
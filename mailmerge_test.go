@@ -0,0 +1,81 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func letterTemplate(d *Document) {
+	d.AddParagraph("Dear customer,")
+	d.AddTextField("name", 60, 8, "L")
+	d.AddImageSlot("logo", 20, 20)
+}
+
+func TestMailMergeMerge(t *testing.T) {
+	records := []map[string]any{{"name": "Ada"}, {"name": "Grace"}, {"name": "Linus"}}
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	NewMailMerge(letterTemplate).Merge(doc, slices.Values(records))
+	if err := doc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n := doc.internal.PageCount(); n != 3 {
+		t.Fatalf("got %d pages, want one per record", n)
+	}
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, r := range records {
+		if !strings.Contains(out, "("+r["name"].(string)+")Tj") {
+			t.Errorf("letter to %s missing", r["name"])
+		}
+	}
+	if n := strings.Count(out, "(Dear customer,)Tj"); n != 3 {
+		t.Errorf("got the template %d times, want 3", n)
+	}
+}
+
+func TestMailMergeEach(t *testing.T) {
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logo, img.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	records := []map[string]any{{"name": "Ada", "logo": logo}, {"name": "Grace", "logo": logo}}
+	var letters []string
+	err := NewMailMerge(letterTemplate).Each(slices.Values(records), func(i int, d *Document) error {
+		if i != len(letters) {
+			t.Errorf("got letter %d, want %d", i, len(letters))
+		}
+		d.internal.SetCompression(false)
+		var buf bytes.Buffer
+		if err := d.OutputTo(&buf); err != nil {
+			return err
+		}
+		letters = append(letters, buf.String())
+		// The image is read once for all the letters
+		return os.Remove(logo)
+	})
+	if err == nil || len(letters) != 2 {
+		t.Fatalf("got %d letters and error %v, want 2 and an error removing the image twice", len(letters), err)
+	}
+	for j, out := range letters {
+		if !strings.Contains(out, "("+records[j]["name"].(string)+")Tj") || !strings.Contains(out, "/Subtype /Image") {
+			t.Errorf("letter %d lacks its name or the logo", j)
+		}
+		if strings.Contains(out, "(Grace)Tj") != (j == 1) {
+			t.Errorf("letter %d holds the fields of another letter", j)
+		}
+	}
+}
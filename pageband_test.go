@@ -0,0 +1,46 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tinywasm/time"
+)
+
+func TestPageHeaderFooter(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.SetPageHeader().Left("ACME Corp.").Right("Page " + PageNumber())
+	doc.SetPageFooter().Center(PageOfTotal())
+	doc.AddPage()
+	doc.AddPage()
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+	for _, want := range []string{"(ACME Corp.)", "(Page 2)", "(1 / 2)", "(2 / 2)"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("output does not contain %s", want)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	defer time.SetTimeZoneOffset(time.GetTimeZoneOffset())
+	const nano = 1700000000 * 1e9 // 2023-11-14T22:13:20Z
+	time.SetTimeZoneOffset(0)
+	if got, want := formatDate(nano, "02/01/2006 15:04:05"), "14/11/2023 22:13:20"; got != want {
+		t.Errorf("formatDate: got %q, want %q", got, want)
+	}
+	// Three hours behind UTC the date is still the 14th, three hours ahead
+	// it is already the 15th
+	time.SetTimeZoneOffset(-3)
+	if got, want := formatDate(nano, "02/01/2006 15:04"), "14/11/2023 19:13"; got != want {
+		t.Errorf("formatDate at UTC-3: got %q, want %q", got, want)
+	}
+	time.SetTimeZoneOffset(3)
+	if got, want := formatDate(nano, "02/01/2006 15:04"), "15/11/2023 01:13"; got != want {
+		t.Errorf("formatDate at UTC+3: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+// Package pdftest helps maintain regression tests against reference PDF
+// files. A test generates a document and compares it with a reference copy
+// generated earlier and checked into the repository; the values that change
+// on every run, such as the creation date, are ignored.
+//
+//	var buf bytes.Buffer
+//	if err := doc.OutputTo(&buf); err != nil {
+//		t.Fatal(err)
+//	}
+//	pdftest.Golden(t, pdftest.Filename("testdata", t.Name()), buf.Bytes())
+//
+// Set PDFTEST_UPDATE=1 in the environment to rewrite the reference files
+// after an intended change of the output.
+package pdftest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+// UpdateEnv is the environment variable which, set to a non-empty value,
+// makes Golden() rewrite the reference files instead of comparing with them.
+const UpdateEnv = "PDFTEST_UPDATE"
+
+// volatile lists the values of a PDF that differ between two runs of the
+// same program: the key preceding each value and the byte closing it.
+var volatile = []struct {
+	key   string
+	close byte
+}{
+	{"/CreationDate (", ')'},
+	{"/ModDate (", ')'},
+	{"/ID [", ']'},
+}
+
+// Filename returns the path of the PDF file named base, with the suffix
+// ".pdf" appended, in directory dir. Slashes and backslashes in base, as in
+// the names of subtests, are replaced by underscores, so that the file stays
+// in dir and subtests of the same name in different tests get their own file.
+func Filename(dir, base string) string {
+	return filepath.Join(dir, Convert(base).Replace("/", "_").Replace("\\", "_").String()+".pdf")
+}
+
+// Normalize returns a copy of pdf with the creation and modification dates
+// and the file identifiers overwritten by zeros. The values keep their
+// length, so that the offsets of the cross-reference table are unchanged.
+func Normalize(pdf []byte) []byte {
+	out := bytes.Clone(pdf)
+	for _, v := range volatile {
+		key := []byte(v.key)
+		for pos := 0; ; {
+			i := bytes.Index(out[pos:], key)
+			if i < 0 {
+				break
+			}
+			start := pos + i + len(key)
+			end := bytes.IndexByte(out[start:], v.close)
+			if end < 0 {
+				break
+			}
+			for j := start; j < start+end; j++ {
+				out[j] = '0'
+			}
+			pos = start + end
+		}
+	}
+	return out
+}
+
+// Compare compares two PDF files once normalized by Normalize(). Nil is
+// returned if they are equal, otherwise an error. With printDiff, the
+// differing bytes are printed to standard output as a hex dump.
+func Compare(got, want []byte, printDiff bool) error {
+	return fpdf.CompareBytes(Normalize(got), Normalize(want), printDiff)
+}
+
+// CompareFiles compares the file of path gotPath with the reference file of
+// path wantPath as Compare() does. Nil is returned if the contents are
+// equivalent, or if the reference file does not exist.
+func CompareFiles(gotPath, wantPath string) error {
+	got, err := os.ReadFile(gotPath)
+	if err != nil {
+		return err
+	}
+	want, err := os.ReadFile(wantPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err = Compare(got, want, false); err != nil {
+		return Err(Sprintf("%s differs from %s: %v", gotPath, wantPath, err))
+	}
+	return nil
+}
+
+// ReferenceCompare compares the file of path file with its reference copy,
+// of the same name in the "reference" subdirectory of its directory, as
+// CompareFiles() does. The subdirectory is created if it does not exist.
+func ReferenceCompare(file string) error {
+	dir, base := filepath.Split(file)
+	refDir := filepath.Join(dir, "reference")
+	if err := os.MkdirAll(refDir, 0755); err != nil {
+		return err
+	}
+	return CompareFiles(file, filepath.Join(refDir, base))
+}
+
+// SummaryCompare generates a predictable report for use by examples. If err
+// is nil, file is compared with its reference copy by ReferenceCompare(); if
+// they match, a success message with the path of file relative to root, with
+// forward slashes, is printed to standard output. Otherwise the error is
+// printed.
+func SummaryCompare(err error, root, file string) {
+	if err == nil {
+		err = ReferenceCompare(file)
+	}
+	if err != nil {
+		Println(err)
+		return
+	}
+	if rel, relErr := filepath.Rel(root, file); relErr == nil {
+		file = rel
+	}
+	Printf("Successfully generated %s\n", filepath.ToSlash(file))
+}
+
+// Golden compares got with the reference file of path golden as Compare()
+// does, and fails t if they differ. A missing reference file is written with
+// got, as is an existing one when the UpdateEnv environment variable is set;
+// the reference file is then to be reviewed and checked in.
+func Golden(t testing.TB, golden string, got []byte) {
+	t.Helper()
+	want, err := os.ReadFile(golden)
+	if os.IsNotExist(err) || (err == nil && os.Getenv(UpdateEnv) != "") {
+		if err = os.MkdirAll(filepath.Dir(golden), 0755); err == nil {
+			err = os.WriteFile(golden, got, 0644)
+		}
+		if err != nil {
+			t.Fatalf("writing reference %s: %v", golden, err)
+		}
+		t.Logf("wrote reference %s", golden)
+		return
+	}
+	if err != nil {
+		t.Fatalf("reading reference %s: %v", golden, err)
+	}
+	if err = Compare(got, want, testing.Verbose()); err != nil {
+		t.Errorf("%s: %v; set %s=1 to update the reference", golden, err, UpdateEnv)
+	}
+}
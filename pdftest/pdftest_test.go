@@ -0,0 +1,114 @@
+//go:build !wasm
+
+package pdftest_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tinywasm/pdf/fpdf"
+	"github.com/tinywasm/pdf/pdftest"
+)
+
+func generate(t *testing.T, text string, date time.Time) []byte {
+	t.Helper()
+	f := fpdf.New()
+	f.SetCreationDate(date)
+	f.SetModificationDate(date)
+	f.AddPage()
+	f.SetFont("Helvetica", "", 12)
+	f.Cell(40, 10, text)
+	var buf bytes.Buffer
+	if err := f.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompareIgnoresDates(t *testing.T) {
+	a := generate(t, "Hello", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := generate(t, "Hello", time.Date(2024, 6, 30, 12, 34, 56, 0, time.UTC))
+	if bytes.Equal(a, b) {
+		t.Fatal("documents of different dates are identical")
+	}
+	if err := pdftest.Compare(a, b, false); err != nil {
+		t.Errorf("documents differing by dates: %v", err)
+	}
+	c := generate(t, "World", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := pdftest.Compare(a, c, false); err == nil {
+		t.Error("documents of different text compare equal")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	in := []byte("/CreationDate (D:20240630123456)\n/ID [<ab12><cd34>]\n/Title (x)")
+	want := "/CreationDate (0000000000000000)\n/ID [000000000000]\n/Title (x)"
+	if got := string(pdftest.Normalize(in)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if in[15] != 'D' {
+		t.Error("Normalize modified its argument")
+	}
+}
+
+func TestFilename(t *testing.T) {
+	for base, want := range map[string]string{
+		"TestInvoice":       "TestInvoice.pdf",
+		"TestInvoice/paid":  "TestInvoice_paid.pdf",
+		"TestReceipt/paid":  "TestReceipt_paid.pdf",
+		`TestInvoice/a\b/c`: "TestInvoice_a_b_c.pdf",
+	} {
+		if got := pdftest.Filename("testdata", base); got != filepath.Join("testdata", want) {
+			t.Errorf("%s: got %q, want %q", base, got, filepath.Join("testdata", want))
+		}
+	}
+}
+
+// recorder records the failure of a test instead of failing it.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Errorf(string, ...any) { r.failed = true }
+
+func TestGolden(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "ref", "doc.pdf")
+	first := generate(t, "Hello", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	pdftest.Golden(t, golden, first)
+	if _, err := os.Stat(golden); err != nil {
+		t.Fatalf("reference not written: %v", err)
+	}
+	pdftest.Golden(t, golden, generate(t, "Hello", time.Now()))
+
+	other := &recorder{TB: t}
+	pdftest.Golden(other, golden, generate(t, "World", time.Now()))
+	if !other.failed {
+		t.Error("a different document matches the reference")
+	}
+}
+
+func TestCompareFilesMissingReference(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "doc.pdf")
+	if err := os.WriteFile(file, generate(t, "Hello", time.Now()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdftest.ReferenceCompare(file); err != nil {
+		t.Errorf("missing reference: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "reference", "doc.pdf"), generate(t, "World", time.Now()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := pdftest.ReferenceCompare(file)
+	if err == nil {
+		t.Fatal("different reference compares equal")
+	}
+	if msg := err.Error(); !strings.Contains(msg, filepath.Join(dir, "reference", "doc.pdf")) || !strings.Contains(msg, "documents are different") {
+		t.Errorf("error lacks the reference path or the cause: %s", msg)
+	}
+}
@@ -0,0 +1,35 @@
+package pdf
+
+import "github.com/tinywasm/pdf/fpdf"
+
+// NewReceipt creates a Document for a thermal receipt printer using paper,
+// such as fpdf.Receipt58 or fpdf.Receipt80, with its first page started. The
+// page grows with its content, the margins are 3 mm and the text is printed
+// in Courier 9 so that the columns of ReceiptLine line up.
+func NewReceipt(paper fpdf.PageSize) *Document {
+	d := NewDocument()
+	d.internal.SetMargins(3, 3, 3)
+	d.internal.SetAutoPageBreak(true, 3)
+	d.internal.AddPageFormat(fpdf.Portrait, paper)
+	d.internal.SetFont("Courier", "", 9)
+	return d
+}
+
+// ReceiptLine prints left and right at both ends of a line, with the gap
+// filled with the repeated fill string, as in "Coffee ...... 3.50".
+func (d *Document) ReceiptLine(left, right, fill string) *Document {
+	d.internal.ReceiptLine(left, right, fill)
+	return d
+}
+
+// ReceiptRule prints a line of the repeated char string, such as "-".
+func (d *Document) ReceiptRule(char string) *Document {
+	d.internal.ReceiptRule(char)
+	return d
+}
+
+// ReceiptCut marks where the paper is to be cut with a dashed line across it.
+func (d *Document) ReceiptCut() *Document {
+	d.internal.ReceiptCut()
+	return d
+}
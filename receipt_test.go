@@ -0,0 +1,29 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestNewReceipt(t *testing.T) {
+	doc := NewReceipt(fpdf.Receipt58).
+		ReceiptLine("Espresso", "2.80", ".").
+		ReceiptRule("=").
+		ReceiptLine("Total", "2.80", "").
+		ReceiptCut()
+	if got := doc.internal.ReceiptColumns(); got != 27 {
+		t.Errorf("got %d columns, want 27", got)
+	}
+	y := doc.internal.GetY()
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	w, h, _ := doc.internal.PageSize(1)
+	if math.Abs(w-58) > 0.01 || math.Abs(h-(y+3)) > 0.01 {
+		t.Errorf("got a page of %.2f x %.2f mm, want 58 x %.2f mm", w, h, y+3)
+	}
+}
@@ -2,29 +2,104 @@ package pdf
 
 import (
 	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/pdf/fpdf"
 )
 
 type Table struct {
 	doc         *Document
 	columns     []*TableColumn
-	rows        [][]string
+	rows        []tableRow
 	headerStyle Style
+	shrink      bool // reduce the font sizes for the columns to fit
+	// number of rows below the last one in which each column is covered by a
+	// cell spanning several rows
+	covered []int
+	// rows added after groups and at the bottom of the pages
+	subtotal, footer tableTotal
+}
+
+// tableCell is a value of a table row, with the columns and rows it spans.
+type tableCell struct {
+	text             string
+	col              int // first column
+	colSpan, rowSpan int
+	drawing          *CellDrawing // content drawn instead of the text, or nil
+}
+
+// CellDrawing is a value of a table row whose content is drawn by a
+// function, as returned by DrawCell.
+type CellDrawing struct {
+	Height float64 // height the content needs; the row grows to it
+	Draw   func(e *fpdf.Fpdf, x, y, w, h float64)
+}
+
+// DrawCell returns a value of a table row for AddRow, possibly wrapped by
+// Span, whose content is drawn by fn: a sparkline, rating stars, a barcode
+// or an image. The row is made at least height high for it, or the last row
+// of the span for a cell spanning several rows. fn receives the engine of
+// the document and the rectangle of the cell, inside its frame, and draws
+// clipped to it. The font, colors and line width it sets are restored
+// afterwards.
+//
+//	table.AddRow("Widget", pdf.DrawCell(8, func(e *fpdf.Fpdf, x, y, w, h float64) {
+//		e.Rect(x+1, y+2, (w-2)*score, h-4, "F")
+//	}))
+func DrawCell(height float64, fn func(e *fpdf.Fpdf, x, y, w, h float64)) CellDrawing {
+	return CellDrawing{Height: height, Draw: fn}
+}
+
+// CellSpan is a value of a table row that spans several columns or rows, as
+// returned by Span.
+type CellSpan struct {
+	Value      any
+	Cols, Rows int
+}
+
+// Span returns the value v of a table row spanning cols columns and rows
+// rows, counting those it starts at, for AddRow. The values that follow in
+// the row go to the columns after the span, and the next rows leave the
+// columns it covers out:
+//
+//	table.AddRow(pdf.Span("North", 1, 2), "Q1", 120).
+//		AddRow("Q2", 140). // Q2 goes to the second column
+//		AddRow(pdf.Span("Total", 2, 1), 260)
+func Span(v any, cols, rows int) CellSpan {
+	return CellSpan{Value: v, Cols: cols, Rows: rows}
 }
 
 type TableColumn struct {
-	table  *Table
-	header string
-	width  float64
-	align  string
-	prefix string
-	suffix string
+	table    *Table
+	header   string
+	width    float64
+	align    string
+	prefix   string
+	suffix   string
+	format   columnFormat
+	decimals int
+	// bounds of the width computed for the column when width is not set
+	minWidth, maxWidth float64
+	percent            float64 // share of the table width, in percent
 }
 
+// minTableFontScale is how much ShrinkToFit may reduce the font sizes of a
+// table.
+const minTableFontScale = 0.5
+
+// columnFormat selects how AddRow formats the values of a column.
+type columnFormat int
+
+const (
+	columnPlain columnFormat = iota
+	columnNumber
+	columnCurrency
+	columnDate
+)
+
 func (d *Document) AddTable() *Table {
 	return &Table{
 		doc:     d,
 		columns: make([]*TableColumn, 0),
-		rows:    make([][]string, 0),
+		rows:    make([]tableRow, 0),
 	}
 }
 
@@ -49,6 +124,27 @@ func (c *TableColumn) Width(w float64) *TableColumn {
 	return c
 }
 
+// MinWidth keeps the width computed for a column without Width from going
+// below w.
+func (c *TableColumn) MinWidth(w float64) *TableColumn {
+	c.minWidth = w
+	return c
+}
+
+// MaxWidth keeps the width computed for a column without Width from going
+// above w.
+func (c *TableColumn) MaxWidth(w float64) *TableColumn {
+	c.maxWidth = w
+	return c
+}
+
+// Percent makes the column p percent as wide as the table may be, from the
+// current position to the right margin.
+func (c *TableColumn) Percent(p float64) *TableColumn {
+	c.percent = p
+	return c
+}
+
 func (c *TableColumn) AlignLeft() *TableColumn {
 	c.align = "L"
 	return c
@@ -74,6 +170,79 @@ func (c *TableColumn) Suffix(s string) *TableColumn {
 	return c
 }
 
+// Number formats the numbers of the column with decimals digits after the
+// decimal separator and grouped thousands, following the document locale.
+func (c *TableColumn) Number(decimals int) *TableColumn {
+	c.format, c.decimals = columnNumber, decimals
+	return c
+}
+
+// Currency formats the numbers of the column as amounts of money in the
+// currency of the document locale.
+func (c *TableColumn) Currency() *TableColumn {
+	c.format = columnCurrency
+	return c
+}
+
+// Date formats the values of the column, int64 times in nanoseconds since the
+// Unix epoch, with the date layout of the document locale.
+func (c *TableColumn) Date() *TableColumn {
+	c.format = columnDate
+	return c
+}
+
+// formatValue returns v as printed in the column. Values of another type than
+// the format of the column expects are printed as they are.
+func (c *TableColumn) formatValue(d *Document, v any) string {
+	switch c.format {
+	case columnNumber, columnCurrency:
+		n, ok := toFloat(v)
+		if !ok {
+			break
+		}
+		if c.format == columnCurrency {
+			return d.FormatCurrency(n)
+		}
+		return d.FormatNumber(n, c.decimals)
+	case columnDate:
+		if nano, ok := v.(int64); ok {
+			return d.FormatDate(nano)
+		}
+	}
+	return Sprintf("%v", v)
+}
+
+// toFloat returns v as a float64 if it is a number.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
 // Table methods that can be called from TableColumn too
 
 func (t *Table) HeaderStyle(s Style) *Table {
@@ -85,10 +254,204 @@ func (c *TableColumn) HeaderStyle(s Style) *Table {
 	return c.table.HeaderStyle(s)
 }
 
+// ShrinkToFit lets the table reduce its font sizes, down to half of them,
+// when its columns do not fit between the current position and the right
+// margin at the width of their content.
+func (t *Table) ShrinkToFit() *Table {
+	t.shrink = true
+	return t
+}
+
+func (c *TableColumn) ShrinkToFit() *Table {
+	return c.table.ShrinkToFit()
+}
+
+// ColumnWidths returns the widths Draw gives the columns. A column set with
+// Width keeps it and one set with Percent takes its share of the space from
+// the current position to the right margin. The other columns are as wide as
+// their header and values, within their MinWidth and MaxWidth. When they do
+// not fit in the remaining space, the font sizes are reduced if ShrinkToFit
+// is set, and the columns are narrowed in proportion to their widths, down
+// to their MinWidth, if that is not enough.
+func (t *Table) ColumnWidths() []float64 {
+	f := t.doc.internal
+	family, style := f.GetFontFamily(), f.GetFontStyle()
+	size, _ := f.GetFontSize()
+	widths, _ := t.layout()
+	if family != "" {
+		f.SetFont(family, style, size)
+	}
+	return widths
+}
+
+// fonts returns the styles of the header and body of the table, from the
+// font configuration of the document and the header style of the table, with
+// their family and size set.
+func (t *Table) fonts() (header, body TextStyle) {
+	family := t.doc.theme.Family
+	if family == "" {
+		family = t.doc.internal.GetFontFamily()
+	}
+	if family == "" {
+		family = "Arial"
+	}
+	header, body = t.doc.theme.TableHeader, t.doc.theme.Body
+	if header.Family == "" {
+		header.Family = family
+	}
+	if t.headerStyle.Font != "" {
+		header.Font = t.headerStyle.Font
+	}
+	if t.headerStyle.FontSize != 0 {
+		header.Size = t.headerStyle.FontSize
+	}
+	if t.headerStyle.TextColor != (Color{}) {
+		header.Color = t.headerStyle.TextColor
+	}
+	if header.Size == 0 {
+		header.Size = 12
+	}
+	if body.Family == "" {
+		body.Family = family
+	}
+	if body.Size == 0 {
+		body.Size = 12
+	}
+	return
+}
+
+// layout measures the header and values of the columns and returns their
+// widths and the factor applied to the font sizes, as described for
+// ColumnWidths. It leaves the body font set.
+func (t *Table) layout() (widths []float64, scale float64) {
+	f := t.doc.internal
+	header, body := t.fonts()
+	_, _, rMargin, _ := f.GetMargins()
+	pageW, _ := f.GetPageSize()
+	avail := pageW - rMargin - f.GetX()
+	margin := 2 * f.GetCellMargin()
+
+	// Widths of the texts of the columns sized by their content, at the full
+	// font sizes
+	text := make([]float64, len(t.columns))
+	sized := func(i int) bool {
+		return i < len(t.columns) && t.columns[i].width <= 0 && t.columns[i].percent <= 0
+	}
+	f.SetFont(header.Family, header.Font, header.Size)
+	for i, col := range t.columns {
+		if sized(i) {
+			text[i] = f.GetStringWidth(col.header)
+		}
+	}
+	f.SetFont(body.Family, "", body.Size)
+	for _, row := range t.displayRows() {
+		for _, c := range row.cells {
+			// Cells spanning several columns leave the widths to the others
+			if c.colSpan == 1 && sized(c.col) {
+				col := t.columns[c.col]
+				text[c.col] = max(text[c.col], f.GetStringWidth(col.prefix+c.text+col.suffix))
+			}
+		}
+	}
+
+	widths = make([]float64, len(t.columns))
+	auto := avail
+	for i, col := range t.columns {
+		switch {
+		case col.width > 0:
+			widths[i] = col.width
+		case col.percent > 0:
+			widths[i] = avail * col.percent / 100
+		default:
+			continue
+		}
+		auto -= widths[i]
+	}
+	// fit returns the total width of the columns sized by their content when
+	// the fonts are scaled by s
+	fit := func(s float64) (sum float64) {
+		for i, col := range t.columns {
+			if !sized(i) {
+				continue
+			}
+			w := text[i]*s + margin
+			if col.maxWidth > 0 {
+				w = min(w, col.maxWidth)
+			}
+			widths[i] = max(w, col.minWidth)
+			sum += widths[i]
+		}
+		return
+	}
+	scale = 1
+	if sum := fit(scale); sum > auto && t.shrink {
+		lo, hi := minTableFontScale, 1.0
+		for j := 0; j < 20; j++ {
+			if s := (lo + hi) / 2; fit(s) > auto {
+				hi = s
+			} else {
+				lo = s
+			}
+		}
+		scale = lo
+	}
+	if sum := fit(scale); sum > auto && auto > 0 {
+		for i, col := range t.columns {
+			if sized(i) {
+				widths[i] = max(widths[i]*auto/sum, col.minWidth)
+			}
+		}
+	}
+	if scale != 1 {
+		f.SetFont(body.Family, "", body.Size*scale)
+	}
+	return
+}
+
+// AddRow adds a row with values, one for each column, or for each span of
+// columns made with Span. Columns covered by a span of rows begun in a row
+// above are skipped.
 func (t *Table) AddRow(values ...any) *Table {
-	row := make([]string, len(values))
-	for i, v := range values {
-		row[i] = Sprintf("%v", v)
+	n := len(t.columns)
+	if len(t.covered) < n {
+		t.covered = append(t.covered, make([]int, n-len(t.covered))...)
+	}
+	row := tableRow{kind: rowData, values: make([]any, n), cells: make([]tableCell, 0, len(values))}
+	col := 0
+	for _, v := range values {
+		for col < n && t.covered[col] > 0 {
+			col++
+		}
+		c := tableCell{col: col, colSpan: 1, rowSpan: 1}
+		if span, ok := v.(CellSpan); ok {
+			v = span.Value
+			c.colSpan, c.rowSpan = max(span.Cols, 1), max(span.Rows, 1)
+		}
+		if drawing, ok := v.(CellDrawing); ok {
+			// Copied in the branch, so that only the cells with a drawing
+			// allocate.
+			d := drawing
+			c.drawing = &d
+			c.colSpan = min(c.colSpan, max(n-col, 1))
+		} else if col < n {
+			c.colSpan = min(c.colSpan, n-col)
+			c.text = t.columns[col].formatValue(t.doc, v)
+			row.values[col] = v
+		} else {
+			c.text = Sprintf("%v", v)
+		}
+		row.cells = append(row.cells, c)
+		col += c.colSpan
+	}
+	for j := range t.covered {
+		if t.covered[j] > 0 {
+			t.covered[j]--
+		}
+	}
+	for _, c := range row.cells {
+		for j := c.col; j < min(c.col+c.colSpan, n); j++ {
+			t.covered[j] = c.rowSpan - 1
+		}
 	}
 	t.rows = append(t.rows, row)
 	return t
@@ -99,63 +462,238 @@ func (c *TableColumn) AddRow(values ...any) *Table {
 }
 
 func (t *Table) Draw() *Document {
-	// Draw Header
-	// Save current font settings?
-	// For simplicity, we just set what we need.
+	f := t.doc.internal
+	x := f.GetX()
+	// The font and text color are restored once the table is drawn
+	family, style := f.GetFontFamily(), f.GetFontStyle()
+	size, _ := f.GetFontSize()
+	r, g, b := f.GetTextColor()
+	widths, scale := t.layout()
+	header, body := t.fonts()
 
-	fontFamily := t.doc.internal.GetFontFamily()
-	if fontFamily == "" {
-		fontFamily = "Arial"
+	f.SetFont(header.Family, header.Font, header.Size*scale)
+
+	// Apply colors
+	if t.headerStyle.FillColor != (Color{}) {
+		f.SetFillColor(t.headerStyle.FillColor.R, t.headerStyle.FillColor.G, t.headerStyle.FillColor.B)
+	} else {
+		f.SetFillColor(255, 255, 255)
 	}
+	f.SetTextColor(header.Color.R, header.Color.G, header.Color.B)
 
-	headerFont := t.headerStyle.Font
-	if headerFont == "" {
-		headerFont = "B"
+	// Draw Header Row
+	for i, col := range t.columns {
+		f.CellFormat(widths[i], 10, col.header, "1", 0, "C", true, 0, "")
 	}
+	f.Ln(10)
 
-	headerSize := t.headerStyle.FontSize
-	if headerSize == 0 {
-		headerSize = 12
+	// Draw Data
+	f.SetFont(body.Family, "", body.Size*scale)
+	f.SetTextColor(0, 0, 0)
+	f.SetFillColor(255, 255, 255)
+	t.drawRows(x, widths)
+
+	if family != "" {
+		f.SetFont(family, style, size)
 	}
+	f.SetTextColor(r, g, b)
+	return t.doc
+}
 
-	t.doc.internal.SetFont(fontFamily, headerFont, headerSize)
+// openSpan is a cell spanning several rows whose bottom is not drawn yet.
+type openSpan struct {
+	cell tableCell
+	w    float64 // width of the columns spanned
+	top  float64 // top of the cell on the current page
+	last int     // last row spanned
+	text string  // text still to print, empty once printed on a page
+	// drawing still to draw, nil once drawn on a page
+	drawing *CellDrawing
+}
 
-	// Apply colors
-	if t.headerStyle.FillColor != (Color{}) {
-		t.doc.internal.SetFillColor(t.headerStyle.FillColor.R, t.headerStyle.FillColor.G, t.headerStyle.FillColor.B)
-	} else {
-		t.doc.internal.SetFillColor(255, 255, 255)
+// tableRowHeight is the height of the rows of a table, unless they hold
+// taller drawings.
+const tableRowHeight = 10
+
+// rowHeights returns the heights of rows, grown for the drawings of their
+// cells.
+func rowHeights(rows []tableRow) []float64 {
+	heights := make([]float64, len(rows))
+	for r, row := range rows {
+		heights[r] = tableRowHeight
+		for _, c := range row.cells {
+			if c.drawing != nil && c.rowSpan == 1 {
+				heights[r] = max(heights[r], c.drawing.Height)
+			}
+		}
 	}
+	// The last row spanned by a drawing grows by what the others lack
+	for r, row := range rows {
+		for _, c := range row.cells {
+			if c.drawing == nil || c.rowSpan == 1 {
+				continue
+			}
+			end := min(r+c.rowSpan, len(rows))
+			var sum float64
+			for _, h := range heights[r:end] {
+				sum += h
+			}
+			if sum < c.drawing.Height {
+				heights[end-1] += c.drawing.Height - sum
+			}
+		}
+	}
+	return heights
+}
 
-	if t.headerStyle.TextColor != (Color{}) {
-		t.doc.internal.SetTextColor(t.headerStyle.TextColor.R, t.headerStyle.TextColor.G, t.headerStyle.TextColor.B)
-	} else {
-		t.doc.internal.SetTextColor(0, 0, 0)
+// drawRows draws the rows of the table from the abscissa x. The rows tied by
+// cells spanning several of them are moved together to the next page when
+// they do not fit on the current one but fit on a page. Otherwise a spanning
+// cell is split by the page break: each part is framed, and the text is
+// printed in the first one.
+func (t *Table) drawRows(x float64, widths []float64) {
+	f := t.doc.internal
+	colX := make([]float64, len(widths)+1)
+	colX[0] = x
+	for i, w := range widths {
+		colX[i+1] = colX[i] + w
+	}
+	auto, margin := f.GetAutoPageBreak()
+	f.SetAutoPageBreak(false, margin)
+	defer f.SetAutoPageBreak(auto, margin)
+	_, pageH := f.GetPageSize()
+	_, pageTop, _, _ := f.GetMargins()
+	trigger := pageH - margin
+	footer := t.footer.label != "" || t.footer.agg != nil
+	if footer {
+		// Room for the footer at the bottom of the pages
+		trigger -= tableRowHeight
 	}
 
-	// Draw Header Row
-	for _, col := range t.columns {
-		t.doc.internal.CellFormat(col.width, 10, col.header, "1", 0, "C", true, 0, "")
+	rows := t.displayRows()
+	heights := rowHeights(rows)
+	y := f.GetY()
+	var open []openSpan
+	var done []tableRow // rows printed so far, totaled by the footer
+	family, style := f.GetFontFamily(), FontRegular
+	size, _ := f.GetFontSize()
+	setStyle := func(k rowKind) {
+		if k.fontStyle() != style {
+			style = k.fontStyle()
+			f.SetFont(family, style, size)
+		}
+	}
+	drawFooter := func() {
+		setStyle(rowTotal)
+		for _, c := range t.totalRow(t.footer, done).cells {
+			t.drawCell(colX[c.col], y, colX[c.col+1]-colX[c.col], tableRowHeight, t.cellText(rowTotal, c), c.col)
+		}
+		y += tableRowHeight
+	}
+	pageBreak := func() {
+		for j := range open {
+			s := &open[j]
+			if y > s.top {
+				t.drawCell(colX[s.cell.col], s.top, s.w, y-s.top, s.text, s.cell.col)
+				t.drawContent(s.drawing, colX[s.cell.col], s.top, s.w, y-s.top)
+				s.text, s.drawing = "", nil
+			}
+		}
+		if footer {
+			drawFooter()
+		}
+		f.AddPage()
+		y = f.GetY()
+		for j := range open {
+			open[j].top = y
+		}
+	}
+	for r, row := range rows {
+		if auto && len(open) == 0 {
+			end := spannedRows(rows, r)
+			if row.kind == rowGroup && end < len(rows) {
+				// Keep the title of a group with its first rows
+				end = spannedRows(rows, end)
+			}
+			var h float64
+			for _, rowH := range heights[r:end] {
+				h += rowH
+			}
+			if y+h > trigger && y > pageTop && pageTop+h <= trigger {
+				pageBreak()
+			}
+		}
+		if auto && y+heights[r] > trigger && y > pageTop {
+			pageBreak()
+		}
+		setStyle(row.kind)
+		for _, c := range row.cells {
+			if c.col >= len(widths) {
+				continue
+			}
+			w := colX[c.col+c.colSpan] - colX[c.col]
+			text := t.cellText(row.kind, c)
+			if last := min(r+c.rowSpan, len(rows)) - 1; last > r {
+				open = append(open, openSpan{cell: c, w: w, top: y, last: last, text: text, drawing: c.drawing})
+				continue
+			}
+			t.drawCell(colX[c.col], y, w, heights[r], text, c.col)
+			t.drawContent(c.drawing, colX[c.col], y, w, heights[r])
+		}
+		y += heights[r]
+		if footer {
+			done = rows[:r+1]
+		}
+		kept := open[:0]
+		for _, s := range open {
+			if s.last == r {
+				t.drawCell(colX[s.cell.col], s.top, s.w, y-s.top, s.text, s.cell.col)
+				t.drawContent(s.drawing, colX[s.cell.col], s.top, s.w, y-s.top)
+			} else {
+				kept = append(kept, s)
+			}
+		}
+		open = kept
+	}
+	if footer {
+		drawFooter()
 	}
-	t.doc.internal.Ln(10)
+	setStyle(rowData)
+	f.SetY(y)
+}
 
-	// Draw Data
-	t.doc.internal.SetFont(fontFamily, "", 12) // Reset to regular 12
-	t.doc.internal.SetTextColor(0, 0, 0)
-	t.doc.internal.SetFillColor(255, 255, 255)
+// drawContent calls the function of drawing, if any, for the cell of width w
+// and height h at (x, y), clipped to it, and restores the font, colors and
+// line width of the table afterwards.
+func (t *Table) drawContent(drawing *CellDrawing, x, y, w, h float64) {
+	if drawing == nil || drawing.Draw == nil {
+		return
+	}
+	f := t.doc.internal
+	c := f.SaveCursor()
+	f.ClipRect(x, y, w, h, false)
+	drawing.Draw(f, x, y, w, h)
+	f.ClipEnd()
+	f.RestoreCursor(c)
+}
 
-	for _, row := range t.rows {
-		for i, val := range row {
-			if i < len(t.columns) {
-				col := t.columns[i]
-				text := col.prefix + val + col.suffix
-				t.doc.internal.CellFormat(col.width, 10, text, "1", 0, col.align, false, 0, "")
-			}
+// spannedRows returns the index of the row after the rows tied to row r by
+// cells spanning several rows.
+func spannedRows(rows []tableRow, r int) int {
+	end := r + 1
+	for j := r; j < end && j < len(rows); j++ {
+		for _, c := range rows[j].cells {
+			end = max(end, min(j+c.rowSpan, len(rows)))
 		}
-		t.doc.internal.Ln(10)
 	}
+	return end
+}
 
-	return t.doc
+// drawCell draws a framed cell of width w and height h at (x, y) with text
+// aligned as in column col.
+func (t *Table) drawCell(x, y, w, h float64, text string, col int) {
+	t.doc.internal.SetXY(x, y)
+	t.doc.internal.CellFormat(w, h, text, "1", 0, t.columns[col].align, false, 0, "")
 }
 
 func (c *TableColumn) Draw() *Document {
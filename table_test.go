@@ -0,0 +1,373 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf/fpdf"
+)
+
+func TestTableLocale(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.SetLocale("de-CH")
+	doc.AddPage()
+	const nano = 1700000000 * 1e9 // 2023-11-14T22:13:20Z
+	doc.AddTable().
+		AddColumn("Item").Width(40).
+		AddColumn("Qty").Width(30).AlignRight().Number(0).
+		AddColumn("Price").Width(40).AlignRight().Currency().
+		AddColumn("Date").Width(30).Date().
+		AddRow("Widget", 12000, 1234.5, int64(nano)).
+		AddRow("Gadget", "n/a", 9.99, "soon").
+		Draw()
+	if doc.internal.Err() {
+		t.Fatal(doc.internal.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+	for _, want := range []string{"(Widget)", "(n/a)", "(14.11.2023)", "(soon)", "(CHF 9.99)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %s", want)
+		}
+	}
+	if got := doc.FormatNumber(12000, 0); got != "12’000" {
+		t.Errorf("unexpected number: %s", got)
+	}
+}
+
+func TestTableColumnWidths(t *testing.T) {
+	doc := NewDocument()
+	doc.AddPage()
+	f := doc.internal
+	margin := 2 * f.GetCellMargin()
+	table := doc.AddTable().
+		AddColumn("Code").Width(20).
+		AddColumn("Product").
+		AddColumn("Notes").MaxWidth(30).
+		AddColumn("Qty").MinWidth(25).
+		AddRow("A1", "A rather long product name", "Some notes that do not fit", 3)
+	widths := table.ColumnWidths()
+
+	f.SetFont("Arial", "", 12)
+	want := []float64{20, f.GetStringWidth("A rather long product name") + margin, 30, 25}
+	for i := range want {
+		if math.Abs(widths[i]-want[i]) > 1e-9 {
+			t.Errorf("column %d: got width %.2f, want %.2f", i, widths[i], want[i])
+		}
+	}
+
+	left, _, right, _ := f.GetMargins()
+	pageW, _ := f.GetPageSize()
+	avail := pageW - left - right
+	pct := doc.AddTable().
+		AddColumn("Half").Percent(50).
+		AddColumn("Rest").
+		AddRow("x", "y").ColumnWidths()
+	if math.Abs(pct[0]-avail/2) > 1e-9 {
+		t.Errorf("got width %.2f for 50%%, want %.2f", pct[0], avail/2)
+	}
+}
+
+func TestTableShrinkToFit(t *testing.T) {
+	long := strings.Repeat("wide text ", 6)
+	build := func(doc *Document) *Table {
+		table := doc.AddTable()
+		for j := 0; j < 3; j++ {
+			table.AddColumn("Column")
+		}
+		return table.AddRow(long, long, long)
+	}
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	left, _, right, _ := doc.internal.GetMargins()
+	pageW, _ := doc.internal.GetPageSize()
+	avail := pageW - left - right
+
+	// Without shrinking the columns are narrowed to the page
+	widths := build(doc).ColumnWidths()
+	if sum := widths[0] + widths[1] + widths[2]; math.Abs(sum-avail) > 1e-6 {
+		t.Errorf("columns are %.2f wide, want %.2f", sum, avail)
+	}
+	// With shrinking the font size is reduced until the text fits
+	doc.SetFont("Times", 10)
+	build(doc).ShrinkToFit().Draw()
+	if pt, _ := doc.internal.GetFontSize(); doc.internal.GetFontFamily() != "times" || pt != 10 {
+		t.Errorf("font not restored after the table: %s %.2f", doc.internal.GetFontFamily(), pt)
+	}
+	pt := 12.0
+	for _, m := range regexp.MustCompile(`/F\w+ ([\d.]+) Tf`).FindAllStringSubmatch(doc.internal.PageContentString(1), -1) {
+		size, _ := strconv.ParseFloat(m[1], 64)
+		pt = min(pt, size)
+	}
+	if pt >= 12 || pt < 6 {
+		t.Errorf("got body font size %.2f, want a reduced size", pt)
+	}
+	doc.internal.SetFont("Arial", "", pt)
+	if w := doc.internal.GetStringWidth(long) + 2*doc.internal.GetCellMargin(); w > avail/3+0.01 {
+		t.Errorf("text %.2f wide does not fit in a column of %.2f", w, avail/3)
+	}
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTableSpans(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	table := doc.AddTable().
+		AddColumn("Region").Width(40).
+		AddColumn("Quarter").Width(30).
+		AddColumn("Sales").Width(30).AlignRight().
+		AddRow(Span("North", 1, 2), "Q1", 120).
+		AddRow("Q2", 140).
+		AddRow(Span("Total", 2, 1), 260)
+	want := [][]tableCell{
+		{{"North", 0, 1, 2, nil}, {"Q1", 1, 1, 1, nil}, {"120", 2, 1, 1, nil}},
+		{{"Q2", 1, 1, 1, nil}, {"140", 2, 1, 1, nil}},
+		{{"Total", 0, 2, 1, nil}, {"260", 2, 1, 1, nil}},
+	}
+	for r := range want {
+		for j := range want[r] {
+			if table.rows[r].cells[j] != want[r][j] {
+				t.Errorf("row %d, cell %d: got %+v, want %+v", r, j, table.rows[r].cells[j], want[r][j])
+			}
+		}
+	}
+	table.Draw()
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// North over two rows, Q1, 120, Q2, 140, Total over two columns and 260
+	// below the header
+	k := 72 / 25.4
+	left, top, _, _ := doc.internal.GetMargins()
+	for _, box := range [][4]float64{
+		{left, top + 10, 40, 20},
+		{left, top + 30, 70, 10},
+	} {
+		rect := fmt.Sprintf("%.2f %.2f %.2f %.2f re S", box[0]*k, (297-box[1])*k, box[2]*k, -box[3]*k)
+		if !strings.Contains(out, rect) {
+			t.Errorf("missing cell frame %s", rect)
+		}
+	}
+	if n := strings.Count(out, " re S"); n != 7 {
+		t.Errorf("got %d cell frames, want 7", n)
+	}
+}
+
+func TestTableSpanPageBreak(t *testing.T) {
+	draw := func(span int) (*Document, string) {
+		doc := NewDocument()
+		doc.internal.SetCompression(false)
+		doc.AddPage()
+		_, pageH := doc.internal.GetPageSize()
+		_, margin := doc.internal.GetAutoPageBreak()
+		// Room for the header and two rows
+		doc.internal.SetY(pageH - margin - 31)
+		table := doc.AddTable().AddColumn("A").Width(40).AddColumn("B").Width(40)
+		table.AddRow(Span("Group", 1, span), "b")
+		for j := 1; j < span; j++ {
+			table.AddRow("b")
+		}
+		table.Draw()
+		var buf bytes.Buffer
+		if err := doc.OutputTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return doc, buf.String()
+	}
+	k := 72 / 25.4
+	// frames returns the heights of the frames of the first column
+	frames := func(doc *Document, out string) (heights []float64) {
+		left, _, _, _ := doc.internal.GetMargins()
+		prefix := fmt.Sprintf("%.2f ", left*k)
+		width := fmt.Sprintf(" %.2f -", 40*k)
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, prefix) && strings.Contains(line, width) {
+				fields := strings.Fields(line)
+				h, _ := strconv.ParseFloat(fields[3], 64)
+				heights = append(heights, math.Round(-h/k))
+			}
+		}
+		return
+	}
+
+	// Three rows tied by a span move to the next page together
+	doc, out := draw(3)
+	if n := doc.internal.PageCount(); n != 2 {
+		t.Fatalf("got %d pages, want 2", n)
+	}
+	left, top, _, _ := doc.internal.GetMargins()
+	if want := fmt.Sprintf("%.2f %.2f %.2f %.2f re S", left*k, (297-top)*k, 40*k, -30*k); !strings.Contains(out, want) {
+		t.Errorf("span not moved to the top of the next page: %s missing", want)
+	}
+
+	// A span taller than a page is framed on each page, with its text once
+	doc, out = draw(40)
+	if n := doc.internal.PageCount(); n != 3 {
+		t.Fatalf("got %d pages, want 3", n)
+	}
+	heights := frames(doc, out)
+	if len(heights) != 1+3 || heights[0] != 10 || heights[1]+heights[2]+heights[3] != 400 {
+		t.Errorf("got frames %v, want the header and three parts of 400 in total", heights)
+	}
+	if n := strings.Count(out, "(Group)"); n != 1 {
+		t.Errorf("text of the split span printed %d times, want once", n)
+	}
+}
+
+func TestTableDrawCell(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	left, top, _, _ := doc.internal.GetMargins()
+	var rects [][4]float64
+	stars := DrawCell(16, func(e *fpdf.Fpdf, x, y, w, h float64) {
+		rects = append(rects, [4]float64{x, y, w, h})
+		e.SetFillColor(255, 200, 0)
+		e.SetFont("Arial", "B", 20)
+		e.Rect(x+1, y+1, 10, 10, "F")
+	})
+	doc.AddTable().
+		AddColumn("Item").Width(40).
+		AddColumn("Rating").Width(30).
+		AddRow("Widget", stars).
+		AddRow("Gadget", Span(stars, 1, 2)).
+		AddRow("Gizmo").
+		Draw()
+	want := [][4]float64{
+		{left + 40, top + 10, 30, 16},
+		{left + 40, top + 26, 30, 20},
+	}
+	if len(rects) != len(want) {
+		t.Fatalf("got %d drawings, want %d", len(rects), len(want))
+	}
+	for j := range want {
+		if rects[j] != want[j] {
+			t.Errorf("drawing %d: got rectangle %v, want %v", j, rects[j], want[j])
+		}
+	}
+	// The body font is restored after the drawings
+	if pt, _ := doc.internal.GetFontSize(); pt != 12 || doc.internal.GetFontStyle() != "" {
+		t.Errorf("got font %s %.1f after the table", doc.internal.GetFontStyle(), pt)
+	}
+	if y := doc.internal.GetY(); y != top+10+16+20 {
+		t.Errorf("got y %.2f after the table, want %.2f", y, top+46)
+	}
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), " re W n"); n != 2 {
+		t.Errorf("got %d clipped drawings, want 2", n)
+	}
+}
+
+func TestTableGroups(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	doc.AddTable().
+		AddColumn("Item").Width(40).
+		AddColumn("Units").Width(30).
+		AddColumn("Price").Width(30).Prefix("$").
+		Subtotals("Subtotal", Sum).
+		Group("Fruit").
+		AddRow("Apples", 3, 1.5).
+		AddRow("Pears", 2, 2.25).
+		Group("Tools").
+		AddRow("Hammer", 1, 12.0).
+		AddRow("Saw", nil, "n/a").
+		Draw()
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if start, end := strings.Index(line, "("), strings.LastIndex(line, ")Tj"); start >= 0 && end > start {
+			texts = append(texts, line[start+1:end])
+		}
+	}
+	want := []string{
+		"Item", "Units", "Price",
+		"Fruit",
+		"Apples", "3", "$1.5",
+		"Pears", "2", "$2.25",
+		"Subtotal", "5", "$3.75",
+		"Tools",
+		"Hammer", "1", "$12",
+		"Saw", "$n/a",
+		"Subtotal", "1",
+	}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("got cells\n%q\nwant\n%q", texts, want)
+	}
+	// Group titles span all the columns
+	k := 72 / 25.4
+	if n := strings.Count(buf.String(), fmt.Sprintf(" %.2f -%.2f re S", 100*k, 10*k)); n != 2 {
+		t.Errorf("got %d rows across the table, want the 2 group titles", n)
+	}
+	if got := doc.internal.GetFontStyle(); got != "" {
+		t.Errorf("got font style %q after the table, want regular", got)
+	}
+}
+
+func TestTableFooter(t *testing.T) {
+	doc := NewDocument()
+	doc.internal.SetCompression(false)
+	doc.AddPage()
+	table := doc.AddTable().
+		AddColumn("Day").Width(40).
+		AddColumn("Hours").Width(30).
+		Footer("Total", Sum)
+	for j := 1; j <= 40; j++ {
+		table.AddRow(fmt.Sprintf("Day %d", j), 2)
+	}
+	table.Draw()
+	if n := doc.internal.PageCount(); n != 2 {
+		t.Fatalf("got %d pages, want 2", n)
+	}
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "(Total)Tj"); n != 2 {
+		t.Fatalf("got %d footers, want one per page", n)
+	}
+	// The footer of the first page totals the rows printed on it
+	first := strings.Index(out, "(Total)Tj")
+	last := strings.LastIndex(out[:first], "(Day ")
+	day, _ := strconv.Atoi(out[last+len("(Day ") : strings.Index(out[last:], ")")+last])
+	if day == 0 {
+		t.Fatal("no rows before the footer of the first page")
+	}
+	if !strings.Contains(out[first:first+200], fmt.Sprintf("(%d)Tj", 2*day)) {
+		t.Errorf("footer of the first page does not total the %d rows printed on it", day)
+	}
+	if !strings.Contains(out[strings.LastIndex(out, "(Total)Tj"):], "(80)Tj") {
+		t.Errorf("footer of the table does not total all the rows")
+	}
+	// The header, the rows and the footer fit above the margin
+	_, pageH := doc.internal.GetPageSize()
+	_, margin := doc.internal.GetAutoPageBreak()
+	_, top, _, _ := doc.internal.GetMargins()
+	if y := top + float64(day+2)*10; y > pageH-margin {
+		t.Errorf("footer of the first page ends at %.2f, below the margin at %.2f", y, pageH-margin)
+	}
+}
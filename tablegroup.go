@@ -0,0 +1,171 @@
+package pdf
+
+// rowKind tells what a table row holds.
+type rowKind int
+
+const (
+	rowData  rowKind = iota // values added with AddRow
+	rowGroup                // title of a group of rows
+	rowTotal                // subtotal of a group or total of the table
+)
+
+// fontStyle returns the style of the font of the rows of kind k.
+func (k rowKind) fontStyle() string {
+	if k == rowData {
+		return FontRegular
+	}
+	return FontBold
+}
+
+// tableRow is a row of a table: its cells and, for a row of data, the values
+// given to AddRow by column.
+type tableRow struct {
+	cells  []tableCell
+	kind   rowKind
+	values []any
+}
+
+// tableTotal configures the rows that total other rows.
+type tableTotal struct {
+	label string
+	agg   Aggregator
+}
+
+// Aggregator returns the value a total row shows in column col, from the
+// values of the rows it totals in that column, or nil to leave the column
+// empty. A value of a cell spanning several columns is in its first column;
+// the other columns have nil values for it.
+type Aggregator func(col int, values []any) any
+
+// Sum is an Aggregator that adds up the numbers of a column. Columns with
+// other values are left empty.
+func Sum(col int, values []any) any {
+	var sum float64
+	ints, found := true, false
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		n, ok := toFloat(v)
+		if !ok {
+			return nil
+		}
+		if _, ok := v.(int); !ok {
+			ints = false
+		}
+		sum += n
+		found = true
+	}
+	switch {
+	case !found:
+		return nil
+	case ints:
+		return int(sum)
+	}
+	return sum
+}
+
+// Group starts a group of rows: a row showing title across the columns,
+// above the rows added up to the next group. Cells spanning several rows do
+// not extend beyond their group.
+func (t *Table) Group(title string) *Table {
+	for r := len(t.rows) - 1; r >= 0 && t.rows[r].kind != rowGroup; r-- {
+		for j := range t.rows[r].cells {
+			c := &t.rows[r].cells[j]
+			c.rowSpan = min(c.rowSpan, len(t.rows)-r)
+		}
+	}
+	clear(t.covered)
+	t.rows = append(t.rows, tableRow{kind: rowGroup, cells: []tableCell{
+		{text: title, colSpan: max(len(t.columns), 1), rowSpan: 1},
+	}})
+	return t
+}
+
+func (c *TableColumn) Group(title string) *Table {
+	return c.table.Group(title)
+}
+
+// Subtotals adds a row after each group started with Group, with label in
+// the first column and the values agg computes from the rows of the group in
+// the others.
+func (t *Table) Subtotals(label string, agg Aggregator) *Table {
+	t.subtotal = tableTotal{label, agg}
+	return t
+}
+
+func (c *TableColumn) Subtotals(label string, agg Aggregator) *Table {
+	return c.table.Subtotals(label, agg)
+}
+
+// Footer adds a row at the end of the table, with label in the first column
+// and the values agg computes from all the rows in the others. The row is
+// repeated at the bottom of each page the table breaks across, with the
+// values of the rows printed so far, and the pages keep room for it.
+func (t *Table) Footer(label string, agg Aggregator) *Table {
+	t.footer = tableTotal{label, agg}
+	return t
+}
+
+func (c *TableColumn) Footer(label string, agg Aggregator) *Table {
+	return c.table.Footer(label, agg)
+}
+
+// displayRows returns the rows of the table with the subtotals of the groups.
+func (t *Table) displayRows() []tableRow {
+	if t.subtotal.agg == nil {
+		return t.rows
+	}
+	rows := make([]tableRow, 0, len(t.rows))
+	start := -1 // first row of the current group
+	for j, row := range t.rows {
+		if row.kind == rowGroup {
+			if start >= 0 {
+				rows = append(rows, t.totalRow(t.subtotal, t.rows[start:j]))
+			}
+			start = j + 1
+		}
+		rows = append(rows, row)
+	}
+	if start >= 0 {
+		rows = append(rows, t.totalRow(t.subtotal, t.rows[start:]))
+	}
+	return rows
+}
+
+// totalRow returns the row that totals rows as configured by total.
+func (t *Table) totalRow(total tableTotal, rows []tableRow) tableRow {
+	row := tableRow{kind: rowTotal}
+	for col := range t.columns {
+		text := total.label
+		if col > 0 {
+			text = ""
+		}
+		if col > 0 && total.agg != nil {
+			var values []any
+			for _, r := range rows {
+				if r.kind == rowData && col < len(r.values) {
+					values = append(values, r.values[col])
+				}
+			}
+			if v := total.agg(col, values); v != nil {
+				text = t.columns[col].formatValue(t.doc, v)
+			}
+		}
+		row.cells = append(row.cells, tableCell{text: text, col: col, colSpan: 1, rowSpan: 1})
+	}
+	return row
+}
+
+// cellText returns the text printed in cell c of a row of kind k: its value
+// with the prefix and suffix of its column, except for the titles of groups
+// and the labels and empty values of total rows.
+func (t *Table) cellText(k rowKind, c tableCell) string {
+	if c.drawing != nil {
+		return ""
+	}
+	if k == rowGroup || (k == rowTotal && (c.col == 0 || c.text == "")) {
+		return c.text
+	}
+	return t.columns[c.col].prefix + c.text + t.columns[c.col].suffix
+}
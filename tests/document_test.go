@@ -0,0 +1,184 @@
+package pdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/pdf"
+)
+
+func TestDocumentBuilder(t *testing.T) {
+	doc := pdf.NewDocument()
+	cfg := doc.FontConfig()
+	cfg.Header1.Color = pdf.ColorRGB(20, 20, 100)
+	cfg.Header2.Size = 16
+	cfg.Body.SpaceAfter = 2
+	cfg.TableHeader = pdf.TextStyle{Family: "Courier", Font: pdf.FontItalic, Size: 10}
+	doc.SetFontConfig(cfg)
+	doc.Engine().SetCompression(false)
+
+	doc.AddPage()
+	doc.AddHeader1("Quarterly Report")
+	doc.AddParagraph("Sales grew steadily during the quarter.")
+	doc.AddSpace(5)
+	doc.AddLine()
+	doc.AddHeader2("Details")
+	doc.AddParagraph("Every region met its target.")
+	doc.AddTable().
+		AddColumn("Region").Width(60).
+		AddColumn("Total").Width(30).AlignRight().
+		AddRow("North", "120").
+		Draw()
+	// The table leaves the font of the paragraph before it
+	if eng := doc.Engine(); eng.GetFontFamily() != "helvetica" || eng.GetFontStyle() != pdf.FontRegular {
+		t.Errorf("font not restored after the table: %s %q", eng.GetFontFamily(), eng.GetFontStyle())
+	}
+
+	content := doc.Engine().PageContentString(1)
+	for _, c := range []struct {
+		text, font, color string
+	}{
+		{"Quarterly Report", fontOp("Arial", pdf.FontBold, 24), "q 0.078 0.078 0.392 rg BT "},
+		{"Sales grew steadily during the quarter.", fontOp("Arial", pdf.FontRegular, 12), "BT "},
+		{"Details", fontOp("Arial", pdf.FontBold, 16), "BT "},
+		{"Every region met its target.", fontOp("Arial", pdf.FontRegular, 12), "BT "},
+		// table cells, drawn after their borders
+		{"Region", fontOp("Courier", pdf.FontItalic, 10), ""},
+		{"North", fontOp("Arial", pdf.FontRegular, 12), ""},
+	} {
+		font, line := textState(content, c.text)
+		if font != c.font {
+			t.Errorf("%q drawn with %q, want %q", c.text, font, c.font)
+		}
+		if !strings.HasPrefix(line, c.color) {
+			t.Errorf("%q drawn with %q, want the color of %q", c.text, line, c.color)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Errorf("output is not a PDF document")
+	}
+}
+
+func TestDocumentDividerAtPageTop(t *testing.T) {
+	doc := pdf.NewDocument()
+	doc.AddPage()
+	_, top := doc.Engine().GetXY()
+	doc.Divider(pdf.DividerStyle{Dashed: true})
+	if _, y := doc.Engine().GetXY(); y != top {
+		t.Errorf("divider at the page top moved the position from %v to %v", top, y)
+	}
+	doc.AddParagraph("A divider right after a page break is dropped.")
+
+	content := doc.Engine().PageContentString(1)
+	if strings.Contains(content, " l S") || strings.Contains(content, "] 0.00 d") {
+		t.Errorf("divider drawn at the page top: %s", content)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+}
+
+func TestDocumentDividerKeepWithNext(t *testing.T) {
+	red := pdf.DividerStyle{Color: pdf.ColorRGB(255, 0, 0)}
+	for _, breaks := range []bool{false, true} {
+		doc := pdf.NewDocument()
+		doc.Engine().SetCompression(false)
+		doc.AddPage()
+		doc.AddParagraph("Some text before the divider.")
+		doc.Divider(red)
+		if breaks {
+			doc.AddPage()
+		}
+		doc.AddParagraph("The content that follows the divider.")
+
+		var buf bytes.Buffer
+		if err := doc.OutputTo(&buf); err != nil {
+			t.Fatalf("OutputTo failed: %v", err)
+		}
+		if got := bytes.Contains(buf.Bytes(), []byte("1.000 0.000 0.000 RG")); got == breaks {
+			t.Errorf("page break %v: divider drawn %v", breaks, got)
+		}
+	}
+}
+
+func TestDocumentTheme(t *testing.T) {
+	doc := pdf.NewDocument()
+	theme := pdf.DefaultTheme()
+	theme.Styles[pdf.StyleCaption] = pdf.TextStyle{Font: pdf.FontItalic, Size: 8, Color: pdf.ColorRGB(100, 100, 100), LineHeight: 4}
+	doc.SetTheme(theme)
+	doc.Engine().SetCompression(false)
+
+	doc.AddPage()
+	doc.AddStyledText(pdf.StyleTitle, "Annual Report")
+	doc.AddStyledText(pdf.StyleCode, "total := a + b")
+	doc.UseStyle(pdf.StyleCaption)
+	doc.AddText("Figure 1: revenue by region").Draw()
+
+	// The text drawn after UseStyle keeps the italic and the gray of the style.
+	content := doc.Engine().PageContentString(1)
+	caption := content[strings.Index(content, "(total := a + b)"):]
+	if !strings.Contains(caption, fontOp("Arial", pdf.FontItalic, 8)) {
+		t.Errorf("caption not in italic 8pt Arial: %s", caption)
+	}
+	if !strings.Contains(caption, "0.392 g BT") || !strings.Contains(caption, "(Figure 1: revenue by region)Tj") {
+		t.Errorf("caption not in gray: %s", caption)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.OutputTo(&buf); err != nil {
+		t.Fatalf("OutputTo failed: %v", err)
+	}
+
+	// The theme returned by Theme is a copy
+	got := doc.Theme()
+	got.Styles[pdf.StyleCaption] = pdf.TextStyle{Size: 30}
+	delete(got.Styles, pdf.StyleTitle)
+	theme.Styles[pdf.StyleCode] = pdf.TextStyle{Size: 30}
+	if styles := doc.Theme().Styles; styles[pdf.StyleCaption].Size != 8 || styles[pdf.StyleCode].Size != 10 {
+		t.Errorf("theme changed without SetTheme: %+v", styles)
+	} else if _, ok := styles[pdf.StyleTitle]; !ok {
+		t.Errorf("style removed without SetTheme")
+	}
+
+	doc = pdf.NewDocument()
+	doc.AddPage()
+	doc.UseStyle("Missing")
+	if err := doc.OutputTo(&buf); err == nil {
+		t.Errorf("expecting error for undefined style")
+	}
+}
+
+// textState returns the font operator in effect where text is drawn in
+// content, and the line that draws it.
+func textState(content, text string) (font, line string) {
+	end := strings.Index(content, "("+text+")Tj")
+	if end < 0 {
+		return "", ""
+	}
+	start := strings.LastIndex(content[:end], "\n") + 1
+	line = content[start:end]
+	if op := strings.LastIndex(content[:end], "BT /F"); op >= 0 {
+		op += 3
+		font = content[op : op+strings.Index(content[op:], " Tf")+3]
+	}
+	return
+}
+
+// fontOp returns the operator that selects the font family in style at size
+// points in a page content.
+func fontOp(family, style string, size float64) string {
+	eng := pdf.NewDocument().Engine()
+	eng.AddPage()
+	eng.SetFont(family, style, size)
+	content := eng.PageContentString(1)
+	start := strings.LastIndex(content, "BT /F") + 3
+	return content[start : start+strings.Index(content[start:], " Tf")+3]
+}